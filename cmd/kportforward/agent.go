@@ -0,0 +1,112 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"github.com/victorkazakov/kportforward/internal/agent"
+	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+var (
+	agentListen    string
+	agentNamespace string
+	agentImage     string
+)
+
+func init() {
+	agentCmd := &cobra.Command{
+		Use:   "agent",
+		Short: "Run or deploy the in-cluster agent for agent mode",
+		Long: `In agent mode (agent.enabled in the config) kportforward forwards once, to a small
+agent deployed in the cluster, and reaches every service whose target is a
+Kubernetes Service through it, instead of running one kubectl process per service.
+
+Deploy the agent with its token taken from the config (agent.token or agent.tokenEnv),
+or a new one that is printed for you to add to the config:
+  kportforward agent manifest | kubectl apply -f -
+
+The agent container runs 'kportforward agent serve'.`,
+	}
+
+	serveCmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Run the agent (inside the cluster)",
+		Args:  cobra.NoArgs,
+		RunE:  runAgentServe,
+	}
+	serveCmd.Flags().StringVar(&agentListen, "listen", ":"+strconv.Itoa(agent.DefaultPort), "Address to listen on")
+
+	manifestCmd := &cobra.Command{
+		Use:   "manifest",
+		Short: "Print the Kubernetes manifest deploying the agent",
+		Args:  cobra.NoArgs,
+		RunE:  runAgentManifest,
+	}
+	manifestCmd.Flags().StringVarP(&agentNamespace, "namespace", "n", "", "Namespace to deploy into (default: agent.namespace, or "+agent.DefaultNamespace+")")
+	manifestCmd.Flags().StringVar(&agentImage, "image", "", "Agent image (default: ghcr.io/catio-tech/kportforward at this version)")
+
+	agentCmd.AddCommand(serveCmd, manifestCmd)
+	rootCmd.AddCommand(agentCmd)
+}
+
+func runAgentServe(cmd *cobra.Command, args []string) error {
+	logger := utils.NewLogger(utils.LevelInfo)
+	token := os.Getenv(agent.TokenEnv)
+	if token == "" {
+		logger.Warn("%s is not set; accepting connections without a token", agent.TokenEnv)
+	}
+
+	listener, err := net.Listen("tcp", agentListen)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", agentListen, err)
+	}
+	logger.Info("kportforward agent %s listening on %s", version, listener.Addr())
+	return agent.NewServer(token, logger).Serve(listener)
+}
+
+func runAgentManifest(cmd *cobra.Command, args []string) error {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	namespace := agentNamespace
+	if namespace == "" {
+		namespace = cfg.Agent.Namespace
+	}
+	if namespace == "" {
+		namespace = agent.DefaultNamespace
+	}
+
+	image := agentImage
+	if image == "" {
+		tag := version
+		if tag == "dev" {
+			tag = "latest"
+		}
+		image = "ghcr.io/catio-tech/kportforward:" + tag
+	}
+
+	token := cfg.Agent.Token
+	if cfg.Agent.TokenEnv != "" {
+		token = os.Getenv(cfg.Agent.TokenEnv)
+	}
+	if token == "" {
+		secret := make([]byte, 24)
+		if _, err := rand.Read(secret); err != nil {
+			return fmt.Errorf("failed to generate a token: %w", err)
+		}
+		token = hex.EncodeToString(secret)
+		fmt.Fprintf(os.Stderr, "Generated a token for the agent; add it to the config:\nagent:\n  enabled: true\n  token: %q\n\n", token)
+	}
+
+	fmt.Print(agent.Manifest(namespace, image, token))
+	return nil
+}
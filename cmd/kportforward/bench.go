@@ -0,0 +1,215 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"sort"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/victorkazakov/kportforward/internal/bench"
+	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/kubeconfig"
+	"github.com/victorkazakov/kportforward/internal/portforward"
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+var (
+	benchDuration    time.Duration
+	benchConcurrency int
+	benchMode        string
+	benchPath        string
+	benchTimeout     time.Duration
+	benchReportFile  string
+)
+
+func init() {
+	benchCmd := &cobra.Command{
+		Use:   "bench <service>",
+		Short: "Benchmark a forwarded service endpoint",
+		Long: `Drive HTTP or TCP load through a service's port-forward and report latency
+percentiles and error rates. The forward of a running kportforward daemon or instance
+is used on the port it actually listens on; if none reports the service as running,
+a temporary port-forward is started for the duration of the benchmark.
+
+Examples:
+  kportforward bench flyte-console --duration 10s --concurrency 8
+  kportforward bench api-gateway --mode http --path healthz`,
+		Args: cobra.ExactArgs(1),
+		Run:  runBench,
+	}
+
+	benchCmd.Flags().DurationVar(&benchDuration, "duration", 10*time.Second, "Duration of the benchmark")
+	benchCmd.Flags().IntVar(&benchConcurrency, "concurrency", 8, "Number of concurrent workers")
+	benchCmd.Flags().StringVar(&benchMode, "mode", "", "Load mode: http or tcp (default: http for web/rest services, tcp otherwise)")
+	benchCmd.Flags().StringVar(&benchPath, "path", "", "Request path for HTTP mode")
+	benchCmd.Flags().DurationVar(&benchTimeout, "timeout", 5*time.Second, "Per-request timeout")
+
+	reportCmd := &cobra.Command{
+		Use:   "report",
+		Short: "Run the built-in benchmarks and write a Markdown performance report",
+		Long: `Run kportforward's config loading, port resolution and manager benchmarks on this
+machine and write a Markdown report comparing them with baseline numbers. Attach the
+report to performance issues.
+
+Examples:
+  kportforward bench report
+  kportforward bench report --output perf.md`,
+		Args: cobra.NoArgs,
+		Run:  runBenchReport,
+	}
+	reportCmd.Flags().StringVarP(&benchReportFile, "output", "o", "", "Write the report to this file instead of stdout")
+	benchCmd.AddCommand(reportCmd)
+
+	rootCmd.AddCommand(benchCmd)
+}
+
+func runBenchReport(cmd *cobra.Command, args []string) {
+	out := os.Stdout
+	if benchReportFile != "" {
+		f, err := os.Create(benchReportFile)
+		if err != nil {
+			log.Fatalf("Failed to create report: %v", err)
+		}
+		defer f.Close()
+		out = f
+	}
+
+	benchmarks := bench.Benchmarks()
+	measurements := bench.RunBenchmarks(benchmarks, func(benchmark bench.Benchmark) {
+		fmt.Fprintf(os.Stderr, "Running %s/%s...\n", benchmark.Suite, benchmark.Name)
+	})
+
+	info := bench.ReportInfo{Version: version, Commit: commit, Time: time.Now()}
+	if err := bench.WriteReport(out, info, measurements); err != nil {
+		log.Fatalf("Failed to write report: %v", err)
+	}
+	if benchReportFile != "" {
+		fmt.Fprintf(os.Stderr, "Wrote performance report to %s\n", benchReportFile)
+	}
+}
+
+func runBench(cmd *cobra.Command, args []string) {
+	serviceName := args[0]
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+	if err := kubeconfig.Apply(cfg.Kubeconfigs); err != nil {
+		log.Fatalf("Failed to load kubeconfigs: %v", err)
+	}
+
+	serviceConfig, exists := cfg.PortForwards[serviceName]
+	if !exists {
+		log.Fatalf("Service %s not found in configuration", serviceName)
+	}
+
+	logger := utils.NewLogger(utils.LevelWarn)
+
+	// Reuse the forward of a running daemon or instance, on the port it reports, since
+	// the configured port may have been reassigned
+	port, running := runningForwards(cfg)[serviceName]
+	cleanup := func() {}
+	if !running {
+		sm := portforward.NewServiceManager(serviceName, serviceConfig, logger)
+		if err := sm.Start(); err != nil {
+			log.Fatalf("Failed to start port-forward for %s: %v", serviceName, err)
+		}
+		cleanup = func() { sm.Stop() }
+		defer cleanup()
+
+		port = sm.GetStatus().LocalPort
+		fmt.Printf("Started temporary port-forward for %s on port %d\n", serviceName, port)
+
+		if err := waitForPort(port, 15*time.Second); err != nil {
+			cleanup()
+			log.Fatalf("Port-forward for %s did not become ready: %v", serviceName, err)
+		}
+	} else {
+		fmt.Printf("Using the running port-forward for %s on port %d\n", serviceName, port)
+	}
+
+	mode := bench.Mode(benchMode)
+	if mode == "" {
+		mode = bench.ModeTCP
+		if serviceConfig.Type == "web" || serviceConfig.Type == "rest" {
+			mode = bench.ModeHTTP
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-sigChan
+		cancel()
+	}()
+
+	fmt.Printf("Benchmarking %s (%s) on localhost:%d for %v with %d workers...\n",
+		serviceName, mode, port, benchDuration, benchConcurrency)
+
+	result, err := bench.Run(ctx, bench.Options{
+		Port:        port,
+		Mode:        mode,
+		Path:        benchPath,
+		Duration:    benchDuration,
+		Concurrency: benchConcurrency,
+		Timeout:     benchTimeout,
+	})
+	if err != nil {
+		cleanup()
+		log.Fatalf("Benchmark failed: %v", err)
+	}
+
+	printBenchResult(result)
+}
+
+// waitForPort polls a local port until it accepts connections or the timeout expires
+func waitForPort(port int, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if utils.CheckPortConnectivity(port) {
+			return nil
+		}
+		time.Sleep(250 * time.Millisecond)
+	}
+	return fmt.Errorf("port %d not reachable after %v", port, timeout)
+}
+
+func printBenchResult(result *bench.Result) {
+	fmt.Printf("\n=== Benchmark Results ===\n")
+	fmt.Printf("Requests:    %d (%.1f/s)\n", result.Requests, result.Throughput())
+	fmt.Printf("Errors:      %d (%.2f%%)\n", result.Errors, result.ErrorRate()*100)
+	fmt.Printf("Latency p50: %v\n", result.Percentile(50))
+	fmt.Printf("Latency p90: %v\n", result.Percentile(90))
+	fmt.Printf("Latency p99: %v\n", result.Percentile(99))
+	if len(result.Latencies) > 0 {
+		fmt.Printf("Latency max: %v\n", result.Latencies[len(result.Latencies)-1])
+	}
+
+	if len(result.StatusHits) > 0 {
+		codes := make([]int, 0, len(result.StatusHits))
+		for code := range result.StatusHits {
+			codes = append(codes, code)
+		}
+		sort.Ints(codes)
+
+		fmt.Printf("Status codes:")
+		for _, code := range codes {
+			fmt.Printf(" %d=%d", code, result.StatusHits[code])
+		}
+		fmt.Println()
+	}
+
+	if result.LastError != "" {
+		fmt.Printf("Last error:  %s\n", result.LastError)
+	}
+	fmt.Printf("=========================\n")
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/victorkazakov/kportforward/internal/bundles"
+	"github.com/victorkazakov/kportforward/internal/config"
+)
+
+var (
+	bundlesRegistry string
+	bundlesForce    bool
+)
+
+func init() {
+	bundlesCmd := &cobra.Command{
+		Use:   "bundles",
+		Short: "Browse and add curated service bundles",
+		Long: `Service bundles are canned sets of services for common stacks (e.g. flyte, argo,
+istio-observability), maintained by the community or vendors in a bundle registry.
+Adding a bundle merges its services into your user config and pins the bundle's
+version; add it again with a newer version to upgrade.
+
+Examples:
+  kportforward bundles list
+  kportforward bundles add flyte
+  kportforward bundles add argo@1.2.0`,
+	}
+	bundlesCmd.PersistentFlags().StringVar(&bundlesRegistry, "registry", "", "Bundle registry URL (default: bundleRegistry from the config, or "+bundles.DefaultRegistry+")")
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the bundles in the registry",
+		Args:  cobra.NoArgs,
+		RunE:  runBundlesList,
+	}
+
+	addCmd := &cobra.Command{
+		Use:   "add <name>[@version]",
+		Short: "Add a bundle's services to the user config",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runBundlesAdd,
+	}
+	addCmd.Flags().BoolVar(&bundlesForce, "force", false, "Replace configured services with the same names as the bundle's")
+
+	bundlesCmd.AddCommand(listCmd, addCmd)
+	rootCmd.AddCommand(bundlesCmd)
+}
+
+// bundleRegistry returns the registry selected by --registry or the config
+func bundleRegistry(cfg *config.Config) *bundles.Registry {
+	if bundlesRegistry != "" {
+		return bundles.NewRegistry(bundlesRegistry)
+	}
+	return bundles.NewRegistry(cfg.BundleRegistry)
+}
+
+func runBundlesList(cmd *cobra.Command, args []string) error {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	entries, err := bundleRegistry(cfg).Index()
+	if err != nil {
+		return err
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tLATEST\tADDED\tDESCRIPTION")
+	for _, entry := range entries {
+		added := "-"
+		if ref, ok := cfg.Bundles[entry.Name]; ok {
+			added = ref.Version
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", entry.Name, entry.Latest(), added, entry.Description)
+	}
+	return w.Flush()
+}
+
+func runBundlesAdd(cmd *cobra.Command, args []string) error {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	name, version := bundles.ParseRef(args[0])
+	bundle, err := bundleRegistry(cfg).Fetch(name, version)
+	if err != nil {
+		return err
+	}
+
+	if conflicts := bundles.Conflicts(bundle, cfg); len(conflicts) > 0 && !bundlesForce {
+		return fmt.Errorf("bundle %s would replace configured services %s; rerun with --force to replace them",
+			bundle.Name, strings.Join(conflicts, ", "))
+	}
+
+	previous, upgraded := cfg.Bundles[bundle.Name]
+	if err := config.SaveBundle(bundle.Name, config.BundleRef{Version: bundle.Version}, bundle.PortForwards); err != nil {
+		return fmt.Errorf("failed to save bundle: %w", err)
+	}
+
+	services := make([]string, 0, len(bundle.PortForwards))
+	for service := range bundle.PortForwards {
+		services = append(services, service)
+	}
+	sort.Strings(services)
+
+	if upgraded && previous.Version != bundle.Version {
+		fmt.Printf("Updated bundle %s from %s to %s: %s\n", bundle.Name, previous.Version, bundle.Version, strings.Join(services, ", "))
+	} else {
+		fmt.Printf("Added bundle %s@%s: %s\n", bundle.Name, bundle.Version, strings.Join(services, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/victorkazakov/kportforward/internal/daemon"
+)
+
+var (
+	startDaemon bool
+
+	// controlSocket serves the daemon's control socket; set by the start command
+	controlSocket bool
+)
+
+const (
+	// daemonStartTimeout bounds how long start --daemon waits for the daemon to answer
+	daemonStartTimeout = 30 * time.Second
+
+	// daemonStopTimeout bounds how long stop waits for the services to shut down
+	daemonStopTimeout = time.Minute
+)
+
+func init() {
+	startCmd := &cobra.Command{
+		Use:   "start [-- flags...]",
+		Short: "Run kportforward without the terminal UI, controlled by stop and restart",
+		Long: `Run the forwards headless with a control socket that 'kportforward stop',
+'kportforward restart <service>' and 'kportforward status' talk to. With --daemon
+it detaches into the background, so the forwards survive closing the terminal, and
+logs to daemon.log in the config directory. Flags after -- are passed to kportforward.
+
+Examples:
+  kportforward start --daemon
+  kportforward start --daemon -- --profile backend --api-port 7070
+  kportforward status --format table
+  kportforward restart api-gateway
+  kportforward stop`,
+		RunE: runStart,
+	}
+	startCmd.Flags().BoolVar(&startDaemon, "daemon", false, "Detach into the background and return once the daemon is running")
+
+	stopCmd := &cobra.Command{
+		Use:   "stop",
+		Short: "Stop the kportforward daemon and its forwards",
+		Args:  cobra.NoArgs,
+		RunE:  runStop,
+	}
+
+	restartCmd := &cobra.Command{
+		Use:   "restart <service>",
+		Short: "Restart a service of the kportforward daemon",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runRestart,
+	}
+
+	rootCmd.AddCommand(startCmd, stopCmd, restartCmd)
+}
+
+func runStart(cmd *cobra.Command, args []string) error {
+	path, err := daemon.SocketPath()
+	if err != nil {
+		return err
+	}
+	if info, err := daemon.NewClient(path).Info(context.Background()); err == nil {
+		return fmt.Errorf("a kportforward daemon is already running (pid %d)", info.PID)
+	}
+
+	if startDaemon {
+		logPath, err := daemon.LogPath()
+		if err != nil {
+			return err
+		}
+		info, err := daemon.Start(path, logPath, args, daemonStartTimeout)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Started the kportforward daemon (pid %d), logging to %s\n", info.PID, logPath)
+		return nil
+	}
+
+	// In the foreground, e.g. as the detached daemon: flags after -- configure the run
+	if err := rootCmd.Flags().Parse(args); err != nil {
+		return err
+	}
+	headless = true
+	controlSocket = true
+	runPortForward(rootCmd, nil)
+	return nil
+}
+
+func runStop(cmd *cobra.Command, args []string) error {
+	client, err := daemonClient()
+	if err != nil {
+		return err
+	}
+	info, err := client.Info(context.Background())
+	if err != nil {
+		return err
+	}
+	if err := client.Stop(context.Background(), daemonStopTimeout); err != nil {
+		return err
+	}
+	fmt.Printf("Stopped the kportforward daemon (pid %d)\n", info.PID)
+	return nil
+}
+
+func runRestart(cmd *cobra.Command, args []string) error {
+	client, err := daemonClient()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := client.Restart(ctx, args[0]); err != nil {
+		if _, infoErr := client.Info(ctx); errors.Is(infoErr, daemon.ErrNotRunning) {
+			return infoErr
+		}
+		return err
+	}
+	fmt.Printf("Restarted %s\n", args[0])
+	return nil
+}
+
+// daemonClient returns a client for the daemon's control socket
+func daemonClient() (*daemon.Client, error) {
+	path, err := daemon.SocketPath()
+	if err != nil {
+		return nil, err
+	}
+	return daemon.NewClient(path), nil
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/hosts"
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+var hostsHelperFile string
+
+func init() {
+	// Privileged helper invoked through sudo by --hosts; not meant to be run directly
+	hostsHelperCmd := &cobra.Command{
+		Use:    "hosts-helper",
+		Short:  "Write kportforward entries (read from stdin) to the hosts file",
+		Hidden: true,
+		Args:   cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			data, err := io.ReadAll(os.Stdin)
+			if err != nil {
+				return fmt.Errorf("failed to read entries: %w", err)
+			}
+			entries, err := hosts.ParseEntries(data)
+			if err != nil {
+				return err
+			}
+			return hosts.WriteEntries(hostsHelperFile, entries)
+		},
+	}
+
+	hostsHelperCmd.Flags().StringVar(&hostsHelperFile, "file", hosts.DefaultPath(), "Hosts file to update")
+
+	rootCmd.AddCommand(hostsHelperCmd)
+}
+
+// applyClusterPorts binds service/ targets on their in-cluster port so cluster DNS names
+// resolve to a working local endpoint
+func applyClusterPorts(cfg *config.Config, logger *utils.Logger) {
+	owners := make(map[int]string)
+	for name, service := range cfg.PortForwards {
+		if len(hosts.ClusterHostnames(service)) == 0 {
+			continue
+		}
+		if owner, taken := owners[service.TargetPort]; taken {
+			logger.Warn("Services %s and %s both use in-cluster port %d; one will be reassigned", owner, name, service.TargetPort)
+		}
+		owners[service.TargetPort] = name
+
+		service.LocalPort = service.TargetPort
+		cfg.PortForwards[name] = service
+	}
+}
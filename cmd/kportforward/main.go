@@ -1,15 +1,35 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
 	"os/signal"
+	"strings"
+	"sync"
 	"syscall"
+	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/victorkazakov/kportforward/internal/adminapi"
+	"github.com/victorkazakov/kportforward/internal/autostart"
+	"github.com/victorkazakov/kportforward/internal/clusterwatch"
 	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/daemon"
+	"github.com/victorkazakov/kportforward/internal/dashboard"
+	"github.com/victorkazakov/kportforward/internal/hosts"
+	"github.com/victorkazakov/kportforward/internal/httpapi"
+	"github.com/victorkazakov/kportforward/internal/i18n"
+	"github.com/victorkazakov/kportforward/internal/journal"
+	"github.com/victorkazakov/kportforward/internal/kubeconfig"
+	"github.com/victorkazakov/kportforward/internal/mdns"
+	"github.com/victorkazakov/kportforward/internal/notify"
 	"github.com/victorkazakov/kportforward/internal/portforward"
+	"github.com/victorkazakov/kportforward/internal/relay"
+	"github.com/victorkazakov/kportforward/internal/sessionreport"
+	"github.com/victorkazakov/kportforward/internal/statusfile"
+	"github.com/victorkazakov/kportforward/internal/telemetry"
 	"github.com/victorkazakov/kportforward/internal/ui"
 	"github.com/victorkazakov/kportforward/internal/ui_handlers"
 	"github.com/victorkazakov/kportforward/internal/updater"
@@ -24,7 +44,29 @@ var (
 	// CLI flags
 	enableGRPCUI    bool
 	enableSwaggerUI bool
+	openBrowser     bool
+	desktopNotify   bool
+	dashboardAddr   string
+	apiPort         int
+	statusFilePath  string
+	advertiseMDNS   bool
+	hostsMode       bool
+	captureTraffic  bool
+	captureDir      string
+	accessLog       bool
+	clusterEvents   bool
+	kubeconfigs     []string
 	logFile         string
+	pprofAddr       string
+	debugAddr       string
+	headless        bool
+	noSync          bool
+	sessionReport   string
+	onlyServices    []string
+	excludeServices []string
+	profileName     string
+	updateChannel   string
+	noUpdateCheck   bool
 
 	// Global root command
 	rootCmd = &cobra.Command{
@@ -40,6 +82,28 @@ Examples:
   # With UI integrations
   kportforward --grpcui --swaggerui
   
+  # Open web services and UIs in the browser once they are running
+  kportforward --open --grpcui --swaggerui
+
+  # Web dashboard and gRPC admin API alongside the TUI
+  kportforward --dashboard-addr localhost:7080
+
+  # Only a few services of a large config
+  kportforward --only api-gateway,flyte-* --exclude flyte-admin
+  kportforward --profile frontend
+
+  # HTTP API for scripts and CI jobs
+  kportforward --api-port 7070
+
+  # Status file for editor plugins, tmux status bars, and shell prompts
+  kportforward --status-file ~/.kportforward/status.json
+
+  # Reach services by their cluster DNS names (e.g., http://api.backend:8080)
+  sudo kportforward --hosts
+
+  # Follow prereleases as well as stable releases
+  kportforward --update-channel beta
+
   # Write logs to file
   kportforward --log-file ./kportforward.log
   
@@ -47,7 +111,15 @@ Examples:
   kportforward --grpcui --swaggerui --log-file /var/log/kportforward.log
 
   # Performance profiling
-  kportforward profile --cpuprofile=cpu.prof --duration=30s`,
+  kportforward profile --cpuprofile=cpu.prof --duration=30s
+
+  # Live profiling of a running session
+  kportforward --pprof-addr localhost:6060
+  go tool pprof http://localhost:6060/debug/pprof/heap
+
+  # Counters and goroutine dump of a running session
+  kportforward --debug-addr localhost:6061
+  curl localhost:6061/debug/vars; curl localhost:6061/debug/goroutines`,
 		Run: runPortForward,
 	}
 )
@@ -57,7 +129,29 @@ func main() {
 	// Add CLI flags
 	rootCmd.Flags().BoolVar(&enableGRPCUI, "grpcui", false, "Enable gRPC UI for RPC services")
 	rootCmd.Flags().BoolVar(&enableSwaggerUI, "swaggerui", false, "Enable Swagger UI for REST services")
+	rootCmd.Flags().BoolVar(&openBrowser, "open", false, "Open web services (and gRPC/Swagger UIs) in the browser once running")
+	rootCmd.Flags().BoolVar(&desktopNotify, "notify", false, "Send desktop notifications when services fail or recover")
+	rootCmd.Flags().StringVar(&dashboardAddr, "dashboard-addr", "", "Serve a web dashboard and gRPC admin API on this address (e.g., --dashboard-addr localhost:7080)")
+	rootCmd.Flags().IntVar(&apiPort, "api-port", 0, "Serve an HTTP API on localhost at this port for scripts and CI: /healthz, /readyz, /services and POST /services/{name}/restart (e.g., --api-port 7070)")
+	rootCmd.Flags().StringVar(&statusFilePath, "status-file", "", "Write the service status as JSON to this path on every change (e.g., --status-file ~/.kportforward/status.json)")
+	rootCmd.Flags().BoolVar(&advertiseMDNS, "mdns", false, "Advertise running services bound to a LAN address (bindAddress) via mDNS/Bonjour")
+	rootCmd.Flags().BoolVar(&hostsMode, "hosts", false, "Add in-cluster service names to the hosts file and bind services on their cluster ports (may prompt for sudo)")
+	rootCmd.Flags().BoolVar(&captureTraffic, "capture", false, "Record HTTP traffic through web and rest services to disk (replay with 'kportforward replay')")
+	rootCmd.Flags().StringVar(&captureDir, "capture-dir", "", "Directory for captured traffic (default: ./kportforward-captures)")
+	rootCmd.Flags().BoolVar(&accessLog, "access-log", false, "Log every connection and HTTP request through each forward (shown in the service detail view)")
+	rootCmd.Flags().BoolVar(&clusterEvents, "cluster-events", false, "Watch the pods behind each forward and report crash loops, OOM kills and rollouts")
+	rootCmd.Flags().StringSliceVar(&kubeconfigs, "kubeconfig", nil, "Kubeconfig files to merge, in order (repeatable; overrides kubeconfigs in the config file)")
+	rootCmd.Flags().StringVar(&pprofAddr, "pprof-addr", "", "Serve net/http/pprof on this address for live profiling (e.g., --pprof-addr localhost:6060)")
+	rootCmd.Flags().StringVar(&debugAddr, "debug-addr", "", "Serve pprof, expvar counters and a goroutine dump on this address to diagnose a wedged session (e.g., --debug-addr localhost:6061)")
 	rootCmd.Flags().StringVar(&logFile, "log-file", "", "Write logs to file instead of stdout (e.g., --log-file ./app.log)")
+	rootCmd.Flags().BoolVar(&headless, "headless", false, "Run without the terminal UI, logging state changes (as when installed with 'kportforward service install')")
+	rootCmd.Flags().StringSliceVar(&onlyServices, "only", nil, "Only run these services; comma-separated names or globs like api-* (e.g., --only api-gateway,flyte-*)")
+	rootCmd.Flags().StringSliceVar(&excludeServices, "exclude", nil, "Don't run these services; comma-separated names or globs")
+	rootCmd.Flags().StringVar(&profileName, "profile", "", "Only run the services of this profile from the config's profiles (switch with P in the TUI)")
+	rootCmd.Flags().StringVar(&sessionReport, "session-report", "", "On shutdown, write a summary of the session to this path, as JSON for .json files and Markdown otherwise")
+	rootCmd.Flags().StringVar(&updateChannel, "update-channel", "", "Release channel update checks follow: "+strings.Join(updater.Channels, ", ")+" (overrides updates.channel in the config)")
+	rootCmd.Flags().BoolVar(&noUpdateCheck, "no-update-check", false, "Don't check for updates, e.g. offline or where the release source is blocked (same as updates.disabled)")
+	rootCmd.Flags().BoolVar(&noSync, "no-sync", false, "Don't pull the team config (sync.repo) on startup; the last pulled one is used")
 
 	rootCmd.AddCommand(&cobra.Command{
 		Use:   "version",
@@ -92,19 +186,97 @@ func initializeLogger(logFile string) (*utils.Logger, error) {
 }
 
 func runPortForward(cmd *cobra.Command, args []string) {
+	// Initialize logger
+	logger, err := initializeLogger(logFile)
+	if err != nil {
+		log.Fatalf("Failed to initialize logger: %v", err)
+	}
+
+	// Pull the team's shared config before loading it
+	if !noSync {
+		pullTeamConfig(logger)
+	}
+
 	// Load configuration
 	cfg, err := config.LoadConfig()
 	if err != nil {
 		log.Fatalf("Failed to load configuration: %v", err)
 	}
-
-	// Initialize logger
-	logger, err := initializeLogger(logFile)
-	if err != nil {
-		log.Fatalf("Failed to initialize logger: %v", err)
+	serviceFilter := config.ServiceFilter{Only: onlyServices, Exclude: excludeServices}
+	if profileName != "" {
+		if serviceFilter.Profile, err = cfg.Profile(profileName); err != nil {
+			log.Fatalf("%v", err)
+		}
+	}
+	if err := serviceFilter.Validate(); err != nil {
+		log.Fatalf("%v", err)
+	}
+	// Fail before the hosts file or anything else outside the process is touched
+	if err := updater.ValidateChannel(updateChannelFor(cfg.Updates)); err != nil {
+		log.Fatalf("%v", err)
 	}
+	applyServiceFilter(cfg, serviceFilter, logger)
 	logger.Info("Starting kportforward with %d services", len(cfg.PortForwards))
 
+	// Merge kubeconfig files before anything runs kubectl
+	if len(kubeconfigs) > 0 {
+		cfg.Kubeconfigs = kubeconfigs
+	}
+	if err := kubeconfig.Apply(cfg.Kubeconfigs); err != nil {
+		log.Fatalf("Failed to load kubeconfigs: %v", err)
+	}
+
+	applyServiceFlags(cfg, logger)
+
+	// Make cluster DNS names (service.namespace) resolve locally, kubefwd-style, along
+	// with host aliases that don't resolve to loopback on their own
+	var hostEntries []hosts.Entry
+	if hostsMode || cfg.Hosts.Enabled {
+		hostEntries = hosts.EntriesFor(cfg.PortForwards)
+	}
+	hostEntries = append(hostEntries, hosts.AliasEntries(cfg.PortForwards)...)
+	var hostsManager *hosts.Manager
+	if len(hostEntries) > 0 {
+		hostsManager = hosts.NewManager(cfg.Hosts.Path, logger)
+		if err := hostsManager.Apply(hostEntries); err != nil {
+			logger.Warn("Failed to update hosts file: %v", err)
+			hostsManager = nil
+		}
+	}
+	// cleanHosts removes the entries again; exits from here on must call it
+	cleanHosts := func() {
+		if hostsManager != nil {
+			if err := hostsManager.Clean(); err != nil {
+				logger.Error("Error cleaning hosts file: %v", err)
+			}
+		}
+	}
+
+	// Record HTTP traffic through the relays
+	if captureTraffic {
+		cfg.Capture.Enabled = true
+	}
+	if captureDir != "" {
+		cfg.Capture.Dir = captureDir
+	}
+	if cfg.Capture.Enabled {
+		dir := cfg.Capture.Dir
+		if dir == "" {
+			dir = relay.DefaultCaptureDir
+		}
+		logger.Info("Capturing HTTP traffic of web and rest services to %s", dir)
+	}
+
+	// Live profiling of the running session with 'go tool pprof'
+	var profiler *pprofServer
+	if pprofAddr != "" {
+		profiler, err = startPprofServer(pprofAddr, logger)
+		if err != nil {
+			logger.Warn("Failed to start pprof server: %v", err)
+			profiler = nil
+		}
+	}
+
 	// Initialize UI handlers
 	var grpcUIManager *ui_handlers.GRPCUIManager
 	var swaggerUIManager *ui_handlers.SwaggerUIManager
@@ -127,42 +299,362 @@ func runPortForward(cmd *cobra.Command, args []string) {
 
 	// Create port forward manager
 	manager := portforward.NewManager(cfg, logger)
+	manager.SetServiceFilter(serviceFilter)
+
+	// Counters and goroutine dumps for diagnosing the running session
+	var debugServer *pprofServer
+	if debugAddr != "" {
+		debugServer, err = startDebugServer(debugAddr, manager, logger)
+		if err != nil {
+			logger.Warn("Failed to start debug server: %v", err)
+			debugServer = nil
+		}
+	}
 
 	// Set UI handlers on the manager
 	manager.SetUIHandlers(grpcUIManager, swaggerUIManager)
 
+	// Auto-open browser tabs for --open or services with autoOpen set
+	if openBrowser || hasAutoOpenServices(cfg) {
+		opener := ui_handlers.NewBrowserOpener(logger, openBrowser)
+		if grpcUIManager != nil {
+			opener.AddURLProvider(grpcUIManager)
+		}
+		if swaggerUIManager != nil {
+			opener.AddURLProvider(swaggerUIManager)
+		}
+		manager.AddUIHandler(opener)
+	}
+
+	// Export lifecycle activity to OpenTelemetry if configured
+	var tracer *telemetry.Tracer
+	if cfg.Telemetry.Enabled {
+		tracer, err = telemetry.NewTracer(context.Background(), cfg.Telemetry, version, logger)
+		if err != nil {
+			logger.Warn("Failed to initialize OpenTelemetry: %v", err)
+			tracer = nil
+		} else {
+			manager.AddEventListener(tracer.HandleEvent)
+		}
+	}
+
+	// Desktop notifications for failures and recoveries
+	var desktopNotifier *notify.DesktopNotifier
+	if desktopNotify || cfg.Notifications.Desktop {
+		desktopNotifier = notify.NewDesktopNotifier(manager.ServiceConfigs, logger)
+		desktopNotifier.Start()
+		manager.AddEventListener(desktopNotifier.HandleEvent)
+	}
+
+	// Webhook/Slack notifications for state changes
+	var webhookNotifier *notify.WebhookNotifier
+	if len(cfg.Notifications.Webhooks) > 0 {
+		webhookNotifier = notify.NewWebhookNotifier(cfg.Notifications.Webhooks, logger)
+		webhookNotifier.Start()
+		manager.AddEventListener(webhookNotifier.HandleEvent)
+	}
+
+	// Local command run on every state change
+	var commandNotifier *notify.CommandNotifier
+	if cfg.Notifications.Command != "" {
+		commandNotifier = notify.NewCommandNotifier(cfg.Notifications.Command, logger)
+		commandNotifier.Start()
+		manager.AddEventListener(commandNotifier.HandleEvent)
+	}
+
+	// Web dashboard mirroring the TUI, with the gRPC admin API on the same port
+	var dashboardServer *dashboard.Server
+	var adminServer *adminapi.Server
+	if dashboardAddr != "" {
+		dashboardServer = dashboard.NewServer(dashboardAddr, manager, logger)
+		adminServer = adminapi.NewServer(manager, logger)
+		dashboardServer.SetGRPCHandler(adminServer.GRPCServer())
+		if grpcUIManager != nil {
+			dashboardServer.AddURLProvider(grpcUIManager)
+		}
+		if swaggerUIManager != nil {
+			dashboardServer.AddURLProvider(swaggerUIManager)
+		}
+		if err := dashboardServer.Start(); err != nil {
+			logger.Warn("Failed to start web dashboard: %v", err)
+			dashboardServer = nil
+			adminServer = nil
+		} else {
+			manager.AddEventListener(dashboardServer.HandleEvent)
+			manager.AddEventListener(adminServer.HandleEvent)
+		}
+	}
+
+	// HTTP API for scripts and CI jobs
+	var apiServer *httpapi.Server
+	if apiPort != 0 {
+		apiServer = httpapi.NewServer(apiPort, manager, logger)
+		if err := apiServer.Start(); err != nil {
+			logger.Warn("Failed to start HTTP API: %v", err)
+			apiServer = nil
+		}
+	}
+
+	// JSON status file for prompts and status bars
+	var statusWriter *statusfile.Writer
+	if statusFilePath == "" {
+		statusFilePath = cfg.StatusFile
+	}
+	if statusFilePath != "" {
+		statusWriter, err = statusfile.NewWriter(statusFilePath, manager, logger)
+		if err != nil {
+			logger.Warn("Failed to set up status file: %v", err)
+			statusWriter = nil
+		} else {
+			logger.Info("Writing status to %s", statusWriter.Path())
+			manager.AddEventListener(statusWriter.HandleEvent)
+		}
+	}
+
+	// mDNS/Bonjour advertisement of services exposed on the LAN
+	var advertiser *mdns.Advertiser
+	if advertiseMDNS || cfg.MDNS.Enabled {
+		advertiser = mdns.NewAdvertiser(manager.ServiceConfigs, cfg.MDNS, logger)
+		if err := advertiser.Start(); err != nil {
+			logger.Warn("Failed to start mDNS advertisement: %v", err)
+			advertiser = nil
+		} else {
+			manager.AddEventListener(advertiser.HandleEvent)
+		}
+	}
+
+	// Session journal for reviewing past sessions with 'kportforward sessions show'
+	var sessionJournal *journal.Journal
+	if journalDir, err := journal.Dir(); err != nil {
+		logger.Warn("Failed to set up session journal: %v", err)
+	} else if sessionJournal, err = journal.Open(journalDir, version); err != nil {
+		logger.Warn("Failed to set up session journal: %v", err)
+		sessionJournal = nil
+	} else {
+		manager.AddEventListener(sessionJournal.HandleEvent)
+	}
+
+	// Summary of the session's uptime, restarts and errors, logged on shutdown
+	sessionCollector := sessionreport.NewCollector(time.Now())
+	manager.AddEventListener(sessionCollector.HandleEvent)
+
+	// Initialize the update manager; checks start once the services are up
+	source, err := releaseSource(cfg.Updates)
+	if err != nil {
+		logger.Warn("Invalid updates config, checking GitHub releases instead: %v", err)
+		source, _ = releaseSource(config.UpdatesConfig{})
+	}
+	updateManager := updater.NewManager(source, version, logger)
+	if err := updateManager.SetChannel(updateChannelFor(cfg.Updates)); err != nil {
+		cleanHosts()
+		log.Fatalf("%v", err)
+	}
+	if err := updateManager.SetVerification(updateVerification(cfg.Updates)); err != nil {
+		logger.Warn("Invalid update verification config; updates will be refused: %v", err)
+	}
+	if err := updateManager.SetNetwork(updateNetwork(cfg.Updates)); err != nil {
+		logger.Warn("Invalid update network config, connecting directly: %v", err)
+	}
+	if tracer != nil {
+		updateManager.SetCheckHook(tracer.RecordUpdateCheck)
+	}
+
 	// Set up signal handling for graceful shutdown
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	autostart.NotifyStop(sigChan) // Stop requests when running as a Windows service
+
+	// Control socket of 'kportforward start', for stop, restart and status
+	var controlServer *daemon.Server
+	if controlSocket {
+		path, err := daemon.SocketPath()
+		if err == nil {
+			info := daemon.Info{PID: os.Getpid(), Started: time.Now(), Version: version}
+			controlServer, err = daemon.Listen(path, httpapi.NewHandler(manager, logger), info, func() {
+				select {
+				case sigChan <- syscall.SIGTERM:
+				default: // Already shutting down
+				}
+			}, logger)
+		}
+		if err != nil {
+			logger.Error("Failed to open the control socket; stop the daemon with a signal instead: %v", err)
+		}
+	}
+
+	locale := i18n.Detect(cfg.UIOptions.Language)
+	if language := cfg.UIOptions.Language; language != "" && locale == i18n.DefaultLocale && !strings.HasPrefix(language, i18n.DefaultLocale) {
+		logger.Warn("Unsupported uiOptions.language %q; supported: %s", language, strings.Join(i18n.Locales(), ", "))
+	}
+	i18n.SetLocale(locale)
+
+	switch cfg.ContextPolicy {
+	case "", "restart", "ask", "ignore":
+	default:
+		logger.Warn("Unknown contextPolicy %q; supported: restart, ask, ignore", cfg.ContextPolicy)
+	}
+
+	// Reloading applies the services of the configuration on disk to the running ones,
+	// from F5 in the TUI or when the config file changes; switching profiles with P in
+	// the TUI reloads with the services of another profile
+	var reloadMutex sync.Mutex
+	activeProfile := profileName
+	applyConfig := func(profile string) (map[string]config.Service, config.ServiceDiff, error) {
+		reloaded, err := config.LoadConfig()
+		if err != nil {
+			return nil, config.ServiceDiff{}, err
+		}
+		filter := serviceFilter
+		filter.Profile = nil
+		if profile != "" {
+			if filter.Profile, err = reloaded.Profile(profile); err != nil {
+				return nil, config.ServiceDiff{}, err
+			}
+			if err := filter.Validate(); err != nil {
+				return nil, config.ServiceDiff{}, err
+			}
+		}
+		manager.SetServiceFilter(filter)
+		applyServiceFilter(reloaded, filter, logger)
+		applyServiceFlags(reloaded, logger)
+		return reloaded.PortForwards, manager.ApplyServices(reloaded.PortForwards), nil
+	}
+	reloadConfig := func() (map[string]config.Service, config.ServiceDiff, error) {
+		reloadMutex.Lock()
+		defer reloadMutex.Unlock()
+		return applyConfig(activeProfile)
+	}
+	switchProfile := func(profile string) (map[string]config.Service, config.ServiceDiff, error) {
+		reloadMutex.Lock()
+		defer reloadMutex.Unlock()
+		services, diff, err := applyConfig(profile)
+		if err != nil {
+			return nil, config.ServiceDiff{}, err
+		}
+		activeProfile = profile
+		if profile == "" {
+			logger.Info("Switched to all services: %s", diff)
+		} else {
+			logger.Info("Switched to profile %s: %s", profile, diff)
+		}
+		return services, diff, nil
+	}
 
-	// Start port forwarding
+	// Initialize and start TUI; it shows progress while the services start
+	// The subscription lasts until the manager stops
+	var tui *ui.TUI
+	if headless {
+		if cfg.ContextPolicy == "ask" {
+			logger.Warn("contextPolicy ask needs the terminal UI to answer; headless, the services stay in their context as with ignore")
+		}
+		manager.AddEventListener(logStateChange(logger))
+	} else {
+		statusUpdates, _ := manager.Subscribe()
+		tui = ui.NewTUI(statusUpdates, cfg.PortForwards)
+		tui.SetController(manager)
+		tui.SetConfigReloader(reloadConfig)
+		tui.SetProfiles(cfg.ProfileNames(), profileName, switchProfile)
+		tui.SetUpdateDownloader(func(progress updater.ProgressFunc) (string, error) {
+			updateInfo := updateManager.GetLastUpdateInfo()
+			if updateInfo == nil || !updateInfo.Available {
+				return "", fmt.Errorf("no update available")
+			}
+			return updateManager.PrepareUpdate(updateInfo, progress)
+		})
+		if err := tui.Start(); err != nil {
+			logger.Error("Failed to start TUI: %v", err)
+			cleanHosts()
+			os.Exit(1)
+		}
+		manager.AddEventListener(func(event portforward.Event) {
+			switch event.Type {
+			case portforward.EventStartupProgress:
+				tui.UpdateStartupProgress(event.Started, event.Total)
+			case portforward.EventRestartProgress:
+				tui.UpdateRestartProgress(event.Started, event.Total)
+			case portforward.EventContextChanged:
+				tui.UpdateKubernetesContext(event.Context)
+			case portforward.EventContextChangePending:
+				tui.PromptContextChange(event.Context, event.PreviousContext)
+			}
+		})
+	}
+
+	// Start port forwarding. Headless, the monitor keeps retrying services that failed;
+	// only when nothing could be started (e.g. no Kubernetes context) does it exit, so
+	// the service manager retries.
 	if err := manager.Start(); err != nil {
-		logger.Error("Failed to start port forwarding: %v", err)
-		os.Exit(1)
+		if headless && len(manager.GetCurrentStatus()) > 0 {
+			logger.Warn("%v", err)
+		} else {
+			if tui != nil {
+				tui.Stop()
+			}
+			logger.Error("Failed to start port forwarding: %v", err)
+			cleanHosts()
+			os.Exit(1)
+		}
+	}
+	if sessionJournal != nil {
+		sessionJournal.HandleEvent(portforward.Event{Type: portforward.EventContextChanged, Context: manager.GetKubernetesContext()})
+	}
+	sessionCollector.HandleEvent(portforward.Event{Type: portforward.EventContextChanged, Context: manager.GetKubernetesContext()})
+
+	// Apply edits of the config file without a restart
+	configWatcher, err := config.WatchUserConfig(func() {
+		services, diff, err := reloadConfig()
+		switch {
+		case err != nil:
+			logger.Warn("Config file changed but can't be loaded: %v", err)
+		case diff.Empty():
+			return
+		default:
+			logger.Info("Config file changed, reloaded: %s", diff)
+		}
+		if tui != nil {
+			tui.NotifyConfigReloaded(services, diff, err)
+		}
+	}, func(err error) {
+		logger.Warn("Config file watch: %v", err)
+	})
+	if err != nil {
+		logger.Warn("Config changes won't be applied live: %v", err)
 	}
 
-	// Initialize and start update manager
-	updateManager := updater.NewManager("catio-tech", "kportforward", version, logger)
-	if err := updateManager.Start(); err != nil {
+	// Start update checks, unless disabled; the updater makes no requests without them
+	if noUpdateCheck || cfg.Updates.Disabled {
+		logger.Info("Update checks are disabled")
+	} else if err := updateManager.Start(); err != nil {
 		logger.Error("Failed to start update manager: %v", err)
 		// Don't exit - updates are not critical
 	}
 
-	// Initialize and start TUI
-	tui := ui.NewTUI(manager.GetStatusChannel(), cfg.PortForwards)
-	if err := tui.Start(); err != nil {
-		logger.Error("Failed to start TUI: %v", err)
-		os.Exit(1)
+	// Update TUI with initial context
+	if tui != nil {
+		tui.UpdateKubernetesContext(manager.GetKubernetesContext())
 	}
 
-	// Update TUI with initial context
-	tui.UpdateKubernetesContext(manager.GetKubernetesContext())
+	// Report pod trouble behind the forwards, which is usually why a forward keeps dying
+	var clusterWatcher *clusterwatch.Watcher
+	if clusterEvents || cfg.ClusterEvents.Enabled {
+		clusterWatcher = clusterwatch.NewWatcher(manager.ServiceConfigs, cfg.ClusterEvents.Interval, logger, func(event clusterwatch.Event) {
+			logger.Warn("Cluster event for %s", event)
+			if tui != nil {
+				tui.AddClusterEvent(event)
+			}
+		})
+		clusterWatcher.Start()
+	}
 
 	// Listen for update notifications
 	go func() {
 		updateChan := updateManager.GetUpdateChannel()
 		for updateInfo := range updateChan {
-			tui.NotifyUpdateAvailable(updateInfo)
+			if tui != nil {
+				tui.NotifyUpdateAvailable(updateInfo)
+			} else {
+				logger.Info("Update available: %s (run 'kportforward update')", updateInfo.LatestVersion)
+			}
 		}
 	}()
 
@@ -175,8 +667,34 @@ func runPortForward(cmd *cobra.Command, args []string) {
 		logger.Error("Error stopping update manager: %v", err)
 	}
 
-	if err := tui.Stop(); err != nil {
-		logger.Error("Error stopping TUI: %v", err)
+	if clusterWatcher != nil {
+		clusterWatcher.Stop()
+	}
+
+	if configWatcher != nil {
+		configWatcher.Close()
+	}
+
+	if tui != nil {
+		if err := tui.Stop(); err != nil {
+			logger.Error("Error stopping TUI: %v", err)
+		}
+	}
+
+	if adminServer != nil {
+		adminServer.Stop()
+	}
+
+	if dashboardServer != nil {
+		if err := dashboardServer.Stop(); err != nil {
+			logger.Error("Error stopping web dashboard: %v", err)
+		}
+	}
+
+	if apiServer != nil {
+		if err := apiServer.Stop(); err != nil {
+			logger.Error("Error stopping HTTP API: %v", err)
+		}
 	}
 
 	// Stop UI handlers explicitly
@@ -192,11 +710,82 @@ func runPortForward(cmd *cobra.Command, args []string) {
 		}
 	}
 
+	if sessionJournal != nil {
+		if err := sessionJournal.Close(); err != nil {
+			logger.Error("Error closing session journal: %v", err)
+		}
+	}
+
+	report := sessionCollector.Report(time.Now())
+	report.Log(logger)
+	if sessionReport != "" {
+		if err := report.Write(sessionReport); err != nil {
+			logger.Error("%v", err)
+		} else {
+			logger.Info("Session report written to %s", sessionReport)
+		}
+	}
+
 	if err := manager.Stop(); err != nil {
 		logger.Error("Error during shutdown: %v", err)
+		cleanHosts()
 		os.Exit(1)
 	}
 
+	cleanHosts()
+
+	if advertiser != nil {
+		if err := advertiser.Stop(); err != nil {
+			logger.Error("Error stopping mDNS advertisement: %v", err)
+		}
+	}
+
+	if statusWriter != nil {
+		if err := statusWriter.Remove(); err != nil {
+			logger.Error("Error removing status file: %v", err)
+		}
+	}
+
+	if desktopNotifier != nil {
+		desktopNotifier.Stop()
+	}
+
+	if webhookNotifier != nil {
+		webhookNotifier.Stop()
+	}
+
+	if commandNotifier != nil {
+		commandNotifier.Stop()
+	}
+
+	if profiler != nil {
+		if err := profiler.Stop(); err != nil {
+			logger.Error("Error stopping pprof server: %v", err)
+		}
+	}
+
+	if debugServer != nil {
+		if err := debugServer.Stop(); err != nil {
+			logger.Error("Error stopping debug server: %v", err)
+		}
+	}
+
+	// Flush pending spans
+	if tracer != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		if err := tracer.Shutdown(ctx); err != nil {
+			logger.Error("Error flushing OpenTelemetry spans: %v", err)
+		}
+		cancel()
+	}
+
+	// Last, so 'kportforward stop' returns once everything is down
+	if controlServer != nil {
+		if err := controlServer.Stop(); err != nil {
+			logger.Error("Error closing control socket: %v", err)
+		}
+	}
+
 	logger.Info("Shutdown complete")
 
 	// Close log file if it was opened
@@ -205,6 +794,60 @@ func runPortForward(cmd *cobra.Command, args []string) {
 	}
 }
 
+// applyServiceFlags applies the command-line options that change individual
+// services, at startup and when the configuration is reloaded
+func applyServiceFlags(cfg *config.Config, logger *utils.Logger) {
+	// Bind services on their cluster ports so their cluster DNS names work
+	if hostsMode || cfg.Hosts.Enabled {
+		applyClusterPorts(cfg, logger)
+	}
+
+	// Access logging runs in the relay in front of each forward
+	if accessLog {
+		for name, service := range cfg.PortForwards {
+			service.AccessLog = true
+			cfg.PortForwards[name] = service
+		}
+	}
+}
+
+// applyServiceFilter drops the services --only, --exclude and the profile leave out
+func applyServiceFilter(cfg *config.Config, filter config.ServiceFilter, logger *utils.Logger) {
+	if filter.IsZero() {
+		return
+	}
+	selected := filter.Apply(cfg.PortForwards)
+	if len(selected) == 0 {
+		logger.Warn("No configured service is selected by --only, --exclude and the profile")
+	}
+	cfg.PortForwards = selected
+}
+
+// logStateChange returns an event listener logging service state changes, which the
+// TUI shows otherwise
+func logStateChange(logger *utils.Logger) portforward.EventListener {
+	return func(event portforward.Event) {
+		if event.Type != portforward.EventServiceStateChanged {
+			return
+		}
+		if event.Status.Status == "Running" || event.Status.LastError == "" {
+			logger.Info("%s: %s -> %s", event.Service, event.PreviousStatus, event.Status.Status)
+		} else {
+			logger.Warn("%s: %s -> %s (%s)", event.Service, event.PreviousStatus, event.Status.Status, event.Status.LastError)
+		}
+	}
+}
+
+// hasAutoOpenServices reports whether any service requests opening in the browser
+func hasAutoOpenServices(cfg *config.Config) bool {
+	for _, service := range cfg.PortForwards {
+		if service.AutoOpen {
+			return true
+		}
+	}
+	return false
+}
+
 func displayStatus(status map[string]config.ServiceStatus, kubeContext string) {
 	fmt.Printf("\n=== kportforward Status (Context: %s) ===\n", kubeContext)
 	fmt.Printf("%-25s %-10s %-8s %-8s %-10s %s\n",
@@ -226,3 +869,56 @@ func displayStatus(status map[string]config.ServiceStatus, kubeContext string) {
 			name, svc.Status, svc.LocalPort, svc.PID, uptime, errorMsg)
 	}
 }
+
+// releaseSource builds the updater's release source from the updates config,
+// defaulting to the project's GitHub releases
+func releaseSource(cfg config.UpdatesConfig) (updater.ReleaseSource, error) {
+	token := cfg.Token
+	if cfg.TokenEnv != "" {
+		if value := os.Getenv(cfg.TokenEnv); value != "" {
+			token = value
+		}
+	}
+
+	repo := cfg.Repo
+	if repo == "" && (cfg.Provider == "" || cfg.Provider == "github") {
+		repo = "catio-tech/kportforward"
+	}
+
+	return updater.NewSource(updater.SourceConfig{
+		Provider: cfg.Provider,
+		URL:      cfg.URL,
+		Repo:     repo,
+		Token:    token,
+	})
+}
+
+// updateChannelFor returns the update channel of --update-channel or else the updates config
+func updateChannelFor(cfg config.UpdatesConfig) string {
+	if updateChannel != "" {
+		return updateChannel
+	}
+	return cfg.Channel
+}
+
+// updateNetwork builds the updater's proxy and CA settings from the updates config
+func updateNetwork(cfg config.UpdatesConfig) updater.NetworkConfig {
+	caBundle := cfg.CABundle
+	if expanded, err := utils.ExpandPath(caBundle); err == nil {
+		caBundle = expanded
+	}
+	return updater.NetworkConfig{Proxy: cfg.Proxy, CABundle: caBundle}
+}
+
+// updateVerification builds the updater's download verification from the updates config
+func updateVerification(cfg config.UpdatesConfig) updater.Verification {
+	publicKey := cfg.PublicKey
+	if expanded, err := utils.ExpandPath(publicKey); err == nil {
+		publicKey = expanded
+	}
+	return updater.Verification{
+		Signature:       cfg.Signature,
+		PublicKey:       publicKey,
+		AllowUnverified: cfg.AllowUnverified,
+	}
+}
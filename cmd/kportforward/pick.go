@@ -0,0 +1,183 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/spf13/cobra"
+	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/kubeconfig"
+	"github.com/victorkazakov/kportforward/internal/pick"
+	"github.com/victorkazakov/kportforward/internal/portforward"
+	"github.com/victorkazakov/kportforward/internal/ui"
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+var (
+	pickNamespace     string
+	pickAllNamespaces bool
+	pickContext       string
+	pickLocalPort     int
+	pickName          string
+	pickSave          bool
+)
+
+func init() {
+	pickCmd := &cobra.Command{
+		Use:   "pick",
+		Short: "Pick a service or pod with a fuzzy finder and forward it",
+		Long: `List the services and running pods in the current context, filter them by typing,
+choose one and a port, and forward it right away with the same monitoring and
+restarts as configured services. Press Ctrl+C to stop the forward. With --save the
+forward is also added to the user config.
+
+Examples:
+  kportforward pick
+  kportforward pick -n payments --save
+  kportforward pick -A --local-port 15432 --name orders-db`,
+		Args: cobra.NoArgs,
+		RunE: runPick,
+	}
+
+	pickCmd.Flags().StringVarP(&pickNamespace, "namespace", "n", "", "Namespace to list (default: the context's namespace)")
+	pickCmd.Flags().BoolVarP(&pickAllNamespaces, "all-namespaces", "A", false, "List every namespace")
+	pickCmd.Flags().StringVar(&pickContext, "context", "", "Kubeconfig context (default: the current context)")
+	pickCmd.Flags().IntVar(&pickLocalPort, "local-port", 0, "Local port (default: the target port, or the next free port)")
+	pickCmd.Flags().StringVar(&pickName, "name", "", "Service name (default: the resource's name)")
+	pickCmd.Flags().BoolVar(&pickSave, "save", false, "Add the forward to the user config")
+
+	rootCmd.AddCommand(pickCmd)
+}
+
+func runPick(cmd *cobra.Command, args []string) error {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if err := kubeconfig.Apply(cfg.Kubeconfigs); err != nil {
+		return fmt.Errorf("failed to load kubeconfigs: %w", err)
+	}
+
+	scope := cfg.WithServiceDefaults(config.Service{Namespace: pickNamespace, Context: pickContext})
+	candidates, err := pick.List(scope, pickAllNamespaces)
+	if err != nil {
+		return err
+	}
+	if len(candidates) == 0 {
+		return fmt.Errorf("no services or running pods found")
+	}
+
+	candidate, targetPort, err := ui.RunPicker(candidates)
+	if errors.Is(err, ui.ErrPickCancelled) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	localPort := pickLocalPort
+	if localPort == 0 {
+		if localPort, err = pickFreePort(cfg, targetPort); err != nil {
+			return err
+		}
+	}
+
+	name := pickName
+	if name == "" {
+		name = uniqueServiceName(cfg, candidate)
+	}
+	service := config.Service{
+		Target:     candidate.Target(),
+		TargetPort: targetPort,
+		LocalPort:  localPort,
+		Namespace:  candidate.Namespace,
+		Context:    pickContext,
+	}
+
+	if pickSave {
+		if err := config.SaveService(name, service); err != nil {
+			return fmt.Errorf("failed to save %s: %w", name, err)
+		}
+		fmt.Printf("Saved %s to the user config\n", name)
+	}
+
+	return runPickedForward(cfg, name, service)
+}
+
+// runPickedForward forwards a single service with a manager until interrupted,
+// printing its state changes
+func runPickedForward(cfg *config.Config, name string, service config.Service) error {
+	session := *cfg
+	session.PortForwards = map[string]config.Service{name: service}
+
+	logger := utils.NewLogger(utils.LevelWarn)
+	manager := portforward.NewManager(&session, logger)
+	manager.AddEventListener(func(event portforward.Event) {
+		if event.Type != portforward.EventServiceStateChanged {
+			return
+		}
+		if event.Status.LastError != "" && event.Status.Status != "Running" {
+			fmt.Printf("%s: %s (%s)\n", name, event.Status.Status, event.Status.LastError)
+		} else {
+			fmt.Printf("%s: %s\n", name, event.Status.Status)
+		}
+	})
+
+	fmt.Printf("Forwarding localhost:%d -> %s/%s:%d (Ctrl+C to stop)\n",
+		service.LocalPort, service.Namespace, service.Target, service.TargetPort)
+	if err := manager.Start(); err != nil {
+		// The monitor keeps retrying a forward that failed to start
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigChan)
+	<-sigChan
+
+	fmt.Println("\nStopping...")
+	return manager.Stop()
+}
+
+// pickFreePort returns the target port if it is free to use locally, or the next
+// free port that no configured service uses; privileged ports start from 8000 above
+func pickFreePort(cfg *config.Config, targetPort int) (int, error) {
+	used := make(map[int]bool, len(cfg.PortForwards))
+	for _, service := range cfg.PortForwards {
+		used[service.LocalPort] = true
+	}
+
+	port := targetPort
+	if port < 1024 {
+		port += 8000
+	}
+	for {
+		free, err := utils.FindAvailablePort(port)
+		if err != nil {
+			return 0, fmt.Errorf("no free local port: %w", err)
+		}
+		if !used[free] {
+			return free, nil
+		}
+		port = free + 1
+	}
+}
+
+// uniqueServiceName names a picked forward after its resource, qualified by the
+// namespace (and then a number) if a configured service already has the name
+func uniqueServiceName(cfg *config.Config, candidate pick.Candidate) string {
+	name := candidate.Name
+	if _, exists := cfg.PortForwards[name]; !exists {
+		return name
+	}
+	name = candidate.Name + "-" + candidate.Namespace
+	for i := 2; ; i++ {
+		if _, exists := cfg.PortForwards[name]; !exists {
+			return name
+		}
+		name = fmt.Sprintf("%s-%s-%d", candidate.Name, candidate.Namespace, i)
+	}
+}
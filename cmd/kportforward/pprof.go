@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"time"
+
+	"github.com/victorkazakov/kportforward/internal/portforward"
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+// pprofServer serves net/http/pprof, and with --debug-addr also expvar counters and a
+// goroutine dump, for inspecting a running session
+type pprofServer struct {
+	server *http.Server
+}
+
+// pprofMux returns a mux serving the pprof endpoints under /debug/pprof/
+func pprofMux() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	return mux
+}
+
+// startPprofServer serves the pprof endpoints under /debug/pprof/ on addr
+func startPprofServer(addr string, logger *utils.Logger) (*pprofServer, error) {
+	server, listenAddr, err := serveDebugMux(addr, pprofMux(), logger)
+	if err != nil {
+		return nil, err
+	}
+	logger.Info("Serving pprof on http://%s/debug/pprof/", listenAddr)
+	return server, nil
+}
+
+// startDebugServer serves pprof, the manager's counters with the runtime's expvars
+// under /debug/vars and a dump of all goroutines under /debug/goroutines on addr
+func startDebugServer(addr string, manager *portforward.Manager, logger *utils.Logger) (*pprofServer, error) {
+	mux := pprofMux()
+	expvar.Publish("kportforward", expvar.Func(func() any { return manager.DebugVars() }))
+	mux.Handle("/debug/vars", expvar.Handler())
+	mux.HandleFunc("/debug/goroutines", handleGoroutineDump)
+
+	server, listenAddr, err := serveDebugMux(addr, mux, logger)
+	if err != nil {
+		return nil, err
+	}
+	logger.Info("Serving debug endpoints on http://%s/debug/ (pprof/, vars, goroutines)", listenAddr)
+	return server, nil
+}
+
+// handleGoroutineDump writes the stacks of all goroutines as plain text
+func handleGoroutineDump(w http.ResponseWriter, r *http.Request) {
+	buf := make([]byte, 1<<20)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Write(buf)
+}
+
+// serveDebugMux serves mux on addr in the background and returns the address it listens on
+func serveDebugMux(addr string, mux *http.ServeMux, logger *utils.Logger) (*pprofServer, net.Addr, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("Debug server stopped: %v", err)
+		}
+	}()
+	return &pprofServer{server: server}, listener.Addr(), nil
+}
+
+// Stop shuts the server down
+func (p *pprofServer) Stop() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return p.server.Shutdown(ctx)
+}
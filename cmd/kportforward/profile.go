@@ -1,15 +1,23 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
+	"os/signal"
 	"runtime"
 	"runtime/pprof"
+	"runtime/trace"
+	"sort"
+	"sync"
+	"syscall"
 	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/victorkazakov/kportforward/internal/bench"
 	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/kubeconfig"
 	"github.com/victorkazakov/kportforward/internal/portforward"
 	"github.com/victorkazakov/kportforward/internal/utils"
 )
@@ -17,7 +25,13 @@ import (
 var (
 	cpuProfile      string
 	memProfile      string
+	blockProfile    string
+	mutexProfile    string
+	traceProfile    string
+	goroutineDump   string
 	profileDuration time.Duration
+	profileReal     bool
+	profileWorkers  int
 )
 
 func init() {
@@ -25,13 +39,21 @@ func init() {
 		Use:   "profile",
 		Short: "Run performance profiling",
 		Long: `Run performance profiling to analyze CPU and memory usage.
-This command runs the port forward manager for a specified duration while collecting profiling data.`,
+This command runs the port forward manager for a specified duration while collecting profiling data.
+By default the manager's status polling is simulated without starting any forward; with --real the
+configured forwards are started and traffic is driven through each running one for the duration.`,
 		Run: runProfiling,
 	}
 
 	profileCmd.Flags().StringVar(&cpuProfile, "cpuprofile", "", "Write CPU profile to file")
 	profileCmd.Flags().StringVar(&memProfile, "memprofile", "", "Write memory profile to file")
+	profileCmd.Flags().StringVar(&blockProfile, "blockprofile", "", "Write goroutine blocking profile to file")
+	profileCmd.Flags().StringVar(&mutexProfile, "mutexprofile", "", "Write mutex contention profile to file")
+	profileCmd.Flags().StringVar(&traceProfile, "traceprofile", "", "Write execution trace to file (view with 'go tool trace')")
+	profileCmd.Flags().StringVar(&goroutineDump, "goroutinedump", "", "Write the stacks of all goroutines to file at the end of the run")
 	profileCmd.Flags().DurationVar(&profileDuration, "duration", 30*time.Second, "Duration to run profiling")
+	profileCmd.Flags().BoolVar(&profileReal, "real", false, "Start the configured forwards and drive traffic through them instead of simulating")
+	profileCmd.Flags().IntVar(&profileWorkers, "workers", 2, "Concurrent traffic workers per service with --real")
 
 	rootCmd.AddCommand(profileCmd)
 }
@@ -54,6 +76,29 @@ func runProfiling(cmd *cobra.Command, args []string) {
 		fmt.Printf("CPU profiling enabled, writing to %s\n", cpuProfile)
 	}
 
+	// Record every blocking event and mutex contention while profiling
+	if blockProfile != "" {
+		runtime.SetBlockProfileRate(1)
+	}
+	if mutexProfile != "" {
+		runtime.SetMutexProfileFraction(1)
+	}
+
+	// Start execution tracing if requested
+	if traceProfile != "" {
+		f, err := os.Create(traceProfile)
+		if err != nil {
+			log.Fatalf("Could not create trace file: %v", err)
+		}
+		defer f.Close()
+
+		if err := trace.Start(f); err != nil {
+			log.Fatalf("Could not start trace: %v", err)
+		}
+		defer trace.Stop()
+		fmt.Printf("Execution tracing enabled, writing to %s\n", traceProfile)
+	}
+
 	// Load configuration
 	cfg, err := config.LoadConfig()
 	if err != nil {
@@ -67,8 +112,11 @@ func runProfiling(cmd *cobra.Command, args []string) {
 	// Create port forward manager
 	manager := portforward.NewManager(cfg, logger)
 
-	// Simulate workload
-	simulateWorkload(manager, logger)
+	if profileReal {
+		runRealWorkload(cfg, manager, logger)
+	} else {
+		simulateWorkload(manager, logger)
+	}
 
 	// Write memory profile if requested
 	if memProfile != "" {
@@ -85,6 +133,10 @@ func runProfiling(cmd *cobra.Command, args []string) {
 		fmt.Printf("Memory profiling enabled, writing to %s\n", memProfile)
 	}
 
+	writeProfile("block", blockProfile, 0)
+	writeProfile("mutex", mutexProfile, 0)
+	writeProfile("goroutine", goroutineDump, 2)
+
 	printMemoryStats()
 	fmt.Println("Profiling completed successfully")
 }
@@ -119,6 +171,111 @@ func simulateWorkload(manager *portforward.Manager, logger *utils.Logger) {
 	fmt.Println("Workload simulation completed")
 }
 
+// runRealWorkload starts the configured forwards and drives traffic through every
+// running one for the profiling duration
+func runRealWorkload(cfg *config.Config, manager *portforward.Manager, logger *utils.Logger) {
+	if err := kubeconfig.Apply(cfg.Kubeconfigs); err != nil {
+		log.Fatalf("Failed to load kubeconfigs: %v", err)
+	}
+
+	fmt.Println("Starting port-forwards...")
+	if err := manager.Start(); err != nil {
+		log.Fatalf("Failed to start port forwarding: %v", err)
+	}
+	defer func() {
+		if err := manager.Stop(); err != nil {
+			logger.Error("Error stopping port forwarding: %v", err)
+		}
+	}()
+
+	ports := waitForRunning(manager, 30*time.Second)
+	if len(ports) == 0 {
+		fmt.Println("No port-forward became ready; check the configuration and cluster access")
+		return
+	}
+	fmt.Printf("Driving traffic through %d of %d services for %v...\n", len(ports), len(cfg.PortForwards), profileDuration)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigChan)
+	go func() {
+		select {
+		case <-sigChan:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	results := make(map[string]*bench.Result, len(ports))
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+	for name, port := range ports {
+		mode := bench.ModeTCP
+		if service := cfg.PortForwards[name]; service.IsHTTP() {
+			mode = bench.ModeHTTP
+		}
+
+		wg.Add(1)
+		go func(name string, port int, mode bench.Mode) {
+			defer wg.Done()
+			result, err := bench.Run(ctx, bench.Options{
+				Port:        port,
+				Mode:        mode,
+				Duration:    profileDuration,
+				Concurrency: profileWorkers,
+			})
+			if err != nil {
+				logger.Warn("Traffic for %s failed: %v", name, err)
+				return
+			}
+			mutex.Lock()
+			results[name] = result
+			mutex.Unlock()
+		}(name, port, mode)
+	}
+	wg.Wait()
+
+	printWorkloadResults(results)
+}
+
+// waitForRunning waits until every service is running or the timeout expires and
+// returns the local ports of the running ones
+func waitForRunning(manager *portforward.Manager, timeout time.Duration) map[string]int {
+	deadline := time.Now().Add(timeout)
+	for {
+		status := manager.GetCurrentStatus()
+		ports := make(map[string]int)
+		for name, service := range status {
+			if service.Status == "Running" {
+				ports[name] = service.LocalPort
+			}
+		}
+		if len(ports) == len(status) || time.Now().After(deadline) {
+			return ports
+		}
+		time.Sleep(250 * time.Millisecond)
+	}
+}
+
+// printWorkloadResults summarizes the traffic driven through each service
+func printWorkloadResults(results map[string]*bench.Result) {
+	names := make([]string, 0, len(results))
+	for name := range results {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Printf("\n=== Traffic ===\n")
+	for _, name := range names {
+		result := results[name]
+		fmt.Printf("%-30s %6d requests (%.1f/s), %.2f%% errors, p50 %v, p99 %v\n",
+			name, result.Requests, result.Throughput(), result.ErrorRate()*100,
+			result.Percentile(50), result.Percentile(99))
+	}
+}
+
 func processServices(status map[string]config.ServiceStatus) {
 	// Simulate processing of service status
 	for name, svc := range status {
@@ -127,6 +284,24 @@ func processServices(status map[string]config.ServiceStatus) {
 	}
 }
 
+// writeProfile writes a named runtime profile to a file, if a path was given; debug 2
+// writes goroutine stacks as text
+func writeProfile(name, path string, debug int) {
+	if path == "" {
+		return
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		log.Fatalf("Could not create %s profile: %v", name, err)
+	}
+	defer f.Close()
+
+	if err := pprof.Lookup(name).WriteTo(f, debug); err != nil {
+		log.Fatalf("Could not write %s profile: %v", name, err)
+	}
+	fmt.Printf("Wrote %s profile to %s\n", name, path)
+}
+
 func printMemoryStats() {
 	var m runtime.MemStats
 	runtime.ReadMemStats(&m)
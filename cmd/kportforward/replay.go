@@ -0,0 +1,110 @@
+package main
+
+import (
+	"crypto/tls"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/victorkazakov/kportforward/internal/capture"
+)
+
+var (
+	replayTarget   string
+	replayMatch    string
+	replayInsecure bool
+	replayTimeout  time.Duration
+	replayHAR      string
+)
+
+func init() {
+	replayCmd := &cobra.Command{
+		Use:   "replay <capture-file>",
+		Short: "Resend HTTP requests recorded with --capture",
+		Long: `Resend the requests in a capture file written by 'kportforward --capture' (or any
+HAR file) and compare each response status with the recorded one.
+
+Examples:
+  kportforward replay kportforward-captures/api-20250101-120000.jsonl
+  kportforward replay api.jsonl --target http://localhost:9090 --match /v1/orders
+  kportforward replay api.jsonl --har api.har`,
+		Args: cobra.ExactArgs(1),
+		Run:  runReplay,
+	}
+
+	replayCmd.Flags().StringVar(&replayTarget, "target", "", "Base URL to send requests to instead of the recorded host (e.g., http://localhost:9090)")
+	replayCmd.Flags().StringVar(&replayMatch, "match", "", "Only replay requests whose URL contains this string")
+	replayCmd.Flags().BoolVar(&replayInsecure, "insecure", false, "Skip TLS certificate verification (e.g., for localTLS services)")
+	replayCmd.Flags().DurationVar(&replayTimeout, "timeout", 30*time.Second, "Per-request timeout")
+	replayCmd.Flags().StringVar(&replayHAR, "har", "", "Convert the capture to a HAR file at this path instead of replaying")
+
+	rootCmd.AddCommand(replayCmd)
+}
+
+func runReplay(cmd *cobra.Command, args []string) {
+	entries, err := capture.Load(args[0])
+	if err != nil {
+		log.Fatalf("Failed to load capture: %v", err)
+	}
+
+	var selected []capture.Entry
+	for _, entry := range entries {
+		if replayMatch == "" || strings.Contains(entry.Request.URL, replayMatch) {
+			selected = append(selected, entry)
+		}
+	}
+	if len(selected) == 0 {
+		fmt.Println("No captured requests to replay")
+		return
+	}
+
+	if replayHAR != "" {
+		if err := capture.WriteHAR(replayHAR, selected, version); err != nil {
+			log.Fatalf("Failed to write HAR: %v", err)
+		}
+		fmt.Printf("Wrote %d entries to %s\n", len(selected), replayHAR)
+		return
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if replayInsecure {
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+	replayer := &capture.Replayer{
+		Client: &http.Client{
+			Transport: transport,
+			Timeout:   replayTimeout,
+			// Report redirects as recorded instead of following them
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		Target: replayTarget,
+	}
+
+	mismatches := 0
+	for _, entry := range selected {
+		result := replayer.Replay(entry)
+		marker := "✓"
+		if !result.Matches() {
+			marker = "✗"
+			mismatches++
+		}
+
+		if result.Err != nil {
+			fmt.Printf("%s %-6s %s  error: %v\n", marker, result.Method, result.URL, result.Err)
+			continue
+		}
+		fmt.Printf("%s %-6s %s  %d (recorded %d)  %v\n", marker, result.Method, result.URL,
+			result.Status, result.RecordedStatus, result.Duration.Round(time.Millisecond))
+	}
+
+	fmt.Printf("\nReplayed %d requests, %d differed from the recording\n", len(selected), mismatches)
+	if mismatches > 0 {
+		os.Exit(1)
+	}
+}
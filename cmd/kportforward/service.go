@@ -0,0 +1,90 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/victorkazakov/kportforward/internal/autostart"
+)
+
+func init() {
+	serviceCmd := &cobra.Command{
+		Use:   "service",
+		Short: "Run kportforward in the background as a system service",
+		Long: `Register kportforward with the platform's service manager so the forwards are up
+without a terminal left open: a systemd user unit on Linux, a LaunchAgent on macOS,
+or a Windows service (install from an administrator prompt). The service runs
+'kportforward --headless' with the user config, restarts it when it fails, and
+starts it at login (at boot on Windows). Flags after -- are passed to kportforward.
+
+Examples:
+  kportforward service install
+  kportforward service install -- --dashboard-addr localhost:7080 --status-file ~/.kportforward/status.json
+  kportforward service status
+  kportforward service uninstall`,
+	}
+
+	installCmd := &cobra.Command{
+		Use:   "install [-- flags...]",
+		Short: "Install and start kportforward as a service",
+		RunE:  runServiceInstall,
+	}
+
+	uninstallCmd := &cobra.Command{
+		Use:   "uninstall",
+		Short: "Stop and remove the kportforward service",
+		Args:  cobra.NoArgs,
+		RunE:  runServiceUninstall,
+	}
+
+	statusCmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show whether the kportforward service is installed and running",
+		Args:  cobra.NoArgs,
+		RunE:  runServiceStatus,
+	}
+
+	serviceCmd.AddCommand(installCmd, uninstallCmd, statusCmd)
+	rootCmd.AddCommand(serviceCmd)
+}
+
+func runServiceInstall(cmd *cobra.Command, args []string) error {
+	for _, arg := range args {
+		if arg == "--headless" {
+			return fmt.Errorf("--headless is always set for the service")
+		}
+	}
+
+	spec, err := autostart.NewSpec(args)
+	if err != nil {
+		return err
+	}
+	if err := autostart.Install(spec); err != nil {
+		return err
+	}
+	fmt.Printf("Installed and started: %s %s\n", spec.Executable, strings.Join(spec.Args, " "))
+	return nil
+}
+
+func runServiceUninstall(cmd *cobra.Command, args []string) error {
+	if err := autostart.Uninstall(); err != nil {
+		if errors.Is(err, autostart.ErrNotInstalled) {
+			fmt.Println("kportforward is not installed as a service")
+			return nil
+		}
+		return err
+	}
+	fmt.Println("Stopped and removed the kportforward service")
+	return nil
+}
+
+func runServiceStatus(cmd *cobra.Command, args []string) error {
+	status, err := autostart.CurrentStatus()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("kportforward service: %s\n", status)
+	return nil
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/victorkazakov/kportforward/internal/journal"
+)
+
+var sessionsService string
+
+func init() {
+	sessionsCmd := &cobra.Command{
+		Use:   "sessions",
+		Short: "List recorded sessions",
+		Long: `Every run of kportforward records service state changes, errors, restarts and
+context switches in a session journal. List the recorded sessions, newest last.`,
+		Args: cobra.NoArgs,
+		Run:  runSessionsList,
+	}
+
+	showCmd := &cobra.Command{
+		Use:   "show [session-id]",
+		Short: "Show the journal of a session (default: the latest)",
+		Long: `Print the journal of a recorded session for reviewing what happened to the forwards.
+
+Examples:
+  kportforward sessions show
+  kportforward sessions show 20250101-090000 --service flyte-console`,
+		Args: cobra.MaximumNArgs(1),
+		Run:  runSessionsShow,
+	}
+	showCmd.Flags().StringVar(&sessionsService, "service", "", "Only show entries for this service")
+
+	sessionsCmd.AddCommand(showCmd)
+	rootCmd.AddCommand(sessionsCmd)
+}
+
+func runSessionsList(cmd *cobra.Command, args []string) {
+	dir, err := journal.Dir()
+	if err != nil {
+		log.Fatalf("Failed to locate sessions: %v", err)
+	}
+	sessions, err := journal.List(dir)
+	if err != nil {
+		log.Fatalf("Failed to list sessions: %v", err)
+	}
+	if len(sessions) == 0 {
+		fmt.Printf("No sessions recorded in %s\n", dir)
+		return
+	}
+
+	fmt.Printf("%-16s  %-19s  %-10s  %8s  %8s\n", "SESSION", "STARTED", "DURATION", "FAILURES", "RESTARTS")
+	for _, session := range sessions {
+		duration := "running"
+		if !session.End.IsZero() {
+			duration = session.End.Sub(session.Start).Round(time.Second).String()
+		}
+		fmt.Printf("%-16s  %-19s  %-10s  %8d  %8d\n", session.ID, session.Start.Format("2006-01-02 15:04:05"),
+			duration, session.Failures, session.Restarts)
+	}
+}
+
+func runSessionsShow(cmd *cobra.Command, args []string) {
+	dir, err := journal.Dir()
+	if err != nil {
+		log.Fatalf("Failed to locate sessions: %v", err)
+	}
+
+	id := ""
+	if len(args) > 0 {
+		id = args[0]
+	}
+	session, err := journal.Find(dir, id)
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+	records, err := journal.Read(session.Path)
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+
+	fmt.Printf("Session %s (%s)\n\n", session.ID, session.Path)
+	for _, record := range records {
+		if sessionsService != "" && record.Service != sessionsService {
+			continue
+		}
+		fmt.Println(record)
+	}
+}
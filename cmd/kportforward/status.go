@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/export"
+	"github.com/victorkazakov/kportforward/internal/httpapi"
+	"github.com/victorkazakov/kportforward/internal/statusfile"
+)
+
+var (
+	statusFormat   string
+	statusReadFile string
+	statusAPIPort  int
+)
+
+// statusDocumentFormats are printed as the status document itself rather than a table
+var statusDocumentFormats = []string{"json", "yaml"}
+
+func init() {
+	statusCmd := &cobra.Command{
+		Use:   "status",
+		Short: "Print the status of a running instance as a table, JSON or YAML",
+		Long: `Print the services of a running kportforward, as a paste-ready Markdown or CSV
+table for standups and incident docs, an aligned table for the terminal, or the full
+status as JSON or YAML for scripts. The status is fetched from the HTTP API of the
+running instance (--api-port), from the daemon of 'kportforward start', or else read
+from the status file it writes (--status-file or statusFile in the config).
+
+Examples:
+  kportforward status
+  kportforward status --format csv > status.csv
+  kportforward status --api-port 7070 --format table
+  kportforward status --api-port 7070 --format json | jq '.services | map_values(.status)'`,
+		Args: cobra.NoArgs,
+		RunE: runStatus,
+	}
+
+	statusCmd.Flags().StringVar(&statusFormat, "format", "markdown", "Output format: "+strings.Join(statusFormats(), ", "))
+	statusCmd.Flags().StringVar(&statusReadFile, "status-file", "", "Status file written by the running instance (default: statusFile from the config)")
+	statusCmd.Flags().IntVar(&statusAPIPort, "api-port", 0, "Fetch the status from the HTTP API of the running instance (its --api-port) instead of the status file")
+
+	rootCmd.AddCommand(statusCmd)
+}
+
+// statusFormats lists the formats status can print
+func statusFormats() []string {
+	return append(append([]string{}, export.Formats...), statusDocumentFormats...)
+}
+
+func runStatus(cmd *cobra.Command, args []string) error {
+	if !slices.Contains(statusFormats(), statusFormat) && statusFormat != "md" {
+		return fmt.Errorf("unknown format %q (expected %s)", statusFormat, strings.Join(statusFormats(), ", "))
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	doc, err := readStatus(cfg)
+	if err != nil {
+		return err
+	}
+
+	switch statusFormat {
+	case "json":
+		data, err := json.MarshalIndent(doc, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode status: %w", err)
+		}
+		fmt.Println(string(data))
+		return nil
+	case "yaml":
+		data, err := yaml.Marshal(doc)
+		if err != nil {
+			return fmt.Errorf("failed to encode status: %w", err)
+		}
+		fmt.Print(string(data))
+		return nil
+	}
+
+	output, err := export.Render(statusFormat, export.Rows(doc.Statuses(), cfg.PortForwards, time.Now()))
+	if err != nil {
+		return err
+	}
+	fmt.Print(output)
+	return nil
+}
+
+// readStatus fetches the status from the API with --api-port or from a running daemon,
+// or else reads the status file
+func readStatus(cfg *config.Config) (statusfile.Document, error) {
+	if statusAPIPort != 0 {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		doc, err := httpapi.NewClient(statusAPIPort).Services(ctx)
+		if err != nil {
+			return doc, fmt.Errorf("%w; is kportforward running with --api-port %d?", err, statusAPIPort)
+		}
+		return doc, nil
+	}
+
+	// A daemon started with 'kportforward start' answers on its control socket
+	if statusReadFile == "" {
+		if client, err := daemonClient(); err == nil {
+			if _, err := client.Info(context.Background()); err == nil {
+				ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+				defer cancel()
+				return client.Services(ctx)
+			}
+		}
+	}
+
+	path := statusReadFile
+	if path == "" {
+		path = cfg.StatusFile
+	}
+	if path == "" {
+		return statusfile.Document{}, fmt.Errorf("no status source; start kportforward with 'kportforward start', pass --api-port of an instance run with --api-port, or run kportforward with --status-file or set statusFile in the config")
+	}
+	return statusfile.Read(path)
+}
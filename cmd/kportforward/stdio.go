@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/kubeconfig"
+	"github.com/victorkazakov/kportforward/internal/portforward"
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+var stdioTimeout time.Duration
+
+func init() {
+	stdioCmd := &cobra.Command{
+		Use:   "stdio <service>",
+		Short: "Connect stdin/stdout to a service's forwarded endpoint",
+		Long: `Pipe stdin and stdout to a configured service, like netcat, e.g. as an SSH
+ProxyCommand. The forward of a running kportforward instance is used when its local
+port accepts connections; otherwise a forward is started for the duration of the
+connection and waited for while it starts or restarts. Only the connection's data is
+written to stdout; errors go to stderr.
+
+Examples:
+  ssh -o ProxyCommand='kportforward stdio bastion-sshd' user@bastion
+  kportforward stdio redis --timeout 1m`,
+		Args: cobra.ExactArgs(1),
+		RunE: runStdio,
+	}
+
+	stdioCmd.Flags().DurationVar(&stdioTimeout, "timeout", 30*time.Second, "How long to wait for the forward to accept a connection")
+
+	rootCmd.AddCommand(stdioCmd)
+}
+
+func runStdio(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if err := kubeconfig.Apply(cfg.Kubeconfigs); err != nil {
+		return fmt.Errorf("failed to load kubeconfigs: %w", err)
+	}
+	service, exists := cfg.PortForwards[name]
+	if !exists {
+		return fmt.Errorf("service %s not found in configuration", name)
+	}
+
+	// stdout carries the connection, so everything else goes to stderr
+	logger := utils.NewLoggerWithOutput(utils.LevelWarn, os.Stderr)
+
+	port := service.LocalPort
+	if !utils.CheckPortConnectivity(port) {
+		// A forward of our own, on a port a kportforward instance started later won't want
+		if port, err = utils.FindFreeLoopbackPort(); err != nil {
+			return err
+		}
+		service.LocalPort = port
+		service.BindAddress = ""
+
+		session := *cfg
+		session.PortForwards = map[string]config.Service{name: service}
+		manager := portforward.NewManager(&session, logger)
+		if err := manager.Start(); err != nil {
+			logger.Warn("%v; retrying", err) // The monitor restarts the forward
+		}
+		defer manager.Stop()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), stdioTimeout)
+	conn, err := utils.DialWithRetry(ctx, net.JoinHostPort("127.0.0.1", strconv.Itoa(port)))
+	cancel()
+	if err != nil {
+		return fmt.Errorf("%s is not accepting connections: %w", name, err)
+	}
+	return utils.PipeStdio(conn, os.Stdin, os.Stdout)
+}
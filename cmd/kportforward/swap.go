@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+
+	"github.com/victorkazakov/kportforward/internal/adminapi/adminpb"
+)
+
+var swapAddr string
+
+func init() {
+	swapCmd := &cobra.Command{
+		Use:   "swap <service> [target]",
+		Short: "Switch which target backs a service's local port in a running instance",
+		Long: `Switch a service between its target and its alternateTargets (e.g. a canary)
+without reconfiguring clients; only that forward is restarted. Without a target the
+next alternate is used. The running instance must serve the admin API (--dashboard-addr).
+
+Examples:
+  kportforward swap payments
+  kportforward swap payments service/payments-canary --addr localhost:7080`,
+		Args: cobra.RangeArgs(1, 2),
+		RunE: runSwap,
+	}
+
+	swapCmd.Flags().StringVar(&swapAddr, "addr", "localhost:7080", "Admin API address of the running instance (its --dashboard-addr)")
+
+	rootCmd.AddCommand(swapCmd)
+}
+
+func runSwap(cmd *cobra.Command, args []string) error {
+	target := ""
+	if len(args) == 2 {
+		target = args[1]
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	conn, err := grpc.DialContext(ctx, swapAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s: %w", swapAddr, err)
+	}
+	defer conn.Close()
+
+	resp, err := adminpb.NewAdminServiceClient(conn).SwapTarget(ctx, &adminpb.SwapTargetRequest{Name: args[0], Target: target})
+	if err != nil {
+		return fmt.Errorf("%s", status.Convert(err).Message())
+	}
+	fmt.Printf("%s now forwards to %s\n", args[0], resp.GetTarget())
+	return nil
+}
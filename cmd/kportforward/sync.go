@@ -0,0 +1,167 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/teamsync"
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+// syncStartupTimeout bounds the pull on startup, so an unreachable remote only
+// delays the forwards briefly
+const syncStartupTimeout = 15 * time.Second
+
+func init() {
+	syncCmd := &cobra.Command{
+		Use:   "sync",
+		Short: "Pull the team's shared config and show how yours differs",
+		Long: `Keep the team's forward list in a git repository and point the user config at it:
+
+  sync:
+    repo: "git@github.com:acme/dev-config.git"
+    branch: "main"              # optional
+    path: "kportforward.yaml"   # optional
+
+The repository is cloned into the config directory and pulled on every start (skip
+with --no-sync) and whenever you run 'kportforward sync'. Its config is merged beneath
+your user config, so your own services and settings still take precedence; the report
+lists where they override the team's. Reload the config in a running instance (F5 or
+Ctrl+L) after a sync.
+
+Examples:
+  kportforward sync
+  kportforward sync status`,
+		Args: cobra.NoArgs,
+		RunE: runSync,
+	}
+
+	statusCmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show the pulled team config, whether it is behind, and the drift from it",
+		Args:  cobra.NoArgs,
+		RunE:  runSyncStatus,
+	}
+
+	syncCmd.AddCommand(statusCmd)
+	rootCmd.AddCommand(syncCmd)
+}
+
+// loadSyncSettings returns the user config, failing if it doesn't configure sync
+func loadSyncSettings() (*config.Config, error) {
+	userConfig, err := config.LoadUserConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if userConfig.Sync.Repo == "" {
+		return nil, fmt.Errorf("no team config repository configured; set sync.repo in the user config")
+	}
+	return userConfig, nil
+}
+
+func runSync(cmd *cobra.Command, args []string) error {
+	userConfig, err := loadSyncSettings()
+	if err != nil {
+		return err
+	}
+
+	result, err := teamsync.Pull(context.Background(), userConfig.Sync)
+	if err != nil {
+		return err
+	}
+	if result.Updated() {
+		fmt.Printf("Pulled %s at %s: %s\n", userConfig.Sync.Repo, teamsync.ShortCommit(result.Commit), result.Changes)
+	} else {
+		fmt.Printf("%s is up to date at %s\n", userConfig.Sync.Repo, teamsync.ShortCommit(result.Commit))
+	}
+	return printDrift(userConfig)
+}
+
+func runSyncStatus(cmd *cobra.Command, args []string) error {
+	userConfig, err := loadSyncSettings()
+	if err != nil {
+		return err
+	}
+
+	branch := userConfig.Sync.Branch
+	if branch == "" {
+		branch = "default branch"
+	}
+	fmt.Printf("Team config: %s (%s)\n", userConfig.Sync.Repo, branch)
+
+	status, err := teamsync.CurrentStatus(context.Background())
+	if err != nil {
+		return err
+	}
+	if status.Commit == "" {
+		fmt.Println("Not pulled yet; run 'kportforward sync'")
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), syncStartupTimeout)
+	upstream, err := teamsync.Upstream(ctx, userConfig.Sync)
+	cancel()
+	state := "up to date"
+	switch {
+	case err != nil:
+		state = fmt.Sprintf("remote unreachable: %v", err)
+	case upstream != status.Commit:
+		state = fmt.Sprintf("behind %s; run 'kportforward sync'", teamsync.ShortCommit(upstream))
+	}
+	fmt.Printf("Pulled:      %s from %s (%s)\n", teamsync.ShortCommit(status.Commit), status.CommitTime.Local().Format("2006-01-02 15:04"), state)
+	return printDrift(userConfig)
+}
+
+// printDrift lists where the user config departs from the pulled team config
+func printDrift(userConfig *config.Config) error {
+	teamConfig, err := config.LoadTeamConfig(userConfig.Sync)
+	if err != nil {
+		return err
+	}
+	if teamConfig == nil {
+		return nil
+	}
+
+	drift := teamsync.ComputeDrift(teamConfig, userConfig)
+	if drift.Empty() {
+		fmt.Println("Your config doesn't override the team config")
+	}
+	for _, group := range []struct {
+		label string
+		names []string
+	}{
+		{"Overridden by your config", drift.Overridden},
+		{"Same as the team's (can be removed from your config)", drift.Redundant},
+		{"Settings overridden by your config", drift.Settings},
+		{"Only in your config", drift.PersonalOnly},
+	} {
+		if len(group.names) > 0 {
+			fmt.Printf("%s: %s\n", group.label, strings.Join(group.names, ", "))
+		}
+	}
+	return nil
+}
+
+// pullTeamConfig pulls the team config on startup; when that fails the last pulled
+// one is used
+func pullTeamConfig(logger *utils.Logger) {
+	userConfig, err := config.LoadUserConfig()
+	if err != nil || userConfig.Sync.Repo == "" {
+		return // Loading the config reports a broken user config
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), syncStartupTimeout)
+	defer cancel()
+	result, err := teamsync.Pull(ctx, userConfig.Sync)
+	if err != nil {
+		logger.Warn("Failed to pull the team config, using the last pulled one: %v", err)
+		return
+	}
+	if result.Updated() {
+		logger.Info("Pulled the team config at %s: %s", teamsync.ShortCommit(result.Commit), result.Changes)
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/updater"
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+var updateCheckOnly bool
+
+func init() {
+	updateCmd := &cobra.Command{
+		Use:   "update",
+		Short: "Check for a new release and download it",
+		Long: `Check the configured release source for a newer version and download its binary
+for this platform into the update cache, showing progress. The binary is checked
+against the release's SHA-256 checksums file, and the checksums against their
+cosign or minisign signature when updates.signature is set. An interrupted download
+is resumed when the command runs again.
+
+Examples:
+  kportforward update
+  kportforward update --check
+  kportforward update --update-channel nightly`,
+		Args: cobra.NoArgs,
+		RunE: runUpdate,
+	}
+
+	updateCmd.Flags().BoolVar(&updateCheckOnly, "check", false, "Only report whether an update is available")
+	updateCmd.Flags().StringVar(&updateChannel, "update-channel", "", "Release channel to update from: "+strings.Join(updater.Channels, ", ")+" (overrides updates.channel in the config)")
+
+	rootCmd.AddCommand(updateCmd)
+}
+
+func runUpdate(cmd *cobra.Command, args []string) error {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if cfg.Updates.Disabled {
+		return fmt.Errorf("updates are disabled (updates.disabled in the config)")
+	}
+	source, err := releaseSource(cfg.Updates)
+	if err != nil {
+		return fmt.Errorf("invalid updates config: %w", err)
+	}
+
+	updateManager := updater.NewManager(source, version, utils.NewLogger(utils.LevelWarn))
+	if err := updateManager.SetChannel(updateChannelFor(cfg.Updates)); err != nil {
+		return err
+	}
+	if err := updateManager.SetVerification(updateVerification(cfg.Updates)); err != nil {
+		return fmt.Errorf("invalid updates config: %w", err)
+	}
+	if err := updateManager.SetNetwork(updateNetwork(cfg.Updates)); err != nil {
+		return fmt.Errorf("invalid updates config: %w", err)
+	}
+	updateInfo, err := updateManager.ForceCheck()
+	if err != nil {
+		return fmt.Errorf("update check failed: %w", err)
+	}
+	if !updateInfo.Available {
+		fmt.Printf("kportforward %s is up to date\n", version)
+		return nil
+	}
+
+	fmt.Printf("Update available: %s -> %s\n", updateInfo.CurrentVersion, updateInfo.LatestVersion)
+	if updateCheckOnly {
+		return nil
+	}
+
+	path, err := updateManager.PrepareUpdate(updateInfo, func(progress updater.Progress) {
+		fmt.Fprintf(os.Stderr, "\r\033[KDownloading: %s", progress)
+	})
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return fmt.Errorf("download failed (run again to resume): %w", err)
+	}
+
+	fmt.Printf("Downloaded %s to %s; replace the kportforward binary with it to finish\n", updateInfo.LatestVersion, path)
+	return nil
+}
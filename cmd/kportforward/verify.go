@@ -0,0 +1,234 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/kubeconfig"
+	"github.com/victorkazakov/kportforward/internal/portforward"
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+// verifyPollInterval is how often a started forward is checked for coming up
+const verifyPollInterval = 250 * time.Millisecond
+
+var verifyTimeout time.Duration
+
+func init() {
+	verifyCmd := &cobra.Command{
+		Use:   "verify [service...]",
+		Short: "Check that the forwards come up and pass their health checks",
+		Long: `Bring up the configured forwards, probe each service's health check once and
+print a pass/fail table. Exits non-zero when any service fails, for pre-demo checks
+and CI smoke tests.
+
+Forwards of a running instance are reused: a service counts as running when the
+status file lists it as running, or when its local port already accepts
+connections. The other services are started for the check and stopped afterwards.
+
+Examples:
+  kportforward verify
+  kportforward verify api-gateway flyte-console --timeout 1m`,
+		RunE: runVerify,
+	}
+
+	verifyCmd.Flags().DurationVar(&verifyTimeout, "timeout", 30*time.Second, "How long the forwards may take to come up")
+
+	rootCmd.AddCommand(verifyCmd)
+}
+
+// verifyResult is the outcome of verifying one service
+type verifyResult struct {
+	name     string
+	service  config.Service
+	port     int
+	reused   bool // The forward of a running instance was probed
+	duration time.Duration
+	err      error
+}
+
+func runVerify(cmd *cobra.Command, args []string) error {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if err := kubeconfig.Apply(cfg.Kubeconfigs); err != nil {
+		return fmt.Errorf("failed to load kubeconfigs: %w", err)
+	}
+
+	names := args
+	if len(names) == 0 {
+		for name, service := range cfg.PortForwards {
+			if service.IsWildcard() {
+				fmt.Fprintf(os.Stderr, "Skipping wildcard entry %s\n", name)
+				continue
+			}
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	}
+	if len(names) == 0 {
+		return fmt.Errorf("no services configured")
+	}
+
+	running := runningForwards(cfg)
+	toStart := make(map[string]config.Service)
+	results := make([]*verifyResult, 0, len(names))
+	for _, name := range names {
+		service, exists := cfg.PortForwards[name]
+		if !exists {
+			return fmt.Errorf("service %s not found in configuration", name)
+		}
+		if service.IsWildcard() {
+			return fmt.Errorf("%s is a wildcard entry, which verify doesn't expand", name)
+		}
+		result := &verifyResult{name: name, service: cfg.WithServiceDefaults(service)}
+		if port, ok := running[name]; ok {
+			result.port, result.reused = port, true
+		} else if service.LocalPort != 0 && utils.CheckPortConnectivity(service.LocalPort) {
+			result.port, result.reused = service.LocalPort, true
+		} else {
+			toStart[name] = service
+		}
+		results = append(results, result)
+	}
+
+	var manager *portforward.Manager
+	if len(toStart) > 0 {
+		session := *cfg
+		session.PortForwards = toStart
+		session.Availability = config.AvailabilityConfig{}
+		manager = portforward.NewManager(&session, utils.NewLogger(utils.LevelError))
+
+		fmt.Fprintf(os.Stderr, "Starting the forwards of %d services...\n", len(toStart))
+		if err := manager.Start(); err != nil {
+			if len(manager.GetCurrentStatus()) == 0 {
+				// Nothing was started, e.g. without a Kubernetes context
+				for _, result := range results {
+					if !result.reused {
+						result.err = err
+					}
+				}
+			} else {
+				// The monitor keeps retrying forwards that failed to start until the timeout
+				fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+			}
+		}
+		defer manager.Stop()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), verifyTimeout)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	for _, result := range results {
+		wg.Add(1)
+		go func(result *verifyResult) {
+			defer wg.Done()
+			verifyService(ctx, manager, result)
+		}(result)
+	}
+	wg.Wait()
+
+	failed := printVerifyResults(results)
+	if failed > 0 {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("%d of %d services failed verification", failed, len(results))
+	}
+	return nil
+}
+
+// runningForwards returns the local ports of the services a running daemon or instance
+// reports as running, when they accept connections
+func runningForwards(cfg *config.Config) map[string]int {
+	ports := make(map[string]int)
+	doc, err := readStatus(cfg)
+	if err != nil {
+		return ports
+	}
+	for name, status := range doc.Statuses() {
+		if status.Status == "Running" && utils.CheckPortConnectivity(status.LocalPort) {
+			ports[name] = status.LocalPort
+		}
+	}
+	return ports
+}
+
+// verifyService waits for a started forward to come up, then probes its health check
+func verifyService(ctx context.Context, manager *portforward.Manager, result *verifyResult) {
+	if result.err != nil {
+		return
+	}
+	started := time.Now()
+	defer func() { result.duration = time.Since(started) }()
+
+	if !result.reused {
+		status, err := waitForService(ctx, manager, result.name)
+		if err != nil {
+			result.err = err
+			return
+		}
+		result.port = status.LocalPort
+	}
+	result.err = portforward.CheckService(ctx, result.service, result.port)
+}
+
+// waitForService polls a started service until it runs, fails for a reason a retry
+// won't fix, or the timeout expires
+func waitForService(ctx context.Context, manager *portforward.Manager, name string) (config.ServiceStatus, error) {
+	ticker := time.NewTicker(verifyPollInterval)
+	defer ticker.Stop()
+
+	for {
+		status, exists := manager.GetCurrentStatus()[name]
+		switch {
+		case !exists:
+			return status, fmt.Errorf("not started")
+		case status.Status == "Running":
+			return status, nil
+		case status.Status == "Failed" && status.ErrorCategory.Fatal():
+			return status, fmt.Errorf("%s", status.LastError)
+		}
+
+		select {
+		case <-ctx.Done():
+			if status.LastError != "" {
+				return status, fmt.Errorf("not running after %s: %s (%s)", verifyTimeout, status.Status, status.LastError)
+			}
+			return status, fmt.Errorf("not running after %s: %s", verifyTimeout, status.Status)
+		case <-ticker.C:
+		}
+	}
+}
+
+// printVerifyResults prints the pass/fail table and returns the number of failures
+func printVerifyResults(results []*verifyResult) int {
+	failed := 0
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SERVICE\tPORT\tFORWARD\tCHECK\tRESULT\tTIME\tDETAIL")
+	for _, result := range results {
+		forward := "started"
+		if result.reused {
+			forward = "running"
+		}
+		port, outcome, detail := "-", "PASS", ""
+		if result.port != 0 {
+			port = fmt.Sprintf("%d", result.port)
+		}
+		if result.err != nil {
+			failed++
+			outcome, detail = "FAIL", result.err.Error()
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", result.name, port, forward,
+			portforward.DescribeHealthCheck(result.service), outcome, result.duration.Round(time.Millisecond), detail)
+	}
+	w.Flush()
+	return failed
+}
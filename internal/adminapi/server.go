@@ -0,0 +1,234 @@
+package adminapi
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/victorkazakov/kportforward/internal/adminapi/adminpb"
+	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/portforward"
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+// Controller is the subset of the port-forward manager exposed over gRPC
+type Controller interface {
+	GetCurrentStatus() map[string]config.ServiceStatus
+	GetKubernetesContext() string
+	RestartService(name string) error
+	StopService(name string) error
+	SwapTarget(name, target string) (config.Service, error)
+}
+
+// Server implements the AdminService gRPC API
+type Server struct {
+	adminpb.UnimplementedAdminServiceServer
+
+	controller Controller
+	logger     *utils.Logger
+	grpcServer *grpc.Server
+
+	// Active WatchStatus streams, each with a single-slot buffer holding the latest snapshot
+	watchers     map[chan *adminpb.StatusSnapshot]struct{}
+	watcherMutex sync.Mutex
+	done         chan struct{}
+}
+
+// NewServer creates the admin API and registers it on a new gRPC server
+func NewServer(controller Controller, logger *utils.Logger) *Server {
+	s := &Server{
+		controller: controller,
+		logger:     logger,
+		grpcServer: grpc.NewServer(),
+		watchers:   make(map[chan *adminpb.StatusSnapshot]struct{}),
+		done:       make(chan struct{}),
+	}
+	adminpb.RegisterAdminServiceServer(s.grpcServer, s)
+	return s
+}
+
+// GRPCServer returns the underlying gRPC server, which also implements http.Handler
+func (s *Server) GRPCServer() *grpc.Server {
+	return s.grpcServer
+}
+
+// Stop ends open watch streams and stops the gRPC server
+func (s *Server) Stop() {
+	close(s.done)
+	s.grpcServer.Stop()
+}
+
+// HandleEvent pushes status snapshots from the manager to watchers
+func (s *Server) HandleEvent(event portforward.Event) {
+	if event.Type != portforward.EventStatusUpdated {
+		return
+	}
+
+	s.watcherMutex.Lock()
+	defer s.watcherMutex.Unlock()
+
+	if len(s.watchers) == 0 {
+		return
+	}
+	snapshot := s.buildSnapshot(event.Snapshot)
+
+	for watcher := range s.watchers {
+		// Replace any snapshot the watcher hasn't consumed yet
+		select {
+		case <-watcher:
+		default:
+		}
+		watcher <- snapshot
+	}
+}
+
+// ListServices returns the current status of all services
+func (s *Server) ListServices(ctx context.Context, req *adminpb.ListServicesRequest) (*adminpb.ListServicesResponse, error) {
+	snapshot := s.buildSnapshot(s.controller.GetCurrentStatus())
+	return &adminpb.ListServicesResponse{
+		Context:  snapshot.Context,
+		Services: snapshot.Services,
+	}, nil
+}
+
+// WatchStatus streams the current status followed by a snapshot on every monitoring tick
+func (s *Server) WatchStatus(req *adminpb.WatchStatusRequest, stream adminpb.AdminService_WatchStatusServer) error {
+	watcher := make(chan *adminpb.StatusSnapshot, 1)
+	s.watcherMutex.Lock()
+	s.watchers[watcher] = struct{}{}
+	s.watcherMutex.Unlock()
+
+	defer func() {
+		s.watcherMutex.Lock()
+		delete(s.watchers, watcher)
+		s.watcherMutex.Unlock()
+	}()
+
+	if err := stream.Send(s.buildSnapshot(s.controller.GetCurrentStatus())); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case <-s.done:
+			return nil
+		case snapshot := <-watcher:
+			if err := stream.Send(snapshot); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// RestartService restarts a single service
+func (s *Server) RestartService(ctx context.Context, req *adminpb.RestartServiceRequest) (*adminpb.RestartServiceResponse, error) {
+	if err := s.checkService(req.GetName()); err != nil {
+		return nil, err
+	}
+	if err := s.controller.RestartService(req.GetName()); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to restart %s: %v", req.GetName(), err)
+	}
+	s.logger.Info("Restarted %s via admin API", req.GetName())
+	return &adminpb.RestartServiceResponse{}, nil
+}
+
+// StopService stops a single service
+func (s *Server) StopService(ctx context.Context, req *adminpb.StopServiceRequest) (*adminpb.StopServiceResponse, error) {
+	if err := s.checkService(req.GetName()); err != nil {
+		return nil, err
+	}
+	if err := s.controller.StopService(req.GetName()); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to stop %s: %v", req.GetName(), err)
+	}
+	s.logger.Info("Stopped %s via admin API", req.GetName())
+	return &adminpb.StopServiceResponse{}, nil
+}
+
+// SwapTarget switches the target backing a service's local port
+func (s *Server) SwapTarget(ctx context.Context, req *adminpb.SwapTargetRequest) (*adminpb.SwapTargetResponse, error) {
+	if err := s.checkService(req.GetName()); err != nil {
+		return nil, err
+	}
+	service, err := s.controller.SwapTarget(req.GetName(), req.GetTarget())
+	if err != nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "failed to swap the target of %s: %v", req.GetName(), err)
+	}
+	s.logger.Info("Switched %s to %s via admin API", req.GetName(), service.Target)
+	return &adminpb.SwapTargetResponse{Target: service.Target}, nil
+}
+
+// checkService validates that a request names a service the manager runs, including
+// those added since startup by a reload, a wildcard or the TUI
+func (s *Server) checkService(name string) error {
+	if name == "" {
+		return status.Error(codes.InvalidArgument, "service name is required")
+	}
+	if _, exists := s.controller.GetCurrentStatus()[name]; !exists {
+		return status.Errorf(codes.NotFound, "service %s not found", name)
+	}
+	return nil
+}
+
+// buildSnapshot converts a status map into its protobuf representation
+func (s *Server) buildSnapshot(statuses map[string]config.ServiceStatus) *adminpb.StatusSnapshot {
+	snapshot := &adminpb.StatusSnapshot{
+		Context:   s.controller.GetKubernetesContext(),
+		Services:  make([]*adminpb.Service, 0, len(statuses)),
+		UpdatedAt: timestamppb.Now(),
+	}
+
+	for name, serviceStatus := range statuses {
+		snapshot.Services = append(snapshot.Services, &adminpb.Service{
+			Name:           name,
+			Status:         serviceStatus.Status,
+			Type:           serviceStatus.Type,
+			LocalPort:      int32(serviceStatus.LocalPort),
+			Pid:            int32(serviceStatus.PID),
+			StartTime:      timestampOrNil(serviceStatus.StartTime),
+			RestartCount:   int32(serviceStatus.RestartCount),
+			LastError:      serviceStatus.LastError,
+			InCooldown:     serviceStatus.InCooldown,
+			CooldownUntil:  timestampOrNil(serviceStatus.CooldownUntil),
+			Latency:        durationOrNil(serviceStatus.Latency),
+			LatencyP50:     durationOrNil(serviceStatus.LatencyP50),
+			LatencyP95:     durationOrNil(serviceStatus.LatencyP95),
+			Context:        serviceStatus.Context,
+			Cluster:        serviceStatus.Cluster,
+			Namespace:      serviceStatus.Namespace,
+			Target:         serviceStatus.Target,
+			ConfiguredPort: int32(serviceStatus.ConfiguredPort),
+			ErrorCategory:  string(serviceStatus.ErrorCategory),
+		})
+	}
+
+	sort.Slice(snapshot.Services, func(i, j int) bool {
+		return snapshot.Services[i].Name < snapshot.Services[j].Name
+	})
+
+	return snapshot
+}
+
+// timestampOrNil converts a time to a protobuf timestamp, leaving zero times unset
+func timestampOrNil(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+// durationOrNil converts a duration to a protobuf duration, leaving unmeasured values unset
+func durationOrNil(d time.Duration) *durationpb.Duration {
+	if d == 0 {
+		return nil
+	}
+	return durationpb.New(d)
+}
@@ -0,0 +1,200 @@
+package adminapi
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/victorkazakov/kportforward/internal/adminapi/adminpb"
+	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/portforward"
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+type fakeController struct {
+	statuses  map[string]config.ServiceStatus
+	restarted []string
+	stopped   []string
+	swapped   []string
+}
+
+func (f *fakeController) GetCurrentStatus() map[string]config.ServiceStatus {
+	return f.statuses
+}
+
+func (f *fakeController) GetKubernetesContext() string {
+	return "test-context"
+}
+
+func (f *fakeController) RestartService(name string) error {
+	f.restarted = append(f.restarted, name)
+	return nil
+}
+
+func (f *fakeController) StopService(name string) error {
+	f.stopped = append(f.stopped, name)
+	return nil
+}
+
+func (f *fakeController) SwapTarget(name, target string) (config.Service, error) {
+	service, err := config.Service{Target: "service/web", AlternateTargets: []string{"service/web-canary"}}.WithTarget(target)
+	if err != nil {
+		return config.Service{}, err
+	}
+	f.swapped = append(f.swapped, name)
+	return service, nil
+}
+
+func newTestClient(t *testing.T) (adminpb.AdminServiceClient, *Server, *fakeController) {
+	controller := &fakeController{
+		statuses: map[string]config.ServiceStatus{
+			"web":     {Name: "web", Type: "web", Status: "Running", LocalPort: 8080, StartTime: time.Now()},
+			"backend": {Name: "backend", Type: "rpc", Status: "Failed", LocalPort: 50051, LastError: "Health check failed"},
+		},
+	}
+	server := NewServer(controller, utils.NewLogger(utils.LevelError))
+
+	listener := bufconn.Listen(1024 * 1024)
+	go server.GRPCServer().Serve(listener)
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("Failed to dial admin server: %v", err)
+	}
+
+	t.Cleanup(func() {
+		conn.Close()
+		server.Stop()
+	})
+
+	return adminpb.NewAdminServiceClient(conn), server, controller
+}
+
+func TestListServices(t *testing.T) {
+	client, _, _ := newTestClient(t)
+
+	resp, err := client.ListServices(context.Background(), &adminpb.ListServicesRequest{})
+	if err != nil {
+		t.Fatalf("ListServices failed: %v", err)
+	}
+
+	if resp.Context != "test-context" {
+		t.Errorf("Expected context test-context, got %s", resp.Context)
+	}
+	if len(resp.Services) != 2 {
+		t.Fatalf("Expected 2 services, got %d", len(resp.Services))
+	}
+
+	// Services are sorted by name
+	backend, web := resp.Services[0], resp.Services[1]
+	if backend.Name != "backend" || backend.Type != "rpc" || backend.LastError != "Health check failed" {
+		t.Errorf("Unexpected backend service: %+v", backend)
+	}
+	if backend.StartTime != nil {
+		t.Error("Expected zero start time to be left unset")
+	}
+	if web.Name != "web" || web.LocalPort != 8080 || web.StartTime == nil {
+		t.Errorf("Unexpected web service: %+v", web)
+	}
+}
+
+func TestRestartAndStopService(t *testing.T) {
+	client, _, controller := newTestClient(t)
+	ctx := context.Background()
+
+	if _, err := client.RestartService(ctx, &adminpb.RestartServiceRequest{Name: "web"}); err != nil {
+		t.Fatalf("RestartService failed: %v", err)
+	}
+	if _, err := client.StopService(ctx, &adminpb.StopServiceRequest{Name: "backend"}); err != nil {
+		t.Fatalf("StopService failed: %v", err)
+	}
+
+	if len(controller.restarted) != 1 || controller.restarted[0] != "web" {
+		t.Errorf("Expected web to be restarted, got %v", controller.restarted)
+	}
+	if len(controller.stopped) != 1 || controller.stopped[0] != "backend" {
+		t.Errorf("Expected backend to be stopped, got %v", controller.stopped)
+	}
+
+	_, err := client.RestartService(ctx, &adminpb.RestartServiceRequest{Name: "missing"})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("Expected NotFound for unknown service, got %v", err)
+	}
+
+	// Services added after startup, e.g. by a reload, can be controlled too
+	controller.statuses["added"] = config.ServiceStatus{Name: "added", Status: "Running"}
+	if _, err := client.RestartService(ctx, &adminpb.RestartServiceRequest{Name: "added"}); err != nil {
+		t.Errorf("Expected a service added later to be restarted, got %v", err)
+	}
+
+	_, err = client.StopService(ctx, &adminpb.StopServiceRequest{})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument for empty name, got %v", err)
+	}
+}
+
+func TestSwapTarget(t *testing.T) {
+	client, _, controller := newTestClient(t)
+	ctx := context.Background()
+
+	resp, err := client.SwapTarget(ctx, &adminpb.SwapTargetRequest{Name: "web"})
+	if err != nil {
+		t.Fatalf("SwapTarget failed: %v", err)
+	}
+	if resp.GetTarget() != "service/web-canary" || len(controller.swapped) != 1 {
+		t.Errorf("Expected web to switch to its canary, got %q", resp.GetTarget())
+	}
+
+	_, err = client.SwapTarget(ctx, &adminpb.SwapTargetRequest{Name: "web", Target: "service/other"})
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("Expected FailedPrecondition for an unknown target, got %v", err)
+	}
+}
+
+func TestWatchStatus(t *testing.T) {
+	client, server, _ := newTestClient(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	stream, err := client.WatchStatus(ctx, &adminpb.WatchStatusRequest{})
+	if err != nil {
+		t.Fatalf("WatchStatus failed: %v", err)
+	}
+
+	// The current state is sent immediately
+	initial, err := stream.Recv()
+	if err != nil {
+		t.Fatalf("Failed to receive initial snapshot: %v", err)
+	}
+	if len(initial.Services) != 2 {
+		t.Fatalf("Expected 2 services in initial snapshot, got %d", len(initial.Services))
+	}
+
+	server.HandleEvent(portforward.Event{
+		Type: portforward.EventStatusUpdated,
+		Snapshot: map[string]config.ServiceStatus{
+			"web": {Name: "web", Status: "Failed", LocalPort: 8080},
+		},
+	})
+
+	update, err := stream.Recv()
+	if err != nil {
+		t.Fatalf("Failed to receive update: %v", err)
+	}
+	if len(update.Services) != 1 || update.Services[0].Status != "Failed" {
+		t.Errorf("Unexpected update: %+v", update.Services)
+	}
+}
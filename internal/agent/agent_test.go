@@ -0,0 +1,187 @@
+package agent
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+// startEcho starts a TCP echo server and returns its address
+func startEcho(t *testing.T) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				io.Copy(conn, conn)
+			}()
+		}
+	}()
+	return listener.Addr().String()
+}
+
+// startServer starts an agent with token and returns a tunnel to it using clientToken
+func startServer(t *testing.T, token, clientToken string) *Tunnel {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	go NewServer(token, utils.NewLogger(utils.LevelError)).Serve(listener)
+
+	address := listener.Addr().String()
+	return &Tunnel{Token: clientToken, Address: func() (string, error) { return address, nil }}
+}
+
+func TestForwardRelaysThroughAgent(t *testing.T) {
+	echo := startEcho(t)
+	tunnel := startServer(t, "secret", "secret")
+
+	port, err := utils.FindFreeLoopbackPort()
+	if err != nil {
+		t.Fatal(err)
+	}
+	forward, err := tunnel.Listen("", port, echo, utils.NewLogger(utils.LevelError))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer forward.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := forward.Probe(ctx); err != nil {
+		t.Fatalf("Probe() = %v", err)
+	}
+
+	conn, err := net.Dial("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(port)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	if _, err := conn.Write([]byte("hello\n")); err != nil {
+		t.Fatal(err)
+	}
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil || line != "hello\n" {
+		t.Fatalf("echo = %q, %v", line, err)
+	}
+
+	forward.Close()
+	select {
+	case <-forward.Done():
+	case <-time.After(time.Second):
+		t.Fatal("Done() not closed after Close()")
+	}
+	if err := forward.Err(); err != nil {
+		t.Errorf("Err() after Close() = %v, want nil", err)
+	}
+}
+
+func TestDialRejectsInvalidToken(t *testing.T) {
+	echo := startEcho(t)
+	tunnel := startServer(t, "secret", "wrong")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err := tunnel.Dial(ctx, echo)
+	if err == nil || !strings.Contains(err.Error(), "invalid token") {
+		t.Fatalf("Dial() = %v, want invalid token", err)
+	}
+}
+
+func TestDialUnreachableTarget(t *testing.T) {
+	tunnel := startServer(t, "", "")
+
+	// A port nothing listens on
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	closed := listener.Addr().String()
+	listener.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if _, err := tunnel.Dial(ctx, closed); err == nil || !strings.HasPrefix(err.Error(), "agent: ") {
+		t.Fatalf("Dial() = %v, want the agent's error", err)
+	}
+}
+
+func TestReadRequest(t *testing.T) {
+	tests := []struct {
+		line, token, target string
+		wantErr             bool
+	}{
+		{"secret db.ns.svc:5432\n", "secret", "db.ns.svc:5432", false},
+		{"- db.ns.svc:5432\n", "", "db.ns.svc:5432", false},
+		{"secret\n", "", "", true},
+		{strings.Repeat("x", maxRequestLine+1) + " a:1\n", "", "", true},
+	}
+	for _, tt := range tests {
+		token, target, err := readRequest(bufio.NewReaderSize(strings.NewReader(tt.line), 16))
+		if (err != nil) != tt.wantErr || token != tt.token || target != tt.target {
+			t.Errorf("readRequest(%.20q) = %q, %q, %v", tt.line, token, target, err)
+		}
+	}
+}
+
+func TestEligible(t *testing.T) {
+	tests := []struct {
+		service config.Service
+		want    bool
+	}{
+		{config.Service{Target: "service/api", TargetPort: 80}, true},
+		{config.Service{Target: "deployment/api", TargetPort: 80}, false},
+		{config.Service{Target: "service/api", TargetPort: 80, Context: "prod"}, false},
+		{config.Service{Target: "service/api", TargetPort: 80, Kubeconfig: "/tmp/kc"}, false},
+	}
+	for _, tt := range tests {
+		if got := Eligible(tt.service); got != tt.want {
+			t.Errorf("Eligible(%+v) = %v, want %v", tt.service, got, tt.want)
+		}
+	}
+}
+
+func TestTargetAddress(t *testing.T) {
+	if got := TargetAddress(config.Service{Target: "service/api", Namespace: "shop", TargetPort: 8080}); got != "api.shop.svc:8080" {
+		t.Errorf("TargetAddress() = %q", got)
+	}
+	if got := TargetAddress(config.Service{Target: "service/api", TargetPort: 80}); got != "api.default.svc:80" {
+		t.Errorf("TargetAddress() without namespace = %q", got)
+	}
+}
+
+func TestManifest(t *testing.T) {
+	manifest := Manifest("tools", "example.com/kpf:1.0", "tok3n")
+	for _, want := range []string{
+		"name: tools",
+		"name: kportforward-agent",
+		"image: example.com/kpf:1.0",
+		`token: "tok3n"`,
+		"name: " + TokenEnv,
+		`args: ["agent", "serve", "--listen", ":7000"]`,
+	} {
+		if !strings.Contains(manifest, want) {
+			t.Errorf("manifest missing %q", want)
+		}
+	}
+}
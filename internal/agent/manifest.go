@@ -0,0 +1,107 @@
+package agent
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+)
+
+// Defaults for the agent's deployment
+const (
+	DefaultNamespace = "kportforward"
+	DefaultTarget    = "deployment/kportforward-agent"
+
+	// ServiceName is the name the forward to the agent is shown under
+	ServiceName = "kportforward-agent"
+
+	// TokenEnv holds the agent's token inside its pod
+	TokenEnv = "KPF_AGENT_TOKEN"
+)
+
+// Eligible reports whether a service can be reached through the agent: kubectl
+// forwards to a Kubernetes Service in the agent's cluster, which the agent reaches by
+// its cluster DNS name. Pods and workloads still get their own kubectl forward.
+func Eligible(service config.Service) bool {
+	return service.UsesKubectl() && strings.HasPrefix(service.Target, "service/") &&
+		service.Context == "" && service.Kubeconfig == ""
+}
+
+// TargetAddress returns the cluster DNS address the agent dials for a service
+func TargetAddress(service config.Service) string {
+	name := strings.TrimPrefix(service.Target, "service/")
+	namespace := service.Namespace
+	if namespace == "" {
+		namespace = "default"
+	}
+	return net.JoinHostPort(name+"."+namespace+".svc", strconv.Itoa(service.TargetPort))
+}
+
+// Manifest renders the Kubernetes objects running the agent: a namespace, a secret
+// holding its token and a single-replica deployment
+func Manifest(namespace, image, token string) string {
+	name := strings.TrimPrefix(DefaultTarget, "deployment/")
+	return fmt.Sprintf(`apiVersion: v1
+kind: Namespace
+metadata:
+  name: %[1]s
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: %[2]s
+  namespace: %[1]s
+stringData:
+  token: %[4]q
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: %[2]s
+  namespace: %[1]s
+  labels:
+    app.kubernetes.io/name: %[2]s
+spec:
+  replicas: 1
+  selector:
+    matchLabels:
+      app.kubernetes.io/name: %[2]s
+  template:
+    metadata:
+      labels:
+        app.kubernetes.io/name: %[2]s
+    spec:
+      automountServiceAccountToken: false
+      securityContext:
+        runAsNonRoot: true
+        runAsUser: 65532
+      containers:
+        - name: agent
+          image: %[3]s
+          args: ["agent", "serve", "--listen", ":%[5]d"]
+          env:
+            - name: %[6]s
+              valueFrom:
+                secretKeyRef:
+                  name: %[2]s
+                  key: token
+          ports:
+            - containerPort: %[5]d
+          readinessProbe:
+            tcpSocket:
+              port: %[5]d
+          resources:
+            requests:
+              cpu: 10m
+              memory: 16Mi
+            limits:
+              memory: 64Mi
+          securityContext:
+            allowPrivilegeEscalation: false
+            readOnlyRootFilesystem: true
+            capabilities:
+              drop: ["ALL"]
+`, namespace, name, image, token, DefaultPort, TokenEnv)
+}
@@ -0,0 +1,77 @@
+package agent
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// The agent protocol is one request line per connection, "<token> <host:port>\n",
+// answered by "OK\n" or "ERR <message>\n"; after OK the connection carries the
+// target's traffic. kubectl port-forward already multiplexes every connection to
+// the agent over its one tunnel, so the agent only needs to route them.
+
+// DefaultPort is the port the agent listens on
+const DefaultPort = 7000
+
+// maxRequestLine bounds a request line so a stray client can't make the agent buffer forever
+const maxRequestLine = 1024
+
+// writeRequest asks the agent to connect to target
+func writeRequest(w io.Writer, token, target string) error {
+	if token == "" {
+		token = "-"
+	}
+	_, err := fmt.Fprintf(w, "%s %s\n", token, target)
+	return err
+}
+
+// readRequest reads the token and target of a request
+func readRequest(r *bufio.Reader) (string, string, error) {
+	line, err := readLine(r)
+	if err != nil {
+		return "", "", err
+	}
+	token, target, ok := strings.Cut(line, " ")
+	if !ok || target == "" {
+		return "", "", fmt.Errorf("malformed request")
+	}
+	if token == "-" {
+		token = ""
+	}
+	return token, target, nil
+}
+
+// readReply reads the agent's answer to a request, returning its error if it refused
+func readReply(r *bufio.Reader) error {
+	line, err := readLine(r)
+	if err != nil {
+		return fmt.Errorf("no reply from agent: %w", err)
+	}
+	if line == "OK" {
+		return nil
+	}
+	if message, ok := strings.CutPrefix(line, "ERR "); ok {
+		return fmt.Errorf("agent: %s", message)
+	}
+	return fmt.Errorf("unexpected reply from agent: %q", line)
+}
+
+// readLine reads one line, without its newline, of at most maxRequestLine bytes
+func readLine(r *bufio.Reader) (string, error) {
+	var line []byte
+	for {
+		chunk, isPrefix, err := r.ReadLine()
+		if err != nil {
+			return "", err
+		}
+		line = append(line, chunk...)
+		if len(line) > maxRequestLine {
+			return "", fmt.Errorf("line too long")
+		}
+		if !isPrefix {
+			return string(line), nil
+		}
+	}
+}
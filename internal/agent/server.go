@@ -0,0 +1,84 @@
+package agent
+
+import (
+	"bufio"
+	"crypto/subtle"
+	"fmt"
+	"io"
+	"net"
+	"time"
+
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+// requestTimeout bounds how long a client may take to send its request
+const requestTimeout = 10 * time.Second
+
+// dialTimeout bounds how long the agent tries to reach a target
+const dialTimeout = 5 * time.Second
+
+// Server is the in-cluster side of agent mode: it routes each connection it accepts
+// to the target the client names
+type Server struct {
+	token  string // Required from clients when set
+	logger *utils.Logger
+}
+
+// NewServer creates an agent server; an empty token accepts every client
+func NewServer(token string, logger *utils.Logger) *Server {
+	return &Server{token: token, logger: logger}
+}
+
+// Serve accepts connections on listener until it is closed
+func (s *Server) Serve(listener net.Listener) error {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return err
+		}
+		go s.handle(conn)
+	}
+}
+
+// handle reads a connection's request, connects it to the target and relays its traffic
+func (s *Server) handle(conn net.Conn) {
+	defer conn.Close()
+
+	conn.SetReadDeadline(time.Now().Add(requestTimeout))
+	reader := bufio.NewReader(conn)
+	token, target, err := readRequest(reader)
+	if err != nil {
+		s.logger.Debug("Rejected connection from %s: %v", conn.RemoteAddr(), err)
+		return
+	}
+	conn.SetReadDeadline(time.Time{})
+
+	if s.token != "" && subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+		s.logger.Warn("Rejected connection from %s to %s: invalid token", conn.RemoteAddr(), target)
+		fmt.Fprintf(conn, "ERR invalid token\n")
+		return
+	}
+
+	backend, err := net.DialTimeout("tcp", target, dialTimeout)
+	if err != nil {
+		fmt.Fprintf(conn, "ERR %v\n", err)
+		return
+	}
+	defer backend.Close()
+	if _, err := io.WriteString(conn, "OK\n"); err != nil {
+		return
+	}
+
+	// The reader may hold bytes the client sent right after its request
+	done := make(chan struct{})
+	go func() {
+		io.Copy(backend, reader)
+		if tcp, ok := backend.(*net.TCPConn); ok {
+			tcp.CloseWrite()
+		}
+		close(done)
+	}()
+	io.Copy(conn, backend)
+	conn.Close()
+	<-done
+}
@@ -0,0 +1,159 @@
+package agent
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+// Tunnel reaches in-cluster targets through the agent, via the local end of the
+// forward to it
+type Tunnel struct {
+	Token   string
+	Address func() (string, error) // Local address of the forward to the agent, which can move when it restarts
+}
+
+// Dial connects to target (host:port as seen from the agent's pod) through the agent
+func (t *Tunnel) Dial(ctx context.Context, target string) (net.Conn, error) {
+	address, err := t.Address()
+	if err != nil {
+		return nil, err
+	}
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("agent tunnel is down: %w", err)
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	reader := bufio.NewReader(conn)
+	if err := writeRequest(conn, t.Token, target); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to send request to agent: %w", err)
+	}
+	if err := readReply(reader); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	conn.SetDeadline(time.Time{})
+	return &bufferedConn{Conn: conn, reader: reader}, nil
+}
+
+// bufferedConn reads through the reader that consumed the agent's reply
+type bufferedConn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+// Read implements net.Conn
+func (c *bufferedConn) Read(p []byte) (int, error) {
+	return c.reader.Read(p)
+}
+
+// CloseWrite half-closes the underlying TCP connection
+func (c *bufferedConn) CloseWrite() error {
+	if tcp, ok := c.Conn.(*net.TCPConn); ok {
+		return tcp.CloseWrite()
+	}
+	return c.Conn.Close()
+}
+
+// Forward listens on a local port and connects everything it accepts to one target
+// through the tunnel, taking the place of a kubectl port-forward process
+type Forward struct {
+	tunnel   *Tunnel
+	target   string
+	listener net.Listener
+	logger   *utils.Logger
+
+	done    chan struct{}
+	errOnce sync.Once
+	err     error
+}
+
+// Listen starts a forward from bindAddress:port (localhost when "") to target
+func (t *Tunnel) Listen(bindAddress string, port int, target string, logger *utils.Logger) (*Forward, error) {
+	if bindAddress == "" {
+		bindAddress = "127.0.0.1"
+	}
+	listener, err := net.Listen("tcp", net.JoinHostPort(bindAddress, strconv.Itoa(port)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on port %d: %w", port, err)
+	}
+
+	f := &Forward{tunnel: t, target: target, listener: listener, logger: logger, done: make(chan struct{})}
+	go f.serve()
+	return f, nil
+}
+
+// Probe checks that the agent can reach the target
+func (f *Forward) Probe(ctx context.Context) error {
+	conn, err := f.tunnel.Dial(ctx, f.target)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+// Done is closed when the forward stops listening
+func (f *Forward) Done() <-chan struct{} {
+	return f.done
+}
+
+// Err returns why the forward stopped listening; nil after Close
+func (f *Forward) Err() error {
+	<-f.done
+	return f.err
+}
+
+// Close stops listening; connections already established are left to finish
+func (f *Forward) Close() error {
+	f.errOnce.Do(func() {})
+	return f.listener.Close()
+}
+
+// serve accepts connections until the listener is closed
+func (f *Forward) serve() {
+	defer close(f.done)
+	for {
+		conn, err := f.listener.Accept()
+		if err != nil {
+			f.errOnce.Do(func() { f.err = err }) // Unless closed deliberately
+			return
+		}
+		go f.handle(conn)
+	}
+}
+
+// handle connects one local connection to the target
+func (f *Forward) handle(conn net.Conn) {
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	backend, err := f.tunnel.Dial(ctx, f.target)
+	cancel()
+	if err != nil {
+		f.logger.Warn("Failed to reach %s through the agent: %v", f.target, err)
+		return
+	}
+	defer backend.Close()
+
+	done := make(chan struct{})
+	go func() {
+		io.Copy(backend, conn)
+		backend.(*bufferedConn).CloseWrite()
+		close(done)
+	}()
+	io.Copy(conn, backend)
+	conn.Close()
+	<-done
+}
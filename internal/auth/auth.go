@@ -0,0 +1,331 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+const (
+	FlowClientCredentials = "client_credentials"
+	FlowDeviceCode        = "device_code"
+)
+
+// TokenProvider obtains, caches and refreshes tokens for a service and
+// injects them into outgoing requests
+type TokenProvider struct {
+	name      string
+	cfg       config.AuthConfig
+	logger    *utils.Logger
+	cachePath string
+	client    *http.Client
+
+	// openURL shows the device verification page; overridden in tests
+	openURL func(url string) error
+
+	source    oauth2.TokenSource
+	lastSaved string
+	mutex     sync.Mutex
+}
+
+// Providers are shared across service restarts so tokens survive relay recreation
+var (
+	providers     = make(map[string]*TokenProvider)
+	providerMutex sync.Mutex
+)
+
+// ProviderFor returns the token provider for a service, creating it on first use
+func ProviderFor(name string, cfg config.AuthConfig, logger *utils.Logger) (*TokenProvider, error) {
+	providerMutex.Lock()
+	defer providerMutex.Unlock()
+
+	if provider, exists := providers[name]; exists {
+		return provider, nil
+	}
+
+	provider, err := NewTokenProvider(name, cfg, logger)
+	if err != nil {
+		return nil, err
+	}
+	providers[name] = provider
+	return provider, nil
+}
+
+// NewTokenProvider validates the auth configuration and creates a provider
+func NewTokenProvider(name string, cfg config.AuthConfig, logger *utils.Logger) (*TokenProvider, error) {
+	switch cfg.Flow {
+	case FlowClientCredentials, FlowDeviceCode:
+	default:
+		return nil, fmt.Errorf("unsupported auth flow %q for %s (use %s or %s)", cfg.Flow, name, FlowClientCredentials, FlowDeviceCode)
+	}
+	if cfg.ClientID == "" {
+		return nil, fmt.Errorf("auth for %s requires clientID", name)
+	}
+	if cfg.Issuer == "" && cfg.TokenURL == "" {
+		return nil, fmt.Errorf("auth for %s requires issuer or tokenURL", name)
+	}
+
+	provider := &TokenProvider{
+		name:    name,
+		cfg:     cfg,
+		logger:  logger,
+		client:  &http.Client{Timeout: 30 * time.Second},
+		openURL: utils.OpenBrowser,
+	}
+
+	// Device-code tokens are cached on disk so users aren't prompted on every run
+	if cfg.Flow == FlowDeviceCode {
+		if configDir, err := config.GetUserConfigDir(); err == nil {
+			provider.cachePath = filepath.Join(configDir, "tokens", name+".json")
+		}
+	}
+
+	return provider, nil
+}
+
+// Authorize adds the token header to a request unless the client already set it
+func (p *TokenProvider) Authorize(req *http.Request) error {
+	header := p.cfg.Header
+	if header == "" {
+		header = "Authorization"
+	}
+	if req.Header.Get(header) != "" {
+		return nil
+	}
+
+	token, err := p.Token(req.Context())
+	if err != nil {
+		return err
+	}
+
+	if strings.EqualFold(header, "Authorization") {
+		req.Header.Set(header, token.Type()+" "+token.AccessToken)
+	} else {
+		req.Header.Set(header, token.AccessToken)
+	}
+	return nil
+}
+
+// Token returns a valid access token, running the configured flow when needed
+func (p *TokenProvider) Token(ctx context.Context) (*oauth2.Token, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.source == nil {
+		source, err := p.newSource(ctx)
+		if err != nil {
+			return nil, err
+		}
+		p.source = source
+	}
+
+	token, err := p.source.Token()
+	if err != nil {
+		// Start over on the next request, e.g. when a refresh token was revoked
+		p.source = nil
+		p.removeCache()
+		return nil, fmt.Errorf("failed to obtain token for %s: %w", p.name, err)
+	}
+
+	p.saveCache(token)
+	return token, nil
+}
+
+// newSource creates the token source for the configured flow
+func (p *TokenProvider) newSource(ctx context.Context) (oauth2.TokenSource, error) {
+	endpoint, err := p.endpoint(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Token refreshes happen outside any single request
+	background := context.WithValue(context.Background(), oauth2.HTTPClient, p.client)
+	var params []oauth2.AuthCodeOption
+	if p.cfg.Audience != "" {
+		params = append(params, oauth2.SetAuthURLParam("audience", p.cfg.Audience))
+	}
+
+	if p.cfg.Flow == FlowClientCredentials {
+		ccConfig := &clientcredentials.Config{
+			ClientID:     p.cfg.ClientID,
+			ClientSecret: p.clientSecret(),
+			TokenURL:     endpoint.TokenURL,
+			Scopes:       p.cfg.Scopes,
+		}
+		if p.cfg.Audience != "" {
+			ccConfig.EndpointParams = map[string][]string{"audience": {p.cfg.Audience}}
+		}
+		return ccConfig.TokenSource(background), nil
+	}
+
+	oauthConfig := &oauth2.Config{
+		ClientID:     p.cfg.ClientID,
+		ClientSecret: p.clientSecret(),
+		Endpoint:     endpoint,
+		Scopes:       p.cfg.Scopes,
+	}
+
+	if cached := p.loadCache(); cached != nil {
+		return oauthConfig.TokenSource(background, cached), nil
+	}
+
+	// Sign-in can take minutes; don't tie it to the request that triggered it
+	token, err := p.deviceFlow(background, oauthConfig, params)
+	if err != nil {
+		return nil, err
+	}
+	return oauthConfig.TokenSource(background, token), nil
+}
+
+// deviceFlow runs the OAuth 2.0 device authorization grant
+func (p *TokenProvider) deviceFlow(ctx context.Context, oauthConfig *oauth2.Config, params []oauth2.AuthCodeOption) (*oauth2.Token, error) {
+	if oauthConfig.Endpoint.DeviceAuthURL == "" {
+		return nil, fmt.Errorf("no device authorization endpoint for %s", p.name)
+	}
+
+	response, err := oauthConfig.DeviceAuth(ctx, params...)
+	if err != nil {
+		return nil, fmt.Errorf("device authorization failed for %s: %w", p.name, err)
+	}
+
+	verificationURL := response.VerificationURIComplete
+	if verificationURL == "" {
+		verificationURL = response.VerificationURI
+	}
+	p.logger.Warn("Sign in for %s: visit %s and enter code %s", p.name, response.VerificationURI, response.UserCode)
+	if err := p.openURL(verificationURL); err != nil {
+		p.logger.Debug("Failed to open browser for %s sign-in: %v", p.name, err)
+	}
+
+	token, err := oauthConfig.DeviceAccessToken(ctx, response, params...)
+	if err != nil {
+		return nil, fmt.Errorf("device sign-in failed for %s: %w", p.name, err)
+	}
+	p.logger.Info("Signed in for %s", p.name)
+	return token, nil
+}
+
+// clientSecret returns the configured secret, preferring the environment variable
+func (p *TokenProvider) clientSecret() string {
+	if p.cfg.ClientSecretEnv != "" {
+		if secret := os.Getenv(p.cfg.ClientSecretEnv); secret != "" {
+			return secret
+		}
+	}
+	return p.cfg.ClientSecret
+}
+
+// endpoint returns the token endpoints, discovering them from the issuer when not configured
+func (p *TokenProvider) endpoint(ctx context.Context) (oauth2.Endpoint, error) {
+	endpoint := oauth2.Endpoint{
+		TokenURL:      p.cfg.TokenURL,
+		DeviceAuthURL: p.cfg.DeviceAuthURL,
+	}
+	needsDiscovery := endpoint.TokenURL == "" || (p.cfg.Flow == FlowDeviceCode && endpoint.DeviceAuthURL == "")
+	if p.cfg.Issuer == "" || !needsDiscovery {
+		return endpoint, nil
+	}
+
+	discovery, err := discover(ctx, p.client, p.cfg.Issuer)
+	if err != nil {
+		return endpoint, err
+	}
+	if endpoint.TokenURL == "" {
+		endpoint.TokenURL = discovery.TokenEndpoint
+	}
+	if endpoint.DeviceAuthURL == "" {
+		endpoint.DeviceAuthURL = discovery.DeviceAuthorizationEndpoint
+	}
+	return endpoint, nil
+}
+
+// discoveryDocument holds the fields of the OIDC discovery document we need
+type discoveryDocument struct {
+	TokenEndpoint               string `json:"token_endpoint"`
+	DeviceAuthorizationEndpoint string `json:"device_authorization_endpoint"`
+}
+
+// discover fetches the OIDC discovery document of an issuer
+func discover(ctx context.Context, client *http.Client, issuer string) (*discoveryDocument, error) {
+	url := strings.TrimSuffix(issuer, "/") + "/.well-known/openid-configuration"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("OIDC discovery failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("OIDC discovery returned status %d from %s", resp.StatusCode, url)
+	}
+
+	var doc discoveryDocument
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to parse OIDC discovery document: %w", err)
+	}
+	if doc.TokenEndpoint == "" {
+		return nil, fmt.Errorf("OIDC discovery document from %s has no token endpoint", url)
+	}
+	return &doc, nil
+}
+
+// loadCache returns the cached device-code token, if any
+func (p *TokenProvider) loadCache() *oauth2.Token {
+	if p.cachePath == "" {
+		return nil
+	}
+	data, err := os.ReadFile(p.cachePath)
+	if err != nil {
+		return nil
+	}
+	var token oauth2.Token
+	if err := json.Unmarshal(data, &token); err != nil {
+		return nil
+	}
+	// An expired token without a refresh token is useless
+	if !token.Valid() && token.RefreshToken == "" {
+		return nil
+	}
+	return &token
+}
+
+// saveCache persists refreshed device-code tokens
+func (p *TokenProvider) saveCache(token *oauth2.Token) {
+	if p.cachePath == "" || token.AccessToken == p.lastSaved {
+		return
+	}
+
+	data, err := json.Marshal(token)
+	if err != nil {
+		return
+	}
+	if err := utils.WriteFileAtomic(p.cachePath, data, 0600); err != nil {
+		p.logger.Warn("Failed to cache token for %s: %v", p.name, err)
+		return
+	}
+	p.lastSaved = token.AccessToken
+}
+
+// removeCache deletes the cached token after it stopped working
+func (p *TokenProvider) removeCache() {
+	if p.cachePath != "" {
+		os.Remove(p.cachePath)
+		p.lastSaved = ""
+	}
+}
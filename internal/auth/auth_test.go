@@ -0,0 +1,150 @@
+package auth
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+// newIdentityProvider serves OIDC discovery, client-credentials and device-code endpoints
+func newIdentityProvider(t *testing.T) (*httptest.Server, *int32) {
+	var tokensIssued int32
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"token_endpoint":                server.URL + "/token",
+			"device_authorization_endpoint": server.URL + "/device",
+		})
+	})
+	mux.HandleFunc("/device", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"device_code":      "device-123",
+			"user_code":        "ABCD-EFGH",
+			"verification_uri": server.URL + "/activate",
+			"interval":         1,
+			"expires_in":       60,
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if r.Form.Get("audience") != "api://test" {
+			http.Error(w, `{"error":"invalid_request"}`, http.StatusBadRequest)
+			return
+		}
+		atomic.AddInt32(&tokensIssued, 1)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token":  "token-for-" + r.Form.Get("grant_type"),
+			"token_type":    "Bearer",
+			"expires_in":    3600,
+			"refresh_token": "refresh",
+		})
+	})
+
+	t.Cleanup(server.Close)
+	return server, &tokensIssued
+}
+
+func TestClientCredentialsAuthorize(t *testing.T) {
+	server, tokensIssued := newIdentityProvider(t)
+
+	provider, err := NewTokenProvider("api", config.AuthConfig{
+		Flow:     FlowClientCredentials,
+		Issuer:   server.URL,
+		ClientID: "kportforward",
+		Audience: "api://test",
+	}, utils.NewLogger(utils.LevelError))
+	if err != nil {
+		t.Fatalf("NewTokenProvider failed: %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if err := provider.Authorize(req); err != nil {
+			t.Fatalf("Authorize failed: %v", err)
+		}
+		if got := req.Header.Get("Authorization"); got != "Bearer token-for-client_credentials" {
+			t.Errorf("Unexpected Authorization header: %q", got)
+		}
+	}
+
+	// The token is cached until it expires
+	if *tokensIssued != 1 {
+		t.Errorf("Expected 1 token request, got %d", *tokensIssued)
+	}
+
+	// Requests that already carry credentials are left alone
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Authorization", "Bearer mine")
+	provider.Authorize(req)
+	if req.Header.Get("Authorization") != "Bearer mine" {
+		t.Error("Expected an explicit Authorization header to be preserved")
+	}
+}
+
+func TestDeviceCodeFlowCachesToken(t *testing.T) {
+	server, _ := newIdentityProvider(t)
+	cfg := config.AuthConfig{
+		Flow:     FlowDeviceCode,
+		Issuer:   server.URL,
+		ClientID: "kportforward",
+		Audience: "api://test",
+		Header:   "X-Api-Token",
+	}
+	cachePath := filepath.Join(t.TempDir(), "tokens", "api.json")
+
+	provider, err := NewTokenProvider("api", cfg, utils.NewLogger(utils.LevelError))
+	if err != nil {
+		t.Fatalf("NewTokenProvider failed: %v", err)
+	}
+	provider.cachePath = cachePath
+	var opened string
+	provider.openURL = func(url string) error {
+		opened = url
+		return nil
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	if err := provider.Authorize(req); err != nil {
+		t.Fatalf("Authorize failed: %v", err)
+	}
+	if got := req.Header.Get("X-Api-Token"); got != "token-for-urn:ietf:params:oauth:grant-type:device_code" {
+		t.Errorf("Unexpected token header: %q", got)
+	}
+	if opened != server.URL+"/activate" {
+		t.Errorf("Expected verification page to be opened, got %q", opened)
+	}
+
+	// A new provider reuses the cached token without prompting again
+	second, _ := NewTokenProvider("api", cfg, utils.NewLogger(utils.LevelError))
+	second.cachePath = cachePath
+	second.openURL = func(url string) error {
+		t.Error("Expected cached token to be used without sign-in")
+		return nil
+	}
+	if err := second.Authorize(httptest.NewRequest(http.MethodGet, "/", nil)); err != nil {
+		t.Fatalf("Authorize with cached token failed: %v", err)
+	}
+}
+
+func TestNewTokenProviderValidation(t *testing.T) {
+	logger := utils.NewLogger(utils.LevelError)
+
+	if _, err := NewTokenProvider("api", config.AuthConfig{Flow: "password", ClientID: "x", TokenURL: "http://x"}, logger); err == nil {
+		t.Error("Expected error for unsupported flow")
+	}
+	if _, err := NewTokenProvider("api", config.AuthConfig{Flow: FlowClientCredentials, TokenURL: "http://x"}, logger); err == nil {
+		t.Error("Expected error for missing clientID")
+	}
+	if _, err := NewTokenProvider("api", config.AuthConfig{Flow: FlowClientCredentials, ClientID: "x"}, logger); err == nil {
+		t.Error("Expected error for missing issuer and tokenURL")
+	}
+}
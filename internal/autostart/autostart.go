@@ -0,0 +1,65 @@
+package autostart
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Name is the name kportforward is registered under with the service manager
+const Name = "kportforward"
+
+// ErrNotInstalled is returned by Uninstall when kportforward isn't installed
+var ErrNotInstalled = errors.New("kportforward is not installed as a service")
+
+// Spec describes how the service manager runs kportforward
+type Spec struct {
+	Executable string
+	Args       []string          // Starting with --headless
+	Env        map[string]string // Environment captured at install time
+}
+
+// NewSpec returns a spec running this executable headless with the given extra
+// arguments and the current PATH and KUBECONFIG, which service managers don't
+// inherit from the login shell (kubectl auth plugins are often found via PATH)
+func NewSpec(args []string) (Spec, error) {
+	executable, err := os.Executable()
+	if err != nil {
+		return Spec{}, fmt.Errorf("failed to locate the kportforward executable: %w", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(executable); err == nil {
+		executable = resolved
+	}
+
+	spec := Spec{
+		Executable: executable,
+		Args:       append([]string{"--headless"}, args...),
+		Env:        make(map[string]string),
+	}
+	for _, key := range capturedEnv {
+		if value, ok := os.LookupEnv(key); ok {
+			spec.Env[key] = value
+		}
+	}
+	return spec, nil
+}
+
+// Status describes the installed service
+type Status struct {
+	Installed bool
+	Running   bool
+	Detail    string // Where it is registered, and e.g. its PID
+}
+
+// String renders the status for 'kportforward service status'
+func (s Status) String() string {
+	switch {
+	case !s.Installed:
+		return "not installed"
+	case s.Running:
+		return "installed and running (" + s.Detail + ")"
+	default:
+		return "installed but not running (" + s.Detail + ")"
+	}
+}
@@ -0,0 +1,72 @@
+package autostart
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSystemdUnit(t *testing.T) {
+	spec := Spec{
+		Executable: "/home/dev/bin/kportforward",
+		Args:       []string{"--headless", "--status-file", "/home/dev/My Status/status.json"},
+		Env:        map[string]string{"PATH": "/usr/bin:/home/dev/bin", "KUBECONFIG": "/home/dev/.kube/100%.yaml"},
+	}
+
+	unit := systemdUnit(spec)
+	for _, expected := range []string{
+		`ExecStart=/home/dev/bin/kportforward --headless --status-file "/home/dev/My Status/status.json"` + "\n",
+		"Environment=KUBECONFIG=/home/dev/.kube/100%%.yaml\nEnvironment=PATH=/usr/bin:/home/dev/bin\n",
+		"Restart=on-failure\n",
+		"WantedBy=default.target\n",
+	} {
+		if !strings.Contains(unit, expected) {
+			t.Errorf("Expected unit to contain %q, got:\n%s", expected, unit)
+		}
+	}
+}
+
+func TestSystemdQuote(t *testing.T) {
+	tests := map[string]string{
+		"--headless":   "--headless",
+		"a b":          `"a b"`,
+		`say "hi"`:     `"say \"hi\""`,
+		"":             `""`,
+		"50%":          "50%%",
+		`C:\path with`: `"C:\\path with"`,
+	}
+	for word, expected := range tests {
+		if got := systemdQuote(word); got != expected {
+			t.Errorf("systemdQuote(%q): expected %s, got %s", word, expected, got)
+		}
+	}
+}
+
+func TestLaunchdPlist(t *testing.T) {
+	spec := Spec{
+		Executable: "/usr/local/bin/kportforward",
+		Args:       []string{"--headless", "--dashboard-addr", "localhost:7080"},
+		Env:        map[string]string{"PATH": "/usr/bin&/opt/bin"},
+	}
+
+	plist := launchdPlist(spec, "/Users/dev/Library/Logs/kportforward.log")
+	for _, expected := range []string{
+		"<string>" + launchdLabel + "</string>",
+		"<string>/usr/local/bin/kportforward</string>\n\t\t<string>--headless</string>\n\t\t<string>--dashboard-addr</string>\n\t\t<string>localhost:7080</string>",
+		"<key>PATH</key>\n\t\t<string>/usr/bin&amp;/opt/bin</string>",
+		"<key>KeepAlive</key>\n\t<true/>",
+		"<key>StandardOutPath</key>\n\t<string>/Users/dev/Library/Logs/kportforward.log</string>",
+	} {
+		if !strings.Contains(plist, expected) {
+			t.Errorf("Expected plist to contain %q, got:\n%s", expected, plist)
+		}
+	}
+}
+
+func TestStatusString(t *testing.T) {
+	if got := (Status{}).String(); got != "not installed" {
+		t.Errorf("Unexpected status: %s", got)
+	}
+	if got := (Status{Installed: true, Running: true, Detail: "PID 42"}).String(); got != "installed and running (PID 42)" {
+		t.Errorf("Unexpected status: %s", got)
+	}
+}
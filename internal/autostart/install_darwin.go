@@ -0,0 +1,102 @@
+//go:build darwin
+
+package autostart
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// capturedEnv is the environment copied into the LaunchAgent
+var capturedEnv = []string{"PATH", "KUBECONFIG"}
+
+// pidPattern finds the PID in launchctl print output
+var pidPattern = regexp.MustCompile(`(?m)^\s*pid = (\d+)`)
+
+// agentPaths returns where the LaunchAgent and its log are written
+func agentPaths() (string, string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", "", fmt.Errorf("failed to locate the home directory: %w", err)
+	}
+	return filepath.Join(home, "Library", "LaunchAgents", launchdLabel+".plist"),
+		filepath.Join(home, "Library", "Logs", Name+".log"), nil
+}
+
+// domain returns the launchd domain of the user's GUI session
+func domain() string {
+	return fmt.Sprintf("gui/%d", os.Getuid())
+}
+
+// Install writes a LaunchAgent for the spec and (re)loads it
+func Install(spec Spec) error {
+	path, logFile, err := agentPaths()
+	if err != nil {
+		return err
+	}
+	for _, dir := range []string{filepath.Dir(path), filepath.Dir(logFile)} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create %s: %w", dir, err)
+		}
+	}
+	if err := os.WriteFile(path, []byte(launchdPlist(spec, logFile)), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	launchctl("bootout", domain()+"/"+launchdLabel) // Not loaded on a first install
+	return launchctl("bootstrap", domain(), path)
+}
+
+// Uninstall unloads and removes the LaunchAgent
+func Uninstall() error {
+	path, _, err := agentPaths()
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return ErrNotInstalled
+	}
+
+	launchctl("bootout", domain()+"/"+launchdLabel) // Already unloaded if it kept crashing
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("failed to remove %s: %w", path, err)
+	}
+	return nil
+}
+
+// CurrentStatus reports whether the LaunchAgent is installed and has a running process
+func CurrentStatus() (Status, error) {
+	path, logFile, err := agentPaths()
+	if err != nil {
+		return Status{}, err
+	}
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return Status{}, nil
+	}
+
+	status := Status{Installed: true, Detail: fmt.Sprintf("LaunchAgent %s, not loaded; logs: %s", path, logFile)}
+	output, err := exec.Command("launchctl", "print", domain()+"/"+launchdLabel).Output()
+	if err != nil {
+		return status, nil
+	}
+	if match := pidPattern.FindSubmatch(output); match != nil {
+		status.Running = true
+		status.Detail = fmt.Sprintf("LaunchAgent %s, PID %s; logs: %s", path, match[1], logFile)
+	} else {
+		status.Detail = fmt.Sprintf("LaunchAgent %s, loaded; logs: %s", path, logFile)
+	}
+	return status, nil
+}
+
+// launchctl runs a launchctl command
+func launchctl(args ...string) error {
+	output, err := exec.Command("launchctl", args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("launchctl %s failed: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+//go:build linux
+
+package autostart
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// capturedEnv is the environment copied into the unit
+var capturedEnv = []string{"PATH", "KUBECONFIG"}
+
+// unitName is the systemd user unit kportforward runs as
+const unitName = Name + ".service"
+
+// unitPath returns where the systemd user unit is written
+func unitPath() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate the user config directory: %w", err)
+	}
+	return filepath.Join(configDir, "systemd", "user", unitName), nil
+}
+
+// Install writes a systemd user unit for the spec and (re)starts it
+func Install(spec Spec) error {
+	path, err := unitPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+	if err := os.WriteFile(path, []byte(systemdUnit(spec)), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	if err := systemctl("daemon-reload"); err != nil {
+		return err
+	}
+	if err := systemctl("enable", unitName); err != nil {
+		return err
+	}
+	return systemctl("restart", unitName)
+}
+
+// Uninstall stops and removes the systemd user unit
+func Uninstall() error {
+	path, err := unitPath()
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return ErrNotInstalled
+	}
+
+	if err := systemctl("disable", "--now", unitName); err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("failed to remove %s: %w", path, err)
+	}
+	return systemctl("daemon-reload")
+}
+
+// CurrentStatus reports whether the systemd user unit is installed and active
+func CurrentStatus() (Status, error) {
+	path, err := unitPath()
+	if err != nil {
+		return Status{}, err
+	}
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return Status{}, nil
+	}
+
+	// is-active exits non-zero for anything but active, printing the state either way
+	output, _ := exec.Command("systemctl", "--user", "is-active", unitName).Output()
+	state := strings.TrimSpace(string(output))
+	if state == "" {
+		state = "unknown"
+	}
+	return Status{
+		Installed: true,
+		Running:   state == "active",
+		Detail:    fmt.Sprintf("systemd user unit %s, %s; logs: journalctl --user -u %s", path, state, Name),
+	}, nil
+}
+
+// systemctl runs a systemctl --user command
+func systemctl(args ...string) error {
+	output, err := exec.Command("systemctl", append([]string{"--user"}, args...)...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("systemctl --user %s failed: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
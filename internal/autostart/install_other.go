@@ -0,0 +1,26 @@
+//go:build !linux && !darwin && !windows
+
+package autostart
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// capturedEnv is unused where installing isn't supported
+var capturedEnv []string
+
+// Install is not supported on this platform
+func Install(spec Spec) error {
+	return fmt.Errorf("installing as a service is not supported on %s", runtime.GOOS)
+}
+
+// Uninstall is not supported on this platform
+func Uninstall() error {
+	return fmt.Errorf("installing as a service is not supported on %s", runtime.GOOS)
+}
+
+// CurrentStatus is not supported on this platform
+func CurrentStatus() (Status, error) {
+	return Status{}, fmt.Errorf("installing as a service is not supported on %s", runtime.GOOS)
+}
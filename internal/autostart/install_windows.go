@@ -0,0 +1,165 @@
+//go:build windows
+
+package autostart
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/windows/registry"
+	"golang.org/x/sys/windows/svc"
+	"golang.org/x/sys/windows/svc/mgr"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+)
+
+// capturedEnv is the environment set on the service. The service runs as LocalSystem,
+// so the profile variables point it at the installing user's config and kubeconfig.
+var capturedEnv = []string{"PATH", "KUBECONFIG", "USERPROFILE", "APPDATA", "LOCALAPPDATA", "HOME"}
+
+// Install registers a Windows service for the spec, starting automatically and
+// restarted by the service manager when it fails, and starts it. A service that is
+// already registered is reconfigured and restarted.
+func Install(spec Spec) error {
+	configDir, err := config.GetUserConfigDir()
+	if err != nil {
+		return err
+	}
+	args := append(spec.Args, "--log-file", filepath.Join(configDir, Name+".log"))
+
+	m, err := mgr.Connect()
+	if err != nil {
+		return fmt.Errorf("failed to connect to the service manager (run as administrator): %w", err)
+	}
+	defer m.Disconnect()
+
+	s, err := m.OpenService(Name)
+	if err == nil {
+		stopService(s)
+		serviceConfig, err := s.Config()
+		if err == nil {
+			serviceConfig.BinaryPathName = commandLine(spec.Executable, args)
+			err = s.UpdateConfig(serviceConfig)
+		}
+		if err != nil {
+			s.Close()
+			return fmt.Errorf("failed to update service %s: %w", Name, err)
+		}
+	} else {
+		s, err = m.CreateService(Name, spec.Executable, mgr.Config{
+			DisplayName: "kportforward",
+			Description: "Kubernetes port-forward manager",
+			StartType:   mgr.StartAutomatic,
+		}, args...)
+		if err != nil {
+			return fmt.Errorf("failed to create service %s: %w", Name, err)
+		}
+	}
+	defer s.Close()
+
+	if err := s.SetRecoveryActions([]mgr.RecoveryAction{{Type: mgr.ServiceRestart, Delay: 5 * time.Second}}, 24*60*60); err != nil {
+		return fmt.Errorf("failed to set restart on failure: %w", err)
+	}
+	if err := setEnvironment(spec.Env); err != nil {
+		return err
+	}
+	if err := s.Start(); err != nil {
+		return fmt.Errorf("failed to start service %s: %w", Name, err)
+	}
+	return nil
+}
+
+// Uninstall stops and deletes the Windows service
+func Uninstall() error {
+	m, err := mgr.Connect()
+	if err != nil {
+		return fmt.Errorf("failed to connect to the service manager (run as administrator): %w", err)
+	}
+	defer m.Disconnect()
+
+	s, err := m.OpenService(Name)
+	if err != nil {
+		return ErrNotInstalled
+	}
+	defer s.Close()
+
+	stopService(s)
+	if err := s.Delete(); err != nil {
+		return fmt.Errorf("failed to delete service %s: %w", Name, err)
+	}
+	return nil
+}
+
+// CurrentStatus reports whether the Windows service is registered and running
+func CurrentStatus() (Status, error) {
+	m, err := mgr.Connect()
+	if err != nil {
+		return Status{}, fmt.Errorf("failed to connect to the service manager: %w", err)
+	}
+	defer m.Disconnect()
+
+	s, err := m.OpenService(Name)
+	if err != nil {
+		return Status{}, nil
+	}
+	defer s.Close()
+
+	status, err := s.Query()
+	if err != nil {
+		return Status{}, fmt.Errorf("failed to query service %s: %w", Name, err)
+	}
+	detail := "Windows service " + Name
+	if status.State == svc.Running {
+		detail += fmt.Sprintf(", PID %d", status.ProcessId)
+	}
+	if configDir, err := config.GetUserConfigDir(); err == nil {
+		detail += "; logs: " + filepath.Join(configDir, Name+".log")
+	}
+	return Status{Installed: true, Running: status.State == svc.Running, Detail: detail}, nil
+}
+
+// stopService asks a running service to stop and waits up to 15s for it
+func stopService(s *mgr.Service) {
+	status, err := s.Control(svc.Stop)
+	if err != nil {
+		return // Not running
+	}
+	deadline := time.Now().Add(15 * time.Second)
+	for status.State != svc.Stopped && time.Now().Before(deadline) {
+		time.Sleep(300 * time.Millisecond)
+		if status, err = s.Query(); err != nil {
+			return
+		}
+	}
+}
+
+// setEnvironment sets the service's environment, which the service manager reads
+// from the service's registry key
+func setEnvironment(env map[string]string) error {
+	key, err := registry.OpenKey(registry.LOCAL_MACHINE, `SYSTEM\CurrentControlSet\Services\`+Name, registry.SET_VALUE)
+	if err != nil {
+		return fmt.Errorf("failed to open the service's registry key: %w", err)
+	}
+	defer key.Close()
+
+	values := make([]string, 0, len(env))
+	for _, name := range sortedKeys(env) {
+		values = append(values, name+"="+env[name])
+	}
+	if err := key.SetStringsValue("Environment", values); err != nil {
+		return fmt.Errorf("failed to set the service's environment: %w", err)
+	}
+	return nil
+}
+
+// commandLine joins an executable and its arguments into a Windows command line
+func commandLine(executable string, args []string) string {
+	parts := []string{syscall.EscapeArg(executable)}
+	for _, arg := range args {
+		parts = append(parts, syscall.EscapeArg(arg))
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,9 @@
+//go:build !windows
+
+package autostart
+
+import "os"
+
+// NotifyStop does nothing outside Windows, where service managers stop kportforward
+// with SIGTERM
+func NotifyStop(c chan<- os.Signal) {}
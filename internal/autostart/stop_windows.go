@@ -0,0 +1,38 @@
+//go:build windows
+
+package autostart
+
+import (
+	"os"
+	"syscall"
+
+	"golang.org/x/sys/windows/svc"
+)
+
+// NotifyStop relays the service manager's stop and shutdown requests to c as SIGTERM
+// when kportforward runs as a Windows service; otherwise it does nothing
+func NotifyStop(c chan<- os.Signal) {
+	if isService, err := svc.IsWindowsService(); err != nil || !isService {
+		return
+	}
+	go svc.Run(Name, stopHandler(c))
+}
+
+// stopHandler reports the service running until it is asked to stop
+type stopHandler chan<- os.Signal
+
+// Execute implements svc.Handler
+func (h stopHandler) Execute(args []string, requests <-chan svc.ChangeRequest, status chan<- svc.Status) (bool, uint32) {
+	status <- svc.Status{State: svc.Running, Accepts: svc.AcceptStop | svc.AcceptShutdown}
+	for request := range requests {
+		switch request.Cmd {
+		case svc.Interrogate:
+			status <- request.CurrentStatus
+		case svc.Stop, svc.Shutdown:
+			status <- svc.Status{State: svc.StopPending}
+			h <- syscall.SIGTERM
+			return false, 0
+		}
+	}
+	return false, 0
+}
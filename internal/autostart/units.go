@@ -0,0 +1,99 @@
+package autostart
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// sortedKeys returns the keys of env in order, so generated files are stable
+func sortedKeys(env map[string]string) []string {
+	keys := make([]string, 0, len(env))
+	for key := range env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// systemdUnit renders a systemd user unit running the spec, restarted when it fails
+func systemdUnit(spec Spec) string {
+	var b strings.Builder
+	b.WriteString("[Unit]\n")
+	b.WriteString("Description=kportforward Kubernetes port-forward manager\n")
+	b.WriteString("After=network-online.target\n\n")
+	b.WriteString("[Service]\n")
+	b.WriteString("ExecStart=" + systemdQuote(spec.Executable))
+	for _, arg := range spec.Args {
+		b.WriteString(" " + systemdQuote(arg))
+	}
+	b.WriteString("\n")
+	for _, key := range sortedKeys(spec.Env) {
+		b.WriteString("Environment=" + systemdQuote(key+"="+spec.Env[key]) + "\n")
+	}
+	b.WriteString("Restart=on-failure\n")
+	b.WriteString("RestartSec=5\n\n")
+	b.WriteString("[Install]\n")
+	b.WriteString("WantedBy=default.target\n")
+	return b.String()
+}
+
+// systemdQuote quotes a word for a unit file when needed; % starts a specifier there
+func systemdQuote(word string) string {
+	word = strings.ReplaceAll(word, "%", "%%")
+	if word != "" && !strings.ContainsAny(word, " \t\"'\\") {
+		return word
+	}
+	word = strings.ReplaceAll(word, `\`, `\\`)
+	word = strings.ReplaceAll(word, `"`, `\"`)
+	return `"` + word + `"`
+}
+
+// launchdLabel identifies the LaunchAgent
+const launchdLabel = "com.catio-tech.kportforward"
+
+// launchdPlist renders a LaunchAgent running the spec at login and keeping it
+// alive, with its output in logFile
+func launchdPlist(spec Spec, logFile string) string {
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+	b.WriteString(`<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">` + "\n")
+	b.WriteString(`<plist version="1.0">` + "\n<dict>\n")
+	plistString(&b, "Label", launchdLabel)
+
+	b.WriteString("\t<key>ProgramArguments</key>\n\t<array>\n")
+	for _, arg := range append([]string{spec.Executable}, spec.Args...) {
+		fmt.Fprintf(&b, "\t\t<string>%s</string>\n", xmlEscape(arg))
+	}
+	b.WriteString("\t</array>\n")
+
+	if len(spec.Env) > 0 {
+		b.WriteString("\t<key>EnvironmentVariables</key>\n\t<dict>\n")
+		for _, key := range sortedKeys(spec.Env) {
+			fmt.Fprintf(&b, "\t\t<key>%s</key>\n\t\t<string>%s</string>\n", xmlEscape(key), xmlEscape(spec.Env[key]))
+		}
+		b.WriteString("\t</dict>\n")
+	}
+
+	b.WriteString("\t<key>RunAtLoad</key>\n\t<true/>\n")
+	b.WriteString("\t<key>KeepAlive</key>\n\t<true/>\n")
+	b.WriteString("\t<key>ThrottleInterval</key>\n\t<integer>10</integer>\n")
+	plistString(&b, "StandardOutPath", logFile)
+	plistString(&b, "StandardErrorPath", logFile)
+	b.WriteString("</dict>\n</plist>\n")
+	return b.String()
+}
+
+// plistString writes a string entry of the top-level dict
+func plistString(b *strings.Builder, key, value string) {
+	fmt.Fprintf(b, "\t<key>%s</key>\n\t<string>%s</string>\n", key, xmlEscape(value))
+}
+
+// xmlEscape escapes text for XML character data
+func xmlEscape(text string) string {
+	var buf bytes.Buffer
+	xml.EscapeText(&buf, []byte(text))
+	return buf.String()
+}
@@ -0,0 +1,177 @@
+package bench
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Mode represents the kind of load generated against a forward
+type Mode string
+
+const (
+	ModeTCP  Mode = "tcp"
+	ModeHTTP Mode = "http"
+)
+
+// Options configures a benchmark run
+type Options struct {
+	Port        int
+	Mode        Mode
+	Path        string
+	Duration    time.Duration
+	Concurrency int
+	Timeout     time.Duration
+}
+
+// Result contains the aggregated outcome of a benchmark run
+type Result struct {
+	Requests   int
+	Errors     int
+	Elapsed    time.Duration
+	Latencies  []time.Duration // Sorted latencies of successful requests
+	StatusHits map[int]int     // HTTP status code counts (HTTP mode only)
+	LastError  string
+}
+
+// Run drives load through the local port for the configured duration
+func Run(ctx context.Context, opts Options) (*Result, error) {
+	if opts.Port <= 0 {
+		return nil, fmt.Errorf("invalid port: %d", opts.Port)
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 1
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = 5 * time.Second
+	}
+
+	var probe func() (int, error)
+	switch opts.Mode {
+	case ModeHTTP:
+		probe = httpProbe(opts)
+	case ModeTCP, "":
+		probe = tcpProbe(opts)
+	default:
+		return nil, fmt.Errorf("unsupported mode: %s", opts.Mode)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, opts.Duration)
+	defer cancel()
+
+	result := &Result{
+		StatusHits: make(map[int]int),
+	}
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+
+	start := time.Now()
+	for i := 0; i < opts.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ctx.Err() == nil {
+				begin := time.Now()
+				code, err := probe()
+				latency := time.Since(begin)
+
+				mutex.Lock()
+				result.Requests++
+				if code != 0 {
+					result.StatusHits[code]++
+				}
+				if err != nil {
+					result.Errors++
+					result.LastError = err.Error()
+				} else {
+					result.Latencies = append(result.Latencies, latency)
+				}
+				mutex.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	result.Elapsed = time.Since(start)
+
+	sort.Slice(result.Latencies, func(i, j int) bool {
+		return result.Latencies[i] < result.Latencies[j]
+	})
+
+	return result, nil
+}
+
+// Percentile returns the latency at the given percentile (0-100)
+func (r *Result) Percentile(p float64) time.Duration {
+	if len(r.Latencies) == 0 {
+		return 0
+	}
+	index := int(float64(len(r.Latencies)-1) * p / 100)
+	if index < 0 {
+		index = 0
+	}
+	if index >= len(r.Latencies) {
+		index = len(r.Latencies) - 1
+	}
+	return r.Latencies[index]
+}
+
+// ErrorRate returns the fraction of failed requests
+func (r *Result) ErrorRate() float64 {
+	if r.Requests == 0 {
+		return 0
+	}
+	return float64(r.Errors) / float64(r.Requests)
+}
+
+// Throughput returns the number of requests per second
+func (r *Result) Throughput() float64 {
+	if r.Elapsed <= 0 {
+		return 0
+	}
+	return float64(r.Requests) / r.Elapsed.Seconds()
+}
+
+// tcpProbe measures the time to establish a TCP connection through the forward
+func tcpProbe(opts Options) func() (int, error) {
+	address := fmt.Sprintf("localhost:%d", opts.Port)
+	return func() (int, error) {
+		conn, err := net.DialTimeout("tcp", address, opts.Timeout)
+		if err != nil {
+			return 0, err
+		}
+		return 0, conn.Close()
+	}
+}
+
+// httpProbe measures full HTTP request round trips through the forward
+func httpProbe(opts Options) func() (int, error) {
+	url := fmt.Sprintf("http://localhost:%d/%s", opts.Port, strings.TrimLeft(opts.Path, "/"))
+	client := &http.Client{
+		Timeout: opts.Timeout,
+		Transport: &http.Transport{
+			MaxIdleConnsPerHost: opts.Concurrency,
+		},
+	}
+
+	return func() (int, error) {
+		resp, err := client.Get(url)
+		if err != nil {
+			return 0, err
+		}
+		defer resp.Body.Close()
+
+		if _, err := io.Copy(io.Discard, resp.Body); err != nil {
+			return resp.StatusCode, err
+		}
+		if resp.StatusCode >= 500 {
+			return resp.StatusCode, fmt.Errorf("server returned status %d", resp.StatusCode)
+		}
+		return resp.StatusCode, nil
+	}
+}
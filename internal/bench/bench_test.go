@@ -0,0 +1,78 @@
+package bench
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestResultPercentile(t *testing.T) {
+	result := &Result{}
+	for i := 1; i <= 100; i++ {
+		result.Latencies = append(result.Latencies, time.Duration(i)*time.Millisecond)
+	}
+
+	if p := result.Percentile(50); p != 50*time.Millisecond {
+		t.Errorf("Expected p50 of 50ms, got %v", p)
+	}
+	if p := result.Percentile(100); p != 100*time.Millisecond {
+		t.Errorf("Expected p100 of 100ms, got %v", p)
+	}
+
+	empty := &Result{}
+	if p := empty.Percentile(99); p != 0 {
+		t.Errorf("Expected 0 for empty result, got %v", p)
+	}
+}
+
+func TestResultErrorRate(t *testing.T) {
+	result := &Result{Requests: 10, Errors: 2}
+	if rate := result.ErrorRate(); rate != 0.2 {
+		t.Errorf("Expected error rate 0.2, got %f", rate)
+	}
+
+	empty := &Result{}
+	if rate := empty.ErrorRate(); rate != 0 {
+		t.Errorf("Expected error rate 0 for empty result, got %f", rate)
+	}
+}
+
+func TestRunHTTP(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	port := server.Listener.Addr().(*net.TCPAddr).Port
+	result, err := Run(context.Background(), Options{
+		Port:        port,
+		Mode:        ModeHTTP,
+		Duration:    200 * time.Millisecond,
+		Concurrency: 2,
+	})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	if result.Requests == 0 {
+		t.Error("Expected some requests to be made")
+	}
+	if result.Errors != 0 {
+		t.Errorf("Expected no errors, got %d (last: %s)", result.Errors, result.LastError)
+	}
+	if result.StatusHits[http.StatusOK] != result.Requests {
+		t.Errorf("Expected all requests to return 200, got %v", result.StatusHits)
+	}
+}
+
+func TestRunInvalidOptions(t *testing.T) {
+	if _, err := Run(context.Background(), Options{Port: 0}); err == nil {
+		t.Error("Expected error for invalid port")
+	}
+	if _, err := Run(context.Background(), Options{Port: 80, Mode: "udp"}); err == nil {
+		t.Error("Expected error for unsupported mode")
+	}
+}
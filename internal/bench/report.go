@@ -0,0 +1,52 @@
+package bench
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"time"
+)
+
+// ReportInfo describes the build and machine a report was produced on
+type ReportInfo struct {
+	Version string
+	Commit  string
+	Time    time.Time
+}
+
+// WriteReport writes the measurements as a Markdown report comparing them with the
+// reference machine's baseline
+func WriteReport(w io.Writer, info ReportInfo, measurements []Measurement) error {
+	fmt.Fprintf(w, "# kportforward performance report\n\n")
+	fmt.Fprintf(w, "- Version: %s (%s)\n", info.Version, info.Commit)
+	fmt.Fprintf(w, "- Platform: %s/%s, %d CPUs, %s\n", runtime.GOOS, runtime.GOARCH, runtime.NumCPU(), runtime.Version())
+	fmt.Fprintf(w, "- Date: %s\n\n", info.Time.Format(time.RFC3339))
+
+	fmt.Fprintf(w, "| Benchmark | Time/op | Allocs/op | Bytes/op | Baseline | vs. baseline |\n")
+	fmt.Fprintf(w, "|---|---:|---:|---:|---:|---|\n")
+	for _, m := range measurements {
+		perOp := time.Duration(m.Result.NsPerOp())
+		fmt.Fprintf(w, "| %s/%s | %v | %d | %d | %v | %s |\n",
+			m.Suite, m.Name, perOp, m.Result.AllocsPerOp(), m.Result.AllocedBytesPerOp(),
+			m.Baseline, compareToBaseline(perOp, m.Baseline))
+	}
+
+	_, err := fmt.Fprintf(w, "\nBaselines were measured on the reference machine; ratios above 2x are worth a look.\n")
+	return err
+}
+
+// compareToBaseline describes how a time per operation relates to its baseline, e.g. "1.8x slower"
+func compareToBaseline(perOp, baseline time.Duration) string {
+	if perOp <= 0 || baseline <= 0 {
+		return "n/a"
+	}
+	ratio := float64(perOp) / float64(baseline)
+	switch {
+	case ratio >= 1.1:
+		return fmt.Sprintf("%.1fx slower", ratio)
+	case ratio <= 0.9:
+		return fmt.Sprintf("%.1fx faster", 1/ratio)
+	default:
+		return "on par"
+	}
+}
@@ -0,0 +1,47 @@
+package bench
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCompareToBaseline(t *testing.T) {
+	tests := []struct {
+		perOp    time.Duration
+		baseline time.Duration
+		expected string
+	}{
+		{2 * time.Millisecond, time.Millisecond, "2.0x slower"},
+		{time.Millisecond, 4 * time.Millisecond, "4.0x faster"},
+		{time.Millisecond, 1050 * time.Microsecond, "on par"},
+		{time.Millisecond, 0, "n/a"},
+	}
+
+	for _, test := range tests {
+		if got := compareToBaseline(test.perOp, test.baseline); got != test.expected {
+			t.Errorf("compareToBaseline(%v, %v) = %q, expected %q", test.perOp, test.baseline, got, test.expected)
+		}
+	}
+}
+
+func TestWriteReport(t *testing.T) {
+	measurements := []Measurement{{
+		Benchmark: Benchmark{Suite: "config", Name: "LoadConfig", Baseline: time.Millisecond},
+		Result:    testing.BenchmarkResult{N: 10, T: 20 * time.Millisecond},
+	}}
+
+	var buf bytes.Buffer
+	info := ReportInfo{Version: "v1.2.3", Commit: "abc123", Time: time.Now()}
+	if err := WriteReport(&buf, info, measurements); err != nil {
+		t.Fatalf("WriteReport failed: %v", err)
+	}
+
+	report := buf.String()
+	for _, want := range []string{"v1.2.3 (abc123)", "| config/LoadConfig | 2ms |", "2.0x slower"} {
+		if !strings.Contains(report, want) {
+			t.Errorf("Expected report to contain %q, got:\n%s", want, report)
+		}
+	}
+}
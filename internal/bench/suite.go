@@ -0,0 +1,117 @@
+package bench
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/portforward"
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+// Benchmark is one of the micro-benchmarks of kportforward's internals, built into
+// the binary so it can run on a user's machine without the source tree
+type Benchmark struct {
+	Suite    string
+	Name     string
+	Baseline time.Duration // Time per operation on the reference machine
+	Run      func(b *testing.B)
+}
+
+// Measurement is the outcome of running a benchmark
+type Measurement struct {
+	Benchmark
+	Result testing.BenchmarkResult
+}
+
+// Benchmarks returns the config loading, port resolution and manager benchmarks,
+// mirroring the suites run with `go test -bench`
+func Benchmarks() []Benchmark {
+	return []Benchmark{
+		{"config", "LoadConfig", 500 * time.Microsecond, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := config.LoadConfig(); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}},
+		{"config", "LoadConfigFast", 100 * time.Nanosecond, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := config.LoadConfigFast(); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}},
+		{"ports", "IsPortAvailable", 15 * time.Microsecond, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				utils.IsPortAvailable(45000 + i%100)
+			}
+		}},
+		{"ports", "FindAvailablePort", 15 * time.Microsecond, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := utils.FindAvailablePort(50000 + i%1000); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}},
+		{"ports", "ResolvePortConflicts", time.Millisecond, func(b *testing.B) {
+			services := make(map[string]utils.ServiceConfig, 50)
+			for i := 0; i < 50; i++ {
+				services[fmt.Sprintf("service-%d", i)] = utils.ServiceConfig{LocalPort: 60000 + i%10}
+			}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := utils.ResolvePortConflicts(services); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}},
+		{"manager", "NewManager", time.Microsecond, func(b *testing.B) {
+			cfg := benchConfig(20)
+			logger := utils.NewLogger(utils.LevelError)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				portforward.NewManager(cfg, logger)
+			}
+		}},
+		{"manager", "GetCurrentStatus", 150 * time.Nanosecond, func(b *testing.B) {
+			manager := portforward.NewManager(benchConfig(100), utils.NewLogger(utils.LevelError))
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				manager.GetCurrentStatus()
+			}
+		}},
+	}
+}
+
+// RunBenchmarks runs the benchmarks one after another, reporting each before it starts
+func RunBenchmarks(benchmarks []Benchmark, starting func(Benchmark)) []Measurement {
+	measurements := make([]Measurement, 0, len(benchmarks))
+	for _, benchmark := range benchmarks {
+		if starting != nil {
+			starting(benchmark)
+		}
+		measurements = append(measurements, Measurement{Benchmark: benchmark, Result: testing.Benchmark(benchmark.Run)})
+	}
+	return measurements
+}
+
+// benchConfig returns a configuration with the given number of web services
+func benchConfig(services int) *config.Config {
+	cfg := &config.Config{
+		PortForwards:       make(map[string]config.Service, services),
+		MonitoringInterval: time.Second,
+	}
+	for i := 0; i < services; i++ {
+		name := fmt.Sprintf("service-%d", i)
+		cfg.PortForwards[name] = config.Service{
+			Target:     "service/" + name,
+			TargetPort: 8000 + i,
+			LocalPort:  9000 + i,
+			Namespace:  "default",
+			Type:       "web",
+		}
+	}
+	return cfg
+}
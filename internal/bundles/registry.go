@@ -0,0 +1,170 @@
+package bundles
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultRegistry is where bundles are fetched from unless bundleRegistry is configured
+const DefaultRegistry = "https://raw.githubusercontent.com/catio-tech/kportforward-bundles/main"
+
+// namePattern limits bundle names and versions to path-safe strings
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// Entry describes a bundle in the registry index
+type Entry struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Maintainer  string   `json:"maintainer,omitempty"`
+	Versions    []string `json:"versions"` // Newest first
+}
+
+// Latest returns the newest version of the bundle
+func (e Entry) Latest() string {
+	if len(e.Versions) == 0 {
+		return ""
+	}
+	return e.Versions[0]
+}
+
+// Bundle is a versioned set of services that can be merged into the user config
+type Bundle struct {
+	Name         string                    `yaml:"name"`
+	Version      string                    `yaml:"version"`
+	Description  string                    `yaml:"description,omitempty"`
+	PortForwards map[string]config.Service `yaml:"portForwards"`
+}
+
+// Registry reads bundles from a static registry: an index.json listing the bundles
+// and one YAML file per version at <name>/<version>.yaml
+type Registry struct {
+	baseURL string
+	client  *http.Client
+}
+
+// NewRegistry creates a registry client for the given base URL, or the default registry
+func NewRegistry(baseURL string) *Registry {
+	if baseURL == "" {
+		baseURL = DefaultRegistry
+	}
+	return &Registry{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		client:  &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Index returns the bundles the registry offers
+func (r *Registry) Index() ([]Entry, error) {
+	data, err := r.get("index.json")
+	if err != nil {
+		return nil, err
+	}
+
+	var index struct {
+		Bundles []Entry `json:"bundles"`
+	}
+	if err := json.Unmarshal(data, &index); err != nil {
+		return nil, fmt.Errorf("failed to parse bundle index: %w", err)
+	}
+	return index.Bundles, nil
+}
+
+// Fetch downloads a bundle at the given version, or its latest version when version is ""
+func (r *Registry) Fetch(name, version string) (*Bundle, error) {
+	if !namePattern.MatchString(name) {
+		return nil, fmt.Errorf("invalid bundle name %q", name)
+	}
+
+	if version == "" {
+		entries, err := r.Index()
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			if entry.Name == name {
+				version = entry.Latest()
+				break
+			}
+		}
+		if version == "" {
+			return nil, fmt.Errorf("bundle %s not found in the registry", name)
+		}
+	}
+	if !namePattern.MatchString(version) {
+		return nil, fmt.Errorf("invalid bundle version %q", version)
+	}
+
+	data, err := r.get(name + "/" + version + ".yaml")
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch bundle %s@%s: %w", name, version, err)
+	}
+
+	var bundle Bundle
+	if err := yaml.Unmarshal(data, &bundle); err != nil {
+		return nil, fmt.Errorf("failed to parse bundle %s@%s: %w", name, version, err)
+	}
+	if bundle.Name != name || bundle.Version != version {
+		return nil, fmt.Errorf("registry returned %s@%s when asked for %s@%s", bundle.Name, bundle.Version, name, version)
+	}
+	if len(bundle.PortForwards) == 0 {
+		return nil, fmt.Errorf("bundle %s@%s has no services", name, version)
+	}
+	return &bundle, nil
+}
+
+// get fetches a file from the registry
+func (r *Registry) get(path string) ([]byte, error) {
+	target, err := url.JoinPath(r.baseURL, path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid registry URL: %w", err)
+	}
+
+	resp, err := r.client.Get(target)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach bundle registry: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("bundle registry returned status %d for %s", resp.StatusCode, path)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return data, nil
+}
+
+// ParseRef splits a "name@version" reference; the version is "" when not given
+func ParseRef(ref string) (string, string) {
+	name, version, _ := strings.Cut(ref, "@")
+	return name, version
+}
+
+// Conflicts returns the bundle's services that would replace a configured service the
+// bundle didn't add itself, sorted by name
+func Conflicts(bundle *Bundle, cfg *config.Config) []string {
+	owned := make(map[string]bool)
+	for _, service := range cfg.Bundles[bundle.Name].Services {
+		owned[service] = true
+	}
+
+	var conflicts []string
+	for name := range bundle.PortForwards {
+		if _, exists := cfg.PortForwards[name]; exists && !owned[name] {
+			conflicts = append(conflicts, name)
+		}
+	}
+	sort.Strings(conflicts)
+	return conflicts
+}
@@ -0,0 +1,104 @@
+package bundles
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+)
+
+func newTestRegistry(t *testing.T) *Registry {
+	files := map[string]string{
+		"/index.json": `{"bundles": [{"name": "flyte", "description": "Flyte console and admin", "versions": ["1.1.0", "1.0.0"]}]}`,
+		"/flyte/1.1.0.yaml": `name: flyte
+version: 1.1.0
+portForwards:
+  flyte-console:
+    target: service/flyteconsole
+    targetPort: 80
+    localPort: 8088
+    namespace: flyte
+    type: web
+`,
+		"/flyte/1.0.0.yaml": "name: flyte\nversion: 9.9.9\nportForwards: {}\n",
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		content, ok := files[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(content))
+	}))
+	t.Cleanup(server.Close)
+	return NewRegistry(server.URL + "/")
+}
+
+func TestRegistryIndex(t *testing.T) {
+	entries, err := newTestRegistry(t).Index()
+	if err != nil {
+		t.Fatalf("Index failed: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Name != "flyte" || entries[0].Latest() != "1.1.0" {
+		t.Errorf("Unexpected index %+v", entries)
+	}
+}
+
+func TestRegistryFetchLatest(t *testing.T) {
+	bundle, err := newTestRegistry(t).Fetch("flyte", "")
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if bundle.Version != "1.1.0" {
+		t.Errorf("Expected the latest version 1.1.0, got %s", bundle.Version)
+	}
+	if service := bundle.PortForwards["flyte-console"]; service.LocalPort != 8088 || service.Namespace != "flyte" {
+		t.Errorf("Unexpected service %+v", service)
+	}
+}
+
+func TestRegistryFetchErrors(t *testing.T) {
+	registry := newTestRegistry(t)
+	tests := map[string]struct {
+		name, version, expected string
+	}{
+		"unknown bundle":  {"argo", "", "not found"},
+		"missing version": {"flyte", "2.0.0", "404"},
+		"mismatched file": {"flyte", "1.0.0", "registry returned flyte@9.9.9"},
+		"path in name":    {"../etc", "1.0.0", "invalid bundle name"},
+		"path in version": {"flyte", "../../x", "invalid bundle version"},
+	}
+
+	for label, test := range tests {
+		_, err := registry.Fetch(test.name, test.version)
+		if err == nil || !strings.Contains(err.Error(), test.expected) {
+			t.Errorf("%s: expected an error containing %q, got %v", label, test.expected, err)
+		}
+	}
+}
+
+func TestParseRef(t *testing.T) {
+	if name, version := ParseRef("argo@1.2.0"); name != "argo" || version != "1.2.0" {
+		t.Errorf("Unexpected ref %s, %s", name, version)
+	}
+	if name, version := ParseRef("argo"); name != "argo" || version != "" {
+		t.Errorf("Unexpected ref %s, %s", name, version)
+	}
+}
+
+func TestConflicts(t *testing.T) {
+	bundle := &Bundle{Name: "flyte", PortForwards: map[string]config.Service{
+		"flyte-console": {}, "flyte-admin": {}, "flyte-new": {},
+	}}
+	cfg := &config.Config{
+		PortForwards: map[string]config.Service{"flyte-console": {}, "flyte-admin": {}},
+		Bundles:      map[string]config.BundleRef{"flyte": {Version: "1.0.0", Services: []string{"flyte-admin"}}},
+	}
+
+	if conflicts := Conflicts(bundle, cfg); !reflect.DeepEqual(conflicts, []string{"flyte-console"}) {
+		t.Errorf("Expected only flyte-console to conflict, got %v", conflicts)
+	}
+}
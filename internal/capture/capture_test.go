@@ -0,0 +1,110 @@
+package capture
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMiddlewareRecordsAndReplays(t *testing.T) {
+	var received []string
+	backend := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received = append(received, r.Method+" "+r.URL.Path+" "+string(body))
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		io.WriteString(w, `{"ok":true}`)
+	})
+
+	dir := t.TempDir()
+	recorder, err := NewRecorder(dir, "api")
+	if err != nil {
+		t.Fatalf("NewRecorder failed: %v", err)
+	}
+
+	server := httptest.NewServer(recorder.Middleware(backend, func(err error) {
+		t.Errorf("Record failed: %v", err)
+	}))
+	defer server.Close()
+
+	resp, err := http.Post(server.URL+"/items?debug=1", "application/json", strings.NewReader(`{"name":"a"}`))
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	resp.Body.Close()
+	recorder.Close()
+
+	entries, err := Load(recorder.Path())
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("Expected 1 entry, got %d", len(entries))
+	}
+
+	entry := entries[0]
+	if entry.Request.Method != http.MethodPost || !strings.HasSuffix(entry.Request.URL, "/items?debug=1") {
+		t.Errorf("Unexpected request: %s %s", entry.Request.Method, entry.Request.URL)
+	}
+	if entry.Request.PostData == nil || entry.Request.PostData.Text != `{"name":"a"}` {
+		t.Errorf("Expected request body to be recorded, got %+v", entry.Request.PostData)
+	}
+	if entry.Response.Status != http.StatusCreated || entry.Response.Content.Text != `{"ok":true}` {
+		t.Errorf("Unexpected response: %d %q", entry.Response.Status, entry.Response.Content.Text)
+	}
+	if entry.Comment != "api" {
+		t.Errorf("Expected service name in comment, got %q", entry.Comment)
+	}
+
+	// Replay against a fresh server
+	target := httptest.NewServer(backend)
+	defer target.Close()
+
+	replayer := &Replayer{Target: target.URL}
+	result := replayer.Replay(entry)
+	if !result.Matches() {
+		t.Errorf("Expected replay to match the recorded status, got %+v", result)
+	}
+	if last := received[len(received)-1]; last != `POST /items {"name":"a"}` {
+		t.Errorf("Unexpected replayed request at backend: %q", last)
+	}
+}
+
+func TestLoadHARDocument(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "capture.har")
+	entries := []Entry{{
+		Request:  Request{Method: http.MethodGet, URL: "http://localhost:8080/"},
+		Response: Response{Status: http.StatusOK},
+	}}
+	if err := WriteHAR(path, entries, "test"); err != nil {
+		t.Fatalf("WriteHAR failed: %v", err)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(loaded) != 1 || loaded[0].Request.URL != "http://localhost:8080/" {
+		t.Errorf("Unexpected entries: %+v", loaded)
+	}
+}
+
+func TestBinaryAndLargeBodies(t *testing.T) {
+	text, encoding := encodeText([]byte{0xff, 0x00, 0xfe})
+	if encoding != "base64" {
+		t.Errorf("Expected binary body to be base64 encoded, got %q", encoding)
+	}
+	decoded, _ := decodeText(text, encoding)
+	if string(decoded) != "\xff\x00\xfe" {
+		t.Errorf("Round trip failed: %q", decoded)
+	}
+
+	var buffer limitedBuffer
+	buffer.Write(make([]byte, MaxBodySize+10))
+	if buffer.Len() != MaxBodySize || !buffer.truncated || buffer.total != MaxBodySize+10 {
+		t.Errorf("Expected body to be truncated, got len=%d total=%d", buffer.Len(), buffer.total)
+	}
+}
@@ -0,0 +1,204 @@
+package capture
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"time"
+	"unicode/utf8"
+)
+
+// Entry is a single request/response pair in HAR 1.2 entry format
+type Entry struct {
+	StartedDateTime time.Time `json:"startedDateTime"`
+	Time            float64   `json:"time"` // Total elapsed milliseconds
+	Request         Request   `json:"request"`
+	Response        Response  `json:"response"`
+	Cache           struct{}  `json:"cache"`
+	Timings         Timings   `json:"timings"`
+	Comment         string    `json:"comment,omitempty"` // Service the traffic went through
+}
+
+// Request is a HAR request
+type Request struct {
+	Method      string      `json:"method"`
+	URL         string      `json:"url"`
+	HTTPVersion string      `json:"httpVersion"`
+	Cookies     []NameValue `json:"cookies"`
+	Headers     []NameValue `json:"headers"`
+	QueryString []NameValue `json:"queryString"`
+	PostData    *PostData   `json:"postData,omitempty"`
+	HeadersSize int         `json:"headersSize"`
+	BodySize    int         `json:"bodySize"`
+}
+
+// Response is a HAR response
+type Response struct {
+	Status      int         `json:"status"`
+	StatusText  string      `json:"statusText"`
+	HTTPVersion string      `json:"httpVersion"`
+	Cookies     []NameValue `json:"cookies"`
+	Headers     []NameValue `json:"headers"`
+	Content     Content     `json:"content"`
+	RedirectURL string      `json:"redirectURL"`
+	HeadersSize int         `json:"headersSize"`
+	BodySize    int         `json:"bodySize"`
+}
+
+// NameValue is a HAR header, cookie or query parameter
+type NameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// PostData is a HAR request body
+type PostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+	Encoding string `json:"encoding,omitempty"` // "base64" for binary bodies (extension)
+	Comment  string `json:"comment,omitempty"`
+}
+
+// Content is a HAR response body
+type Content struct {
+	Size     int    `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+	Encoding string `json:"encoding,omitempty"`
+	Comment  string `json:"comment,omitempty"`
+}
+
+// Timings are HAR phase timings in milliseconds
+type Timings struct {
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
+
+// harDocument is the top level of a full HAR file
+type harDocument struct {
+	Log struct {
+		Version string  `json:"version"`
+		Creator Creator `json:"creator"`
+		Entries []Entry `json:"entries"`
+	} `json:"log"`
+}
+
+// Creator identifies the application that wrote a HAR file
+type Creator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// Body returns the decoded request body
+func (r Request) Body() ([]byte, error) {
+	if r.PostData == nil {
+		return nil, nil
+	}
+	return decodeText(r.PostData.Text, r.PostData.Encoding)
+}
+
+// Load reads entries from a capture file (one entry per line) or a full HAR document
+func Load(path string) ([]Entry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read capture: %w", err)
+	}
+
+	// A full HAR document starts with {"log":
+	var doc harDocument
+	if err := json.Unmarshal(data, &doc); err == nil && doc.Log.Entries != nil {
+		return doc.Log.Entries, nil
+	}
+
+	var entries []Entry
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("invalid capture entry on line %d: %w", line, err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read capture: %w", err)
+	}
+	return entries, nil
+}
+
+// WriteHAR writes entries as a full HAR document, for browser devtools and other HAR viewers
+func WriteHAR(path string, entries []Entry, version string) error {
+	var doc harDocument
+	doc.Log.Version = "1.2"
+	doc.Log.Creator = Creator{Name: "kportforward", Version: version}
+	doc.Log.Entries = entries
+
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode HAR: %w", err)
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// headerList converts HTTP headers to sorted HAR name/value pairs
+func headerList(header http.Header) []NameValue {
+	list := make([]NameValue, 0, len(header))
+	for name, values := range header {
+		for _, value := range values {
+			list = append(list, NameValue{Name: name, Value: value})
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+	return list
+}
+
+// queryList converts URL query parameters to HAR name/value pairs
+func queryList(values url.Values) []NameValue {
+	list := make([]NameValue, 0, len(values))
+	for name, vals := range values {
+		for _, value := range vals {
+			list = append(list, NameValue{Name: name, Value: value})
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+	return list
+}
+
+// cookieList converts parsed cookies to HAR name/value pairs
+func cookieList(cookies []*http.Cookie) []NameValue {
+	list := make([]NameValue, 0, len(cookies))
+	for _, cookie := range cookies {
+		list = append(list, NameValue{Name: cookie.Name, Value: cookie.Value})
+	}
+	return list
+}
+
+// encodeText returns body text, base64-encoding bodies that aren't valid UTF-8
+func encodeText(body []byte) (string, string) {
+	if utf8.Valid(body) {
+		return string(body), ""
+	}
+	return base64.StdEncoding.EncodeToString(body), "base64"
+}
+
+// decodeText reverses encodeText
+func decodeText(text, encoding string) ([]byte, error) {
+	if encoding == "base64" {
+		return base64.StdEncoding.DecodeString(text)
+	}
+	return []byte(text), nil
+}
@@ -0,0 +1,243 @@
+package capture
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// MaxBodySize is how much of each request and response body is recorded
+const MaxBodySize = 1 << 20
+
+// sessionStart names capture files so relay restarts keep appending to the same file
+var sessionStart = time.Now()
+
+// Recorder appends captured entries to a file, one JSON entry per line
+type Recorder struct {
+	service string
+	path    string
+	file    *os.File
+	mutex   sync.Mutex
+}
+
+// FilePath returns the capture file for a service in dir for this session
+func FilePath(dir, service string) string {
+	return filepath.Join(dir, fmt.Sprintf("%s-%s.jsonl", service, sessionStart.Format("20060102-150405")))
+}
+
+// NewRecorder opens the capture file for a service, creating dir if needed
+func NewRecorder(dir, service string) (*Recorder, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create capture directory: %w", err)
+	}
+
+	path := FilePath(dir, service)
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open capture file: %w", err)
+	}
+
+	return &Recorder{service: service, path: path, file: file}, nil
+}
+
+// Path returns the file the recorder writes to
+func (r *Recorder) Path() string {
+	return r.path
+}
+
+// Record appends an entry to the capture file
+func (r *Recorder) Record(entry Entry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.file == nil {
+		return os.ErrClosed
+	}
+	_, err = r.file.Write(data)
+	return err
+}
+
+// Close closes the capture file
+func (r *Recorder) Close() error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.file == nil {
+		return nil
+	}
+	err := r.file.Close()
+	r.file = nil
+	return err
+}
+
+// Middleware records every request and response passing through next.
+// Recording failures are reported to onError and never affect the response.
+func (r *Recorder) Middleware(next http.Handler, onError func(error)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		started := time.Now()
+
+		var requestBody limitedBuffer
+		if req.Body != nil && req.Body != http.NoBody {
+			req.Body = &teeReadCloser{ReadCloser: req.Body, buffer: &requestBody}
+		}
+
+		// Snapshot the request before handlers such as auth injection modify it
+		entryRequest := requestEntry(req)
+
+		recorder := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, req)
+		elapsed := time.Since(started)
+
+		if requestBody.Len() > 0 || requestBody.truncated {
+			text, encoding := encodeText(requestBody.Bytes())
+			entryRequest.PostData = &PostData{
+				MimeType: req.Header.Get("Content-Type"),
+				Text:     text,
+				Encoding: encoding,
+				Comment:  requestBody.comment(),
+			}
+			entryRequest.BodySize = requestBody.total
+		}
+
+		millis := float64(elapsed) / float64(time.Millisecond)
+		entry := Entry{
+			StartedDateTime: started,
+			Time:            millis,
+			Request:         entryRequest,
+			Response:        recorder.entry(req.Proto),
+			Timings:         Timings{Wait: millis},
+			Comment:         r.service,
+		}
+		if err := r.Record(entry); err != nil && onError != nil {
+			onError(err)
+		}
+	})
+}
+
+// requestEntry converts the request line, headers and cookies to HAR
+func requestEntry(req *http.Request) Request {
+	scheme := "http"
+	if req.TLS != nil {
+		scheme = "https"
+	}
+	url := *req.URL
+	url.Scheme = scheme
+	url.Host = req.Host
+
+	return Request{
+		Method:      req.Method,
+		URL:         url.String(),
+		HTTPVersion: req.Proto,
+		Cookies:     cookieList(req.Cookies()),
+		Headers:     headerList(req.Header),
+		QueryString: queryList(req.URL.Query()),
+		HeadersSize: -1,
+		BodySize:    0,
+	}
+}
+
+// responseRecorder captures the status, headers and body written by the proxy
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	body   limitedBuffer
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(data []byte) (int, error) {
+	r.body.Write(data)
+	return r.ResponseWriter.Write(data)
+}
+
+// Flush keeps streaming responses such as server-sent events flowing
+func (r *responseRecorder) Flush() {
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (r *responseRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// entry converts the recorded response to HAR
+func (r *responseRecorder) entry(proto string) Response {
+	header := r.Header()
+	text, encoding := encodeText(r.body.Bytes())
+	return Response{
+		Status:      r.status,
+		StatusText:  http.StatusText(r.status),
+		HTTPVersion: proto,
+		Cookies:     cookieList((&http.Response{Header: header}).Cookies()),
+		Headers:     headerList(header),
+		Content: Content{
+			Size:     r.body.total,
+			MimeType: header.Get("Content-Type"),
+			Text:     text,
+			Encoding: encoding,
+			Comment:  r.body.comment(),
+		},
+		RedirectURL: header.Get("Location"),
+		HeadersSize: -1,
+		BodySize:    r.body.total,
+	}
+}
+
+// limitedBuffer keeps the first MaxBodySize bytes written and counts the rest
+type limitedBuffer struct {
+	bytes.Buffer
+	total     int
+	truncated bool
+}
+
+func (b *limitedBuffer) Write(data []byte) (int, error) {
+	b.total += len(data)
+	if room := MaxBodySize - b.Buffer.Len(); room > 0 {
+		if len(data) > room {
+			b.Buffer.Write(data[:room])
+			b.truncated = true
+		} else {
+			b.Buffer.Write(data)
+		}
+	} else if len(data) > 0 {
+		b.truncated = true
+	}
+	return len(data), nil
+}
+
+// comment notes truncation in the HAR entry
+func (b *limitedBuffer) comment() string {
+	if b.truncated {
+		return fmt.Sprintf("truncated to %d of %d bytes", b.Buffer.Len(), b.total)
+	}
+	return ""
+}
+
+// teeReadCloser copies a request body into a buffer as the proxy reads it
+type teeReadCloser struct {
+	io.ReadCloser
+	buffer *limitedBuffer
+}
+
+func (t *teeReadCloser) Read(p []byte) (int, error) {
+	n, err := t.ReadCloser.Read(p)
+	if n > 0 {
+		t.buffer.Write(p[:n])
+	}
+	return n, err
+}
@@ -0,0 +1,118 @@
+package capture
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// hopHeaders are connection-specific and must not be replayed
+var hopHeaders = map[string]bool{
+	"Connection":        true,
+	"Content-Length":    true,
+	"Keep-Alive":        true,
+	"Proxy-Connection":  true,
+	"Te":                true,
+	"Trailer":           true,
+	"Transfer-Encoding": true,
+	"Upgrade":           true,
+}
+
+// ReplayResult compares a replayed request with its recording
+type ReplayResult struct {
+	Method         string
+	URL            string
+	RecordedStatus int
+	Status         int
+	Duration       time.Duration
+	Err            error
+}
+
+// Matches reports whether the replayed status equals the recorded one
+func (r ReplayResult) Matches() bool {
+	return r.Err == nil && r.Status == r.RecordedStatus
+}
+
+// Replayer resends captured requests
+type Replayer struct {
+	Client *http.Client
+	Target string // Base URL replacing the recorded scheme and host, e.g. http://localhost:8080
+}
+
+// Replay sends a captured request and returns the outcome
+func (p *Replayer) Replay(entry Entry) ReplayResult {
+	result := ReplayResult{
+		Method:         entry.Request.Method,
+		URL:            entry.Request.URL,
+		RecordedStatus: entry.Response.Status,
+	}
+
+	req, err := p.newRequest(entry.Request)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	result.URL = req.URL.String()
+
+	client := p.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	started := time.Now()
+	resp, err := client.Do(req)
+	result.Duration = time.Since(started)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	result.Status = resp.StatusCode
+	return result
+}
+
+// newRequest rebuilds an HTTP request from a captured one
+func (p *Replayer) newRequest(captured Request) (*http.Request, error) {
+	target, err := url.Parse(captured.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid captured URL %q: %w", captured.URL, err)
+	}
+
+	if p.Target != "" {
+		base, err := url.Parse(p.Target)
+		if err != nil {
+			return nil, fmt.Errorf("invalid target %q: %w", p.Target, err)
+		}
+		target.Scheme = base.Scheme
+		target.Host = base.Host
+		if prefix := strings.TrimSuffix(base.Path, "/"); prefix != "" {
+			target.Path = prefix + target.Path
+		}
+	}
+
+	body, err := captured.Body()
+	if err != nil {
+		return nil, fmt.Errorf("invalid captured body: %w", err)
+	}
+
+	req, err := http.NewRequest(captured.Method, target.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for _, header := range captured.Headers {
+		if hopHeaders[http.CanonicalHeaderKey(header.Name)] {
+			continue
+		}
+		if strings.EqualFold(header.Name, "Host") {
+			continue
+		}
+		req.Header.Add(header.Name, header.Value)
+	}
+	return req, nil
+}
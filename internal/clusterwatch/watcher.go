@@ -0,0 +1,354 @@
+package clusterwatch
+
+import (
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+// DefaultInterval is how often pods are polled when no interval is configured
+const DefaultInterval = 15 * time.Second
+
+// notableWaitingReasons are container waiting states that mean the pod can't serve
+var notableWaitingReasons = map[string]bool{
+	"CrashLoopBackOff":           true,
+	"ImagePullBackOff":           true,
+	"ErrImagePull":               true,
+	"CreateContainerConfigError": true,
+	"CreateContainerError":       true,
+	"RunContainerError":          true,
+}
+
+// Event is a notable change in the pods behind a forwarded service
+type Event struct {
+	Time    time.Time
+	Service string
+	Pod     string
+	Reason  string // e.g. CrashLoopBackOff, OOMKilled, Rollout
+	Message string
+}
+
+// String formats the event as a single log line
+func (e Event) String() string {
+	return fmt.Sprintf("%s: %s: %s", e.Service, e.Reason, e.Message)
+}
+
+// Watcher polls the pods behind kubectl-forwarded services and reports crashes,
+// OOM kills, image pull failures, evictions and rollouts
+type Watcher struct {
+	configs  func() map[string]config.Service
+	interval time.Duration
+	logger   *utils.Logger
+	onEvent  func(Event)
+
+	// kubectl runs a kubectl command; overridden in tests
+	kubectl func(args ...string) ([]byte, error)
+
+	selectors map[string]selector // Label selector per service, resolved once per target
+	states    map[string]*serviceState
+	stop      chan struct{}
+	stopOnce  sync.Once
+}
+
+// selector is the label selector of a service's workload, with the target it was resolved for
+type selector struct {
+	target string
+	labels string
+}
+
+// serviceState is what the previous poll saw for a service's pods
+type serviceState struct {
+	restarts map[string]int    // pod/container -> restart count
+	waiting  map[string]string // pod/container -> waiting reason
+	failed   map[string]bool   // pods in phase Failed
+	hashes   map[string]bool   // pod-template-hash labels seen
+}
+
+// NewWatcher creates a watcher for the services returned by configs, read on every
+// poll; onEvent is called from the polling goroutine
+func NewWatcher(configs func() map[string]config.Service, interval time.Duration, logger *utils.Logger, onEvent func(Event)) *Watcher {
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	return &Watcher{
+		configs:   configs,
+		interval:  interval,
+		logger:    logger,
+		onEvent:   onEvent,
+		kubectl:   runKubectl,
+		selectors: make(map[string]selector),
+		states:    make(map[string]*serviceState),
+		stop:      make(chan struct{}),
+	}
+}
+
+// Start begins polling in the background
+func (w *Watcher) Start() {
+	go func() {
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+
+		w.Poll()
+		for {
+			select {
+			case <-w.stop:
+				return
+			case <-ticker.C:
+				w.Poll()
+			}
+		}
+	}()
+}
+
+// Stop ends polling
+func (w *Watcher) Stop() {
+	w.stopOnce.Do(func() { close(w.stop) })
+}
+
+// Poll checks the pods of every kubectl service once
+func (w *Watcher) Poll() {
+	configs := w.configs()
+	names := make([]string, 0, len(configs))
+	for name, service := range configs {
+		if service.UsesKubectl() {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	// Forget services that were removed or no longer go through kubectl
+	watched := func(name string) bool {
+		service, exists := configs[name]
+		return exists && service.UsesKubectl()
+	}
+	for name := range w.states {
+		if !watched(name) {
+			delete(w.states, name)
+		}
+	}
+	for name := range w.selectors {
+		if !watched(name) {
+			delete(w.selectors, name)
+		}
+	}
+
+	for _, name := range names {
+		pods, err := w.pods(name, configs[name])
+		if err != nil {
+			w.logger.Debug("Failed to check pods for %s: %v", name, err)
+			continue
+		}
+		for _, event := range w.diff(name, pods) {
+			w.onEvent(event)
+		}
+	}
+}
+
+// pods returns the pods currently behind a service's target
+func (w *Watcher) pods(name string, service config.Service) ([]pod, error) {
+	kind, resource, found := strings.Cut(service.Target, "/")
+	if !found {
+		return nil, fmt.Errorf("unsupported target %q", service.Target)
+	}
+
+	base := append([]string{"-n", service.Namespace}, service.KubectlScope()...)
+
+	if kind == "pod" || kind == "pods" || kind == "po" {
+		output, err := w.kubectl(append([]string{"get", "pod", resource, "-o", "json"}, base...)...)
+		if err != nil {
+			return nil, err
+		}
+		var single pod
+		if err := json.Unmarshal(output, &single); err != nil {
+			return nil, fmt.Errorf("failed to parse pod: %w", err)
+		}
+		return []pod{single}, nil
+	}
+
+	// Resolve the selector again when the service now points at another workload
+	target := strings.Join(append([]string{service.Target}, base...), " ")
+	cached, found := w.selectors[name]
+	if !found || cached.target != target {
+		output, err := w.kubectl(append([]string{"get", service.Target, "-o", "json"}, base...)...)
+		if err != nil {
+			return nil, err
+		}
+		labels, err := parseSelector(output)
+		if err != nil {
+			return nil, err
+		}
+		cached = selector{target: target, labels: labels}
+		w.selectors[name] = cached
+	}
+
+	output, err := w.kubectl(append([]string{"get", "pods", "-l", cached.labels, "-o", "json"}, base...)...)
+	if err != nil {
+		// The workload may have been recreated with a different selector
+		delete(w.selectors, name)
+		return nil, err
+	}
+	var list podList
+	if err := json.Unmarshal(output, &list); err != nil {
+		return nil, fmt.Errorf("failed to parse pods: %w", err)
+	}
+	return list.Items, nil
+}
+
+// diff compares pods with the previous poll and returns notable events
+func (w *Watcher) diff(name string, pods []pod) []Event {
+	previous, known := w.states[name]
+	current := &serviceState{
+		restarts: make(map[string]int),
+		waiting:  make(map[string]string),
+		failed:   make(map[string]bool),
+		hashes:   make(map[string]bool),
+	}
+	w.states[name] = current
+
+	now := time.Now()
+	var events []Event
+	add := func(pod, reason, message string) {
+		events = append(events, Event{Time: now, Service: name, Pod: pod, Reason: reason, Message: message})
+	}
+
+	for _, p := range pods {
+		podName := p.Metadata.Name
+
+		// Report each new pod template once, when its first pod shows up
+		if hash := p.Metadata.Labels["pod-template-hash"]; hash != "" && !current.hashes[hash] {
+			current.hashes[hash] = true
+			if known && len(previous.hashes) > 0 && !previous.hashes[hash] {
+				add(podName, "Rollout", fmt.Sprintf("new pod %s from an updated pod template", podName))
+			}
+		}
+
+		if p.Status.Phase == "Failed" {
+			current.failed[podName] = true
+			if !known || !previous.failed[podName] {
+				reason := p.Status.Reason
+				if reason == "" {
+					reason = "PodFailed"
+				}
+				add(podName, reason, fmt.Sprintf("pod %s failed: %s", podName, p.Status.Message))
+			}
+		}
+
+		for _, c := range p.Status.ContainerStatuses {
+			key := podName + "/" + c.Name
+			current.restarts[key] = c.RestartCount
+
+			if waiting := c.State.Waiting; waiting != nil && notableWaitingReasons[waiting.Reason] {
+				current.waiting[key] = waiting.Reason
+				if !known || previous.waiting[key] != waiting.Reason {
+					add(podName, waiting.Reason, fmt.Sprintf("container %s in pod %s: %s", c.Name, podName, waiting.Message))
+				}
+			}
+
+			if before, seen := previous.restartsFor(key); known && seen && c.RestartCount > before {
+				reason, detail := "Restarted", ""
+				if terminated := c.LastState.Terminated; terminated != nil {
+					reason = terminated.Reason
+					detail = fmt.Sprintf(" (%s, exit code %d)", terminated.Reason, terminated.ExitCode)
+				}
+				add(podName, reason, fmt.Sprintf("container %s in pod %s restarted%s", c.Name, podName, detail))
+			}
+		}
+	}
+
+	return events
+}
+
+// restartsFor returns the previously seen restart count of a container
+func (s *serviceState) restartsFor(key string) (int, bool) {
+	if s == nil {
+		return 0, false
+	}
+	count, seen := s.restarts[key]
+	return count, seen
+}
+
+// parseSelector extracts the pod label selector of a service or workload
+func parseSelector(data []byte) (string, error) {
+	var object struct {
+		Kind string `json:"kind"`
+		Spec struct {
+			Selector json.RawMessage `json:"selector"`
+		} `json:"spec"`
+	}
+	if err := json.Unmarshal(data, &object); err != nil {
+		return "", fmt.Errorf("failed to parse target: %w", err)
+	}
+
+	// Services have a plain label map; workloads have a LabelSelector
+	var labels map[string]string
+	if object.Kind == "Service" {
+		json.Unmarshal(object.Spec.Selector, &labels)
+	} else {
+		var selector struct {
+			MatchLabels map[string]string `json:"matchLabels"`
+		}
+		json.Unmarshal(object.Spec.Selector, &selector)
+		labels = selector.MatchLabels
+	}
+	if len(labels) == 0 {
+		return "", fmt.Errorf("%s has no pod selector", strings.ToLower(object.Kind))
+	}
+
+	pairs := make([]string, 0, len(labels))
+	for key, value := range labels {
+		pairs = append(pairs, key+"="+value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ","), nil
+}
+
+// runKubectl runs kubectl and returns its standard output
+func runKubectl(args ...string) ([]byte, error) {
+	output, err := exec.Command("kubectl", args...).Output()
+	if exitErr, ok := err.(*exec.ExitError); ok && len(exitErr.Stderr) > 0 {
+		return nil, fmt.Errorf("kubectl %s: %s", args[0], strings.TrimSpace(string(exitErr.Stderr)))
+	}
+	return output, err
+}
+
+// podList and pod hold the fields of kubectl's pod JSON the watcher uses
+type podList struct {
+	Items []pod `json:"items"`
+}
+
+type pod struct {
+	Metadata struct {
+		Name   string            `json:"name"`
+		Labels map[string]string `json:"labels"`
+	} `json:"metadata"`
+	Status struct {
+		Phase             string            `json:"phase"`
+		Reason            string            `json:"reason"`
+		Message           string            `json:"message"`
+		ContainerStatuses []containerStatus `json:"containerStatuses"`
+	} `json:"status"`
+}
+
+type containerStatus struct {
+	Name         string `json:"name"`
+	RestartCount int    `json:"restartCount"`
+	State        struct {
+		Waiting *struct {
+			Reason  string `json:"reason"`
+			Message string `json:"message"`
+		} `json:"waiting"`
+	} `json:"state"`
+	LastState struct {
+		Terminated *struct {
+			Reason   string `json:"reason"`
+			ExitCode int    `json:"exitCode"`
+		} `json:"terminated"`
+	} `json:"lastState"`
+}
@@ -0,0 +1,117 @@
+package clusterwatch
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+const deploymentJSON = `{"kind": "Deployment", "spec": {"selector": {"matchLabels": {"app": "api", "tier": "backend"}}}}`
+
+// podsJSON renders a pod list with one container per pod
+func podsJSON(pods ...string) string {
+	return `{"items": [` + strings.Join(pods, ",") + `]}`
+}
+
+func podJSON(name, hash string, restarts int, waiting, lastTerminated string) string {
+	state := `{}`
+	if waiting != "" {
+		state = fmt.Sprintf(`{"waiting": {"reason": %q, "message": "back-off"}}`, waiting)
+	}
+	lastState := `{}`
+	if lastTerminated != "" {
+		lastState = fmt.Sprintf(`{"terminated": {"reason": %q, "exitCode": 137}}`, lastTerminated)
+	}
+	return fmt.Sprintf(`{"metadata": {"name": %q, "labels": {"pod-template-hash": %q}},
+		"status": {"phase": "Running", "containerStatuses": [{"name": "app", "restartCount": %d, "state": %s, "lastState": %s}]}}`,
+		name, hash, restarts, state, lastState)
+}
+
+func TestWatcherReportsPodProblems(t *testing.T) {
+	var events []Event
+	configs := map[string]config.Service{
+		"api": {Target: "deployment/api", Namespace: "prod"},
+		"db":  {Target: "db.internal", Type: "ssh"},
+	}
+	watcher := NewWatcher(func() map[string]config.Service { return configs }, 0, utils.NewLogger(utils.LevelError), func(event Event) {
+		events = append(events, event)
+	})
+
+	var pods string
+	var selectors []string
+	watcher.kubectl = func(args ...string) ([]byte, error) {
+		if args[1] == "deployment/api" {
+			return []byte(deploymentJSON), nil
+		}
+		if args[1] == "deployment/api-v2" {
+			return []byte(strings.Replace(deploymentJSON, `"api"`, `"api-v2"`, 1)), nil
+		}
+		selectors = append(selectors, args[3])
+		return []byte(pods), nil
+	}
+
+	// Baseline: a healthy pod produces no events
+	pods = podsJSON(podJSON("api-aaa-1", "aaa", 0, "", ""))
+	watcher.Poll()
+	if len(events) != 0 {
+		t.Fatalf("Expected no events for a healthy pod, got %v", events)
+	}
+	if selectors[0] != "app=api,tier=backend" {
+		t.Errorf("Unexpected label selector: %q", selectors[0])
+	}
+
+	// OOM kill followed by crash looping
+	pods = podsJSON(podJSON("api-aaa-1", "aaa", 1, "CrashLoopBackOff", "OOMKilled"))
+	watcher.Poll()
+	if len(events) != 2 {
+		t.Fatalf("Expected crash loop and OOM events, got %v", events)
+	}
+	reasons := events[0].Reason + "," + events[1].Reason
+	if reasons != "CrashLoopBackOff,OOMKilled" {
+		t.Errorf("Unexpected reasons: %s", reasons)
+	}
+	if !strings.Contains(events[1].Message, "exit code 137") {
+		t.Errorf("Expected exit code in message: %q", events[1].Message)
+	}
+
+	// The same state isn't reported twice
+	watcher.Poll()
+	if len(events) != 2 {
+		t.Errorf("Expected no repeated events, got %v", events[2:])
+	}
+
+	// A rollout brings pods with a new template hash
+	pods = podsJSON(podJSON("api-bbb-1", "bbb", 0, "", ""), podJSON("api-bbb-2", "bbb", 0, "", ""))
+	watcher.Poll()
+	if len(events) != 3 || events[2].Reason != "Rollout" || events[2].Service != "api" {
+		t.Errorf("Expected a single rollout event, got %v", events[2:])
+	}
+
+	// Pointing the service at another workload resolves its selector again
+	configs = map[string]config.Service{"api": {Target: "deployment/api-v2", Namespace: "prod"}}
+	watcher.Poll()
+	if last := selectors[len(selectors)-1]; last != "app=api-v2,tier=backend" {
+		t.Errorf("Expected the new workload's selector, got %q", last)
+	}
+
+	// Removed services are forgotten
+	configs = map[string]config.Service{}
+	watcher.Poll()
+	if len(watcher.states) != 0 || len(watcher.selectors) != 0 {
+		t.Errorf("Expected removed services to be forgotten, got %v and %v", watcher.states, watcher.selectors)
+	}
+}
+
+func TestParseSelector(t *testing.T) {
+	selector, err := parseSelector([]byte(`{"kind": "Service", "spec": {"selector": {"app": "web"}}}`))
+	if err != nil || selector != "app=web" {
+		t.Errorf("Unexpected service selector %q (%v)", selector, err)
+	}
+
+	if _, err := parseSelector([]byte(`{"kind": "Service", "spec": {}}`)); err == nil {
+		t.Error("Expected error for a service without selector")
+	}
+}
@@ -32,8 +32,18 @@ func LoadConfig() (*Config, error) {
 		return nil, fmt.Errorf("failed to load user config: %w", err)
 	}
 
+	// The team's shared config sits between the defaults and the user's
+	teamConfig, err := LoadTeamConfig(userConfig.Sync)
+	if err != nil {
+		return nil, err
+	}
+	if teamConfig != nil {
+		config = mergeConfigs(config, teamConfig)
+	}
+
 	// Merge user config into default config
 	mergedConfig := mergeConfigs(config, userConfig)
+	mergedConfig.applyServiceDefaults()
 	return mergedConfig, nil
 }
 
@@ -80,6 +90,26 @@ func mergeConfigs(defaultConfig, userConfig *Config) *Config {
 		PortForwards:       make(map[string]Service),
 		MonitoringInterval: defaultConfig.MonitoringInterval,
 		UIOptions:          defaultConfig.UIOptions,
+		Telemetry:          defaultConfig.Telemetry,
+		Notifications:      defaultConfig.Notifications,
+		StatusFile:         defaultConfig.StatusFile,
+		MDNS:               defaultConfig.MDNS,
+		Hosts:              defaultConfig.Hosts,
+		Capture:            defaultConfig.Capture,
+		Kubeconfigs:        defaultConfig.Kubeconfigs,
+		ClusterEvents:      defaultConfig.ClusterEvents,
+		Startup:            defaultConfig.Startup,
+		KubectlPath:        defaultConfig.KubectlPath,
+		Env:                defaultConfig.Env,
+		Updates:            defaultConfig.Updates,
+		BundleRegistry:     defaultConfig.BundleRegistry,
+		Agent:              defaultConfig.Agent,
+		Sync:               defaultConfig.Sync,
+		Defaults:           defaultConfig.Defaults,
+		Availability:       defaultConfig.Availability,
+		ContextPolicy:      defaultConfig.ContextPolicy,
+		Bundles:            defaultConfig.Bundles,
+		Profiles:           copyProfiles(defaultConfig.Profiles),
 	}
 
 	// Start with default port forwards
@@ -106,10 +136,107 @@ func mergeConfigs(defaultConfig, userConfig *Config) *Config {
 	if userConfig.UIOptions.Theme != "" {
 		merged.UIOptions.Theme = userConfig.UIOptions.Theme
 	}
+	if userConfig.UIOptions.Language != "" {
+		merged.UIOptions.Language = userConfig.UIOptions.Language
+	}
+
+	// Override telemetry settings if the user configured them
+	if userConfig.Telemetry != (TelemetryConfig{}) {
+		merged.Telemetry = userConfig.Telemetry
+	}
+
+	// Override notification settings if the user configured them
+	if userConfig.Notifications.Desktop || len(userConfig.Notifications.Webhooks) > 0 || userConfig.Notifications.Command != "" {
+		merged.Notifications = userConfig.Notifications
+	}
+
+	if userConfig.StatusFile != "" {
+		merged.StatusFile = userConfig.StatusFile
+	}
+
+	if userConfig.ContextPolicy != "" {
+		merged.ContextPolicy = userConfig.ContextPolicy
+	}
+
+	// Override mDNS settings if the user configured them
+	if userConfig.MDNS != (MDNSConfig{}) {
+		merged.MDNS = userConfig.MDNS
+	}
+
+	// Override hosts file settings if the user configured them
+	if userConfig.Hosts != (HostsConfig{}) {
+		merged.Hosts = userConfig.Hosts
+	}
+
+	// Override capture settings if the user configured them
+	if userConfig.Capture != (CaptureConfig{}) {
+		merged.Capture = userConfig.Capture
+	}
+
+	if len(userConfig.Kubeconfigs) > 0 {
+		merged.Kubeconfigs = userConfig.Kubeconfigs
+	}
+
+	if userConfig.ClusterEvents != (ClusterEventsConfig{}) {
+		merged.ClusterEvents = userConfig.ClusterEvents
+	}
+
+	if userConfig.Startup.Parallelism != 0 {
+		merged.Startup.Parallelism = userConfig.Startup.Parallelism
+	}
+	if userConfig.Startup.Stagger != 0 {
+		merged.Startup.Stagger = userConfig.Startup.Stagger
+	}
+
+	if userConfig.KubectlPath != "" {
+		merged.KubectlPath = userConfig.KubectlPath
+	}
+	if len(userConfig.Env) > 0 {
+		merged.Env = userConfig.Env
+	}
+
+	// Override the release source if the user configured one
+	if userConfig.Updates != (UpdatesConfig{}) {
+		merged.Updates = userConfig.Updates
+	}
+
+	if userConfig.BundleRegistry != "" {
+		merged.BundleRegistry = userConfig.BundleRegistry
+	}
+
+	if userConfig.Agent != (AgentConfig{}) {
+		merged.Agent = userConfig.Agent
+	}
+	if userConfig.Sync != (SyncConfig{}) {
+		merged.Sync = userConfig.Sync
+	}
+	merged.Defaults = merged.Defaults.overriddenBy(userConfig.Defaults)
+	if userConfig.Availability != (AvailabilityConfig{}) {
+		merged.Availability = userConfig.Availability
+	}
+	if len(userConfig.Bundles) > 0 {
+		merged.Bundles = userConfig.Bundles
+	}
+	// Profiles override the default profiles of the same name
+	for name, patterns := range userConfig.Profiles {
+		if merged.Profiles == nil {
+			merged.Profiles = make(map[string][]string)
+		}
+		merged.Profiles[name] = patterns
+	}
 
 	return merged
 }
 
+// GetUserConfigDir returns the directory holding the user config and other kportforward state
+func GetUserConfigDir() (string, error) {
+	configPath, err := getUserConfigPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Dir(configPath), nil
+}
+
 // CreateUserConfigDir creates the user config directory if it doesn't exist
 func CreateUserConfigDir() error {
 	configPath, err := getUserConfigPath()
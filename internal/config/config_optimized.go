@@ -69,8 +69,18 @@ func (ocl *OptimizedConfigLoader) LoadConfigOptimized() (*Config, error) {
 		return defaultConfig, nil
 	}
 
+	// Merge the team config, if synced, beneath the user config
+	teamConfig, err := LoadTeamConfig(userConfig.Sync)
+	if err != nil {
+		return nil, err
+	}
+	if teamConfig != nil {
+		defaultConfig = ocl.mergeConfigsOptimized(defaultConfig, teamConfig)
+	}
+
 	// Merge configs
 	merged := ocl.mergeConfigsOptimized(defaultConfig, userConfig)
+	merged.applyServiceDefaults()
 
 	ocl.cache.config = merged
 	ocl.cache.loadTime = time.Now()
@@ -140,6 +150,26 @@ func (ocl *OptimizedConfigLoader) mergeConfigsOptimized(defaultConfig, userConfi
 		PortForwards:       make(map[string]Service, totalServices),
 		MonitoringInterval: defaultConfig.MonitoringInterval,
 		UIOptions:          defaultConfig.UIOptions,
+		Telemetry:          defaultConfig.Telemetry,
+		Notifications:      defaultConfig.Notifications,
+		StatusFile:         defaultConfig.StatusFile,
+		ContextPolicy:      defaultConfig.ContextPolicy,
+		MDNS:               defaultConfig.MDNS,
+		Hosts:              defaultConfig.Hosts,
+		Capture:            defaultConfig.Capture,
+		Kubeconfigs:        defaultConfig.Kubeconfigs,
+		ClusterEvents:      defaultConfig.ClusterEvents,
+		Startup:            defaultConfig.Startup,
+		KubectlPath:        defaultConfig.KubectlPath,
+		Env:                defaultConfig.Env,
+		Updates:            defaultConfig.Updates,
+		BundleRegistry:     defaultConfig.BundleRegistry,
+		Agent:              defaultConfig.Agent,
+		Sync:               defaultConfig.Sync,
+		Defaults:           defaultConfig.Defaults,
+		Availability:       defaultConfig.Availability,
+		Bundles:            defaultConfig.Bundles,
+		Profiles:           copyProfiles(defaultConfig.Profiles),
 	}
 
 	// Copy default port forwards
@@ -165,6 +195,94 @@ func (ocl *OptimizedConfigLoader) mergeConfigsOptimized(defaultConfig, userConfi
 	if userConfig.UIOptions.Theme != "" {
 		merged.UIOptions.Theme = userConfig.UIOptions.Theme
 	}
+	if userConfig.UIOptions.Language != "" {
+		merged.UIOptions.Language = userConfig.UIOptions.Language
+	}
+
+	// Override telemetry settings if the user configured them
+	if userConfig.Telemetry != (TelemetryConfig{}) {
+		merged.Telemetry = userConfig.Telemetry
+	}
+
+	// Override notification settings if the user configured them
+	if userConfig.Notifications.Desktop || len(userConfig.Notifications.Webhooks) > 0 || userConfig.Notifications.Command != "" {
+		merged.Notifications = userConfig.Notifications
+	}
+
+	if userConfig.StatusFile != "" {
+		merged.StatusFile = userConfig.StatusFile
+	}
+
+	if userConfig.ContextPolicy != "" {
+		merged.ContextPolicy = userConfig.ContextPolicy
+	}
+
+	// Override mDNS settings if the user configured them
+	if userConfig.MDNS != (MDNSConfig{}) {
+		merged.MDNS = userConfig.MDNS
+	}
+
+	// Override hosts file settings if the user configured them
+	if userConfig.Hosts != (HostsConfig{}) {
+		merged.Hosts = userConfig.Hosts
+	}
+
+	// Override capture settings if the user configured them
+	if userConfig.Capture != (CaptureConfig{}) {
+		merged.Capture = userConfig.Capture
+	}
+
+	if len(userConfig.Kubeconfigs) > 0 {
+		merged.Kubeconfigs = userConfig.Kubeconfigs
+	}
+
+	if userConfig.ClusterEvents != (ClusterEventsConfig{}) {
+		merged.ClusterEvents = userConfig.ClusterEvents
+	}
+
+	if userConfig.Startup.Parallelism != 0 {
+		merged.Startup.Parallelism = userConfig.Startup.Parallelism
+	}
+	if userConfig.Startup.Stagger != 0 {
+		merged.Startup.Stagger = userConfig.Startup.Stagger
+	}
+
+	if userConfig.KubectlPath != "" {
+		merged.KubectlPath = userConfig.KubectlPath
+	}
+	if len(userConfig.Env) > 0 {
+		merged.Env = userConfig.Env
+	}
+
+	// Override the release source if the user configured one
+	if userConfig.Updates != (UpdatesConfig{}) {
+		merged.Updates = userConfig.Updates
+	}
+
+	if userConfig.BundleRegistry != "" {
+		merged.BundleRegistry = userConfig.BundleRegistry
+	}
+
+	if userConfig.Agent != (AgentConfig{}) {
+		merged.Agent = userConfig.Agent
+	}
+	if userConfig.Sync != (SyncConfig{}) {
+		merged.Sync = userConfig.Sync
+	}
+	merged.Defaults = merged.Defaults.overriddenBy(userConfig.Defaults)
+	if userConfig.Availability != (AvailabilityConfig{}) {
+		merged.Availability = userConfig.Availability
+	}
+	if len(userConfig.Bundles) > 0 {
+		merged.Bundles = userConfig.Bundles
+	}
+	// Profiles override the default profiles of the same name
+	for name, patterns := range userConfig.Profiles {
+		if merged.Profiles == nil {
+			merged.Profiles = make(map[string][]string)
+		}
+		merged.Profiles[name] = patterns
+	}
 
 	return merged
 }
@@ -179,6 +297,26 @@ func (ocl *OptimizedConfigLoader) copyConfig(original *Config) *Config {
 		PortForwards:       make(map[string]Service, len(original.PortForwards)),
 		MonitoringInterval: original.MonitoringInterval,
 		UIOptions:          original.UIOptions,
+		Telemetry:          original.Telemetry,
+		Notifications:      original.Notifications,
+		StatusFile:         original.StatusFile,
+		ContextPolicy:      original.ContextPolicy,
+		MDNS:               original.MDNS,
+		Hosts:              original.Hosts,
+		Capture:            original.Capture,
+		Kubeconfigs:        append([]string(nil), original.Kubeconfigs...),
+		ClusterEvents:      original.ClusterEvents,
+		Startup:            original.Startup,
+		KubectlPath:        original.KubectlPath,
+		Env:                copyEnv(original.Env),
+		Updates:            original.Updates,
+		BundleRegistry:     original.BundleRegistry,
+		Agent:              original.Agent,
+		Sync:               original.Sync,
+		Defaults:           original.Defaults,
+		Availability:       original.Availability,
+		Bundles:            copyBundles(original.Bundles),
+		Profiles:           copyProfiles(original.Profiles),
 	}
 
 	for name, service := range original.PortForwards {
@@ -188,6 +326,18 @@ func (ocl *OptimizedConfigLoader) copyConfig(original *Config) *Config {
 	return copy
 }
 
+// copyEnv copies an environment map so the cached config can't be mutated through it
+func copyEnv(env map[string]string) map[string]string {
+	if env == nil {
+		return nil
+	}
+	copied := make(map[string]string, len(env))
+	for key, value := range env {
+		copied[key] = value
+	}
+	return copied
+}
+
 // InvalidateCache clears the configuration cache
 func (ocl *OptimizedConfigLoader) InvalidateCache() {
 	ocl.cache.mutex.Lock()
@@ -264,3 +414,16 @@ func (ocs *OptimizedConfigWithStats) GetStats() ConfigStats {
 	defer ocs.statsMutex.RUnlock()
 	return ocs.stats
 }
+
+// copyBundles copies the bundle references so the cached config can't be mutated through them
+func copyBundles(bundles map[string]BundleRef) map[string]BundleRef {
+	if bundles == nil {
+		return nil
+	}
+	copied := make(map[string]BundleRef, len(bundles))
+	for name, ref := range bundles {
+		ref.Services = append([]string(nil), ref.Services...)
+		copied[name] = ref
+	}
+	return copied
+}
@@ -1,6 +1,11 @@
 package config
 
 import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
 	"testing"
 )
 
@@ -127,6 +132,246 @@ func TestServiceValidation(t *testing.T) {
 	}
 }
 
+func TestWithTargetRotatesTargets(t *testing.T) {
+	service := Service{Target: "service/payments", AlternateTargets: []string{"service/payments-canary", "service/payments-v2"}}
+
+	next, err := service.WithTarget("")
+	if err != nil {
+		t.Fatalf("WithTarget failed: %v", err)
+	}
+	if next.Target != "service/payments-canary" || strings.Join(next.AlternateTargets, " ") != "service/payments-v2 service/payments" {
+		t.Errorf("Expected the canary with the others rotated, got %s %v", next.Target, next.AlternateTargets)
+	}
+
+	back, err := next.WithTarget("service/payments")
+	if err != nil || back.Target != "service/payments" || strings.Join(back.AlternateTargets, " ") != "service/payments-canary service/payments-v2" {
+		t.Errorf("Expected to switch back to the original order, got %s %v (%v)", back.Target, back.AlternateTargets, err)
+	}
+
+	if _, err := service.WithTarget("service/other"); err == nil {
+		t.Error("Expected an unknown target to fail")
+	}
+	if _, err := (Service{Target: "service/api"}).WithTarget(""); err == nil {
+		t.Error("Expected a service without alternates to fail")
+	}
+}
+
+func TestWithServiceDefaults(t *testing.T) {
+	cfg := &Config{
+		KubectlPath: "kubectl-oidc",
+		Env:         map[string]string{"AWS_PROFILE": "dev", "AWS_REGION": "eu-west-1"},
+	}
+
+	service := cfg.WithServiceDefaults(Service{Env: map[string]string{"AWS_PROFILE": "prod"}})
+	if service.KubectlPath != "kubectl-oidc" {
+		t.Errorf("Expected the global kubectlPath, got %q", service.KubectlPath)
+	}
+	if service.Env["AWS_PROFILE"] != "prod" || service.Env["AWS_REGION"] != "eu-west-1" {
+		t.Errorf("Expected service env to override the global env, got %v", service.Env)
+	}
+	if cfg.Env["AWS_PROFILE"] != "dev" {
+		t.Errorf("Expected the global env to be left alone, got %v", cfg.Env)
+	}
+
+	service = cfg.WithServiceDefaults(Service{KubectlPath: "/opt/bin/kubectl"})
+	if service.KubectlPath != "/opt/bin/kubectl" {
+		t.Errorf("Expected the service kubectlPath to win, got %q", service.KubectlPath)
+	}
+}
+
+func TestLoadConfigAppliesDefaultsBlock(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("APPDATA", t.TempDir())
+	configDir, err := GetUserConfigDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.MkdirAll(configDir, 0755)
+	os.WriteFile(filepath.Join(configDir, "config.yaml"), []byte(`defaults:
+  namespace: "payments"
+  bindAddress: "127.0.0.2"
+  healthCheck:
+    type: "http"
+    path: "/healthz"
+portForwards:
+  api:
+    target: "service/api"
+    targetPort: 80
+    localPort: 18080
+  admin:
+    target: "service/admin"
+    targetPort: 80
+    localPort: 18081
+    namespace: "internal"
+    healthCheck:
+      type: "tcp"
+`), 0644)
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	api := cfg.PortForwards["api"]
+	if api.Namespace != "payments" || api.BindAddress != "127.0.0.2" {
+		t.Errorf("Expected the defaults to fill in api, got namespace %q and bindAddress %q", api.Namespace, api.BindAddress)
+	}
+	if api.HealthCheck == nil || api.HealthCheck.Path != "/healthz" {
+		t.Errorf("Expected the default health check, got %+v", api.HealthCheck)
+	}
+	admin := cfg.PortForwards["admin"]
+	if admin.Namespace != "internal" || admin.HealthCheck.Type != "tcp" {
+		t.Errorf("Expected admin's own settings to win, got namespace %q and health check %+v", admin.Namespace, admin.HealthCheck)
+	}
+
+	added := cfg.WithServiceDefaults(Service{Target: "service/new"})
+	if added.Namespace != "payments" {
+		t.Errorf("Expected services added at runtime to get the defaults, got namespace %q", added.Namespace)
+	}
+}
+
+func TestServiceDefaultsMergeFieldByField(t *testing.T) {
+	team := ServiceDefaults{Namespace: "team", Priority: "low"}
+	merged := team.overriddenBy(ServiceDefaults{Namespace: "mine"})
+	if merged.Namespace != "mine" || merged.Priority != "low" {
+		t.Errorf("Expected the user's namespace over the team's priority, got %+v", merged)
+	}
+}
+
+func TestLocalURLUsesHostAlias(t *testing.T) {
+	if url := (Service{}).LocalURL(9080); url != "http://localhost:9080" {
+		t.Errorf("Expected a localhost URL, got %s", url)
+	}
+	if url := (Service{HostAlias: "payments.localtest.me", LocalTLS: true}).LocalURL(9443); url != "https://payments.localtest.me:9443" {
+		t.Errorf("Expected the alias URL, got %s", url)
+	}
+}
+
+func TestServiceIsWildcard(t *testing.T) {
+	tests := []struct {
+		service  Service
+		wildcard bool
+		pattern  string
+	}{
+		{Service{Target: "service/api-*"}, true, "api-*"},
+		{Service{Target: "svc/api-?"}, true, "api-?"},
+		{Service{Selector: "app=shop"}, true, "*"},
+		{Service{Target: "service/api"}, false, "api"},
+		{Service{Target: "pod/api-*"}, false, "api-*"},
+		{Service{Target: "api-*"}, false, "*"},
+		{Service{Target: "pod", Selector: "app=shop"}, false, "*"},
+	}
+	for _, test := range tests {
+		if wildcard := test.service.IsWildcard(); wildcard != test.wildcard {
+			t.Errorf("%+v: expected wildcard %v", test.service, test.wildcard)
+		}
+		if pattern := test.service.WildcardPattern(); pattern != test.pattern {
+			t.Errorf("%+v: expected pattern %q, got %q", test.service, test.pattern, pattern)
+		}
+	}
+}
+
+func TestServiceIsPodSelector(t *testing.T) {
+	tests := []struct {
+		service     Service
+		podSelector bool
+	}{
+		{Service{Target: "pod", Selector: "app=shop"}, true},
+		{Service{Target: "pod/shop-7d9f", Selector: "app=shop"}, true},
+		{Service{Target: "po/shop-7d9f", Selector: "app=shop"}, true},
+		{Service{Target: "pod/shop-7d9f"}, false},
+		{Service{Target: "service/shop", Selector: "app=shop"}, false},
+		{Service{Selector: "app=shop"}, false},
+	}
+	for _, test := range tests {
+		if podSelector := test.service.IsPodSelector(); podSelector != test.podSelector {
+			t.Errorf("%+v: expected pod selector %v", test.service, test.podSelector)
+		}
+	}
+}
+
+func TestServiceLocalPorts(t *testing.T) {
+	first, last, err := Service{LocalPortRange: "30000-30099"}.LocalPorts()
+	if err != nil || first != 30000 || last != 30099 {
+		t.Errorf("Expected 30000-30099, got %d-%d (%v)", first, last, err)
+	}
+	if first, last, err := (Service{}).LocalPorts(); err != nil || first != 0 || last != 0 {
+		t.Errorf("Expected no range, got %d-%d (%v)", first, last, err)
+	}
+	for _, invalid := range []string{"30000", "30099-30000", "0-10", "30000-70000", "a-b"} {
+		if _, _, err := (Service{LocalPortRange: invalid}).LocalPorts(); err == nil {
+			t.Errorf("Expected %q to be rejected", invalid)
+		}
+	}
+}
+
+func TestWithServiceDefaultsMainPort(t *testing.T) {
+	ports := []PortMapping{{Local: 8080, Target: 80}, {Name: "metrics", Local: 9090, Target: 9090}}
+
+	service := (&Config{}).WithServiceDefaults(Service{Ports: ports})
+	if service.LocalPort != 8080 || service.TargetPort != 80 || len(service.Ports) != 1 || service.Ports[0].Name != "metrics" {
+		t.Errorf("Expected the first port to become the main one, got %+v", service)
+	}
+	if again := (&Config{}).WithServiceDefaults(service); again.TargetPort != 80 || len(again.Ports) != 1 {
+		t.Errorf("Expected applying defaults twice to change nothing, got %+v", again)
+	}
+
+	service = (*Config)(nil).WithServiceDefaults(Service{LocalPort: 3000, TargetPort: 3000, Ports: ports})
+	if service.TargetPort != 3000 || len(service.Ports) != 2 {
+		t.Errorf("Expected ports to be further ports next to targetPort, got %+v", service)
+	}
+}
+
+func TestLocalURLUsesBindAddress(t *testing.T) {
+	tests := []struct {
+		bindAddress string
+		expected    string
+	}{
+		{"127.0.0.1,::1", "http://localhost:9080"},
+		{"0.0.0.0", "http://localhost:9080"},
+		{"::1", "http://[::1]:9080"},
+		{" 192.168.1.20 , 127.0.0.1", "http://192.168.1.20:9080"},
+	}
+	for _, test := range tests {
+		if url := (Service{BindAddress: test.bindAddress}).LocalURL(9080); url != test.expected {
+			t.Errorf("bindAddress %q: expected %s, got %s", test.bindAddress, test.expected, url)
+		}
+	}
+
+	urls := Service{BindAddress: "127.0.0.1,::1,::"}.LocalURLs(9080)
+	expected := []string{"http://127.0.0.1:9080", "http://[::1]:9080", "http://localhost:9080"}
+	if !reflect.DeepEqual(urls, expected) {
+		t.Errorf("Expected a URL per address %v, got %v", expected, urls)
+	}
+
+	if url := (Service{Protocol: "UDP"}).LocalURL(8125); url != "udp://localhost:8125" {
+		t.Errorf("Expected a udp URL, got %s", url)
+	}
+}
+
+func TestServicePluginName(t *testing.T) {
+	service := Service{Type: "plugin:corpvpn"}
+	if name := service.PluginName(); name != "corpvpn" {
+		t.Errorf("Expected plugin corpvpn, got %q", name)
+	}
+	if service.UsesKubectl() {
+		t.Errorf("Expected a plugin service not to use kubectl")
+	}
+	if name := (Service{Type: "web"}).PluginName(); name != "" {
+		t.Errorf("Expected no plugin for a web service, got %q", name)
+	}
+}
+
+func TestServiceEnviron(t *testing.T) {
+	if environ := (Service{}).Environ(); environ != nil {
+		t.Errorf("Expected a service without env to inherit the environment, got %d entries", len(environ))
+	}
+
+	environ := Service{Env: map[string]string{"AWS_PROFILE": "prod"}}.Environ()
+	if len(environ) == 0 || environ[len(environ)-1] != "AWS_PROFILE=prod" {
+		t.Errorf("Expected AWS_PROFILE=prod last so it overrides the inherited value, got %v", environ)
+	}
+}
+
 // validateService is a helper function for testing service validation
 func validateService(service Service) bool {
 	if service.Target == "" {
@@ -143,3 +388,72 @@ func validateService(service Service) bool {
 	}
 	return true
 }
+
+func TestServiceFilter(t *testing.T) {
+	services := map[string]Service{
+		"api-gateway":   {Target: "service/api-gateway"},
+		"api-users":     {Target: "service/api-users"},
+		"flyte-console": {Target: "service/flyte-console"},
+		"shop":          {Target: "service/shop-*"},
+	}
+
+	tests := []struct {
+		name   string
+		filter ServiceFilter
+		want   []string
+	}{
+		{"no filter", ServiceFilter{}, []string{"api-gateway", "api-users", "flyte-console", "shop"}},
+		{"only names", ServiceFilter{Only: []string{"api-gateway", "flyte-console"}}, []string{"api-gateway", "flyte-console", "shop"}},
+		{"only glob", ServiceFilter{Only: []string{"api-*"}}, []string{"api-gateway", "api-users", "shop"}},
+		{"exclude", ServiceFilter{Exclude: []string{"api-users", "shop"}}, []string{"api-gateway", "flyte-console"}},
+		{"only and exclude", ServiceFilter{Only: []string{"api-*"}, Exclude: []string{"*-users"}}, []string{"api-gateway", "shop"}},
+		{"profile", ServiceFilter{Profile: []string{"api-*", "flyte-console"}}, []string{"api-gateway", "api-users", "flyte-console", "shop"}},
+		{"profile and only", ServiceFilter{Only: []string{"*-gateway", "flyte-*"}, Profile: []string{"api-*"}}, []string{"api-gateway", "shop"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selected := tt.filter.Apply(services)
+			var got []string
+			for name := range selected {
+				got = append(got, name)
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+
+	// Forwards of a wildcard entry are selected by their own name or the entry's
+	filter := ServiceFilter{Only: []string{"shop"}, Exclude: []string{"shop-cart"}}
+	if !filter.Matches("shop-orders", "shop") || filter.Matches("shop-cart", "shop") || filter.Matches("other-orders", "other") {
+		t.Error("Expected wildcard forwards to be selected through their entry, unless excluded")
+	}
+
+	if err := (ServiceFilter{Only: []string{"api-["}}).Validate(); err == nil {
+		t.Error("Expected a malformed pattern to be rejected")
+	}
+}
+
+func TestProfiles(t *testing.T) {
+	defaultConfig := &Config{Profiles: map[string][]string{"frontend": {"web-*"}, "backend": {"api-*"}}}
+	userConfig := &Config{Profiles: map[string][]string{"backend": {"api-*", "db"}, "empty": {}}}
+	merged := mergeConfigs(defaultConfig, userConfig)
+
+	if got, want := merged.ProfileNames(), []string{"backend", "empty", "frontend"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected profiles %v, got %v", want, got)
+	}
+	patterns, err := merged.Profile("backend")
+	if err != nil || !reflect.DeepEqual(patterns, []string{"api-*", "db"}) {
+		t.Errorf("Expected the user's backend profile to override the default, got %v, %v", patterns, err)
+	}
+	if _, err := merged.Profile("empty"); err == nil {
+		t.Error("Expected a profile without services to be rejected")
+	}
+	if _, err := merged.Profile("missing"); err == nil || !strings.Contains(err.Error(), "backend, empty, frontend") {
+		t.Errorf("Expected an unknown profile to list the profiles, got %v", err)
+	}
+	if defaultConfig.Profiles["backend"][0] != "api-*" || len(defaultConfig.Profiles["backend"]) != 1 {
+		t.Error("Expected merging to leave the default profiles alone")
+	}
+}
@@ -0,0 +1,60 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ServiceDiff lists the services that differ between two sets of port-forwards
+type ServiceDiff struct {
+	Added   []string
+	Removed []string
+	Changed []string
+}
+
+// DiffServices compares a running set of services with an updated one
+func DiffServices(running, updated map[string]Service) ServiceDiff {
+	var diff ServiceDiff
+	for name, service := range updated {
+		current, exists := running[name]
+		switch {
+		case !exists:
+			diff.Added = append(diff.Added, name)
+		case !reflect.DeepEqual(current, service):
+			diff.Changed = append(diff.Changed, name)
+		}
+	}
+	for name := range running {
+		if _, exists := updated[name]; !exists {
+			diff.Removed = append(diff.Removed, name)
+		}
+	}
+	sort.Strings(diff.Added)
+	sort.Strings(diff.Removed)
+	sort.Strings(diff.Changed)
+	return diff
+}
+
+// Empty reports whether the two sets were the same
+func (d ServiceDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// String summarizes the diff, e.g. "added api; removed db; restarted web"
+func (d ServiceDiff) String() string {
+	if d.Empty() {
+		return "no changes"
+	}
+	var parts []string
+	for _, group := range []struct {
+		verb  string
+		names []string
+	}{{"added", d.Added}, {"removed", d.Removed}, {"restarted", d.Changed}} {
+		if len(group.names) > 0 {
+			parts = append(parts, fmt.Sprintf("%s %s", group.verb, strings.Join(group.names, ", ")))
+		}
+	}
+	return strings.Join(parts, "; ")
+}
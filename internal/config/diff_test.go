@@ -0,0 +1,32 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffServices(t *testing.T) {
+	running := map[string]Service{
+		"api": {Target: "service/api", LocalPort: 8080},
+		"db":  {Target: "service/db", LocalPort: 5432},
+		"web": {Target: "service/web", LocalPort: 3000},
+	}
+	updated := map[string]Service{
+		"api":   {Target: "service/api", LocalPort: 8080},
+		"web":   {Target: "service/web", LocalPort: 3001},
+		"cache": {Target: "service/redis", LocalPort: 6379},
+	}
+
+	diff := DiffServices(running, updated)
+	expected := ServiceDiff{Added: []string{"cache"}, Removed: []string{"db"}, Changed: []string{"web"}}
+	if !reflect.DeepEqual(diff, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, diff)
+	}
+	if summary := diff.String(); summary != "added cache; removed db; restarted web" {
+		t.Errorf("Unexpected summary %q", summary)
+	}
+
+	if diff := DiffServices(running, running); !diff.Empty() || diff.String() != "no changes" {
+		t.Errorf("Expected no changes, got %+v", diff)
+	}
+}
@@ -0,0 +1,80 @@
+package config
+
+import (
+	"fmt"
+	"path"
+)
+
+// ServiceFilter selects services by name, e.g. from --only, --exclude and --profile.
+// Patterns are globs like api-*; an empty Only or Profile selects every service.
+type ServiceFilter struct {
+	Only    []string
+	Exclude []string
+	Profile []string // Patterns of the active profile
+}
+
+// Validate reports a malformed pattern
+func (f ServiceFilter) Validate() error {
+	patterns := append(append(append([]string{}, f.Only...), f.Exclude...), f.Profile...)
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid service pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// IsZero reports whether the filter selects every service
+func (f ServiceFilter) IsZero() bool {
+	return len(f.Only) == 0 && len(f.Exclude) == 0 && len(f.Profile) == 0
+}
+
+// Matches reports whether a service known by any of names is selected: one of them
+// matches Only, one matches Profile and none matches Exclude. A forward expanded from a
+// wildcard entry is known by its own name and the entry's.
+func (f ServiceFilter) Matches(names ...string) bool {
+	for _, name := range names {
+		if matchAny(f.Exclude, name) {
+			return false
+		}
+	}
+	return selects(f.Only, names) && selects(f.Profile, names)
+}
+
+// Apply returns the selected services. Wildcard entries are kept unless excluded, as
+// the forwards they expand to are only known once the cluster is listed.
+func (f ServiceFilter) Apply(services map[string]Service) map[string]Service {
+	if f.IsZero() {
+		return services
+	}
+	selected := make(map[string]Service, len(services))
+	for name, service := range services {
+		if service.IsWildcard() && !matchAny(f.Exclude, name) || f.Matches(name) {
+			selected[name] = service
+		}
+	}
+	return selected
+}
+
+// selects reports whether any of names matches patterns; no patterns select every name
+func selects(patterns []string, names []string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, name := range names {
+		if matchAny(patterns, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchAny reports whether name matches any of patterns
+func matchAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,186 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/victorkazakov/kportforward/internal/utils"
+	"gopkg.in/yaml.v3"
+)
+
+// SaveService writes a service's settings to the user config. A service already in
+// the user config only has the given keys (e.g. "localPort") updated, keeping the
+// rest of the entry and its comments; other services are added in full, since a
+// user entry replaces the embedded default.
+func SaveService(name string, service Service, keys ...string) error {
+	path, err := getUserConfigPath()
+	if err != nil {
+		return err
+	}
+	return saveServiceTo(path, name, service, keys)
+}
+
+// saveServiceTo updates the service entry in the config file at path
+func saveServiceTo(path, name string, service Service, keys []string) error {
+	doc, root, err := readConfigDoc(path)
+	if err != nil {
+		return err
+	}
+
+	var encoded yaml.Node
+	if err := encoded.Encode(service); err != nil {
+		return fmt.Errorf("failed to encode service %s: %w", name, err)
+	}
+
+	portForwards := mappingValue(root, "portForwards")
+	if portForwards == nil {
+		portForwards = &yaml.Node{Kind: yaml.MappingNode}
+		setMappingValue(root, "portForwards", portForwards)
+	}
+
+	existing := mappingValue(portForwards, name)
+	if existing == nil || existing.Kind != yaml.MappingNode {
+		setMappingValue(portForwards, name, &encoded)
+	} else {
+		for _, key := range keys {
+			if value := mappingValue(&encoded, key); value != nil {
+				setMappingValue(existing, key, value)
+			} else {
+				deleteMappingKey(existing, key) // Zero value, omitted when encoding
+			}
+		}
+	}
+
+	return writeConfigDoc(path, doc)
+}
+
+// SaveBundle adds a bundle's services to the user config in full and records its
+// pinned version under bundles. Services that an earlier version of the bundle
+// added but this one no longer has are removed.
+func SaveBundle(name string, ref BundleRef, services map[string]Service) error {
+	path, err := getUserConfigPath()
+	if err != nil {
+		return err
+	}
+	if err := CreateUserConfigDir(); err != nil {
+		return err
+	}
+	return saveBundleTo(path, name, ref, services)
+}
+
+// saveBundleTo writes the bundle's services and reference to the config file at path
+func saveBundleTo(path, name string, ref BundleRef, services map[string]Service) error {
+	doc, root, err := readConfigDoc(path)
+	if err != nil {
+		return err
+	}
+
+	portForwards := mappingValue(root, "portForwards")
+	if portForwards == nil {
+		portForwards = &yaml.Node{Kind: yaml.MappingNode}
+		setMappingValue(root, "portForwards", portForwards)
+	}
+
+	bundles := mappingValue(root, "bundles")
+	if bundles == nil {
+		bundles = &yaml.Node{Kind: yaml.MappingNode}
+		setMappingValue(root, "bundles", bundles)
+	}
+	if previous := mappingValue(bundles, name); previous != nil {
+		var old BundleRef
+		if err := previous.Decode(&old); err == nil {
+			for _, service := range old.Services {
+				if _, kept := services[service]; !kept {
+					deleteMappingKey(portForwards, service)
+				}
+			}
+		}
+	}
+
+	ref.Services = make([]string, 0, len(services))
+	for service := range services {
+		ref.Services = append(ref.Services, service)
+	}
+	sort.Strings(ref.Services)
+
+	for _, service := range ref.Services {
+		var encoded yaml.Node
+		if err := encoded.Encode(services[service]); err != nil {
+			return fmt.Errorf("failed to encode service %s: %w", service, err)
+		}
+		setMappingValue(portForwards, service, &encoded)
+	}
+
+	var encodedRef yaml.Node
+	if err := encodedRef.Encode(ref); err != nil {
+		return fmt.Errorf("failed to encode bundle %s: %w", name, err)
+	}
+	setMappingValue(bundles, name, &encodedRef)
+
+	return writeConfigDoc(path, doc)
+}
+
+// readConfigDoc parses the config file at path, or starts an empty document when it
+// doesn't exist, and returns the document with its root mapping
+func readConfigDoc(path string) (*yaml.Node, *yaml.Node, error) {
+	doc := &yaml.Node{}
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	if len(data) > 0 {
+		if err := yaml.Unmarshal(data, doc); err != nil {
+			return nil, nil, fmt.Errorf("failed to parse config file: %w", err)
+		}
+	}
+	if doc.Kind == 0 {
+		doc = &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, nil, fmt.Errorf("config file %s is not a mapping", path)
+	}
+	return doc, root, nil
+}
+
+// writeConfigDoc writes a document back to the config file at path
+func writeConfigDoc(path string, doc *yaml.Node) error {
+	out, err := yaml.Marshal(doc)
+	if err != nil {
+		return fmt.Errorf("failed to encode config file: %w", err)
+	}
+	return utils.WriteFileAtomic(path, out, 0644)
+}
+
+// mappingValue returns the value node for key in a mapping node, or nil
+func mappingValue(mapping *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// setMappingValue replaces or appends key in a mapping node
+func setMappingValue(mapping *yaml.Node, key string, value *yaml.Node) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			mapping.Content[i+1] = value
+			return
+		}
+	}
+	mapping.Content = append(mapping.Content,
+		&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, value)
+}
+
+// deleteMappingKey removes key from a mapping node
+func deleteMappingKey(mapping *yaml.Node, key string) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			mapping.Content = append(mapping.Content[:i], mapping.Content[i+2:]...)
+			return
+		}
+	}
+}
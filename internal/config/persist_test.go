@@ -0,0 +1,109 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestSaveServiceUpdatesExistingEntry(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	original := `# My services
+portForwards:
+  api:
+    target: "service/api" # the gateway
+    targetPort: 8080
+    localPort: 9080
+    namespace: "default"
+    type: "rest"
+    bindAddress: "0.0.0.0"
+`
+	os.WriteFile(path, []byte(original), 0644)
+
+	service := Service{Target: "service/api", TargetPort: 8080, LocalPort: 9090, Namespace: "default", Type: "rest"}
+	if err := saveServiceTo(path, "api", service, []string{"localPort", "bindAddress"}); err != nil {
+		t.Fatalf("saveServiceTo failed: %v", err)
+	}
+
+	data, _ := os.ReadFile(path)
+	if !strings.Contains(string(data), "# the gateway") {
+		t.Errorf("Expected comments to be kept, got:\n%s", data)
+	}
+
+	var saved Config
+	if err := yaml.Unmarshal(data, &saved); err != nil {
+		t.Fatalf("Saved config doesn't parse: %v", err)
+	}
+	api := saved.PortForwards["api"]
+	if api.LocalPort != 9090 || api.BindAddress != "" || api.TargetPort != 8080 {
+		t.Errorf("Unexpected saved service %+v", api)
+	}
+}
+
+func TestSaveServiceAddsNewEntry(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+
+	service := Service{Target: "service/web", TargetPort: 80, LocalPort: 8081, Namespace: "web", Type: "web"}
+	if err := saveServiceTo(path, "web", service, []string{"localPort"}); err != nil {
+		t.Fatalf("saveServiceTo failed: %v", err)
+	}
+
+	saved, err := loadUserConfig(path)
+	if err != nil {
+		t.Fatalf("Saved config doesn't load: %v", err)
+	}
+	if !reflect.DeepEqual(saved.PortForwards["web"], service) {
+		t.Errorf("Expected the full service to be saved, got %+v", saved.PortForwards["web"])
+	}
+}
+
+func TestSaveBundleReplacesPreviousVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	original := `portForwards:
+  mine:
+    target: "service/mine"
+    targetPort: 80
+    localPort: 8000
+    namespace: "default"
+    type: "web"
+`
+	os.WriteFile(path, []byte(original), 0644)
+
+	v1 := map[string]Service{
+		"flyte-console": {Target: "service/flyteconsole", TargetPort: 80, LocalPort: 8088, Namespace: "flyte", Type: "web"},
+		"flyte-old":     {Target: "service/old", TargetPort: 80, LocalPort: 8089, Namespace: "flyte", Type: "web"},
+	}
+	if err := saveBundleTo(path, "flyte", BundleRef{Version: "1.0.0"}, v1); err != nil {
+		t.Fatalf("saveBundleTo failed: %v", err)
+	}
+
+	v2 := map[string]Service{
+		"flyte-console": {Target: "service/flyteconsole", TargetPort: 80, LocalPort: 8090, Namespace: "flyte", Type: "web"},
+	}
+	if err := saveBundleTo(path, "flyte", BundleRef{Version: "1.1.0"}, v2); err != nil {
+		t.Fatalf("saveBundleTo failed: %v", err)
+	}
+
+	data, _ := os.ReadFile(path)
+	var saved Config
+	if err := yaml.Unmarshal(data, &saved); err != nil {
+		t.Fatalf("Saved config doesn't parse: %v", err)
+	}
+	if _, ok := saved.PortForwards["mine"]; !ok {
+		t.Errorf("Expected the user's own service to be kept")
+	}
+	if _, ok := saved.PortForwards["flyte-old"]; ok {
+		t.Errorf("Expected the service dropped by the new version to be removed")
+	}
+	if saved.PortForwards["flyte-console"].LocalPort != 8090 {
+		t.Errorf("Expected the updated service, got %+v", saved.PortForwards["flyte-console"])
+	}
+	expected := BundleRef{Version: "1.1.0", Services: []string{"flyte-console"}}
+	if !reflect.DeepEqual(saved.Bundles["flyte"], expected) {
+		t.Errorf("Expected bundle ref %+v, got %+v", expected, saved.Bundles["flyte"])
+	}
+}
@@ -0,0 +1,44 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ProfileNames returns the names of the configured profiles, sorted
+func (c *Config) ProfileNames() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Profile returns the service patterns of the named profile
+func (c *Config) Profile(name string) ([]string, error) {
+	patterns, exists := c.Profiles[name]
+	if !exists {
+		if len(c.Profiles) == 0 {
+			return nil, fmt.Errorf("unknown profile %q: no profiles are configured", name)
+		}
+		return nil, fmt.Errorf("unknown profile %q (profiles: %s)", name, strings.Join(c.ProfileNames(), ", "))
+	}
+	if len(patterns) == 0 {
+		return nil, fmt.Errorf("profile %q lists no services", name)
+	}
+	return patterns, nil
+}
+
+// copyProfiles copies the profiles so the cached config can't be mutated through them
+func copyProfiles(profiles map[string][]string) map[string][]string {
+	if profiles == nil {
+		return nil
+	}
+	copied := make(map[string][]string, len(profiles))
+	for name, patterns := range profiles {
+		copied[name] = append([]string(nil), patterns...)
+	}
+	return copied
+}
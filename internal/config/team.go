@@ -0,0 +1,76 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// DefaultTeamConfigFile is the team config's file within its repository
+const DefaultTeamConfigFile = "kportforward.yaml"
+
+// TeamConfigDir returns the directory the team config repository is cloned into
+func TeamConfigDir() (string, error) {
+	configDir, err := GetUserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "team"), nil
+}
+
+// TeamConfigPath returns the team config file within the clone
+func TeamConfigPath(sync SyncConfig) (string, error) {
+	dir, err := TeamConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return teamConfigFile(dir, sync), nil
+}
+
+// teamConfigFile returns the team config file within a clone at dir
+func teamConfigFile(dir string, sync SyncConfig) string {
+	path := sync.Path
+	if path == "" {
+		path = DefaultTeamConfigFile
+	}
+	return filepath.Join(dir, filepath.FromSlash(path))
+}
+
+// LoadTeamConfig loads the team config last pulled for sync; nil when sync isn't
+// configured or nothing has been pulled yet
+func LoadTeamConfig(sync SyncConfig) (*Config, error) {
+	if sync.Repo == "" {
+		return nil, nil
+	}
+	path, err := TeamConfigPath(sync)
+	if err != nil {
+		return nil, err
+	}
+	return loadTeamConfigFile(path)
+}
+
+// loadTeamConfigFile loads a team config, ignoring its own sync settings
+func loadTeamConfigFile(path string) (*Config, error) {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil, nil
+	}
+	teamConfig, err := loadUserConfig(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load team config %s: %w", path, err)
+	}
+	teamConfig.Sync = SyncConfig{}
+	return teamConfig, nil
+}
+
+// LoadUserConfig loads the user config alone, without defaults or the team config;
+// an empty config when there is none
+func LoadUserConfig() (*Config, error) {
+	path, err := getUserConfigPath()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return &Config{}, nil
+	}
+	return loadUserConfig(path)
+}
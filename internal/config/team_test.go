@@ -0,0 +1,76 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadConfigMergesTeamConfigBeneathUserConfig(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("APPDATA", t.TempDir())
+	configDir, err := GetUserConfigDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	teamFile := filepath.Join(configDir, "team", "teams", "payments.yaml")
+	os.MkdirAll(filepath.Dir(teamFile), 0755)
+	os.WriteFile(teamFile, []byte(`monitoringInterval: 7s
+kubectlPath: "kubectl-team"
+sync:
+  repo: "ignored"
+portForwards:
+  team-api:
+    target: "service/api"
+    targetPort: 80
+    localPort: 18080
+    namespace: "payments"
+  team-web:
+    target: "service/web"
+    targetPort: 80
+    localPort: 18081
+    namespace: "payments"
+`), 0644)
+	os.WriteFile(filepath.Join(configDir, "config.yaml"), []byte(`sync:
+  repo: "git@example.com:acme/config.git"
+  path: "teams/payments.yaml"
+monitoringInterval: 3s
+portForwards:
+  team-web:
+    target: "service/web"
+    targetPort: 80
+    localPort: 19081
+    namespace: "payments"
+`), 0644)
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	if _, ok := cfg.PortForwards["team-api"]; !ok {
+		t.Error("Expected the team's services to be merged")
+	}
+	if got := cfg.PortForwards["team-web"].LocalPort; got != 19081 {
+		t.Errorf("Expected the user's team-web to override the team's, got localPort %d", got)
+	}
+	if cfg.MonitoringInterval.Seconds() != 3 || cfg.KubectlPath != "kubectl-team" {
+		t.Errorf("Expected user settings over team settings over defaults, got %v and %q", cfg.MonitoringInterval, cfg.KubectlPath)
+	}
+	if cfg.Sync.Repo != "git@example.com:acme/config.git" {
+		t.Errorf("Expected sync from the user config, got %q", cfg.Sync.Repo)
+	}
+}
+
+func TestLoadTeamConfigNotPulled(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("APPDATA", t.TempDir())
+
+	team, err := LoadTeamConfig(SyncConfig{Repo: "git@example.com:acme/config.git"})
+	if err != nil || team != nil {
+		t.Errorf("LoadTeamConfig before a pull = %v, %v; want nil, nil", team, err)
+	}
+	if team, err := LoadTeamConfig(SyncConfig{}); err != nil || team != nil {
+		t.Errorf("LoadTeamConfig without sync = %v, %v; want nil, nil", team, err)
+	}
+}
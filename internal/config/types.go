@@ -1,42 +1,801 @@
 package config
 
 import (
+	"fmt"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
+
+	"github.com/victorkazakov/kportforward/internal/utils"
 )
 
 // Config represents the main configuration structure
 type Config struct {
-	PortForwards       map[string]Service `yaml:"portForwards"`
-	MonitoringInterval time.Duration      `yaml:"monitoringInterval"`
-	UIOptions          UIConfig           `yaml:"uiOptions"`
+	PortForwards       map[string]Service   `yaml:"portForwards"`
+	MonitoringInterval time.Duration        `yaml:"monitoringInterval"`
+	UIOptions          UIConfig             `yaml:"uiOptions"`
+	Telemetry          TelemetryConfig      `yaml:"telemetry,omitempty"`
+	Notifications      NotificationConfig   `yaml:"notifications,omitempty"`
+	StatusFile         string               `yaml:"statusFile,omitempty"` // Write the status map as JSON to this path on every change
+	MDNS               MDNSConfig           `yaml:"mdns,omitempty"`
+	Hosts              HostsConfig          `yaml:"hosts,omitempty"`
+	Capture            CaptureConfig        `yaml:"capture,omitempty"`
+	Kubeconfigs        []string             `yaml:"kubeconfigs,omitempty"` // Kubeconfig files merged in order, like a KUBECONFIG list
+	ClusterEvents      ClusterEventsConfig  `yaml:"clusterEvents,omitempty"`
+	Startup            StartupConfig        `yaml:"startup,omitempty"`
+	KubectlPath        string               `yaml:"kubectlPath,omitempty"` // Default kubectl binary for services, e.g. a wrapper like kubectl-oidc
+	Env                map[string]string    `yaml:"env,omitempty"`         // Default environment for the commands services run
+	Updates            UpdatesConfig        `yaml:"updates,omitempty"`
+	BundleRegistry     string               `yaml:"bundleRegistry,omitempty"` // Base URL of the service bundle registry
+	Bundles            map[string]BundleRef `yaml:"bundles,omitempty"`        // Bundles added with `kportforward bundles add`
+	Agent              AgentConfig          `yaml:"agent,omitempty"`          // Route services through an in-cluster agent
+	Sync               SyncConfig           `yaml:"sync,omitempty"`           // Team config merged beneath this one; only read from the user config
+	Defaults           ServiceDefaults      `yaml:"defaults,omitempty"`       // Applied to services that leave those fields empty
+	Availability       AvailabilityConfig   `yaml:"availability,omitempty"`
+	ContextPolicy      string               `yaml:"contextPolicy,omitempty"` // On a kubectl context change: "restart" (default), "ask" or "ignore"
+	Profiles           map[string][]string  `yaml:"profiles,omitempty"`      // Named groups of services, by name or glob, picked with --profile
 }
 
 // Service represents a single port-forward service configuration
 type Service struct {
 	Target      string `yaml:"target"`
 	TargetPort  int    `yaml:"targetPort"`
-	LocalPort   int    `yaml:"localPort"`
+	LocalPort   int    `yaml:"localPort"` // 0 picks any free port
 	Namespace   string `yaml:"namespace"`
+	Context     string `yaml:"context,omitempty"` // Kubeconfig context to forward through (default: the current context)
 	Type        string `yaml:"type"`
 	SwaggerPath string `yaml:"swaggerPath,omitempty"`
 	APIPath     string `yaml:"apiPath,omitempty"`
+	AutoOpen    bool   `yaml:"autoOpen,omitempty"`    // Open the service (or its UI) in the browser once running
+	BindAddress string `yaml:"bindAddress,omitempty"` // Local addresses to listen on, comma-separated, e.g. 127.0.0.1,::1 (default: localhost); 0.0.0.0 exposes it on the LAN
+	LocalTLS    bool   `yaml:"localTLS,omitempty"`    // Serve https://localhost:PORT in front of the forward
+	TLSCert     string `yaml:"tlsCert,omitempty"`     // Certificate for localTLS (default: generated localhost certificate)
+	TLSKey      string `yaml:"tlsKey,omitempty"`      // Private key for tlsCert
+	AccessLog   bool   `yaml:"accessLog,omitempty"`   // Log every connection (and HTTP request) through the forward
+	Priority    string `yaml:"priority,omitempty"`    // "critical", "high", "normal" (default) or "low"
+	Pinned      bool   `yaml:"pinned,omitempty"`      // Never cool down; retry after every failure with a short fixed delay
+	KubectlPath string `yaml:"kubectlPath,omitempty"` // kubectl binary for this service (default: kubectlPath, then kubectl)
+	Kubeconfig  string `yaml:"kubeconfig,omitempty"`  // Kubeconfig file for this service, e.g. another cluster's (default: kubeconfigs / KUBECONFIG)
+	HostAlias   string `yaml:"hostAlias,omitempty"`   // Friendly local hostname, e.g. payments.localtest.me, shown in URLs
+
+	Env map[string]string `yaml:"env,omitempty"` // Environment for the commands this service runs, on top of env
+
+	AlternateTargets []string `yaml:"alternateTargets,omitempty"` // Targets the local port can be switched to, e.g. a canary
+
+	Ports []PortMapping `yaml:"ports,omitempty"` // Further ports forwarded by the same kubectl process; without targetPort the first is the main one
+
+	Protocol string `yaml:"protocol,omitempty"` // "tcp" (default) or "udp", relayed through a socat pod in the namespace
+	UDPImage string `yaml:"udpImage,omitempty"` // Image of the socat pod of a udp service (default: alpine/socat)
+
+	Selector string `yaml:"selector,omitempty"` // Label selector of a wildcard entry, e.g. app.kubernetes.io/part-of=shop, or of the pods a pod target is picked from
+	Wildcard string `yaml:"-"`                  // Wildcard entry the service was expanded from
+
+	LocalPortRange string `yaml:"localPortRange,omitempty"` // Local ports the forwards of a wildcard entry take, e.g. 30000-30099 (default: any free port)
+
+	WaitForRollout bool          `yaml:"waitForRollout,omitempty"` // Hold the forward until the target has rolled out / has ready endpoints
+	IdleTimeout    time.Duration `yaml:"idleTimeout,omitempty"`    // Suspend the forward after this long without traffic; the next connection resumes it
+
+	MuteNotifications bool `yaml:"muteNotifications,omitempty"` // Suppress failure/recovery notifications
+
+	HealthCheck *HealthCheckConfig `yaml:"healthCheck,omitempty"` // How to tell the forward is healthy (default: TCP connect)
+
+	Hooks *HooksConfig `yaml:"hooks,omitempty"` // Commands run when the forward starts, stops or fails
+
+	Auth *AuthConfig `yaml:"auth,omitempty"` // Inject an OAuth2/OIDC token into every request (web/rest services)
+
+	SSH      *SSHConfig      `yaml:"ssh,omitempty"`      // Bastion for type: ssh; target is then the host to reach from the bastion
+	Teleport *TeleportConfig `yaml:"teleport,omitempty"` // Tunnel for type: teleport; target is the database, app or remote host
+	CloudSQL *CloudSQLConfig `yaml:"cloudSQL,omitempty"` // Proxy options for type: cloudsql; target is the instance connection name
+
+	PluginOptions map[string]string `yaml:"pluginOptions,omitempty"` // Passed to the plugin of a type: plugin:<name> service
+}
+
+// PortMapping is a further local port of a service, forwarded to a port of its target
+type PortMapping struct {
+	Name   string `yaml:"name,omitempty"` // e.g. metrics, shown with the port
+	Local  int    `yaml:"local"`          // 0 picks any free port
+	Target int    `yaml:"target"`
+}
+
+// WithServiceDefaults applies the defaults block and the global kubectlPath and env to
+// a service; the service's own settings take precedence
+func (c *Config) WithServiceDefaults(service Service) Service {
+	service = service.withMainPort()
+	if c == nil {
+		return service
+	}
+	service = c.Defaults.Apply(service)
+	if service.KubectlPath == "" {
+		service.KubectlPath = c.KubectlPath
+	}
+	if len(c.Env) > 0 {
+		env := make(map[string]string, len(c.Env)+len(service.Env))
+		for key, value := range c.Env {
+			env[key] = value
+		}
+		for key, value := range service.Env {
+			env[key] = value
+		}
+		service.Env = env
+	}
+	return service
+}
+
+// Environ returns the service's env as KEY=value pairs on top of the current environment,
+// or nil (inherit the environment) when it sets none
+func (s Service) Environ() []string {
+	if len(s.Env) == 0 {
+		return nil
+	}
+	environ := os.Environ()
+	keys := make([]string, 0, len(s.Env))
+	for key := range s.Env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		environ = append(environ, key+"="+s.Env[key])
+	}
+	return environ
+}
+
+// IsHTTP reports whether the service speaks plain HTTP (web and rest services)
+func (s Service) IsHTTP() bool {
+	return s.Type == "web" || s.Type == "rest"
+}
+
+// UsesKubectl reports whether the service is forwarded by kubectl and follows the Kubernetes context
+func (s Service) UsesKubectl() bool {
+	return s.Type != "ssh" && s.Type != "teleport" && s.Type != "cloudsql" && s.PluginName() == ""
+}
+
+// IsUDP reports whether the service forwards UDP
+func (s Service) IsUDP() bool {
+	return strings.EqualFold(s.Protocol, "udp")
+}
+
+// PluginName returns the plugin of a type: plugin:<name> service, or ""
+func (s Service) PluginName() string {
+	if name, ok := strings.CutPrefix(s.Type, "plugin:"); ok {
+		return name
+	}
+	return ""
+}
+
+// FollowsCurrentContext reports whether the service forwards through the current
+// context of the shared kubeconfig, and so is affected when that context changes
+func (s Service) FollowsCurrentContext() bool {
+	return s.UsesKubectl() && s.Context == "" && s.Kubeconfig == ""
+}
+
+// KubeconfigPath returns the service's kubeconfig file with ~ expanded, or "" for the shared kubeconfig
+func (s Service) KubeconfigPath() string {
+	if path, err := utils.ExpandPath(s.Kubeconfig); err == nil {
+		return path
+	}
+	return s.Kubeconfig // kubectl reports the unusable path
+}
+
+// KubectlScope returns the kubectl flags selecting the service's kubeconfig and context
+func (s Service) KubectlScope() []string {
+	var args []string
+	if s.Kubeconfig != "" {
+		args = append(args, "--kubeconfig", s.KubeconfigPath())
+	}
+	if s.Context != "" {
+		args = append(args, "--context", s.Context)
+	}
+	return args
+}
+
+// WithTarget returns the service switched to target, one of its target and alternate
+// targets; an empty target switches to the next alternate. The targets are rotated
+// so that repeated switches cycle through all of them.
+func (s Service) WithTarget(target string) (Service, error) {
+	targets := append([]string{s.Target}, s.AlternateTargets...)
+	if len(targets) == 1 {
+		return s, fmt.Errorf("no alternate targets configured")
+	}
+
+	index := 1
+	if target != "" {
+		index = -1
+		for i, candidate := range targets {
+			if candidate == target {
+				index = i
+				break
+			}
+		}
+		if index < 0 {
+			return s, fmt.Errorf("%s is not a configured target (expected one of %s)", target, strings.Join(targets, ", "))
+		}
+	}
+
+	rotated := append(append([]string{}, targets[index:]...), targets[:index]...)
+	s.Target = rotated[0]
+	s.AlternateTargets = rotated[1:]
+	return s, nil
+}
+
+// IsWildcard reports whether the entry stands for every matching Kubernetes Service in
+// its namespace: its target is a name glob like service/api-*, or empty with a selector
+func (s Service) IsWildcard() bool {
+	if s.Target == "" {
+		return s.Selector != ""
+	}
+	kind, name, found := strings.Cut(s.Target, "/")
+	if !found {
+		return false // A bare name is a pod
+	}
+	switch kind {
+	case "service", "services", "svc":
+		return strings.ContainsAny(name, "*?[")
+	}
+	return false
+}
+
+// IsPodSelector reports whether the service forwards to whichever ready pod matches its
+// selector: its target is pod, or pod/<name> for the pod to start with
+func (s Service) IsPodSelector() bool {
+	if s.Selector == "" {
+		return false
+	}
+	kind, _, _ := strings.Cut(s.Target, "/")
+	switch kind {
+	case "pod", "pods", "po":
+		return true
+	}
+	return false
+}
+
+// WildcardPattern returns the name glob of a wildcard entry, "*" when only a selector is set
+func (s Service) WildcardPattern() string {
+	if _, name, found := strings.Cut(s.Target, "/"); found {
+		return name
+	}
+	return "*"
+}
+
+// withMainPort makes the first of Ports the service's main port when it only lists ports
+func (s Service) withMainPort() Service {
+	if s.TargetPort != 0 || len(s.Ports) == 0 {
+		return s
+	}
+	s.LocalPort, s.TargetPort = s.Ports[0].Local, s.Ports[0].Target
+	s.Ports = s.Ports[1:]
+	return s
+}
+
+// LocalPorts returns the first and last port of LocalPortRange, zero when it is unset
+func (s Service) LocalPorts() (int, int, error) {
+	if s.LocalPortRange == "" {
+		return 0, 0, nil
+	}
+	from, to, found := strings.Cut(s.LocalPortRange, "-")
+	first, err := strconv.Atoi(strings.TrimSpace(from))
+	if err != nil || !found {
+		return 0, 0, fmt.Errorf("invalid localPortRange %q (expected e.g. 30000-30099)", s.LocalPortRange)
+	}
+	last, err := strconv.Atoi(strings.TrimSpace(to))
+	if err != nil || first < 1 || last > 65535 || first > last {
+		return 0, 0, fmt.Errorf("invalid localPortRange %q (expected e.g. 30000-30099)", s.LocalPortRange)
+	}
+	return first, last, nil
+}
+
+// PriorityRank orders services by priority class; lower ranks start and restart first
+func (s Service) PriorityRank() int {
+	switch s.Priority {
+	case "critical":
+		return 0
+	case "high":
+		return 1
+	case "low":
+		return 3
+	default:
+		return 2
+	}
+}
+
+// BindAddresses returns the local addresses the forward listens on, or nil for localhost
+func (s Service) BindAddresses() []string {
+	return utils.SplitAddresses(s.BindAddress)
+}
+
+// LocalURL returns the URL the service is reachable at on the given local port: through
+// its host alias, or its first bind address unless that is loopback or a wildcard
+func (s Service) LocalURL(port int) string {
+	host := "localhost"
+	if addresses := s.BindAddresses(); len(addresses) > 0 {
+		host = urlHost(addresses[0])
+	}
+	if s.HostAlias != "" {
+		host = s.HostAlias
+	}
+	return s.urlFor(host, port)
+}
+
+// LocalURLs returns a URL for each address the service listens on
+func (s Service) LocalURLs(port int) []string {
+	addresses := s.BindAddresses()
+	if len(addresses) == 0 {
+		return []string{s.urlFor("localhost", port)}
+	}
+	urls := make([]string, 0, len(addresses))
+	for _, address := range addresses {
+		host := address
+		if ip := net.ParseIP(address); ip != nil && ip.IsUnspecified() {
+			host = "localhost"
+		}
+		urls = append(urls, s.urlFor(host, port))
+	}
+	return urls
+}
+
+// urlFor returns the service URL on host
+func (s Service) urlFor(host string, port int) string {
+	address := net.JoinHostPort(host, strconv.Itoa(port))
+	if s.IsUDP() {
+		return "udp://" + address
+	}
+	if s.LocalTLS {
+		return "https://" + address
+	}
+	return "http://" + address
+}
+
+// urlHost returns the host to show in URLs for a bind address: localhost for loopback
+// and wildcard addresses, which localhost reaches, and the address itself otherwise
+func urlHost(address string) string {
+	ip := net.ParseIP(address)
+	if ip == nil {
+		return address
+	}
+	if ip.IsUnspecified() || (ip.IsLoopback() && ip.To4() != nil) {
+		return "localhost"
+	}
+	return address
+}
+
+// HealthCheckConfig selects how a service's health is checked through the forward
+type HealthCheckConfig struct {
+	Type               string        `yaml:"type"`                         // "tcp" (default), "http", "grpc" (grpc.health.v1) or "exec"
+	Path               string        `yaml:"path,omitempty"`               // http: request path (default: /)
+	Scheme             string        `yaml:"scheme,omitempty"`             // http: "http" (default) or "https"
+	ExpectedStatus     []string      `yaml:"expectedStatus,omitempty"`     // http: accepted statuses, e.g. 200, 2xx or 200-204 (default: 2xx and 3xx)
+	MaxRedirects       int           `yaml:"maxRedirects,omitempty"`       // http: redirects followed on the same host (default: 5; -1 follows none)
+	InsecureSkipVerify bool          `yaml:"insecureSkipVerify,omitempty"` // http: accept any certificate over https
+	GRPCService        string        `yaml:"grpcService,omitempty"`        // grpc: service name to check (default: the server as a whole)
+	Command            []string      `yaml:"command,omitempty"`            // exec: healthy when it exits with 0; $host and $port are replaced
+	Timeout            time.Duration `yaml:"timeout,omitempty"`            // Defaults to 2s
+}
+
+// String describes the check, e.g. "https /healthz"; a nil check is the default TCP connect
+func (c *HealthCheckConfig) String() string {
+	if c == nil || c.Type == "" {
+		return "tcp"
+	}
+	switch c.Type {
+	case "http":
+		scheme, path := c.Scheme, c.Path
+		if scheme == "" {
+			scheme = "http"
+		}
+		if path == "" {
+			path = "/"
+		}
+		return scheme + " " + path
+	case "grpc":
+		if c.GRPCService != "" {
+			return "grpc " + c.GRPCService
+		}
+	case "exec":
+		return "exec " + strings.Join(c.Command, " ")
+	}
+	return c.Type
+}
+
+// ServiceDefaults are house defaults for the services that leave these fields empty
+type ServiceDefaults struct {
+	Namespace   string             `yaml:"namespace,omitempty"`
+	Type        string             `yaml:"type,omitempty"` // rpc, web, rest...; services it applies to skip type detection
+	BindAddress string             `yaml:"bindAddress,omitempty"`
+	Priority    string             `yaml:"priority,omitempty"`
+	IdleTimeout time.Duration      `yaml:"idleTimeout,omitempty"`
+	HealthCheck *HealthCheckConfig `yaml:"healthCheck,omitempty"`
+}
+
+// Apply fills in the fields a service leaves empty
+func (d ServiceDefaults) Apply(service Service) Service {
+	if service.Namespace == "" {
+		service.Namespace = d.Namespace
+	}
+	if service.Type == "" {
+		service.Type = d.Type
+	}
+	if service.BindAddress == "" {
+		service.BindAddress = d.BindAddress
+	}
+	if service.Priority == "" {
+		service.Priority = d.Priority
+	}
+	if service.IdleTimeout == 0 {
+		service.IdleTimeout = d.IdleTimeout
+	}
+	if service.HealthCheck == nil {
+		service.HealthCheck = d.HealthCheck
+	}
+	return service
+}
+
+// overriddenBy merges other defaults over these field by field
+func (d ServiceDefaults) overriddenBy(other ServiceDefaults) ServiceDefaults {
+	if other.Namespace != "" {
+		d.Namespace = other.Namespace
+	}
+	if other.Type != "" {
+		d.Type = other.Type
+	}
+	if other.BindAddress != "" {
+		d.BindAddress = other.BindAddress
+	}
+	if other.Priority != "" {
+		d.Priority = other.Priority
+	}
+	if other.IdleTimeout != 0 {
+		d.IdleTimeout = other.IdleTimeout
+	}
+	if other.HealthCheck != nil {
+		d.HealthCheck = other.HealthCheck
+	}
+	return d
+}
+
+// applyServiceDefaults fills in the configured services from the defaults block
+func (c *Config) applyServiceDefaults() {
+	if c.Defaults == (ServiceDefaults{}) {
+		return
+	}
+	for name, service := range c.PortForwards {
+		c.PortForwards[name] = c.Defaults.Apply(service)
+	}
+}
+
+// HooksConfig holds shell commands run at points of a service's lifecycle, with the
+// service's details in KPF_* environment variables
+type HooksConfig struct {
+	PreStart  string        `yaml:"preStart,omitempty"`  // Before every start; a failure fails the start
+	PostStart string        `yaml:"postStart,omitempty"` // Once the forward is running
+	PreStop   string        `yaml:"preStop,omitempty"`   // Before the forward is stopped
+	OnFailure string        `yaml:"onFailure,omitempty"` // When the forward fails
+	Timeout   time.Duration `yaml:"timeout,omitempty"`   // Per hook (default: 30s)
+}
+
+// AuthConfig configures the auth-injecting relay for a service
+type AuthConfig struct {
+	Flow            string   `yaml:"flow"`                    // "client_credentials" or "device_code"
+	Issuer          string   `yaml:"issuer,omitempty"`        // OIDC issuer used to discover endpoints
+	TokenURL        string   `yaml:"tokenURL,omitempty"`      // Overrides the discovered token endpoint
+	DeviceAuthURL   string   `yaml:"deviceAuthURL,omitempty"` // Overrides the discovered device authorization endpoint
+	ClientID        string   `yaml:"clientID"`
+	ClientSecret    string   `yaml:"clientSecret,omitempty"`
+	ClientSecretEnv string   `yaml:"clientSecretEnv,omitempty"` // Read the client secret from this environment variable
+	Scopes          []string `yaml:"scopes,omitempty"`
+	Audience        string   `yaml:"audience,omitempty"` // Sent as the audience parameter (Auth0, Okta)
+	Header          string   `yaml:"header,omitempty"`   // Header to inject (default: Authorization)
+}
+
+// SSHConfig configures the bastion an ssh service tunnels through
+type SSHConfig struct {
+	Host         string   `yaml:"host"`
+	Port         int      `yaml:"port,omitempty"`         // Defaults to 22
+	User         string   `yaml:"user,omitempty"`         // Defaults to ~/.ssh/config or the local user
+	IdentityFile string   `yaml:"identityFile,omitempty"` // Defaults to ssh-agent and ~/.ssh/config
+	Jump         []string `yaml:"jump,omitempty"`         // Additional hops before the bastion (ssh -J)
+	Options      []string `yaml:"options,omitempty"`      // Extra ssh -o options, e.g. StrictHostKeyChecking=accept-new
+}
+
+// TeleportConfig configures a tunnel through Teleport (tsh)
+type TeleportConfig struct {
+	Kind    string `yaml:"kind"`              // "db" (tsh proxy db), "app" (tsh proxy app) or "node" (tsh ssh -L)
+	Proxy   string `yaml:"proxy,omitempty"`   // Defaults to the active tsh profile
+	Cluster string `yaml:"cluster,omitempty"` // Leaf cluster
+	Node    string `yaml:"node,omitempty"`    // [login@]node to tunnel through for kind node
+	DBUser  string `yaml:"dbUser,omitempty"`
+	DBName  string `yaml:"dbName,omitempty"`
+}
+
+// CloudSQLConfig configures the Cloud SQL Auth Proxy for a cloudsql service
+type CloudSQLConfig struct {
+	Binary                    string `yaml:"binary,omitempty"`                    // Defaults to cloud-sql-proxy on PATH
+	PrivateIP                 bool   `yaml:"privateIP,omitempty"`                 // Connect over the instance's private IP
+	AutoIAMAuthn              bool   `yaml:"autoIAMAuthn,omitempty"`              // Log in to the database with IAM
+	CredentialsFile           string `yaml:"credentialsFile,omitempty"`           // Defaults to application default credentials
+	ImpersonateServiceAccount string `yaml:"impersonateServiceAccount,omitempty"` // Service account to act as
 }
 
 // UIConfig represents UI-specific configuration options
 type UIConfig struct {
 	RefreshRate time.Duration `yaml:"refreshRate"`
 	Theme       string        `yaml:"theme"`
+	Language    string        `yaml:"language,omitempty"` // Locale of the TUI, e.g. "de" (default: from LC_ALL, LC_MESSAGES or LANG)
+}
+
+// TelemetryConfig configures OpenTelemetry export of lifecycle events
+type TelemetryConfig struct {
+	Enabled     bool   `yaml:"enabled"`
+	Exporter    string `yaml:"exporter,omitempty"` // "otlp" (default) or "stdout"
+	Endpoint    string `yaml:"endpoint,omitempty"` // OTLP/HTTP endpoint, e.g. localhost:4318
+	Insecure    bool   `yaml:"insecure,omitempty"` // Use plain HTTP for the OTLP endpoint
+	ServiceName string `yaml:"serviceName,omitempty"`
+}
+
+// NotificationConfig configures notifications about service state changes
+type NotificationConfig struct {
+	Desktop  bool            `yaml:"desktop"` // Native OS notifications on failure and recovery
+	Webhooks []WebhookConfig `yaml:"webhooks,omitempty"`
+	Command  string          `yaml:"command,omitempty"` // Shell command run on every state change, with a JSON payload on stdin
+}
+
+// WebhookConfig configures a single webhook notification target
+type WebhookConfig struct {
+	URL      string        `yaml:"url"`
+	Format   string        `yaml:"format,omitempty"`   // "json" (default) or "slack"
+	Events   []string      `yaml:"events,omitempty"`   // failure, recovery, context_change (default: all)
+	Debounce time.Duration `yaml:"debounce,omitempty"` // Minimum interval between repeats per service and event
+}
+
+// MDNSConfig configures mDNS/Bonjour advertisement of LAN-exposed services
+type MDNSConfig struct {
+	Enabled bool   `yaml:"enabled"`
+	Domain  string `yaml:"domain,omitempty"` // Defaults to "local"
+}
+
+// HostsConfig configures kubefwd-style hosts file entries for in-cluster service names
+type HostsConfig struct {
+	Enabled bool   `yaml:"enabled"`
+	Path    string `yaml:"path,omitempty"` // Defaults to the platform's hosts file
+}
+
+// CaptureConfig configures recording of HTTP traffic through web and rest forwards
+type CaptureConfig struct {
+	Enabled bool   `yaml:"enabled"`
+	Dir     string `yaml:"dir,omitempty"` // Defaults to ./kportforward-captures
+}
+
+// ClusterEventsConfig configures watching the pods behind forwarded services
+type ClusterEventsConfig struct {
+	Enabled  bool          `yaml:"enabled"`
+	Interval time.Duration `yaml:"interval,omitempty"` // Defaults to 15s
+}
+
+// BundleRef records the pinned version of an added bundle and the services it contributed
+type BundleRef struct {
+	Version  string   `yaml:"version"`
+	Services []string `yaml:"services"`
+}
+
+// AvailabilityConfig controls how the availability of services is tracked
+type AvailabilityConfig struct {
+	Persist bool `yaml:"persist"` // Keep availability across sessions, in the user cache directory
+}
+
+// AgentConfig routes forwards to Kubernetes Services through a single forward to an
+// in-cluster agent instead of one kubectl process each
+type AgentConfig struct {
+	Enabled   bool   `yaml:"enabled"`
+	Namespace string `yaml:"namespace,omitempty"` // Defaults to kportforward
+	Target    string `yaml:"target,omitempty"`    // Defaults to deployment/kportforward-agent
+	Port      int    `yaml:"port,omitempty"`      // Agent's port (default: 7000)
+	Token     string `yaml:"token,omitempty"`     // Shared with the agent; or tokenEnv
+	TokenEnv  string `yaml:"tokenEnv,omitempty"`
+}
+
+// SyncConfig points at a git repository holding the team's shared config
+type SyncConfig struct {
+	Repo   string `yaml:"repo"`             // Anything git clone accepts
+	Branch string `yaml:"branch,omitempty"` // Defaults to the repository's default branch
+	Path   string `yaml:"path,omitempty"`   // Config file within the repository (default: kportforward.yaml)
+}
+
+// UpdatesConfig selects where the updater looks for new releases
+type UpdatesConfig struct {
+	Disabled bool   `yaml:"disabled,omitempty"` // No update checks at all, e.g. in air-gapped environments
+	Provider string `yaml:"provider,omitempty"` // "github" (default), "gitlab" or "gitea"
+	URL      string `yaml:"url,omitempty"`      // Instance URL, e.g. https://gitlab.example.com
+	Repo     string `yaml:"repo,omitempty"`     // "owner/name", or a GitLab project path or ID
+	Token    string `yaml:"token,omitempty"`
+	TokenEnv string `yaml:"tokenEnv,omitempty"` // Read the access token from this environment variable
+	Channel  string `yaml:"channel,omitempty"`  // "stable" (default), "beta" for prereleases or "nightly" for nightly builds
+	Proxy    string `yaml:"proxy,omitempty"`    // Proxy URL for checks and downloads (default: HTTPS_PROXY from the environment)
+	CABundle string `yaml:"caBundle,omitempty"` // PEM file of extra CAs to trust, e.g. a TLS-intercepting corporate proxy's
+
+	// Downloads are checked against the release's SHA-256 checksums file
+	Signature       string `yaml:"signature,omitempty"`       // "cosign" or "minisign": also verify the signature of the checksums file
+	PublicKey       string `yaml:"publicKey,omitempty"`       // Key the checksums are signed with, or a file holding it
+	AllowUnverified bool   `yaml:"allowUnverified,omitempty"` // Install releases that publish no checksums file
+}
+
+// StartupConfig controls how services are started when kportforward launches
+type StartupConfig struct {
+	Parallelism int           `yaml:"parallelism,omitempty"` // Services started concurrently (default: 8)
+	Stagger     time.Duration `yaml:"stagger,omitempty"`     // Delay between starting consecutive services (default: 50ms)
 }
 
 // ServiceStatus represents the runtime status of a service
 type ServiceStatus struct {
-	Name          string
-	Status        string
-	LocalPort     int // Actual port being used (may differ from config if reassigned)
-	PID           int // Process ID of kubectl port-forward
-	StartTime     time.Time
-	RestartCount  int
-	LastError     string
-	InCooldown    bool
-	CooldownUntil time.Time
+	Name            string
+	Namespace       string
+	Target          string // e.g. service/api, or the host/instance for non-kubectl services
+	Type            string // rpc, web, rest, ssh, teleport, cloudsql or other
+	TypeDetected    bool   // Type was inferred by probing the forward, as the configuration has none
+	Status          string
+	ConfiguredPort  int // Local port from the configuration; 0 asks for any free port
+	LocalPort       int // Actual port being used (may differ from config if reassigned)
+	PID             int // Process ID of kubectl port-forward
+	StartTime       time.Time
+	RestartCount    int
+	LastError       string
+	ErrorCategory   ErrorCategory // Kind of the last failure, when it could be recognized
+	InCooldown      bool
+	CooldownUntil   time.Time
+	Latency         time.Duration    // Round-trip time of the latest successful health check (0 until measured)
+	LatencyP50      time.Duration    // Rolling median over recent health checks
+	LatencyP95      time.Duration    // Rolling 95th percentile over recent health checks
+	RecentAccess    []AccessLogEntry // Latest connections seen by the relay when accessLog is enabled, oldest first
+	RecentErrors    []ErrorEntry     // Latest errors of the service, oldest first
+	Context         string           // Kubeconfig context the forward runs in (empty for non-kubectl services)
+	Cluster         string           // Cluster of that context
+	HealthCheckTime time.Duration    // How long the latest passing health check took
+	Availability    Availability     // Time the service was up and down, with its recent health timeline
+	Wildcard        string           // Wildcard entry the service was expanded from
+	Ports           []PortStatus     // Further ports of the service, in configuration order
+	Generation      uint64           // Increases, across all services, whenever the status changes; a lower one is older
+}
+
+// PortStatus is the state of one of a service's further ports
+type PortStatus struct {
+	Name       string
+	LocalPort  int // Actual port being used
+	TargetPort int
+	Healthy    bool   // Accepted a connection at the latest health check
+	LastError  string // Latest failure of the port: its health check, or kubectl failing to forward a connection
+}
+
+// StatusUpdate is a change to the status of the services: those added or changed
+// since the previous update, and those removed
+type StatusUpdate struct {
+	Version uint64                   // Increases with every update
+	Changed map[string]ServiceStatus // Shared between receivers; must not be modified
+	Removed []string
+	Full    bool // Changed holds every service and replaces the previous state
+}
+
+// Apply applies the update to the receiver's own copy of the statuses (nil before the
+// first update) and returns it
+func (u StatusUpdate) Apply(statuses map[string]ServiceStatus) map[string]ServiceStatus {
+	if u.Full || statuses == nil {
+		statuses = make(map[string]ServiceStatus, len(u.Changed))
+	}
+	for name, status := range u.Changed {
+		statuses[name] = status
+	}
+	for _, name := range u.Removed {
+		delete(statuses, name)
+	}
+	return statuses
+}
+
+// Availability is how long a service was up and down while it was tracked, and a
+// timeline of its recent health
+type Availability struct {
+	Up       time.Duration `json:"up"`
+	Down     time.Duration `json:"down"`
+	Timeline []HealthSlot  `json:"timeline,omitempty"` // One slot per minute, oldest first
+}
+
+// Percent returns the share of the tracked time the service was up, or false while
+// no time was tracked
+func (a Availability) Percent() (float64, bool) {
+	total := a.Up + a.Down
+	if total == 0 {
+		return 0, false
+	}
+	return float64(a.Up) / float64(total) * 100, true
+}
+
+// String formats the percentage the service was up, e.g. "99.5%", or "" while no time was tracked
+func (a Availability) String() string {
+	percent, ok := a.Percent()
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf("%.1f%%", percent)
+}
+
+// Health states of a timeline slot
+const (
+	HealthUnknown  = ""         // Not tracked, e.g. stopped or suspended
+	HealthUp       = "up"       // Running throughout
+	HealthDown     = "down"     // Never running
+	HealthDegraded = "degraded" // Running part of the time
+)
+
+// HealthSlot is how long a service was up and down within one slot of the timeline
+type HealthSlot struct {
+	Start time.Time     `json:"start"`
+	Up    time.Duration `json:"up,omitempty"`
+	Down  time.Duration `json:"down,omitempty"`
+}
+
+// State summarizes the slot as one of the health states
+func (s HealthSlot) State() string {
+	switch {
+	case s.Up == 0 && s.Down == 0:
+		return HealthUnknown
+	case s.Down == 0:
+		return HealthUp
+	case s.Up == 0:
+		return HealthDown
+	}
+	return HealthDegraded
+}
+
+// ErrorEntry is an error a service ran into
+type ErrorEntry struct {
+	Time    time.Time
+	Message string
+}
+
+// ErrorCategory is the recognized kind of a forward's failure
+type ErrorCategory string
+
+// Error categories recognized in kubectl's output and preflight errors
+const (
+	ErrorLostConnection     ErrorCategory = "lost-connection"     // The pod went away, e.g. restarted or rescheduled
+	ErrorConnectionRefused  ErrorCategory = "connection-refused"  // Nothing listens on the target port in the pod
+	ErrorPortInUse          ErrorCategory = "port-in-use"         // The local port is taken
+	ErrorClusterUnreachable ErrorCategory = "cluster-unreachable" // The API server can't be reached
+	ErrorUnauthorized       ErrorCategory = "unauthorized"        // Credentials are missing or expired
+	ErrorForbidden          ErrorCategory = "forbidden"           // RBAC denies the port-forward
+	ErrorNamespaceNotFound  ErrorCategory = "namespace-not-found" // The namespace doesn't exist in the context
+	ErrorNotFound           ErrorCategory = "not-found"           // The pod, service or deployment doesn't exist
+	ErrorUpgradeFailed      ErrorCategory = "upgrade-failed"      // The API server couldn't open the stream to the pod, e.g. its node is unreachable
+)
+
+// Fatal reports whether the failure needs the user to fix something; other
+// failures are expected to go away on their own with a restart
+func (c ErrorCategory) Fatal() bool {
+	switch c {
+	case ErrorUnauthorized, ErrorForbidden, ErrorNamespaceNotFound, ErrorNotFound:
+		return true
+	}
+	return false
+}
+
+// OutputLine is a line a service's forward process wrote to stdout or stderr
+type OutputLine struct {
+	Time time.Time
+	Text string
+}
+
+// AccessLogEntry is a connection or HTTP request that reached a service's relay
+type AccessLogEntry struct {
+	Time     time.Time
+	Peer     string        // Client address
+	Request  string        // HTTP method, path and protocol; empty for raw connections
+	Status   int           // HTTP response status
+	Duration time.Duration // Time to serve the HTTP request
+}
+
+// String formats the entry as an access log line
+func (e AccessLogEntry) String() string {
+	if e.Request == "" {
+		return fmt.Sprintf("%s %s connected", e.Time.Format("15:04:05"), e.Peer)
+	}
+	return fmt.Sprintf("%s %s \"%s\" %d %s", e.Time.Format("15:04:05"), e.Peer, e.Request, e.Status,
+		e.Duration.Round(time.Millisecond))
 }
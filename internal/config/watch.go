@@ -0,0 +1,108 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// watchDebounce is how long the config file must stay unchanged before a change is
+// reported, as editors often write a file in several steps
+const watchDebounce = 300 * time.Millisecond
+
+// Watcher reports changes to a config file
+type Watcher struct {
+	path     string
+	watcher  *fsnotify.Watcher
+	onChange func()
+	onError  func(error)
+
+	mutex sync.Mutex
+	timer *time.Timer
+	done  chan struct{}
+}
+
+// WatchUserConfig calls onChange when the user config file is written or replaced;
+// errors of the underlying watch go to onError
+func WatchUserConfig(onChange func(), onError func(error)) (*Watcher, error) {
+	path, err := getUserConfigPath()
+	if err != nil {
+		return nil, err
+	}
+	return WatchFile(path, onChange, onError)
+}
+
+// WatchFile calls onChange when the file at path is written or replaced. Its directory
+// is watched, so saves that replace the file (as many editors do) and a file created
+// later are noticed too.
+func WatchFile(path string, onChange func(), onError func(error)) (*Watcher, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("failed to watch config: %w", err)
+	}
+	if err := watcher.Add(filepath.Dir(path)); err != nil {
+		watcher.Close()
+		return nil, fmt.Errorf("failed to watch %s: %w", filepath.Dir(path), err)
+	}
+
+	w := &Watcher{
+		path:     filepath.Clean(path),
+		watcher:  watcher,
+		onChange: onChange,
+		onError:  onError,
+		done:     make(chan struct{}),
+	}
+	go w.run()
+	return w, nil
+}
+
+// run passes the file's events on until the watcher is closed
+func (w *Watcher) run() {
+	for {
+		select {
+		case event, ok := <-w.watcher.Events:
+			if !ok {
+				return
+			}
+			// A removed file is left alone: falling back to the defaults would stop
+			// every service while an editor swaps the file
+			if filepath.Clean(event.Name) == w.path && event.Has(fsnotify.Write|fsnotify.Create) {
+				w.schedule()
+			}
+		case err, ok := <-w.watcher.Errors:
+			if !ok {
+				return
+			}
+			w.onError(err)
+		}
+	}
+}
+
+// schedule reports a change once the file has been quiet for watchDebounce
+func (w *Watcher) schedule() {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	select {
+	case <-w.done:
+		return
+	default:
+	}
+	if w.timer != nil {
+		w.timer.Stop()
+	}
+	w.timer = time.AfterFunc(watchDebounce, w.onChange)
+}
+
+// Close stops watching
+func (w *Watcher) Close() error {
+	w.mutex.Lock()
+	close(w.done)
+	if w.timer != nil {
+		w.timer.Stop()
+	}
+	w.mutex.Unlock()
+	return w.watcher.Close()
+}
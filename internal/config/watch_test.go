@@ -0,0 +1,57 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWatchFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(path, []byte("portForwards: {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var changes atomic.Int32
+	changed := make(chan struct{}, 10)
+	watcher, err := WatchFile(path, func() {
+		changes.Add(1)
+		changed <- struct{}{}
+	}, func(err error) { t.Errorf("Watch error: %v", err) })
+	if err != nil {
+		t.Fatalf("WatchFile failed: %v", err)
+	}
+	defer watcher.Close()
+
+	// Other files in the directory are ignored
+	if err := os.WriteFile(filepath.Join(dir, "other.yaml"), []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// A burst of writes, and a save that replaces the file, is reported once
+	for i := 0; i < 3; i++ {
+		if err := os.WriteFile(path, []byte("portForwards: {}\n# edit\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	replacement := filepath.Join(dir, "config.yaml.tmp")
+	if err := os.WriteFile(replacement, []byte("portForwards: {}\n# saved\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(replacement, path); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-changed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the change to be reported")
+	}
+	time.Sleep(2 * watchDebounce)
+	if n := changes.Load(); n != 1 {
+		t.Errorf("Expected one change for the burst, got %d", n)
+	}
+}
@@ -0,0 +1,171 @@
+package daemon
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/httpapi"
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+const (
+	// socketName and logName are the control socket and the log of the daemon, in
+	// the user config directory
+	socketName = "daemon.sock"
+	logName    = "daemon.log"
+
+	// dialTimeout bounds how long checking for a running daemon waits
+	dialTimeout = 2 * time.Second
+)
+
+// ErrNotRunning is returned when no daemon answers on the control socket
+var ErrNotRunning = errors.New("no kportforward daemon is running")
+
+// Info describes a running daemon; it is the JSON body of /daemon
+type Info struct {
+	PID     int       `json:"pid"`
+	Started time.Time `json:"started"`
+	Version string    `json:"version,omitempty"`
+}
+
+// SocketPath returns the path of the daemon's control socket
+func SocketPath() (string, error) {
+	dir, err := config.GetUserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, socketName), nil
+}
+
+// LogPath returns the path of the file the daemon logs to
+func LogPath() (string, error) {
+	dir, err := config.GetUserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, logName), nil
+}
+
+// Server serves the control API of the daemon on its unix socket: the HTTP API's
+// routes, /daemon and POST /stop
+type Server struct {
+	path     string
+	listener net.Listener
+	server   *http.Server
+	logger   *utils.Logger
+}
+
+// Listen serves api on the unix socket at path, which only the user can connect to.
+// It fails when another daemon answers there; a socket left behind by one that died
+// is replaced. POST /stop calls stop.
+func Listen(path string, api http.Handler, info Info, stop func(), logger *utils.Logger) (*Server, error) {
+	if _, err := NewClient(path).Info(context.Background()); err == nil {
+		return nil, fmt.Errorf("a kportforward daemon is already running (socket %s)", path)
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to remove stale socket %s: %w", path, err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create socket directory: %w", err)
+	}
+
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", path, err)
+	}
+	if err := os.Chmod(path, 0600); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("failed to restrict socket %s: %w", path, err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/", api)
+	mux.HandleFunc("/daemon", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(info)
+	})
+	mux.HandleFunc("/stop", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		logger.Info("Stop requested through the control socket")
+		w.WriteHeader(http.StatusAccepted)
+		go stop() // Answer before shutting down closes the socket
+	})
+
+	s := &Server{
+		path:     path,
+		listener: listener,
+		server:   &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second},
+		logger:   logger,
+	}
+	go func() {
+		if err := s.server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			logger.Error("Control socket error: %v", err)
+		}
+	}()
+	logger.Info("Control socket listening at %s", path)
+	return s, nil
+}
+
+// Stop closes the control socket and removes it
+func (s *Server) Stop() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err := s.server.Shutdown(ctx)
+	os.Remove(s.path) // Closing the listener usually removes it already
+	return err
+}
+
+// Client controls a running daemon through its control socket
+type Client struct {
+	*httpapi.Client
+}
+
+// NewClient creates a client for the daemon listening on the socket at path
+func NewClient(path string) *Client {
+	return &Client{Client: httpapi.NewSocketClient(path)}
+}
+
+// Info describes the running daemon, or returns ErrNotRunning
+func (c *Client) Info(ctx context.Context) (Info, error) {
+	var info Info
+	ctx, cancel := context.WithTimeout(ctx, dialTimeout)
+	defer cancel()
+	if err := c.Do(ctx, http.MethodGet, "/daemon", &info); err != nil {
+		var netErr *net.OpError
+		if errors.As(err, &netErr) && netErr.Op == "dial" {
+			return info, ErrNotRunning
+		}
+		return info, err
+	}
+	return info, nil
+}
+
+// Stop asks the daemon to shut down and waits until it has, up to timeout
+func (c *Client) Stop(ctx context.Context, timeout time.Duration) error {
+	if err := c.Do(ctx, http.MethodPost, "/stop", nil); err != nil {
+		return err
+	}
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if _, err := c.Info(ctx); errors.Is(err, ErrNotRunning) {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(200 * time.Millisecond):
+		}
+	}
+	return fmt.Errorf("the daemon didn't stop within %s", timeout)
+}
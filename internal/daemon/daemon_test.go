@@ -0,0 +1,71 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+func TestListenAndControl(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "daemon.sock")
+	client := NewClient(path)
+	if _, err := client.Info(context.Background()); !errors.Is(err, ErrNotRunning) {
+		t.Fatalf("Expected ErrNotRunning without a daemon, got %v", err)
+	}
+
+	// A socket left behind by a daemon that died is replaced
+	stale, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatalf("Failed to create a stale socket: %v", err)
+	}
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	stale.Close()
+
+	api := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"context":"dev","services":{"web":{"status":"Running","localPort":8080}}}`))
+	})
+	stopped := make(chan struct{})
+	var server *Server
+	server, err = Listen(path, api, Info{PID: 42, Version: "1.2.3"}, func() {
+		server.Stop()
+		close(stopped)
+	}, utils.NewLogger(utils.LevelError))
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+
+	if info, err := client.Info(context.Background()); err != nil || info.PID != 42 || info.Version != "1.2.3" {
+		t.Errorf("Unexpected info %+v, %v", info, err)
+	}
+	if stat, err := os.Stat(path); err != nil || stat.Mode().Perm() != 0600 {
+		t.Errorf("Expected the socket to be private to the user, got %v, %v", stat, err)
+	}
+	doc, err := client.Services(context.Background())
+	if err != nil || doc.Services["web"].Status != "Running" {
+		t.Errorf("Expected the API's routes on the socket, got %+v, %v", doc, err)
+	}
+
+	if _, err := Listen(path, api, Info{}, func() {}, utils.NewLogger(utils.LevelError)); err == nil {
+		t.Error("Expected a second daemon to be refused")
+	}
+
+	if err := client.Stop(context.Background(), 5*time.Second); err != nil {
+		t.Fatalf("Stop failed: %v", err)
+	}
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("Expected the stop callback to run")
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("Expected the socket to be removed, got %v", err)
+	}
+}
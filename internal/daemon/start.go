@@ -0,0 +1,54 @@
+package daemon
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+)
+
+// Start runs `kportforward start -- args` as a background process detached from the
+// terminal, appending its output to the log at logPath. It returns once the daemon
+// answers on the socket at path, or fails when it exits or doesn't answer within timeout.
+func Start(path, logPath string, args []string, timeout time.Duration) (Info, error) {
+	executable, err := os.Executable()
+	if err != nil {
+		return Info{}, fmt.Errorf("failed to locate the kportforward executable: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(logPath), 0755); err != nil {
+		return Info{}, fmt.Errorf("failed to create log directory: %w", err)
+	}
+	logFile, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return Info{}, fmt.Errorf("failed to open daemon log: %w", err)
+	}
+	defer logFile.Close() // The daemon keeps its own handle
+
+	cmd := exec.Command(executable, append([]string{"start", "--"}, args...)...)
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+	cmd.SysProcAttr = detachAttr()
+	if err := cmd.Start(); err != nil {
+		return Info{}, fmt.Errorf("failed to start the daemon: %w", err)
+	}
+
+	exited := make(chan error, 1)
+	go func() { exited <- cmd.Wait() }()
+
+	client := NewClient(path)
+	deadline := time.After(timeout)
+	for {
+		if info, err := client.Info(context.Background()); err == nil && info.PID == cmd.Process.Pid {
+			return info, nil
+		}
+		select {
+		case err := <-exited:
+			return Info{}, fmt.Errorf("the daemon exited during startup (%v); see %s", err, logPath)
+		case <-deadline:
+			return Info{}, fmt.Errorf("the daemon didn't answer within %s; see %s", timeout, logPath)
+		case <-time.After(200 * time.Millisecond):
+		}
+	}
+}
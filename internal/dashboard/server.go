@@ -0,0 +1,394 @@
+package dashboard
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/portforward"
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+// Embed the dashboard page
+//
+//go:embed dashboard.html
+var dashboardHTML []byte
+
+// Controller is the subset of the port-forward manager used by the dashboard
+type Controller interface {
+	GetCurrentStatus() map[string]config.ServiceStatus
+	GetKubernetesContext() string
+	RestartService(name string) error
+	Subscribe() (<-chan config.StatusUpdate, func())
+	ServiceConfigs() map[string]config.Service
+}
+
+// URLProvider exposes the URL of a UI attached to a service (gRPC UI, Swagger UI)
+type URLProvider interface {
+	GetServiceURL(serviceName string) string
+}
+
+// ServiceView is the JSON representation of a service row
+type ServiceView struct {
+	Name           string     `json:"name"`
+	Status         string     `json:"status"`
+	Type           string     `json:"type"`
+	Namespace      string     `json:"namespace,omitempty"`
+	Target         string     `json:"target,omitempty"`
+	LocalPort      int        `json:"localPort"`
+	ConfiguredPort int        `json:"configuredPort"`
+	URL            string     `json:"url,omitempty"`
+	UIURL          string     `json:"uiUrl,omitempty"`
+	PID            int        `json:"pid"`
+	Uptime         string     `json:"uptime"`
+	StartedAt      *time.Time `json:"startedAt,omitempty"` // Lets stream clients keep the uptime current
+	RestartCount   int        `json:"restartCount"`
+	LastError      string     `json:"lastError,omitempty"`
+	ErrorCategory  string     `json:"errorCategory,omitempty"`
+	LatencyP50Ms   float64    `json:"latencyP50Ms,omitempty"`
+	LatencyP95Ms   float64    `json:"latencyP95Ms,omitempty"`
+	Context        string     `json:"context,omitempty"`
+	Cluster        string     `json:"cluster,omitempty"`
+}
+
+// Snapshot is the JSON document pushed to dashboard clients
+type Snapshot struct {
+	Context   string        `json:"context"`
+	Services  []ServiceView `json:"services"`
+	UpdatedAt time.Time     `json:"updatedAt"`
+}
+
+// Server serves the web dashboard and its JSON/SSE endpoints
+type Server struct {
+	addr       string
+	bindHost   string // Host part of addr, accepted in Host headers besides loopback
+	controller Controller
+	providers  []URLProvider
+	logger     *utils.Logger
+	mux        *http.ServeMux
+	server     *http.Server
+
+	// Optional gRPC handler sharing the listener (see SetGRPCHandler)
+	grpcHandler http.Handler
+
+	// Connected SSE clients, each with a single-slot buffer holding the latest snapshot,
+	// and status stream clients receiving events
+	clients     map[chan Snapshot]struct{}
+	streams     map[chan EventView]struct{}
+	clientMutex sync.Mutex
+	done        chan struct{}
+}
+
+// NewServer creates a dashboard server listening on addr
+func NewServer(addr string, controller Controller, logger *utils.Logger) *Server {
+	bindHost, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		bindHost = addr
+	}
+
+	s := &Server{
+		addr:       addr,
+		bindHost:   bindHost,
+		controller: controller,
+		logger:     logger,
+		clients:    make(map[chan Snapshot]struct{}),
+		streams:    make(map[chan EventView]struct{}),
+		done:       make(chan struct{}),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", s.handleIndex)
+	mux.HandleFunc("/api/services", s.handleServices)
+	mux.HandleFunc("/api/services/", s.handleServiceAction)
+	mux.HandleFunc("/api/events", s.handleEvents)
+	mux.HandleFunc("/ws/status", s.handleStatusStream)
+
+	s.mux = mux
+
+	// h2c lets gRPC clients speak cleartext HTTP/2 on the same port as the dashboard
+	s.server = &http.Server{
+		Addr:              addr,
+		Handler:           h2c.NewHandler(http.HandlerFunc(s.serveHTTP), &http2.Server{}),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	return s
+}
+
+// AddURLProvider registers a UI manager whose URLs are linked from the dashboard
+func (s *Server) AddURLProvider(provider URLProvider) {
+	s.providers = append(s.providers, provider)
+}
+
+// SetGRPCHandler serves gRPC requests on the dashboard listener; must be called before Start
+func (s *Server) SetGRPCHandler(handler http.Handler) {
+	s.grpcHandler = handler
+}
+
+// serveHTTP routes gRPC requests to the gRPC handler and everything else to the dashboard.
+// Requests for host names other than localhost and the listen address are refused,
+// so a page whose domain was rebound to 127.0.0.1 can't pass the origin checks.
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if !s.allowedHost(r.Host) {
+		http.Error(w, fmt.Sprintf("host %s not allowed", r.Host), http.StatusForbidden)
+		return
+	}
+	if s.grpcHandler != nil && r.ProtoMajor == 2 && strings.HasPrefix(r.Header.Get("Content-Type"), "application/grpc") {
+		s.grpcHandler.ServeHTTP(w, r)
+		return
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+// allowedHost reports whether a Host header names this machine: localhost, a loopback
+// address, or the address the dashboard listens on. Any IP address is accepted when
+// it listens on all interfaces, since rebinding needs a host name.
+func (s *Server) allowedHost(host string) bool {
+	if name, _, err := net.SplitHostPort(host); err == nil {
+		host = name
+	}
+	host = strings.TrimSuffix(strings.Trim(host, "[]"), ".")
+
+	if strings.EqualFold(host, "localhost") || strings.EqualFold(host, s.bindHost) {
+		return true
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+	bindIP := net.ParseIP(s.bindHost)
+	return ip.IsLoopback() || ip.Equal(bindIP) || s.bindHost == "" || (bindIP != nil && bindIP.IsUnspecified())
+}
+
+// Start begins serving the dashboard in the background
+func (s *Server) Start() error {
+	listener, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", s.addr, err)
+	}
+
+	go func() {
+		if err := s.server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			s.logger.Error("Dashboard server error: %v", err)
+		}
+	}()
+
+	s.logger.Info("Web dashboard available at http://%s", listener.Addr())
+	return nil
+}
+
+// Stop shuts down the dashboard server
+func (s *Server) Stop() error {
+	// End open event streams; Shutdown does not cancel in-flight requests
+	close(s.done)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return s.server.Shutdown(ctx)
+}
+
+// HandleEvent pushes status snapshots from the manager to connected clients, and
+// other events to status stream clients
+func (s *Server) HandleEvent(event portforward.Event) {
+	if event.Type != portforward.EventStatusUpdated {
+		s.publishEvent(event)
+		return
+	}
+
+	s.clientMutex.Lock()
+	defer s.clientMutex.Unlock()
+
+	if len(s.clients) == 0 {
+		return
+	}
+	snapshot := s.buildSnapshot(event.Snapshot)
+
+	for client := range s.clients {
+		// Replace any snapshot the client hasn't consumed yet
+		select {
+		case <-client:
+		default:
+		}
+		client <- snapshot
+	}
+}
+
+// buildSnapshot converts a status map into the dashboard's JSON model
+func (s *Server) buildSnapshot(statuses map[string]config.ServiceStatus) Snapshot {
+	snapshot := Snapshot{
+		Context:   s.controller.GetKubernetesContext(),
+		Services:  make([]ServiceView, 0, len(statuses)),
+		UpdatedAt: time.Now(),
+	}
+
+	configs := s.controller.ServiceConfigs()
+	for name, status := range statuses {
+		snapshot.Services = append(snapshot.Services, s.serviceView(name, status, configs[name]))
+	}
+
+	sort.Slice(snapshot.Services, func(i, j int) bool {
+		return snapshot.Services[i].Name < snapshot.Services[j].Name
+	})
+
+	return snapshot
+}
+
+// serviceView converts a service's status and configuration into its JSON row
+func (s *Server) serviceView(name string, status config.ServiceStatus, serviceConfig config.Service) ServiceView {
+	view := ServiceView{
+		Name:           name,
+		Status:         status.Status,
+		Type:           status.Type,
+		Namespace:      status.Namespace,
+		Target:         status.Target,
+		LocalPort:      status.LocalPort,
+		ConfiguredPort: status.ConfiguredPort,
+		PID:            status.PID,
+		RestartCount:   status.RestartCount,
+		LastError:      status.LastError,
+		ErrorCategory:  string(status.ErrorCategory),
+		LatencyP50Ms:   utils.Milliseconds(status.LatencyP50),
+		LatencyP95Ms:   utils.Milliseconds(status.LatencyP95),
+		Context:        status.Context,
+		Cluster:        status.Cluster,
+	}
+
+	if status.Status == "Running" {
+		view.URL = serviceConfig.LocalURL(status.LocalPort)
+	}
+	if !status.StartTime.IsZero() {
+		startedAt := status.StartTime
+		view.Uptime = utils.FormatUptime(time.Since(startedAt))
+		view.StartedAt = &startedAt
+	}
+	for _, provider := range s.providers {
+		if url := provider.GetServiceURL(name); url != "" {
+			view.UIURL = url
+			break
+		}
+	}
+	return view
+}
+
+// handleIndex serves the dashboard page
+func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(dashboardHTML)
+}
+
+// handleServices returns the current snapshot as JSON
+func (s *Server) handleServices(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	writeJSON(w, http.StatusOK, s.buildSnapshot(s.controller.GetCurrentStatus()))
+}
+
+// handleServiceAction handles POST /api/services/{name}/restart. Browsers send any web
+// page's POST here, so only the dashboard's own pages and clients without an Origin may.
+func (s *Server) handleServiceAction(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if _, err := allowedOrigin(r); err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/api/services/"), "/")
+	if len(parts) != 2 || parts[0] == "" {
+		http.NotFound(w, r)
+		return
+	}
+	name, action := parts[0], parts[1]
+
+	switch action {
+	case "restart":
+		if err := s.controller.RestartService(name); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		s.logger.Info("Restarted %s from web dashboard", name)
+		writeJSON(w, http.StatusOK, map[string]string{"status": "restarted"})
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// handleEvents streams status snapshots using server-sent events
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	client := make(chan Snapshot, 1)
+	s.clientMutex.Lock()
+	s.clients[client] = struct{}{}
+	s.clientMutex.Unlock()
+
+	defer func() {
+		s.clientMutex.Lock()
+		delete(s.clients, client)
+		s.clientMutex.Unlock()
+	}()
+
+	// Send the current state immediately so the page renders without waiting a tick
+	if err := writeEvent(w, s.buildSnapshot(s.controller.GetCurrentStatus())); err != nil {
+		return
+	}
+	flusher.Flush()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-s.done:
+			return
+		case snapshot := <-client:
+			if err := writeEvent(w, snapshot); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// writeEvent writes a single SSE data frame
+func writeEvent(w http.ResponseWriter, snapshot Snapshot) error {
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "data: %s\n\n", data)
+	return err
+}
+
+// writeJSON writes a JSON response with the given status code
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(value)
+}
@@ -0,0 +1,306 @@
+package dashboard
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/websocket"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/portforward"
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+type fakeController struct {
+	statuses  map[string]config.ServiceStatus
+	configs   map[string]config.Service
+	restarted []string
+	updates   chan config.StatusUpdate
+}
+
+func (f *fakeController) GetCurrentStatus() map[string]config.ServiceStatus {
+	return f.statuses
+}
+
+func (f *fakeController) GetKubernetesContext() string {
+	return "test-context"
+}
+
+func (f *fakeController) RestartService(name string) error {
+	if _, exists := f.statuses[name]; !exists {
+		return fmt.Errorf("service %s not found", name)
+	}
+	f.restarted = append(f.restarted, name)
+	return nil
+}
+
+func (f *fakeController) ServiceConfigs() map[string]config.Service {
+	return f.configs
+}
+
+// Subscribe starts with the full status, like the manager
+func (f *fakeController) Subscribe() (<-chan config.StatusUpdate, func()) {
+	f.updates <- config.StatusUpdate{Version: 1, Changed: f.statuses, Full: true}
+	return f.updates, func() {}
+}
+
+func newTestServer() (*Server, *fakeController) {
+	controller := &fakeController{
+		statuses: map[string]config.ServiceStatus{
+			"web":     {Name: "web", Type: "web", Status: "Running", LocalPort: 8080},
+			"backend": {Name: "backend", Type: "rpc", Status: "Failed", LocalPort: 50051, LastError: "Health check failed"},
+		},
+		configs: map[string]config.Service{
+			"web":     {Type: "web"},
+			"backend": {Type: "rpc"},
+		},
+		updates: make(chan config.StatusUpdate, 4),
+	}
+	return NewServer("localhost:0", controller, utils.NewLogger(utils.LevelError)), controller
+}
+
+// newRequest builds a request for the dashboard on localhost
+func newRequest(method, target string, body io.Reader) *http.Request {
+	request := httptest.NewRequest(method, target, body)
+	request.Host = "localhost:7080"
+	return request
+}
+
+func TestHandleServices(t *testing.T) {
+	server, controller := newTestServer()
+
+	recorder := httptest.NewRecorder()
+	server.server.Handler.ServeHTTP(recorder, newRequest(http.MethodGet, "/api/services", nil))
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", recorder.Code)
+	}
+
+	var snapshot Snapshot
+	if err := json.Unmarshal(recorder.Body.Bytes(), &snapshot); err != nil {
+		t.Fatalf("Failed to decode snapshot: %v", err)
+	}
+
+	if snapshot.Context != "test-context" {
+		t.Errorf("Unexpected context: %s", snapshot.Context)
+	}
+	if len(snapshot.Services) != 2 || snapshot.Services[0].Name != "backend" {
+		t.Fatalf("Expected services sorted by name, got %+v", snapshot.Services)
+	}
+	if snapshot.Services[0].URL != "" {
+		t.Error("Failed service should not expose a URL")
+	}
+	if snapshot.Services[1].URL != "http://localhost:8080" || snapshot.Services[1].Type != "web" {
+		t.Errorf("Unexpected web service view: %+v", snapshot.Services[1])
+	}
+
+	// Configs edited since startup are picked up
+	controller.configs["web"] = config.Service{Type: "web", HostAlias: "web.test"}
+	if url := server.buildSnapshot(controller.statuses).Services[1].URL; url != "http://web.test:8080" {
+		t.Errorf("Expected the edited host alias in the URL, got %s", url)
+	}
+}
+
+func TestHandleRestart(t *testing.T) {
+	server, controller := newTestServer()
+
+	recorder := httptest.NewRecorder()
+	server.server.Handler.ServeHTTP(recorder, newRequest(http.MethodPost, "/api/services/backend/restart", nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", recorder.Code)
+	}
+	if len(controller.restarted) != 1 || controller.restarted[0] != "backend" {
+		t.Errorf("Expected backend to be restarted, got %v", controller.restarted)
+	}
+
+	recorder = httptest.NewRecorder()
+	server.server.Handler.ServeHTTP(recorder, newRequest(http.MethodPost, "/api/services/missing/restart", nil))
+	if recorder.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for unknown service, got %d", recorder.Code)
+	}
+
+	recorder = httptest.NewRecorder()
+	server.server.Handler.ServeHTTP(recorder, newRequest(http.MethodGet, "/api/services/backend/restart", nil))
+	if recorder.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected 405 for GET restart, got %d", recorder.Code)
+	}
+
+	// Other web pages can't restart services; the dashboard's own page can
+	for origin, code := range map[string]int{"https://evil.example": http.StatusForbidden, "http://localhost:7080": http.StatusOK} {
+		request := newRequest(http.MethodPost, "/api/services/backend/restart", nil)
+		request.Header.Set("Origin", origin)
+		recorder = httptest.NewRecorder()
+		server.server.Handler.ServeHTTP(recorder, request)
+		if recorder.Code != code {
+			t.Errorf("Expected %d for a restart from %s, got %d", code, origin, recorder.Code)
+		}
+	}
+	if len(controller.restarted) != 2 {
+		t.Errorf("Expected only the dashboard's own page to restart backend, got %v", controller.restarted)
+	}
+}
+
+func TestGRPCRequestsRouted(t *testing.T) {
+	server, _ := newTestServer()
+
+	var grpcHits int
+	server.SetGRPCHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		grpcHits++
+	}))
+
+	grpcRequest := newRequest(http.MethodPost, "/kportforward.admin.v1.AdminService/ListServices", nil)
+	grpcRequest.ProtoMajor = 2
+	grpcRequest.Header.Set("Content-Type", "application/grpc")
+	server.server.Handler.ServeHTTP(httptest.NewRecorder(), grpcRequest)
+
+	recorder := httptest.NewRecorder()
+	server.server.Handler.ServeHTTP(recorder, newRequest(http.MethodGet, "/api/services", nil))
+
+	if grpcHits != 1 {
+		t.Errorf("Expected 1 gRPC request, got %d", grpcHits)
+	}
+	if recorder.Code != http.StatusOK {
+		t.Errorf("Expected dashboard request to be served, got %d", recorder.Code)
+	}
+}
+
+func TestReboundHostsRejected(t *testing.T) {
+	server, controller := newTestServer()
+	var grpcHits int
+	server.SetGRPCHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		grpcHits++
+	}))
+
+	// A page on a domain rebound to 127.0.0.1 sends its own name as Host and Origin
+	restart := newRequest(http.MethodPost, "/api/services/backend/restart", nil)
+	restart.Host = "rebind.example:7080"
+	restart.Header.Set("Origin", "http://rebind.example:7080")
+	grpcRequest := newRequest(http.MethodPost, "/kportforward.admin.v1.AdminService/RestartService", nil)
+	grpcRequest.Host = "rebind.example:7080"
+	grpcRequest.ProtoMajor = 2
+	grpcRequest.Header.Set("Content-Type", "application/grpc")
+
+	for _, request := range []*http.Request{restart, grpcRequest} {
+		recorder := httptest.NewRecorder()
+		server.server.Handler.ServeHTTP(recorder, request)
+		if recorder.Code != http.StatusForbidden {
+			t.Errorf("Expected 403 for %s on a rebound host, got %d", request.URL.Path, recorder.Code)
+		}
+	}
+	if len(controller.restarted) != 0 || grpcHits != 0 {
+		t.Errorf("Expected nothing to run, got restarts %v and %d gRPC calls", controller.restarted, grpcHits)
+	}
+}
+
+func TestAllowedHost(t *testing.T) {
+	tests := []struct {
+		bind, host string
+		allowed    bool
+	}{
+		{"localhost:7080", "localhost:7080", true},
+		{"localhost:7080", "127.0.0.1:7080", true},
+		{"localhost:7080", "[::1]:7080", true},
+		{"localhost:7080", "rebind.example:7080", false},
+		{"localhost:7080", "192.168.1.20:7080", false},
+		{"192.168.1.20:7080", "192.168.1.20:7080", true},
+		{"devbox.lan:7080", "devbox.lan:7080", true},
+		{"0.0.0.0:7080", "192.168.1.20:7080", true},
+		{":7080", "192.168.1.20:7080", true},
+		{":7080", "rebind.example:7080", false},
+	}
+	for _, test := range tests {
+		server := NewServer(test.bind, &fakeController{}, utils.NewLogger(utils.LevelError))
+		if allowed := server.allowedHost(test.host); allowed != test.allowed {
+			t.Errorf("Listening on %s, expected host %s allowed=%v, got %v", test.bind, test.host, test.allowed, allowed)
+		}
+	}
+}
+
+func TestStatusStream(t *testing.T) {
+	server, controller := newTestServer()
+	httpServer := httptest.NewServer(server.server.Handler)
+	defer httpServer.Close()
+	defer close(server.done)
+
+	conn, err := websocket.Dial("ws"+strings.TrimPrefix(httpServer.URL, "http")+"/ws/status", "", httpServer.URL)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer conn.Close()
+
+	var message StreamMessage
+	if err := websocket.JSON.Receive(conn, &message); err != nil {
+		t.Fatalf("Failed to receive the initial status: %v", err)
+	}
+	if message.Type != "status" || !message.Full || len(message.Changed) != 2 || message.Changed[0].Name != "backend" {
+		t.Fatalf("Expected the full status first, got %+v", message)
+	}
+
+	controller.updates <- config.StatusUpdate{Version: 2, Changed: map[string]config.ServiceStatus{
+		"web": {Name: "web", Type: "web", Status: "Failed", LocalPort: 8080},
+	}, Removed: []string{"backend"}}
+	message = StreamMessage{}
+	if err := websocket.JSON.Receive(conn, &message); err != nil {
+		t.Fatalf("Failed to receive the delta: %v", err)
+	}
+	if message.Full || message.Version != 2 || len(message.Changed) != 1 || message.Changed[0].Status != "Failed" ||
+		len(message.Removed) != 1 || message.Removed[0] != "backend" {
+		t.Errorf("Unexpected delta: %+v", message)
+	}
+
+	server.HandleEvent(portforward.Event{Type: portforward.EventContextChanged, Context: "prod", PreviousContext: "dev"})
+	message = StreamMessage{}
+	if err := websocket.JSON.Receive(conn, &message); err != nil {
+		t.Fatalf("Failed to receive the event: %v", err)
+	}
+	if message.Type != "event" || message.Event == nil || message.Event.Type != "context.changed" || message.Event.Context != "prod" {
+		t.Errorf("Unexpected event message: %+v", message)
+	}
+}
+
+func TestStatusStreamRejectsForeignOrigins(t *testing.T) {
+	server, _ := newTestServer()
+	httpServer := httptest.NewServer(server.server.Handler)
+	defer httpServer.Close()
+
+	if _, err := websocket.Dial("ws"+strings.TrimPrefix(httpServer.URL, "http")+"/ws/status", "", "https://example.com"); err == nil {
+		t.Error("Expected a page from another origin to be rejected")
+	}
+}
+
+func TestStatusStreamSSE(t *testing.T) {
+	server, _ := newTestServer()
+	httpServer := httptest.NewServer(server.server.Handler)
+	defer httpServer.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	request, _ := http.NewRequestWithContext(ctx, http.MethodGet, httpServer.URL+"/ws/status", nil)
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	defer response.Body.Close()
+
+	if contentType := response.Header.Get("Content-Type"); contentType != "text/event-stream" {
+		t.Fatalf("Expected server-sent events, got %s", contentType)
+	}
+	line, err := bufio.NewReader(response.Body).ReadString('\n')
+	if err != nil {
+		t.Fatalf("Failed to read the first event: %v", err)
+	}
+	var message StreamMessage
+	if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &message); err != nil {
+		t.Fatalf("Failed to decode %q: %v", line, err)
+	}
+	if message.Type != "status" || !message.Full || len(message.Changed) != 2 {
+		t.Errorf("Expected the full status first, got %+v", message)
+	}
+}
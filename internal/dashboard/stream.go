@@ -0,0 +1,230 @@
+package dashboard
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/net/websocket"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/portforward"
+)
+
+// streamEventBuffer is how many events a stream client can fall behind before further
+// events are dropped for it; status deltas are never dropped, see Manager.Subscribe
+const streamEventBuffer = 64
+
+// StreamMessage is one message of the status stream: a status delta or an event
+type StreamMessage struct {
+	Type    string        `json:"type"` // "status" or "event"
+	Context string        `json:"context,omitempty"`
+	Version uint64        `json:"version,omitempty"`
+	Full    bool          `json:"full,omitempty"` // Changed holds every service; drop the ones not in it
+	Changed []ServiceView `json:"changed,omitempty"`
+	Removed []string      `json:"removed,omitempty"`
+	Event   *EventView    `json:"event,omitempty"`
+}
+
+// EventView is the JSON representation of a manager event
+type EventView struct {
+	Type            string    `json:"type"`
+	Timestamp       time.Time `json:"timestamp"`
+	Service         string    `json:"service,omitempty"`
+	Status          string    `json:"status,omitempty"`
+	PreviousStatus  string    `json:"previousStatus,omitempty"`
+	Error           string    `json:"error,omitempty"`
+	Context         string    `json:"context,omitempty"`
+	PreviousContext string    `json:"previousContext,omitempty"`
+	Started         int       `json:"started,omitempty"`
+	Total           int       `json:"total,omitempty"`
+}
+
+// handleStatusStream streams status deltas and events over a WebSocket, or as
+// server-sent events to clients that don't ask for an upgrade
+func (s *Server) handleStatusStream(w http.ResponseWriter, r *http.Request) {
+	if !strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+		s.streamSSE(w, r)
+		return
+	}
+	websocket.Server{Handshake: checkOrigin, Handler: s.streamWebSocket}.ServeHTTP(w, r)
+}
+
+// checkOrigin accepts clients without an Origin, such as editor extensions, and pages
+// served by the dashboard itself; other web pages may not read the stream
+func checkOrigin(config *websocket.Config, r *http.Request) error {
+	origin, err := allowedOrigin(r)
+	if err != nil {
+		return err
+	}
+	config.Origin = origin
+	return nil
+}
+
+// allowedOrigin returns the Origin of a request from a page served by the dashboard
+// itself, nil for a request without one, or an error for any other web page. serveHTTP
+// has already refused Host headers that don't name this machine.
+func allowedOrigin(r *http.Request) (*url.URL, error) {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return nil, nil
+	}
+	parsed, err := url.Parse(origin)
+	if err != nil || parsed.Host != r.Host {
+		return nil, fmt.Errorf("origin %s not allowed", origin)
+	}
+	return parsed, nil
+}
+
+// streamWebSocket sends the stream as JSON text frames until the client disconnects
+func (s *Server) streamWebSocket(conn *websocket.Conn) {
+	ctx, cancel := context.WithCancel(conn.Request().Context())
+	defer cancel()
+
+	// Clients don't send anything; reading notices when they go away
+	go func() {
+		defer cancel()
+		var discard string
+		for websocket.Message.Receive(conn, &discard) == nil {
+		}
+	}()
+
+	s.stream(ctx, func(message StreamMessage) error {
+		return websocket.JSON.Send(conn, message)
+	})
+}
+
+// streamSSE sends the stream as server-sent events until the client disconnects
+func (s *Server) streamSSE(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	s.stream(r.Context(), func(message StreamMessage) error {
+		data, err := json.Marshal(message)
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "data: %s\n\n", data); err != nil {
+			return err
+		}
+		flusher.Flush()
+		return nil
+	})
+}
+
+// stream subscribes to the manager's status deltas and events and sends them until
+// ctx ends, the server stops or sending fails. The first status message holds every
+// service.
+func (s *Server) stream(ctx context.Context, send func(StreamMessage) error) {
+	updates, cancel := s.controller.Subscribe()
+	defer cancel()
+	events := s.addStream()
+	defer s.removeStream(events)
+
+	// Subscribing only sends the current status once there are services
+	if len(updates) == 0 {
+		if err := send(s.statusMessage(config.StatusUpdate{Full: true})); err != nil {
+			return
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.done:
+			return
+		case update, ok := <-updates:
+			if !ok {
+				return // The manager stopped
+			}
+			if err := send(s.statusMessage(update)); err != nil {
+				return
+			}
+		case event := <-events:
+			if err := send(StreamMessage{Type: "event", Event: &event}); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// statusMessage converts a status update into a stream message
+func (s *Server) statusMessage(update config.StatusUpdate) StreamMessage {
+	message := StreamMessage{
+		Type:    "status",
+		Context: s.controller.GetKubernetesContext(),
+		Version: update.Version,
+		Full:    update.Full,
+		Changed: make([]ServiceView, 0, len(update.Changed)),
+		Removed: update.Removed,
+	}
+	configs := s.controller.ServiceConfigs()
+	for name, status := range update.Changed {
+		message.Changed = append(message.Changed, s.serviceView(name, status, configs[name]))
+	}
+	sort.Slice(message.Changed, func(i, j int) bool {
+		return message.Changed[i].Name < message.Changed[j].Name
+	})
+	return message
+}
+
+// addStream registers a stream client for events
+func (s *Server) addStream() chan EventView {
+	events := make(chan EventView, streamEventBuffer)
+	s.clientMutex.Lock()
+	s.streams[events] = struct{}{}
+	s.clientMutex.Unlock()
+	return events
+}
+
+// removeStream unregisters a stream client
+func (s *Server) removeStream(events chan EventView) {
+	s.clientMutex.Lock()
+	delete(s.streams, events)
+	s.clientMutex.Unlock()
+}
+
+// publishEvent passes an event to the stream clients without blocking; status updates
+// reach them as deltas instead
+func (s *Server) publishEvent(event portforward.Event) {
+	if event.Type == portforward.EventStatusUpdated {
+		return
+	}
+	view := EventView{
+		Type:            string(event.Type),
+		Timestamp:       event.Timestamp,
+		Service:         event.Service,
+		Status:          event.Status.Status,
+		PreviousStatus:  event.PreviousStatus,
+		Error:           event.Error,
+		Context:         event.Context,
+		PreviousContext: event.PreviousContext,
+		Started:         event.Started,
+		Total:           event.Total,
+	}
+
+	s.clientMutex.Lock()
+	defer s.clientMutex.Unlock()
+	for events := range s.streams {
+		select {
+		case events <- view:
+		default:
+			s.logger.Debug("Dropped %s event for a lagging stream client", event.Type)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package export
+
+import (
+	"encoding/csv"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+// Formats lists the supported export formats
+var Formats = []string{"markdown", "csv", "table"}
+
+// Row is one service in an exported status table
+type Row struct {
+	Name     string
+	Status   string
+	Type     string
+	Target   string // namespace/target
+	URL      string
+	Uptime   string
+	Restarts int
+	Error    string
+}
+
+// header is the column titles shared by all formats
+var header = []string{"Service", "Status", "Type", "Target", "URL", "Uptime", "Restarts", "Last Error"}
+
+// Rows builds the table rows for a status map, sorted by service name. Services
+// supply the local URL scheme and may be nil.
+func Rows(statuses map[string]config.ServiceStatus, services map[string]config.Service, now time.Time) []Row {
+	rows := make([]Row, 0, len(statuses))
+	for name, status := range statuses {
+		row := Row{
+			Name:     name,
+			Status:   status.Status,
+			Type:     status.Type,
+			Target:   status.Target,
+			Restarts: status.RestartCount,
+			Error:    status.LastError,
+		}
+		if status.Namespace != "" {
+			row.Target = status.Namespace + "/" + status.Target
+		}
+		if status.Status == "Running" && status.LocalPort != 0 {
+			row.URL = services[name].LocalURL(status.LocalPort)
+		}
+		if status.Status == "Running" && !status.StartTime.IsZero() {
+			row.Uptime = utils.FormatUptime(now.Sub(status.StartTime))
+		}
+		rows = append(rows, row)
+	}
+	sort.Slice(rows, func(i, j int) bool { return rows[i].Name < rows[j].Name })
+	return rows
+}
+
+// Render renders rows in the named format
+func Render(format string, rows []Row) (string, error) {
+	switch format {
+	case "markdown", "md":
+		return Markdown(rows), nil
+	case "csv":
+		return CSV(rows)
+	case "table":
+		return Table(rows), nil
+	default:
+		return "", fmt.Errorf("unknown format %q (expected %s)", format, strings.Join(Formats, ", "))
+	}
+}
+
+// Markdown renders rows as a GitHub-flavored Markdown table
+func Markdown(rows []Row) string {
+	var b strings.Builder
+	writeMarkdownRow(&b, header)
+	separators := make([]string, len(header))
+	for i := range separators {
+		separators[i] = "---"
+	}
+	writeMarkdownRow(&b, separators)
+	for _, row := range rows {
+		writeMarkdownRow(&b, orDash(row.fields()))
+	}
+	return b.String()
+}
+
+// CSV renders rows as CSV with a header line
+func CSV(rows []Row) (string, error) {
+	var b strings.Builder
+	w := csv.NewWriter(&b)
+	w.Write(header)
+	for _, row := range rows {
+		w.Write(row.fields())
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return "", fmt.Errorf("failed to encode CSV: %w", err)
+	}
+	return b.String(), nil
+}
+
+// Table renders rows as a plain text table with aligned columns, for terminals
+func Table(rows []Row) string {
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, strings.ToUpper(strings.Join(header, "\t")))
+	for _, row := range rows {
+		cells := orDash(row.fields())
+		for i, cell := range cells {
+			cells[i] = strings.Join(strings.Fields(cell), " ")
+		}
+		fmt.Fprintln(w, strings.Join(cells, "\t"))
+	}
+	w.Flush()
+	return b.String()
+}
+
+// fields returns the row's cells in header order
+func (r Row) fields() []string {
+	return []string{r.Name, r.Status, r.Type, r.Target, r.URL, r.Uptime, strconv.Itoa(r.Restarts), r.Error}
+}
+
+// writeMarkdownRow writes a table row, escaping characters that would break the table
+func writeMarkdownRow(b *strings.Builder, cells []string) {
+	b.WriteString("|")
+	for _, cell := range cells {
+		cell = strings.ReplaceAll(cell, "|", `\|`)
+		cell = strings.Join(strings.Fields(cell), " ")
+		b.WriteString(" " + cell + " |")
+	}
+	b.WriteString("\n")
+}
+
+// orDash replaces empty cells with "-" so Markdown tables read clearly
+func orDash(cells []string) []string {
+	for i, cell := range cells {
+		if cell == "" {
+			cells[i] = "-"
+		}
+	}
+	return cells
+}
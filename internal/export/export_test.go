@@ -0,0 +1,82 @@
+package export
+
+import (
+	"encoding/csv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+)
+
+func testRows() []Row {
+	now := time.Now()
+	statuses := map[string]config.ServiceStatus{
+		"web": {Status: "Running", Type: "web", Namespace: "apps", Target: "service/web", LocalPort: 8080,
+			StartTime: now.Add(-90 * time.Minute), RestartCount: 1},
+		"api": {Status: "Failed", Type: "rest", Namespace: "apps", Target: "service/api", LocalPort: 8081,
+			LastError: "pod | not\nready", RestartCount: 3},
+	}
+	services := map[string]config.Service{"web": {LocalTLS: true}}
+	return Rows(statuses, services, now)
+}
+
+func TestRows(t *testing.T) {
+	rows := testRows()
+	if len(rows) != 2 || rows[0].Name != "api" || rows[1].Name != "web" {
+		t.Fatalf("Expected rows sorted by name, got %+v", rows)
+	}
+	if rows[0].URL != "" || rows[0].Uptime != "" {
+		t.Errorf("Expected no URL or uptime for a failed service, got %+v", rows[0])
+	}
+	if rows[1].URL != "https://localhost:8080" || rows[1].Uptime != "1h30m" || rows[1].Target != "apps/service/web" {
+		t.Errorf("Unexpected running row: %+v", rows[1])
+	}
+}
+
+func TestMarkdown(t *testing.T) {
+	lines := strings.Split(strings.TrimSpace(Markdown(testRows())), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("Expected header, separator and 2 rows, got %q", lines)
+	}
+	if lines[0] != "| Service | Status | Type | Target | URL | Uptime | Restarts | Last Error |" {
+		t.Errorf("Unexpected header: %s", lines[0])
+	}
+	if lines[2] != `| api | Failed | rest | apps/service/api | - | - | 3 | pod \| not ready |` {
+		t.Errorf("Expected escaped error and dashes for empty cells, got %s", lines[2])
+	}
+}
+
+func TestTable(t *testing.T) {
+	lines := strings.Split(strings.TrimRight(Table(testRows()), "\n"), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected header and 2 rows, got %q", lines)
+	}
+	if !strings.HasPrefix(lines[0], "SERVICE  STATUS   TYPE") {
+		t.Errorf("Unexpected header: %q", lines[0])
+	}
+	if !strings.HasSuffix(lines[1], "pod | not ready") || !strings.Contains(lines[2], "https://localhost:8080") {
+		t.Errorf("Unexpected rows: %q", lines[1:])
+	}
+	if column := strings.Index(lines[0], "TARGET"); column < 0 || lines[2][column:column+len("apps/")] != "apps/" {
+		t.Errorf("Expected aligned columns, got %q", lines)
+	}
+}
+
+func TestCSV(t *testing.T) {
+	out, err := Render("csv", testRows())
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	records, err := csv.NewReader(strings.NewReader(out)).ReadAll()
+	if err != nil {
+		t.Fatalf("Output is not valid CSV: %v", err)
+	}
+	if len(records) != 3 || records[1][7] != "pod | not\nready" || records[2][4] != "https://localhost:8080" {
+		t.Errorf("Unexpected records: %q", records)
+	}
+
+	if _, err := Render("html", nil); err == nil {
+		t.Error("Expected an error for an unknown format")
+	}
+}
@@ -0,0 +1,247 @@
+package hosts
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+const (
+	beginMarker = "# BEGIN kportforward"
+	endMarker   = "# END kportforward"
+)
+
+// Entry maps an IP address to the host names that should resolve to it
+type Entry struct {
+	IP        string
+	Hostnames []string
+}
+
+// DefaultPath returns the platform's hosts file location
+func DefaultPath() string {
+	if runtime.GOOS == "windows" {
+		systemRoot := os.Getenv("SystemRoot")
+		if systemRoot == "" {
+			systemRoot = `C:\Windows`
+		}
+		return systemRoot + `\System32\drivers\etc\hosts`
+	}
+	return "/etc/hosts"
+}
+
+// EntriesFor returns cluster DNS names for every service/ target, pointing at 127.0.0.1
+func EntriesFor(services map[string]config.Service) []Entry {
+	names := make([]string, 0, len(services))
+	for name := range services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var entries []Entry
+	for _, name := range names {
+		if hostnames := ClusterHostnames(services[name]); len(hostnames) > 0 {
+			entries = append(entries, Entry{IP: "127.0.0.1", Hostnames: hostnames})
+		}
+	}
+	return entries
+}
+
+// loopbackDomains resolve to 127.0.0.1 through public DNS, so aliases under them need no hosts entry
+var loopbackDomains = []string{"localhost", "localtest.me", "lvh.me"}
+
+// AliasEntries returns hosts entries for the services' hostAlias names, pointing at
+// 127.0.0.1. Aliases under domains that already resolve to loopback are skipped.
+func AliasEntries(services map[string]config.Service) []Entry {
+	names := make([]string, 0, len(services))
+	for name := range services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var entries []Entry
+	for _, name := range names {
+		if alias := services[name].HostAlias; alias != "" && !resolvesToLoopback(alias) {
+			entries = append(entries, Entry{IP: "127.0.0.1", Hostnames: []string{alias}})
+		}
+	}
+	return entries
+}
+
+// resolvesToLoopback reports whether hostname is under a loopback domain
+func resolvesToLoopback(hostname string) bool {
+	hostname = strings.ToLower(strings.TrimSuffix(hostname, "."))
+	for _, domain := range loopbackDomains {
+		if hostname == domain || strings.HasSuffix(hostname, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClusterHostnames returns the in-cluster DNS names of a service target.
+// Only service/ targets have cluster DNS names.
+func ClusterHostnames(service config.Service) []string {
+	if !strings.HasPrefix(service.Target, "service/") && !strings.HasPrefix(service.Target, "svc/") {
+		return nil
+	}
+	name := service.Target[strings.Index(service.Target, "/")+1:]
+	namespace := service.Namespace
+	if namespace == "" {
+		namespace = "default"
+	}
+
+	return []string{
+		name,
+		fmt.Sprintf("%s.%s", name, namespace),
+		fmt.Sprintf("%s.%s.svc", name, namespace),
+		fmt.Sprintf("%s.%s.svc.cluster.local", name, namespace),
+	}
+}
+
+// Render returns the hosts file content with the kportforward block replaced by entries.
+// An empty entry list removes the block.
+func Render(existing []byte, entries []Entry) []byte {
+	var out bytes.Buffer
+	inBlock := false
+
+	scanner := bufio.NewScanner(bytes.NewReader(existing))
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.TrimSpace(line) == beginMarker:
+			inBlock = true
+		case strings.TrimSpace(line) == endMarker:
+			inBlock = false
+		case !inBlock:
+			out.WriteString(line)
+			out.WriteByte('\n')
+		}
+	}
+
+	if len(entries) == 0 {
+		return out.Bytes()
+	}
+
+	out.WriteString(beginMarker + "\n")
+	for _, entry := range entries {
+		fmt.Fprintf(&out, "%s\t%s\n", entry.IP, strings.Join(entry.Hostnames, " "))
+	}
+	out.WriteString(endMarker + "\n")
+	return out.Bytes()
+}
+
+// WriteEntries replaces the kportforward block in the hosts file at path
+func WriteEntries(path string, entries []Entry) error {
+	existing, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read hosts file: %w", err)
+	}
+
+	// Write in place; hosts files are often bind-mounted and cannot be renamed over
+	if err := os.WriteFile(path, Render(existing, entries), 0644); err != nil {
+		return fmt.Errorf("failed to write hosts file: %w", err)
+	}
+	return nil
+}
+
+// FormatEntries serializes entries in hosts file syntax, for passing to the helper
+func FormatEntries(entries []Entry) string {
+	var b strings.Builder
+	for _, entry := range entries {
+		fmt.Fprintf(&b, "%s %s\n", entry.IP, strings.Join(entry.Hostnames, " "))
+	}
+	return b.String()
+}
+
+// ParseEntries parses entries written by FormatEntries
+func ParseEntries(data []byte) ([]Entry, error) {
+	var entries []Entry
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("invalid hosts entry: %q", scanner.Text())
+		}
+		entries = append(entries, Entry{IP: fields[0], Hostnames: fields[1:]})
+	}
+	return entries, scanner.Err()
+}
+
+// Manager keeps the kportforward block of the hosts file up to date, escalating
+// through the privileged helper when the file isn't writable by the current user
+type Manager struct {
+	path   string
+	logger *utils.Logger
+}
+
+// NewManager creates a hosts file manager for path
+func NewManager(path string, logger *utils.Logger) *Manager {
+	if path == "" {
+		path = DefaultPath()
+	}
+	return &Manager{path: path, logger: logger}
+}
+
+// Apply writes entries to the hosts file
+func (m *Manager) Apply(entries []Entry) error {
+	if err := m.write(entries); err != nil {
+		return err
+	}
+	m.logger.Info("Added %d host entries to %s", len(entries), m.path)
+	return nil
+}
+
+// Clean removes all kportforward entries from the hosts file
+func (m *Manager) Clean() error {
+	if err := m.write(nil); err != nil {
+		return err
+	}
+	m.logger.Info("Removed kportforward host entries from %s", m.path)
+	return nil
+}
+
+// write updates the hosts file directly or through the privileged helper
+func (m *Manager) write(entries []Entry) error {
+	if isWritable(m.path) {
+		return WriteEntries(m.path, entries)
+	}
+
+	if runtime.GOOS == "windows" {
+		return fmt.Errorf("%s is not writable; run kportforward as Administrator", m.path)
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to locate kportforward executable: %w", err)
+	}
+
+	// sudo prompts on the terminal, so entries are passed on stdin
+	cmd := exec.Command("sudo", executable, "hosts-helper", "--file", m.path)
+	cmd.Stdin = strings.NewReader(FormatEntries(entries))
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("privileged hosts helper failed: %w", err)
+	}
+	return nil
+}
+
+// isWritable reports whether the current user can write the file
+func isWritable(path string) bool {
+	f, err := os.OpenFile(path, os.O_WRONLY, 0)
+	if err != nil {
+		return false
+	}
+	f.Close()
+	return true
+}
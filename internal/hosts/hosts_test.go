@@ -0,0 +1,107 @@
+package hosts
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+func TestClusterHostnames(t *testing.T) {
+	hostnames := ClusterHostnames(config.Service{Target: "service/api", Namespace: "backend"})
+	expected := []string{"api", "api.backend", "api.backend.svc", "api.backend.svc.cluster.local"}
+	if strings.Join(hostnames, " ") != strings.Join(expected, " ") {
+		t.Errorf("Expected %v, got %v", expected, hostnames)
+	}
+
+	if hostnames := ClusterHostnames(config.Service{Target: "deployment/api", Namespace: "backend"}); hostnames != nil {
+		t.Errorf("Expected no hostnames for deployment targets, got %v", hostnames)
+	}
+}
+
+func TestAliasEntries(t *testing.T) {
+	entries := AliasEntries(map[string]config.Service{
+		"payments": {HostAlias: "payments.localtest.me"},
+		"orders":   {HostAlias: "orders.dev.internal"},
+		"web":      {HostAlias: "web.localhost"},
+		"api":      {},
+	})
+	if len(entries) != 1 || entries[0].IP != "127.0.0.1" || strings.Join(entries[0].Hostnames, " ") != "orders.dev.internal" {
+		t.Errorf("Expected only the alias outside loopback domains, got %v", entries)
+	}
+}
+
+func TestRenderReplacesManagedBlock(t *testing.T) {
+	existing := []byte("127.0.0.1\tlocalhost\n# BEGIN kportforward\n127.0.0.1\told\n# END kportforward\n::1\tlocalhost\n")
+	entries := []Entry{{IP: "127.0.0.1", Hostnames: []string{"api", "api.backend"}}}
+
+	rendered := string(Render(existing, entries))
+	if strings.Contains(rendered, "old") {
+		t.Error("Expected previous kportforward entries to be replaced")
+	}
+	if !strings.Contains(rendered, "127.0.0.1\tapi api.backend\n") {
+		t.Errorf("Expected new entry in output:\n%s", rendered)
+	}
+	if !strings.HasPrefix(rendered, "127.0.0.1\tlocalhost\n::1\tlocalhost\n") {
+		t.Errorf("Expected unmanaged lines to be preserved:\n%s", rendered)
+	}
+
+	cleaned := string(Render([]byte(rendered), nil))
+	if cleaned != "127.0.0.1\tlocalhost\n::1\tlocalhost\n" {
+		t.Errorf("Expected block to be removed, got:\n%s", cleaned)
+	}
+}
+
+func TestFormatAndParseEntries(t *testing.T) {
+	entries := []Entry{
+		{IP: "127.0.0.1", Hostnames: []string{"api", "api.backend"}},
+		{IP: "127.0.0.1", Hostnames: []string{"web"}},
+	}
+
+	parsed, err := ParseEntries([]byte(FormatEntries(entries)))
+	if err != nil {
+		t.Fatalf("ParseEntries failed: %v", err)
+	}
+	if len(parsed) != 2 || parsed[0].Hostnames[1] != "api.backend" || parsed[1].Hostnames[0] != "web" {
+		t.Errorf("Unexpected round trip result: %+v", parsed)
+	}
+
+	if _, err := ParseEntries([]byte("127.0.0.1\n")); err == nil {
+		t.Error("Expected error for entry without hostnames")
+	}
+}
+
+func TestManagerApplyAndClean(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hosts")
+	if err := os.WriteFile(path, []byte("127.0.0.1\tlocalhost\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	manager := NewManager(path, utils.NewLogger(utils.LevelError))
+	entries := EntriesFor(map[string]config.Service{
+		"api": {Target: "service/api", Namespace: "backend"},
+		"job": {Target: "deployment/job", Namespace: "backend"},
+	})
+	if len(entries) != 1 {
+		t.Fatalf("Expected 1 entry, got %d", len(entries))
+	}
+
+	if err := manager.Apply(entries); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	data, _ := os.ReadFile(path)
+	if !strings.Contains(string(data), "api.backend.svc.cluster.local") {
+		t.Errorf("Expected cluster names in hosts file:\n%s", data)
+	}
+
+	if err := manager.Clean(); err != nil {
+		t.Fatalf("Clean failed: %v", err)
+	}
+	data, _ = os.ReadFile(path)
+	if string(data) != "127.0.0.1\tlocalhost\n" {
+		t.Errorf("Expected original hosts file after clean, got:\n%s", data)
+	}
+}
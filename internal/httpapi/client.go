@@ -0,0 +1,89 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/victorkazakov/kportforward/internal/statusfile"
+)
+
+// Client calls the API of a running instance, on its --api-port or the daemon's
+// control socket
+type Client struct {
+	baseURL string
+	target  string // Names the instance in errors
+	http    *http.Client
+}
+
+// NewClient creates a client for the API a running instance serves on localhost at
+// port (its --api-port)
+func NewClient(port int) *Client {
+	return &Client{
+		baseURL: "http://" + net.JoinHostPort("127.0.0.1", strconv.Itoa(port)),
+		target:  fmt.Sprintf("the API on port %d", port),
+		http:    &http.Client{},
+	}
+}
+
+// NewSocketClient creates a client for the API served on the unix socket at path
+func NewSocketClient(path string) *Client {
+	transport := &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, "unix", path)
+		},
+	}
+	return &Client{baseURL: "http://kportforward", target: path, http: &http.Client{Transport: transport}}
+}
+
+// Services gets the status of every service
+func (c *Client) Services(ctx context.Context) (statusfile.Document, error) {
+	var doc statusfile.Document
+	err := c.Do(ctx, http.MethodGet, "/services", &doc)
+	return doc, err
+}
+
+// Restart restarts a service
+func (c *Client) Restart(ctx context.Context, name string) error {
+	return c.Do(ctx, http.MethodPost, "/services/"+url.PathEscape(name)+"/restart", nil)
+}
+
+// Do calls path and decodes the JSON response into result, which may be nil. An error
+// response is returned as an error with its message.
+func (c *Client) Do(ctx context.Context, method, path string, result interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach %s: %w", c.target, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		var apiError struct {
+			Error string `json:"error"`
+		}
+		if json.Unmarshal(body, &apiError) == nil && apiError.Error != "" {
+			return fmt.Errorf("%s", apiError.Error)
+		}
+		return fmt.Errorf("%s answered %s: %s", c.target, resp.Status, strings.TrimSpace(string(body)))
+	}
+	if result == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return fmt.Errorf("failed to decode the response of %s: %w", c.target, err)
+	}
+	return nil
+}
@@ -0,0 +1,207 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/statusfile"
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+// Controller is the subset of the port-forward manager used by the API
+type Controller interface {
+	GetCurrentStatus() map[string]config.ServiceStatus
+	GetKubernetesContext() string
+	RestartService(name string) error
+}
+
+// Health is the JSON body of /healthz and /readyz
+type Health struct {
+	Status  string             `json:"status"` // "ok", or "not ready" while services aren't running
+	Context string             `json:"context"`
+	Summary statusfile.Summary `json:"summary"`
+}
+
+// Server serves the HTTP status and control API for scripts and CI jobs
+type Server struct {
+	addr       string
+	controller Controller
+	logger     *utils.Logger
+	server     *http.Server
+	listener   net.Listener
+}
+
+// NewServer creates an API server listening on localhost at port; 0 picks a free port
+func NewServer(port int, controller Controller, logger *utils.Logger) *Server {
+	s := &Server{
+		addr:       net.JoinHostPort("127.0.0.1", strconv.Itoa(port)),
+		controller: controller,
+		logger:     logger,
+	}
+
+	s.server = &http.Server{
+		Addr:              s.addr,
+		Handler:           s.routes(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	return s
+}
+
+// NewHandler returns the API's routes without a listener, e.g. to serve them on the
+// daemon's control socket
+func NewHandler(controller Controller, logger *utils.Logger) http.Handler {
+	s := &Server{controller: controller, logger: logger}
+	return s.routes()
+}
+
+// routes returns the API's routes
+func (s *Server) routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", s.handleHealth)
+	mux.HandleFunc("/readyz", s.handleReady)
+	mux.HandleFunc("/services", s.handleServices)
+	mux.HandleFunc("/services/", s.handleService)
+	return mux
+}
+
+// Start begins serving the API in the background
+func (s *Server) Start() error {
+	listener, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", s.addr, err)
+	}
+	s.listener = listener
+
+	go func() {
+		if err := s.server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			s.logger.Error("API server error: %v", err)
+		}
+	}()
+
+	s.logger.Info("HTTP API available at http://%s", listener.Addr())
+	return nil
+}
+
+// Addr returns the address the API listens on, once started
+func (s *Server) Addr() string {
+	if s.listener == nil {
+		return s.addr
+	}
+	return s.listener.Addr().String()
+}
+
+// Stop shuts down the API server
+func (s *Server) Stop() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return s.server.Shutdown(ctx)
+}
+
+// handleHealth reports that kportforward is up, with the service counts
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	writeJSON(w, http.StatusOK, s.health())
+}
+
+// handleReady answers 503 until every service is running, for scripts waiting on the forwards
+func (s *Server) handleReady(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	health := s.health()
+	if health.Summary.Running < health.Summary.Total {
+		health.Status = "not ready"
+		writeJSON(w, http.StatusServiceUnavailable, health)
+		return
+	}
+	writeJSON(w, http.StatusOK, health)
+}
+
+// health summarizes the current status
+func (s *Server) health() Health {
+	doc := statusfile.BuildDocument(s.controller.GetKubernetesContext(), s.controller.GetCurrentStatus())
+	return Health{Status: "ok", Context: doc.Context, Summary: doc.Summary}
+}
+
+// handleServices returns the status of every service, in the status file format
+func (s *Server) handleServices(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	doc := statusfile.BuildDocument(s.controller.GetKubernetesContext(), s.controller.GetCurrentStatus())
+	doc.UpdatedAt = time.Now()
+	writeJSON(w, http.StatusOK, doc)
+}
+
+// handleService handles GET /services/{name} and POST /services/{name}/restart
+func (s *Server) handleService(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/services/"), "/")
+	if parts[0] == "" || len(parts) > 2 {
+		writeError(w, http.StatusNotFound, "not found")
+		return
+	}
+	name := parts[0]
+
+	if len(parts) == 1 {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		status, exists := s.controller.GetCurrentStatus()[name]
+		if !exists {
+			writeError(w, http.StatusNotFound, fmt.Sprintf("service %s not found", name))
+			return
+		}
+		doc := statusfile.BuildDocument(s.controller.GetKubernetesContext(), map[string]config.ServiceStatus{name: status})
+		writeJSON(w, http.StatusOK, doc.Services[name])
+		return
+	}
+
+	if parts[1] != "restart" {
+		writeError(w, http.StatusNotFound, "not found")
+		return
+	}
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	// Browsers send any web page's POST to localhost with its Origin; scripts send none
+	if origin := r.Header.Get("Origin"); origin != "" {
+		writeError(w, http.StatusForbidden, fmt.Sprintf("origin %s not allowed", origin))
+		return
+	}
+	if _, exists := s.controller.GetCurrentStatus()[name]; !exists {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("service %s not found", name))
+		return
+	}
+	if err := s.controller.RestartService(name); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	s.logger.Info("Restarted %s through the HTTP API", name)
+	writeJSON(w, http.StatusOK, map[string]string{"status": "restarted"})
+}
+
+// writeError writes a JSON error response
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
+
+// writeJSON writes a JSON response with the given status code
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(value)
+}
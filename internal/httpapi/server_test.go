@@ -0,0 +1,174 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/statusfile"
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+type fakeController struct {
+	statuses  map[string]config.ServiceStatus
+	restarted []string
+}
+
+func (f *fakeController) GetCurrentStatus() map[string]config.ServiceStatus {
+	return f.statuses
+}
+
+func (f *fakeController) GetKubernetesContext() string {
+	return "test-context"
+}
+
+func (f *fakeController) RestartService(name string) error {
+	if _, exists := f.statuses[name]; !exists {
+		return fmt.Errorf("service %s not found", name)
+	}
+	f.restarted = append(f.restarted, name)
+	return nil
+}
+
+func newTestServer() (*Server, *fakeController) {
+	controller := &fakeController{
+		statuses: map[string]config.ServiceStatus{
+			"web":     {Name: "web", Type: "web", Status: "Running", LocalPort: 8080},
+			"backend": {Name: "backend", Type: "rpc", Status: "Failed", LocalPort: 50051, LastError: "Health check failed"},
+		},
+	}
+	return NewServer(0, controller, utils.NewLogger(utils.LevelError)), controller
+}
+
+func serve(server *Server, method, path string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	server.server.Handler.ServeHTTP(recorder, httptest.NewRequest(method, path, nil))
+	return recorder
+}
+
+func TestHealthAndReadiness(t *testing.T) {
+	server, controller := newTestServer()
+
+	recorder := serve(server, http.MethodGet, "/healthz")
+	var health Health
+	if err := json.Unmarshal(recorder.Body.Bytes(), &health); err != nil {
+		t.Fatalf("Failed to decode health: %v", err)
+	}
+	if recorder.Code != http.StatusOK || health.Status != "ok" || health.Summary.Total != 2 || health.Summary.Failed != 1 {
+		t.Errorf("Unexpected health %d %+v", recorder.Code, health)
+	}
+
+	if recorder := serve(server, http.MethodGet, "/readyz"); recorder.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected 503 while backend is failed, got %d", recorder.Code)
+	}
+	controller.statuses["backend"] = config.ServiceStatus{Name: "backend", Status: "Running"}
+	if recorder := serve(server, http.MethodGet, "/readyz"); recorder.Code != http.StatusOK {
+		t.Errorf("Expected 200 once every service runs, got %d", recorder.Code)
+	}
+}
+
+func TestServices(t *testing.T) {
+	server, _ := newTestServer()
+
+	recorder := serve(server, http.MethodGet, "/services")
+	var doc statusfile.Document
+	if err := json.Unmarshal(recorder.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("Failed to decode services: %v", err)
+	}
+	if doc.Context != "test-context" || len(doc.Services) != 2 || doc.Services["backend"].LastError != "Health check failed" {
+		t.Errorf("Unexpected services document: %+v", doc)
+	}
+
+	recorder = serve(server, http.MethodGet, "/services/web")
+	var entry statusfile.ServiceEntry
+	if err := json.Unmarshal(recorder.Body.Bytes(), &entry); err != nil {
+		t.Fatalf("Failed to decode service: %v", err)
+	}
+	if recorder.Code != http.StatusOK || entry.Status != "Running" || entry.LocalPort != 8080 {
+		t.Errorf("Unexpected service %d %+v", recorder.Code, entry)
+	}
+
+	if recorder := serve(server, http.MethodGet, "/services/missing"); recorder.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown service, got %d", recorder.Code)
+	}
+}
+
+func TestRestart(t *testing.T) {
+	server, controller := newTestServer()
+
+	if recorder := serve(server, http.MethodPost, "/services/backend/restart"); recorder.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", recorder.Code)
+	}
+	if len(controller.restarted) != 1 || controller.restarted[0] != "backend" {
+		t.Errorf("Expected backend to be restarted, got %v", controller.restarted)
+	}
+	if recorder := serve(server, http.MethodPost, "/services/missing/restart"); recorder.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown service, got %d", recorder.Code)
+	}
+	if recorder := serve(server, http.MethodGet, "/services/backend/restart"); recorder.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected 405 for GET restart, got %d", recorder.Code)
+	}
+
+	// A web page's POST carries its Origin
+	request := httptest.NewRequest(http.MethodPost, "/services/backend/restart", nil)
+	request.Header.Set("Origin", "https://evil.example")
+	recorder := httptest.NewRecorder()
+	server.server.Handler.ServeHTTP(recorder, request)
+	if recorder.Code != http.StatusForbidden || len(controller.restarted) != 1 {
+		t.Errorf("Expected a browser restart to be forbidden, got %d", recorder.Code)
+	}
+}
+
+func TestStartListensOnLocalhost(t *testing.T) {
+	server, _ := newTestServer()
+	if err := server.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer server.Stop()
+
+	response, err := http.Get("http://" + server.Addr() + "/healthz")
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		t.Errorf("Expected 200, got %d", response.StatusCode)
+	}
+}
+
+func TestClient(t *testing.T) {
+	server, _ := newTestServer()
+	if err := server.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer server.Stop()
+
+	_, portText, _ := net.SplitHostPort(server.Addr())
+	port, _ := strconv.Atoi(portText)
+	client := NewClient(port)
+	doc, err := client.Services(context.Background())
+	if err != nil {
+		t.Fatalf("Services failed: %v", err)
+	}
+	if doc.Context != "test-context" || doc.Summary.Total != 2 || doc.Services["web"].Status != "Running" {
+		t.Errorf("Unexpected document: %+v", doc)
+	}
+
+	if err := client.Restart(context.Background(), "backend"); err != nil {
+		t.Errorf("Restart failed: %v", err)
+	}
+	if err := client.Restart(context.Background(), "missing"); err == nil || err.Error() != "service missing not found" {
+		t.Errorf("Expected the API's error message, got %v", err)
+	}
+
+	server.Stop()
+	if _, err := client.Services(context.Background()); err == nil {
+		t.Error("Expected an error once nothing listens on the port")
+	}
+}
@@ -0,0 +1,70 @@
+package i18n
+
+// german is the German catalog
+var german = map[string]string{
+	// Header and table
+	"Context: %s":                          "Kontext: %s",
+	"Profile: %s":                          "Profil: %s",
+	"all":                                  "alle",
+	"Services (%d/%d running)":             "Dienste (%d/%d aktiv)",
+	"Starting services (%d/%d)":            "Dienste werden gestartet (%d/%d)",
+	"Restarting services (%d/%d)":          "Dienste werden neu gestartet (%d/%d)",
+	"No services configured":               "Keine Dienste konfiguriert",
+	"Name":                                 "Name",
+	"Status":                               "Status",
+	"Type":                                 "Typ",
+	"Port":                                 "Port",
+	"Uptime":                               "Laufzeit",
+	"Latency":                              "Latenz",
+	"Cluster":                              "Cluster",
+	"Error":                                "Fehler",
+	"Restarts":                             "Neustarts",
+	"Errors":                               "Fehler",
+	"Service Details: %s":                  "Dienstdetails: %s",
+	"Last Error:":                          "Letzter Fehler:",
+	"Error Type: %s (%s)":                  "Fehlertyp: %s (%s)",
+	"transient, should recover on its own": "vorübergehend, sollte sich von selbst erholen",
+	"needs fixing":                         "muss behoben werden",
+
+	// Help footers
+	"Sort: %s":        "Sortierung: %s",
+	"Sort: %s (desc)": "Sortierung: %s (absteigend)",
+	"[↑↓] Navigate":   "[↑↓] Navigieren",
+	"[Enter] Details": "[Enter] Details",
+	"[n/s/t/p/u/c/e] Sort by Name/Status/Type/Port/Uptime/Restarts/Errors": "[n/s/t/p/u/c/e] Sortieren nach Name/Status/Typ/Port/Laufzeit/Neustarts/Fehler",
+	"[r] Reverse":              "[r] Umkehren",
+	"[l] Split log":            "[l] Geteiltes Log",
+	"[x/X] Export MD/CSV":      "[x/X] Export MD/CSV",
+	"[w] Swap target":          "[w] Ziel wechseln",
+	"[R] Restart":              "[R] Neu starten",
+	"[P] Profile":              "[P] Profil",
+	"[-/+] Pause/Resume":       "[-/+] Pausieren/Fortsetzen",
+	"[F5] Reload config":       "[F5] Konfiguration neu laden",
+	"[q] Quit":                 "[q] Beenden",
+	"[e] Edit":                 "[e] Bearbeiten",
+	"[ESC] Back to table view": "[ESC] Zurück zur Tabelle",
+	"[l] Output":               "[l] Ausgabe",
+	"[l] Details":              "[l] Details",
+	"[↑↓/PgUp/PgDn] Scroll":    "[↑↓/BildAuf/BildAb] Blättern",
+	"[g/G] Oldest/Newest":      "[g/G] Älteste/Neueste",
+	"Output: %s":               "Ausgabe: %s",
+	"(%d newer lines below)":   "(%d neuere Zeilen darunter)",
+	"No output yet":            "Noch keine Ausgabe",
+
+	// Error hints
+	"Next step: %s":       "Nächster Schritt: %s",
+	"the current context": "aktueller Kontext",
+	"The pod restarted or was rescheduled; the forward reconnects to a new pod automatically":                                         "Der Pod wurde neu gestartet oder verschoben; die Weiterleitung verbindet sich automatisch mit einem neuen Pod",
+	"Nothing listens on port %d in the pod; check the targetPort and that the app has started":                                        "Im Pod lauscht nichts auf Port %d; prüfe targetPort und ob die Anwendung gestartet ist",
+	"Local port %d is taken; a free port is picked on the next restart, or stop whatever uses it":                                     "Der lokale Port %d ist belegt; beim nächsten Neustart wird ein freier Port gewählt, oder beende, was ihn verwendet",
+	"The API server can't be reached; check your VPN or network, forwards resume once it answers":                                     "Der API-Server ist nicht erreichbar; prüfe VPN oder Netzwerk, die Weiterleitungen laufen weiter, sobald er antwortet",
+	"Your credentials for %s are missing or expired; log in to the cluster again":                                                     "Zugangsdaten fehlen oder sind abgelaufen (Kontext: %s); melde dich erneut am Cluster an",
+	"RBAC denies the forward; run: kubectl auth can-i create pods/portforward -n %s":                                                  "RBAC verweigert die Weiterleitung; führe aus: kubectl auth can-i create pods/portforward -n %s",
+	"Namespace %s doesn't exist in %s; check that you are on the right context":                                                       "Namespace %s existiert nicht (Kontext: %s); prüfe, ob du den richtigen Kontext verwendest",
+	"%s doesn't exist in namespace %s; check the target name or whether it was deleted":                                               "%s existiert nicht im Namespace %s; prüfe den Zielnamen oder ob es gelöscht wurde",
+	"The API server couldn't open a stream to the pod; its node may be unreachable, or a proxy in between blocks connection upgrades": "Der API-Server konnte keinen Datenstrom zum Pod öffnen; sein Knoten ist eventuell nicht erreichbar, oder ein Proxy dazwischen blockiert Verbindungs-Upgrades",
+	"Kubernetes API server unreachable: %d services paused, they restart automatically once the cluster answers":                      "Kubernetes-API-Server nicht erreichbar: %d Dienste pausiert, sie starten automatisch neu, sobald der Cluster antwortet",
+	"Teleport login required for %s: run %s in another terminal; services start automatically once logged in":                         "Teleport-Anmeldung erforderlich für %s: führe %s in einem anderen Terminal aus; die Dienste starten nach der Anmeldung automatisch",
+	"kubectl switched from %s to %s: restart the services in %s? [y] Restart [n] Keep %s":                                             "kubectl wechselte von %s zu %s: Dienste in %s neu starten? [y] Neu starten [n] %s behalten",
+	" or ": " oder ",
+}
@@ -0,0 +1,70 @@
+package i18n
+
+// spanish is the Spanish catalog
+var spanish = map[string]string{
+	// Header and table
+	"Context: %s":                          "Contexto: %s",
+	"Profile: %s":                          "Perfil: %s",
+	"all":                                  "todos",
+	"Services (%d/%d running)":             "Servicios (%d/%d en ejecución)",
+	"Starting services (%d/%d)":            "Iniciando servicios (%d/%d)",
+	"Restarting services (%d/%d)":          "Reiniciando servicios (%d/%d)",
+	"No services configured":               "No hay servicios configurados",
+	"Name":                                 "Nombre",
+	"Status":                               "Estado",
+	"Type":                                 "Tipo",
+	"Port":                                 "Puerto",
+	"Uptime":                               "Activo",
+	"Latency":                              "Latencia",
+	"Cluster":                              "Clúster",
+	"Error":                                "Error",
+	"Restarts":                             "Reinicios",
+	"Errors":                               "Errores",
+	"Service Details: %s":                  "Detalles del servicio: %s",
+	"Last Error:":                          "Último error:",
+	"Error Type: %s (%s)":                  "Tipo de error: %s (%s)",
+	"transient, should recover on its own": "transitorio, debería recuperarse solo",
+	"needs fixing":                         "requiere corrección",
+
+	// Help footers
+	"Sort: %s":        "Orden: %s",
+	"Sort: %s (desc)": "Orden: %s (desc)",
+	"[↑↓] Navigate":   "[↑↓] Navegar",
+	"[Enter] Details": "[Enter] Detalles",
+	"[n/s/t/p/u/c/e] Sort by Name/Status/Type/Port/Uptime/Restarts/Errors": "[n/s/t/p/u/c/e] Ordenar por nombre/estado/tipo/puerto/actividad/reinicios/errores",
+	"[r] Reverse":              "[r] Invertir",
+	"[l] Split log":            "[l] Registro dividido",
+	"[x/X] Export MD/CSV":      "[x/X] Exportar MD/CSV",
+	"[w] Swap target":          "[w] Cambiar destino",
+	"[R] Restart":              "[R] Reiniciar",
+	"[P] Profile":              "[P] Perfil",
+	"[-/+] Pause/Resume":       "[-/+] Pausar/Reanudar",
+	"[F5] Reload config":       "[F5] Recargar configuración",
+	"[q] Quit":                 "[q] Salir",
+	"[e] Edit":                 "[e] Editar",
+	"[ESC] Back to table view": "[ESC] Volver a la tabla",
+	"[l] Output":               "[l] Salida",
+	"[l] Details":              "[l] Detalles",
+	"[↑↓/PgUp/PgDn] Scroll":    "[↑↓/RePág/AvPág] Desplazar",
+	"[g/G] Oldest/Newest":      "[g/G] Más antiguas/Más recientes",
+	"Output: %s":               "Salida: %s",
+	"(%d newer lines below)":   "(%d líneas más recientes abajo)",
+	"No output yet":            "Aún no hay salida",
+
+	// Error hints
+	"Next step: %s":       "Siguiente paso: %s",
+	"the current context": "el contexto actual",
+	"The pod restarted or was rescheduled; the forward reconnects to a new pod automatically":                                         "El pod se reinició o se reprogramó; el reenvío se reconecta automáticamente a un pod nuevo",
+	"Nothing listens on port %d in the pod; check the targetPort and that the app has started":                                        "Nada escucha en el puerto %d del pod; revisa targetPort y que la aplicación haya arrancado",
+	"Local port %d is taken; a free port is picked on the next restart, or stop whatever uses it":                                     "El puerto local %d está ocupado; se elegirá uno libre en el próximo reinicio, o detén lo que lo esté usando",
+	"The API server can't be reached; check your VPN or network, forwards resume once it answers":                                     "No se puede contactar con el servidor de API; revisa tu VPN o red, los reenvíos se reanudan cuando responda",
+	"Your credentials for %s are missing or expired; log in to the cluster again":                                                     "Tus credenciales para %s faltan o han caducado; vuelve a iniciar sesión en el clúster",
+	"RBAC denies the forward; run: kubectl auth can-i create pods/portforward -n %s":                                                  "RBAC deniega el reenvío; ejecuta: kubectl auth can-i create pods/portforward -n %s",
+	"Namespace %s doesn't exist in %s; check that you are on the right context":                                                       "El namespace %s no existe en %s; comprueba que estás en el contexto correcto",
+	"%s doesn't exist in namespace %s; check the target name or whether it was deleted":                                               "%s no existe en el namespace %s; revisa el nombre del destino o si se eliminó",
+	"The API server couldn't open a stream to the pod; its node may be unreachable, or a proxy in between blocks connection upgrades": "El servidor de API no pudo abrir un flujo hacia el pod; puede que su nodo no sea accesible o que un proxy intermedio bloquee las actualizaciones de conexión",
+	"Kubernetes API server unreachable: %d services paused, they restart automatically once the cluster answers":                      "Servidor de API de Kubernetes inaccesible: %d servicios en pausa, se reinician automáticamente cuando el clúster responda",
+	"Teleport login required for %s: run %s in another terminal; services start automatically once logged in":                         "Se requiere iniciar sesión en Teleport para %s: ejecuta %s en otra terminal; los servicios arrancan automáticamente tras iniciar sesión",
+	"kubectl switched from %s to %s: restart the services in %s? [y] Restart [n] Keep %s":                                             "kubectl cambió de %s a %s: ¿reiniciar los servicios en %s? [y] Reiniciar [n] Mantener %s",
+	" or ": " o ",
+}
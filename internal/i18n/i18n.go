@@ -0,0 +1,73 @@
+// Package i18n translates user-facing strings. Messages are looked up by their
+// English text, gettext-style, so a string missing from a catalog shows in English.
+package i18n
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// DefaultLocale is the language of the message keys themselves
+const DefaultLocale = "en"
+
+// catalogs holds the translations of each supported locale, keyed by English message
+var catalogs = map[string]map[string]string{
+	"de": german,
+	"es": spanish,
+}
+
+// current is the catalog in use; nil for English. Set before the UI starts.
+var current map[string]string
+
+// SetLocale selects the catalog used by T; unsupported locales fall back to English
+func SetLocale(locale string) {
+	current = catalogs[locale]
+}
+
+// Detect picks the locale to use: the configured language if set, else the first of
+// LC_ALL, LC_MESSAGES and LANG that is set (e.g. "de_DE.UTF-8" selects "de").
+// Returns DefaultLocale when nothing selects a supported locale.
+func Detect(configured string) string {
+	value := configured
+	if value == "" {
+		for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+			if value = os.Getenv(name); value != "" {
+				break
+			}
+		}
+	}
+
+	// language[_territory][.codeset][@modifier], or a BCP 47 tag like es-MX
+	value, _, _ = strings.Cut(value, ".")
+	value, _, _ = strings.Cut(value, "@")
+	language, _, _ := strings.Cut(strings.ReplaceAll(value, "-", "_"), "_")
+	language = strings.ToLower(language)
+	if _, ok := catalogs[language]; ok {
+		return language
+	}
+	return DefaultLocale
+}
+
+// Locales lists the supported locales
+func Locales() []string {
+	locales := []string{DefaultLocale}
+	for locale := range catalogs {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales[1:])
+	return locales
+}
+
+// T translates message to the current locale, formatting it with args like
+// fmt.Sprintf when any are given
+func T(message string, args ...any) string {
+	if translated, ok := current[message]; ok {
+		message = translated
+	}
+	if len(args) == 0 {
+		return message
+	}
+	return fmt.Sprintf(message, args...)
+}
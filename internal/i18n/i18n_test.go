@@ -0,0 +1,67 @@
+package i18n
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+// verbPattern matches fmt verbs, ignoring escaped percent signs
+var verbPattern = regexp.MustCompile(`%[-+# 0-9.*]*[a-zA-Z]`)
+
+func TestCatalogsKeepFormatVerbs(t *testing.T) {
+	for locale, catalog := range catalogs {
+		for message, translated := range catalog {
+			want := verbPattern.FindAllString(message, -1)
+			if got := verbPattern.FindAllString(translated, -1); !reflect.DeepEqual(got, want) {
+				t.Errorf("%s: %q has verbs %v, want %v", locale, translated, got, want)
+			}
+		}
+	}
+}
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		configured, lcAll, lang string
+		want                    string
+	}{
+		{"", "", "de_DE.UTF-8", "de"},
+		{"", "es_MX", "de_DE.UTF-8", "es"},
+		{"es-AR", "", "de_DE.UTF-8", "es"},
+		{"DE", "", "", "de"},
+		{"", "", "fr_FR.UTF-8", "en"},
+		{"", "", "C", "en"},
+		{"", "", "", "en"},
+	}
+	for _, tt := range tests {
+		t.Setenv("LC_ALL", tt.lcAll)
+		t.Setenv("LC_MESSAGES", "")
+		t.Setenv("LANG", tt.lang)
+		if got := Detect(tt.configured); got != tt.want {
+			t.Errorf("Detect(%q) with LC_ALL=%q LANG=%q = %q, want %q", tt.configured, tt.lcAll, tt.lang, got, tt.want)
+		}
+	}
+}
+
+func TestT(t *testing.T) {
+	defer SetLocale(DefaultLocale)
+
+	SetLocale("de")
+	if got := T("Services (%d/%d running)", 2, 3); got != "Dienste (2/3 aktiv)" {
+		t.Errorf("T() = %q", got)
+	}
+	if got := T("Not in any catalog: %d", 1); got != "Not in any catalog: 1" {
+		t.Errorf("T() without a translation = %q", got)
+	}
+
+	SetLocale("xx")
+	if got := T("[q] Quit"); got != "[q] Quit" {
+		t.Errorf("T() with an unsupported locale = %q", got)
+	}
+}
+
+func TestLocales(t *testing.T) {
+	if got := Locales(); !reflect.DeepEqual(got, []string{"en", "de", "es"}) {
+		t.Errorf("Locales() = %v", got)
+	}
+}
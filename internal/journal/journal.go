@@ -0,0 +1,283 @@
+package journal
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/victorkazakov/kportforward/internal/portforward"
+)
+
+const (
+	// MaxSessions is how many session journals are kept; older ones are removed on open
+	MaxSessions = 30
+
+	// sessionTimeFormat names journal files after the session start
+	sessionTimeFormat = "20060102-150405"
+
+	recordSessionStart = "session.start"
+	recordSessionEnd   = "session.end"
+)
+
+// Record is a single line of a session journal
+type Record struct {
+	Time            time.Time `json:"time"`
+	Type            string    `json:"type"`
+	Service         string    `json:"service,omitempty"`
+	Status          string    `json:"status,omitempty"`
+	PreviousStatus  string    `json:"previousStatus,omitempty"`
+	Error           string    `json:"error,omitempty"`
+	DurationMs      float64   `json:"durationMs,omitempty"`
+	LocalPort       int       `json:"localPort,omitempty"`
+	ConfiguredPort  int       `json:"configuredPort,omitempty"`
+	RestartCount    int       `json:"restartCount,omitempty"`
+	Context         string    `json:"context,omitempty"`
+	PreviousContext string    `json:"previousContext,omitempty"`
+	Version         string    `json:"version,omitempty"`
+}
+
+// String formats the record as a single human-readable line
+func (r Record) String() string {
+	var b strings.Builder
+	b.WriteString(r.Time.Format("2006-01-02 15:04:05"))
+	b.WriteString("  ")
+
+	switch r.Type {
+	case recordSessionStart:
+		fmt.Fprintf(&b, "session started (version %s)", r.Version)
+	case recordSessionEnd:
+		b.WriteString("session ended")
+	case string(portforward.EventContextChanged):
+		if r.PreviousContext == "" {
+			fmt.Fprintf(&b, "context %s", r.Context)
+		} else {
+			fmt.Fprintf(&b, "context changed %s -> %s", r.PreviousContext, r.Context)
+		}
+	case string(portforward.EventServiceStateChanged):
+		previous := r.PreviousStatus
+		if previous == "" {
+			previous = "-"
+		}
+		fmt.Fprintf(&b, "%s: %s -> %s", r.Service, previous, r.Status)
+	case string(portforward.EventServiceRestarted):
+		fmt.Fprintf(&b, "%s: restarted (restart #%d)", r.Service, r.RestartCount)
+	case string(portforward.EventServiceStarted):
+		fmt.Fprintf(&b, "%s: started on port %d", r.Service, r.LocalPort)
+	case string(portforward.EventServiceStartFailed):
+		fmt.Fprintf(&b, "%s: failed to start", r.Service)
+	case string(portforward.EventPortReassigned):
+		fmt.Fprintf(&b, "%s: port %d in use, reassigned to %d", r.Service, r.ConfiguredPort, r.LocalPort)
+	default:
+		fmt.Fprintf(&b, "%s %s", r.Type, r.Service)
+	}
+
+	if r.Error != "" {
+		fmt.Fprintf(&b, ": %s", r.Error)
+	}
+	return b.String()
+}
+
+// Dir returns the directory holding session journals
+func Dir() (string, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get cache directory: %w", err)
+	}
+	return filepath.Join(cacheDir, "kportforward", "sessions"), nil
+}
+
+// Journal appends manager events of one session to a JSON lines file
+type Journal struct {
+	path  string
+	file  *os.File
+	mutex sync.Mutex
+}
+
+// Open starts a new session journal in dir and records the session start
+func Open(dir, version string) (*Journal, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create session directory: %w", err)
+	}
+	Prune(dir, MaxSessions-1)
+
+	started := time.Now()
+	path := filepath.Join(dir, started.Format(sessionTimeFormat)+".jsonl")
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open session journal: %w", err)
+	}
+
+	j := &Journal{path: path, file: file}
+	if err := j.write(Record{Time: started, Type: recordSessionStart, Version: version}); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return j, nil
+}
+
+// Path returns the journal file path
+func (j *Journal) Path() string {
+	return j.path
+}
+
+// HandleEvent records service and context events; status snapshots and startup
+// progress are skipped because state changes already cover them
+func (j *Journal) HandleEvent(event portforward.Event) {
+	if event.Type == portforward.EventStatusUpdated || event.Type.IsProgress() {
+		return
+	}
+
+	record := Record{
+		Time:            event.Timestamp,
+		Type:            string(event.Type),
+		Service:         event.Service,
+		Status:          event.Status.Status,
+		PreviousStatus:  event.PreviousStatus,
+		Error:           event.Error,
+		DurationMs:      float64(event.Duration) / float64(time.Millisecond),
+		LocalPort:       event.Status.LocalPort,
+		ConfiguredPort:  event.Status.ConfiguredPort,
+		RestartCount:    event.Status.RestartCount,
+		Context:         event.Context,
+		PreviousContext: event.PreviousContext,
+	}
+	j.write(record)
+}
+
+// Close records the session end and closes the file
+func (j *Journal) Close() error {
+	j.write(Record{Time: time.Now(), Type: recordSessionEnd})
+
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	if j.file == nil {
+		return nil
+	}
+	err := j.file.Close()
+	j.file = nil
+	return err
+}
+
+// write appends a record as one line
+func (j *Journal) write(record Record) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode journal record: %w", err)
+	}
+
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	if j.file == nil {
+		return nil
+	}
+	if _, err := j.file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write session journal: %w", err)
+	}
+	return nil
+}
+
+// Session summarizes a session journal
+type Session struct {
+	ID       string // Journal file name without extension, e.g. 20240101-090000
+	Path     string
+	Start    time.Time
+	End      time.Time // Zero if the session is running or didn't shut down cleanly
+	Restarts int
+	Failures int // Transitions into Failed
+}
+
+// List returns the sessions in dir, oldest first
+func List(dir string) ([]Session, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.jsonl"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+
+	sessions := make([]Session, 0, len(paths))
+	for _, path := range paths {
+		records, err := Read(path)
+		if err != nil {
+			continue
+		}
+		session := Session{ID: strings.TrimSuffix(filepath.Base(path), ".jsonl"), Path: path}
+		for _, record := range records {
+			switch record.Type {
+			case recordSessionStart:
+				session.Start = record.Time
+			case recordSessionEnd:
+				session.End = record.Time
+			case string(portforward.EventServiceRestarted):
+				session.Restarts++
+			case string(portforward.EventServiceStateChanged):
+				if record.Status == "Failed" {
+					session.Failures++
+				}
+			}
+		}
+		sessions = append(sessions, session)
+	}
+	return sessions, nil
+}
+
+// Find returns the session with the given ID, or the latest one when id is empty
+func Find(dir, id string) (Session, error) {
+	sessions, err := List(dir)
+	if err != nil {
+		return Session{}, err
+	}
+	if len(sessions) == 0 {
+		return Session{}, fmt.Errorf("no sessions recorded in %s", dir)
+	}
+	if id == "" {
+		return sessions[len(sessions)-1], nil
+	}
+	for _, session := range sessions {
+		if session.ID == id {
+			return session, nil
+		}
+	}
+	return Session{}, fmt.Errorf("session %s not found", id)
+}
+
+// Read parses a session journal; a truncated last line is ignored
+func Read(path string) ([]Record, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open session journal: %w", err)
+	}
+	defer file.Close()
+
+	var records []Record
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var record Record
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			continue
+		}
+		records = append(records, record)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read session journal: %w", err)
+	}
+	return records, nil
+}
+
+// Prune removes all but the newest keep journals in dir
+func Prune(dir string, keep int) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.jsonl"))
+	if err != nil || len(paths) <= keep {
+		return
+	}
+	sort.Strings(paths)
+	for _, path := range paths[:len(paths)-keep] {
+		os.Remove(path)
+	}
+}
@@ -0,0 +1,81 @@
+package journal
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/portforward"
+)
+
+func TestJournalRecordsSession(t *testing.T) {
+	dir := t.TempDir()
+
+	j, err := Open(dir, "v1.2.3")
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+
+	now := time.Now()
+	j.HandleEvent(portforward.Event{Type: portforward.EventStatusUpdated, Timestamp: now})
+	j.HandleEvent(portforward.Event{Type: portforward.EventServiceStateChanged, Timestamp: now,
+		Service: "api", Status: config.ServiceStatus{Status: "Failed"}, PreviousStatus: "Running",
+		Error: "connection refused"})
+	j.HandleEvent(portforward.Event{Type: portforward.EventServiceRestarted, Timestamp: now,
+		Service: "api", Status: config.ServiceStatus{Status: "Running", RestartCount: 1}})
+	j.HandleEvent(portforward.Event{Type: portforward.EventContextChanged, Timestamp: now,
+		Context: "prod", PreviousContext: "dev"})
+	if err := j.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	records, err := Read(j.Path())
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if len(records) != 5 {
+		t.Fatalf("Expected 5 records (status snapshots skipped), got %d", len(records))
+	}
+	if got := records[1].String(); !strings.HasSuffix(got, "api: Running -> Failed: connection refused") {
+		t.Errorf("Unexpected state change line %q", got)
+	}
+	if got := records[3].String(); !strings.HasSuffix(got, "context changed dev -> prod") {
+		t.Errorf("Unexpected context change line %q", got)
+	}
+
+	session, err := Find(dir, "")
+	if err != nil {
+		t.Fatalf("Find failed: %v", err)
+	}
+	if session.Restarts != 1 || session.Failures != 1 || session.End.IsZero() {
+		t.Errorf("Unexpected session summary %+v", session)
+	}
+	if _, err := Find(dir, "19990101-000000"); err == nil {
+		t.Error("Expected unknown session to fail")
+	}
+}
+
+func TestPortReassignedRecord(t *testing.T) {
+	record := Record{Time: time.Now(), Type: string(portforward.EventPortReassigned), Service: "api",
+		LocalPort: 8081, ConfiguredPort: 8080}
+	if got := record.String(); !strings.HasSuffix(got, "api: port 8080 in use, reassigned to 8081") {
+		t.Errorf("Unexpected reassignment line %q", got)
+	}
+}
+
+func TestPrune(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"20240101-090000", "20240102-090000", "20240103-090000"} {
+		os.WriteFile(filepath.Join(dir, name+".jsonl"), nil, 0600)
+	}
+
+	Prune(dir, 2)
+
+	remaining, _ := filepath.Glob(filepath.Join(dir, "*.jsonl"))
+	if len(remaining) != 2 || filepath.Base(remaining[0]) != "20240102-090000.jsonl" {
+		t.Errorf("Expected the two newest journals to remain, got %v", remaining)
+	}
+}
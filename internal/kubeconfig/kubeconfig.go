@@ -0,0 +1,111 @@
+package kubeconfig
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+// ContextInfo describes a context of the merged kubeconfig
+type ContextInfo struct {
+	Name      string
+	Cluster   string
+	Namespace string
+	User      string
+}
+
+// Set holds the contexts of the merged kubeconfig
+type Set struct {
+	Current  string
+	Contexts map[string]ContextInfo
+}
+
+// Apply points kubectl at the given kubeconfig files by setting KUBECONFIG,
+// which kubectl merges in order (earlier files win on conflicts)
+func Apply(paths []string) error {
+	if len(paths) == 0 {
+		return nil
+	}
+
+	expanded := make([]string, 0, len(paths))
+	for _, path := range paths {
+		path, err := utils.ExpandPath(path)
+		if err != nil {
+			return err
+		}
+		if _, err := os.Stat(path); err != nil {
+			return fmt.Errorf("kubeconfig %s: %w", path, err)
+		}
+		expanded = append(expanded, path)
+	}
+
+	return os.Setenv("KUBECONFIG", strings.Join(expanded, string(os.PathListSeparator)))
+}
+
+// Load reads a kubeconfig file through kubectl; an empty path reads the merged
+// kubeconfig kubectl uses by default
+func Load(path string) (*Set, error) {
+	args := []string{"config", "view", "-o", "json"}
+	if path != "" {
+		args = append(args, "--kubeconfig", path)
+	}
+	output, err := exec.Command("kubectl", args...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read kubeconfig: %w", err)
+	}
+	return parse(output)
+}
+
+// rawConfig holds the parts of `kubectl config view -o json` we need
+type rawConfig struct {
+	CurrentContext string `json:"current-context"`
+	Contexts       []struct {
+		Name    string `json:"name"`
+		Context struct {
+			Cluster   string `json:"cluster"`
+			Namespace string `json:"namespace"`
+			User      string `json:"user"`
+		} `json:"context"`
+	} `json:"contexts"`
+}
+
+// parse builds a Set from kubectl's JSON output
+func parse(data []byte) (*Set, error) {
+	var raw rawConfig
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse kubeconfig: %w", err)
+	}
+
+	set := &Set{
+		Current:  raw.CurrentContext,
+		Contexts: make(map[string]ContextInfo, len(raw.Contexts)),
+	}
+	for _, context := range raw.Contexts {
+		set.Contexts[context.Name] = ContextInfo{
+			Name:      context.Name,
+			Cluster:   context.Context.Cluster,
+			Namespace: context.Context.Namespace,
+			User:      context.Context.User,
+		}
+	}
+	return set, nil
+}
+
+// Resolve returns the named context, or the current context when name is empty
+func (s *Set) Resolve(name string) (ContextInfo, error) {
+	if name == "" {
+		name = s.Current
+	}
+	if name == "" {
+		return ContextInfo{}, fmt.Errorf("no current context in kubeconfig")
+	}
+	info, exists := s.Contexts[name]
+	if !exists {
+		return ContextInfo{Name: name}, fmt.Errorf("context %q not found in kubeconfig", name)
+	}
+	return info, nil
+}
@@ -0,0 +1,60 @@
+package kubeconfig
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const mergedConfig = `{
+  "current-context": "dev",
+  "contexts": [
+    {"name": "dev", "context": {"cluster": "kind-dev", "namespace": "default", "user": "me"}},
+    {"name": "prod-eu", "context": {"cluster": "gke_prod_europe-west1_main", "user": "me"}}
+  ]
+}`
+
+func TestResolveContexts(t *testing.T) {
+	set, err := parse([]byte(mergedConfig))
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	current, err := set.Resolve("")
+	if err != nil || current.Name != "dev" || current.Cluster != "kind-dev" {
+		t.Errorf("Expected current context dev on kind-dev, got %+v (%v)", current, err)
+	}
+
+	prod, err := set.Resolve("prod-eu")
+	if err != nil || prod.Cluster != "gke_prod_europe-west1_main" {
+		t.Errorf("Unexpected prod-eu context: %+v (%v)", prod, err)
+	}
+
+	if _, err := set.Resolve("missing"); err == nil {
+		t.Error("Expected error for an unknown context")
+	}
+}
+
+func TestApplySetsKubeconfig(t *testing.T) {
+	dir := t.TempDir()
+	first := filepath.Join(dir, "dev.yaml")
+	second := filepath.Join(dir, "prod.yaml")
+	for _, path := range []string{first, second} {
+		if err := os.WriteFile(path, []byte("apiVersion: v1\nkind: Config\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	t.Setenv("KUBECONFIG", "")
+	if err := Apply([]string{first, second}); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if got := os.Getenv("KUBECONFIG"); got != strings.Join([]string{first, second}, string(os.PathListSeparator)) {
+		t.Errorf("Unexpected KUBECONFIG: %q", got)
+	}
+
+	if err := Apply([]string{filepath.Join(dir, "missing.yaml")}); err == nil {
+		t.Error("Expected error for a missing kubeconfig")
+	}
+}
@@ -0,0 +1,191 @@
+package mdns
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"sync"
+
+	mdnsserver "github.com/hashicorp/mdns"
+	"github.com/miekg/dns"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/portforward"
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+// Advertiser announces running, LAN-exposed forwards via mDNS/Bonjour
+type Advertiser struct {
+	configs  func() map[string]config.Service
+	domain   string
+	hostName string
+	logger   *utils.Logger
+	server   *mdnsserver.Server
+
+	// Advertised services by name, served from a single zone
+	services map[string]*mdnsserver.MDNSService
+	records  map[string]string // What each service was advertised with, see advertisement
+	mutex    sync.RWMutex
+}
+
+// NewAdvertiser creates an advertiser for the services returned by configs
+func NewAdvertiser(configs func() map[string]config.Service, cfg config.MDNSConfig, logger *utils.Logger) *Advertiser {
+	domain := strings.Trim(cfg.Domain, ".")
+	if domain == "" {
+		domain = "local"
+	}
+
+	return &Advertiser{
+		configs:  configs,
+		domain:   domain + ".",
+		hostName: localHostName(domain),
+		logger:   logger,
+		services: make(map[string]*mdnsserver.MDNSService),
+		records:  make(map[string]string),
+	}
+}
+
+// Start begins answering mDNS queries
+func (a *Advertiser) Start() error {
+	server, err := mdnsserver.NewServer(&mdnsserver.Config{Zone: a})
+	if err != nil {
+		return fmt.Errorf("failed to start mDNS responder: %w", err)
+	}
+	a.server = server
+	return nil
+}
+
+// Stop withdraws all advertisements
+func (a *Advertiser) Stop() error {
+	if a.server == nil {
+		return nil
+	}
+	return a.server.Shutdown()
+}
+
+// Records implements the mdns.Zone interface across all advertised services
+func (a *Advertiser) Records(q dns.Question) []dns.RR {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+
+	var records []dns.RR
+	for _, service := range a.services {
+		records = append(records, service.Records(q)...)
+	}
+	return records
+}
+
+// HandleEvent keeps advertisements in sync with the running services
+func (a *Advertiser) HandleEvent(event portforward.Event) {
+	if event.Type != portforward.EventStatusUpdated {
+		return
+	}
+
+	configs := a.configs()
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	for name, status := range event.Snapshot {
+		serviceConfig, exists := configs[name]
+		ips := advertisedIPs(serviceConfig.BindAddress)
+		advertise := exists && status.Status == "Running" && len(ips) > 0
+
+		if !advertise {
+			if _, advertised := a.services[name]; advertised {
+				a.logger.Info("Withdrew mDNS advertisement for %s", name)
+				delete(a.services, name)
+				delete(a.records, name)
+			}
+			continue
+		}
+
+		// Re-advertise when the local port was reassigned or the service was edited
+		record := advertisement(serviceConfig, status.LocalPort)
+		if a.records[name] == record {
+			continue
+		}
+
+		service, err := mdnsserver.NewMDNSService(name, serviceType(serviceConfig.Type), a.domain,
+			a.hostName, status.LocalPort, ips, txtRecords(serviceConfig))
+		if err != nil {
+			a.logger.Warn("Failed to advertise %s via mDNS: %v", name, err)
+			continue
+		}
+
+		a.services[name] = service
+		a.records[name] = record
+		a.logger.Info("Advertising %s.%s%s on port %d", name, serviceType(serviceConfig.Type), a.domain, status.LocalPort)
+	}
+}
+
+// advertisement summarizes what a service is advertised with
+func advertisement(service config.Service, port int) string {
+	return fmt.Sprintf("%d %s %s", port, service.BindAddress, strings.Join(txtRecords(service), " "))
+}
+
+// serviceType maps a kportforward service type to a DNS-SD service type
+func serviceType(serviceType string) string {
+	if serviceType == "rpc" {
+		return "_grpc._tcp"
+	}
+	return "_http._tcp"
+}
+
+// txtRecords builds DNS-SD TXT records describing a service
+func txtRecords(service config.Service) []string {
+	txt := []string{"type=" + service.Type}
+	if service.Type == "rest" && service.APIPath != "" {
+		txt = append(txt, "path="+service.APIPath)
+	} else if service.Type == "web" {
+		txt = append(txt, "path=/")
+	}
+	return txt
+}
+
+// advertisedIPs returns the addresses reachable from the LAN for a comma-separated
+// list of bind addresses, or nil when the forward only listens on loopback
+func advertisedIPs(bindAddress string) []net.IP {
+	var ips []net.IP
+	for _, address := range utils.SplitAddresses(bindAddress) {
+		ip := net.ParseIP(address)
+		if ip == nil || ip.IsLoopback() {
+			continue
+		}
+		if ip.IsUnspecified() {
+			return interfaceIPs()
+		}
+		ips = append(ips, ip)
+	}
+	return ips
+}
+
+// interfaceIPs returns the non-loopback unicast addresses of this machine
+func interfaceIPs() []net.IP {
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return nil
+	}
+
+	var ips []net.IP
+	for _, addr := range addrs {
+		ipNet, ok := addr.(*net.IPNet)
+		if !ok || ipNet.IP.IsLoopback() || ipNet.IP.IsLinkLocalUnicast() {
+			continue
+		}
+		ips = append(ips, ipNet.IP)
+	}
+	return ips
+}
+
+// localHostName returns this machine's mDNS host name (e.g. "laptop.local.")
+func localHostName(domain string) string {
+	hostName, err := os.Hostname()
+	if err != nil || hostName == "" {
+		hostName = "kportforward"
+	}
+	// Only the first label of a fully-qualified host name is meaningful on the LAN
+	hostName = strings.SplitN(hostName, ".", 2)[0]
+	return fmt.Sprintf("%s.%s.", hostName, domain)
+}
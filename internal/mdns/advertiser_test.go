@@ -0,0 +1,110 @@
+package mdns
+
+import (
+	"testing"
+
+	"github.com/miekg/dns"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/portforward"
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+func newTestAdvertiser() *Advertiser {
+	configs := map[string]config.Service{
+		"web":     {Type: "web", BindAddress: "192.168.1.20"},
+		"backend": {Type: "rpc", BindAddress: "192.168.1.20"},
+		"private": {Type: "web"},
+	}
+	return NewAdvertiser(func() map[string]config.Service { return configs }, config.MDNSConfig{Enabled: true}, utils.NewLogger(utils.LevelError))
+}
+
+func ptrTargets(a *Advertiser, serviceType string) []string {
+	var targets []string
+	for _, rr := range a.Records(dns.Question{Name: serviceType + ".local.", Qtype: dns.TypePTR, Qclass: dns.ClassINET}) {
+		if ptr, ok := rr.(*dns.PTR); ok {
+			targets = append(targets, ptr.Ptr)
+		}
+	}
+	return targets
+}
+
+func TestAdvertiserTracksRunningServices(t *testing.T) {
+	advertiser := newTestAdvertiser()
+
+	advertiser.HandleEvent(portforward.Event{
+		Type: portforward.EventStatusUpdated,
+		Snapshot: map[string]config.ServiceStatus{
+			"web":     {Status: "Running", LocalPort: 8080},
+			"backend": {Status: "Failed", LocalPort: 9090},
+			"private": {Status: "Running", LocalPort: 8081},
+		},
+	})
+
+	// Only running services bound to a LAN address are advertised
+	http := ptrTargets(advertiser, "_http._tcp")
+	if len(http) != 1 || http[0] != "web._http._tcp.local." {
+		t.Errorf("Expected only web to be advertised, got %v", http)
+	}
+	if grpc := ptrTargets(advertiser, "_grpc._tcp"); len(grpc) != 0 {
+		t.Errorf("Expected failed backend not to be advertised, got %v", grpc)
+	}
+
+	advertiser.HandleEvent(portforward.Event{
+		Type: portforward.EventStatusUpdated,
+		Snapshot: map[string]config.ServiceStatus{
+			"web":     {Status: "Failed", LocalPort: 8080},
+			"backend": {Status: "Running", LocalPort: 9090},
+		},
+	})
+
+	if http := ptrTargets(advertiser, "_http._tcp"); len(http) != 0 {
+		t.Errorf("Expected web advertisement to be withdrawn, got %v", http)
+	}
+	if grpc := ptrTargets(advertiser, "_grpc._tcp"); len(grpc) != 1 {
+		t.Errorf("Expected backend to be advertised, got %v", grpc)
+	}
+}
+
+func TestAdvertiserFollowsEditedConfigs(t *testing.T) {
+	configs := map[string]config.Service{"web": {Type: "web", BindAddress: "192.168.1.20"}}
+	advertiser := NewAdvertiser(func() map[string]config.Service { return configs }, config.MDNSConfig{Enabled: true}, utils.NewLogger(utils.LevelError))
+	running := portforward.Event{
+		Type:     portforward.EventStatusUpdated,
+		Snapshot: map[string]config.ServiceStatus{"web": {Status: "Running", LocalPort: 8080}},
+	}
+
+	advertiser.HandleEvent(running)
+	if http := ptrTargets(advertiser, "_http._tcp"); len(http) != 1 {
+		t.Fatalf("Expected web to be advertised, got %v", http)
+	}
+
+	// Changing the type re-advertises under the new service type
+	configs = map[string]config.Service{"web": {Type: "rpc", BindAddress: "192.168.1.20"}}
+	advertiser.HandleEvent(running)
+	if grpc := ptrTargets(advertiser, "_grpc._tcp"); len(grpc) != 1 {
+		t.Errorf("Expected web to be re-advertised as gRPC, got %v", grpc)
+	}
+
+	// Binding it to loopback withdraws it
+	configs = map[string]config.Service{"web": {Type: "rpc"}}
+	advertiser.HandleEvent(running)
+	if grpc := ptrTargets(advertiser, "_grpc._tcp"); len(grpc) != 0 {
+		t.Errorf("Expected the advertisement to be withdrawn, got %v", grpc)
+	}
+}
+
+func TestAdvertisedIPs(t *testing.T) {
+	if ips := advertisedIPs(""); ips != nil {
+		t.Errorf("Expected no addresses for default bind address, got %v", ips)
+	}
+	if ips := advertisedIPs("127.0.0.1"); ips != nil {
+		t.Errorf("Expected no addresses for loopback, got %v", ips)
+	}
+	if ips := advertisedIPs("10.0.0.5"); len(ips) != 1 || ips[0].String() != "10.0.0.5" {
+		t.Errorf("Expected the bind address itself, got %v", ips)
+	}
+	if ips := advertisedIPs("127.0.0.1,::1,10.0.0.5"); len(ips) != 1 || ips[0].String() != "10.0.0.5" {
+		t.Errorf("Expected only the LAN address of a list, got %v", ips)
+	}
+}
@@ -0,0 +1,107 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/victorkazakov/kportforward/internal/portforward"
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+// KindStateChange is the event of a state transition that is neither a failure nor a recovery
+const KindStateChange = "state_change"
+
+// commandTimeout bounds a single run of the state-change command
+const commandTimeout = 10 * time.Second
+
+// CommandNotifier runs a local command on every service state transition, with the
+// transition as a WebhookPayload on its stdin
+type CommandNotifier struct {
+	command string
+	logger  *utils.Logger
+	queue   chan WebhookPayload
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+// NewCommandNotifier creates a notifier running the given shell command
+func NewCommandNotifier(command string, logger *utils.Logger) *CommandNotifier {
+	return &CommandNotifier{
+		command: command,
+		logger:  logger,
+		queue:   make(chan WebhookPayload, 64),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+}
+
+// Start begins the dispatcher goroutine; commands run one at a time, in event order
+func (cn *CommandNotifier) Start() {
+	go func() {
+		defer close(cn.done)
+		for {
+			select {
+			case payload := <-cn.queue:
+				cn.run(payload)
+			case <-cn.stop:
+				for {
+					select {
+					case payload := <-cn.queue:
+						cn.run(payload)
+					default:
+						return
+					}
+				}
+			}
+		}
+	}()
+}
+
+// Stop runs the pending commands and stops the dispatcher
+func (cn *CommandNotifier) Stop() {
+	close(cn.stop)
+	<-cn.done
+}
+
+// HandleEvent queues a run of the command for service state transitions and context changes
+func (cn *CommandNotifier) HandleEvent(event portforward.Event) {
+	kind, ok := classifyEvent(event)
+	if !ok {
+		if event.Type != portforward.EventServiceStateChanged || event.PreviousStatus == event.Status.Status {
+			return
+		}
+		kind = KindStateChange
+	}
+
+	select {
+	case cn.queue <- buildPayload(kind, event):
+	default:
+		cn.logger.Warn("State-change command queue full, dropping %s event for %s", kind, event.Service)
+	}
+}
+
+// run runs the command with the payload on its stdin
+func (cn *CommandNotifier) run(payload WebhookPayload) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		cn.logger.Error("Failed to encode state-change payload: %v", err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), commandTimeout)
+	defer cancel()
+
+	cmd := utils.ShellCommand(ctx, cn.command)
+	cmd.Stdin = bytes.NewReader(append(data, '\n'))
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			cn.logger.Warn("State-change command timed out after %s", commandTimeout)
+			return
+		}
+		cn.logger.Warn("State-change command failed: %v: %s", err, strings.TrimSpace(string(output)))
+	}
+}
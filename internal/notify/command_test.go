@@ -0,0 +1,53 @@
+package notify
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/victorkazakov/kportforward/internal/portforward"
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+func TestCommandNotifierPassesPayloadOnStdin(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+
+	out := filepath.Join(t.TempDir(), "events.jsonl")
+	notifier := NewCommandNotifier("cat >> "+out, utils.NewLogger(utils.LevelError))
+	notifier.Start()
+
+	notifier.HandleEvent(stateChange("api", "Starting", "Running"))
+	notifier.HandleEvent(stateChange("api", "Running", "Failed"))
+	notifier.HandleEvent(stateChange("api", "Failed", "Failed")) // Not a transition
+	notifier.HandleEvent(portforward.Event{Type: portforward.EventStatusUpdated})
+	notifier.Stop()
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("Failed to read command output: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 payloads, got %d: %s", len(lines), data)
+	}
+
+	expected := []struct{ event, previous, status string }{
+		{KindStateChange, "Starting", "Running"},
+		{KindFailure, "Running", "Failed"},
+	}
+	for i, line := range lines {
+		var payload WebhookPayload
+		if err := json.Unmarshal([]byte(line), &payload); err != nil {
+			t.Fatalf("Failed to decode payload %q: %v", line, err)
+		}
+		if payload.Event != expected[i].event || payload.PreviousStatus != expected[i].previous ||
+			payload.Status != expected[i].status || payload.Service != "api" {
+			t.Errorf("Payload %d: unexpected %+v", i, payload)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package notify
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/portforward"
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+// Notification is a single message to show to the user
+type Notification struct {
+	Title   string
+	Message string
+}
+
+// DesktopNotifier sends native OS notifications for service failures and recoveries
+type DesktopNotifier struct {
+	logger   *utils.Logger
+	configs  func() map[string]config.Service
+	queue    chan Notification
+	sendFunc func(Notification) error
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// NewDesktopNotifier creates a desktop notifier. Services with
+// muteNotifications set in their current configuration, as returned by configs,
+// are ignored.
+func NewDesktopNotifier(configs func() map[string]config.Service, logger *utils.Logger) *DesktopNotifier {
+	return &DesktopNotifier{
+		logger:   logger,
+		configs:  configs,
+		queue:    make(chan Notification, 16),
+		sendFunc: sendDesktopNotification,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// Start begins delivering queued notifications
+func (dn *DesktopNotifier) Start() {
+	go func() {
+		defer close(dn.done)
+		for {
+			select {
+			case notification := <-dn.queue:
+				dn.send(notification)
+			case <-dn.stop:
+				// Deliver whatever is still pending before exiting
+				for {
+					select {
+					case notification := <-dn.queue:
+						dn.send(notification)
+					default:
+						return
+					}
+				}
+			}
+		}
+	}()
+}
+
+// Stop delivers pending notifications and stops the notifier
+func (dn *DesktopNotifier) Stop() {
+	close(dn.stop)
+	<-dn.done
+}
+
+// send delivers a single notification, logging failures
+func (dn *DesktopNotifier) send(notification Notification) {
+	if err := dn.sendFunc(notification); err != nil {
+		dn.logger.Warn("Failed to send desktop notification: %v", err)
+	}
+}
+
+// HandleEvent turns service state transitions into notifications
+func (dn *DesktopNotifier) HandleEvent(event portforward.Event) {
+	notification, ok := notificationForTransition(event)
+	if !ok {
+		return
+	}
+	if serviceConfig, exists := dn.configs()[event.Service]; exists && serviceConfig.MuteNotifications {
+		return
+	}
+
+	// Never block the manager; drop the notification if the queue is full
+	select {
+	case dn.queue <- notification:
+	default:
+		dn.logger.Warn("Desktop notification queue full, dropping notification for %s", event.Service)
+	}
+}
+
+// notificationForTransition builds a notification for transitions worth telling the user about
+func notificationForTransition(event portforward.Event) (Notification, bool) {
+	kind, ok := classifyEvent(event)
+	if !ok {
+		return Notification{}, false
+	}
+
+	switch kind {
+	case KindFailure:
+		message := fmt.Sprintf("%s is %s", event.Service, strings.ToLower(event.Status.Status))
+		if event.Error != "" {
+			message += ": " + event.Error
+		}
+		return Notification{Title: "kportforward: service down", Message: message}, true
+
+	case KindRecovery:
+		message := fmt.Sprintf("%s recovered on localhost:%d", event.Service, event.Status.LocalPort)
+		return Notification{Title: "kportforward: service recovered", Message: message}, true
+	}
+
+	return Notification{}, false
+}
+
+// sendDesktopNotification shows a notification using the platform's native mechanism
+func sendDesktopNotification(notification Notification) error {
+	var cmd *exec.Cmd
+
+	switch runtime.GOOS {
+	case "darwin":
+		script := fmt.Sprintf("display notification %q with title %q", notification.Message, notification.Title)
+		cmd = exec.Command("osascript", "-e", script)
+	case "windows":
+		script := fmt.Sprintf(`[void][System.Reflection.Assembly]::LoadWithPartialName('System.Windows.Forms');`+
+			`$n = New-Object System.Windows.Forms.NotifyIcon;`+
+			`$n.Icon = [System.Drawing.SystemIcons]::Information;`+
+			`$n.Visible = $true;`+
+			`$n.ShowBalloonTip(5000, '%s', '%s', 'Info');`+
+			`Start-Sleep -Seconds 6; $n.Dispose()`,
+			escapePowerShell(notification.Title), escapePowerShell(notification.Message))
+		cmd = exec.Command("powershell", "-NoProfile", "-NonInteractive", "-Command", script)
+	default: // Linux and other Unix-like systems
+		cmd = exec.Command("notify-send", "--app-name=kportforward", notification.Title, notification.Message)
+	}
+
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// escapePowerShell escapes a string for use inside single-quoted PowerShell literals
+func escapePowerShell(s string) string {
+	return strings.ReplaceAll(s, "'", "''")
+}
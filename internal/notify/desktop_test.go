@@ -0,0 +1,72 @@
+package notify
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/portforward"
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+func stateChange(service, previous, current string) portforward.Event {
+	return portforward.Event{
+		Type:           portforward.EventServiceStateChanged,
+		Service:        service,
+		PreviousStatus: previous,
+		Status:         config.ServiceStatus{Name: service, Status: current, LocalPort: 8080},
+	}
+}
+
+func TestNotificationForTransition(t *testing.T) {
+	tests := []struct {
+		previous string
+		current  string
+		expected bool
+	}{
+		{"Running", "Failed", true},
+		{"Starting", "Cooldown", true},
+		{"Failed", "Cooldown", false},
+		{"Cooldown", "Running", true},
+		{"Running", "Login", true},
+		{"Login", "Running", true},
+		{"Running", "Cluster Unreachable", true},
+		{"Failed", "Cluster Unreachable", false},
+		{"Starting", "Running", false},
+		{"", "Running", false},
+	}
+
+	for _, test := range tests {
+		_, ok := notificationForTransition(stateChange("svc", test.previous, test.current))
+		if ok != test.expected {
+			t.Errorf("Transition %q -> %q: expected notify=%v, got %v",
+				test.previous, test.current, test.expected, ok)
+		}
+	}
+}
+
+func TestDesktopNotifierMuting(t *testing.T) {
+	configs := map[string]config.Service{
+		"noisy": {MuteNotifications: true},
+		"quiet": {},
+	}
+	notifier := NewDesktopNotifier(func() map[string]config.Service { return configs }, utils.NewLogger(utils.LevelError))
+
+	var mutex sync.Mutex
+	var sent []Notification
+	notifier.sendFunc = func(n Notification) error {
+		mutex.Lock()
+		defer mutex.Unlock()
+		sent = append(sent, n)
+		return nil
+	}
+
+	notifier.Start()
+	notifier.HandleEvent(stateChange("noisy", "Running", "Failed"))
+	notifier.HandleEvent(stateChange("quiet", "Running", "Failed"))
+	notifier.Stop()
+
+	if len(sent) != 1 {
+		t.Fatalf("Expected 1 notification, got %d", len(sent))
+	}
+}
@@ -0,0 +1,38 @@
+package notify
+
+import (
+	"github.com/victorkazakov/kportforward/internal/portforward"
+)
+
+// Notification kinds derived from manager events
+const (
+	KindFailure       = "failure"
+	KindRecovery      = "recovery"
+	KindContextChange = "context_change"
+)
+
+// classifyEvent maps a manager event to a notification kind, if it is one worth reporting
+func classifyEvent(event portforward.Event) (string, bool) {
+	switch event.Type {
+	case portforward.EventContextChanged:
+		return KindContextChange, true
+
+	case portforward.EventServiceStateChanged:
+		wasDown := isDownState(event.PreviousStatus)
+		isDown := isDownState(event.Status.Status)
+
+		if isDown && !wasDown {
+			return KindFailure, true
+		}
+		if wasDown && event.Status.Status == "Running" {
+			return KindRecovery, true
+		}
+	}
+
+	return "", false
+}
+
+// isDownState reports whether a status represents a failed service
+func isDownState(status string) bool {
+	return status == "Failed" || status == "Cooldown" || status == "Login" || status == "Cluster Unreachable"
+}
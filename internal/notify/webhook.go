@@ -0,0 +1,219 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/portforward"
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+// WebhookPayload is the JSON document posted to generic webhooks
+type WebhookPayload struct {
+	Event           string    `json:"event"`
+	Service         string    `json:"service,omitempty"`
+	Status          string    `json:"status,omitempty"`
+	PreviousStatus  string    `json:"previousStatus,omitempty"`
+	Error           string    `json:"error,omitempty"`
+	ErrorCategory   string    `json:"errorCategory,omitempty"` // e.g. lost-connection or forbidden
+	Fatal           bool      `json:"fatal,omitempty"`         // The failure needs fixing rather than a restart
+	LocalPort       int       `json:"localPort,omitempty"`
+	RestartCount    int       `json:"restartCount,omitempty"`
+	Context         string    `json:"context,omitempty"`
+	PreviousContext string    `json:"previousContext,omitempty"`
+	Suppressed      int       `json:"suppressed,omitempty"` // Similar events skipped by debouncing
+	Timestamp       time.Time `json:"timestamp"`
+}
+
+// slackPayload is the message format accepted by Slack incoming webhooks
+type slackPayload struct {
+	Text string `json:"text"`
+}
+
+// webhookDelivery is a payload queued for a specific webhook
+type webhookDelivery struct {
+	webhook config.WebhookConfig
+	payload WebhookPayload
+}
+
+// WebhookNotifier posts service state changes to configured webhooks
+type WebhookNotifier struct {
+	webhooks []config.WebhookConfig
+	logger   *utils.Logger
+	client   *http.Client
+	queue    chan webhookDelivery
+	stop     chan struct{}
+	done     chan struct{}
+
+	// Debounce state
+	lastSent   map[string]time.Time
+	suppressed map[string]int
+	mutex      sync.Mutex
+}
+
+// NewWebhookNotifier creates a notifier for the given webhooks
+func NewWebhookNotifier(webhooks []config.WebhookConfig, logger *utils.Logger) *WebhookNotifier {
+	return &WebhookNotifier{
+		webhooks: webhooks,
+		logger:   logger,
+		client: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+		queue:      make(chan webhookDelivery, 64),
+		stop:       make(chan struct{}),
+		done:       make(chan struct{}),
+		lastSent:   make(map[string]time.Time),
+		suppressed: make(map[string]int),
+	}
+}
+
+// Start begins the dispatcher goroutine
+func (wn *WebhookNotifier) Start() {
+	go func() {
+		defer close(wn.done)
+		for {
+			select {
+			case delivery := <-wn.queue:
+				wn.deliver(delivery)
+			case <-wn.stop:
+				// Flush whatever is still pending before exiting
+				for {
+					select {
+					case delivery := <-wn.queue:
+						wn.deliver(delivery)
+					default:
+						return
+					}
+				}
+			}
+		}
+	}()
+}
+
+// Stop flushes pending deliveries and stops the dispatcher
+func (wn *WebhookNotifier) Stop() {
+	close(wn.stop)
+	<-wn.done
+}
+
+// HandleEvent queues payloads for every webhook interested in the event
+func (wn *WebhookNotifier) HandleEvent(event portforward.Event) {
+	kind, ok := classifyEvent(event)
+	if !ok {
+		return
+	}
+
+	wn.mutex.Lock()
+	defer wn.mutex.Unlock()
+
+	for i, webhook := range wn.webhooks {
+		if !wantsEvent(webhook, kind) {
+			continue
+		}
+
+		// Debounce repeated events of the same kind for the same service
+		key := fmt.Sprintf("%d/%s/%s", i, kind, event.Service)
+		if webhook.Debounce > 0 {
+			if last, exists := wn.lastSent[key]; exists && event.Timestamp.Sub(last) < webhook.Debounce {
+				wn.suppressed[key]++
+				continue
+			}
+		}
+
+		payload := buildPayload(kind, event)
+		payload.Suppressed = wn.suppressed[key]
+		wn.lastSent[key] = event.Timestamp
+		delete(wn.suppressed, key)
+
+		select {
+		case wn.queue <- webhookDelivery{webhook: webhook, payload: payload}:
+		default:
+			wn.logger.Warn("Webhook queue full, dropping %s notification for %s", kind, event.Service)
+		}
+	}
+}
+
+// wantsEvent reports whether a webhook subscribed to the given event kind
+func wantsEvent(webhook config.WebhookConfig, kind string) bool {
+	if len(webhook.Events) == 0 {
+		return true
+	}
+	for _, event := range webhook.Events {
+		if event == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// buildPayload converts a manager event into a webhook payload
+func buildPayload(kind string, event portforward.Event) WebhookPayload {
+	return WebhookPayload{
+		Event:           kind,
+		Service:         event.Service,
+		Status:          event.Status.Status,
+		PreviousStatus:  event.PreviousStatus,
+		Error:           event.Error,
+		ErrorCategory:   string(event.Status.ErrorCategory),
+		Fatal:           event.Status.ErrorCategory.Fatal(),
+		LocalPort:       event.Status.LocalPort,
+		RestartCount:    event.Status.RestartCount,
+		Context:         event.Context,
+		PreviousContext: event.PreviousContext,
+		Timestamp:       event.Timestamp,
+	}
+}
+
+// deliver posts a payload to its webhook
+func (wn *WebhookNotifier) deliver(delivery webhookDelivery) {
+	var body interface{} = delivery.payload
+	if delivery.webhook.Format == "slack" {
+		body = slackPayload{Text: slackText(delivery.payload)}
+	}
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		wn.logger.Error("Failed to encode webhook payload: %v", err)
+		return
+	}
+
+	resp, err := wn.client.Post(delivery.webhook.URL, "application/json", bytes.NewReader(data))
+	if err != nil {
+		wn.logger.Warn("Failed to post webhook notification: %v", err)
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		wn.logger.Warn("Webhook %s returned status %d", delivery.webhook.URL, resp.StatusCode)
+	}
+}
+
+// slackText renders a payload as a human-readable Slack message
+func slackText(payload WebhookPayload) string {
+	var text string
+	switch payload.Event {
+	case KindFailure:
+		text = fmt.Sprintf(":red_circle: *%s* is %s", payload.Service, payload.Status)
+		if payload.Error != "" {
+			text += fmt.Sprintf(" (%s)", payload.Error)
+		}
+	case KindRecovery:
+		text = fmt.Sprintf(":large_green_circle: *%s* recovered on localhost:%d", payload.Service, payload.LocalPort)
+	case KindContextChange:
+		text = fmt.Sprintf(":twisted_rightwards_arrows: Kubernetes context changed from `%s` to `%s`",
+			payload.PreviousContext, payload.Context)
+	default:
+		text = payload.Event
+	}
+
+	if payload.Suppressed > 0 {
+		text += fmt.Sprintf(" — %d similar events suppressed", payload.Suppressed)
+	}
+	return text
+}
@@ -0,0 +1,90 @@
+package notify
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+func TestWebhookNotifierPostsPayloads(t *testing.T) {
+	var mutex sync.Mutex
+	var received []WebhookPayload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload WebhookPayload
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("Failed to decode payload: %v", err)
+		}
+		mutex.Lock()
+		received = append(received, payload)
+		mutex.Unlock()
+	}))
+	defer server.Close()
+
+	notifier := NewWebhookNotifier([]config.WebhookConfig{
+		{URL: server.URL, Events: []string{KindFailure}},
+	}, utils.NewLogger(utils.LevelError))
+	notifier.Start()
+
+	now := time.Now()
+	failure := stateChange("api", "Running", "Failed")
+	failure.Timestamp = now
+	recovery := stateChange("api", "Failed", "Running")
+	recovery.Timestamp = now
+
+	notifier.HandleEvent(failure)
+	notifier.HandleEvent(recovery) // Filtered out by events list
+	notifier.Stop()
+
+	if len(received) != 1 {
+		t.Fatalf("Expected 1 payload, got %d", len(received))
+	}
+	if received[0].Event != KindFailure || received[0].Service != "api" {
+		t.Errorf("Unexpected payload: %+v", received[0])
+	}
+}
+
+func TestWebhookNotifierDebounce(t *testing.T) {
+	notifier := NewWebhookNotifier([]config.WebhookConfig{
+		{URL: "http://127.0.0.1:0", Debounce: time.Minute},
+	}, utils.NewLogger(utils.LevelError))
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		event := stateChange("flappy", "Running", "Failed")
+		event.Timestamp = start.Add(time.Duration(i) * time.Second)
+		notifier.HandleEvent(event)
+	}
+
+	if len(notifier.queue) != 1 {
+		t.Fatalf("Expected 1 queued delivery within debounce window, got %d", len(notifier.queue))
+	}
+	<-notifier.queue
+
+	// After the window the next event carries the suppressed count
+	event := stateChange("flappy", "Running", "Failed")
+	event.Timestamp = start.Add(2 * time.Minute)
+	notifier.HandleEvent(event)
+
+	delivery := <-notifier.queue
+	if delivery.payload.Suppressed != 2 {
+		t.Errorf("Expected 2 suppressed events, got %d", delivery.payload.Suppressed)
+	}
+}
+
+func TestSlackText(t *testing.T) {
+	text := slackText(WebhookPayload{
+		Event:           KindContextChange,
+		Context:         "prod",
+		PreviousContext: "dev",
+	})
+	if !strings.Contains(text, "`dev`") || !strings.Contains(text, "`prod`") {
+		t.Errorf("Expected both contexts in Slack text, got %q", text)
+	}
+}
@@ -0,0 +1,123 @@
+package pick
+
+import (
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+)
+
+// Candidate is a service or pod that can be forwarded
+type Candidate struct {
+	Kind      string // "service" or "pod"
+	Name      string
+	Namespace string
+	Ports     []int // Ports the resource declares, sorted
+}
+
+// Target returns the candidate as a kubectl port-forward target
+func (c Candidate) Target() string {
+	return c.Kind + "/" + c.Name
+}
+
+// String renders the candidate as shown in the picker
+func (c Candidate) String() string {
+	return c.Namespace + "/" + c.Target()
+}
+
+// List returns the services and running pods kubectl sees in the namespace (the
+// context's namespace when "", every namespace when allNamespaces), using the
+// kubectl binary, environment and context of service
+func List(service config.Service, allNamespaces bool) ([]Candidate, error) {
+	args := append([]string{"get", "services,pods", "--output", "json"}, service.KubectlScope()...)
+	if allNamespaces {
+		args = append(args, "--all-namespaces")
+	} else if service.Namespace != "" {
+		args = append(args, "--namespace", service.Namespace)
+	}
+
+	binary := service.KubectlPath
+	if binary == "" {
+		binary = "kubectl"
+	}
+	cmd := exec.Command(binary, args...)
+	cmd.Env = service.Environ()
+	output, err := cmd.Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok && len(exitErr.Stderr) > 0 {
+			return nil, fmt.Errorf("failed to list services and pods: %s", strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return nil, fmt.Errorf("failed to list services and pods: %w", err)
+	}
+	return parseList(output)
+}
+
+// resourceList is the part of a kubectl List of services and pods that candidates need
+type resourceList struct {
+	Items []struct {
+		Kind     string `json:"kind"`
+		Metadata struct {
+			Name      string `json:"name"`
+			Namespace string `json:"namespace"`
+		} `json:"metadata"`
+		Spec struct {
+			Ports []struct {
+				Port int `json:"port"`
+			} `json:"ports"` // Services
+			Containers []struct {
+				Ports []struct {
+					ContainerPort int `json:"containerPort"`
+				} `json:"ports"`
+			} `json:"containers"` // Pods
+		} `json:"spec"`
+		Status struct {
+			Phase string `json:"phase"`
+		} `json:"status"`
+	} `json:"items"`
+}
+
+// parseList converts kubectl's JSON output into candidates, services first, skipping
+// pods that aren't running
+func parseList(data []byte) ([]Candidate, error) {
+	var list resourceList
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("failed to parse kubectl output: %w", err)
+	}
+
+	candidates := make([]Candidate, 0, len(list.Items))
+	for _, item := range list.Items {
+		candidate := Candidate{Name: item.Metadata.Name, Namespace: item.Metadata.Namespace}
+		switch item.Kind {
+		case "Service":
+			candidate.Kind = "service"
+			for _, port := range item.Spec.Ports {
+				candidate.Ports = append(candidate.Ports, port.Port)
+			}
+		case "Pod":
+			if item.Status.Phase != "Running" {
+				continue
+			}
+			candidate.Kind = "pod"
+			for _, container := range item.Spec.Containers {
+				for _, port := range container.Ports {
+					candidate.Ports = append(candidate.Ports, port.ContainerPort)
+				}
+			}
+		default:
+			continue
+		}
+		sort.Ints(candidate.Ports)
+		candidates = append(candidates, candidate)
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].Kind != candidates[j].Kind {
+			return candidates[i].Kind == "service"
+		}
+		return candidates[i].String() < candidates[j].String()
+	})
+	return candidates, nil
+}
@@ -0,0 +1,77 @@
+package pick
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Score reports whether the characters of query appear in order in text (ignoring
+// case) and how well they match: consecutive characters and characters at the start
+// of a word score higher. An empty query matches everything with a score of 0.
+func Score(query, text string) (int, bool) {
+	needle := []rune(strings.Join(strings.Fields(strings.ToLower(query)), ""))
+	if len(needle) == 0 {
+		return 0, true
+	}
+	runes := []rune(text)
+	lower := []rune(strings.ToLower(text))
+
+	// Match greedily from every occurrence of the first character and keep the best
+	best, matched := 0, false
+	for start, r := range lower {
+		if r != needle[0] {
+			continue
+		}
+		if score, ok := scoreFrom(needle, runes, lower, start); ok && (!matched || score > best) {
+			best, matched = score, true
+		}
+	}
+	return best, matched
+}
+
+// scoreFrom scores a greedy match of needle in text that starts at position start
+func scoreFrom(needle, runes, lower []rune, start int) (int, bool) {
+	score, position, previous := 0, start, -2
+	for _, q := range needle {
+		for position < len(lower) && lower[position] != q {
+			position++
+		}
+		if position == len(lower) {
+			return 0, false
+		}
+
+		score++
+		if position == previous+1 {
+			score += 3 // Consecutive
+		}
+		if position == 0 || !unicode.IsLetter(runes[position-1]) && !unicode.IsDigit(runes[position-1]) {
+			score += 2 // Start of a word, e.g. after / or -
+		}
+		previous = position
+		position++
+	}
+	return score, true
+}
+
+// Filter returns the candidates matching query, best matches first; ties keep their order
+func Filter(candidates []Candidate, query string) []Candidate {
+	type match struct {
+		candidate Candidate
+		score     int
+	}
+
+	var matches []match
+	for _, candidate := range candidates {
+		if score, ok := Score(query, candidate.String()); ok {
+			matches = append(matches, match{candidate, score})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].score > matches[j].score })
+
+	filtered := make([]Candidate, len(matches))
+	for i, m := range matches {
+		filtered[i] = m.candidate
+	}
+	return filtered
+}
@@ -0,0 +1,94 @@
+package pick
+
+import (
+	"reflect"
+	"testing"
+)
+
+const kubectlList = `{
+  "apiVersion": "v1",
+  "kind": "List",
+  "items": [
+    {"kind": "Pod", "metadata": {"name": "orders-7d9f", "namespace": "shop"},
+     "spec": {"containers": [{"ports": [{"containerPort": 9090}, {"containerPort": 8080}]}, {}]},
+     "status": {"phase": "Running"}},
+    {"kind": "Pod", "metadata": {"name": "migrate-x2", "namespace": "shop"},
+     "spec": {"containers": [{}]}, "status": {"phase": "Succeeded"}},
+    {"kind": "Service", "metadata": {"name": "orders", "namespace": "shop"},
+     "spec": {"ports": [{"port": 80}, {"port": 443}]}},
+    {"kind": "Service", "metadata": {"name": "cart", "namespace": "shop"},
+     "spec": {"ports": [{"port": 8080}]}}
+  ]
+}`
+
+func TestParseList(t *testing.T) {
+	candidates, err := parseList([]byte(kubectlList))
+	if err != nil {
+		t.Fatalf("parseList failed: %v", err)
+	}
+
+	expected := []Candidate{
+		{Kind: "service", Name: "cart", Namespace: "shop", Ports: []int{8080}},
+		{Kind: "service", Name: "orders", Namespace: "shop", Ports: []int{80, 443}},
+		{Kind: "pod", Name: "orders-7d9f", Namespace: "shop", Ports: []int{8080, 9090}},
+	}
+	if !reflect.DeepEqual(candidates, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, candidates)
+	}
+	if target := candidates[1].Target(); target != "service/orders" {
+		t.Errorf("Expected target service/orders, got %s", target)
+	}
+
+	if _, err := parseList([]byte("error: You must be logged in")); err == nil {
+		t.Error("Expected an error for non-JSON output")
+	}
+}
+
+func TestScore(t *testing.T) {
+	tests := []struct {
+		query string
+		text  string
+		match bool
+	}{
+		{"", "shop/service/orders", true},
+		{"ord", "shop/service/orders", true},
+		{"ORD", "shop/service/orders", true},
+		{"svcord", "shop/service/orders", true},
+		{"sho ord", "shop/service/orders", true},
+		{"cart", "shop/service/orders", false},
+		{"ordersx", "shop/service/orders", false},
+	}
+	for _, test := range tests {
+		if _, ok := Score(test.query, test.text); ok != test.match {
+			t.Errorf("Score(%q, %q): expected match %v", test.query, test.text, test.match)
+		}
+	}
+
+	consecutive, _ := Score("ord", "shop/service/orders")
+	scattered, _ := Score("ord", "shop/service/o-r-d")
+	if consecutive <= scattered {
+		t.Errorf("Expected consecutive characters to score higher: %d vs %d", consecutive, scattered)
+	}
+}
+
+func TestFilter(t *testing.T) {
+	candidates := []Candidate{
+		{Kind: "service", Name: "order-router", Namespace: "shop"},
+		{Kind: "service", Name: "cart", Namespace: "shop"},
+		{Kind: "service", Name: "orders", Namespace: "shop"},
+	}
+
+	filtered := Filter(candidates, "orders")
+	if len(filtered) != 1 || filtered[0].Name != "orders" {
+		t.Errorf("Expected only orders, got %+v", filtered)
+	}
+
+	filtered = Filter(candidates, "ord")
+	if len(filtered) != 2 || filtered[0].Name != "order-router" || filtered[1].Name != "orders" {
+		t.Errorf("Expected equally good matches to keep their order, got %+v", filtered)
+	}
+
+	if filtered := Filter(candidates, ""); len(filtered) != 3 {
+		t.Errorf("Expected an empty query to match everything, got %d", len(filtered))
+	}
+}
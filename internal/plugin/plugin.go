@@ -0,0 +1,237 @@
+package plugin
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+// readyTimeout bounds how long a plugin may take to report its tunnel ready
+const readyTimeout = 30 * time.Second
+
+// stopTimeout is how long a plugin gets to exit after a stop request before it is killed
+const stopTimeout = 5 * time.Second
+
+// Dir returns the directory plugins are discovered in
+func Dir() (string, error) {
+	configDir, err := config.GetUserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "plugins"), nil
+}
+
+// Find returns the executable of the named plugin in dir: either <name> or
+// kportforward-plugin-<name>, with .exe on Windows
+func Find(dir, name string) (string, error) {
+	if name == "" || strings.ContainsAny(name, `/\`) || strings.HasPrefix(name, ".") {
+		return "", fmt.Errorf("invalid plugin name %q", name)
+	}
+
+	suffix := ""
+	if runtime.GOOS == "windows" {
+		suffix = ".exe"
+	}
+	for _, candidate := range []string{name, "kportforward-plugin-" + name} {
+		path := filepath.Join(dir, candidate+suffix)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("plugin %s not found in %s", name, dir)
+}
+
+// Process is a running plugin serving one service's tunnel
+type Process struct {
+	name   string
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	logger *utils.Logger
+
+	writeMutex  sync.Mutex
+	healthMutex sync.Mutex // One health request in flight, so replies can't be mismatched
+	health      chan Message
+	done        chan struct{} // Closed once the plugin's stdout ends
+}
+
+// Start runs the plugin at path and asks it to start the tunnel, returning once it
+// reports ready. The plugin's stderr goes to stderr (e.g. the service's output tail).
+func Start(path string, request StartRequest, env []string, stderr io.Writer, logger *utils.Logger) (*Process, error) {
+	cmd := utils.ForwardCommand(path, nil, env)
+	cmd.Stderr = stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to plugin: %w", err)
+	}
+	// Not StdoutPipe: the owner reaps the process with Wait, which would close the pipe under the reader
+	stdout, stdoutWriter, err := os.Pipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to plugin: %w", err)
+	}
+	cmd.Stdout = stdoutWriter
+	err = cmd.Start()
+	stdoutWriter.Close()
+	if err != nil {
+		stdout.Close()
+		return nil, fmt.Errorf("failed to start plugin: %w", err)
+	}
+
+	p := &Process{
+		name:   filepath.Base(path),
+		cmd:    cmd,
+		stdin:  stdin,
+		logger: logger,
+		health: make(chan Message, 1),
+		done:   make(chan struct{}),
+	}
+	ready := make(chan error, 1)
+	go p.readMessages(stdout, ready)
+
+	request.Protocol = ProtocolVersion
+	if err := p.send(Request{Method: "start", Start: &request}); err != nil {
+		p.kill()
+		return nil, err
+	}
+
+	select {
+	case err := <-ready:
+		if err != nil {
+			p.kill()
+			return nil, err
+		}
+		return p, nil
+	case <-time.After(readyTimeout):
+		p.kill()
+		return nil, fmt.Errorf("plugin %s didn't report ready within %s", p.name, readyTimeout)
+	}
+}
+
+// Cmd returns the plugin's process
+func (p *Process) Cmd() *exec.Cmd {
+	return p.cmd
+}
+
+// Health asks the plugin whether its tunnel is healthy
+func (p *Process) Health(ctx context.Context) error {
+	p.healthMutex.Lock()
+	defer p.healthMutex.Unlock()
+
+	// Drop a late reply to an earlier request that timed out
+	select {
+	case <-p.health:
+	default:
+	}
+
+	if err := p.send(Request{Method: "health"}); err != nil {
+		return err
+	}
+	select {
+	case reply := <-p.health:
+		if !reply.Healthy {
+			if reply.Message == "" {
+				return fmt.Errorf("plugin %s reports the tunnel unhealthy", p.name)
+			}
+			return errors.New(reply.Message)
+		}
+		return nil
+	case <-p.done:
+		return fmt.Errorf("plugin %s exited", p.name)
+	case <-ctx.Done():
+		return fmt.Errorf("plugin %s didn't answer the health check: %w", p.name, ctx.Err())
+	}
+}
+
+// Stop asks the plugin to tear down its tunnel and exit, killing it if it doesn't
+// within stopTimeout. The caller still reaps the process with Cmd().Wait().
+func (p *Process) Stop() {
+	if err := p.send(Request{Method: "stop"}); err == nil {
+		p.stdin.Close()
+		select {
+		case <-p.done: // stdout closes as the plugin exits
+		case <-time.After(stopTimeout):
+		}
+	}
+	p.kill()
+}
+
+// kill terminates the plugin process if it is still running
+func (p *Process) kill() {
+	p.stdin.Close()
+	if p.cmd.Process != nil && utils.IsProcessRunning(p.cmd.Process.Pid) {
+		utils.KillProcess(p.cmd.Process.Pid)
+	}
+}
+
+// send writes a request to the plugin
+func (p *Process) send(request Request) error {
+	data, err := json.Marshal(request)
+	if err != nil {
+		return fmt.Errorf("failed to encode plugin request: %w", err)
+	}
+
+	p.writeMutex.Lock()
+	defer p.writeMutex.Unlock()
+	if _, err := p.stdin.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to send %s to plugin %s: %w", request.Method, p.name, err)
+	}
+	return nil
+}
+
+// readMessages handles the plugin's messages until its stdout closes, reporting the
+// outcome of the start request on ready
+func (p *Process) readMessages(stdout io.ReadCloser, ready chan<- error) {
+	defer close(p.done)
+	defer stdout.Close()
+
+	started := false
+	scanner := bufio.NewScanner(stdout)
+	for scanner.Scan() {
+		line := scanner.Text()
+		var message Message
+		if err := json.Unmarshal([]byte(line), &message); err != nil {
+			p.logger.Debug("[plugin %s] %s", p.name, line) // Not a protocol message
+			continue
+		}
+
+		switch message.Type {
+		case "ready":
+			if !started {
+				started = true
+				ready <- nil
+			}
+		case "error":
+			if !started {
+				started = true
+				ready <- fmt.Errorf("plugin %s: %s", p.name, message.Message)
+			} else {
+				p.logger.Warn("[plugin %s] %s", p.name, message.Message)
+			}
+		case "health":
+			select {
+			case p.health <- message:
+			default:
+			}
+		case "log":
+			p.logger.Info("[plugin %s] %s", p.name, message.Message)
+		default:
+			p.logger.Debug("[plugin %s] unknown message type %q", p.name, message.Type)
+		}
+	}
+
+	if !started {
+		ready <- fmt.Errorf("plugin %s exited before reporting ready", p.name)
+	}
+}
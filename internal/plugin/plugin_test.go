@@ -0,0 +1,98 @@
+package plugin
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+// testPlugin answers the protocol: ready for service "db", healthy until stopped
+const testPlugin = `#!/bin/sh
+read start
+case "$start" in
+  *'"service":"db"'*'"options":{"region":"eu"}'*) ;;
+  *) echo '{"type":"error","message":"unexpected start request"}'; exit 1 ;;
+esac
+echo 'not json'
+echo '{"type":"log","message":"tunnel up"}'
+echo '{"type":"ready"}'
+while read request; do
+  case "$request" in
+    *health*) echo '{"type":"health","healthy":false,"message":"vpn down"}' ;;
+    *stop*) exit 0 ;;
+  esac
+done
+`
+
+func writePlugin(t *testing.T, name, script string) (string, string) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell script plugins need a Unix shell")
+	}
+	dir := t.TempDir()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(script), 0755); err != nil {
+		t.Fatalf("Failed to write plugin: %v", err)
+	}
+	return dir, path
+}
+
+func TestFind(t *testing.T) {
+	dir, path := writePlugin(t, "kportforward-plugin-corpvpn", testPlugin)
+
+	found, err := Find(dir, "corpvpn")
+	if err != nil || found != path {
+		t.Errorf("Expected %s, got %s (%v)", path, found, err)
+	}
+	if _, err := Find(dir, "missing"); err == nil {
+		t.Errorf("Expected an error for a missing plugin")
+	}
+	if _, err := Find(dir, "../corpvpn"); err == nil {
+		t.Errorf("Expected an error for a name with a path")
+	}
+}
+
+func TestProcessLifecycle(t *testing.T) {
+	_, path := writePlugin(t, "corpvpn", testPlugin)
+	logger := utils.NewLogger(utils.LevelError)
+
+	p, err := Start(path, StartRequest{Service: "db", LocalPort: 15432, Options: map[string]string{"region": "eu"}}, nil, io.Discard, logger)
+	if err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := p.Health(ctx); err == nil || err.Error() != "vpn down" {
+		t.Errorf("Expected the plugin's unhealthy reason, got %v", err)
+	}
+
+	p.Stop()
+	if err := p.Cmd().Wait(); err != nil {
+		t.Errorf("Expected the plugin to exit cleanly on stop, got %v", err)
+	}
+}
+
+func TestStartReportsPluginError(t *testing.T) {
+	_, path := writePlugin(t, "corpvpn", testPlugin)
+
+	_, err := Start(path, StartRequest{Service: "other"}, nil, io.Discard, utils.NewLogger(utils.LevelError))
+	if err == nil || !strings.Contains(err.Error(), "unexpected start request") {
+		t.Errorf("Expected the plugin's error, got %v", err)
+	}
+}
+
+func TestStartFailsWhenPluginExits(t *testing.T) {
+	_, path := writePlugin(t, "broken", "#!/bin/sh\nexit 3\n")
+
+	_, err := Start(path, StartRequest{Service: "db"}, nil, io.Discard, utils.NewLogger(utils.LevelError))
+	if err == nil {
+		t.Errorf("Expected an error when the plugin exits before ready")
+	}
+}
@@ -0,0 +1,30 @@
+package plugin
+
+// ProtocolVersion is sent with every start request so plugins can reject versions they don't speak
+const ProtocolVersion = 1
+
+// Request is a JSON line kportforward writes to a plugin's stdin
+type Request struct {
+	Method string        `json:"method"` // "start", "health" or "stop"
+	Start  *StartRequest `json:"start,omitempty"`
+}
+
+// StartRequest asks the plugin to accept connections on LocalPort and tunnel them to the target
+type StartRequest struct {
+	Protocol    int               `json:"protocol"`
+	Service     string            `json:"service"`
+	Target      string            `json:"target"`
+	TargetPort  int               `json:"targetPort"`
+	LocalPort   int               `json:"localPort"`
+	BindAddress string            `json:"bindAddress,omitempty"` // Comma-separated local addresses (default: localhost)
+	Namespace   string            `json:"namespace,omitempty"`
+	Context     string            `json:"context,omitempty"`
+	Options     map[string]string `json:"options,omitempty"` // The service's pluginOptions
+}
+
+// Message is a JSON line a plugin writes to its stdout
+type Message struct {
+	Type    string `json:"type"`              // "ready", "health", "log" or "error"
+	Healthy bool   `json:"healthy,omitempty"` // For health replies
+	Message string `json:"message,omitempty"` // Log line, error, or why the tunnel is unhealthy
+}
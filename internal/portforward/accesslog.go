@@ -0,0 +1,41 @@
+package portforward
+
+import (
+	"sync"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+)
+
+// accessLogSize is how many recent access log entries are kept per service
+const accessLogSize = 50
+
+// accessLog keeps the most recent relay access log entries of a service
+type accessLog struct {
+	recent []config.AccessLogEntry
+	mutex  sync.Mutex
+}
+
+// add appends an entry, dropping the oldest once full
+func (l *accessLog) add(entry config.AccessLogEntry) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if len(l.recent) == accessLogSize {
+		copy(l.recent, l.recent[1:])
+		l.recent = l.recent[:accessLogSize-1]
+	}
+	l.recent = append(l.recent, entry)
+}
+
+// entries returns a copy of the entries, oldest first
+func (l *accessLog) entries() []config.AccessLogEntry {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if len(l.recent) == 0 {
+		return nil
+	}
+	entries := make([]config.AccessLogEntry, len(l.recent))
+	copy(entries, l.recent)
+	return entries
+}
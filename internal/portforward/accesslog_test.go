@@ -0,0 +1,26 @@
+package portforward
+
+import (
+	"testing"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+)
+
+func TestAccessLogKeepsRecentEntries(t *testing.T) {
+	var log accessLog
+	if log.entries() != nil {
+		t.Error("Expected no entries initially")
+	}
+
+	for i := 0; i < accessLogSize+5; i++ {
+		log.add(config.AccessLogEntry{Status: i})
+	}
+
+	entries := log.entries()
+	if len(entries) != accessLogSize {
+		t.Fatalf("Expected %d entries, got %d", accessLogSize, len(entries))
+	}
+	if entries[0].Status != 5 || entries[len(entries)-1].Status != accessLogSize+4 {
+		t.Errorf("Expected oldest entries to be dropped, got %d..%d", entries[0].Status, entries[len(entries)-1].Status)
+	}
+}
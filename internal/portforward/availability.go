@@ -0,0 +1,190 @@
+package portforward
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+const (
+	// healthSlotDuration is how much time each slot of the health timeline covers
+	healthSlotDuration = time.Minute
+	// healthTimelineSlots is how many slots of the timeline are kept
+	healthTimelineSlots = 60
+)
+
+// availabilityTracker accumulates the time services were up and down, from the
+// statuses seen by the monitor loop
+type availabilityTracker struct {
+	services map[string]*serviceAvailability
+	maxGap   time.Duration // Longer gaps between observations, e.g. sleep, are not counted
+	mutex    sync.Mutex
+}
+
+// serviceAvailability is the availability of one service and its last observed state
+type serviceAvailability struct {
+	config.Availability
+	lastSeen time.Time
+	lastUp   bool
+	tracked  bool
+}
+
+// newAvailabilityTracker creates a tracker; setInterval adapts it to the monitor loop
+func newAvailabilityTracker() *availabilityTracker {
+	return &availabilityTracker{
+		services: make(map[string]*serviceAvailability),
+		maxGap:   healthSlotDuration,
+	}
+}
+
+// setInterval sets the monitoring interval observations are expected at
+func (t *availabilityTracker) setInterval(interval time.Duration) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.maxGap = max(2*interval, healthSlotDuration)
+}
+
+// trackedState reports whether a status counts as up, and whether it counts at all:
+// stopped, paused, suspended and waiting services are neither up nor down
+func trackedState(status string) (up, tracked bool) {
+	switch status {
+	case "Running":
+		return true, true
+	case "Stopped", "Login", StatusPaused, StatusSuspended, StatusWaitingForWorkload:
+		return false, false
+	}
+	return false, true
+}
+
+// observe attributes the time since the previous observation to the state seen then,
+// records the current status and returns the service's availability
+func (t *availabilityTracker) observe(name, status string, now time.Time) config.Availability {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	a, exists := t.services[name]
+	if !exists {
+		a = &serviceAvailability{}
+		t.services[name] = a
+	}
+	if a.tracked && !a.lastSeen.IsZero() {
+		if elapsed := now.Sub(a.lastSeen); elapsed > 0 && elapsed <= t.maxGap {
+			a.record(a.lastUp, elapsed, a.lastSeen)
+		}
+	}
+	a.lastSeen = now
+	a.lastUp, a.tracked = trackedState(status)
+	return a.snapshot()
+}
+
+// get returns a service's availability without observing it
+func (t *availabilityTracker) get(name string) config.Availability {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if a, exists := t.services[name]; exists {
+		return a.snapshot()
+	}
+	return config.Availability{}
+}
+
+// record adds time elapsed from since to the totals and to the timeline slot it began in
+func (a *serviceAvailability) record(up bool, elapsed time.Duration, since time.Time) {
+	slot := a.slotAt(since)
+	if up {
+		a.Up += elapsed
+		slot.Up += elapsed
+	} else {
+		a.Down += elapsed
+		slot.Down += elapsed
+	}
+}
+
+// slotAt returns the timeline slot holding at, adding empty slots for the minutes
+// nothing was tracked and dropping the oldest beyond the timeline length
+func (a *serviceAvailability) slotAt(at time.Time) *config.HealthSlot {
+	start := at.Truncate(healthSlotDuration)
+	if n := len(a.Timeline); n > 0 {
+		last := a.Timeline[n-1].Start
+		if !start.After(last) {
+			return &a.Timeline[n-1]
+		}
+		if start.Sub(last) > healthTimelineSlots*healthSlotDuration {
+			a.Timeline = nil
+		} else {
+			for gap := last.Add(healthSlotDuration); gap.Before(start); gap = gap.Add(healthSlotDuration) {
+				a.Timeline = append(a.Timeline, config.HealthSlot{Start: gap})
+			}
+		}
+	}
+
+	a.Timeline = append(a.Timeline, config.HealthSlot{Start: start})
+	if len(a.Timeline) > healthTimelineSlots {
+		a.Timeline = append(a.Timeline[:0], a.Timeline[len(a.Timeline)-healthTimelineSlots:]...)
+	}
+	return &a.Timeline[len(a.Timeline)-1]
+}
+
+// snapshot returns a copy of the availability that later observations leave alone
+func (a *serviceAvailability) snapshot() config.Availability {
+	snapshot := a.Availability
+	if len(a.Timeline) > 0 {
+		snapshot.Timeline = make([]config.HealthSlot, len(a.Timeline))
+		copy(snapshot.Timeline, a.Timeline)
+	}
+	return snapshot
+}
+
+// availabilityPath returns the file availability is kept in across sessions
+func availabilityPath() (string, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get cache directory: %w", err)
+	}
+	return filepath.Join(cacheDir, "kportforward", "availability.json"), nil
+}
+
+// load restores the availability saved by a previous session
+func (t *availabilityTracker) load(path string) error {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read availability: %w", err)
+	}
+
+	var saved map[string]config.Availability
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return fmt.Errorf("failed to parse availability: %w", err)
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	for name, availability := range saved {
+		t.services[name] = &serviceAvailability{Availability: availability}
+	}
+	return nil
+}
+
+// save writes the availability of every service for the next session
+func (t *availabilityTracker) save(path string) error {
+	t.mutex.Lock()
+	saved := make(map[string]config.Availability, len(t.services))
+	for name, a := range t.services {
+		saved[name] = a.snapshot()
+	}
+	t.mutex.Unlock()
+
+	data, err := json.MarshalIndent(saved, "", "  ")
+	if err != nil {
+		return err
+	}
+	return utils.WriteFileAtomic(path, append(data, '\n'), 0644)
+}
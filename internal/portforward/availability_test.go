@@ -0,0 +1,112 @@
+package portforward
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+)
+
+func TestAvailabilityTrackerCountsUpAndDown(t *testing.T) {
+	tracker := newTestTracker(5 * time.Second)
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tracker.observe("api", "Running", start)
+	tracker.observe("api", "Failed", start.Add(30*time.Second))
+	tracker.observe("api", "Running", start.Add(40*time.Second))
+	availability := tracker.observe("api", "Stopped", start.Add(50*time.Second))
+	// Stopped time is neither up nor down
+	availability = tracker.observe("api", "Running", start.Add(10*time.Minute))
+
+	if availability.Up != 40*time.Second || availability.Down != 10*time.Second {
+		t.Fatalf("Expected 40s up and 10s down, got %v and %v", availability.Up, availability.Down)
+	}
+	if got := availability.String(); got != "80.0%" {
+		t.Errorf("Expected 80.0%%, got %s", got)
+	}
+	if len(availability.Timeline) != 1 || availability.Timeline[0].State() != config.HealthDegraded {
+		t.Errorf("Expected one degraded slot, got %+v", availability.Timeline)
+	}
+}
+
+func TestAvailabilityTrackerSkipsLongGaps(t *testing.T) {
+	tracker := newTestTracker(5 * time.Second)
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tracker.observe("api", "Running", start)
+	availability := tracker.observe("api", "Running", start.Add(3*time.Hour))
+	if availability.Up != 0 {
+		t.Errorf("Expected a gap such as sleep not to count, got %v up", availability.Up)
+	}
+}
+
+func TestAvailabilityTimelineFillsAndTrims(t *testing.T) {
+	tracker := newTestTracker(time.Minute)
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tracker.observe("api", "Running", start)
+	tracker.observe("api", "Running", start.Add(30*time.Second))
+	tracker.observe("api", "Stopped", start.Add(60*time.Second))
+	availability := tracker.observe("api", "Running", start.Add(3*time.Minute+30*time.Second))
+	tracker.observe("api", "Failed", start.Add(4*time.Minute))
+	availability = tracker.observe("api", "Failed", start.Add(4*time.Minute+30*time.Second))
+
+	var states []string
+	for _, slot := range availability.Timeline {
+		states = append(states, slot.State())
+	}
+	want := []string{config.HealthUp, config.HealthUnknown, config.HealthUnknown, config.HealthUp, config.HealthDown}
+	if len(states) != len(want) {
+		t.Fatalf("Expected states %v, got %v", want, states)
+	}
+	for i := range want {
+		if states[i] != want[i] {
+			t.Fatalf("Expected states %v, got %v", want, states)
+		}
+	}
+
+	now := start.Add(4 * time.Minute)
+	for i := 0; i < 2*healthTimelineSlots; i++ {
+		now = now.Add(30 * time.Second)
+		availability = tracker.observe("api", "Running", now)
+	}
+	if len(availability.Timeline) != healthTimelineSlots {
+		t.Errorf("Expected the timeline to keep %d slots, got %d", healthTimelineSlots, len(availability.Timeline))
+	}
+}
+
+func TestAvailabilitySaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "availability.json")
+	tracker := newTestTracker(5 * time.Second)
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	tracker.observe("api", "Running", start)
+	tracker.observe("api", "Running", start.Add(45*time.Second))
+
+	if err := tracker.save(path); err != nil {
+		t.Fatalf("save failed: %v", err)
+	}
+	restored := newTestTracker(5 * time.Second)
+	if err := restored.load(path); err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	availability := restored.get("api")
+	if availability.Up != 45*time.Second || len(availability.Timeline) != 1 {
+		t.Errorf("Expected the saved availability back, got %+v", availability)
+	}
+
+	// The first observation of a new session starts a new interval
+	availability = restored.observe("api", "Running", start.Add(time.Hour))
+	if availability.Up != 45*time.Second {
+		t.Errorf("Expected the time between sessions not to count, got %v up", availability.Up)
+	}
+	if err := newTestTracker(time.Second).load(filepath.Join(t.TempDir(), "missing.json")); err != nil {
+		t.Errorf("Expected a missing file to be ignored, got %v", err)
+	}
+}
+
+func newTestTracker(interval time.Duration) *availabilityTracker {
+	tracker := newAvailabilityTracker()
+	tracker.setInterval(interval)
+	return tracker
+}
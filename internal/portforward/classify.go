@@ -0,0 +1,103 @@
+package portforward
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+)
+
+// outputTailSize is how much of a forward's latest output is kept for classifying its failure
+const outputTailSize = 4096
+
+// errorSignatures map known failure messages to their category. The first match
+// wins, so more specific signatures come first.
+var errorSignatures = []struct {
+	pattern  *regexp.Regexp
+	category config.ErrorCategory
+}{
+	{regexp.MustCompile(`(?i)lost connection to pod|pod does not exist`), config.ErrorLostConnection},
+	{regexp.MustCompile(`(?i)unable to listen on|address already in use`), config.ErrorPortInUse},
+	{regexp.MustCompile(`(?i)unable to connect to the server`), config.ErrorClusterUnreachable},
+	{regexp.MustCompile(`(?i)unauthorized|you must be logged in|provide credentials`), config.ErrorUnauthorized},
+	{regexp.MustCompile(`(?i)forbidden`), config.ErrorForbidden},
+	{regexp.MustCompile(`(?i)namespaces? "?[^" ]+"? not found`), config.ErrorNamespaceNotFound},
+	{regexp.MustCompile(`(?i)not found`), config.ErrorNotFound},
+	{regexp.MustCompile(`(?i)error upgrading connection|unable to upgrade connection`), config.ErrorUpgradeFailed},
+	{regexp.MustCompile(`(?i)connection refused`), config.ErrorConnectionRefused},
+}
+
+// klogPrefix is the severity, time, thread and source location kubectl puts before its
+// log lines, e.g. "E1017 12:00:00.123456   4242 portforward.go:413] "
+var klogPrefix = regexp.MustCompile(`^[IWEF]\d{4} \d{2}:\d{2}:\d{2}\.\d+(\s+\d+)? [^\]]+\] `)
+
+// classifyError returns the category of the latest line of output with a known
+// failure signature, along with that line
+func classifyError(output string) (config.ErrorCategory, string) {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	for _, signature := range errorSignatures {
+		for i := len(lines) - 1; i >= 0; i-- {
+			if line := strings.TrimSpace(lines[i]); signature.pattern.MatchString(line) {
+				return signature.category, klogPrefix.ReplaceAllString(line, "")
+			}
+		}
+	}
+	return "", ""
+}
+
+// outputWatcher classifies a process's output line by line as it arrives, reporting
+// failures kubectl logs while it keeps running, such as refused connections to the pod
+type outputWatcher struct {
+	partial []byte
+	report  func(category config.ErrorCategory, line string)
+	mutex   sync.Mutex
+}
+
+// Write passes each complete line with a known failure signature to report
+func (w *outputWatcher) Write(p []byte) (int, error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	w.partial = append(w.partial, p...)
+	for {
+		end := bytes.IndexByte(w.partial, '\n')
+		if end < 0 {
+			break
+		}
+		if category, line := classifyError(string(w.partial[:end])); category != "" {
+			w.report(category, line)
+		}
+		w.partial = w.partial[end+1:]
+	}
+	if len(w.partial) > outputTailSize {
+		w.partial = nil // A line this long isn't one of kubectl's errors
+	}
+	return len(p), nil
+}
+
+// outputTail keeps the end of a process's output
+type outputTail struct {
+	data  []byte
+	mutex sync.Mutex
+}
+
+// Write appends output, dropping the oldest bytes beyond outputTailSize
+func (t *outputTail) Write(p []byte) (int, error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.data = append(t.data, p...)
+	if len(t.data) > outputTailSize {
+		t.data = t.data[len(t.data)-outputTailSize:]
+	}
+	return len(p), nil
+}
+
+// String returns the kept output
+func (t *outputTail) String() string {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return string(t.data)
+}
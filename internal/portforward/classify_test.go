@@ -0,0 +1,102 @@
+package portforward
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		output   string
+		expected config.ErrorCategory
+	}{
+		{"Forwarding from 127.0.0.1:8080 -> 80\nE0101 12:00:00.000000 portforward.go:413] an error occurred forwarding 8080 -> 80: error forwarding port 80 to pod 1a2b, uid : failed to execute portforward in network namespace \"/var/run/netns/cni-1\": failed to connect to localhost:80 inside namespace \"1a2b\", IPv4: dial tcp4 127.0.0.1:80: connect: connection refused", config.ErrorConnectionRefused},
+		{"Forwarding from 127.0.0.1:8080 -> 80\nerror: lost connection to pod", config.ErrorLostConnection},
+		{"Unable to listen on port 8080: Listeners failed to create with the following errors: [unable to create listener: Error listen tcp4 127.0.0.1:8080: bind: address already in use]", config.ErrorPortInUse},
+		{"error: You must be logged in to the server (Unauthorized)", config.ErrorUnauthorized},
+		{`Error from server (Forbidden): pods "api-1" is forbidden: User "dev" cannot create resource "pods/portforward" in API group "" in the namespace "payments"`, config.ErrorForbidden},
+		{`Error from server (NotFound): namespaces "payments" not found`, config.ErrorNamespaceNotFound},
+		{"namespace payments not found", config.ErrorNamespaceNotFound},
+		{`Error from server (NotFound): services "api" not found`, config.ErrorNotFound},
+		{"pod/api not found in namespace payments", config.ErrorNotFound},
+		{"Unable to connect to the server: dial tcp 10.0.0.1:443: i/o timeout", config.ErrorClusterUnreachable},
+		{"error: error upgrading connection: error dialing backend: dial tcp 10.0.3.7:10250: connect: connection refused", config.ErrorUpgradeFailed},
+		{"error: error upgrading connection: unable to upgrade connection: pod does not exist", config.ErrorLostConnection},
+		{`error: error upgrading connection: pods "api-1" is forbidden: User "dev" cannot create resource "pods/portforward"`, config.ErrorForbidden},
+		{"Forwarding from 127.0.0.1:8080 -> 80\nHandling connection for 8080", ""},
+	}
+
+	for _, test := range tests {
+		category, line := classifyError(test.output)
+		if category != test.expected {
+			t.Errorf("Expected %q for %q, got %q", test.expected, test.output, category)
+		}
+		if category != "" && !strings.Contains(test.output, line) {
+			t.Errorf("Expected the matching line from the output, got %q", line)
+		}
+	}
+}
+
+func TestOutputTailKeepsLatestOutput(t *testing.T) {
+	tail := &outputTail{}
+	tail.Write([]byte(strings.Repeat("x", outputTailSize)))
+	tail.Write([]byte("\nerror: lost connection to pod\n"))
+
+	output := tail.String()
+	if len(output) != outputTailSize || !strings.HasSuffix(output, "lost connection to pod\n") {
+		t.Errorf("Expected the last %d bytes, got %d ending in %q", outputTailSize, len(output), output[len(output)-10:])
+	}
+}
+
+func TestClassifyErrorStripsKlogPrefix(t *testing.T) {
+	_, line := classifyError("E1017 12:00:00.123456   4242 portforward.go:413] an error occurred forwarding 8080 -> 80: connect: connection refused")
+	if line != "an error occurred forwarding 8080 -> 80: connect: connection refused" {
+		t.Errorf("Expected the line without kubectl's log prefix, got %q", line)
+	}
+}
+
+func TestOutputWatcherReportsFailures(t *testing.T) {
+	var reported []string
+	watcher := &outputWatcher{report: func(category config.ErrorCategory, line string) {
+		reported = append(reported, string(category)+": "+line)
+	}}
+
+	watcher.Write([]byte("Forwarding from 127.0.0.1:8080 -> 80\nHandling connection for 8080\nE1017 12:00:00.123456   4242 portforward.go:413] an error occurred forwarding 8080 -> 80: connect: conn"))
+	if len(reported) != 0 {
+		t.Fatalf("Expected nothing reported before the line ends, got %q", reported)
+	}
+	watcher.Write([]byte("ection refused\nerror: lost connection to pod\n"))
+
+	expected := []string{
+		"connection-refused: an error occurred forwarding 8080 -> 80: connect: connection refused",
+		"lost-connection: error: lost connection to pod",
+	}
+	if strings.Join(reported, "|") != strings.Join(expected, "|") {
+		t.Errorf("Expected %q, got %q", expected, reported)
+	}
+}
+
+func TestServiceShowsFailuresKubectlReports(t *testing.T) {
+	sm := NewServiceManager("api", config.Service{LocalPort: 8080}, utils.NewLogger(utils.LevelError))
+	tail := &outputTail{}
+	sm.output = tail
+	sm.status.Status = "Running"
+
+	sm.outputFailure(tail, "an error occurred forwarding 8080 -> 80: connect: connection refused")
+	sm.outputFailure(tail, "an error occurred forwarding 8080 -> 80: connect: connection refused")
+	sm.outputFailure(&outputTail{}, "error: lost connection to pod") // From a replaced process
+
+	status := sm.GetStatus()
+	if status.Status != "Running" {
+		t.Errorf("Expected the service to keep running, got %s", status.Status)
+	}
+	if status.ErrorCategory != config.ErrorConnectionRefused || !strings.Contains(status.LastError, "connection refused") {
+		t.Errorf("Expected kubectl's connection refused error, got %q (%s)", status.LastError, status.ErrorCategory)
+	}
+	if len(status.RecentErrors) != 1 {
+		t.Errorf("Expected a repeated failure to be recorded once, got %+v", status.RecentErrors)
+	}
+}
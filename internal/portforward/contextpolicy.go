@@ -0,0 +1,81 @@
+package portforward
+
+import (
+	"fmt"
+)
+
+// PendingContext returns the context the kubectl context changed to while contextPolicy
+// ask waits for an answer, or "" when no change is pending
+func (m *Manager) PendingContext() string {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return m.pendingContext
+}
+
+// SwitchContext answers a pending context change by restarting the services following
+// the current context in the new one
+func (m *Manager) SwitchContext() error {
+	m.mutex.RLock()
+	pending := m.pendingContext
+	m.mutex.RUnlock()
+	if pending == "" {
+		return fmt.Errorf("no context change is pending")
+	}
+	m.switchContext(pending)
+	return nil
+}
+
+// KeepContext answers a pending context change by keeping the services in their context
+func (m *Manager) KeepContext() error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if m.pendingContext == "" {
+		return fmt.Errorf("no context change is pending")
+	}
+	m.logger.Info("Keeping the services in %s instead of switching to %s", m.kubernetesContext, m.pendingContext)
+	m.pendingContext = ""
+	return nil
+}
+
+// switchContext moves the services following the current context to newContext and
+// restarts them there
+func (m *Manager) switchContext(newContext string) {
+	m.mutex.Lock()
+	previousContext := m.kubernetesContext
+	m.kubernetesContext = newContext
+	m.pendingContext = ""
+	pinned := m.pinContext("")
+	services := make(map[string]*ServiceManager, len(m.services))
+	for name, sm := range m.services {
+		services[name] = sm
+	}
+	m.mutex.Unlock()
+	pinServices(pinned, "")
+
+	m.logger.Info("Kubernetes context changed from %s to %s, restarting all services", previousContext, newContext)
+	m.emit(Event{Type: EventContextChanged, Context: newContext, PreviousContext: previousContext})
+	m.resolveContexts(services)
+
+	// Restart all services in the new context, and match wildcards against it on the next tick
+	go m.restartAllServices()
+	m.wildcards.refreshNow.Store(true)
+}
+
+// pinContext pins the services following the current context, including those added
+// later, to context; "" unpins them. The caller holds the mutex and pins the returned
+// services after releasing it, as a starting service holds its own for a while.
+func (m *Manager) pinContext(context string) []*ServiceManager {
+	m.pinnedContext = context
+	services := make([]*ServiceManager, 0, len(m.services))
+	for _, sm := range m.services {
+		services = append(services, sm)
+	}
+	return services
+}
+
+// pinServices pins services to context
+func pinServices(services []*ServiceManager, context string) {
+	for _, sm := range services {
+		sm.PinContext(context)
+	}
+}
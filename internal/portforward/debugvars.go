@@ -0,0 +1,49 @@
+package portforward
+
+import (
+	"runtime"
+	"sync/atomic"
+	"time"
+
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+// debugStats are counters for diagnosing a running session
+type debugStats struct {
+	restarts      atomic.Int64
+	monitorPasses atomic.Int64
+	monitorLast   atomic.Int64 // Duration of the latest monitoring pass
+	monitorMax    atomic.Int64 // Longest monitoring pass
+}
+
+// recordMonitorPass records the duration of a monitoring pass
+func (s *debugStats) recordMonitorPass(elapsed time.Duration) {
+	s.monitorPasses.Add(1)
+	s.monitorLast.Store(int64(elapsed))
+	for {
+		longest := s.monitorMax.Load()
+		if int64(elapsed) <= longest || s.monitorMax.CompareAndSwap(longest, int64(elapsed)) {
+			return
+		}
+	}
+}
+
+// DebugVars returns the manager's counters, e.g. to publish with expvar
+func (m *Manager) DebugVars() map[string]any {
+	m.mutex.RLock()
+	services := len(m.services)
+	m.mutex.RUnlock()
+	subscribers, drops, version := m.subscribers.stats()
+
+	return map[string]any{
+		"services":        services,
+		"restarts":        m.stats.restarts.Load(),
+		"monitorPasses":   m.stats.monitorPasses.Load(),
+		"monitorLastMs":   utils.Milliseconds(time.Duration(m.stats.monitorLast.Load())),
+		"monitorMaxMs":    utils.Milliseconds(time.Duration(m.stats.monitorMax.Load())),
+		"subscribers":     subscribers,
+		"subscriberDrops": drops,
+		"statusVersion":   version,
+		"goroutines":      runtime.NumGoroutine(),
+	}
+}
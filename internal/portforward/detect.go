@@ -0,0 +1,137 @@
+package portforward
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/grpc/status"
+)
+
+// detectTimeout bounds each step of probing a service for its type
+const detectTimeout = 3 * time.Second
+
+// openAPIPaths are where REST services commonly publish their API description
+var openAPIPaths = []string{"/openapi.json", "/swagger.json", "/v3/api-docs", "/swagger/doc.json"}
+
+// detectServiceType probes a forward to infer its type: rpc when it speaks gRPC,
+// rest when it serves JSON or an OpenAPI document and web for any other HTTP
+// server, with or without TLS. It returns "" for other protocols and an error
+// when the forward doesn't accept connections yet.
+func detectServiceType(host string, port int) (string, error) {
+	address := net.JoinHostPort(host, strconv.Itoa(port))
+	conn, err := net.DialTimeout("tcp", address, detectTimeout)
+	if err != nil {
+		return "", err
+	}
+	conn.Close()
+
+	useTLS := speaksTLS(address)
+	creds := insecure.NewCredentials()
+	if useTLS {
+		creds = credentials.NewTLS(&tls.Config{InsecureSkipVerify: true})
+	}
+	if speaksGRPC(address, creds) {
+		return "rpc", nil
+	}
+
+	scheme := "http"
+	if useTLS {
+		scheme = "https"
+	}
+	return detectHTTPType(scheme + "://" + address), nil
+}
+
+// speaksTLS reports whether the server completes a TLS handshake
+func speaksTLS(address string) bool {
+	dialer := &net.Dialer{Timeout: detectTimeout}
+	conn, err := tls.DialWithDialer(dialer, "tcp", address, &tls.Config{InsecureSkipVerify: true})
+	if err != nil {
+		return false
+	}
+	conn.Close()
+	return true
+}
+
+// speaksGRPC asks for the server's services through gRPC reflection. A server
+// that answers with any gRPC status, even Unimplemented, speaks gRPC.
+func speaksGRPC(address string, creds credentials.TransportCredentials) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), detectTimeout)
+	defer cancel()
+
+	conn, err := grpc.DialContext(ctx, address, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return false
+	}
+	defer conn.Close()
+
+	stream, err := reflectionpb.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+	if err == nil {
+		err = stream.Send(&reflectionpb.ServerReflectionRequest{
+			MessageRequest: &reflectionpb.ServerReflectionRequest_ListServices{},
+		})
+	}
+	if err == nil {
+		_, err = stream.Recv()
+	}
+	if err == nil {
+		return true
+	}
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.Canceled, codes.Unknown, codes.Internal:
+		return false // Transport failures, or an HTTP/1 server's reply
+	}
+	return true
+}
+
+// detectHTTPType tells REST APIs from web apps; it returns "" when the server doesn't speak HTTP
+func detectHTTPType(baseURL string) string {
+	client := &http.Client{
+		Timeout: detectTimeout,
+		Transport: &http.Transport{
+			DisableKeepAlives: true,
+			TLSClientConfig:   &tls.Config{InsecureSkipVerify: true},
+		},
+	}
+
+	resp, err := client.Get(baseURL + "/")
+	if err != nil {
+		return ""
+	}
+	resp.Body.Close()
+	if isJSON(resp) {
+		return "rest"
+	}
+
+	for _, path := range openAPIPaths {
+		resp, err := client.Get(baseURL + path)
+		if err != nil {
+			continue
+		}
+		io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+		resp.Body.Close()
+		if resp.StatusCode == http.StatusOK && isJSON(resp) {
+			return "rest"
+		}
+	}
+	return "web"
+}
+
+// isJSON reports whether a response carries JSON
+func isJSON(resp *http.Response) bool {
+	mediaType, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if err != nil {
+		return false
+	}
+	return mediaType == "application/json" || mediaType == "application/problem+json"
+}
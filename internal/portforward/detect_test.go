@@ -0,0 +1,98 @@
+package portforward
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+)
+
+func TestDetectServiceType(t *testing.T) {
+	html := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte("<html></html>"))
+	})
+	openAPI := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/openapi.json" {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"openapi": "3.0.0"}`))
+			return
+		}
+		http.NotFound(w, r)
+	})
+	jsonRoot := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"status": "ok"}`))
+	})
+
+	tests := []struct {
+		name     string
+		server   *httptest.Server
+		expected string
+	}{
+		{"web", httptest.NewServer(html), "web"},
+		{"rest with openapi", httptest.NewServer(openAPI), "rest"},
+		{"rest with json", httptest.NewServer(jsonRoot), "rest"},
+		{"web over tls", httptest.NewTLSServer(html), "web"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			defer test.server.Close()
+			port := listenerPort(t, test.server.Listener)
+			serviceType, err := detectServiceType("127.0.0.1", port)
+			if err != nil || serviceType != test.expected {
+				t.Errorf("Expected %s, got %q (%v)", test.expected, serviceType, err)
+			}
+		})
+	}
+}
+
+func TestDetectGRPCServiceType(t *testing.T) {
+	for _, withReflection := range []bool{true, false} {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("Listen failed: %v", err)
+		}
+		server := grpc.NewServer()
+		if withReflection {
+			reflection.Register(server)
+		}
+		go server.Serve(listener)
+
+		serviceType, err := detectServiceType("127.0.0.1", listenerPort(t, listener))
+		server.Stop()
+		if err != nil || serviceType != "rpc" {
+			t.Errorf("Expected rpc (reflection: %v), got %q (%v)", withReflection, serviceType, err)
+		}
+	}
+}
+
+func TestDetectOtherServiceType(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	port := listenerPort(t, listener)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conn.Write([]byte("220 smtp.example.com ESMTP\r\n"))
+			conn.Close()
+		}
+	}()
+
+	if serviceType, err := detectServiceType("127.0.0.1", port); err != nil || serviceType != "" {
+		t.Errorf("Expected no type for a non-HTTP server, got %q (%v)", serviceType, err)
+	}
+
+	listener.Close()
+	if _, err := detectServiceType("127.0.0.1", port); err == nil {
+		t.Error("Expected an error for a closed port")
+	}
+}
@@ -0,0 +1,42 @@
+package portforward
+
+import (
+	"sync"
+	"time"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+)
+
+// errorHistorySize is how many recent errors are kept per service
+const errorHistorySize = 10
+
+// errorHistory keeps the most recent errors of a service
+type errorHistory struct {
+	recent []config.ErrorEntry
+	mutex  sync.Mutex
+}
+
+// add records an error, dropping the oldest once full
+func (h *errorHistory) add(message string) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if len(h.recent) == errorHistorySize {
+		copy(h.recent, h.recent[1:])
+		h.recent = h.recent[:errorHistorySize-1]
+	}
+	h.recent = append(h.recent, config.ErrorEntry{Time: time.Now(), Message: message})
+}
+
+// entries returns a copy of the errors, oldest first
+func (h *errorHistory) entries() []config.ErrorEntry {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if len(h.recent) == 0 {
+		return nil
+	}
+	entries := make([]config.ErrorEntry, len(h.recent))
+	copy(entries, h.recent)
+	return entries
+}
@@ -0,0 +1,41 @@
+package portforward
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+func TestErrorHistoryKeepsRecentErrors(t *testing.T) {
+	var history errorHistory
+	for i := 0; i < errorHistorySize+3; i++ {
+		history.add(fmt.Sprintf("error %d", i))
+	}
+
+	entries := history.entries()
+	if len(entries) != errorHistorySize {
+		t.Fatalf("Expected %d entries, got %d", errorHistorySize, len(entries))
+	}
+	if entries[0].Message != "error 3" || entries[len(entries)-1].Message != fmt.Sprintf("error %d", errorHistorySize+2) {
+		t.Errorf("Expected oldest errors to be dropped, got %q..%q", entries[0].Message, entries[len(entries)-1].Message)
+	}
+}
+
+func TestServiceStatusIncludesErrorHistory(t *testing.T) {
+	sm := NewServiceManager("api", config.Service{LocalPort: 8080}, utils.NewLogger(utils.LevelError))
+	sm.setError("connection refused")
+	sm.setError("connection refused")
+	sm.SetClusterError(errors.New("timeout"))
+	sm.SetClusterError(errors.New("timeout")) // Recorded once while the outage lasts
+
+	status := sm.GetStatus()
+	if len(status.RecentErrors) != 3 {
+		t.Fatalf("Expected 3 recorded errors, got %+v", status.RecentErrors)
+	}
+	if status.RecentErrors[2].Message != "API server unreachable: timeout" || status.RecentErrors[0].Time.IsZero() {
+		t.Errorf("Unexpected error history: %+v", status.RecentErrors)
+	}
+}
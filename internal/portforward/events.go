@@ -0,0 +1,78 @@
+package portforward
+
+import (
+	"time"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+)
+
+// EventType identifies the kind of lifecycle event emitted by the manager
+type EventType string
+
+const (
+	EventServiceStarted       EventType = "service.started"
+	EventServiceStartFailed   EventType = "service.start_failed"
+	EventServiceRestarted     EventType = "service.restarted"
+	EventServiceStateChanged  EventType = "service.state_changed"
+	EventPortReassigned       EventType = "service.port_reassigned"
+	EventContextChanged       EventType = "context.changed"
+	EventContextChangePending EventType = "context.change_pending"
+	EventStatusUpdated        EventType = "status.updated"
+	EventStartupProgress      EventType = "startup.progress"
+	EventRestartProgress      EventType = "restart.progress"
+)
+
+// Event describes a lifecycle change of a service or of the manager itself
+type Event struct {
+	Type      EventType
+	Timestamp time.Time
+
+	// Service events
+	Service        string
+	Status         config.ServiceStatus
+	PreviousStatus string
+	Error          string
+	Duration       time.Duration // Time taken by start/restart operations
+
+	// Context events
+	Context         string
+	PreviousContext string
+
+	// Progress of starting or restarting services (startup.progress and restart.progress events only)
+	Started int // Services done so far
+	Total   int
+
+	// Status snapshot of all services (status.updated events only); must not be modified
+	Snapshot map[string]config.ServiceStatus
+}
+
+// IsProgress reports whether the event only reports the progress of a bulk start or restart
+func (t EventType) IsProgress() bool {
+	return t == EventStartupProgress || t == EventRestartProgress
+}
+
+// EventListener receives manager events. Listeners are called synchronously
+// from the manager's goroutines and must not block.
+type EventListener func(Event)
+
+// AddEventListener registers a listener for lifecycle events
+func (m *Manager) AddEventListener(listener EventListener) {
+	m.listenerMutex.Lock()
+	defer m.listenerMutex.Unlock()
+	m.eventListeners = append(m.eventListeners, listener)
+}
+
+// emit delivers an event to all registered listeners
+func (m *Manager) emit(event Event) {
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+
+	m.listenerMutex.RLock()
+	listeners := m.eventListeners
+	m.listenerMutex.RUnlock()
+
+	for _, listener := range listeners {
+		listener(event)
+	}
+}
@@ -0,0 +1,268 @@
+package portforward
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// defaultHealthTimeout bounds a single health check unless the service configures one
+const defaultHealthTimeout = 2 * time.Second
+
+// HealthChecker decides whether a forward is healthy by probing its local endpoint
+type HealthChecker interface {
+	Check(ctx context.Context, host string, port int) error
+}
+
+// newHealthChecker returns the checker configured for a service. Without one, rpc
+// services are checked with the gRPC health protocol where the server implements it
+// and other services with a TCP connect.
+func newHealthChecker(service config.Service) (HealthChecker, error) {
+	check := service.HealthCheck
+	if check == nil {
+		if service.Type == "rpc" {
+			return grpcChecker{implicit: true}, nil
+		}
+		return tcpChecker{}, nil
+	}
+
+	switch check.Type {
+	case "", "tcp":
+		return tcpChecker{}, nil
+	case "http":
+		return newHTTPChecker(check)
+	case "grpc":
+		return grpcChecker{service: check.GRPCService}, nil
+	case "exec":
+		if len(check.Command) == 0 {
+			return nil, fmt.Errorf("exec health check needs a command")
+		}
+		return execChecker{command: check.Command}, nil
+	default:
+		return nil, fmt.Errorf("unknown health check type %q (expected tcp, http, grpc or exec)", check.Type)
+	}
+}
+
+// healthTimeout returns the configured timeout of a service's health check
+func healthTimeout(service config.Service) time.Duration {
+	if service.HealthCheck != nil && service.HealthCheck.Timeout > 0 {
+		return service.HealthCheck.Timeout
+	}
+	return defaultHealthTimeout
+}
+
+// CheckService probes a service's forward on a local port once with its configured health check
+func CheckService(ctx context.Context, service config.Service, port int) error {
+	checker, err := newHealthChecker(service)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, healthTimeout(service))
+	defer cancel()
+	return checker.Check(ctx, healthCheckHost(service.BindAddress), port)
+}
+
+// tcpChecker considers the forward healthy when it accepts connections
+type tcpChecker struct{}
+
+func (tcpChecker) Check(ctx context.Context, host string, port int) error {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(host, strconv.Itoa(port)))
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+// defaultHealthRedirects is how many redirects an http health check follows unless configured
+const defaultHealthRedirects = 5
+
+// httpChecker requests a path through the forward and expects one of the accepted
+// statuses, by default any 2xx or 3xx
+type httpChecker struct {
+	scheme string
+	path   string
+	accept []statusRange
+	client *http.Client
+}
+
+// statusRange is an inclusive range of accepted HTTP status codes
+type statusRange struct {
+	min, max int
+}
+
+// newHTTPChecker builds an http checker from its configuration
+func newHTTPChecker(check *config.HealthCheckConfig) (httpChecker, error) {
+	scheme := check.Scheme
+	switch scheme {
+	case "":
+		scheme = "http"
+	case "http", "https":
+	default:
+		return httpChecker{}, fmt.Errorf("unknown health check scheme %q (expected http or https)", scheme)
+	}
+	path := check.Path
+	if path == "" {
+		path = "/"
+	}
+
+	accept := []statusRange{{200, 399}}
+	if len(check.ExpectedStatus) > 0 {
+		accept = nil
+		for _, spec := range check.ExpectedStatus {
+			r, err := parseStatusRange(spec)
+			if err != nil {
+				return httpChecker{}, err
+			}
+			accept = append(accept, r)
+		}
+	}
+
+	maxRedirects := check.MaxRedirects
+	if maxRedirects == 0 {
+		maxRedirects = defaultHealthRedirects
+	}
+	client := &http.Client{
+		Transport: &http.Transport{
+			DisableKeepAlives: true,
+			TLSClientConfig:   &tls.Config{InsecureSkipVerify: check.InsecureSkipVerify},
+		},
+		// Redirects elsewhere, e.g. to a login page, show the backend answered; they
+		// aren't followed out of the forward
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if maxRedirects < 0 || req.URL.Host != via[0].URL.Host {
+				return http.ErrUseLastResponse
+			}
+			if len(via) > maxRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxRedirects)
+			}
+			return nil
+		},
+	}
+	return httpChecker{scheme: scheme, path: path, accept: accept, client: client}, nil
+}
+
+// parseStatusRange parses an accepted status: a code (200), a class (2xx) or a range (200-204)
+func parseStatusRange(spec string) (statusRange, error) {
+	spec = strings.TrimSpace(spec)
+	if len(spec) == 3 && strings.HasSuffix(strings.ToLower(spec), "xx") && spec[0] >= '1' && spec[0] <= '5' {
+		class := int(spec[0]-'0') * 100
+		return statusRange{class, class + 99}, nil
+	}
+	low, high, isRange := strings.Cut(spec, "-")
+	first, err := strconv.Atoi(strings.TrimSpace(low))
+	last := first
+	if err == nil && isRange {
+		last, err = strconv.Atoi(strings.TrimSpace(high))
+	}
+	if err != nil || first < 100 || last > 599 || first > last {
+		return statusRange{}, fmt.Errorf("invalid expected status %q (expected e.g. 200, 2xx or 200-204)", spec)
+	}
+	return statusRange{first, last}, nil
+}
+
+func (c httpChecker) Check(ctx context.Context, host string, port int) error {
+	url := fmt.Sprintf("%s://%s%s", c.scheme, net.JoinHostPort(host, strconv.Itoa(port)), c.path)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", "kportforward-health-check")
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	for _, r := range c.accept {
+		if resp.StatusCode >= r.min && resp.StatusCode <= r.max {
+			return nil
+		}
+	}
+	return fmt.Errorf("GET %s returned %s", resp.Request.URL.Path, resp.Status)
+}
+
+// grpcChecker calls the standard grpc.health.v1 Health/Check through the forward, for
+// a named service or the server as a whole. The implicit check of rpc services without
+// a configured one only fails on a status other than SERVING; when the call itself
+// fails, e.g. the server doesn't implement the protocol or uses TLS, it falls back to
+// a TCP connect.
+type grpcChecker struct {
+	service  string
+	implicit bool
+}
+
+func (c grpcChecker) Check(ctx context.Context, host string, port int) error {
+	conn, err := grpc.DialContext(ctx, net.JoinHostPort(host, strconv.Itoa(port)),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	resp, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{Service: c.service})
+	if err != nil {
+		if c.implicit {
+			return tcpChecker{}.Check(ctx, host, port)
+		}
+		return err
+	}
+	if resp.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+		if c.service != "" {
+			return fmt.Errorf("gRPC health status of %s: %s", c.service, resp.GetStatus())
+		}
+		return fmt.Errorf("gRPC health status %s", resp.GetStatus())
+	}
+	return nil
+}
+
+// DescribeHealthCheck names the check a service's health is probed with
+func DescribeHealthCheck(service config.Service) string {
+	if service.HealthCheck == nil && service.Type == "rpc" {
+		return "grpc"
+	}
+	return service.HealthCheck.String()
+}
+
+// execChecker runs a command and considers the forward healthy when it exits with 0.
+// $host and $port in the arguments are replaced with the forward's address.
+type execChecker struct {
+	command []string
+}
+
+func (c execChecker) Check(ctx context.Context, host string, port int) error {
+	expand := func(name string) string {
+		switch name {
+		case "host":
+			return host
+		case "port":
+			return strconv.Itoa(port)
+		default:
+			return os.Getenv(name)
+		}
+	}
+	args := make([]string, len(c.command))
+	for i, arg := range c.command {
+		args[i] = os.Expand(arg, expand)
+	}
+
+	output, err := exec.CommandContext(ctx, args[0], args[1:]...).CombinedOutput()
+	if err != nil {
+		if message := strings.TrimSpace(string(output)); message != "" {
+			return fmt.Errorf("%s: %w: %s", args[0], err, message)
+		}
+		return fmt.Errorf("%s: %w", args[0], err)
+	}
+	return nil
+}
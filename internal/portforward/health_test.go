@@ -0,0 +1,255 @@
+package portforward
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func listenerPort(t *testing.T, listener net.Listener) int {
+	t.Helper()
+	_, port, _ := net.SplitHostPort(listener.Addr().String())
+	n, err := strconv.Atoi(port)
+	if err != nil {
+		t.Fatalf("Unexpected listener address %s", listener.Addr())
+	}
+	return n
+}
+
+func checkWith(t *testing.T, check *config.HealthCheckConfig, port int) error {
+	t.Helper()
+	checker, err := newHealthChecker(config.Service{HealthCheck: check})
+	if err != nil {
+		t.Fatalf("newHealthChecker failed: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	return checker.Check(ctx, "127.0.0.1", port)
+}
+
+func TestTCPHealthCheck(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	port := listenerPort(t, listener)
+
+	if err := checkWith(t, nil, port); err != nil {
+		t.Errorf("Expected a listening port to be healthy: %v", err)
+	}
+	listener.Close()
+	if err := checkWith(t, nil, port); err == nil {
+		t.Error("Expected a closed port to be unhealthy")
+	}
+}
+
+func TestHTTPHealthCheck(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/healthz" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+	port := listenerPort(t, server.Listener)
+
+	if err := checkWith(t, &config.HealthCheckConfig{Type: "http", Path: "/healthz"}, port); err != nil {
+		t.Errorf("Expected /healthz to be healthy: %v", err)
+	}
+	if err := checkWith(t, &config.HealthCheckConfig{Type: "http"}, port); err == nil {
+		t.Error("Expected a 503 response to be unhealthy")
+	}
+}
+
+func TestHTTPHealthCheckRedirects(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/", "/loop":
+			http.Redirect(w, r, r.URL.Path+"x", http.StatusFound)
+		case "/x":
+			http.Redirect(w, r, "/ready", http.StatusFound)
+		case "/ready":
+		case "/login":
+			http.Redirect(w, r, "https://sso.example.com/auth", http.StatusFound)
+		default:
+			http.Redirect(w, r, r.URL.Path+"x", http.StatusFound)
+		}
+	}))
+	defer server.Close()
+	port := listenerPort(t, server.Listener)
+
+	check := &config.HealthCheckConfig{Type: "http", ExpectedStatus: []string{"200"}}
+	if err := checkWith(t, check, port); err != nil {
+		t.Errorf("Expected redirects on the same host to be followed: %v", err)
+	}
+	check.Path = "/loop"
+	if err := checkWith(t, check, port); err == nil {
+		t.Error("Expected an endless redirect to fail")
+	}
+	check.Path = "/login"
+	check.ExpectedStatus = nil
+	if err := checkWith(t, check, port); err != nil {
+		t.Errorf("Expected a redirect to another host to count as an answer: %v", err)
+	}
+	check.Path, check.MaxRedirects = "/", -1
+	check.ExpectedStatus = []string{"2xx"}
+	if err := checkWith(t, check, port); err == nil {
+		t.Error("Expected an unfollowed redirect to fail when only 2xx is accepted")
+	}
+}
+
+func TestHTTPSHealthCheck(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+	port := listenerPort(t, server.Listener)
+
+	check := &config.HealthCheckConfig{Type: "http", Scheme: "https"}
+	if err := checkWith(t, check, port); err == nil {
+		t.Error("Expected an untrusted certificate to fail")
+	}
+	check.InsecureSkipVerify = true
+	if err := checkWith(t, check, port); err != nil {
+		t.Errorf("Expected insecureSkipVerify to accept the certificate: %v", err)
+	}
+	if err := checkWith(t, &config.HealthCheckConfig{Type: "http"}, port); err == nil {
+		t.Error("Expected plain HTTP against a TLS backend to fail")
+	}
+}
+
+func TestParseStatusRange(t *testing.T) {
+	for spec, want := range map[string]statusRange{"200": {200, 200}, "2xx": {200, 299}, "200-204": {200, 204}, " 4XX ": {400, 499}} {
+		got, err := parseStatusRange(spec)
+		if err != nil || got != want {
+			t.Errorf("parseStatusRange(%q) = %v, %v; expected %v", spec, got, err, want)
+		}
+	}
+	for _, spec := range []string{"ok", "6xx", "204-200", "99"} {
+		if _, err := parseStatusRange(spec); err == nil {
+			t.Errorf("Expected %q to be rejected", spec)
+		}
+	}
+}
+
+func TestGRPCHealthCheck(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	server := grpc.NewServer()
+	healthServer := health.NewServer()
+	healthpb.RegisterHealthServer(server, healthServer)
+	go server.Serve(listener)
+	defer server.Stop()
+	port := listenerPort(t, listener)
+
+	if err := checkWith(t, &config.HealthCheckConfig{Type: "grpc"}, port); err != nil {
+		t.Errorf("Expected a serving gRPC server to be healthy: %v", err)
+	}
+	healthServer.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+	if err := checkWith(t, &config.HealthCheckConfig{Type: "grpc"}, port); err == nil {
+		t.Error("Expected NOT_SERVING to be unhealthy")
+	}
+}
+
+func TestGRPCHealthCheckServiceName(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	server := grpc.NewServer()
+	healthServer := health.NewServer()
+	healthpb.RegisterHealthServer(server, healthServer)
+	go server.Serve(listener)
+	defer server.Stop()
+	port := listenerPort(t, listener)
+
+	healthServer.SetServingStatus("payments.v1.Payments", healthpb.HealthCheckResponse_NOT_SERVING)
+	check := &config.HealthCheckConfig{Type: "grpc", GRPCService: "payments.v1.Payments"}
+	if err := checkWith(t, check, port); err == nil {
+		t.Error("Expected the named service's NOT_SERVING to be unhealthy")
+	}
+	if err := checkWith(t, &config.HealthCheckConfig{Type: "grpc"}, port); err != nil {
+		t.Errorf("Expected the server as a whole to be healthy: %v", err)
+	}
+}
+
+func TestRPCServicesDefaultToGRPCHealth(t *testing.T) {
+	serve := func(healthServer *health.Server) int {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("Listen failed: %v", err)
+		}
+		server := grpc.NewServer()
+		if healthServer != nil {
+			healthpb.RegisterHealthServer(server, healthServer)
+		}
+		go server.Serve(listener)
+		t.Cleanup(server.Stop)
+		return listenerPort(t, listener)
+	}
+	rpc := config.Service{Type: "rpc"}
+
+	if err := CheckService(context.Background(), rpc, serve(nil)); err != nil {
+		t.Errorf("Expected a server without the health protocol to fall back to TCP: %v", err)
+	}
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+	if err := CheckService(context.Background(), rpc, serve(healthServer)); err == nil {
+		t.Error("Expected NOT_SERVING to fail an rpc service without a configured check")
+	}
+	if name := DescribeHealthCheck(rpc); name != "grpc" {
+		t.Errorf("Expected rpc services to be described as grpc, got %q", name)
+	}
+}
+
+func TestExecHealthCheck(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses the test command")
+	}
+	check := &config.HealthCheckConfig{Type: "exec", Command: []string{"test", "$port", "=", "8080"}}
+	if err := checkWith(t, check, 8080); err != nil {
+		t.Errorf("Expected the command to succeed: %v", err)
+	}
+	if err := checkWith(t, check, 8081); err == nil {
+		t.Error("Expected a failing command to be unhealthy")
+	}
+}
+
+func TestCheckServiceUsesConfiguredCheck(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/ready" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	port := listenerPort(t, server.Listener)
+
+	service := config.Service{HealthCheck: &config.HealthCheckConfig{Type: "http", Path: "/ready"}}
+	if err := CheckService(context.Background(), service, port); err != nil {
+		t.Errorf("Expected /ready to pass: %v", err)
+	}
+	service.HealthCheck.Path = "/missing"
+	if err := CheckService(context.Background(), service, port); err == nil {
+		t.Error("Expected a 404 to fail")
+	}
+}
+
+func TestInvalidHealthCheck(t *testing.T) {
+	for _, check := range []*config.HealthCheckConfig{{Type: "icmp"}, {Type: "exec"},
+		{Type: "http", Scheme: "ftp"}, {Type: "http", ExpectedStatus: []string{"fine"}}} {
+		if _, err := newHealthChecker(config.Service{HealthCheck: check}); err == nil {
+			t.Errorf("Expected %+v to be rejected", check)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package portforward
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+// defaultHookTimeout bounds a hook command unless hooks.timeout is set
+const defaultHookTimeout = 30 * time.Second
+
+// hookCommand returns the service's command for a hook, or "" when it has none
+func (sm *ServiceManager) hookCommand(hook string) string {
+	hooks := sm.config.Hooks
+	if hooks == nil {
+		return ""
+	}
+	switch hook {
+	case "preStart":
+		return hooks.PreStart
+	case "postStart":
+		return hooks.PostStart
+	case "preStop":
+		return hooks.PreStop
+	case "onFailure":
+		return hooks.OnFailure
+	}
+	return ""
+}
+
+// hookEnv returns the environment a hook runs with: the service's env plus its
+// details as KPF_* variables. The caller holds the mutex.
+func (sm *ServiceManager) hookEnv(hook string) []string {
+	env := sm.config.Environ()
+	if env == nil {
+		env = os.Environ()
+	}
+	return append(env,
+		"KPF_HOOK="+hook,
+		"KPF_SERVICE="+sm.name,
+		"KPF_TYPE="+sm.serviceType(),
+		"KPF_TARGET="+sm.config.Target,
+		"KPF_TARGET_PORT="+strconv.Itoa(sm.config.TargetPort),
+		"KPF_NAMESPACE="+sm.config.Namespace,
+		"KPF_CONTEXT="+sm.status.Context,
+		"KPF_LOCAL_PORT="+strconv.Itoa(sm.status.LocalPort),
+		"KPF_STATUS="+sm.status.Status,
+		"KPF_ERROR="+sm.status.LastError,
+		"KPF_RESTART_COUNT="+strconv.Itoa(sm.status.RestartCount),
+	)
+}
+
+// runHook runs a hook and waits for it; it returns nil when the service has no such hook.
+// The caller doesn't hold the mutex, so a slow hook doesn't hold up status reads.
+func (sm *ServiceManager) runHook(hook string) error {
+	sm.mutex.RLock()
+	command := sm.hookCommand(hook)
+	env, timeout := sm.hookEnv(hook), sm.hookTimeout()
+	sm.mutex.RUnlock()
+
+	if command == "" {
+		return nil
+	}
+	return sm.execHook(hook, command, env, timeout)
+}
+
+// runHookAsync runs a hook in the background, logging a failure. The caller holds the mutex.
+func (sm *ServiceManager) runHookAsync(hook string) {
+	command := sm.hookCommand(hook)
+	if command == "" {
+		return
+	}
+	env, timeout := sm.hookEnv(hook), sm.hookTimeout()
+	go sm.execHook(hook, command, env, timeout)
+}
+
+// hookTimeout returns how long a hook may run. The caller holds the mutex.
+func (sm *ServiceManager) hookTimeout() time.Duration {
+	if sm.config.Hooks != nil && sm.config.Hooks.Timeout > 0 {
+		return sm.config.Hooks.Timeout
+	}
+	return defaultHookTimeout
+}
+
+// execHook runs a hook command through the shell, logging its outcome
+func (sm *ServiceManager) execHook(hook, command string, env []string, timeout time.Duration) error {
+	// Not sm.ctx: preStop also runs during shutdown, after it is cancelled
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	cmd := utils.ShellCommand(ctx, command)
+	cmd.Env = env
+	output, err := cmd.CombinedOutput()
+	message := strings.TrimSpace(string(output))
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			err = fmt.Errorf("timed out after %s", timeout)
+		}
+		if message != "" {
+			err = fmt.Errorf("%w: %s", err, message)
+		}
+		sm.logger.Warn("%s hook for %s failed: %v", hook, sm.name, err)
+		return fmt.Errorf("%s hook failed: %w", hook, err)
+	}
+
+	sm.logger.Debug("%s hook for %s succeeded: %s", hook, sm.name, message)
+	return nil
+}
@@ -0,0 +1,138 @@
+package portforward
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+func TestHookEnvironment(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+
+	out := filepath.Join(t.TempDir(), "hook.out")
+	service := config.Service{
+		Target:     "service/postgres",
+		TargetPort: 5432,
+		LocalPort:  15432,
+		Namespace:  "data",
+		Type:       "other",
+		Env:        map[string]string{"PGUSER": "app"},
+		Hooks:      &config.HooksConfig{PreStop: `echo "$KPF_HOOK $KPF_SERVICE $KPF_TARGET $KPF_LOCAL_PORT $KPF_STATUS $PGUSER" > ` + out},
+	}
+	sm := NewServiceManager("postgres", service, utils.NewLogger(utils.LevelError))
+	sm.status.Status = "Running"
+
+	if err := sm.runHook("preStop"); err != nil {
+		t.Fatalf("runHook failed: %v", err)
+	}
+	data, _ := os.ReadFile(out)
+	if got := strings.TrimSpace(string(data)); got != "preStop postgres service/postgres 15432 Running app" {
+		t.Errorf("Unexpected hook environment: %q", got)
+	}
+
+	if err := sm.runHook("postStart"); err != nil {
+		t.Errorf("Expected a missing hook to be skipped, got %v", err)
+	}
+}
+
+func TestPreStartHookFailureFailsStart(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+
+	service := config.Service{
+		Target:     "db.internal",
+		TargetPort: 5432,
+		LocalPort:  15433,
+		Type:       "ssh",
+		Hooks:      &config.HooksConfig{PreStart: "echo token expired; exit 1"},
+	}
+	sm := NewServiceManager("db", service, utils.NewLogger(utils.LevelError))
+
+	err := sm.Start()
+	if err == nil || !strings.Contains(err.Error(), "preStart hook failed") {
+		t.Fatalf("Expected the preStart hook to fail the start, got %v", err)
+	}
+	status := sm.GetStatus()
+	if status.Status != "Failed" || !strings.Contains(status.LastError, "token expired") {
+		t.Errorf("Expected a failed status with the hook's output, got %+v", status)
+	}
+}
+
+func TestHookTimeout(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+
+	service := config.Service{Hooks: &config.HooksConfig{PreStop: "sleep 5", Timeout: 100 * time.Millisecond}}
+	sm := NewServiceManager("slow", service, utils.NewLogger(utils.LevelError))
+
+	started := time.Now()
+	err := sm.runHook("preStop")
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("Expected a timeout, got %v", err)
+	}
+	if elapsed := time.Since(started); elapsed > 3*time.Second {
+		t.Errorf("Expected the hook to be cut short, took %v", elapsed)
+	}
+}
+
+func TestOnFailureHookRunsWhenServiceFails(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+
+	out := filepath.Join(t.TempDir(), "failure.out")
+	service := config.Service{Hooks: &config.HooksConfig{OnFailure: `echo "$KPF_ERROR" > ` + out}}
+	sm := NewServiceManager("api", service, utils.NewLogger(utils.LevelError))
+
+	sm.mutex.Lock()
+	sm.status.Status = "Failed"
+	sm.setError("connection refused")
+	sm.mutex.Unlock()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		data, _ := os.ReadFile(out)
+		if strings.TrimSpace(string(data)) == "connection refused" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected the onFailure hook to run with the error, got %q", data)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestSlowHookDoesNotBlockStatus(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+
+	service := config.Service{Target: "db.internal", TargetPort: 5432, LocalPort: 15438, Type: "ssh",
+		Hooks: &config.HooksConfig{PreStart: "sleep 2; exit 1"}}
+	sm := NewServiceManager("db", service, utils.NewLogger(utils.LevelError))
+
+	done := make(chan error)
+	go func() { done <- sm.Start() }()
+	time.Sleep(100 * time.Millisecond)
+
+	read := make(chan string)
+	go func() { read <- sm.GetStatus().Status }()
+	select {
+	case <-read:
+	case <-time.After(time.Second):
+		t.Fatal("Expected GetStatus to return while the preStart hook runs")
+	}
+	if err := <-done; err == nil {
+		t.Error("Expected the failing hook to fail the start")
+	}
+}
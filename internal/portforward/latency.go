@@ -0,0 +1,68 @@
+package portforward
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// latencyWindowSize is how many recent health checks the percentiles are computed over
+const latencyWindowSize = 20
+
+// latencyWindow keeps the round-trip times of the most recent health checks
+type latencyWindow struct {
+	samples []time.Duration
+	next    int
+	mutex   sync.Mutex
+}
+
+// add records the round-trip time of a successful health check
+func (w *latencyWindow) add(rtt time.Duration) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if len(w.samples) < latencyWindowSize {
+		w.samples = append(w.samples, rtt)
+		return
+	}
+	w.samples[w.next] = rtt
+	w.next = (w.next + 1) % latencyWindowSize
+}
+
+// reset discards samples, e.g. after the forward restarted
+func (w *latencyWindow) reset() {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.samples = nil
+	w.next = 0
+}
+
+// stats returns the latest sample and the rolling p50 and p95
+func (w *latencyWindow) stats() (last, p50, p95 time.Duration) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if len(w.samples) == 0 {
+		return 0, 0, 0
+	}
+
+	latest := (w.next - 1 + len(w.samples)) % len(w.samples)
+	if len(w.samples) < latencyWindowSize {
+		latest = len(w.samples) - 1
+	}
+
+	sorted := make([]time.Duration, len(w.samples))
+	copy(sorted, w.samples)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	return w.samples[latest], percentile(sorted, 50), percentile(sorted, 95)
+}
+
+// percentile returns the nearest-rank percentile of sorted samples
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
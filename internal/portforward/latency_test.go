@@ -0,0 +1,34 @@
+package portforward
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLatencyWindowPercentiles(t *testing.T) {
+	var window latencyWindow
+	if last, p50, p95 := window.stats(); last != 0 || p50 != 0 || p95 != 0 {
+		t.Errorf("Expected zero stats without samples, got %v %v %v", last, p50, p95)
+	}
+
+	// 1ms..30ms; only the last 20 (11ms..30ms) stay in the window
+	for i := 1; i <= 30; i++ {
+		window.add(time.Duration(i) * time.Millisecond)
+	}
+
+	last, p50, p95 := window.stats()
+	if last != 30*time.Millisecond {
+		t.Errorf("Expected last sample 30ms, got %v", last)
+	}
+	if p50 != 20*time.Millisecond {
+		t.Errorf("Expected p50 20ms, got %v", p50)
+	}
+	if p95 != 29*time.Millisecond {
+		t.Errorf("Expected p95 29ms, got %v", p95)
+	}
+
+	window.reset()
+	if last, _, _ := window.stats(); last != 0 {
+		t.Errorf("Expected reset to clear samples, got %v", last)
+	}
+}
@@ -3,12 +3,18 @@ package portforward
 import (
 	"context"
 	"fmt"
+	"net"
+	"os"
 	"os/exec"
 	"reflect"
+	"sort"
+	"strconv"
 	"sync"
 	"time"
 
+	"github.com/victorkazakov/kportforward/internal/agent"
 	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/kubeconfig"
 	"github.com/victorkazakov/kportforward/internal/utils"
 )
 
@@ -20,6 +26,13 @@ type UIHandler interface {
 	IsEnabled() bool
 }
 
+const (
+	// defaultStartupParallelism is how many services start concurrently unless configured
+	defaultStartupParallelism = 8
+	// defaultStartupStagger spaces out service starts unless configured
+	defaultStartupStagger = 50 * time.Millisecond
+)
+
 // Manager coordinates multiple port-forward services
 type Manager struct {
 	services          map[string]*ServiceManager
@@ -28,15 +41,35 @@ type Manager struct {
 	ctx               context.Context
 	cancel            context.CancelFunc
 	mutex             sync.RWMutex
-	kubernetesContext string
+	kubernetesContext string // Context the services following the current context forward in
+	observedContext   string // Current kubectl context as last seen; owned by the monitor loop
+	pendingContext    string // Context change waiting for an answer under contextPolicy ask
+	pinnedContext     string // Context services are pinned to after a context change was kept
+
+	// Tunnel through the in-cluster agent, when agent mode is enabled
+	agentTunnel *agent.Tunnel
 
 	// UI Handlers
 	grpcUIHandler    UIHandler
 	swaggerUIHandler UIHandler
+	extraUIHandlers  []UIHandler
 
 	// Monitoring
 	monitoringTicker *time.Ticker
-	statusChan       chan map[string]config.ServiceStatus
+	resume           *resumeDetector
+	cluster          *clusterProbe
+	subscribers      subscribers
+	availability     *availabilityTracker
+	wildcards        wildcardState
+	filter           config.ServiceFilter // Services to run, e.g. from --only and --exclude
+	stats            debugStats
+	lastStates       map[string]string // Last observed status per service, owned by the monitor loop
+	lastPorts        map[string]int    // Last observed local port per running service, owned by the monitor loop
+	wake             chan struct{}     // Requests an immediate monitoring pass
+
+	// Lifecycle events
+	eventListeners []EventListener
+	listenerMutex  sync.RWMutex
 }
 
 // NewManager creates a new port-forward manager
@@ -44,15 +77,28 @@ func NewManager(cfg *config.Config, logger *utils.Logger) *Manager {
 	ctx, cancel := context.WithCancel(context.Background())
 
 	return &Manager{
-		services:   make(map[string]*ServiceManager),
-		config:     cfg,
-		logger:     logger,
-		ctx:        ctx,
-		cancel:     cancel,
-		statusChan: make(chan map[string]config.ServiceStatus, 1),
+		services:     make(map[string]*ServiceManager),
+		config:       cfg,
+		logger:       logger,
+		ctx:          ctx,
+		cancel:       cancel,
+		lastStates:   make(map[string]string),
+		lastPorts:    make(map[string]int),
+		wake:         make(chan struct{}, 1),
+		cluster:      newClusterProbe(),
+		availability: newAvailabilityTracker(),
 	}
 }
 
+// SetServiceFilter limits the services run to those the filter selects, including the
+// forwards of wildcard entries. Once started, it takes effect when services are applied
+// next, e.g. on switching profiles.
+func (m *Manager) SetServiceFilter(filter config.ServiceFilter) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.filter = filter
+}
+
 // SetUIHandlers sets the UI handlers for the manager
 func (m *Manager) SetUIHandlers(grpcUI, swaggerUI UIHandler) {
 	m.mutex.Lock()
@@ -61,6 +107,13 @@ func (m *Manager) SetUIHandlers(grpcUI, swaggerUI UIHandler) {
 	m.swaggerUIHandler = swaggerUI
 }
 
+// AddUIHandler registers an additional UI handler (e.g. the browser opener)
+func (m *Manager) AddUIHandler(handler UIHandler) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.extraUIHandlers = append(m.extraUIHandlers, handler)
+}
+
 // Start initializes and starts all port-forward services
 func (m *Manager) Start() error {
 	m.mutex.Lock()
@@ -71,32 +124,199 @@ func (m *Manager) Start() error {
 		return fmt.Errorf("failed to get Kubernetes context: %w", err)
 	}
 
-	// Create service managers
-	for name, serviceConfig := range m.config.PortForwards {
-		sm := NewServiceManager(name, serviceConfig, m.logger)
+	// The forward to the agent is run and monitored like any other service
+	if m.config.Agent.Enabled {
+		if err := m.setupAgent(); err != nil {
+			return fmt.Errorf("failed to set up agent mode: %w", err)
+		}
+	}
+
+	if m.config.Availability.Persist {
+		m.loadAvailability()
+	}
+
+	// Create service managers, with a forward for each Service a wildcard entry matches
+	services, wildcards := m.expandServices(m.config.PortForwards, m.filter)
+	m.wildcards.entries = wildcards
+	m.wildcards.lastRefresh = time.Now()
+	for name, serviceConfig := range services {
+		sm := m.newServiceManager(name, serviceConfig)
 		m.services[name] = sm
 	}
+	m.resolveContexts(m.services)
 
 	// Start all services
-	var startErrors []error
-	for name, sm := range m.services {
-		if err := sm.Start(); err != nil {
-			m.logger.Error("Failed to start service %s: %v", name, err)
-			startErrors = append(startErrors, err)
-		}
-	}
+	failed := m.startServices(m.services)
 
 	// Start monitoring
 	m.startMonitoring()
 
-	if len(startErrors) > 0 {
-		return fmt.Errorf("failed to start %d services", len(startErrors))
+	if failed > 0 {
+		return fmt.Errorf("failed to start %d services", failed)
 	}
 
 	m.logger.Info("Started %d port-forward services", len(m.services))
 	return nil
 }
 
+// newServiceManager creates a service manager that wakes the monitor when its forward
+// exits; services the agent can reach are routed through it
+func (m *Manager) newServiceManager(name string, service config.Service) *ServiceManager {
+	sm := NewServiceManager(name, m.config.WithServiceDefaults(service), m.logger)
+	sm.SetCapture(m.config.Capture)
+	sm.pinnedContext = m.pinnedContext
+	sm.onExit = m.wakeMonitor
+	if m.agentTunnel != nil && name != agent.ServiceName && agent.Eligible(service) {
+		sm.agentTunnel = m.agentTunnel
+	}
+	return sm
+}
+
+// setupAgent adds the forward to the in-cluster agent as a service, started first and
+// never cooled down, and the tunnel the other services are routed through. The caller
+// holds the mutex.
+func (m *Manager) setupAgent() error {
+	settings := m.config.Agent
+	if settings.Namespace == "" {
+		settings.Namespace = agent.DefaultNamespace
+	}
+	if settings.Target == "" {
+		settings.Target = agent.DefaultTarget
+	}
+	if settings.Port == 0 {
+		settings.Port = agent.DefaultPort
+	}
+	token := settings.Token
+	if settings.TokenEnv != "" {
+		token = os.Getenv(settings.TokenEnv)
+	}
+
+	port, err := utils.FindFreeLoopbackPort()
+	if err != nil {
+		return err
+	}
+	sm := m.newServiceManager(agent.ServiceName, config.Service{
+		Target:     settings.Target,
+		TargetPort: settings.Port,
+		LocalPort:  port,
+		Namespace:  settings.Namespace,
+		Type:       "other",
+		Priority:   "critical",
+		Pinned:     true,
+	})
+	m.services[agent.ServiceName] = sm
+
+	m.agentTunnel = &agent.Tunnel{
+		Token: token,
+		Address: func() (string, error) {
+			status := sm.GetStatus()
+			if status.Status != "Running" {
+				return "", fmt.Errorf("forward to the agent is %s", status.Status)
+			}
+			return net.JoinHostPort("127.0.0.1", strconv.Itoa(status.LocalPort)), nil
+		},
+	}
+	m.logger.Info("Agent mode: routing services through %s in namespace %s", settings.Target, settings.Namespace)
+	return nil
+}
+
+// wakeMonitor runs a monitoring pass right away instead of at the next tick
+func (m *Manager) wakeMonitor() {
+	select {
+	case m.wake <- struct{}{}:
+	default: // A pass is already pending
+	}
+}
+
+// startServices starts services through a bounded worker pool, spacing out the
+// starts so their health check grace periods and restarts don't all line up.
+// It returns the number of services that failed to start.
+func (m *Manager) startServices(services map[string]*ServiceManager) int {
+	return m.runServices(services, EventStartupProgress, m.startService)
+}
+
+// runServices runs an operation for each service through a bounded worker pool in
+// priority order, emitting progress events of the given type as services finish.
+// It returns the number of services the operation failed for.
+func (m *Manager) runServices(services map[string]*ServiceManager, progressType EventType, run func(string, *ServiceManager) error) int {
+	parallelism := m.config.Startup.Parallelism
+	if parallelism <= 0 {
+		parallelism = defaultStartupParallelism
+	}
+	stagger := m.config.Startup.Stagger
+	if stagger <= 0 {
+		stagger = defaultStartupStagger
+	}
+
+	names := make([]string, 0, len(services))
+	for name := range services {
+		names = append(names, name)
+	}
+	sortByPriority(names, services)
+
+	var (
+		wg       sync.WaitGroup
+		progress sync.Mutex
+		done     int
+		failed   int
+	)
+	queue := make(chan string)
+	for i := 0; i < parallelism && i < len(names); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for name := range queue {
+				err := run(name, services[name])
+
+				// Emitted under the lock so listeners see the count increase monotonically
+				progress.Lock()
+				done++
+				if err != nil {
+					failed++
+				}
+				m.emit(Event{Type: progressType, Started: done, Total: len(names)})
+				progress.Unlock()
+
+				// Let the TUI update rows while the remaining services run; only the
+				// services that changed are sent
+				snapshot := make(Snapshot, len(services))
+				for serviceName, service := range services {
+					status := service.GetStatus()
+					status.Availability = m.availability.get(serviceName)
+					snapshot[serviceName] = status
+				}
+				m.subscribers.publish(snapshot, false)
+			}
+		}()
+	}
+
+	for i, name := range names {
+		if i > 0 {
+			time.Sleep(stagger)
+		}
+		queue <- name
+	}
+	close(queue)
+	wg.Wait()
+
+	return failed
+}
+
+// startService starts a service and emits a started or start failed event
+func (m *Manager) startService(name string, sm *ServiceManager) error {
+	began := time.Now()
+	err := sm.Start()
+
+	if err != nil {
+		m.logger.Error("Failed to start service %s: %v", name, err)
+		m.emit(Event{Type: EventServiceStartFailed, Service: name, Status: sm.GetStatus(),
+			Error: err.Error(), Duration: time.Since(began)})
+	} else {
+		m.emit(Event{Type: EventServiceStarted, Service: name, Status: sm.GetStatus(), Duration: time.Since(began)})
+	}
+	return err
+}
+
 // Stop gracefully stops all services
 func (m *Manager) Stop() error {
 	m.mutex.Lock()
@@ -108,20 +328,9 @@ func (m *Manager) Stop() error {
 	}
 
 	// Stop UI handlers
-	if m.grpcUIHandler != nil && !isNilInterface(m.grpcUIHandler) && m.grpcUIHandler.IsEnabled() {
-		for serviceName := range m.services {
-			if err := m.grpcUIHandler.StopService(serviceName); err != nil {
-				m.logger.Error("Failed to stop gRPC UI for %s: %v", serviceName, err)
-			}
-		}
-	}
-
-	if m.swaggerUIHandler != nil && !isNilInterface(m.swaggerUIHandler) && m.swaggerUIHandler.IsEnabled() {
-		for serviceName := range m.services {
-			if err := m.swaggerUIHandler.StopService(serviceName); err != nil {
-				m.logger.Error("Failed to stop Swagger UI for %s: %v", serviceName, err)
-			}
-		}
+	handlers := m.enabledUIHandlers()
+	for serviceName := range m.services {
+		m.stopUIHandlers(handlers, serviceName)
 	}
 
 	// Stop all services
@@ -129,20 +338,20 @@ func (m *Manager) Stop() error {
 		if err := sm.Stop(); err != nil {
 			m.logger.Error("Failed to stop service %s: %v", name, err)
 		}
+		sm.deleteUDPPod()
 	}
 
 	m.cancel()
-	close(m.statusChan)
+	m.subscribers.close()
+
+	if m.config.Availability.Persist {
+		m.saveAvailability()
+	}
 
 	m.logger.Info("Stopped all port-forward services")
 	return nil
 }
 
-// GetStatusChannel returns a channel that receives status updates
-func (m *Manager) GetStatusChannel() <-chan map[string]config.ServiceStatus {
-	return m.statusChan
-}
-
 // GetCurrentStatus returns the current status of all services
 func (m *Manager) GetCurrentStatus() map[string]config.ServiceStatus {
 	m.mutex.RLock()
@@ -150,11 +359,48 @@ func (m *Manager) GetCurrentStatus() map[string]config.ServiceStatus {
 
 	status := make(map[string]config.ServiceStatus)
 	for name, sm := range m.services {
-		status[name] = sm.GetStatus()
+		serviceStatus := sm.GetStatus()
+		serviceStatus.Availability = m.availability.get(name)
+		status[name] = serviceStatus
 	}
 	return status
 }
 
+// ServiceConfigs returns the current configuration of all services, including
+// services added or edited since startup
+func (m *Manager) ServiceConfigs() map[string]config.Service {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	configs := make(map[string]config.Service, len(m.services))
+	for name, sm := range m.services {
+		configs[name] = sm.Config()
+	}
+	return configs
+}
+
+// loadAvailability restores the availability tracked in previous sessions
+func (m *Manager) loadAvailability() {
+	path, err := availabilityPath()
+	if err == nil {
+		err = m.availability.load(path)
+	}
+	if err != nil {
+		m.logger.Warn("Failed to restore service availability: %v", err)
+	}
+}
+
+// saveAvailability keeps the tracked availability for the next session
+func (m *Manager) saveAvailability() {
+	path, err := availabilityPath()
+	if err == nil {
+		err = m.availability.save(path)
+	}
+	if err != nil {
+		m.logger.Warn("Failed to save service availability: %v", err)
+	}
+}
+
 // RestartService restarts a specific service
 func (m *Manager) RestartService(name string) error {
 	m.mutex.RLock()
@@ -164,8 +410,231 @@ func (m *Manager) RestartService(name string) error {
 	if !exists {
 		return fmt.Errorf("service %s not found", name)
 	}
+	if sm.IsPaused() {
+		return fmt.Errorf("service %s is paused; resume it first", name)
+	}
+
+	return m.restartService(name, sm)
+}
+
+// StartService starts a stopped or paused service, skipping any cooldown from earlier failures
+func (m *Manager) StartService(name string) error {
+	m.mutex.RLock()
+	sm, exists := m.services[name]
+	m.mutex.RUnlock()
+
+	if !exists {
+		return fmt.Errorf("service %s not found", name)
+	}
+	status := sm.GetStatus().Status
+	if status == "Running" {
+		return nil
+	}
+
+	sm.Unpause()
+	if status != "Stopped" && status != StatusPaused {
+		// e.g. failed by a health check or suspended, with kubectl or the relay still up
+		if err := sm.Stop(); err != nil {
+			m.logger.Warn("Error stopping service %s before starting it: %v", name, err)
+		}
+	}
+	sm.resetBackoff()
+	return m.startService(name, sm)
+}
+
+// StopService stops a specific service and its UIs; it stays stopped until started or restarted
+func (m *Manager) StopService(name string) error {
+	m.mutex.RLock()
+	sm, exists := m.services[name]
+	handlers := m.enabledUIHandlers()
+	m.mutex.RUnlock()
+
+	if !exists {
+		return fmt.Errorf("service %s not found", name)
+	}
+
+	m.stopUIHandlers(handlers, name)
+	return sm.Stop()
+}
+
+// AddService adds a service that isn't in the configuration yet and starts it
+func (m *Manager) AddService(name string, service config.Service) error {
+	if name == "" {
+		return fmt.Errorf("service name must not be empty")
+	}
+
+	m.mutex.Lock()
+	if _, exists := m.services[name]; exists {
+		m.mutex.Unlock()
+		return fmt.Errorf("service %s already exists", name)
+	}
+	sm := m.newServiceManager(name, service)
+	m.services[name] = sm
+	m.mutex.Unlock()
+
+	m.resolveContexts(map[string]*ServiceManager{name: sm})
+	m.logger.Info("Added service %s", name)
+	return m.startService(name, sm)
+}
+
+// RemoveService stops a service and its UIs and removes it from the manager
+func (m *Manager) RemoveService(name string) error {
+	m.mutex.Lock()
+	sm, exists := m.services[name]
+	if !exists {
+		m.mutex.Unlock()
+		return fmt.Errorf("service %s not found", name)
+	}
+	delete(m.services, name)
+	handlers := m.enabledUIHandlers()
+	m.mutex.Unlock()
+
+	m.stopUIHandlers(handlers, name)
+	sm.Shutdown()
+	m.logger.Info("Removed service %s", name)
+	return nil
+}
+
+// ApplyServices brings the running services in line with an updated set of
+// port-forwards: new services are started, missing ones removed and changed ones
+// restarted. Services whose configuration is unchanged keep running.
+func (m *Manager) ApplyServices(services map[string]config.Service) config.ServiceDiff {
+	m.mutex.RLock()
+	filter := m.filter
+	m.mutex.RUnlock()
+	services, wildcards := m.expandServices(services, filter)
+
+	m.mutex.Lock()
+	m.wildcards.entries = wildcards
+	m.mutex.Unlock()
+
+	m.mutex.RLock()
+	running := make(map[string]config.Service, len(m.services))
+	for name, sm := range m.services {
+		if m.agentTunnel != nil && name == agent.ServiceName {
+			continue // Not part of the configured services
+		}
+		running[name] = sm.config
+	}
+	updated := make(map[string]config.Service, len(services))
+	for name, service := range services {
+		updated[name] = m.config.WithServiceDefaults(service)
+	}
+	m.mutex.RUnlock()
+
+	diff := config.DiffServices(running, updated)
+	for _, name := range diff.Removed {
+		if err := m.RemoveService(name); err != nil {
+			m.logger.Warn("Failed to remove %s: %v", name, err)
+		}
+	}
+	for _, name := range diff.Added {
+		m.AddService(name, services[name]) // Start failures are logged and retried by the monitor
+	}
+	for _, name := range diff.Changed {
+		if err := m.UpdateService(name, services[name]); err != nil {
+			m.logger.Warn("Failed to restart %s: %v", name, err)
+		}
+	}
+	m.logger.Info("Applied configuration: %s", diff)
+	return diff
+}
+
+// SwapTarget switches which of a service's targets backs its local port, restarting
+// only that forward; an empty target switches to the next alternate. It returns the
+// service with its new target.
+func (m *Manager) SwapTarget(name, target string) (config.Service, error) {
+	m.mutex.RLock()
+	sm, exists := m.services[name]
+	m.mutex.RUnlock()
+	if !exists {
+		return config.Service{}, fmt.Errorf("service %s not found", name)
+	}
+
+	service, err := sm.config.WithTarget(target)
+	if err != nil {
+		return config.Service{}, fmt.Errorf("service %s: %w", name, err)
+	}
+	if service.Target == sm.config.Target {
+		return service, nil
+	}
+	m.logger.Info("Switching %s from %s to %s", name, sm.config.Target, service.Target)
+	if err := m.UpdateService(name, service); err != nil {
+		return config.Service{}, err
+	}
+	return service, nil
+}
+
+// UpdateService applies a changed configuration to one service, restarting only that service
+func (m *Manager) UpdateService(name string, service config.Service) error {
+	m.mutex.Lock()
+	old, exists := m.services[name]
+	if !exists {
+		m.mutex.Unlock()
+		return fmt.Errorf("service %s not found", name)
+	}
+
+	sm := m.newServiceManager(name, service)
+	previous := old.GetStatus()
+	sm.status.RestartCount = previous.RestartCount
+	sm.errorHistory.recent = previous.RecentErrors
+	sm.outputLog.lines = old.outputLog.entries()
+	paused := old.IsPaused()
+	if paused {
+		sm.paused = true
+		sm.status.Status = StatusPaused
+	}
+	m.services[name] = sm
+	m.mutex.Unlock()
+
+	old.Shutdown()
+	m.resolveContexts(map[string]*ServiceManager{name: sm})
+	if paused {
+		m.logger.Info("Configuration of %s changed; it stays paused", name)
+		return nil
+	}
+	m.logger.Info("Configuration of %s changed, restarting it", name)
+
+	sm.mutex.Lock()
+	sm.status.RestartCount++
+	sm.mutex.Unlock()
+	return m.emitRestart(name, sm, sm.Start)
+}
+
+// restartService restarts a service manager and emits a restart event; paused
+// services are left alone
+func (m *Manager) restartService(name string, sm *ServiceManager) error {
+	if sm.IsPaused() {
+		return nil
+	}
+	return m.emitRestart(name, sm, sm.Restart)
+}
+
+// emitRestart runs a (re)start of a service and emits a restart event with its outcome
+func (m *Manager) emitRestart(name string, sm *ServiceManager, start func() error) error {
+	m.stats.restarts.Add(1)
+	started := time.Now()
+	err := start()
+
+	event := Event{Type: EventServiceRestarted, Service: name, Status: sm.GetStatus(), Duration: time.Since(started)}
+	if err != nil {
+		event.Error = err.Error()
+	}
+	m.emit(event)
+
+	return err
+}
 
-	return sm.Restart()
+// checkPortReassignment emits an event when a running service listens on a port
+// other than its configured one, once per port it moves to
+func (m *Manager) checkPortReassignment(name string, status config.ServiceStatus) {
+	if status.Status != "Running" || status.LocalPort == m.lastPorts[name] {
+		return
+	}
+	m.lastPorts[name] = status.LocalPort
+	if status.ConfiguredPort != 0 && status.LocalPort != status.ConfiguredPort {
+		m.emit(Event{Type: EventPortReassigned, Service: name, Status: status})
+	}
 }
 
 // GetKubernetesContext returns the current Kubernetes context
@@ -178,6 +647,8 @@ func (m *Manager) GetKubernetesContext() string {
 // startMonitoring begins the monitoring loop for all services
 func (m *Manager) startMonitoring() {
 	m.monitoringTicker = time.NewTicker(m.config.MonitoringInterval)
+	m.resume = newResumeDetector(m.config.MonitoringInterval)
+	m.availability.setInterval(m.config.MonitoringInterval)
 
 	go func() {
 		defer m.monitoringTicker.Stop()
@@ -187,8 +658,15 @@ func (m *Manager) startMonitoring() {
 			case <-m.ctx.Done():
 				return
 			case <-m.monitoringTicker.C:
+				if reason := m.resume.check(time.Now()); reason != "" {
+					m.logger.Info("%s, restarting all services", reason)
+					m.resumeServices()
+				}
 				m.monitorServices()
 				m.checkKubernetesContext()
+				m.refreshWildcardsIfDue(time.Now())
+			case <-m.wake:
+				m.monitorServices()
 			}
 		}
 	}()
@@ -196,6 +674,8 @@ func (m *Manager) startMonitoring() {
 
 // monitorServices checks the health of all services and restarts failed ones
 func (m *Manager) monitorServices() {
+	defer func(started time.Time) { m.stats.recordMonitorPass(time.Since(started)) }(time.Now())
+
 	m.mutex.RLock()
 	services := make(map[string]*ServiceManager, len(m.services))
 	for name, sm := range m.services {
@@ -203,32 +683,78 @@ func (m *Manager) monitorServices() {
 	}
 	m.mutex.RUnlock()
 
+	// Tell an API server outage apart from services failing; the probe runs in the background
+	m.cluster.check(kubeTargets(services), m.handleClusterReachability)
+
 	statusMap := make(map[string]config.ServiceStatus)
 
+	for _, sm := range services {
+		sm.refreshHealth()
+	}
+
+	now := time.Now()
 	for name, sm := range services {
 		status := sm.GetStatus()
+		status.Availability = m.availability.observe(name, status.Status, now)
 		statusMap[name] = status
 
-		// Check if service needs to be restarted
-		if status.Status == "Failed" && !status.InCooldown {
+		// Emit state transitions observed since the previous tick
+		if previous := m.lastStates[name]; previous != status.Status {
+			m.lastStates[name] = status.Status
+			m.emit(Event{Type: EventServiceStateChanged, Service: name, Status: status,
+				PreviousStatus: previous, Error: status.LastError})
+		}
+		m.checkPortReassignment(name, status)
+
+		// Infer the type of services that don't configure one, for the UI handlers
+		sm.DetectType()
+
+		// Free idle forwards; their relay resumes them on the next connection
+		sm.SuspendIfIdle()
+
+		// Start services waiting for Teleport login once a session exists
+		if sm.LoginCheckDue() {
+			go func(serviceName string, serviceManager *ServiceManager) {
+				if err := serviceManager.Start(); err == nil {
+					m.logger.Info("Teleport session available, started %s", serviceName)
+				}
+			}(name, sm)
+		}
+
+		// Start services waiting for their workload once the rollout is done
+		if sm.WorkloadCheckDue() {
+			go func(serviceName string, serviceManager *ServiceManager) {
+				if err := serviceManager.Start(); err == nil {
+					m.logger.Info("Workload ready, started %s", serviceName)
+				}
+			}(name, sm)
+		}
+
+		// Check if service needs to be restarted; services in cooldown retry once it expires
+		if (status.Status == "Failed" || status.Status == "Cooldown") && !status.InCooldown {
 			m.logger.Info("Restarting failed service: %s", name)
 			go func(serviceName string, serviceManager *ServiceManager) {
-				if err := serviceManager.Restart(); err != nil {
+				if err := m.restartService(serviceName, serviceManager); err != nil {
 					m.logger.Error("Failed to restart service %s: %v", serviceName, err)
 				}
 			}(name, sm)
 		}
 	}
 
+	// Forget removed services
+	for name := range m.lastStates {
+		if _, exists := services[name]; !exists {
+			delete(m.lastStates, name)
+			delete(m.lastPorts, name)
+		}
+	}
+
 	// Monitor UI handlers
 	m.monitorUIHandlers(statusMap)
 
-	// Send status update (non-blocking)
-	select {
-	case m.statusChan <- statusMap:
-	default:
-		// Channel is full, skip this update
-	}
+	m.emit(Event{Type: EventStatusUpdated, Snapshot: statusMap})
+
+	m.subscribers.publish(statusMap, true)
 }
 
 // monitorUIHandlers monitors UI handlers and manages their lifecycle
@@ -236,16 +762,45 @@ func (m *Manager) monitorUIHandlers(statusMap map[string]config.ServiceStatus) {
 	m.mutex.RLock()
 	grpcHandler := m.grpcUIHandler
 	swaggerHandler := m.swaggerUIHandler
+	extraHandlers := make([]UIHandler, len(m.extraUIHandlers))
+	copy(extraHandlers, m.extraUIHandlers)
 	m.mutex.RUnlock()
+	configs := m.ServiceConfigs()
 
 	// Monitor gRPC UI handler - check both nil interface and nil concrete value
 	if grpcHandler != nil && !isNilInterface(grpcHandler) && grpcHandler.IsEnabled() {
-		grpcHandler.MonitorServices(statusMap, m.config.PortForwards)
+		grpcHandler.MonitorServices(statusMap, configs)
 	}
 
 	// Monitor Swagger UI handler - check both nil interface and nil concrete value
 	if swaggerHandler != nil && !isNilInterface(swaggerHandler) && swaggerHandler.IsEnabled() {
-		swaggerHandler.MonitorServices(statusMap, m.config.PortForwards)
+		swaggerHandler.MonitorServices(statusMap, configs)
+	}
+
+	for _, handler := range extraHandlers {
+		if !isNilInterface(handler) && handler.IsEnabled() {
+			handler.MonitorServices(statusMap, configs)
+		}
+	}
+}
+
+// enabledUIHandlers returns the UI handlers that are set and enabled; the caller holds the mutex
+func (m *Manager) enabledUIHandlers() []UIHandler {
+	var handlers []UIHandler
+	for _, handler := range append([]UIHandler{m.grpcUIHandler, m.swaggerUIHandler}, m.extraUIHandlers...) {
+		if !isNilInterface(handler) && handler.IsEnabled() {
+			handlers = append(handlers, handler)
+		}
+	}
+	return handlers
+}
+
+// stopUIHandlers stops the UIs of a service
+func (m *Manager) stopUIHandlers(handlers []UIHandler, serviceName string) {
+	for _, handler := range handlers {
+		if err := handler.StopService(serviceName); err != nil {
+			m.logger.Error("Failed to stop UI handler for %s: %v", serviceName, err)
+		}
 	}
 }
 
@@ -264,46 +819,192 @@ func isNilInterface(handler UIHandler) bool {
 	return false
 }
 
-// checkKubernetesContext monitors for Kubernetes context changes
+// checkKubernetesContext monitors for Kubernetes context changes and handles them as
+// contextPolicy says
 func (m *Manager) checkKubernetesContext() {
 	newContext, err := m.getCurrentKubernetesContext()
 	if err != nil {
 		m.logger.Error("Failed to get Kubernetes context: %v", err)
 		return
 	}
+	if newContext == m.observedContext {
+		return
+	}
+	m.observedContext = newContext
 
-	m.mutex.RLock()
+	m.mutex.Lock()
 	currentContext := m.kubernetesContext
-	m.mutex.RUnlock()
+	if newContext == currentContext {
+		// Switched back before a pending change was answered
+		m.pendingContext = ""
+		m.mutex.Unlock()
+		return
+	}
+	switch m.config.ContextPolicy {
+	case "ignore":
+		pinned := m.pinContext(currentContext)
+		m.mutex.Unlock()
+		pinServices(pinned, currentContext)
+		m.logger.Info("Kubernetes context changed from %s to %s; contextPolicy ignore keeps the services in %s",
+			currentContext, newContext, currentContext)
+	case "ask":
+		m.pendingContext = newContext
+		pinned := m.pinContext(currentContext)
+		m.mutex.Unlock()
+		pinServices(pinned, currentContext)
+		m.logger.Info("Kubernetes context changed from %s to %s; the services stay in %s until the change is confirmed",
+			currentContext, newContext, currentContext)
+		m.emit(Event{Type: EventContextChangePending, Context: newContext, PreviousContext: currentContext})
+	default:
+		m.mutex.Unlock()
+		m.switchContext(newContext)
+	}
+}
 
-	if newContext != currentContext {
-		m.logger.Info("Kubernetes context changed from %s to %s, restarting all services",
-			currentContext, newContext)
+// restartAllServices restarts all services (typically after context change) through
+// the same worker pool as startup, reporting progress
+func (m *Manager) restartAllServices() {
+	m.mutex.RLock()
+	services := make(map[string]*ServiceManager, len(m.services))
+	for name, sm := range m.services {
+		// Tunnels that don't go through kubectl, and services pinned to a context or
+		// their own kubeconfig, are unaffected
+		if sm.config.FollowsCurrentContext() {
+			services[name] = sm
+		}
+	}
+	m.mutex.RUnlock()
 
-		m.mutex.Lock()
-		m.kubernetesContext = newContext
-		m.mutex.Unlock()
+	if failed := m.runServices(services, EventRestartProgress, m.restartService); failed > 0 {
+		m.logger.Error("Failed to restart %d services during context change", failed)
+	}
+}
 
-		// Restart all services in the new context
-		go m.restartAllServices()
+// kubeTargets returns the distinct kubeconfig contexts kubectl services run in
+func kubeTargets(services map[string]*ServiceManager) []kubeTarget {
+	seen := make(map[kubeTarget]bool)
+	var targets []kubeTarget
+	for _, sm := range services {
+		target := targetOf(sm.config)
+		if sm.config.UsesKubectl() && !seen[target] {
+			seen[target] = true
+			targets = append(targets, target)
+		}
 	}
+	sort.Slice(targets, func(i, j int) bool {
+		if targets[i].Kubeconfig != targets[j].Kubeconfig {
+			return targets[i].Kubeconfig < targets[j].Kubeconfig
+		}
+		return targets[i].Context < targets[j].Context
+	})
+	return targets
 }
 
-// restartAllServices restarts all services (typically after context change)
-func (m *Manager) restartAllServices() {
+// handleClusterReachability pauses the services of a context while its API server is
+// unreachable, and restarts them without backoff once it answers again
+func (m *Manager) handleClusterReachability(target kubeTarget, reachable bool, err error) {
 	m.mutex.RLock()
-	services := make([]*ServiceManager, 0, len(m.services))
+	name := target.Context
+	if name == "" && target.Kubeconfig == "" {
+		name = m.kubernetesContext
+	}
+	if target.Kubeconfig != "" {
+		if name == "" {
+			name = "current"
+		}
+		name += " of " + target.Kubeconfig
+	}
+	var services []*ServiceManager
 	for _, sm := range m.services {
-		services = append(services, sm)
+		if sm.config.UsesKubectl() && targetOf(sm.config) == target {
+			services = append(services, sm)
+		}
 	}
 	m.mutex.RUnlock()
 
+	if !reachable {
+		m.logger.Warn("Kubernetes API server for context %s is unreachable, pausing restarts: %v", name, err)
+		for _, sm := range services {
+			sm.SetClusterError(err)
+		}
+		return
+	}
+
+	m.logger.Info("Kubernetes API server for context %s is reachable again, resuming services", name)
 	for _, sm := range services {
-		if err := sm.Restart(); err != nil {
-			m.logger.Error("Failed to restart service during context change: %v", err)
+		sm.SetClusterError(nil)
+		sm.resetBackoff()
+		if status := sm.GetStatus().Status; status == "Failed" || status == "Cooldown" {
+			go func(sm *ServiceManager) {
+				if err := m.restartService(sm.name, sm); err != nil {
+					m.logger.Error("Failed to restart service %s: %v", sm.name, err)
+				}
+			}(sm)
+		}
+	}
+}
+
+// resumeServices restarts every service that isn't deliberately stopped, skipping
+// the backoff, after a wake or network change left the forwards dead or stale
+func (m *Manager) resumeServices() {
+	m.mutex.RLock()
+	services := make(map[string]*ServiceManager, len(m.services))
+	for name, sm := range m.services {
+		services[name] = sm
+	}
+	m.mutex.RUnlock()
+
+	for name, sm := range services {
+		if status := sm.GetStatus().Status; status == "Stopped" || status == StatusPaused {
+			delete(services, name)
+			continue
+		}
+		sm.resetBackoff()
+	}
+
+	if failed := m.runServices(services, EventRestartProgress, m.restartService); failed > 0 {
+		m.logger.Error("Failed to restart %d services after resume", failed)
+	}
+}
+
+// sortByPriority orders service names by priority class, then by name
+func sortByPriority(names []string, services map[string]*ServiceManager) {
+	sort.Slice(names, func(i, j int) bool {
+		a, b := services[names[i]].config.PriorityRank(), services[names[j]].config.PriorityRank()
+		if a != b {
+			return a < b
+		}
+		return names[i] < names[j]
+	})
+}
+
+// resolveContexts looks up each kubectl service's context and cluster in its kubeconfig
+func (m *Manager) resolveContexts(services map[string]*ServiceManager) {
+	sets := make(map[string]*kubeconfig.Set)
+	for name, sm := range services {
+		if !sm.config.UsesKubectl() {
+			continue
+		}
+		path := sm.config.KubeconfigPath()
+		set, loaded := sets[path]
+		if !loaded {
+			var err error
+			if set, err = kubeconfig.Load(path); err != nil {
+				m.logger.Warn("Failed to resolve kubeconfig contexts: %v", err)
+			}
+			sets[path] = set
+		}
+		if set == nil {
+			continue
+		}
+		sm.mutex.RLock()
+		context := sm.kubeService().Context
+		sm.mutex.RUnlock()
+		info, err := set.Resolve(context)
+		if err != nil {
+			m.logger.Warn("Service %s: %v", name, err)
 		}
-		// Small delay between restarts to avoid overwhelming the system
-		time.Sleep(100 * time.Millisecond)
+		sm.SetKubeContext(info.Name, info.Cluster)
 	}
 }
 
@@ -314,6 +1015,7 @@ func (m *Manager) updateKubernetesContext() error {
 		return err
 	}
 	m.kubernetesContext = context
+	m.observedContext = context
 	return nil
 }
 
@@ -1,10 +1,13 @@
 package portforward
 
 import (
+	"fmt"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/relay"
 	"github.com/victorkazakov/kportforward/internal/utils"
 )
 
@@ -77,10 +80,6 @@ func TestNewManager(t *testing.T) {
 	if manager.services == nil {
 		t.Error("Manager services map should be initialized")
 	}
-
-	if manager.statusChan == nil {
-		t.Error("Manager status channel should be initialized")
-	}
 }
 
 func TestManagerUIHandlers(t *testing.T) {
@@ -129,7 +128,7 @@ func TestManagerKubernetesContext(t *testing.T) {
 	}
 }
 
-func TestManagerStatusChannel(t *testing.T) {
+func TestManagerSubscribe(t *testing.T) {
 	cfg := &config.Config{
 		PortForwards:       map[string]config.Service{},
 		MonitoringInterval: 1 * time.Second,
@@ -138,17 +137,74 @@ func TestManagerStatusChannel(t *testing.T) {
 	logger := utils.NewLogger(utils.LevelInfo)
 	manager := NewManager(cfg, logger)
 
-	statusChan := manager.GetStatusChannel()
-	if statusChan == nil {
-		t.Error("Status channel should not be nil")
+	fast, cancelFast := manager.Subscribe()
+	slow, _ := manager.Subscribe()
+	defer cancelFast()
+
+	// Each subscriber receives every change as a delta; unchanged statuses aren't sent
+	for i := 0; i < subscriberBuffer+2; i++ {
+		manager.subscribers.publish(Snapshot{"api": {RestartCount: i}, "web": {Status: "Running"}}, true)
+		got := <-fast
+		if got.Changed["api"].RestartCount != i || got.Full {
+			t.Fatalf("Fast subscriber got %+v, expected api restart count %d", got, i)
+		}
+		if _, sent := got.Changed["web"]; sent != (i == 0) {
+			t.Fatalf("Expected web only in the first update, got %+v", got)
+		}
+	}
+	manager.subscribers.publish(Snapshot{"api": {RestartCount: subscriberBuffer + 1}, "web": {Status: "Running"}}, true)
+	if len(fast) != 0 {
+		t.Error("Expected no update when nothing changed")
+	}
+
+	// One that lags has its backlog replaced with the whole state, then gets deltas again
+	full := <-slow
+	if !full.Full {
+		t.Fatalf("Expected the lagging subscriber's backlog to be replaced with a full update, got %+v", full)
+	}
+	statuses := full.Apply(nil)
+	for len(slow) > 0 {
+		statuses = (<-slow).Apply(statuses)
+	}
+	if statuses["api"].RestartCount != subscriberBuffer+1 || statuses["web"].Status != "Running" {
+		t.Errorf("Expected the latest state after applying the updates, got %+v", statuses)
+	}
+
+	// Services missing from a complete snapshot are removed, from a partial one kept
+	manager.subscribers.publish(Snapshot{"web": {Status: "Failed"}}, false)
+	statuses = (<-slow).Apply(statuses)
+	if len(statuses) != 2 || statuses["web"].Status != "Failed" {
+		t.Errorf("Expected a partial snapshot to keep other services, got %+v", statuses)
+	}
+	manager.subscribers.publish(Snapshot{"web": {Status: "Failed"}}, true)
+	statuses = (<-slow).Apply(statuses)
+	if _, exists := statuses["api"]; exists || len(statuses) != 1 {
+		t.Errorf("Expected api to be removed, got %+v", statuses)
+	}
+	for len(fast) > 0 {
+		<-fast
+	}
+
+	// A new subscriber starts with the current state in full
+	joined, cancelJoined := manager.Subscribe()
+	if first := <-joined; !first.Full || len(first.Changed) != 1 {
+		t.Errorf("Expected a new subscriber to get the current state, got %+v", first)
 	}
+	cancelJoined()
 
-	// Test that we can receive from the channel
-	select {
-	case <-statusChan:
-		// This is fine, we might get an immediate status update
-	case <-time.After(100 * time.Millisecond):
-		// This is also fine, no immediate status update
+	// Cancelling closes only that subscription
+	cancelFast()
+	cancelFast()
+	if _, ok := <-fast; ok {
+		t.Error("Expected a cancelled subscription to be closed")
+	}
+
+	manager.Stop()
+	for range slow {
+	}
+	late, _ := manager.Subscribe()
+	if _, ok := <-late; ok {
+		t.Error("Expected subscriptions after Stop to be closed")
 	}
 }
 
@@ -237,3 +293,586 @@ func TestManagerValidation(t *testing.T) {
 		t.Error("NewManager should not return nil even with nil logger")
 	}
 }
+
+func TestManagerEventListeners(t *testing.T) {
+	cfg := &config.Config{
+		PortForwards:       map[string]config.Service{},
+		MonitoringInterval: 1 * time.Second,
+	}
+
+	manager := NewManager(cfg, utils.NewLogger(utils.LevelInfo))
+
+	var received []Event
+	manager.AddEventListener(func(event Event) {
+		received = append(received, event)
+	})
+
+	manager.emit(Event{Type: EventContextChanged, Context: "new", PreviousContext: "old"})
+
+	if len(received) != 1 {
+		t.Fatalf("Expected 1 event, got %d", len(received))
+	}
+	if received[0].Type != EventContextChanged || received[0].Context != "new" {
+		t.Errorf("Unexpected event received: %+v", received[0])
+	}
+	if received[0].Timestamp.IsZero() {
+		t.Error("Event timestamp should be set when emitted")
+	}
+}
+
+func TestManagerStartServicesReportsProgress(t *testing.T) {
+	cfg := &config.Config{
+		PortForwards:       map[string]config.Service{},
+		MonitoringInterval: 1 * time.Second,
+		Startup:            config.StartupConfig{Parallelism: 3, Stagger: time.Millisecond},
+	}
+	manager := NewManager(cfg, utils.NewLogger(utils.LevelError))
+
+	// ssh services without a bastion fail to start without running anything
+	services := make(map[string]*ServiceManager)
+	for i := 0; i < 10; i++ {
+		name := fmt.Sprintf("svc-%d", i)
+		services[name] = NewServiceManager(name, config.Service{Type: "ssh", LocalPort: 20000 + i}, manager.logger)
+	}
+
+	var mutex sync.Mutex
+	var progress []Event
+	manager.AddEventListener(func(event Event) {
+		if event.Type == EventStartupProgress {
+			mutex.Lock()
+			progress = append(progress, event)
+			mutex.Unlock()
+		}
+	})
+
+	if failed := manager.startServices(services); failed != len(services) {
+		t.Errorf("Expected %d failed services, got %d", len(services), failed)
+	}
+	if len(progress) != len(services) {
+		t.Fatalf("Expected %d progress events, got %d", len(services), len(progress))
+	}
+	last := progress[len(progress)-1]
+	if last.Started != len(services) || last.Total != len(services) {
+		t.Errorf("Expected final progress %d/%d, got %d/%d", len(services), len(services), last.Started, last.Total)
+	}
+}
+
+func TestPriorityOrderingAndBackoff(t *testing.T) {
+	logger := utils.NewLogger(utils.LevelError)
+	services := map[string]*ServiceManager{
+		"dashboard": NewServiceManager("dashboard", config.Service{Priority: "low"}, logger),
+		"auth":      NewServiceManager("auth", config.Service{Priority: "critical"}, logger),
+		"api":       NewServiceManager("api", config.Service{}, logger),
+		"gateway":   NewServiceManager("gateway", config.Service{Priority: "critical"}, logger),
+		"search":    NewServiceManager("search", config.Service{Priority: "high"}, logger),
+	}
+
+	names := []string{"dashboard", "auth", "api", "gateway", "search"}
+	sortByPriority(names, services)
+	expected := []string{"auth", "gateway", "search", "api", "dashboard"}
+	for i := range expected {
+		if names[i] != expected[i] {
+			t.Fatalf("Expected start order %v, got %v", expected, names)
+		}
+	}
+
+	// After many failures, critical services cool down for 10s at most
+	for _, name := range []string{"auth", "api"} {
+		for i := 0; i < 10; i++ {
+			services[name].handleFailure()
+		}
+	}
+	if remaining := time.Until(services["auth"].cooldownUntil); remaining > 10*time.Second {
+		t.Errorf("Expected critical cooldown of at most 10s, got %v", remaining)
+	}
+	if remaining := time.Until(services["api"].cooldownUntil); remaining < 50*time.Second {
+		t.Errorf("Expected normal cooldown to reach 60s, got %v", remaining)
+	}
+}
+
+func TestCooldownExposedInStatus(t *testing.T) {
+	sm := NewServiceManager("api", config.Service{}, utils.NewLogger(utils.LevelError))
+	sm.status.Status = "Failed"
+
+	for i := 0; i < 3; i++ {
+		sm.handleFailure()
+	}
+	status := sm.GetStatus()
+	if !status.InCooldown || time.Until(status.CooldownUntil) <= 0 {
+		t.Fatalf("Expected an active cooldown in the status, got %v until %v", status.InCooldown, status.CooldownUntil)
+	}
+
+	// Once it expires the service is reported as due for a retry
+	sm.cooldownUntil = time.Now().Add(-time.Second)
+	sm.status.CooldownUntil = sm.cooldownUntil
+	if sm.GetStatus().InCooldown {
+		t.Error("Expected an expired cooldown not to be reported as active")
+	}
+}
+
+func TestPinnedServiceSkipsBackoff(t *testing.T) {
+	sm := NewServiceManager("api", config.Service{Pinned: true}, utils.NewLogger(utils.LevelError))
+
+	for i := 0; i < 10; i++ {
+		sm.handleFailure()
+	}
+	if remaining := time.Until(sm.cooldownUntil); remaining > pinnedRetryDelay {
+		t.Errorf("Expected a pinned service to retry within %v, got %v", pinnedRetryDelay, remaining)
+	}
+}
+
+func TestManagerUpdateService(t *testing.T) {
+	cfg := &config.Config{PortForwards: map[string]config.Service{}, MonitoringInterval: time.Second}
+	manager := NewManager(cfg, utils.NewLogger(utils.LevelError))
+	manager.services["bastion"] = NewServiceManager("bastion", config.Service{Type: "ssh", LocalPort: 15432}, manager.logger)
+
+	if err := manager.UpdateService("missing", config.Service{}); err == nil {
+		t.Error("Expected updating an unknown service to fail")
+	}
+
+	// Without a bastion the restart fails, but the new settings are in place
+	manager.UpdateService("bastion", config.Service{Type: "ssh", LocalPort: 15433, Priority: "critical"})
+	sm := manager.services["bastion"]
+	if sm.config.LocalPort != 15433 || sm.config.Priority != "critical" {
+		t.Errorf("Expected the updated configuration, got %+v", sm.config)
+	}
+	if status := sm.GetStatus(); status.ConfiguredPort != 15433 || status.RestartCount != 1 {
+		t.Errorf("Expected configured port 15433 and one restart, got %d and %d", status.ConfiguredPort, status.RestartCount)
+	}
+}
+
+func TestManagerReportsPortReassignment(t *testing.T) {
+	manager := NewManager(&config.Config{PortForwards: map[string]config.Service{}, MonitoringInterval: time.Second},
+		utils.NewLogger(utils.LevelError))
+
+	var received []Event
+	manager.AddEventListener(func(event Event) {
+		received = append(received, event)
+	})
+
+	status := config.ServiceStatus{Status: "Starting", ConfiguredPort: 8080, LocalPort: 8081}
+	manager.checkPortReassignment("api", status)
+
+	status.Status = "Running"
+	manager.checkPortReassignment("api", status)
+	manager.checkPortReassignment("api", status) // Reported once per port
+
+	status.LocalPort = 8080
+	manager.checkPortReassignment("api", status) // Back on the configured port
+
+	if len(received) != 1 {
+		t.Fatalf("Expected 1 reassignment event, got %d: %+v", len(received), received)
+	}
+	if received[0].Type != EventPortReassigned || received[0].Status.LocalPort != 8081 {
+		t.Errorf("Unexpected event: %+v", received[0])
+	}
+}
+
+func TestManagerRuntimeServiceManagement(t *testing.T) {
+	cfg := &config.Config{PortForwards: map[string]config.Service{}, MonitoringInterval: time.Second}
+	manager := NewManager(cfg, utils.NewLogger(utils.LevelError))
+	handler := NewMockUIHandler()
+	handler.Enable()
+	manager.SetUIHandlers(handler, nil)
+
+	var events []EventType
+	manager.AddEventListener(func(event Event) {
+		events = append(events, event.Type)
+	})
+
+	// Without a bastion the start fails, but the service is added
+	manager.AddService("bastion", config.Service{Type: "ssh", LocalPort: 15434})
+	if _, exists := manager.GetCurrentStatus()["bastion"]; !exists {
+		t.Fatal("Expected the added service in the status")
+	}
+	if err := manager.AddService("bastion", config.Service{Type: "ssh", LocalPort: 15435}); err == nil {
+		t.Error("Expected adding a duplicate service to fail")
+	}
+
+	if err := manager.StopService("bastion"); err != nil {
+		t.Fatalf("StopService failed: %v", err)
+	}
+	if status := manager.GetCurrentStatus()["bastion"].Status; status != "Stopped" {
+		t.Errorf("Expected Stopped, got %s", status)
+	}
+
+	manager.StartService("bastion")
+	if len(events) != 2 || events[0] != EventServiceStartFailed || events[1] != EventServiceStartFailed {
+		t.Errorf("Expected a start event for the add and the start, got %v", events)
+	}
+
+	if err := manager.RemoveService("bastion"); err != nil {
+		t.Fatalf("RemoveService failed: %v", err)
+	}
+	if len(manager.GetCurrentStatus()) != 0 {
+		t.Error("Expected the service to be removed")
+	}
+	if len(handler.stopCalls) != 2 {
+		t.Errorf("Expected the UI handler to be stopped on stop and remove, got %v", handler.stopCalls)
+	}
+
+	for _, call := range []func(string) error{manager.StartService, manager.StopService, manager.RemoveService} {
+		if err := call("bastion"); err == nil {
+			t.Error("Expected calls for an unknown service to fail")
+		}
+	}
+}
+
+func TestManagerStartServiceStopsFailedForward(t *testing.T) {
+	cfg := &config.Config{PortForwards: map[string]config.Service{}, MonitoringInterval: time.Second}
+	manager := NewManager(cfg, utils.NewLogger(utils.LevelError))
+
+	// A health check failed, but the relay of the forward still listens
+	sm := NewServiceManager("bastion", config.Service{Type: "ssh", LocalPort: 15437}, manager.logger)
+	udpRelay := relay.NewUDP("bastion", "127.0.0.1:0", "127.0.0.1:1", manager.logger)
+	if err := udpRelay.Start(); err != nil {
+		t.Fatalf("Failed to start relay: %v", err)
+	}
+	sm.udpRelay = udpRelay
+	sm.status.Status = "Failed"
+	manager.services["bastion"] = sm
+
+	manager.StartService("bastion") // Fails without a bastion
+	sm.mutex.RLock()
+	defer sm.mutex.RUnlock()
+	if sm.udpRelay != nil {
+		t.Error("Expected the failed forward to be stopped before starting again")
+	}
+}
+
+func TestManagerPauseService(t *testing.T) {
+	cfg := &config.Config{PortForwards: map[string]config.Service{}, MonitoringInterval: time.Second}
+	manager := NewManager(cfg, utils.NewLogger(utils.LevelError))
+
+	var events []EventType
+	manager.AddEventListener(func(event Event) {
+		events = append(events, event.Type)
+	})
+
+	// Without a bastion the start fails, but the service is added
+	manager.AddService("bastion", config.Service{Type: "ssh", LocalPort: 15436})
+	if err := manager.ResumeService("bastion"); err == nil {
+		t.Error("Expected resuming a service that isn't paused to fail")
+	}
+
+	if err := manager.PauseService("bastion"); err != nil {
+		t.Fatalf("PauseService failed: %v", err)
+	}
+	if status := manager.GetCurrentStatus()["bastion"]; status.Status != StatusPaused || status.InCooldown {
+		t.Errorf("Expected Paused without a cooldown, got %s (cooldown %v)", status.Status, status.InCooldown)
+	}
+
+	// Restarts after failures, context changes and wake-ups leave it alone
+	events = nil
+	manager.restartAllServices()
+	manager.resumeServices()
+	if err := manager.RestartService("bastion"); err == nil {
+		t.Error("Expected restarting a paused service to fail")
+	}
+	if status := manager.GetCurrentStatus()["bastion"].Status; status != StatusPaused || len(events) != 0 {
+		t.Errorf("Expected the service to stay paused without restarts, got %s and %v", status, events)
+	}
+
+	// A changed configuration doesn't resume it either
+	if err := manager.UpdateService("bastion", config.Service{Type: "ssh", LocalPort: 15437}); err != nil {
+		t.Fatalf("UpdateService failed: %v", err)
+	}
+	if status := manager.GetCurrentStatus()["bastion"].Status; status != StatusPaused {
+		t.Errorf("Expected the service to stay paused after a config change, got %s", status)
+	}
+
+	manager.ResumeService("bastion")
+	if status := manager.GetCurrentStatus()["bastion"].Status; status == StatusPaused {
+		t.Error("Expected the service to be started once resumed")
+	}
+	if len(events) != 1 || events[0] != EventServiceStartFailed {
+		t.Errorf("Expected a start event for the resume, got %v", events)
+	}
+
+	for _, call := range []func(string) error{manager.PauseService, manager.ResumeService} {
+		if err := call("unknown"); err == nil {
+			t.Error("Expected calls for an unknown service to fail")
+		}
+	}
+}
+
+func TestRestartAllServicesReportsProgress(t *testing.T) {
+	cfg := &config.Config{
+		PortForwards:       map[string]config.Service{},
+		MonitoringInterval: time.Second,
+		Startup:            config.StartupConfig{Parallelism: 4, Stagger: time.Millisecond},
+	}
+	manager := NewManager(cfg, utils.NewLogger(utils.LevelError))
+	for i := 0; i < 6; i++ {
+		name := fmt.Sprintf("svc-%d", i)
+		manager.services[name] = NewServiceManager(name, config.Service{Type: "web", LocalPort: 21000 + i}, manager.logger)
+	}
+	// Unaffected by a context change
+	manager.services["bastion"] = NewServiceManager("bastion", config.Service{Type: "ssh", LocalPort: 21010}, manager.logger)
+	manager.services["pinned"] = NewServiceManager("pinned", config.Service{Type: "web", LocalPort: 21011, Context: "prod"}, manager.logger)
+	manager.services["other-cluster"] = NewServiceManager("other-cluster", config.Service{Type: "web", LocalPort: 21012, Kubeconfig: "/etc/kube/eu.yaml"}, manager.logger)
+
+	var mutex sync.Mutex
+	var progress []Event
+	manager.AddEventListener(func(event Event) {
+		if event.Type == EventRestartProgress {
+			mutex.Lock()
+			progress = append(progress, event)
+			mutex.Unlock()
+		}
+	})
+
+	manager.restartAllServices()
+
+	if len(progress) != 6 {
+		t.Fatalf("Expected 6 restart progress events, got %d", len(progress))
+	}
+	if last := progress[len(progress)-1]; last.Started != 6 || last.Total != 6 {
+		t.Errorf("Expected final progress 6/6, got %d/%d", last.Started, last.Total)
+	}
+	for _, name := range []string{"bastion", "pinned", "other-cluster"} {
+		if restarts := manager.services[name].GetStatus().RestartCount; restarts != 0 {
+			t.Errorf("Expected %s not to be restarted, got %d restarts", name, restarts)
+		}
+	}
+}
+
+func TestResumeServicesReportsProgress(t *testing.T) {
+	cfg := &config.Config{
+		PortForwards:       map[string]config.Service{},
+		MonitoringInterval: time.Second,
+		Startup:            config.StartupConfig{Parallelism: 2, Stagger: time.Millisecond},
+	}
+	manager := NewManager(cfg, utils.NewLogger(utils.LevelError))
+	for i := 0; i < 4; i++ {
+		name := fmt.Sprintf("svc-%d", i)
+		manager.services[name] = NewServiceManager(name, config.Service{Type: "ssh", LocalPort: 21020 + i}, manager.logger)
+	}
+	// Deliberately stopped, so a wake-up leaves it alone
+	stopped := NewServiceManager("stopped", config.Service{Type: "ssh", LocalPort: 21030}, manager.logger)
+	stopped.Stop()
+	manager.services["stopped"] = stopped
+
+	var mutex sync.Mutex
+	var progress []Event
+	manager.AddEventListener(func(event Event) {
+		if event.Type == EventRestartProgress {
+			mutex.Lock()
+			progress = append(progress, event)
+			mutex.Unlock()
+		}
+	})
+
+	manager.resumeServices()
+
+	if len(progress) != 4 {
+		t.Fatalf("Expected 4 restart progress events, got %d", len(progress))
+	}
+	if last := progress[len(progress)-1]; last.Started != 4 || last.Total != 4 {
+		t.Errorf("Expected final progress 4/4, got %d/%d", last.Started, last.Total)
+	}
+	if restarts := stopped.GetStatus().RestartCount; restarts != 0 {
+		t.Errorf("Expected the stopped service not to be restarted, got %d restarts", restarts)
+	}
+}
+
+func TestManagerSwapTarget(t *testing.T) {
+	cfg := &config.Config{PortForwards: map[string]config.Service{}, MonitoringInterval: time.Second}
+	manager := NewManager(cfg, utils.NewLogger(utils.LevelError))
+	manager.AddService("db", config.Service{Type: "ssh", Target: "db-blue.internal", AlternateTargets: []string{"db-green.internal"}, LocalPort: 15436})
+	defer manager.RemoveService("db")
+
+	// Without a bastion the restart fails, but the target is swapped
+	manager.SwapTarget("db", "")
+	if target := manager.GetCurrentStatus()["db"].Target; target != "db-green.internal" {
+		t.Errorf("Expected the status to show the new target, got %s", target)
+	}
+
+	if _, err := manager.SwapTarget("db", "db-red.internal"); err == nil {
+		t.Error("Expected an unknown target to fail")
+	}
+	if _, err := manager.SwapTarget("missing", ""); err == nil {
+		t.Error("Expected an unknown service to fail")
+	}
+}
+
+func TestManagerApplyServices(t *testing.T) {
+	cfg := &config.Config{PortForwards: map[string]config.Service{}, MonitoringInterval: time.Second}
+	manager := NewManager(cfg, utils.NewLogger(utils.LevelError))
+	for name, port := range map[string]int{"kept": 15437, "changed": 15438, "removed": 15439} {
+		manager.AddService(name, config.Service{Type: "ssh", LocalPort: port})
+	}
+	defer manager.Stop()
+	kept := manager.services["kept"]
+
+	diff := manager.ApplyServices(map[string]config.Service{
+		"kept":    {Type: "ssh", LocalPort: 15437},
+		"changed": {Type: "ssh", LocalPort: 15440},
+		"added":   {Type: "ssh", LocalPort: 15441},
+	})
+	if diff.String() != "added added; removed removed; restarted changed" {
+		t.Errorf("Unexpected diff: %s", diff)
+	}
+
+	status := manager.GetCurrentStatus()
+	if _, exists := status["removed"]; exists || len(status) != 3 {
+		t.Errorf("Expected kept, changed and added services, got %v", status)
+	}
+	if status["changed"].ConfiguredPort != 15440 {
+		t.Errorf("Expected the changed port, got %d", status["changed"].ConfiguredPort)
+	}
+	if manager.services["kept"] != kept {
+		t.Error("Expected the unchanged service to keep running untouched")
+	}
+}
+
+func TestSameStatusIgnoresUnshownChanges(t *testing.T) {
+	base := config.ServiceStatus{Status: "Running", LatencyP50: 12 * time.Millisecond,
+		RecentErrors: []config.ErrorEntry{{Message: "lost connection"}}}
+
+	probed := base
+	probed.LatencyP50 = 12*time.Millisecond + 300*time.Microsecond
+	if !sameStatus(base, probed) {
+		t.Error("Expected a latency change below the shown precision to be ignored")
+	}
+
+	slower := base
+	slower.LatencyP50 = 15 * time.Millisecond
+	if sameStatus(base, slower) {
+		t.Error("Expected a visible latency change to count")
+	}
+
+	failed := base
+	failed.RecentErrors = append([]config.ErrorEntry{}, base.RecentErrors...)
+	failed.RecentErrors = append(failed.RecentErrors, config.ErrorEntry{Message: "connection refused"})
+	if sameStatus(base, failed) {
+		t.Error("Expected a new error to count")
+	}
+
+	restarted := base
+	restarted.RestartCount++
+	if sameStatus(base, restarted) {
+		t.Error("Expected a restart to count")
+	}
+
+	slot := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	base.Availability = config.Availability{Up: time.Hour, Timeline: []config.HealthSlot{{Start: slot, Up: 30 * time.Second}}}
+	longer := base
+	longer.Availability = config.Availability{Up: time.Hour + 5*time.Second, Timeline: []config.HealthSlot{{Start: slot, Up: 35 * time.Second}}}
+	if !sameStatus(base, longer) {
+		t.Error("Expected more time up in the same slot to be ignored")
+	}
+	degraded := base
+	degraded.Availability = config.Availability{Up: time.Hour, Down: 5 * time.Second, Timeline: []config.HealthSlot{{Start: slot, Up: 30 * time.Second, Down: 5 * time.Second}}}
+	if sameStatus(base, degraded) {
+		t.Error("Expected downtime to count")
+	}
+}
+
+func TestManagerDebugVars(t *testing.T) {
+	manager := NewManager(&config.Config{MonitoringInterval: time.Second}, utils.NewLogger(utils.LevelError))
+	_, cancel := manager.Subscribe()
+	defer cancel()
+
+	for i := 0; i < subscriberBuffer+2; i++ {
+		manager.subscribers.publish(Snapshot{"api": {RestartCount: i}}, true)
+	}
+	manager.stats.recordMonitorPass(30 * time.Millisecond)
+	manager.stats.recordMonitorPass(10 * time.Millisecond)
+
+	vars := manager.DebugVars()
+	if vars["subscribers"] != 1 || vars["subscriberDrops"].(int64) == 0 {
+		t.Errorf("Expected one subscriber with dropped updates, got %v", vars)
+	}
+	if vars["monitorPasses"] != int64(2) || vars["monitorLastMs"] != 10.0 || vars["monitorMaxMs"] != 30.0 {
+		t.Errorf("Expected the monitoring passes to be recorded, got %v", vars)
+	}
+}
+
+func TestContextPolicyPinsAndAnswers(t *testing.T) {
+	cfg := &config.Config{PortForwards: map[string]config.Service{}, MonitoringInterval: time.Second, ContextPolicy: "ask"}
+	manager := NewManager(cfg, utils.NewLogger(utils.LevelError))
+	manager.kubernetesContext = "dev"
+	manager.services["api"] = NewServiceManager("api", config.Service{Type: "web", LocalPort: 21020}, manager.logger)
+	manager.services["pinned"] = NewServiceManager("pinned", config.Service{Type: "web", LocalPort: 21021, Context: "prod"}, manager.logger)
+
+	if err := manager.KeepContext(); err == nil {
+		t.Error("Expected KeepContext to fail without a pending change")
+	}
+	if err := manager.SwitchContext(); err == nil {
+		t.Error("Expected SwitchContext to fail without a pending change")
+	}
+
+	// What checkKubernetesContext does when kubectl switches to staging under ask
+	manager.mutex.Lock()
+	manager.pendingContext = "staging"
+	pinned := manager.pinContext("dev")
+	manager.mutex.Unlock()
+	pinServices(pinned, "dev")
+
+	if pending := manager.PendingContext(); pending != "staging" {
+		t.Errorf("Expected staging to be pending, got %q", pending)
+	}
+	contexts := map[string]string{"api": "dev", "pinned": "prod"}
+	for name, want := range contexts {
+		if got := manager.services[name].kubeService().Context; got != want {
+			t.Errorf("Expected %s to forward in %q, got %q", name, want, got)
+		}
+	}
+	if got := manager.newServiceManager("added", config.Service{Type: "web"}).kubeService().Context; got != "dev" {
+		t.Errorf("Expected a service added while pinned to forward in dev, got %q", got)
+	}
+
+	if err := manager.KeepContext(); err != nil {
+		t.Fatalf("KeepContext failed: %v", err)
+	}
+	if manager.PendingContext() != "" || manager.GetKubernetesContext() != "dev" {
+		t.Errorf("Expected the services to stay in dev, got context %q pending %q", manager.GetKubernetesContext(), manager.PendingContext())
+	}
+	if got := manager.services["api"].kubeService().Context; got != "dev" {
+		t.Errorf("Expected api to stay pinned to dev, got %q", got)
+	}
+}
+
+func TestSwitchContextUnpins(t *testing.T) {
+	cfg := &config.Config{PortForwards: map[string]config.Service{}, MonitoringInterval: time.Second, ContextPolicy: "ask"}
+	manager := NewManager(cfg, utils.NewLogger(utils.LevelError))
+	manager.kubernetesContext = "dev"
+	manager.pinnedContext = "dev"
+	manager.pendingContext = "staging"
+
+	var changes []Event
+	manager.AddEventListener(func(event Event) {
+		if event.Type == EventContextChanged {
+			changes = append(changes, event)
+		}
+	})
+
+	if err := manager.SwitchContext(); err != nil {
+		t.Fatalf("SwitchContext failed: %v", err)
+	}
+	if manager.GetKubernetesContext() != "staging" || manager.PendingContext() != "" || manager.pinnedContext != "" {
+		t.Errorf("Expected to switch to staging unpinned, got context %q pending %q pinned %q",
+			manager.GetKubernetesContext(), manager.PendingContext(), manager.pinnedContext)
+	}
+	if len(changes) != 1 || changes[0].Context != "staging" || changes[0].PreviousContext != "dev" {
+		t.Errorf("Expected one context change from dev to staging, got %+v", changes)
+	}
+}
+
+func TestManagerServiceFilter(t *testing.T) {
+	manager := NewManager(&config.Config{MonitoringInterval: time.Second}, utils.NewLogger(utils.LevelError))
+	manager.SetServiceFilter(config.ServiceFilter{Only: []string{"api-*"}, Exclude: []string{"api-admin"}})
+
+	services, _ := manager.expandServices(map[string]config.Service{
+		"api-gateway": {Type: "web", LocalPort: 21030},
+		"api-admin":   {Type: "web", LocalPort: 21031},
+		"console":     {Type: "web", LocalPort: 21032},
+	}, manager.filter)
+	if len(services) != 1 {
+		t.Fatalf("Expected only api-gateway to be selected, got %v", services)
+	}
+	if _, ok := services["api-gateway"]; !ok {
+		t.Errorf("Expected api-gateway to be selected, got %v", services)
+	}
+}
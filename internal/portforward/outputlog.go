@@ -0,0 +1,98 @@
+package portforward
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+)
+
+const (
+	// outputLogSize is how many lines of process output are kept per service
+	outputLogSize = 500
+
+	// maxOutputLineLength splits a line that never ends, e.g. a progress bar
+	maxOutputLineLength = 4096
+)
+
+// outputLog keeps the latest lines the forward processes of a service wrote, across
+// restarts, for viewing what kubectl said when a forward failed
+type outputLog struct {
+	lines   []config.OutputLine
+	partial []byte // Output after the last newline
+	mutex   sync.Mutex
+}
+
+// Write splits output into lines, keeping an unfinished line until it ends
+func (l *outputLog) Write(p []byte) (int, error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	now := time.Now()
+	l.partial = append(l.partial, p...)
+	for {
+		end := bytes.IndexByte(l.partial, '\n')
+		if end < 0 {
+			if len(l.partial) < maxOutputLineLength {
+				break
+			}
+			end = maxOutputLineLength
+		}
+		l.add(now, string(l.partial[:end]))
+		if end < len(l.partial) && l.partial[end] == '\n' {
+			end++
+		}
+		l.partial = l.partial[end:]
+	}
+	return len(p), nil
+}
+
+// mark ends any unfinished line and adds a line of its own, e.g. when a process starts
+func (l *outputLog) mark(text string) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	now := time.Now()
+	if len(l.partial) > 0 {
+		l.add(now, string(l.partial))
+		l.partial = nil
+	}
+	l.add(now, text)
+}
+
+// add appends a line, dropping the oldest once full; the caller holds the mutex
+func (l *outputLog) add(now time.Time, text string) {
+	text = strings.TrimRight(text, "\r")
+	if len(l.lines) == outputLogSize {
+		copy(l.lines, l.lines[1:])
+		l.lines = l.lines[:outputLogSize-1]
+	}
+	l.lines = append(l.lines, config.OutputLine{Time: now, Text: text})
+}
+
+// entries returns a copy of the lines, oldest first
+func (l *outputLog) entries() []config.OutputLine {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if len(l.lines) == 0 {
+		return nil
+	}
+	lines := make([]config.OutputLine, len(l.lines))
+	copy(lines, l.lines)
+	return lines
+}
+
+// ServiceOutput returns the latest lines the forward processes of a service wrote, oldest first
+func (m *Manager) ServiceOutput(name string) ([]config.OutputLine, error) {
+	m.mutex.RLock()
+	sm, exists := m.services[name]
+	m.mutex.RUnlock()
+	if !exists {
+		return nil, fmt.Errorf("service %s not found", name)
+	}
+	return sm.outputLog.entries(), nil
+}
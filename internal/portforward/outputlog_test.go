@@ -0,0 +1,51 @@
+package portforward
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestOutputLogSplitsLines(t *testing.T) {
+	var log outputLog
+	if log.entries() != nil {
+		t.Error("Expected no lines initially")
+	}
+
+	fmt.Fprint(&log, "Forwarding from 127.0.0.1:8080 -> 80\r\nHandling conn")
+	fmt.Fprint(&log, "ection for 8080\nerror: lost connection to pod")
+	log.mark("--- restarted")
+
+	var texts []string
+	for _, line := range log.entries() {
+		texts = append(texts, line.Text)
+	}
+	expected := []string{
+		"Forwarding from 127.0.0.1:8080 -> 80",
+		"Handling connection for 8080",
+		"error: lost connection to pod",
+		"--- restarted",
+	}
+	if strings.Join(texts, "|") != strings.Join(expected, "|") {
+		t.Errorf("Expected lines %q, got %q", expected, texts)
+	}
+}
+
+func TestOutputLogKeepsRecentLines(t *testing.T) {
+	var log outputLog
+	for i := 0; i < outputLogSize+5; i++ {
+		fmt.Fprintf(&log, "line %d\n", i)
+	}
+	fmt.Fprint(&log, strings.Repeat("x", maxOutputLineLength+1))
+
+	lines := log.entries()
+	if len(lines) != outputLogSize {
+		t.Fatalf("Expected %d lines, got %d", outputLogSize, len(lines))
+	}
+	if lines[0].Text != "line 6" {
+		t.Errorf("Expected oldest lines to be dropped, got %q first", lines[0].Text)
+	}
+	if len(lines[len(lines)-1].Text) != maxOutputLineLength {
+		t.Errorf("Expected an overlong line to be split at %d bytes, got %d", maxOutputLineLength, len(lines[len(lines)-1].Text))
+	}
+}
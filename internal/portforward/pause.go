@@ -0,0 +1,87 @@
+package portforward
+
+import (
+	"fmt"
+	"time"
+)
+
+// StatusPaused is reported while a service is paused: stopped until resumed, also
+// when failed services are retried or the others restart after a context change
+const StatusPaused = "Paused"
+
+// Pause stops the forward and keeps it stopped until Resume; Start and Restart leave a
+// paused service alone
+func (sm *ServiceManager) Pause() error {
+	sm.mutex.Lock()
+	sm.paused = true
+	sm.mutex.Unlock()
+
+	if err := sm.Stop(); err != nil {
+		return err
+	}
+
+	sm.mutex.Lock()
+	sm.status.InCooldown = false
+	sm.status.CooldownUntil = time.Time{}
+	sm.mutex.Unlock()
+	sm.logger.Info("Paused %s", sm.name)
+	return nil
+}
+
+// Unpause lets the service be started again; it stays stopped until started
+func (sm *ServiceManager) Unpause() {
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+	if sm.paused {
+		sm.paused = false
+		sm.status.Status = "Stopped"
+	}
+}
+
+// IsPaused reports whether the service is paused
+func (sm *ServiceManager) IsPaused() bool {
+	sm.mutex.RLock()
+	defer sm.mutex.RUnlock()
+	return sm.paused
+}
+
+// PauseService stops a service and its UIs and keeps it stopped, unlike StopService,
+// until it is resumed
+func (m *Manager) PauseService(name string) error {
+	m.mutex.RLock()
+	sm, exists := m.services[name]
+	handlers := m.enabledUIHandlers()
+	m.mutex.RUnlock()
+
+	if !exists {
+		return fmt.Errorf("service %s not found", name)
+	}
+	if sm.IsPaused() {
+		return nil
+	}
+
+	m.stopUIHandlers(handlers, name)
+	if err := sm.Pause(); err != nil {
+		return err
+	}
+	m.wakeMonitor()
+	return nil
+}
+
+// ResumeService starts a paused service again, skipping any cooldown from earlier failures
+func (m *Manager) ResumeService(name string) error {
+	m.mutex.RLock()
+	sm, exists := m.services[name]
+	m.mutex.RUnlock()
+
+	if !exists {
+		return fmt.Errorf("service %s not found", name)
+	}
+	if !sm.IsPaused() {
+		return fmt.Errorf("service %s is not paused", name)
+	}
+
+	sm.Unpause()
+	m.logger.Info("Resuming %s", name)
+	return m.StartService(name)
+}
@@ -0,0 +1,113 @@
+package portforward
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+)
+
+// errNoReadyPod is returned when no pod matching a selector is ready
+var errNoReadyPod = errors.New("no ready pod")
+
+// podList is the part of a kubectl List of Pods that resolving a pod selector needs
+type podList struct {
+	Items []struct {
+		Metadata struct {
+			Name              string     `json:"name"`
+			CreationTimestamp time.Time  `json:"creationTimestamp"`
+			DeletionTimestamp *time.Time `json:"deletionTimestamp"`
+		} `json:"metadata"`
+		Status struct {
+			Conditions []podCondition `json:"conditions"`
+		} `json:"status"`
+	} `json:"items"`
+}
+
+// podCondition is a condition of a pod's status, e.g. Ready
+type podCondition struct {
+	Type   string `json:"type"`
+	Status string `json:"status"`
+}
+
+// resolvePod returns the pod a pod-selector service forwards to: current while it is
+// still ready and matches the selector, or else the newest ready pod that does. Pods
+// being deleted don't count, so a rollout moves the forward to a new pod.
+func resolvePod(service config.Service, current string, run kubectlRunner) (string, error) {
+	args := append([]string{"get", "pods", "--selector", service.Selector, "--output", "json"}, kubectlScope(service)...)
+	output, err := run(args...)
+	if err != nil {
+		return "", fmt.Errorf("failed to list pods matching %s: %s", service.Selector, output)
+	}
+
+	var list podList
+	if err := json.Unmarshal([]byte(output), &list); err != nil {
+		return "", fmt.Errorf("failed to parse pods matching %s: %w", service.Selector, err)
+	}
+
+	var newest string
+	var newestCreated time.Time
+	for _, pod := range list.Items {
+		if pod.Metadata.DeletionTimestamp != nil || !podReady(pod.Status.Conditions) {
+			continue
+		}
+		if pod.Metadata.Name == current {
+			return current, nil
+		}
+		if newest == "" || pod.Metadata.CreationTimestamp.After(newestCreated) {
+			newest, newestCreated = pod.Metadata.Name, pod.Metadata.CreationTimestamp
+		}
+	}
+	if newest == "" {
+		return "", fmt.Errorf("%w matches %s in namespace %s", errNoReadyPod, service.Selector, service.Namespace)
+	}
+	return newest, nil
+}
+
+// podReady reports whether a pod's Ready condition is true
+func podReady(conditions []podCondition) bool {
+	for _, condition := range conditions {
+		if condition.Type == "Ready" {
+			return condition.Status == "True"
+		}
+	}
+	return false
+}
+
+// currentPod returns the pod a pod-selector service forwards to, or the configured pod
+// to start with, if any; the caller holds the mutex
+func (sm *ServiceManager) currentPod() string {
+	if sm.resolvedPod != "" {
+		return sm.resolvedPod
+	}
+	_, pod, _ := strings.Cut(sm.config.Target, "/")
+	return pod
+}
+
+// selectPod picks a ready pod for a pod-selector service before it starts, replacing
+// current when it was deleted or stopped being ready. It waits on kubectl, so the
+// caller doesn't hold the mutex.
+func (sm *ServiceManager) selectPod(kube config.Service, current string, run kubectlRunner) (string, error) {
+	pod, err := resolvePod(kube, current, run)
+	if err != nil && !errors.Is(err, errNoReadyPod) && current != "" {
+		// e.g. the API server is unreachable; kubectl reports it if the pod is gone too
+		sm.logger.Warn("Couldn't check the pods of %s, keeping %s: %v", sm.name, current, err)
+		return current, nil
+	}
+	return pod, err
+}
+
+// recordPod points a pod-selector service at the pod selectPod picked instead of
+// current; the caller holds the mutex
+func (sm *ServiceManager) recordPod(current, pod string) {
+	if pod != current && current != "" {
+		sm.logger.Info("Pod %s of %s is gone or not ready; forwarding to %s", current, sm.name, pod)
+	} else if sm.resolvedPod == "" {
+		sm.logger.Info("Forwarding %s to pod %s (selector %s)", sm.name, pod, sm.config.Selector)
+	}
+	sm.resolvedPod = pod
+	sm.status.Target = "pod/" + pod
+}
@@ -0,0 +1,92 @@
+package portforward
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+const testPods = `{"items": [
+	{"metadata": {"name": "shop-old", "creationTimestamp": "2026-01-01T10:00:00Z", "deletionTimestamp": "2026-01-02T10:00:00Z"},
+	 "status": {"conditions": [{"type": "Ready", "status": "True"}]}},
+	{"metadata": {"name": "shop-a", "creationTimestamp": "2026-01-02T10:00:00Z"},
+	 "status": {"conditions": [{"type": "Ready", "status": "True"}]}},
+	{"metadata": {"name": "shop-b", "creationTimestamp": "2026-01-02T11:00:00Z"},
+	 "status": {"conditions": [{"type": "Ready", "status": "True"}]}},
+	{"metadata": {"name": "shop-c", "creationTimestamp": "2026-01-02T12:00:00Z"},
+	 "status": {"conditions": [{"type": "Ready", "status": "False"}]}}
+]}`
+
+func TestResolvePod(t *testing.T) {
+	service := config.Service{Target: "pod", Selector: "app=shop", Namespace: "shop"}
+	run := fakeKubectl(map[string]string{"get pods": testPods})
+
+	tests := map[string]string{
+		"":          "shop-b", // Newest ready pod
+		"shop-a":    "shop-a", // Still ready, kept
+		"shop-old":  "shop-b", // Being deleted
+		"shop-c":    "shop-b", // Not ready
+		"shop-gone": "shop-b",
+	}
+	for current, expected := range tests {
+		pod, err := resolvePod(service, current, run)
+		if err != nil || pod != expected {
+			t.Errorf("current %q: expected %s, got %q (%v)", current, expected, pod, err)
+		}
+	}
+
+	_, err := resolvePod(service, "shop-a", fakeKubectl(map[string]string{"get pods": `{"items": []}`}))
+	if !errors.Is(err, errNoReadyPod) {
+		t.Errorf("Expected errNoReadyPod without matching pods, got %v", err)
+	}
+}
+
+func TestSelectPodKeepsPodWhenListingFails(t *testing.T) {
+	service := config.Service{Target: "pod/shop-a", Selector: "app=shop", Namespace: "shop", LocalPort: 8080}
+	sm := NewServiceManager("shop", service, utils.NewLogger(utils.LevelError))
+	resolve := func(run kubectlRunner) error {
+		current := sm.currentPod()
+		pod, err := sm.selectPod(sm.kubeService(), current, run)
+		if err == nil {
+			sm.recordPod(current, pod)
+		}
+		return err
+	}
+
+	if err := resolve(fakeKubectl(map[string]string{"get pods": "Error from server: connection refused"})); err != nil {
+		t.Fatalf("Expected the configured pod to be kept, got %v", err)
+	}
+	if sm.kubeService().Target != "pod/shop-a" {
+		t.Errorf("Expected target pod/shop-a, got %s", sm.kubeService().Target)
+	}
+
+	if err := resolve(fakeKubectl(map[string]string{"get pods": testPods})); err != nil {
+		t.Fatalf("Resolving the pod failed: %v", err)
+	}
+	if err := resolve(fakeKubectl(map[string]string{"get pods": strings.Replace(testPods, `"shop-a"`, `"shop-a-gone"`, 1)})); err != nil {
+		t.Fatalf("Resolving the pod failed: %v", err)
+	}
+	if target := sm.kubeService().Target; target != "pod/shop-b" || sm.GetStatus().Target != target {
+		t.Errorf("Expected the forward to move to pod/shop-b, got %s", target)
+	}
+}
+
+func TestStartChecksTheSelectedPod(t *testing.T) {
+	service := config.Service{Target: "pod", Selector: "app=shop", Namespace: "shop", LocalPort: 21022}
+	sm := NewServiceManager("shop", service, utils.NewLogger(utils.LevelError))
+	sm.kubectl = fakeKubectl(map[string]string{
+		"get pods":       testPods,
+		"get pod/shop-b": `Error from server (NotFound): pods "shop-b" not found`,
+	})
+
+	if err := sm.Start(); err == nil {
+		t.Fatal("Expected the preflight check of the selected pod to fail")
+	}
+	status := sm.GetStatus()
+	if status.Target != "pod/shop-b" || status.LastError != "pod/shop-b not found in namespace shop" {
+		t.Errorf("Expected the preflight check to target pod/shop-b, got %s: %s", status.Target, status.LastError)
+	}
+}
@@ -0,0 +1,139 @@
+package portforward
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+// forwardingPort matches the local port kubectl names when forwarding a connection fails,
+// e.g. "an error occurred forwarding 9090 -> 9090: ..."
+var forwardingPort = regexp.MustCompile(`forwarding (\d+) -> \d+`)
+
+// resolveExtraPorts picks the local ports of the service's further ports the way
+// resolvePort does for the main one, skipping taken; a port assigned to a 0 mapping is
+// kept across restarts while it is free. The caller holds the mutex.
+func (sm *ServiceManager) resolveExtraPorts(taken ...int) ([]config.PortStatus, error) {
+	if len(sm.config.Ports) == 0 || !sm.config.UsesKubectl() || sm.agentTunnel != nil || sm.forwardsUDP() {
+		return nil, nil // Only a TCP kubectl process forwards several ports
+	}
+
+	used := make(map[int]bool, len(taken)+len(sm.config.Ports))
+	for _, port := range taken {
+		used[port] = true
+	}
+
+	ports := make([]config.PortStatus, 0, len(sm.config.Ports))
+	for i, mapping := range sm.config.Ports {
+		port := mapping.Local
+		if port == 0 && i < len(sm.status.Ports) {
+			port = sm.status.Ports[i].LocalPort
+		}
+		if port == 0 || used[port] || !utils.IsPortAvailable(port) {
+			free, err := nextFreePort(mapping.Local, used)
+			if err != nil {
+				return nil, fmt.Errorf("no local port for target port %d: %w", mapping.Target, err)
+			}
+			if mapping.Local != 0 {
+				sm.logger.Warn("Port %d is in use for %s, using port %d instead", mapping.Local, sm.name, free)
+			}
+			port = free
+		}
+		used[port] = true
+		ports = append(ports, config.PortStatus{Name: mapping.Name, LocalPort: port, TargetPort: mapping.Target})
+	}
+	return ports, nil
+}
+
+// nextFreePort returns the first free port from start that isn't used, or any free port
+// when start is 0
+func nextFreePort(start int, used map[int]bool) (int, error) {
+	if start == 0 {
+		for {
+			port, err := utils.FindFreePort()
+			if err != nil || !used[port] {
+				return port, err
+			}
+		}
+	}
+	for port := start + 1; port <= 65535; port++ {
+		if !used[port] && utils.IsPortAvailable(port) {
+			return port, nil
+		}
+	}
+	return 0, fmt.Errorf("no available ports found starting from %d", start)
+}
+
+// portPairs returns the further ports kubectl forwards; the caller holds the mutex
+func (sm *ServiceManager) portPairs() []utils.PortPair {
+	pairs := make([]utils.PortPair, 0, len(sm.status.Ports))
+	for _, port := range sm.status.Ports {
+		pairs = append(pairs, utils.PortPair{Local: port.LocalPort, Target: port.TargetPort})
+	}
+	return pairs
+}
+
+// portsCheck returns a TCP check of each further port as the forward is now, reporting
+// one error (nil when healthy) per port; the caller holds the mutex
+func (sm *ServiceManager) portsCheck() func() []error {
+	if len(sm.status.Ports) == 0 {
+		return func() []error { return nil }
+	}
+	host := healthCheckHost(sm.config.BindAddress)
+	if sm.relay != nil {
+		host = "localhost" // kubectl listens on loopback behind a relay
+	}
+	ports := make([]int, len(sm.status.Ports))
+	for i, port := range sm.status.Ports {
+		ports[i] = port.LocalPort
+	}
+
+	parent, timeout := sm.ctx, healthTimeout(sm.config)
+	return func() []error {
+		errs := make([]error, len(ports))
+		for i, port := range ports {
+			ctx, cancel := context.WithTimeout(parent, timeout)
+			errs[i] = tcpChecker{}.Check(ctx, host, port)
+			cancel()
+		}
+		return errs
+	}
+}
+
+// recordPortsHealth stores the outcome of portsCheck; the caller holds the mutex
+func (sm *ServiceManager) recordPortsHealth(errs []error) {
+	if len(errs) != len(sm.status.Ports) {
+		return // The ports changed meanwhile
+	}
+	for i, err := range errs {
+		port := &sm.status.Ports[i]
+		port.Healthy = err == nil
+		if err != nil {
+			port.LastError = fmt.Sprintf("Health check failed: %v", err)
+		}
+	}
+}
+
+// portFailure records a failure kubectl logged for one of the further ports on that port
+// and reports whether line was about one; the caller holds the mutex
+func (sm *ServiceManager) portFailure(line string) bool {
+	match := forwardingPort.FindStringSubmatch(line)
+	if match == nil {
+		return false
+	}
+	local, _ := strconv.Atoi(match[1])
+	for i := range sm.status.Ports {
+		if port := &sm.status.Ports[i]; port.LocalPort == local {
+			if port.LastError != line {
+				sm.logger.Warn("kubectl port-forward for %s reported on port %d: %s", sm.name, local, line)
+				port.LastError = line
+			}
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,76 @@
+package portforward
+
+import (
+	"net"
+	"testing"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+func TestResolveExtraPorts(t *testing.T) {
+	busy, err := net.Listen("tcp", ":21201")
+	if err != nil {
+		t.Skipf("Port 21201 is unavailable: %v", err)
+	}
+	defer busy.Close()
+
+	service := config.Service{Target: "service/api", LocalPort: 21200, TargetPort: 80, Ports: []config.PortMapping{
+		{Name: "metrics", Local: 21201, Target: 9090},
+		{Local: 21200, Target: 8081},
+		{Target: 8082},
+	}}
+	sm := NewServiceManager("api", service, utils.NewLogger(utils.LevelError))
+
+	ports, err := sm.resolveExtraPorts(21200)
+	if err != nil {
+		t.Fatalf("resolveExtraPorts failed: %v", err)
+	}
+	if len(ports) != 3 || ports[0].Name != "metrics" || ports[0].TargetPort != 9090 {
+		t.Fatalf("Expected a status per port, got %+v", ports)
+	}
+	if ports[0].LocalPort <= 21201 || ports[1].LocalPort <= 21200 || ports[0].LocalPort == ports[1].LocalPort {
+		t.Errorf("Expected ports in use to be replaced by distinct later ones, got %+v", ports)
+	}
+	if ports[2].LocalPort == 0 {
+		t.Errorf("Expected a free port for local 0, got %+v", ports[2])
+	}
+
+	sm.status.Ports = ports
+	again, err := sm.resolveExtraPorts(21200)
+	if err != nil || again[2].LocalPort != ports[2].LocalPort {
+		t.Errorf("Expected the assigned port to be kept across restarts, got %+v (%v)", again, err)
+	}
+
+	sm.config.Type = "ssh"
+	if ports, _ := sm.resolveExtraPorts(21200); ports != nil {
+		t.Errorf("Expected further ports to need kubectl, got %+v", ports)
+	}
+}
+
+func TestOutputFailureOfExtraPort(t *testing.T) {
+	sm := NewServiceManager("api", config.Service{Target: "service/api", LocalPort: 8080, TargetPort: 80}, utils.NewLogger(utils.LevelError))
+	tail := &outputTail{}
+	sm.output = tail
+	sm.status.Status = "Running"
+	sm.status.Ports = []config.PortStatus{{LocalPort: 9090, TargetPort: 9090, Healthy: true}}
+
+	line := "an error occurred forwarding 9090 -> 9090: error forwarding port 9090 to pod api-0: connection refused"
+	sm.outputFailure(tail, line)
+	if status := sm.GetStatus(); status.LastError != "" || status.Ports[0].LastError != line {
+		t.Errorf("Expected the failure on the port only, got %q and %+v", status.LastError, status.Ports)
+	}
+
+	line = "an error occurred forwarding 8080 -> 80: error forwarding port 80 to pod api-0: connection refused"
+	sm.outputFailure(tail, line)
+	if status := sm.GetStatus(); status.LastError != line {
+		t.Errorf("Expected a failure of the main port on the service, got %q", status.LastError)
+	}
+
+	sm.mutex.Lock()
+	sm.recordPortsHealth([]error{net.ErrClosed})
+	sm.mutex.Unlock()
+	if port := sm.GetStatus().Ports[0]; port.Healthy || port.LastError == "" {
+		t.Errorf("Expected the failed check on the port, got %+v", port)
+	}
+}
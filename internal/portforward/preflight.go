@@ -0,0 +1,87 @@
+package portforward
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+)
+
+// preflightTimeout bounds each kubectl call made by the preflight check
+const preflightTimeout = "5s"
+
+// kubectlTimeout bounds each kubectl run, including exec credential plugins that
+// --request-timeout doesn't cover
+const kubectlTimeout = 15 * time.Second
+
+// kubectlRunner runs kubectl with the given arguments and returns its combined output
+type kubectlRunner func(args ...string) (string, error)
+
+// newKubectlRunner returns a runner for the service's kubectl binary and environment.
+// Each run is killed after kubectlTimeout or once ctx is done.
+func newKubectlRunner(ctx context.Context, service config.Service) kubectlRunner {
+	binary := service.KubectlPath
+	if binary == "" {
+		binary = "kubectl"
+	}
+	env := service.Environ()
+	return func(args ...string) (string, error) {
+		ctx, cancel := context.WithTimeout(ctx, kubectlTimeout)
+		defer cancel()
+
+		cmd := exec.CommandContext(ctx, binary, args...)
+		cmd.Env = env
+		cmd.WaitDelay = time.Second // A credential plugin left running may hold the output open
+		output, err := cmd.CombinedOutput()
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return fmt.Sprintf("kubectl timed out after %s", kubectlTimeout), ctx.Err()
+		}
+		return strings.TrimSpace(string(output)), err
+	}
+}
+
+// kubectlTarget returns the kind/name of a service's target
+func kubectlTarget(service config.Service) string {
+	if !strings.Contains(service.Target, "/") {
+		return "pod/" + service.Target // kubectl port-forward treats a bare name as a pod
+	}
+	return service.Target
+}
+
+// kubectlScope returns the flags selecting a service's cluster and namespace for a preflight call
+func kubectlScope(service config.Service) []string {
+	scope := append([]string{"--request-timeout", preflightTimeout}, service.KubectlScope()...)
+	if service.Namespace != "" {
+		scope = append(scope, "--namespace", service.Namespace)
+	}
+	return scope
+}
+
+// preflightTarget verifies that the namespace and target of a kubectl forward exist
+// and that port-forwarding is allowed, turning the usual failures into actionable
+// errors. Checks that can't be completed (e.g. the API server is unreachable) are
+// skipped so kubectl itself reports the problem.
+func preflightTarget(service config.Service, run kubectlRunner) error {
+	target := kubectlTarget(service)
+	scope := kubectlScope(service)
+
+	output, err := run(append([]string{"get", target, "--output", "name"}, scope...)...)
+	if err != nil && strings.Contains(output, "NotFound") {
+		// A missing namespace reports the target as not found too
+		if nsOutput, nsErr := run(append([]string{"get", "namespace", service.Namespace, "--output", "name"}, scope...)...); nsErr != nil && strings.Contains(nsOutput, "NotFound") {
+			return fmt.Errorf("namespace %s not found; check the namespace and the Kubernetes context", service.Namespace)
+		}
+		return fmt.Errorf("%s not found in namespace %s", target, service.Namespace)
+	}
+
+	output, _ = run(append([]string{"auth", "can-i", "create", "pods/portforward"}, scope...)...)
+	if output == "no" {
+		return fmt.Errorf("forbidden: not allowed to port-forward in namespace %s (check with 'kubectl auth can-i create pods/portforward -n %s')",
+			service.Namespace, service.Namespace)
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package portforward
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+// fakeKubectl answers kubectl calls by their first arguments
+func fakeKubectl(responses map[string]string) kubectlRunner {
+	return func(args ...string) (string, error) {
+		key := strings.Join(args[:2], " ")
+		if args[0] == "auth" {
+			key = "auth"
+		}
+		output, ok := responses[key]
+		if !ok {
+			return "", nil
+		}
+		if strings.HasPrefix(output, "Error") || output == "no" {
+			return output, errors.New("exit status 1")
+		}
+		return output, nil
+	}
+}
+
+func TestPreflightTarget(t *testing.T) {
+	service := config.Service{Target: "service/api", Namespace: "payments"}
+
+	tests := []struct {
+		name      string
+		responses map[string]string
+		expected  string
+	}{
+		{"ok", map[string]string{"get service/api": "service/api", "auth": "yes"}, ""},
+		{"missing namespace", map[string]string{
+			"get service/api": `Error from server (NotFound): services "api" not found`,
+			"get namespace":   `Error from server (NotFound): namespaces "payments" not found`,
+		}, "namespace payments not found"},
+		{"missing target", map[string]string{
+			"get service/api": `Error from server (NotFound): services "api" not found`,
+			"get namespace":   "namespace/payments",
+		}, "service/api not found in namespace payments"},
+		{"forbidden", map[string]string{"get service/api": "service/api", "auth": "no"}, "forbidden"},
+		{"unreachable", map[string]string{"get service/api": "Error: dial tcp: i/o timeout"}, ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := preflightTarget(service, fakeKubectl(test.responses))
+			if test.expected == "" {
+				if err != nil {
+					t.Errorf("Expected the preflight to pass, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), test.expected) {
+				t.Errorf("Expected an error containing %q, got %v", test.expected, err)
+			}
+		})
+	}
+}
+
+func TestPreflightFailureMarksServiceFailed(t *testing.T) {
+	sm := NewServiceManager("api", config.Service{Target: "api", Namespace: "payments", LocalPort: 21020}, utils.NewLogger(utils.LevelError))
+	sm.kubectl = fakeKubectl(map[string]string{"get pod/api": `Error from server (NotFound): pods "api" not found`})
+
+	if err := sm.Start(); err == nil {
+		t.Fatal("Expected the start to fail")
+	}
+	status := sm.GetStatus()
+	if status.Status != "Failed" || status.LastError != "pod/api not found in namespace payments" {
+		t.Errorf("Expected a failed status with the preflight error, got %s: %s", status.Status, status.LastError)
+	}
+	if status.ErrorCategory != config.ErrorNotFound {
+		t.Errorf("Expected the not-found category, got %q", status.ErrorCategory)
+	}
+}
+
+func TestPreflightRunsWithoutMutex(t *testing.T) {
+	sm := NewServiceManager("api", config.Service{Target: "api", Namespace: "payments", LocalPort: 21021}, utils.NewLogger(utils.LevelError))
+	called, release := make(chan struct{}, 1), make(chan struct{})
+	sm.kubectl = func(args ...string) (string, error) {
+		select {
+		case called <- struct{}{}:
+		default:
+		}
+		<-release
+		return `Error from server (NotFound): pods "api" not found`, errors.New("exit status 1")
+	}
+
+	done := make(chan error)
+	go func() { done <- sm.Start() }()
+	<-called
+
+	// A hanging kubectl doesn't block status reads
+	read := make(chan string)
+	go func() { read <- sm.GetStatus().Status }()
+	select {
+	case <-read:
+	case <-time.After(time.Second):
+		t.Fatal("Expected GetStatus to return while the preflight check runs")
+	}
+
+	close(release)
+	if err := <-done; err == nil {
+		t.Error("Expected the start to fail")
+	}
+}
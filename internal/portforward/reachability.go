@@ -0,0 +1,111 @@
+package portforward
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+)
+
+const (
+	// StatusClusterUnreachable is reported for kubectl services while their API server can't be reached
+	StatusClusterUnreachable = "Cluster Unreachable"
+
+	// apiServerTimeout bounds each reachability probe
+	apiServerTimeout = "5s"
+)
+
+// kubeTarget identifies a cluster services forward through: a context of a kubeconfig
+// ("" for the shared kubeconfig and for its current context)
+type kubeTarget struct {
+	Kubeconfig string
+	Context    string
+}
+
+// targetOf returns the kubeconfig context a service forwards through
+func targetOf(service config.Service) kubeTarget {
+	return kubeTarget{Kubeconfig: service.KubeconfigPath(), Context: service.Context}
+}
+
+// clusterProbe tracks API server reachability per kubeconfig context, so an outage
+// isn't mistaken for every service failing at once
+type clusterProbe struct {
+	probe       func(target kubeTarget) error // Replaced in tests
+	unreachable map[kubeTarget]bool
+	probing     bool
+	mutex       sync.Mutex
+}
+
+// newClusterProbe creates a probe using kubectl
+func newClusterProbe() *clusterProbe {
+	return &clusterProbe{
+		probe:       probeAPIServer,
+		unreachable: make(map[kubeTarget]bool),
+	}
+}
+
+// check probes the given contexts in the background and calls onChange for each
+// context whose reachability changed. Checks don't overlap.
+func (p *clusterProbe) check(targets []kubeTarget, onChange func(target kubeTarget, reachable bool, err error)) {
+	p.mutex.Lock()
+	if p.probing {
+		p.mutex.Unlock()
+		return
+	}
+	p.probing = true
+	p.mutex.Unlock()
+
+	go func() {
+		defer func() {
+			p.mutex.Lock()
+			p.probing = false
+			p.mutex.Unlock()
+		}()
+		p.run(targets, onChange)
+	}()
+}
+
+// run probes each context once
+func (p *clusterProbe) run(targets []kubeTarget, onChange func(target kubeTarget, reachable bool, err error)) {
+	for _, target := range targets {
+		err := p.probe(target)
+
+		p.mutex.Lock()
+		wasUnreachable := p.unreachable[target]
+		p.unreachable[target] = err != nil
+		p.mutex.Unlock()
+
+		if wasUnreachable != (err != nil) {
+			onChange(target, err == nil, err)
+		}
+	}
+}
+
+// probeAPIServer asks the API server whether it is ready
+func probeAPIServer(target kubeTarget) error {
+	args := []string{"get", "--raw", "/readyz", "--request-timeout", apiServerTimeout}
+	if target.Kubeconfig != "" {
+		args = append(args, "--kubeconfig", target.Kubeconfig)
+	}
+	if target.Context != "" {
+		args = append(args, "--context", target.Context)
+	}
+
+	output, err := exec.Command("kubectl", args...).CombinedOutput()
+	if err != nil {
+		message := strings.TrimSpace(string(output))
+		// The API server answered; credentials are a per-service problem, not an outage
+		if strings.Contains(message, "Unauthorized") || strings.Contains(message, "Forbidden") {
+			return nil
+		}
+		if message == "" {
+			return err
+		}
+		// kubectl repeats the error for each retry; the last line is enough
+		lines := strings.Split(message, "\n")
+		return fmt.Errorf("%s", lines[len(lines)-1])
+	}
+	return nil
+}
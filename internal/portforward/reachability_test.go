@@ -0,0 +1,57 @@
+package portforward
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+func TestClusterUnreachableMasksServiceStatus(t *testing.T) {
+	cfg := &config.Config{
+		PortForwards: map[string]config.Service{
+			"api":     {Target: "service/api", Namespace: "default", Type: "rest"},
+			"staging": {Target: "service/api", Namespace: "default", Type: "rest", Context: "staging"},
+			"eu":      {Target: "service/api", Namespace: "default", Type: "rest", Context: "staging", Kubeconfig: "/etc/kube/eu.yaml"},
+			"bastion": {Type: "ssh"},
+		},
+		MonitoringInterval: time.Second,
+	}
+	manager := NewManager(cfg, utils.NewLogger(utils.LevelError))
+	for name, service := range cfg.PortForwards {
+		sm := NewServiceManager(name, service, manager.logger)
+		sm.status.Status = "Failed"
+		manager.services[name] = sm
+	}
+
+	targets := kubeTargets(manager.services)
+	expected := []kubeTarget{{}, {Context: "staging"}, {Kubeconfig: "/etc/kube/eu.yaml", Context: "staging"}}
+	if !reflect.DeepEqual(targets, expected) {
+		t.Errorf("Expected the current, staging and eu kubeconfig contexts, got %v", targets)
+	}
+
+	// Only the current context's and the eu cluster's API servers are down
+	manager.cluster.probe = func(target kubeTarget) error {
+		if target.Context == "" || target.Kubeconfig != "" {
+			return errors.New("dial tcp 10.0.0.1:443: i/o timeout")
+		}
+		return nil
+	}
+	manager.cluster.run(targets, manager.handleClusterReachability)
+
+	if status := manager.services["api"].GetStatus(); status.Status != StatusClusterUnreachable {
+		t.Errorf("Expected api to be %q, got %q", StatusClusterUnreachable, status.Status)
+	}
+	if status := manager.services["eu"].GetStatus(); status.Status != StatusClusterUnreachable {
+		t.Errorf("Expected eu to be %q, got %q", StatusClusterUnreachable, status.Status)
+	}
+	if status := manager.services["staging"].GetStatus(); status.Status != "Failed" {
+		t.Errorf("Expected staging in another context to stay Failed, got %q", status.Status)
+	}
+	if status := manager.services["bastion"].GetStatus(); status.Status != "Failed" {
+		t.Errorf("Expected ssh service to be unaffected, got %q", status.Status)
+	}
+}
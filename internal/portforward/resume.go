@@ -0,0 +1,85 @@
+package portforward
+
+import (
+	"net"
+	"sort"
+	"strings"
+	"time"
+)
+
+// resumeGap is how far past the monitoring interval a tick may arrive before
+// the machine is considered to have been asleep
+const resumeGap = 10 * time.Second
+
+// resumeDetector notices system sleep/wake and network interface changes
+// (Wi-Fi switches, VPN up/down), after which every forward is dead or routed
+// through the wrong network even if it hasn't failed a health check yet
+type resumeDetector struct {
+	interval    time.Duration
+	lastTick    time.Time
+	fingerprint string
+	interfaces  func() (string, error) // Replaced in tests
+}
+
+// newResumeDetector creates a detector for a monitor loop ticking every interval
+func newResumeDetector(interval time.Duration) *resumeDetector {
+	d := &resumeDetector{interval: interval, interfaces: networkFingerprint}
+	d.fingerprint, _ = d.interfaces()
+	return d
+}
+
+// check is called on every monitor tick and returns why the forwards should be
+// restarted, or "" when nothing changed
+func (d *resumeDetector) check(now time.Time) string {
+	// Wall clock time, which keeps running while the machine sleeps
+	now = now.Round(0)
+	last := d.lastTick
+	d.lastTick = now
+
+	reason := ""
+	if !last.IsZero() {
+		if elapsed := now.Sub(last); elapsed > d.interval+resumeGap {
+			reason = "System resumed after " + elapsed.Round(time.Second).String()
+		}
+	}
+
+	fingerprint, err := d.interfaces()
+	if err != nil {
+		return reason
+	}
+	if fingerprint != d.fingerprint {
+		d.fingerprint = fingerprint
+		if reason == "" {
+			reason = "Network interfaces changed"
+		}
+	}
+	return reason
+}
+
+// networkFingerprint summarizes the addresses of the active non-loopback interfaces
+func networkFingerprint() (string, error) {
+	interfaces, err := net.Interfaces()
+	if err != nil {
+		return "", err
+	}
+
+	var entries []string
+	for _, iface := range interfaces {
+		if iface.Flags&net.FlagUp == 0 || iface.Flags&net.FlagLoopback != 0 {
+			continue
+		}
+		addrs, err := iface.Addrs()
+		if err != nil {
+			continue
+		}
+		for _, addr := range addrs {
+			// Link-local IPv6 addresses come and go without affecting routes
+			if ipNet, ok := addr.(*net.IPNet); ok && ipNet.IP.IsLinkLocalUnicast() {
+				continue
+			}
+			entries = append(entries, iface.Name+"="+addr.String())
+		}
+	}
+	sort.Strings(entries)
+	return strings.Join(entries, ","), nil
+}
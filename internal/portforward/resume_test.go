@@ -0,0 +1,38 @@
+package portforward
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestResumeDetector(t *testing.T) {
+	fingerprint := "en0=192.168.1.10/24"
+	detector := &resumeDetector{
+		interval:    2 * time.Second,
+		fingerprint: fingerprint,
+		interfaces:  func() (string, error) { return fingerprint, nil },
+	}
+
+	start := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	if reason := detector.check(start); reason != "" {
+		t.Errorf("Expected no reason on the first tick, got %q", reason)
+	}
+	if reason := detector.check(start.Add(2 * time.Second)); reason != "" {
+		t.Errorf("Expected no reason for a regular tick, got %q", reason)
+	}
+
+	// Lid closed overnight
+	if reason := detector.check(start.Add(8 * time.Hour)); !strings.HasPrefix(reason, "System resumed") {
+		t.Errorf("Expected sleep to be detected, got %q", reason)
+	}
+
+	// VPN came up
+	fingerprint += ",utun3=10.8.0.2/32"
+	if reason := detector.check(start.Add(8*time.Hour + 2*time.Second)); reason != "Network interfaces changed" {
+		t.Errorf("Expected network change to be detected, got %q", reason)
+	}
+	if reason := detector.check(start.Add(8*time.Hour + 4*time.Second)); reason != "" {
+		t.Errorf("Expected the change to be reported once, got %q", reason)
+	}
+}
@@ -0,0 +1,63 @@
+package portforward
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+)
+
+// StatusWaitingForWorkload is reported while a service with waitForRollout waits for
+// its workload to become ready
+const StatusWaitingForWorkload = "Waiting for workload"
+
+// workloadReady checks whether a service's target can take traffic: workloads must have
+// finished rolling out, services need ready endpoints and pods must be ready. When it
+// isn't ready, the reason says what is missing. Checks that can't be completed return
+// an error and don't hold the forward back.
+func workloadReady(service config.Service, run kubectlRunner) (bool, string, error) {
+	target := kubectlTarget(service)
+	kind, name, _ := strings.Cut(target, "/")
+	scope := kubectlScope(service)
+
+	switch kind {
+	case "deployment", "deployments", "deploy", "statefulset", "statefulsets", "sts", "daemonset", "daemonsets", "ds":
+		output, err := run(append([]string{"rollout", "status", target, "--watch=false"}, scope...)...)
+		if err != nil {
+			return false, "", fmt.Errorf("rollout status of %s: %w", target, err)
+		}
+		if strings.Contains(output, "successfully rolled out") {
+			return true, "", nil
+		}
+		return false, lastLine(output), nil
+
+	case "service", "services", "svc":
+		output, err := run(append([]string{"get", "endpoints", name, "--output", "jsonpath={.subsets[*].addresses[*].ip}"}, scope...)...)
+		if err != nil {
+			return false, "", fmt.Errorf("endpoints of %s: %w", target, err)
+		}
+		if output == "" {
+			return false, fmt.Sprintf("%s has no ready endpoints", target), nil
+		}
+		return true, "", nil
+
+	case "pod", "pods", "po":
+		output, err := run(append([]string{"get", "pod", name, "--output", `jsonpath={.status.conditions[?(@.type=="Ready")].status}`}, scope...)...)
+		if err != nil {
+			return false, "", fmt.Errorf("readiness of %s: %w", target, err)
+		}
+		if output != "True" {
+			return false, fmt.Sprintf("%s is not ready", target), nil
+		}
+		return true, "", nil
+	}
+
+	// Other kinds have no readiness to wait for
+	return true, "", nil
+}
+
+// lastLine returns the last line of kubectl output
+func lastLine(output string) string {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	return lines[len(lines)-1]
+}
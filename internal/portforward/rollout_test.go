@@ -0,0 +1,134 @@
+package portforward
+
+import (
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+func TestWorkloadReady(t *testing.T) {
+	tests := []struct {
+		name      string
+		target    string
+		responses map[string]string
+		ready     bool
+		reason    string
+	}{
+		{"rolled out", "deployment/api", map[string]string{"rollout status": `deployment "api" successfully rolled out`}, true, ""},
+		{"rolling out", "deployment/api", map[string]string{
+			"rollout status": `Waiting for deployment "api" rollout to finish: 1 of 3 updated replicas are available...`,
+		}, false, `Waiting for deployment "api" rollout to finish: 1 of 3 updated replicas are available...`},
+		{"endpoints", "service/api", map[string]string{"get endpoints": "10.0.0.5 10.0.0.6"}, true, ""},
+		{"no endpoints", "service/api", map[string]string{}, false, "service/api has no ready endpoints"},
+		{"pod ready", "api-0", map[string]string{"get pod": "True"}, true, ""},
+		{"pod not ready", "api-0", map[string]string{"get pod": "False"}, false, "pod/api-0 is not ready"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			service := config.Service{Target: test.target, Namespace: "payments"}
+			ready, reason, err := workloadReady(service, fakeKubectl(test.responses))
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if ready != test.ready || reason != test.reason {
+				t.Errorf("Expected ready=%v %q, got ready=%v %q", test.ready, test.reason, ready, reason)
+			}
+		})
+	}
+}
+
+func TestWaitForRolloutHoldsTheForward(t *testing.T) {
+	service := config.Service{Target: "service/api", Namespace: "payments", LocalPort: 21021, WaitForRollout: true}
+	sm := NewServiceManager("api", service, utils.NewLogger(utils.LevelError))
+	sm.kubectl = fakeKubectl(map[string]string{"get service/api": "service/api", "auth": "yes"})
+
+	if err := sm.Start(); err == nil {
+		t.Fatal("Expected the start to wait for the workload")
+	}
+	status := sm.GetStatus()
+	if status.Status != StatusWaitingForWorkload || status.LastError != "service/api has no ready endpoints" {
+		t.Errorf("Expected the service to wait for its workload, got %s: %s", status.Status, status.LastError)
+	}
+	if status.InCooldown || sm.failureCount != 0 {
+		t.Errorf("Expected waiting not to count as a failure")
+	}
+	if len(status.RecentErrors) != 0 {
+		t.Errorf("Expected waiting to stay out of the error history, got %v", status.RecentErrors)
+	}
+	if sm.WorkloadCheckDue() {
+		t.Errorf("Expected the next check to wait for the check interval")
+	}
+}
+
+func TestWorkloadCheckRunsWithoutMutex(t *testing.T) {
+	service := config.Service{Target: "service/api", Namespace: "payments", LocalPort: 21021, WaitForRollout: true}
+	sm := NewServiceManager("api", service, utils.NewLogger(utils.LevelError))
+	called, release := make(chan struct{}), make(chan struct{})
+	preflight := fakeKubectl(map[string]string{"get service/api": "service/api", "auth": "yes"})
+	sm.kubectl = func(args ...string) (string, error) {
+		if args[0] == "get" && args[1] == "endpoints" {
+			close(called)
+			<-release
+		}
+		return preflight(args...)
+	}
+
+	done := make(chan error)
+	go func() { done <- sm.Start() }()
+	<-called
+
+	// A hanging kubectl doesn't block status reads
+	read := make(chan string)
+	go func() { read <- sm.GetStatus().Status }()
+	select {
+	case <-read:
+	case <-time.After(time.Second):
+		t.Fatal("Expected GetStatus to return while the workload is checked")
+	}
+
+	close(release)
+	<-done
+	if status := sm.GetStatus().Status; status != StatusWaitingForWorkload {
+		t.Errorf("Expected the service to wait for its workload, got %s", status)
+	}
+}
+
+func TestWorkloadCheckNotDueWhileStarting(t *testing.T) {
+	service := config.Service{Target: "service/api", Namespace: "payments", LocalPort: 21021, WaitForRollout: true}
+	sm := NewServiceManager("api", service, utils.NewLogger(utils.LevelError))
+	sm.status.Status = StatusWaitingForWorkload
+	called, release := make(chan struct{}), make(chan struct{})
+	preflight := fakeKubectl(map[string]string{"get service/api": "service/api", "auth": "yes"})
+	sm.kubectl = func(args ...string) (string, error) {
+		if args[0] == "get" && args[1] == "endpoints" {
+			close(called)
+			<-release
+		}
+		return preflight(args...)
+	}
+
+	done := make(chan error)
+	go func() { done <- sm.Start() }()
+	<-called
+	if sm.WorkloadCheckDue() {
+		t.Error("Expected no further start while the workload is being checked")
+	}
+	close(release)
+	<-done
+
+	// A forward that is already up isn't started twice
+	sm.mutex.Lock()
+	sm.cmd = &exec.Cmd{}
+	sm.mutex.Unlock()
+	sm.kubectl = func(args ...string) (string, error) {
+		t.Errorf("Expected no kubectl call for a running forward, got %v", args)
+		return "", nil
+	}
+	if err := sm.Start(); err != nil {
+		t.Errorf("Expected the start of a running forward to be skipped, got %v", err)
+	}
+}
@@ -2,12 +2,20 @@ package portforward
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"net"
 	"os/exec"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/victorkazakov/kportforward/internal/agent"
 	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/plugin"
+	"github.com/victorkazakov/kportforward/internal/relay"
 	"github.com/victorkazakov/kportforward/internal/utils"
 )
 
@@ -18,6 +26,84 @@ type ServiceManager struct {
 	status *config.ServiceStatus
 	cmd    *exec.Cmd
 	logger *utils.Logger
+
+	// Optional relay in front of kubectl (TLS, auth, capture); kubectl then listens on backendPort
+	relay       *relay.Relay
+	backendPort int
+	capture     config.CaptureConfig
+
+	// Relay of a protocol: udp service, which kubectl forwards over TCP to a socat pod
+	udpRelay *relay.UDPRelay
+
+	// Recent round-trip times measured through the forward
+	latency latencyWindow
+
+	// Recent connections through the relay when accessLog is enabled
+	accessLog accessLog
+
+	// Recent errors, LastError only holds the latest
+	errorHistory errorHistory
+
+	// Decides whether the running forward is healthy
+	health HealthChecker
+
+	// Called when the forward process exits on its own
+	onExit func()
+
+	// Runs kubectl for the preflight check; nil skips it
+	kubectl kubectlRunner
+
+	// Pod a pod-selector service currently forwards to, picked again on each start
+	resolvedPod string
+
+	// Last time the Teleport session was checked
+	loginCheckedAt time.Time
+
+	// Last time the workload was checked for readiness
+	workloadCheckedAt time.Time
+
+	// Serializes resuming a suspended forward so concurrent connections start it once
+	wakeMutex sync.Mutex
+
+	// Serializes Start and Stop, so they can wait on hooks and kubectl without the mutex
+	startMutex sync.Mutex
+
+	// Set while Start waits on tsh, its hook or kubectl without the mutex, so the monitor
+	// doesn't start the service again meanwhile
+	starting bool
+
+	// Why the service's API server is unreachable; empty while it is reachable
+	clusterError string
+
+	// Context a service following the current context stays in after a context change
+	// was kept; empty follows the current context
+	pinnedContext string
+
+	// Stopped until resumed; see Pause
+	paused bool
+
+	// Latest output of the kubectl process, used to classify its failures
+	output *outputTail
+
+	// Output of the forward processes across restarts, for the TUI's output viewer
+	outputLog outputLog
+
+	// Plugin serving a type: plugin:<name> service while it runs
+	plugin *plugin.Process
+
+	// Tunnel to the in-cluster agent; when set, the forward runs in-process through it
+	// instead of as a kubectl process
+	agentTunnel  *agent.Tunnel
+	agentForward *agent.Forward
+
+	// Type inferred by probing the forward when the configuration has none
+	detectedType  string
+	typeDetected  bool
+	detectingType bool
+
+	// Latest status handed out by GetStatus, with its generation
+	snapshot statusSnapshot
+
 	mutex  sync.RWMutex
 	ctx    context.Context
 	cancel context.CancelFunc
@@ -28,96 +114,363 @@ type ServiceManager struct {
 	backoffSeconds []int
 }
 
+// stableRunDuration is how long a forward has to run before an exit no longer counts as a failed start
+const stableRunDuration = 30 * time.Second
+
+// startupGracePeriod is how long a started forward runs before its health is checked
+const startupGracePeriod = 5 * time.Second
+
+// loginCheckInterval is how often a service waiting for Teleport login checks for a new session
+const loginCheckInterval = 15 * time.Second
+
+// pinnedRetryDelay is how long a pinned service waits between retries instead of backing off
+const pinnedRetryDelay = 2 * time.Second
+
+// StatusSuspended is reported while an idle forward is stopped and its relay waits for the next connection
+const StatusSuspended = "Suspended"
+
+// resumeTimeout bounds how long a connection waits for a suspended forward to come back
+const resumeTimeout = 15 * time.Second
+
+// workloadCheckInterval is how often a service waiting for its workload checks the rollout again
+const workloadCheckInterval = 5 * time.Second
+
 // NewServiceManager creates a new service manager
 func NewServiceManager(name string, service config.Service, logger *utils.Logger) *ServiceManager {
 	ctx, cancel := context.WithCancel(context.Background())
 
+	health, err := newHealthChecker(service)
+	if err != nil {
+		logger.Warn("Invalid health check for %s, using TCP: %v", name, err)
+		health = tcpChecker{}
+	}
+	if service.IsUDP() && !service.UsesKubectl() {
+		logger.Warn("Protocol udp isn't supported for %s services like %s, using TCP", service.Type, name)
+	} else if service.Protocol != "" && !service.IsUDP() && !strings.EqualFold(service.Protocol, "tcp") {
+		logger.Warn("Unknown protocol %q for %s, using TCP", service.Protocol, name)
+	}
+
 	return &ServiceManager{
 		name:           name,
+		health:         health,
+		kubectl:        newKubectlRunner(ctx, service),
 		config:         service,
 		logger:         logger,
 		ctx:            ctx,
 		cancel:         cancel,
 		backoffSeconds: []int{5, 10, 20, 40, 60}, // Exponential backoff: 5s, 10s, 20s, 40s, 60s max
 		status: &config.ServiceStatus{
-			Name:         name,
-			Status:       "Starting",
-			LocalPort:    service.LocalPort,
-			RestartCount: 0,
-			InCooldown:   false,
+			Name:           name,
+			Namespace:      service.Namespace,
+			Target:         service.Target,
+			Type:           service.Type,
+			Status:         "Starting",
+			ConfiguredPort: service.LocalPort,
+			LocalPort:      service.LocalPort,
+			Wildcard:       service.Wildcard,
+			RestartCount:   0,
+			InCooldown:     false,
 		},
 	}
 }
 
-// Start begins the port-forward process
+// Start begins the port-forward process. The Teleport session check, the preStart hook and
+// the kubectl checks before it run without the mutex, so a slow tsh, hook or API server
+// doesn't hold up status reads.
 func (sm *ServiceManager) Start() error {
+	sm.startMutex.Lock()
+	defer sm.startMutex.Unlock()
+
 	sm.mutex.Lock()
 	defer sm.mutex.Unlock()
 
+	if sm.paused {
+		return nil
+	}
+
+	// A forward that is already up, e.g. after an overlapping start, is left alone
+	if sm.cmd != nil || sm.agentForward != nil || sm.relay != nil || sm.udpRelay != nil {
+		return nil
+	}
+
 	// Check if we're in cooldown
 	if sm.isInCooldown() {
 		sm.status.Status = "Cooldown"
 		sm.status.InCooldown = true
+		sm.status.CooldownUntil = sm.cooldownUntil
 		return fmt.Errorf("service %s is in cooldown until %v", sm.name, sm.cooldownUntil)
 	}
 
+	// tsh, the preStart hook and kubectl can take a while, so they run without the mutex
+	teleport := sm.config.Type == "teleport"
+	useKubectl := sm.config.UsesKubectl() && sm.kubectl != nil
+	kube, current, run, forwardsUDP := sm.kubeService(), sm.currentPod(), sm.kubectl, sm.forwardsUDP()
+	sm.starting = true
+	sm.mutex.Unlock()
+	var sessionErr, hookErr error
+	var checks kubectlChecks
+	if teleport {
+		sessionErr = utils.CheckTeleportSession(sm.ctx, kube.Teleport.Proxy)
+	}
+	if sessionErr == nil {
+		hookErr = sm.runHook("preStart") // e.g. refresh a token the tunnel needs
+	}
+	if sessionErr == nil && hookErr == nil && useKubectl {
+		checks = sm.runKubectlChecks(kube, current, run, forwardsUDP)
+	}
+	sm.mutex.Lock()
+	sm.starting = false
+
+	if sm.paused {
+		return nil // Paused meanwhile
+	}
+	// An expired Teleport session needs the user, not a restart loop
+	if teleport {
+		if err := sm.recordTeleportSession(sessionErr); err != nil {
+			return err
+		}
+	}
+	if hookErr != nil {
+		sm.status.Status = "Failed"
+		sm.setError(hookErr.Error())
+		sm.handleFailure()
+		return fmt.Errorf("failed to start %s: %w", sm.name, hookErr)
+	}
+
+	if useKubectl {
+		if checks.podErr != nil {
+			sm.status.Status = "Failed"
+			sm.setError(checks.podErr.Error())
+			sm.handleFailure()
+			return fmt.Errorf("failed to find a pod for %s: %w", sm.name, checks.podErr)
+		}
+		if kube.IsPodSelector() {
+			sm.recordPod(current, checks.pod)
+		}
+		if checks.preflightErr != nil {
+			sm.status.Status = "Failed"
+			sm.setError(checks.preflightErr.Error())
+			sm.handleFailure()
+			return fmt.Errorf("preflight check failed for %s: %w", sm.name, checks.preflightErr)
+		}
+		if kube.WaitForRollout {
+			if err := sm.recordWorkload(checks.ready, checks.reason, checks.workloadErr); err != nil {
+				return err
+			}
+		}
+		if forwardsUDP {
+			if err := sm.recordUDPPod(checks.udpReady, checks.udpReason, checks.udpErr); err != nil {
+				return err
+			}
+		}
+	}
+
 	// Resolve port conflicts
 	actualPort, err := sm.resolvePort()
 	if err != nil {
 		sm.status.Status = "Failed"
-		sm.status.LastError = err.Error()
+		sm.setError(err.Error())
 		return fmt.Errorf("port resolution failed for %s: %w", sm.name, err)
 	}
 	sm.status.LocalPort = actualPort
 
-	// Start kubectl port-forward
-	cmd, err := utils.StartKubectlPortForward(
-		sm.config.Namespace,
-		sm.config.Target,
-		actualPort,
-		sm.config.TargetPort,
-	)
+	// With a relay, kubectl listens on a private loopback port and the relay takes the user-facing one
+	forwardPort, bindAddress := actualPort, sm.config.BindAddress
+	if sm.needsRelay() || sm.forwardsUDP() {
+		if forwardPort, err = utils.FindFreeLoopbackPort(); err != nil {
+			sm.status.Status = "Failed"
+			sm.setError(err.Error())
+			return fmt.Errorf("failed to allocate relay port for %s: %w", sm.name, err)
+		}
+		bindAddress = ""
+	}
+
+	// Further ports ride on the same kubectl process
+	ports, err := sm.resolveExtraPorts(actualPort, forwardPort)
 	if err != nil {
 		sm.status.Status = "Failed"
-		sm.status.LastError = err.Error()
+		sm.setError(err.Error())
+		return fmt.Errorf("port resolution failed for %s: %w", sm.name, err)
+	}
+	sm.status.Ports = ports
+
+	// Start kubectl port-forward (or the ssh tunnel), or listen for the agent
+	var cmd *exec.Cmd
+	if sm.agentTunnel != nil {
+		// The agent tunnel listens on a single address
+		sm.agentForward, err = sm.agentTunnel.Listen(primaryAddress(bindAddress), forwardPort, agent.TargetAddress(sm.config), sm.logger)
+	} else {
+		cmd, err = sm.startForward(forwardPort, bindAddress)
+	}
+	if err != nil {
+		sm.status.Status = "Failed"
+		sm.setError(err.Error())
 		sm.handleFailure()
 		return fmt.Errorf("failed to start port-forward for %s: %w", sm.name, err)
 	}
 
+	if sm.needsRelay() || sm.forwardsUDP() {
+		startRelay := sm.startRelay
+		if sm.forwardsUDP() {
+			startRelay = sm.startUDPRelay
+		}
+		if err := startRelay(actualPort, forwardPort); err != nil {
+			if cmd != nil {
+				sm.killForward(cmd)
+				go cmd.Wait() // Reap the killed process
+			} else {
+				sm.agentForward.Close()
+				sm.agentForward = nil
+			}
+			sm.status.Status = "Failed"
+			sm.setError(err.Error())
+			sm.handleFailure()
+			return fmt.Errorf("failed to start relay for %s: %w", sm.name, err)
+		}
+	}
+
 	sm.cmd = cmd
-	sm.status.PID = cmd.Process.Pid
+	sm.latency.reset()
+	sm.status.PID = 0
+	if cmd != nil {
+		sm.status.PID = cmd.Process.Pid
+	}
 	sm.status.StartTime = time.Now()
 	sm.status.Status = "Running"
 	sm.status.LastError = ""
+	sm.status.ErrorCategory = ""
 	sm.status.InCooldown = false
+	sm.status.CooldownUntil = time.Time{}
+
+	if cmd != nil {
+		go sm.waitForExit(cmd)
+	} else {
+		go sm.waitForAgentForward(sm.agentForward)
+	}
+	sm.runHookAsync("postStart")
 
 	sm.logger.Info("Started port-forward for %s: %s:%d -> %d",
 		sm.name, sm.config.Target, sm.config.TargetPort, actualPort)
+	for _, port := range ports {
+		sm.logger.Info("Started port-forward for %s: %s:%d -> %d",
+			sm.name, sm.config.Target, port.TargetPort, port.LocalPort)
+	}
 
 	return nil
 }
 
 // Stop terminates the port-forward process
 func (sm *ServiceManager) Stop() error {
+	sm.startMutex.Lock()
+	defer sm.startMutex.Unlock()
+
+	// The hook runs without the mutex; a failure is logged and the forward stops regardless
+	sm.mutex.RLock()
+	running := (sm.cmd != nil && sm.cmd.Process != nil) || sm.agentForward != nil
+	sm.mutex.RUnlock()
+	if running {
+		sm.runHook("preStop")
+	}
+
 	sm.mutex.Lock()
 	defer sm.mutex.Unlock()
 
 	if sm.cmd != nil && sm.cmd.Process != nil {
-		if err := utils.KillProcess(sm.cmd.Process.Pid); err != nil {
-			sm.logger.Warn("Failed to kill process for %s: %v", sm.name, err)
-		}
+		sm.killForward(sm.cmd)
 		sm.cmd = nil
 	}
+	if sm.agentForward != nil {
+		sm.agentForward.Close()
+		sm.agentForward = nil
+	}
+
+	if sm.relay != nil {
+		if err := sm.relay.Stop(); err != nil {
+			sm.logger.Warn("Failed to stop relay for %s: %v", sm.name, err)
+		}
+		sm.relay = nil
+	}
+	if sm.udpRelay != nil {
+		if err := sm.udpRelay.Stop(); err != nil {
+			sm.logger.Warn("Failed to stop UDP relay for %s: %v", sm.name, err)
+		}
+		sm.udpRelay = nil
+	}
 
 	sm.status.Status = "Stopped"
+	if sm.paused {
+		sm.status.Status = StatusPaused
+	}
 	sm.status.PID = 0
 	sm.logger.Info("Stopped port-forward for %s", sm.name)
 
 	return nil
 }
 
+// waitForExit reaps the forward process and marks the service failed as soon as it
+// exits on its own. Exits shortly after starting count towards the backoff so a
+// crashing forward isn't restarted in a tight loop.
+func (sm *ServiceManager) waitForExit(cmd *exec.Cmd) {
+	err := cmd.Wait()
+
+	sm.mutex.Lock()
+	if sm.cmd != cmd {
+		sm.mutex.Unlock()
+		return // Stopped or restarted
+	}
+	sm.cmd = nil
+	if sm.plugin != nil && sm.plugin.Cmd() == cmd {
+		sm.plugin = nil
+	}
+	message := "process exited"
+	if err != nil {
+		message = fmt.Sprintf("process exited: %v", err)
+	}
+	if _, line := classifyError(sm.outputText()); line != "" {
+		message += ": " + line
+	}
+	sm.forwardExited(message)
+}
+
+// waitForAgentForward marks the service failed if its in-process forward stops
+// listening on its own
+func (sm *ServiceManager) waitForAgentForward(forward *agent.Forward) {
+	err := forward.Err()
+
+	sm.mutex.Lock()
+	if sm.agentForward != forward {
+		sm.mutex.Unlock()
+		return // Stopped or restarted
+	}
+	sm.agentForward = nil
+	sm.forwardExited(fmt.Sprintf("agent forward stopped: %v", err))
+}
+
+// forwardExited marks the service failed after its forward ended on its own and
+// wakes the monitor. The caller holds the mutex, which is released.
+func (sm *ServiceManager) forwardExited(message string) {
+	sm.status.Status = "Failed"
+	sm.status.PID = 0
+	sm.setError(message)
+	if time.Since(sm.status.StartTime) < stableRunDuration {
+		sm.handleFailure()
+	} else {
+		sm.resetFailureCount()
+	}
+	onExit := sm.onExit
+	sm.mutex.Unlock()
+
+	sm.logger.Warn("Port-forward for %s exited: %s", sm.name, message)
+	if onExit != nil {
+		onExit()
+	}
+}
+
 // Restart stops and starts the service
 func (sm *ServiceManager) Restart() error {
+	if sm.IsPaused() {
+		return nil
+	}
 	sm.logger.Info("Restarting service %s", sm.name)
 
 	if err := sm.Stop(); err != nil {
@@ -134,53 +487,643 @@ func (sm *ServiceManager) Restart() error {
 // IsHealthy checks if the service is running and responding
 func (sm *ServiceManager) IsHealthy() bool {
 	sm.mutex.RLock()
-	defer sm.mutex.RUnlock()
+	check := sm.healthCheck()
+	sm.mutex.RUnlock()
+	return check() == nil
+}
+
+// refreshHealth checks a running service once its startup grace period is over and marks
+// it failed when the check fails. The check runs without the mutex, as it waits on the
+// network; its outcome is dropped when the forward was restarted or stopped meanwhile.
+func (sm *ServiceManager) refreshHealth() {
+	sm.mutex.RLock()
+	if sm.status.Status != "Running" || time.Since(sm.status.StartTime) <= startupGracePeriod {
+		sm.mutex.RUnlock()
+		return
+	}
+	startTime := sm.status.StartTime
+	check, checkPorts := sm.healthCheck(), sm.portsCheck()
+	sm.mutex.RUnlock()
+
+	started := time.Now()
+	err := check()
+	elapsed := time.Since(started)
+	portErrs := checkPorts()
 
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+	if sm.status.Status != "Running" || !sm.status.StartTime.Equal(startTime) {
+		return
+	}
+	sm.recordPortsHealth(portErrs)
+	if err != nil {
+		sm.status.Status = "Failed"
+		// kubectl's own output tells more than a failed local dial
+		category, line := classifyError(sm.outputText())
+		if line != "" {
+			sm.setError("Health check failed: " + line)
+		} else {
+			sm.setError(fmt.Sprintf("Health check failed: %v", err))
+		}
+		sm.status.ErrorCategory = category
+		return
+	}
+	sm.status.HealthCheckTime = elapsed
+	sm.latency.add(elapsed)
+}
+
+// healthCheck returns a check of the process and the service's health checker as the
+// forward is now; the caller holds the mutex, which the check doesn't need
+func (sm *ServiceManager) healthCheck() func() error {
 	// Check if process is running
-	if sm.cmd == nil || sm.cmd.Process == nil {
-		return false
+	if sm.agentForward != nil {
+		select {
+		case <-sm.agentForward.Done():
+			return func() error { return fmt.Errorf("agent forward stopped") }
+		default:
+		}
+	} else if sm.cmd == nil || sm.cmd.Process == nil || !utils.IsProcessRunning(sm.cmd.Process.Pid) {
+		return func() error { return fmt.Errorf("process exited") }
 	}
 
-	if !utils.IsProcessRunning(sm.cmd.Process.Pid) {
-		return false
+	// Probe kubectl directly; the relay accepts connections even when the forward is down
+	host, port := healthCheckHost(sm.config.BindAddress), sm.status.LocalPort
+	if sm.relay != nil || sm.udpRelay != nil {
+		host, port = "127.0.0.1", sm.backendPort
 	}
 
-	// Check port connectivity
-	return utils.CheckPortConnectivity(sm.status.LocalPort)
+	parent, timeout := sm.ctx, healthTimeout(sm.config)
+	p, agentForward, checker, configured := sm.plugin, sm.agentForward, sm.health, sm.config.HealthCheck != nil
+	return func() error {
+		ctx, cancel := context.WithTimeout(parent, timeout)
+		defer cancel()
+		switch {
+		case p != nil && !configured:
+			return p.Health(ctx)
+		case agentForward != nil && !configured:
+			return agentForward.Probe(ctx) // The local listener accepts even when the target is down
+		default:
+			return checker.Check(ctx, host, port)
+		}
+	}
 }
 
-// GetStatus returns the current status of the service
-func (sm *ServiceManager) GetStatus() config.ServiceStatus {
+// killForward stops the forward process; a plugin is first asked to tear down its tunnel
+func (sm *ServiceManager) killForward(cmd *exec.Cmd) {
+	if sm.plugin != nil && sm.plugin.Cmd() == cmd {
+		sm.plugin.Stop()
+		sm.plugin = nil
+		return
+	}
+	if err := utils.KillProcess(cmd.Process.Pid); err != nil {
+		sm.logger.Warn("Failed to kill process for %s: %v", sm.name, err)
+	}
+}
+
+// startForward starts the process that listens on localPort and forwards to the target
+func (sm *ServiceManager) startForward(localPort int, bindAddress string) (*exec.Cmd, error) {
+	if name := sm.config.PluginName(); name != "" {
+		return sm.startPlugin(name, localPort, bindAddress)
+	}
+
+	switch sm.config.Type {
+	case "ssh":
+		if sm.config.SSH == nil {
+			return nil, fmt.Errorf("ssh service %s has no ssh bastion configured", sm.name)
+		}
+		identityFile := sm.config.SSH.IdentityFile
+		if identityFile != "" {
+			expanded, err := utils.ExpandPath(identityFile)
+			if err != nil {
+				return nil, err
+			}
+			identityFile = expanded
+		}
+		return utils.StartSSHTunnel(utils.SSHTunnel{
+			Host:         sm.config.SSH.Host,
+			Port:         sm.config.SSH.Port,
+			User:         sm.config.SSH.User,
+			IdentityFile: identityFile,
+			JumpHosts:    sm.config.SSH.Jump,
+			Options:      sm.config.SSH.Options,
+			BindAddress:  bindAddress,
+			LocalPort:    localPort,
+			RemoteHost:   sm.config.Target,
+			RemotePort:   sm.config.TargetPort,
+			Env:          sm.config.Environ(),
+		})
+
+	case "teleport":
+		if sm.config.Teleport == nil {
+			return nil, fmt.Errorf("teleport service %s has no teleport tunnel configured", sm.name)
+		}
+		return utils.StartTeleportTunnel(utils.TeleportTunnel{
+			Kind:        sm.config.Teleport.Kind,
+			Proxy:       sm.config.Teleport.Proxy,
+			Cluster:     sm.config.Teleport.Cluster,
+			Target:      sm.config.Target,
+			Node:        sm.config.Teleport.Node,
+			DBUser:      sm.config.Teleport.DBUser,
+			DBName:      sm.config.Teleport.DBName,
+			BindAddress: bindAddress,
+			LocalPort:   localPort,
+			TargetPort:  sm.config.TargetPort,
+			Env:         sm.config.Environ(),
+		})
+
+	case "cloudsql":
+		proxy := utils.CloudSQLProxy{
+			Instance:    sm.config.Target,
+			BindAddress: bindAddress,
+			LocalPort:   localPort,
+			Env:         sm.config.Environ(),
+		}
+		if options := sm.config.CloudSQL; options != nil {
+			credentialsFile, err := utils.ExpandPath(options.CredentialsFile)
+			if err != nil {
+				return nil, err
+			}
+			proxy.Binary = options.Binary
+			proxy.PrivateIP = options.PrivateIP
+			proxy.AutoIAMAuthn = options.AutoIAMAuthn
+			proxy.CredentialsFile = credentialsFile
+			proxy.ImpersonateServiceAccount = options.ImpersonateServiceAccount
+		}
+		return utils.StartCloudSQLProxy(proxy)
+	}
+
+	kube := sm.kubeService()
+	if sm.forwardsUDP() {
+		kube.Target, kube.TargetPort = "pod/"+udpPodName(sm.name), udpTunnelPort
+	}
+	tail := &outputTail{}
+	sm.output = tail
+	pairs := sm.portPairs()
+	mapping := fmt.Sprintf("%d:%d", localPort, kube.TargetPort)
+	for _, pair := range pairs {
+		mapping += fmt.Sprintf(" %d:%d", pair.Local, pair.Target)
+	}
+	sm.outputLog.mark(fmt.Sprintf("--- kubectl port-forward %s %s", kube.Target, mapping))
+	watcher := &outputWatcher{report: func(_ config.ErrorCategory, line string) {
+		sm.outputFailure(tail, line)
+	}}
+	return utils.StartKubectlPortForward(utils.KubectlPortForward{
+		Kubectl:     sm.config.KubectlPath,
+		Namespace:   sm.config.Namespace,
+		Target:      kube.Target,
+		LocalPort:   localPort,
+		TargetPort:  kube.TargetPort,
+		BindAddress: bindAddress,
+		Kubeconfig:  sm.config.KubeconfigPath(),
+		Context:     kube.Context,
+		Env:         sm.config.Environ(),
+		Output:      io.MultiWriter(tail, &sm.outputLog, watcher),
+		Ports:       pairs,
+	})
+}
+
+// outputFailure records a failure kubectl logged while the forward keeps running, so the
+// service shows the actual cause; whether it failed is left to the health check. A
+// failure forwarding one of the further ports is recorded on that port. Output of a
+// process that was since replaced, or a failure already shown, is ignored.
+func (sm *ServiceManager) outputFailure(tail *outputTail, line string) {
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+
+	if sm.output != tail || sm.status.Status != "Running" || sm.portFailure(line) || sm.status.LastError == line {
+		return
+	}
+	sm.logger.Warn("kubectl port-forward for %s reported: %s", sm.name, line)
+	sm.setError(line)
+}
+
+// startPlugin runs the service's plugin and waits until it reports the tunnel ready
+func (sm *ServiceManager) startPlugin(name string, localPort int, bindAddress string) (*exec.Cmd, error) {
+	dir, err := plugin.Dir()
+	if err != nil {
+		return nil, err
+	}
+	path, err := plugin.Find(dir, name)
+	if err != nil {
+		return nil, err
+	}
+
+	sm.output = &outputTail{}
+	sm.outputLog.mark(fmt.Sprintf("--- plugin %s %s", name, sm.config.Target))
+	p, err := plugin.Start(path, plugin.StartRequest{
+		Service:     sm.name,
+		Target:      sm.config.Target,
+		TargetPort:  sm.config.TargetPort,
+		LocalPort:   localPort,
+		BindAddress: bindAddress,
+		Namespace:   sm.config.Namespace,
+		Context:     sm.config.Context,
+		Options:     sm.config.PluginOptions,
+	}, sm.config.Environ(), io.MultiWriter(sm.output, &sm.outputLog), sm.logger)
+	if err != nil {
+		return nil, err
+	}
+	sm.plugin = p
+	return p.Cmd(), nil
+}
+
+// recordTeleportSession stores the outcome of utils.CheckTeleportSession, marking the
+// service as waiting for login when tsh has no valid session; the caller holds the mutex
+func (sm *ServiceManager) recordTeleportSession(err error) error {
+	sm.loginCheckedAt = time.Now()
+
+	if errors.Is(err, utils.ErrTeleportLoginRequired) {
+		if sm.status.Status != "Login" {
+			sm.logger.Warn("Teleport session for %s expired; run '%s'", sm.name, utils.TeleportLoginCommand(sm.config.Teleport.Proxy))
+			sm.setError(fmt.Sprintf("Teleport login required: run '%s'", utils.TeleportLoginCommand(sm.config.Teleport.Proxy)))
+		}
+		sm.status.Status = "Login"
+		return fmt.Errorf("service %s: %w", sm.name, err)
+	}
+	if err != nil {
+		sm.status.Status = "Failed"
+		sm.setError(err.Error())
+		sm.handleFailure()
+		return fmt.Errorf("failed to check teleport session for %s: %w", sm.name, err)
+	}
+	return nil
+}
+
+// kubectlChecks is the outcome of the kubectl calls made before a forward starts
+type kubectlChecks struct {
+	pod          string // Picked for a pod-selector service
+	podErr       error
+	preflightErr error
+	ready        bool // Whether the workload can take traffic, with waitForRollout
+	reason       string
+	workloadErr  error
+	udpReady     bool // Whether the socat pod of a UDP service runs
+	udpReason    string
+	udpErr       error
+}
+
+// runKubectlChecks picks the pod of a pod-selector service, catches a wrong namespace,
+// target or missing RBAC before kubectl fails with a generic error, and checks the
+// workload of a service waiting for rollouts: a rollout in progress is worth waiting
+// for, not worth burning restarts on. kubectl only forwards TCP, so for a UDP service
+// it then starts the socat pod that turns it back into UDP. The caller doesn't hold the
+// mutex.
+func (sm *ServiceManager) runKubectlChecks(kube config.Service, current string, run kubectlRunner, forwardsUDP bool) kubectlChecks {
+	var checks kubectlChecks
+	if kube.IsPodSelector() {
+		// A pod picked by selector is replaced by another ready one once deleted
+		if checks.pod, checks.podErr = sm.selectPod(kube, current, run); checks.podErr != nil {
+			return checks
+		}
+		kube.Target = "pod/" + checks.pod
+	}
+	if checks.preflightErr = preflightTarget(kube, run); checks.preflightErr != nil {
+		return checks
+	}
+	if kube.WaitForRollout {
+		checks.ready, checks.reason, checks.workloadErr = workloadReady(kube, run)
+		if checks.workloadErr == nil && !checks.ready {
+			return checks
+		}
+	}
+	if forwardsUDP {
+		checks.udpReady, checks.udpReason, checks.udpErr = prepareUDPPod(sm.name, kube, run)
+	}
+	return checks
+}
+
+// recordWorkload stores the outcome of workloadReady, marking the service as waiting
+// while its workload isn't ready; the caller holds the mutex
+func (sm *ServiceManager) recordWorkload(ready bool, reason string, err error) error {
+	sm.workloadCheckedAt = time.Now()
+
+	if err != nil {
+		sm.logger.Warn("Couldn't check whether the workload of %s is ready, starting anyway: %v", sm.name, err)
+		return nil
+	}
+	if !ready {
+		if sm.status.Status != StatusWaitingForWorkload {
+			sm.logger.Info("Waiting for the workload of %s: %s", sm.name, reason)
+		}
+		sm.status.Status = StatusWaitingForWorkload
+		sm.status.LastError = reason // Not a failure, so it stays out of the error history
+		return fmt.Errorf("service %s is waiting for its workload: %s", sm.name, reason)
+	}
+	return nil
+}
+
+// WorkloadCheckDue reports whether a service waiting for its workload should check it again
+func (sm *ServiceManager) WorkloadCheckDue() bool {
 	sm.mutex.RLock()
 	defer sm.mutex.RUnlock()
+	return sm.status.Status == StatusWaitingForWorkload && !sm.starting && time.Since(sm.workloadCheckedAt) >= workloadCheckInterval
+}
 
-	// Update status based on health check, but allow grace period for startup
-	if sm.status.Status == "Running" {
-		// Give service 5 seconds grace period after startup before health checking
-		gracePeriod := 5 * time.Second
-		if time.Since(sm.status.StartTime) > gracePeriod && !sm.IsHealthy() {
-			sm.status.Status = "Failed"
-			sm.status.LastError = "Health check failed"
+// LoginCheckDue reports whether a service waiting for login should check the session again
+func (sm *ServiceManager) LoginCheckDue() bool {
+	sm.mutex.RLock()
+	defer sm.mutex.RUnlock()
+	return sm.status.Status == "Login" && !sm.starting && time.Since(sm.loginCheckedAt) >= loginCheckInterval
+}
+
+// SetClusterError marks the service's API server as unreachable (err != nil) or reachable again
+func (sm *ServiceManager) SetClusterError(err error) {
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+	if err != nil {
+		message := "API server unreachable: " + err.Error()
+		if message != sm.clusterError {
+			sm.errorHistory.add(message)
+		}
+		sm.clusterError = message
+	} else {
+		sm.clusterError = ""
+	}
+}
+
+// SetKubeContext records the kubeconfig context and cluster the forward runs in
+func (sm *ServiceManager) SetKubeContext(context, cluster string) {
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+	sm.status.Context = context
+	sm.status.Cluster = cluster
+}
+
+// PinContext keeps a service that follows the current context in context from its next
+// start, e.g. after a context change was kept; "" follows the current context again
+func (sm *ServiceManager) PinContext(context string) {
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+	sm.pinnedContext = context
+}
+
+// kubeService returns the configuration kubectl runs with, pinned to a context when the
+// service follows the current one and a context change was kept, and targeting the pod
+// picked by selector; the caller holds the mutex
+func (sm *ServiceManager) kubeService() config.Service {
+	service := sm.config
+	if sm.pinnedContext != "" && service.FollowsCurrentContext() {
+		service.Context = sm.pinnedContext
+	}
+	if sm.resolvedPod != "" {
+		service.Target = "pod/" + sm.resolvedPod
+	}
+	return service
+}
+
+// SetCapture enables traffic capture for the service from its next start
+func (sm *ServiceManager) SetCapture(captureConfig config.CaptureConfig) {
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+	sm.capture = captureConfig
+}
+
+// needsRelay reports whether the forward is fronted by a relay; UDP services have their own
+func (sm *ServiceManager) needsRelay() bool {
+	return !sm.forwardsUDP() && (relay.Needed(sm.config) || relay.Captures(sm.config, sm.capture))
+}
+
+// startRelay starts the relay on the user-facing port in front of kubectl's backend port
+func (sm *ServiceManager) startRelay(localPort, backendPort int) error {
+	// The relay listens on a single address
+	host := primaryAddress(sm.config.BindAddress)
+	if host == "" {
+		host = "localhost"
+	}
+
+	r, err := relay.ForService(sm.name, sm.config, sm.capture,
+		net.JoinHostPort(host, strconv.Itoa(localPort)),
+		net.JoinHostPort("127.0.0.1", strconv.Itoa(backendPort)),
+		sm.logger)
+	if err != nil {
+		return err
+	}
+	if sm.config.AccessLog {
+		r.SetAccessLog(sm.recordAccess)
+	}
+	if sm.config.IdleTimeout > 0 {
+		r.SetWake(sm.resumeForward)
+	}
+	if err := r.Start(); err != nil {
+		return err
+	}
+
+	sm.relay = r
+	sm.backendPort = backendPort
+	return nil
+}
+
+// SuspendIfIdle stops the forward process of a service with idleTimeout once its relay
+// has seen no traffic for that long; the relay keeps listening and resumes it
+func (sm *ServiceManager) SuspendIfIdle() {
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+
+	if sm.config.IdleTimeout <= 0 || sm.status.Status != "Running" || sm.relay == nil || sm.cmd == nil {
+		return
+	}
+	idle := sm.relay.IdleFor()
+	if idle < sm.config.IdleTimeout {
+		return
+	}
+
+	cmd := sm.cmd
+	sm.cmd = nil // waitForExit ignores the exit
+	sm.killForward(cmd)
+	sm.status.Status = StatusSuspended
+	sm.status.PID = 0
+	sm.logger.Info("Suspended %s after %s without traffic", sm.name, idle.Round(time.Second))
+}
+
+// resumeForward restarts a suspended forward for a new connection and waits until it
+// accepts connections. It is a no-op while the forward is running.
+func (sm *ServiceManager) resumeForward() error {
+	sm.wakeMutex.Lock()
+	defer sm.wakeMutex.Unlock()
+
+	sm.mutex.Lock()
+	if sm.status.Status != StatusSuspended {
+		sm.mutex.Unlock()
+		return nil
+	}
+	cmd, err := sm.startForward(sm.backendPort, "")
+	if err != nil {
+		sm.status.Status = "Failed"
+		sm.setError(err.Error())
+		sm.handleFailure()
+		sm.mutex.Unlock()
+		return err
+	}
+	sm.cmd = cmd
+	sm.status.PID = cmd.Process.Pid
+	sm.status.StartTime = time.Now()
+	sm.status.Status = "Running"
+	backendPort := sm.backendPort
+	sm.mutex.Unlock()
+
+	go sm.waitForExit(cmd)
+	sm.logger.Info("Resumed %s for a new connection", sm.name)
+
+	deadline := time.Now().Add(resumeTimeout)
+	for !utils.CheckHostConnectivity("127.0.0.1", backendPort) {
+		if time.Now().After(deadline) {
+			return fmt.Errorf("port-forward didn't come up within %s", resumeTimeout)
+		}
+		select {
+		case <-sm.ctx.Done():
+			return sm.ctx.Err()
+		case <-time.After(100 * time.Millisecond):
 		}
 	}
+	return nil
+}
 
-	return *sm.status
+// recordAccess adds a relay access log entry to the service log
+func (sm *ServiceManager) recordAccess(entry config.AccessLogEntry) {
+	sm.accessLog.add(entry)
+	sm.logger.Info("[%s] %s", sm.name, entry)
+}
+
+// primaryAddress returns the first of a comma-separated list of bind addresses
+func primaryAddress(bindAddress string) string {
+	if addresses := utils.SplitAddresses(bindAddress); len(addresses) > 0 {
+		return addresses[0]
+	}
+	return ""
+}
+
+// healthCheckHost returns the host to probe for a forward bound to bindAddress, which
+// may list several addresses; the first is probed
+func healthCheckHost(bindAddress string) string {
+	address := primaryAddress(bindAddress)
+	ip := net.ParseIP(address)
+	if ip == nil || ip.IsUnspecified() || ip.IsLoopback() {
+		return "localhost"
+	}
+	return address
+}
+
+// GetStatus returns the current status of the service
+func (sm *ServiceManager) GetStatus() config.ServiceStatus {
+	// Snapshots are taken one at a time, so a later one never holds older state
+	sm.snapshot.mutex.Lock()
+	defer sm.snapshot.mutex.Unlock()
+
+	sm.mutex.RLock()
+	status := *sm.status
+	if status.InCooldown && !time.Now().Before(status.CooldownUntil) {
+		status.InCooldown = false // Due for a retry
+	}
+	if sm.clusterError != "" && !sm.paused {
+		status.Status = StatusClusterUnreachable
+		status.LastError = sm.clusterError
+		status.ErrorCategory = config.ErrorClusterUnreachable
+	}
+	status.Latency, status.LatencyP50, status.LatencyP95 = sm.latency.stats()
+	status.RecentAccess = sm.accessLog.entries()
+	status.RecentErrors = sm.errorHistory.entries()
+	status.Ports = append([]config.PortStatus(nil), sm.status.Ports...)
+	sm.mutex.RUnlock()
+
+	return sm.snapshot.publish(status)
+}
+
+// setError sets the service's last error and its category and records it in the error
+// history; for a failed service it runs the onFailure hook
+func (sm *ServiceManager) setError(message string) {
+	sm.status.LastError = message
+	sm.status.ErrorCategory, _ = classifyError(message)
+	sm.errorHistory.add(message)
+	if sm.status.Status == "Failed" {
+		sm.runHookAsync("onFailure")
+	}
+}
+
+// outputText returns the latest output of the kubectl process, if any
+func (sm *ServiceManager) outputText() string {
+	if sm.output == nil {
+		return ""
+	}
+	return sm.output.String()
+}
+
+// DetectType infers the type of a running service whose configuration has none by
+// probing the forward in the background. It probes again on later calls until
+// the forward accepts connections.
+func (sm *ServiceManager) DetectType() {
+	sm.mutex.Lock()
+	if sm.config.Type != "" || sm.forwardsUDP() || sm.typeDetected || sm.detectingType || sm.status.Status != "Running" {
+		sm.mutex.Unlock()
+		return
+	}
+	sm.detectingType = true
+	host, port := healthCheckHost(sm.config.BindAddress), sm.status.LocalPort
+	if sm.relay != nil {
+		host, port = "127.0.0.1", sm.backendPort
+	}
+	sm.mutex.Unlock()
+
+	go func() {
+		serviceType, err := detectServiceType(host, port)
+
+		sm.mutex.Lock()
+		defer sm.mutex.Unlock()
+		sm.detectingType = false
+		if err != nil {
+			sm.logger.Debug("Type detection for %s failed: %v", sm.name, err)
+			return
+		}
+		sm.typeDetected = true
+		if serviceType == "" {
+			sm.logger.Info("Couldn't detect the type of %s: neither HTTP nor gRPC", sm.name)
+			return
+		}
+		sm.detectedType = serviceType
+		sm.status.Type = serviceType
+		sm.status.TypeDetected = true
+		sm.logger.Info("Detected type %s for %s", serviceType, sm.name)
+	}()
+}
+
+// Config returns the service's configuration, with its detected type when it has none
+func (sm *ServiceManager) Config() config.Service {
+	sm.mutex.RLock()
+	defer sm.mutex.RUnlock()
+	service := sm.config
+	service.Type = sm.serviceType()
+	return service
+}
+
+// serviceType returns the configured type, or the detected one; the caller holds the mutex
+func (sm *ServiceManager) serviceType() string {
+	if sm.config.Type == "" {
+		return sm.detectedType
+	}
+	return sm.config.Type
 }
 
 // Shutdown gracefully shuts down the service manager
 func (sm *ServiceManager) Shutdown() {
 	sm.cancel()
 	sm.Stop()
+	sm.deleteUDPPod()
 }
 
 // resolvePort finds an available port, starting from the configured port
 func (sm *ServiceManager) resolvePort() (int, error) {
-	if utils.IsPortAvailable(sm.config.LocalPort) {
+	if sm.config.LocalPort == 0 {
+		return sm.assignPort()
+	}
+	if sm.portAvailable(sm.config.LocalPort) {
 		return sm.config.LocalPort, nil
 	}
 
 	// Port is in use, find an alternative
-	newPort, err := utils.FindAvailablePort(sm.config.LocalPort + 1)
+	findAvailable := utils.FindAvailablePort
+	if sm.forwardsUDP() {
+		findAvailable = utils.FindAvailableUDPPort
+	}
+	newPort, err := findAvailable(sm.config.LocalPort + 1)
 	if err != nil {
 		return 0, err
 	}
@@ -191,33 +1134,97 @@ func (sm *ServiceManager) resolvePort() (int, error) {
 	return newPort, nil
 }
 
+// assignPort picks a free port for a service configured with localPort 0, keeping the
+// port of the previous start while it is free so restarts don't move the service
+func (sm *ServiceManager) assignPort() (int, error) {
+	if port := sm.status.LocalPort; port != 0 && sm.portAvailable(port) {
+		return port, nil
+	}
+	findFree := utils.FindFreePort
+	if sm.forwardsUDP() {
+		findFree = utils.FindFreeUDPPort
+	}
+	port, err := findFree()
+	if err != nil {
+		return 0, err
+	}
+	sm.logger.Info("Assigned free port %d to %s", port, sm.name)
+	return port, nil
+}
+
+// portAvailable checks a local port for the protocol the service listens with
+func (sm *ServiceManager) portAvailable(port int) bool {
+	if sm.forwardsUDP() {
+		return utils.IsUDPPortAvailable(port)
+	}
+	return utils.IsPortAvailable(port)
+}
+
 // handleFailure implements exponential backoff for failed services
 func (sm *ServiceManager) handleFailure() {
 	sm.failureCount++
 
+	// Pinned services skip the backoff and retry after a short fixed delay
+	if sm.config.Pinned {
+		sm.cooldownUntil = time.Now().Add(pinnedRetryDelay)
+		sm.status.InCooldown = true
+		sm.status.CooldownUntil = sm.cooldownUntil
+		if sm.failureCount == 3 {
+			sm.logger.Warn("Pinned service %s failed %d times, retrying every %v", sm.name, sm.failureCount, pinnedRetryDelay)
+		}
+		return
+	}
+
 	// Don't set cooldown for the first few failures
 	if sm.failureCount < 3 {
 		return
 	}
 
-	// Calculate backoff index (capped at max)
+	// Calculate backoff index (capped at max, lower for critical and high priority services)
 	backoffIndex := sm.failureCount - 3
-	if backoffIndex >= len(sm.backoffSeconds) {
-		backoffIndex = len(sm.backoffSeconds) - 1
+	if maxIndex := sm.maxBackoffIndex(); backoffIndex > maxIndex {
+		backoffIndex = maxIndex
 	}
 
 	cooldownDuration := time.Duration(sm.backoffSeconds[backoffIndex]) * time.Second
 	sm.cooldownUntil = time.Now().Add(cooldownDuration)
+	sm.status.InCooldown = true
+	sm.status.CooldownUntil = sm.cooldownUntil
 
 	sm.logger.Warn("Service %s failed %d times, entering cooldown for %v",
 		sm.name, sm.failureCount, cooldownDuration)
 }
 
+// maxBackoffIndex caps cooldowns so critical services retry within 10s and high priority ones within 20s
+func (sm *ServiceManager) maxBackoffIndex() int {
+	maxIndex := len(sm.backoffSeconds) - 1
+	switch sm.config.Priority {
+	case "critical":
+		maxIndex = 1
+	case "high":
+		maxIndex = 2
+	}
+	if maxIndex >= len(sm.backoffSeconds) {
+		maxIndex = len(sm.backoffSeconds) - 1
+	}
+	return maxIndex
+}
+
 // isInCooldown checks if the service is currently in cooldown
 func (sm *ServiceManager) isInCooldown() bool {
 	return time.Now().Before(sm.cooldownUntil)
 }
 
+// resetBackoff clears failures and cooldown so the next start happens immediately
+func (sm *ServiceManager) resetBackoff() {
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+	sm.failureCount = 0
+	sm.cooldownUntil = time.Time{}
+	sm.status.InCooldown = false
+	sm.status.CooldownUntil = time.Time{}
+}
+
 // resetFailureCount resets the failure count when service recovers
 func (sm *ServiceManager) resetFailureCount() {
 	if sm.failureCount > 0 {
@@ -0,0 +1,125 @@
+package portforward
+
+import (
+	"os/exec"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/relay"
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+func TestWaitForExitMarksServiceFailed(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+
+	sm := NewServiceManager("api", config.Service{LocalPort: 8080}, utils.NewLogger(utils.LevelError))
+	exited := make(chan struct{})
+	sm.onExit = func() { close(exited) }
+
+	cmd := exec.Command("sh", "-c", "exit 3")
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("Failed to start command: %v", err)
+	}
+	sm.mutex.Lock()
+	sm.cmd = cmd
+	sm.status.Status = "Running"
+	sm.status.PID = cmd.Process.Pid
+	sm.status.StartTime = time.Now()
+	sm.mutex.Unlock()
+
+	go sm.waitForExit(cmd)
+	select {
+	case <-exited:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the exit to be detected")
+	}
+
+	status := sm.GetStatus()
+	if status.Status != "Failed" || status.PID != 0 || !strings.Contains(status.LastError, "exit status 3") {
+		t.Errorf("Expected a failed status with the exit error, got %+v", status)
+	}
+	if sm.failureCount != 1 {
+		t.Errorf("Expected a quick exit to count as a failure, got %d", sm.failureCount)
+	}
+}
+
+func TestWaitForExitIgnoresStoppedProcess(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+
+	sm := NewServiceManager("api", config.Service{LocalPort: 8080}, utils.NewLogger(utils.LevelError))
+	sm.onExit = func() { t.Error("Expected no exit callback for a stopped service") }
+
+	cmd := exec.Command("sh", "-c", "exit 0")
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("Failed to start command: %v", err)
+	}
+	sm.cmd = cmd
+	sm.Stop()
+
+	sm.waitForExit(cmd)
+	if status := sm.GetStatus().Status; status != "Stopped" {
+		t.Errorf("Expected the service to stay stopped, got %s", status)
+	}
+}
+
+func TestSuspendIfIdleStopsForwardButKeepsRelay(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sleep")
+	}
+
+	sm := NewServiceManager("api", config.Service{LocalPort: 8080, IdleTimeout: time.Millisecond}, utils.NewLogger(utils.LevelError))
+	sm.onExit = func() { t.Error("Expected no exit callback for a suspended forward") }
+
+	cmd := exec.Command("sleep", "60")
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("Failed to start command: %v", err)
+	}
+	sm.mutex.Lock()
+	sm.cmd = cmd
+	sm.status.Status = "Running"
+	sm.status.PID = cmd.Process.Pid
+	sm.relay = relay.New(relay.Options{Name: "api", ListenAddr: "127.0.0.1:0", Logger: sm.logger})
+	sm.mutex.Unlock()
+	if err := sm.relay.Start(); err != nil {
+		t.Fatalf("Failed to start relay: %v", err)
+	}
+	defer sm.relay.Stop()
+	go sm.waitForExit(cmd)
+
+	time.Sleep(5 * time.Millisecond)
+	sm.SuspendIfIdle()
+
+	status := sm.GetStatus()
+	if status.Status != StatusSuspended || status.PID != 0 {
+		t.Errorf("Expected the idle forward to be suspended, got %+v", status)
+	}
+	if sm.relay == nil {
+		t.Error("Expected the relay to keep listening while suspended")
+	}
+	time.Sleep(50 * time.Millisecond) // Let waitForExit observe the kill
+}
+
+func TestResolvePortAssignsFreePort(t *testing.T) {
+	sm := NewServiceManager("api", config.Service{LocalPort: 0}, utils.NewLogger(utils.LevelError))
+
+	port, err := sm.resolvePort()
+	if err != nil {
+		t.Fatalf("resolvePort failed: %v", err)
+	}
+	if port == 0 || !utils.IsPortAvailable(port) {
+		t.Fatalf("Expected a free port, got %d", port)
+	}
+
+	// A restart keeps the assigned port while it is free
+	sm.status.LocalPort = port
+	if again, err := sm.resolvePort(); err != nil || again != port {
+		t.Errorf("Expected port %d to be kept, got %d (%v)", port, again, err)
+	}
+}
@@ -0,0 +1,33 @@
+package portforward
+
+import (
+	"reflect"
+	"sync"
+	"sync/atomic"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+)
+
+// statusGenerations numbers status snapshots across all services, so the snapshots of
+// a service manager that replaced another are newer than those of its predecessor
+var statusGenerations atomic.Uint64
+
+// statusSnapshot is the latest status a service manager handed out. Snapshots are
+// immutable: a status that changed gets a new generation, an unchanged one keeps its
+// own, so consumers can tell a newer snapshot from a stale one and see what they missed.
+type statusSnapshot struct {
+	mutex  sync.Mutex
+	latest config.ServiceStatus
+}
+
+// publish numbers a status taken by the caller, who holds the mutex, and keeps it as
+// the latest
+func (s *statusSnapshot) publish(status config.ServiceStatus) config.ServiceStatus {
+	status.Generation = s.latest.Generation
+	if status.Generation != 0 && reflect.DeepEqual(status, s.latest) {
+		return s.latest
+	}
+	status.Generation = statusGenerations.Add(1)
+	s.latest = status
+	return status
+}
@@ -0,0 +1,71 @@
+package portforward
+
+import (
+	"testing"
+	"time"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+func TestGetStatusGenerations(t *testing.T) {
+	sm := NewServiceManager("api", config.Service{LocalPort: 8080}, utils.NewLogger(utils.LevelError))
+
+	first := sm.GetStatus()
+	if first.Generation == 0 {
+		t.Fatal("Expected the first snapshot to be numbered")
+	}
+	if again := sm.GetStatus(); again.Generation != first.Generation {
+		t.Errorf("Expected an unchanged status to keep generation %d, got %d", first.Generation, again.Generation)
+	}
+
+	sm.mutex.Lock()
+	sm.status.RestartCount++
+	sm.mutex.Unlock()
+	changed := sm.GetStatus()
+	if changed.Generation <= first.Generation {
+		t.Errorf("Expected a changed status to get a newer generation than %d, got %d", first.Generation, changed.Generation)
+	}
+
+	// A replacement service manager continues after its predecessor
+	replacement := NewServiceManager("api", config.Service{LocalPort: 8080}, utils.NewLogger(utils.LevelError))
+	if status := replacement.GetStatus(); status.Generation <= changed.Generation {
+		t.Errorf("Expected the replacement's snapshots to be newer, got %d after %d", status.Generation, changed.Generation)
+	}
+}
+
+func TestRefreshHealthSkipsGracePeriod(t *testing.T) {
+	sm := NewServiceManager("api", config.Service{LocalPort: 8080}, utils.NewLogger(utils.LevelError))
+	sm.status.Status = "Running"
+	sm.status.StartTime = time.Now()
+
+	// No process is running, but the forward is still within its startup grace period
+	sm.refreshHealth()
+	if status := sm.GetStatus().Status; status != "Running" {
+		t.Fatalf("Expected no health check during the grace period, got %s", status)
+	}
+
+	sm.status.StartTime = time.Now().Add(-2 * startupGracePeriod)
+	sm.refreshHealth()
+	status := sm.GetStatus()
+	if status.Status != "Failed" || status.LastError != "Health check failed: process exited" {
+		t.Errorf("Expected the failed check to mark the service failed, got %s (%s)", status.Status, status.LastError)
+	}
+}
+
+func TestPublishSkipsStaleStatuses(t *testing.T) {
+	var s subscribers
+	updates, cancel := s.add()
+	defer cancel()
+
+	s.publish(Snapshot{"api": {Status: "Running", Generation: 5}}, false)
+	<-updates
+	s.publish(Snapshot{"api": {Status: "Starting", Generation: 3}}, false)
+	if len(updates) != 0 {
+		t.Errorf("Expected an older snapshot to be skipped, got %+v", <-updates)
+	}
+	s.publish(Snapshot{"api": {Status: "Failed", Generation: 7}}, false)
+	if update := <-updates; update.Changed["api"].Status != "Failed" {
+		t.Errorf("Expected the newer snapshot, got %+v", update)
+	}
+}
@@ -0,0 +1,208 @@
+package portforward
+
+import (
+	"reflect"
+	"sync"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+// subscriberBuffer is how many updates a subscriber can fall behind before its backlog
+// is replaced with the whole state
+const subscriberBuffer = 4
+
+// Snapshot is the status of all services at one point in time; it is shared between
+// listeners and must not be modified
+type Snapshot = map[string]config.ServiceStatus
+
+// subscribers distributes status changes to independent consumers as deltas: each
+// update only holds the services that changed. A slow consumer only loses its own
+// backlog, which is replaced with a full update, and never delays the others.
+type subscribers struct {
+	mutex   sync.Mutex
+	next    int
+	chans   map[int]chan config.StatusUpdate
+	closed  bool
+	current Snapshot // Status as of the latest update; owned by subscribers
+	version uint64
+	drops   int64 // Updates a lagging subscriber lost to a full update
+}
+
+// Subscribe returns a channel receiving status updates, starting with the current
+// status in full, and a function that ends the subscription. The channel is closed
+// on cancel or when the manager stops.
+func (m *Manager) Subscribe() (<-chan config.StatusUpdate, func()) {
+	return m.subscribers.add()
+}
+
+// add registers a subscriber
+func (s *subscribers) add() (<-chan config.StatusUpdate, func()) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	ch := make(chan config.StatusUpdate, subscriberBuffer)
+	if s.closed {
+		close(ch)
+		return ch, func() {}
+	}
+	if s.chans == nil {
+		s.chans = make(map[int]chan config.StatusUpdate)
+	}
+	id := s.next
+	s.next++
+	s.chans[id] = ch
+	if len(s.current) > 0 {
+		ch <- s.full()
+	}
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() { s.remove(id) })
+	}
+}
+
+// remove unregisters a subscriber and closes its channel
+func (s *subscribers) remove(id int) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if ch, ok := s.chans[id]; ok {
+		delete(s.chans, id)
+		close(ch)
+	}
+}
+
+// publish delivers the services whose status differs from the previous update to
+// every subscriber without blocking, skipping statuses older than the ones delivered.
+// Services missing from statuses are removed only when it is complete, i.e. holds every
+// service. A subscriber that lags behind has its
+// backlog replaced with a full update, since deltas can't be skipped.
+func (s *subscribers) publish(statuses Snapshot, complete bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.current == nil {
+		s.current = make(Snapshot, len(statuses))
+	}
+	var changed Snapshot
+	for name, status := range statuses {
+		if previous, exists := s.current[name]; exists {
+			// Snapshots taken concurrently, e.g. during startup, may arrive out of order
+			if status.Generation < previous.Generation {
+				continue
+			}
+			if sameStatus(previous, status) {
+				continue
+			}
+		}
+		if changed == nil {
+			changed = make(Snapshot)
+		}
+		changed[name] = status
+		s.current[name] = status
+	}
+	var removed []string
+	if complete {
+		for name := range s.current {
+			if _, exists := statuses[name]; !exists {
+				removed = append(removed, name)
+				delete(s.current, name)
+			}
+		}
+	}
+	if len(changed) == 0 && len(removed) == 0 {
+		return
+	}
+
+	s.version++
+	update := config.StatusUpdate{Version: s.version, Changed: changed, Removed: removed}
+	for _, ch := range s.chans {
+		select {
+		case ch <- update:
+			continue
+		default:
+		}
+		for drained := false; !drained; {
+			select {
+			case <-ch:
+				s.drops++
+			default:
+				drained = true
+			}
+		}
+		select {
+		case ch <- s.full():
+		default:
+		}
+	}
+}
+
+// full returns an update holding the current status of every service; the caller
+// holds the mutex
+func (s *subscribers) full() config.StatusUpdate {
+	statuses := make(Snapshot, len(s.current))
+	for name, status := range s.current {
+		statuses[name] = status
+	}
+	return config.StatusUpdate{Version: s.version, Changed: statuses, Full: true}
+}
+
+// stats returns the number of subscribers, the updates dropped for lagging ones and
+// the latest version
+func (s *subscribers) stats() (count int, drops int64, version uint64) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return len(s.chans), s.drops, s.version
+}
+
+// close ends all subscriptions; later subscribers get a closed channel
+func (s *subscribers) close() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for id, ch := range s.chans {
+		delete(s.chans, id)
+		close(ch)
+	}
+	s.closed = true
+}
+
+// sameStatus reports whether two statuses of a service look the same to subscribers.
+// Latencies are compared at the precision they are shown, so health checks alone don't make
+// every service change on every tick, and the access and error logs, which only grow
+// at the end, by their length and latest entry. Generations are ignored: a delivered
+// status keeps its generation until a change shows.
+func sameStatus(a, b config.ServiceStatus) bool {
+	if utils.FormatLatency(a.Latency) != utils.FormatLatency(b.Latency) ||
+		utils.FormatLatency(a.LatencyP50) != utils.FormatLatency(b.LatencyP50) ||
+		utils.FormatLatency(a.LatencyP95) != utils.FormatLatency(b.LatencyP95) ||
+		utils.FormatLatency(a.HealthCheckTime) != utils.FormatLatency(b.HealthCheckTime) ||
+		!sameTail(a.RecentAccess, b.RecentAccess) || !sameTail(a.RecentErrors, b.RecentErrors) ||
+		!sameAvailability(a.Availability, b.Availability) {
+		return false
+	}
+	a.Latency, a.LatencyP50, a.LatencyP95, a.RecentAccess, a.RecentErrors = 0, 0, 0, nil, nil
+	b.Latency, b.LatencyP50, b.LatencyP95, b.RecentAccess, b.RecentErrors = 0, 0, 0, nil, nil
+	a.HealthCheckTime, b.HealthCheckTime = 0, 0
+	a.Generation, b.Generation = 0, 0
+	a.Availability, b.Availability = config.Availability{}, config.Availability{}
+	return reflect.DeepEqual(a, b)
+}
+
+// sameAvailability compares availability as it is shown: the percentage and the state
+// of each timeline slot, which grows every minute
+func sameAvailability(a, b config.Availability) bool {
+	if a.String() != b.String() || len(a.Timeline) != len(b.Timeline) {
+		return false
+	}
+	for i := range a.Timeline {
+		if !a.Timeline[i].Start.Equal(b.Timeline[i].Start) || a.Timeline[i].State() != b.Timeline[i].State() {
+			return false
+		}
+	}
+	return true
+}
+
+// sameTail reports whether two append-only logs have the same length and latest entry
+func sameTail[T comparable](a, b []T) bool {
+	return len(a) == len(b) && (len(a) == 0 || a[len(a)-1] == b[len(b)-1])
+}
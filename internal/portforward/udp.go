@@ -0,0 +1,216 @@
+package portforward
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/relay"
+)
+
+// udpTunnelPort is the TCP port the socat pod of a UDP service listens on
+const udpTunnelPort = 10000
+
+// defaultUDPImage is the image of the socat pod unless the service sets udpImage
+const defaultUDPImage = "alpine/socat"
+
+// udpDestinationAnnotation records where a socat pod sends datagrams, so a pod left over
+// from another target is replaced
+const udpDestinationAnnotation = "kportforward/udp-destination"
+
+// udpPod is the part of a kubectl Pod that managing a socat pod needs
+type udpPod struct {
+	Metadata struct {
+		Annotations       map[string]string `json:"annotations"`
+		DeletionTimestamp *time.Time        `json:"deletionTimestamp"`
+	} `json:"metadata"`
+	Status struct {
+		Phase             string         `json:"phase"`
+		Conditions        []podCondition `json:"conditions"`
+		ContainerStatuses []struct {
+			State struct {
+				Waiting *struct {
+					Reason string `json:"reason"`
+				} `json:"waiting"`
+			} `json:"state"`
+		} `json:"containerStatuses"`
+	} `json:"status"`
+}
+
+// udpPodName returns the name of a service's socat pod. It ends in a hash of the host
+// name, so users sharing a namespace get their own pods.
+func udpPodName(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		} else {
+			b.WriteRune('-')
+		}
+	}
+	hostname, _ := os.Hostname()
+	hash := fnv.New32a()
+	hash.Write([]byte(hostname))
+	suffix := fmt.Sprintf("-%08x", hash.Sum32())
+
+	pod := "kportforward-udp-" + strings.Trim(b.String(), "-")
+	if len(pod) > 63-len(suffix) {
+		pod = pod[:63-len(suffix)] // Pod names are DNS labels of at most 63 characters
+	}
+	return strings.TrimRight(pod, "-") + suffix
+}
+
+// udpDestination returns where the socat pod sends a service's datagrams: the Service
+// by name, which resolves within the namespace, or the pod's IP
+func udpDestination(service config.Service, run kubectlRunner) (string, error) {
+	kind, name, _ := strings.Cut(kubectlTarget(service), "/")
+	port := strconv.Itoa(service.TargetPort)
+	switch kind {
+	case "service", "services", "svc":
+		return net.JoinHostPort(name, port), nil
+	case "pod", "pods", "po":
+		output, err := run(append([]string{"get", "pod", name, "--output", "jsonpath={.status.podIP}"}, kubectlScope(service)...)...)
+		if err != nil {
+			return "", fmt.Errorf("failed to get the IP of pod %s: %s", name, output)
+		}
+		if output == "" {
+			return "", fmt.Errorf("pod %s has no IP yet", name)
+		}
+		return net.JoinHostPort(output, port), nil
+	}
+	return "", fmt.Errorf("protocol udp needs a service or pod target, not %s", kind)
+}
+
+// ensureUDPPod makes sure the socat pod of a UDP service runs and sends to destination,
+// creating it or replacing one that failed or sends elsewhere, and reports whether it is
+// ready. When it isn't, the reason says what it waits for.
+func ensureUDPPod(pod, destination string, service config.Service, run kubectlRunner) (bool, string, error) {
+	scope := kubectlScope(service)
+	output, err := run(append([]string{"get", "pod", pod, "--output", "json", "--ignore-not-found"}, scope...)...)
+	if err != nil {
+		return false, "", fmt.Errorf("failed to get UDP relay pod %s: %s", pod, output)
+	}
+
+	if output != "" {
+		var current udpPod
+		if err := json.Unmarshal([]byte(output), &current); err != nil {
+			return false, "", fmt.Errorf("failed to parse UDP relay pod %s: %w", pod, err)
+		}
+		switch {
+		case current.Metadata.DeletionTimestamp != nil:
+			return false, fmt.Sprintf("UDP relay pod %s is being deleted", pod), nil
+		case current.Metadata.Annotations[udpDestinationAnnotation] != destination,
+			current.Status.Phase == "Failed", current.Status.Phase == "Succeeded":
+			if output, err := run(append([]string{"delete", "pod", pod, "--wait=false"}, scope...)...); err != nil {
+				return false, "", fmt.Errorf("failed to replace UDP relay pod %s: %s", pod, output)
+			}
+			return false, fmt.Sprintf("replacing UDP relay pod %s", pod), nil
+		case podReady(current.Status.Conditions):
+			return true, "", nil
+		}
+		for _, container := range current.Status.ContainerStatuses {
+			if waiting := container.State.Waiting; waiting != nil && waiting.Reason != "" {
+				return false, fmt.Sprintf("UDP relay pod %s: %s", pod, waiting.Reason), nil // e.g. ImagePullBackOff
+			}
+		}
+		return false, fmt.Sprintf("UDP relay pod %s is starting", pod), nil
+	}
+
+	image := service.UDPImage
+	if image == "" {
+		image = defaultUDPImage
+	}
+	args := append([]string{"run", pod, "--image", image, "--restart", "Never",
+		"--labels", "app.kubernetes.io/managed-by=kportforward",
+		"--annotations", udpDestinationAnnotation + "=" + destination}, scope...)
+	args = append(args, "--command", "--", "socat", fmt.Sprintf("TCP-LISTEN:%d,fork,reuseaddr", udpTunnelPort), "UDP:"+destination)
+	if output, err := run(args...); err != nil {
+		return false, "", fmt.Errorf("failed to create UDP relay pod %s: %s", pod, output)
+	}
+	return false, fmt.Sprintf("UDP relay pod %s is starting", pod), nil
+}
+
+// forwardsUDP reports whether the service relays UDP through a socat pod; the caller
+// holds the mutex
+func (sm *ServiceManager) forwardsUDP() bool {
+	return sm.config.IsUDP() && sm.config.UsesKubectl() && sm.agentTunnel == nil
+}
+
+// prepareUDPPod makes sure the socat pod of a UDP service runs and sends to its target,
+// reporting whether it is ready like ensureUDPPod
+func prepareUDPPod(name string, service config.Service, run kubectlRunner) (bool, string, error) {
+	destination, err := udpDestination(service, run)
+	if err != nil {
+		return false, "", err
+	}
+	return ensureUDPPod(udpPodName(name), destination, service, run)
+}
+
+// recordUDPPod stores the outcome of prepareUDPPod, marking the service as waiting while
+// the pod starts; the caller holds the mutex
+func (sm *ServiceManager) recordUDPPod(ready bool, reason string, err error) error {
+	sm.workloadCheckedAt = time.Now()
+
+	if err != nil {
+		sm.status.Status = "Failed"
+		sm.setError(err.Error())
+		sm.handleFailure()
+		return fmt.Errorf("failed to start the UDP relay of %s: %w", sm.name, err)
+	}
+	if !ready {
+		if sm.status.Status != StatusWaitingForWorkload {
+			sm.logger.Info("Waiting for the UDP relay of %s: %s", sm.name, reason)
+		}
+		sm.status.Status = StatusWaitingForWorkload
+		sm.status.LastError = reason // Not a failure, so it stays out of the error history
+		return fmt.Errorf("service %s is waiting for its UDP relay: %s", sm.name, reason)
+	}
+	return nil
+}
+
+// deleteUDPPod removes the socat pod of a UDP service once it shuts down
+func (sm *ServiceManager) deleteUDPPod() {
+	sm.mutex.RLock()
+	forwardsUDP, kube, enabled := sm.forwardsUDP(), sm.kubeService(), sm.kubectl != nil
+	sm.mutex.RUnlock()
+	if !forwardsUDP || !enabled {
+		return
+	}
+
+	// The service's own runner stops with its context, which Shutdown cancels first
+	run := newKubectlRunner(context.Background(), kube)
+	pod := udpPodName(sm.name)
+	args := append([]string{"delete", "pod", pod, "--ignore-not-found", "--wait=false"}, kubectlScope(kube)...)
+	if output, err := run(args...); err != nil {
+		sm.logger.Warn("Failed to delete UDP relay pod %s of %s: %s", pod, sm.name, output)
+	}
+}
+
+// startUDPRelay starts the UDP relay on the user-facing port in front of kubectl's
+// backend port; the caller holds the mutex
+func (sm *ServiceManager) startUDPRelay(localPort, backendPort int) error {
+	// The relay listens on a single address
+	host := primaryAddress(sm.config.BindAddress)
+	if host == "" {
+		host = "localhost"
+	}
+
+	r := relay.NewUDP(sm.name,
+		net.JoinHostPort(host, strconv.Itoa(localPort)),
+		net.JoinHostPort("127.0.0.1", strconv.Itoa(backendPort)),
+		sm.logger)
+	if err := r.Start(); err != nil {
+		return err
+	}
+
+	sm.udpRelay = r
+	sm.backendPort = backendPort
+	return nil
+}
@@ -0,0 +1,98 @@
+package portforward
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+func TestUDPPodName(t *testing.T) {
+	name := udpPodName("Cluster_DNS")
+	if !strings.HasPrefix(name, "kportforward-udp-cluster-dns-") || name != udpPodName("Cluster_DNS") {
+		t.Errorf("Expected a stable DNS-safe name, got %s", name)
+	}
+	if long := udpPodName(strings.Repeat("statsd-", 20)); len(long) > 63 || strings.Contains(long, "--") {
+		t.Errorf("Expected a valid pod name of at most 63 characters, got %s", long)
+	}
+}
+
+func TestUDPDestination(t *testing.T) {
+	run := fakeKubectl(map[string]string{"get pod": "10.1.2.3"})
+	tests := map[string]string{
+		"service/kube-dns": "kube-dns:53",
+		"pod/coredns-0":    "10.1.2.3:53",
+	}
+	for target, expected := range tests {
+		destination, err := udpDestination(config.Service{Target: target, TargetPort: 53}, run)
+		if err != nil || destination != expected {
+			t.Errorf("%s: expected %s, got %q (%v)", target, expected, destination, err)
+		}
+	}
+	if _, err := udpDestination(config.Service{Target: "deployment/coredns", TargetPort: 53}, run); err == nil {
+		t.Error("Expected a deployment target to be rejected")
+	}
+}
+
+func TestEnsureUDPPod(t *testing.T) {
+	service := config.Service{Target: "service/statsd", TargetPort: 8125, Namespace: "metrics", Protocol: "udp"}
+	readyPod := `{"metadata": {"annotations": {"kportforward/udp-destination": "statsd:8125"}},
+		"status": {"phase": "Running", "conditions": [{"type": "Ready", "status": "True"}]}}`
+
+	tests := []struct {
+		name    string
+		pod     string
+		ready   bool
+		command string // First words of the kubectl command run after the lookup
+	}{
+		{"missing", "", false, "run kportforward-udp-statsd"},
+		{"ready", readyPod, true, ""},
+		{"other destination", strings.Replace(readyPod, "statsd:8125", "statsd:9125", 1), false, "delete pod"},
+		{"failed", `{"metadata": {"annotations": {"kportforward/udp-destination": "statsd:8125"}}, "status": {"phase": "Failed"}}`, false, "delete pod"},
+		{"pulling", `{"metadata": {"annotations": {"kportforward/udp-destination": "statsd:8125"}},
+			"status": {"phase": "Pending", "containerStatuses": [{"state": {"waiting": {"reason": "ImagePullBackOff"}}}]}}`, false, ""},
+	}
+	for _, test := range tests {
+		var commands []string
+		run := func(args ...string) (string, error) {
+			if args[0] == "get" {
+				return test.pod, nil
+			}
+			commands = append(commands, strings.Join(args, " "))
+			return "", nil
+		}
+
+		ready, reason, err := ensureUDPPod("kportforward-udp-statsd", "statsd:8125", service, run)
+		if err != nil || ready != test.ready {
+			t.Errorf("%s: expected ready %v, got %v (%v)", test.name, test.ready, ready, err)
+		}
+		if !ready && reason == "" {
+			t.Errorf("%s: expected a reason while the pod isn't ready", test.name)
+		}
+		if ran := strings.Join(commands, "; "); !strings.HasPrefix(ran, test.command) || test.command == "" && ran != "" {
+			t.Errorf("%s: expected %q, ran %q", test.name, test.command, commands)
+		}
+		if test.name == "missing" {
+			if !strings.Contains(commands[0], "--namespace metrics") || !strings.HasSuffix(commands[0], "-- socat TCP-LISTEN:10000,fork,reuseaddr UDP:statsd:8125") {
+				t.Errorf("Expected a socat pod in the namespace, ran %q", commands[0])
+			}
+		}
+		if test.name == "pulling" && !strings.Contains(reason, "ImagePullBackOff") {
+			t.Errorf("Expected the waiting reason, got %q", reason)
+		}
+	}
+}
+
+func TestUDPServiceWaitsForRelayPod(t *testing.T) {
+	service := config.Service{Target: "service/statsd", TargetPort: 8125, LocalPort: 28125, Protocol: "udp"}
+	sm := NewServiceManager("statsd", service, utils.NewLogger(utils.LevelError))
+	sm.kubectl = func(args ...string) (string, error) { return "", nil } // No pod yet; creating it succeeds
+
+	if err := sm.Start(); err == nil {
+		t.Fatal("Expected the start to wait for the UDP relay pod")
+	}
+	if status := sm.GetStatus(); status.Status != StatusWaitingForWorkload || !strings.Contains(status.LastError, "starting") {
+		t.Errorf("Expected to wait for the relay pod, got %s: %s", status.Status, status.LastError)
+	}
+}
@@ -0,0 +1,257 @@
+package portforward
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+// wildcardRefreshInterval is how often wildcard entries are matched against the cluster
+// again, picking up Services that were created or deleted
+const wildcardRefreshInterval = time.Minute
+
+// serviceList is the part of a kubectl List of Services that wildcard expansion needs
+type serviceList struct {
+	Items []struct {
+		Metadata struct {
+			Name string `json:"name"`
+		} `json:"metadata"`
+		Spec struct {
+			Ports []struct {
+				Port int `json:"port"`
+			} `json:"ports"`
+		} `json:"spec"`
+	} `json:"items"`
+}
+
+// wildcardState tracks the wildcard entries of the configuration and their refreshes
+type wildcardState struct {
+	entries     map[string]config.Service // Guarded by the manager's mutex
+	lastRefresh time.Time                 // Owned by the monitor loop
+	refreshNow  atomic.Bool               // Refresh on the next tick, e.g. after a context change
+	refreshing  atomic.Bool
+}
+
+// expandWildcard lists the Services a wildcard entry matches and returns a forward for
+// each, named <entry>-<service>. The local ports are picked freely, or from the entry's
+// localPortRange by assignRangePorts; the target port is the entry's, or else the
+// Service's first port. Services without ports are skipped.
+func expandWildcard(name string, entry config.Service, run kubectlRunner) (map[string]config.Service, error) {
+	args := append([]string{"get", "services", "--output", "json"}, kubectlScope(entry)...)
+	if entry.Selector != "" {
+		args = append(args, "--selector", entry.Selector)
+	}
+	output, err := run(args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list services for %s: %s", name, output)
+	}
+
+	var list serviceList
+	if err := json.Unmarshal([]byte(output), &list); err != nil {
+		return nil, fmt.Errorf("failed to parse services for %s: %w", name, err)
+	}
+
+	pattern := entry.WildcardPattern()
+	services := make(map[string]config.Service)
+	for _, item := range list.Items {
+		if matched, _ := path.Match(pattern, item.Metadata.Name); !matched {
+			continue
+		}
+		targetPort := entry.TargetPort
+		if targetPort == 0 && len(item.Spec.Ports) > 0 {
+			targetPort = item.Spec.Ports[0].Port
+		}
+		if targetPort == 0 {
+			continue
+		}
+
+		service := entry
+		service.Target = "service/" + item.Metadata.Name
+		service.TargetPort = targetPort
+		service.LocalPort = 0
+		service.Selector = ""
+		service.Wildcard = name
+		services[name+"-"+item.Metadata.Name] = service
+	}
+	return services, nil
+}
+
+// expandServices replaces the wildcard entries of services with the forwards they
+// expand to and returns the wildcard entries; services filter doesn't select are left
+// out. An entry that can't be expanded, e.g. while the cluster is unreachable, is
+// logged and expands to nothing until a refresh.
+func (m *Manager) expandServices(services map[string]config.Service, filter config.ServiceFilter) (map[string]config.Service, map[string]config.Service) {
+	expanded := make(map[string]config.Service, len(services))
+	wildcards := make(map[string]config.Service)
+	used := make(map[int]bool) // Local ports of configured services, which port ranges skip
+	for name, service := range services {
+		if service.IsWildcard() {
+			wildcards[name] = service
+			continue
+		}
+		used[service.LocalPort] = true
+		if filter.Matches(name) {
+			expanded[name] = service
+		}
+	}
+
+	names := make([]string, 0, len(wildcards))
+	for name := range wildcards {
+		names = append(names, name)
+	}
+	sort.Strings(names) // Earlier entries get the lower ports of overlapping ranges
+
+	for _, name := range names {
+		entry := m.config.WithServiceDefaults(wildcards[name])
+		matches, err := expandWildcard(name, entry, newKubectlRunner(m.ctx, entry))
+		if err != nil {
+			m.logger.Warn("Failed to expand wildcard %s: %v", name, err)
+			continue
+		}
+		m.logger.Info("Wildcard %s matches %d services", name, len(matches))
+		for matchName := range matches {
+			if _, exists := services[matchName]; exists {
+				m.logger.Warn("Skipping %s of wildcard %s: a configured service has that name", matchName, name)
+				delete(matches, matchName)
+			} else if !filter.Matches(matchName, name) {
+				delete(matches, matchName)
+			}
+		}
+		if err := m.assignRangePorts(name, entry, matches, used); err != nil {
+			m.logger.Warn("Failed to expand wildcard %s: %v", name, err)
+			continue
+		}
+		for matchName, match := range matches {
+			expanded[matchName] = match
+		}
+	}
+	return expanded, wildcards
+}
+
+// assignRangePorts gives the forwards of a wildcard entry with a localPortRange their
+// local ports, in name order: a running forward keeps its port, the others take the
+// lowest port of the range that isn't used and is free. Forwards the range has no port
+// left for are logged and dropped. used collects the ports taken.
+func (m *Manager) assignRangePorts(name string, entry config.Service, matches map[string]config.Service, used map[int]bool) error {
+	first, last, err := entry.LocalPorts()
+	if err != nil || first == 0 {
+		return err
+	}
+
+	m.mutex.RLock()
+	kept := make(map[string]int)
+	for serviceName, sm := range m.services {
+		used[sm.config.LocalPort] = true
+		if port := sm.config.LocalPort; sm.config.Wildcard == name && port >= first && port <= last {
+			kept[serviceName] = port
+		}
+	}
+	m.mutex.RUnlock()
+
+	names := make([]string, 0, len(matches))
+	for matchName := range matches {
+		names = append(names, matchName)
+	}
+	sort.Strings(names)
+
+	next := first
+	for _, matchName := range names {
+		port, ok := kept[matchName]
+		for !ok && next <= last {
+			if !used[next] && utils.IsPortAvailable(next) {
+				port, ok = next, true
+			}
+			next++
+		}
+		if !ok {
+			m.logger.Warn("Skipping %s of wildcard %s: no free port left in %s", matchName, name, entry.LocalPortRange)
+			delete(matches, matchName)
+			continue
+		}
+		used[port] = true
+		match := matches[matchName]
+		match.LocalPort = port
+		matches[matchName] = match
+	}
+	return nil
+}
+
+// refreshWildcardsIfDue starts a refresh in the background once the refresh interval has
+// passed or one was requested; called by the monitor loop
+func (m *Manager) refreshWildcardsIfDue(now time.Time) {
+	if !m.wildcards.refreshNow.Swap(false) && now.Sub(m.wildcards.lastRefresh) < wildcardRefreshInterval {
+		return
+	}
+	m.wildcards.lastRefresh = now
+	go m.refreshWildcards()
+}
+
+// refreshWildcards matches the wildcard entries against the cluster again, adding
+// forwards for new Services and removing those of deleted ones. Entries whose Services
+// can't be listed keep their forwards.
+func (m *Manager) refreshWildcards() {
+	if !m.wildcards.refreshing.CompareAndSwap(false, true) {
+		return
+	}
+	defer m.wildcards.refreshing.Store(false)
+
+	m.mutex.RLock()
+	entries := make(map[string]config.Service, len(m.wildcards.entries))
+	for name, entry := range m.wildcards.entries {
+		entries[name] = entry
+	}
+	current := make(map[string]string, len(m.services)) // Service -> wildcard entry it was expanded from
+	for name, sm := range m.services {
+		current[name] = sm.config.Wildcard
+	}
+	filter := m.filter
+	m.mutex.RUnlock()
+
+	names := make([]string, 0, len(entries))
+	for name := range entries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if m.ctx.Err() != nil {
+			return // Stopped
+		}
+		entry := m.config.WithServiceDefaults(entries[name])
+		matches, err := expandWildcard(name, entry, newKubectlRunner(m.ctx, entry))
+		if err != nil {
+			m.logger.Warn("Failed to refresh wildcard %s: %v", name, err)
+			continue
+		}
+		for serviceName := range matches {
+			if !filter.Matches(serviceName, name) {
+				delete(matches, serviceName)
+			}
+		}
+		if err := m.assignRangePorts(name, entry, matches, make(map[int]bool)); err != nil {
+			m.logger.Warn("Failed to refresh wildcard %s: %v", name, err)
+			continue
+		}
+		for serviceName, wildcard := range current {
+			if _, matched := matches[serviceName]; wildcard == name && !matched {
+				m.logger.Info("Service %s no longer matches wildcard %s", serviceName, name)
+				if err := m.RemoveService(serviceName); err != nil {
+					m.logger.Warn("Failed to remove %s: %v", serviceName, err)
+				}
+			}
+		}
+		for serviceName, service := range matches {
+			if _, exists := current[serviceName]; exists {
+				continue
+			}
+			m.logger.Info("Service %s matches wildcard %s", serviceName, name)
+			m.AddService(serviceName, service) // Start failures are logged and retried by the monitor
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package portforward
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+const wildcardServices = `{"items": [
+	{"metadata": {"name": "api-orders"}, "spec": {"ports": [{"port": 8080}, {"port": 9090}]}},
+	{"metadata": {"name": "api-users"}, "spec": {"ports": [{"port": 3000}]}},
+	{"metadata": {"name": "api-headless"}, "spec": {}},
+	{"metadata": {"name": "web"}, "spec": {"ports": [{"port": 80}]}}
+]}`
+
+func TestExpandWildcard(t *testing.T) {
+	var args []string
+	run := func(a ...string) (string, error) {
+		args = a
+		return wildcardServices, nil
+	}
+
+	entry := config.Service{Target: "service/api-*", Namespace: "shop", Selector: "tier=backend", Type: "rest", LocalPort: 9000}
+	services, err := expandWildcard("shop", entry, run)
+	if err != nil {
+		t.Fatalf("expandWildcard failed: %v", err)
+	}
+	if joined := strings.Join(args, " "); !strings.Contains(joined, "--namespace shop") || !strings.Contains(joined, "--selector tier=backend") {
+		t.Errorf("Expected the namespace and selector to scope the listing, got %s", joined)
+	}
+	if len(services) != 2 {
+		t.Fatalf("Expected the two API services with ports, got %v", services)
+	}
+
+	orders := services["shop-api-orders"]
+	if orders.Target != "service/api-orders" || orders.TargetPort != 8080 || orders.LocalPort != 0 {
+		t.Errorf("Expected the first port and a free local port, got %+v", orders)
+	}
+	if orders.Wildcard != "shop" || orders.Selector != "" || orders.Type != "rest" || orders.IsWildcard() {
+		t.Errorf("Expected a concrete forward with the entry's settings, got %+v", orders)
+	}
+
+	entry.TargetPort = 9090
+	services, _ = expandWildcard("shop", entry, run)
+	if port := services["shop-api-users"].TargetPort; port != 9090 {
+		t.Errorf("Expected the entry's target port, got %d", port)
+	}
+}
+
+func TestAssignRangePorts(t *testing.T) {
+	manager := NewManager(&config.Config{MonitoringInterval: time.Second}, utils.NewLogger(utils.LevelError))
+	manager.services["shop-api-users"] = NewServiceManager("shop-api-users",
+		config.Service{Target: "service/api-users", LocalPort: 21102, Wildcard: "shop"}, manager.logger)
+
+	busy, err := net.Listen("tcp", ":21101")
+	if err != nil {
+		t.Skipf("Port 21101 is unavailable: %v", err)
+	}
+	defer busy.Close()
+
+	entry := config.Service{Target: "service/api-*", LocalPortRange: "21100-21104"}
+	matches := map[string]config.Service{
+		"shop-api-audit":  {Target: "service/api-audit"},
+		"shop-api-orders": {Target: "service/api-orders"},
+		"shop-api-users":  {Target: "service/api-users"},
+		"shop-api-zones":  {Target: "service/api-zones"},
+	}
+	used := map[int]bool{21100: true} // A configured service's port
+	if err := manager.assignRangePorts("shop", entry, matches, used); err != nil {
+		t.Fatalf("assignRangePorts failed: %v", err)
+	}
+
+	expected := map[string]int{"shop-api-audit": 21103, "shop-api-orders": 21104, "shop-api-users": 21102}
+	if len(matches) != len(expected) {
+		t.Errorf("Expected the forward without a free port to be dropped, got %v", matches)
+	}
+	for name, port := range expected {
+		if matches[name].LocalPort != port {
+			t.Errorf("Expected %s on port %d, got %d", name, port, matches[name].LocalPort)
+		}
+	}
+
+	entry.LocalPortRange = "21104-21100"
+	if err := manager.assignRangePorts("shop", entry, matches, used); err == nil {
+		t.Error("Expected an invalid range to be rejected")
+	}
+}
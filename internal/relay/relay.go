@@ -0,0 +1,412 @@
+package relay
+
+import (
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/victorkazakov/kportforward/internal/auth"
+	"github.com/victorkazakov/kportforward/internal/capture"
+	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+// DefaultCaptureDir is where captured traffic is written when no directory is configured
+const DefaultCaptureDir = "kportforward-captures"
+
+// Options configures a relay in front of a port-forward
+type Options struct {
+	Name        string
+	ListenAddr  string // Address users connect to (host:port)
+	BackendAddr string // Address kubectl port-forward listens on
+	HTTP        bool   // Proxy at the HTTP layer instead of copying raw bytes
+	TLSConfig   *tls.Config
+	Authorizer  Authorizer                        // Adds credentials to HTTP requests
+	Recorder    *capture.Recorder                 // Records HTTP traffic; closed on Stop
+	OnAccess    func(entry config.AccessLogEntry) // Called for every connection (raw) or request (HTTP)
+	Wake        func() error                      // Called before reaching the backend, e.g. to restart a suspended forward
+	Logger      *utils.Logger
+}
+
+// Authorizer adds credentials to a request before it is forwarded
+type Authorizer interface {
+	Authorize(req *http.Request) error
+}
+
+// Relay listens on the user-facing port and forwards connections to the
+// kubectl port-forward, adding features such as TLS termination and auth on the way
+type Relay struct {
+	opts     Options
+	listener net.Listener
+	server   *http.Server
+
+	// Open raw connections, closed on Stop
+	conns     map[net.Conn]struct{}
+	connMutex sync.Mutex
+	wg        sync.WaitGroup
+
+	// Open connections or requests and when the last one began or ended
+	active        int
+	lastActive    time.Time
+	activityMutex sync.Mutex
+}
+
+// Needed reports whether a service requires a relay in front of its forward
+func Needed(service config.Service) bool {
+	return service.LocalTLS || service.Auth != nil || service.AccessLog || service.IdleTimeout > 0
+}
+
+// Captures reports whether traffic of a service is recorded with the given capture settings
+func Captures(service config.Service, captureConfig config.CaptureConfig) bool {
+	return captureConfig.Enabled && service.IsHTTP()
+}
+
+// ForService creates a relay configured from a service definition
+func ForService(name string, service config.Service, captureConfig config.CaptureConfig, listenAddr, backendAddr string, logger *utils.Logger) (*Relay, error) {
+	opts := Options{
+		Name:        name,
+		ListenAddr:  listenAddr,
+		BackendAddr: backendAddr,
+		HTTP:        service.IsHTTP(),
+		Logger:      logger,
+	}
+
+	if service.LocalTLS {
+		tlsConfig, err := ServerTLSConfig(service)
+		if err != nil {
+			return nil, err
+		}
+		opts.TLSConfig = tlsConfig
+	}
+
+	if service.Auth != nil {
+		if !opts.HTTP {
+			return nil, fmt.Errorf("auth injection is only supported for web and rest services")
+		}
+		provider, err := auth.ProviderFor(name, *service.Auth, logger)
+		if err != nil {
+			return nil, err
+		}
+		opts.Authorizer = provider
+	}
+
+	if Captures(service, captureConfig) {
+		dir := captureConfig.Dir
+		if dir == "" {
+			dir = DefaultCaptureDir
+		}
+		dir, err := utils.ExpandPath(dir)
+		if err != nil {
+			return nil, err
+		}
+		recorder, err := capture.NewRecorder(dir, name)
+		if err != nil {
+			return nil, err
+		}
+		opts.Recorder = recorder
+	}
+
+	return New(opts), nil
+}
+
+// New creates a relay
+func New(opts Options) *Relay {
+	return &Relay{
+		opts:  opts,
+		conns: make(map[net.Conn]struct{}),
+	}
+}
+
+// SetAccessLog sets the callback for the access log; call before Start
+func (r *Relay) SetAccessLog(onAccess func(entry config.AccessLogEntry)) {
+	r.opts.OnAccess = onAccess
+}
+
+// SetWake sets the callback run before each connection (raw) or request (HTTP) reaches
+// the backend; when it fails the client gets an error instead. Call before Start.
+func (r *Relay) SetWake(wake func() error) {
+	r.opts.Wake = wake
+}
+
+// IdleFor returns how long the relay has had no open connections or requests
+func (r *Relay) IdleFor() time.Duration {
+	r.activityMutex.Lock()
+	defer r.activityMutex.Unlock()
+	if r.active > 0 {
+		return 0
+	}
+	return time.Since(r.lastActive)
+}
+
+// begin records a connection or request starting
+func (r *Relay) begin() {
+	r.activityMutex.Lock()
+	defer r.activityMutex.Unlock()
+	r.active++
+	r.lastActive = time.Now()
+}
+
+// end records a connection or request finishing
+func (r *Relay) end() {
+	r.activityMutex.Lock()
+	defer r.activityMutex.Unlock()
+	r.active--
+	r.lastActive = time.Now()
+}
+
+// Start begins accepting connections
+func (r *Relay) Start() error {
+	r.activityMutex.Lock()
+	r.lastActive = time.Now()
+	r.activityMutex.Unlock()
+
+	listener, err := net.Listen("tcp", r.opts.ListenAddr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", r.opts.ListenAddr, err)
+	}
+	if r.opts.TLSConfig != nil {
+		tlsConfig := r.opts.TLSConfig.Clone()
+		if r.opts.HTTP {
+			tlsConfig.NextProtos = []string{"http/1.1"}
+		} else {
+			// Raw mode carries gRPC, which requires HTTP/2 to be negotiated
+			tlsConfig.NextProtos = []string{"h2"}
+		}
+		listener = tls.NewListener(listener, tlsConfig)
+	}
+	r.listener = listener
+
+	if r.opts.HTTP {
+		r.server = &http.Server{
+			Handler:           r.httpHandler(),
+			ReadHeaderTimeout: 30 * time.Second,
+		}
+		go func() {
+			if err := r.server.Serve(listener); err != nil && err != http.ErrServerClosed {
+				r.opts.Logger.Warn("Relay for %s stopped: %v", r.opts.Name, err)
+			}
+		}()
+	} else {
+		go r.acceptLoop()
+	}
+
+	return nil
+}
+
+// Stop closes the listener and all open connections
+func (r *Relay) Stop() error {
+	if r.listener == nil {
+		return nil
+	}
+
+	if r.opts.Recorder != nil {
+		defer r.opts.Recorder.Close()
+	}
+
+	if r.server != nil {
+		// Close rather than Shutdown: long-lived streams would otherwise hold up restarts
+		return r.server.Close()
+	}
+
+	err := r.listener.Close()
+
+	r.connMutex.Lock()
+	for conn := range r.conns {
+		conn.Close()
+	}
+	r.connMutex.Unlock()
+
+	r.wg.Wait()
+	return err
+}
+
+// Addr returns the address the relay is listening on
+func (r *Relay) Addr() net.Addr {
+	return r.listener.Addr()
+}
+
+// httpHandler returns a reverse proxy to the backend
+func (r *Relay) httpHandler() http.Handler {
+	target := &url.URL{Scheme: "http", Host: r.opts.BackendAddr}
+	secure := r.opts.TLSConfig != nil
+
+	proxy := &httputil.ReverseProxy{
+		Rewrite: func(pr *httputil.ProxyRequest) {
+			pr.SetURL(target)
+			pr.SetXForwarded()
+			// Keep the Host the user sees so redirects and cookies point back at the relay
+			pr.Out.Host = pr.In.Host
+			if secure {
+				pr.Out.Header.Set("X-Forwarded-Proto", "https")
+			}
+		},
+		ErrorHandler: func(w http.ResponseWriter, req *http.Request, err error) {
+			r.opts.Logger.Warn("Relay for %s could not reach the port-forward: %v", r.opts.Name, err)
+			http.Error(w, fmt.Sprintf("kportforward: %s is not reachable: %v", r.opts.Name, err), http.StatusBadGateway)
+		},
+	}
+
+	var handler http.Handler = proxy
+	if r.opts.Wake != nil {
+		handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if err := r.opts.Wake(); err != nil {
+				r.opts.Logger.Warn("Relay for %s could not resume the port-forward: %v", r.opts.Name, err)
+				http.Error(w, fmt.Sprintf("kportforward: failed to resume %s: %v", r.opts.Name, err), http.StatusBadGateway)
+				return
+			}
+			proxy.ServeHTTP(w, req)
+		})
+	}
+	if r.opts.Authorizer != nil {
+		next := handler
+		handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if err := r.opts.Authorizer.Authorize(req); err != nil {
+				r.opts.Logger.Warn("Relay for %s could not authorize request: %v", r.opts.Name, err)
+				http.Error(w, fmt.Sprintf("kportforward: failed to obtain token for %s: %v", r.opts.Name, err), http.StatusBadGateway)
+				return
+			}
+			next.ServeHTTP(w, req)
+		})
+	}
+
+	// Capture wraps auth so recordings hold what the client sent, not injected tokens
+	if r.opts.Recorder != nil {
+		handler = r.opts.Recorder.Middleware(handler, func(err error) {
+			r.opts.Logger.Warn("Failed to record traffic for %s: %v", r.opts.Name, err)
+		})
+	}
+
+	if r.opts.OnAccess != nil {
+		handler = r.accessLogHandler(handler)
+	}
+
+	return r.activityHandler(handler)
+}
+
+// activityHandler counts in-flight requests for IdleFor
+func (r *Relay) activityHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		r.begin()
+		defer r.end()
+		next.ServeHTTP(w, req)
+	})
+}
+
+// accessLogHandler reports each request with its response status and duration
+func (r *Relay) accessLogHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		started := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, req)
+
+		r.opts.OnAccess(config.AccessLogEntry{
+			Time:     started,
+			Peer:     req.RemoteAddr,
+			Request:  fmt.Sprintf("%s %s %s", req.Method, req.URL.RequestURI(), req.Proto),
+			Status:   recorder.status,
+			Duration: time.Since(started),
+		})
+	})
+}
+
+// statusRecorder remembers the response status for the access log
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (s *statusRecorder) WriteHeader(status int) {
+	s.status = status
+	s.ResponseWriter.WriteHeader(status)
+}
+
+// Flush keeps streaming responses flowing
+func (s *statusRecorder) Flush() {
+	if flusher, ok := s.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (s *statusRecorder) Unwrap() http.ResponseWriter {
+	return s.ResponseWriter
+}
+
+// acceptLoop relays raw TCP connections to the backend
+func (r *Relay) acceptLoop() {
+	for {
+		conn, err := r.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		if r.opts.OnAccess != nil {
+			r.opts.OnAccess(config.AccessLogEntry{Time: time.Now(), Peer: conn.RemoteAddr().String()})
+		}
+
+		r.wg.Add(1)
+		go func() {
+			defer r.wg.Done()
+			r.pipe(conn)
+		}()
+	}
+}
+
+// pipe copies data between a client connection and a new backend connection
+func (r *Relay) pipe(client net.Conn) {
+	r.begin()
+	defer r.end()
+
+	if r.opts.Wake != nil {
+		if err := r.opts.Wake(); err != nil {
+			r.opts.Logger.Warn("Relay for %s could not resume the port-forward: %v", r.opts.Name, err)
+			client.Close()
+			return
+		}
+	}
+
+	backend, err := net.DialTimeout("tcp", r.opts.BackendAddr, 5*time.Second)
+	if err != nil {
+		r.opts.Logger.Warn("Relay for %s could not reach the port-forward: %v", r.opts.Name, err)
+		client.Close()
+		return
+	}
+
+	r.track(client, true)
+	r.track(backend, true)
+	defer func() {
+		r.track(client, false)
+		r.track(backend, false)
+		client.Close()
+		backend.Close()
+	}()
+
+	// Tear down both sides as soon as either direction finishes
+	done := make(chan struct{}, 2)
+	go func() {
+		io.Copy(backend, client)
+		done <- struct{}{}
+	}()
+	go func() {
+		io.Copy(client, backend)
+		done <- struct{}{}
+	}()
+	<-done
+}
+
+// track records open connections so Stop can close them
+func (r *Relay) track(conn net.Conn, open bool) {
+	r.connMutex.Lock()
+	defer r.connMutex.Unlock()
+	if open {
+		r.conns[conn] = struct{}{}
+	} else {
+		delete(r.conns, conn)
+	}
+}
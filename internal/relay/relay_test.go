@@ -0,0 +1,316 @@
+package relay
+
+import (
+	"bufio"
+	"crypto/tls"
+	"crypto/x509"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+// testTLSConfig returns a server config and a client config trusting it
+func testTLSConfig(t *testing.T) (*tls.Config, *tls.Config) {
+	t.Helper()
+
+	certFile, keyFile, err := EnsureLocalhostCertificate(t.TempDir())
+	if err != nil {
+		t.Fatalf("EnsureLocalhostCertificate failed: %v", err)
+	}
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		t.Fatalf("Failed to load generated certificate: %v", err)
+	}
+	certPEM, _ := os.ReadFile(certFile)
+	pool := x509.NewCertPool()
+	pool.AppendCertsFromPEM(certPEM)
+
+	return &tls.Config{Certificates: []tls.Certificate{cert}}, &tls.Config{RootCAs: pool}
+}
+
+func TestHTTPRelayTerminatesTLS(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Seen-Proto", r.Header.Get("X-Forwarded-Proto"))
+		w.Header().Set("X-Seen-Host", r.Host)
+		io.WriteString(w, "hello from backend")
+	}))
+	defer backend.Close()
+
+	serverTLS, clientTLS := testTLSConfig(t)
+	relay := New(Options{
+		Name:        "web",
+		ListenAddr:  "127.0.0.1:0",
+		BackendAddr: strings.TrimPrefix(backend.URL, "http://"),
+		HTTP:        true,
+		TLSConfig:   serverTLS,
+		Logger:      utils.NewLogger(utils.LevelError),
+	})
+	if err := relay.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer relay.Stop()
+
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: clientTLS}}
+	port := relay.Addr().(*net.TCPAddr).Port
+	resp, err := client.Get("https://localhost:" + strconv.Itoa(port) + "/")
+	if err != nil {
+		t.Fatalf("HTTPS request through relay failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if string(body) != "hello from backend" {
+		t.Errorf("Unexpected body: %q", body)
+	}
+	if resp.Header.Get("X-Seen-Proto") != "https" {
+		t.Errorf("Expected X-Forwarded-Proto https, got %q", resp.Header.Get("X-Seen-Proto"))
+	}
+	if resp.Header.Get("X-Seen-Host") != "localhost:"+strconv.Itoa(port) {
+		t.Errorf("Expected original Host to be preserved, got %q", resp.Header.Get("X-Seen-Host"))
+	}
+}
+
+func TestTCPRelayCopiesBytes(t *testing.T) {
+	// Echo server standing in for a kubectl port-forward
+	backend, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer backend.Close()
+	go func() {
+		for {
+			conn, err := backend.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				io.Copy(conn, conn)
+			}()
+		}
+	}()
+
+	relay := New(Options{
+		Name:        "db",
+		ListenAddr:  "127.0.0.1:0",
+		BackendAddr: backend.Addr().String(),
+		Logger:      utils.NewLogger(utils.LevelError),
+	})
+	if err := relay.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+
+	conn, err := net.Dial("tcp", relay.Addr().String())
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	io.WriteString(conn, "ping\n")
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil || line != "ping\n" {
+		t.Errorf("Expected echoed ping, got %q (%v)", line, err)
+	}
+
+	// Stop closes open connections
+	if err := relay.Stop(); err != nil {
+		t.Fatalf("Stop failed: %v", err)
+	}
+	if _, err := conn.Read(make([]byte, 1)); err == nil {
+		t.Error("Expected connection to be closed after Stop")
+	}
+}
+
+func TestEnsureLocalhostCertificateReuses(t *testing.T) {
+	dir := t.TempDir()
+
+	certFile, _, err := EnsureLocalhostCertificate(dir)
+	if err != nil {
+		t.Fatalf("EnsureLocalhostCertificate failed: %v", err)
+	}
+	first, _ := os.ReadFile(certFile)
+
+	if _, _, err := EnsureLocalhostCertificate(dir); err != nil {
+		t.Fatalf("EnsureLocalhostCertificate failed: %v", err)
+	}
+	second, _ := os.ReadFile(filepath.Join(dir, localhostCertFile))
+
+	if string(first) != string(second) {
+		t.Error("Expected a valid certificate to be reused")
+	}
+}
+
+func TestNeeded(t *testing.T) {
+	if Needed(config.Service{}) {
+		t.Error("Expected no relay for a plain service")
+	}
+	if !Needed(config.Service{LocalTLS: true}) {
+		t.Error("Expected a relay for localTLS services")
+	}
+	if !Needed(config.Service{Auth: &config.AuthConfig{}}) {
+		t.Error("Expected a relay for services with auth")
+	}
+	if !Needed(config.Service{AccessLog: true}) {
+		t.Error("Expected a relay for services with an access log")
+	}
+	if !Needed(config.Service{IdleTimeout: time.Minute}) {
+		t.Error("Expected a relay for services suspended when idle")
+	}
+	if !Captures(config.Service{Type: "rest"}, config.CaptureConfig{Enabled: true}) {
+		t.Error("Expected rest traffic to be captured")
+	}
+	if Captures(config.Service{Type: "rpc"}, config.CaptureConfig{Enabled: true}) {
+		t.Error("Expected rpc services not to be captured")
+	}
+}
+
+type staticAuthorizer string
+
+func (a staticAuthorizer) Authorize(req *http.Request) error {
+	req.Header.Set("Authorization", string(a))
+	return nil
+}
+
+func TestHTTPRelayInjectsCredentials(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, r.Header.Get("Authorization"))
+	}))
+	defer backend.Close()
+
+	relay := New(Options{
+		Name:        "api",
+		ListenAddr:  "127.0.0.1:0",
+		BackendAddr: strings.TrimPrefix(backend.URL, "http://"),
+		HTTP:        true,
+		Authorizer:  staticAuthorizer("Bearer injected"),
+		Logger:      utils.NewLogger(utils.LevelError),
+	})
+	if err := relay.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer relay.Stop()
+
+	resp, err := http.Get("http://" + relay.Addr().String() + "/")
+	if err != nil {
+		t.Fatalf("Request through relay failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if string(body) != "Bearer injected" {
+		t.Errorf("Expected injected credentials at the backend, got %q", body)
+	}
+}
+
+func TestAccessLog(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+	defer backend.Close()
+
+	entries := make(chan config.AccessLogEntry, 1)
+	relay := New(Options{
+		Name:        "web",
+		ListenAddr:  "127.0.0.1:0",
+		BackendAddr: strings.TrimPrefix(backend.URL, "http://"),
+		HTTP:        true,
+		Logger:      utils.NewLogger(utils.LevelError),
+	})
+	relay.SetAccessLog(func(entry config.AccessLogEntry) {
+		entries <- entry
+	})
+	if err := relay.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer relay.Stop()
+
+	resp, err := http.Get("http://" + relay.Addr().String() + "/hello?x=1")
+	if err != nil {
+		t.Fatalf("Request through relay failed: %v", err)
+	}
+	resp.Body.Close()
+
+	entry := <-entries
+	if entry.Request != "GET /hello?x=1 HTTP/1.1" || entry.Status != http.StatusTeapot {
+		t.Errorf("Unexpected access log entry: %+v", entry)
+	}
+	if !strings.HasPrefix(entry.Peer, "127.0.0.1:") {
+		t.Errorf("Expected client address in entry, got %q", entry.Peer)
+	}
+}
+
+func TestRelayWakesBackendAndTracksActivity(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "ok")
+	}))
+	defer backend.Close()
+
+	var wakes atomic.Int32
+	relay := New(Options{
+		Name:        "web",
+		ListenAddr:  "127.0.0.1:0",
+		BackendAddr: strings.TrimPrefix(backend.URL, "http://"),
+		HTTP:        true,
+		Logger:      utils.NewLogger(utils.LevelError),
+	})
+	relay.SetWake(func() error {
+		wakes.Add(1)
+		return nil
+	})
+	if err := relay.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer relay.Stop()
+
+	time.Sleep(20 * time.Millisecond)
+	before := relay.IdleFor()
+	if before < 20*time.Millisecond {
+		t.Errorf("Expected the relay to be idle since it started, got %s", before)
+	}
+
+	resp, err := http.Get("http://" + relay.Addr().String() + "/")
+	if err != nil {
+		t.Fatalf("Request through relay failed: %v", err)
+	}
+	resp.Body.Close()
+
+	if wakes.Load() != 1 {
+		t.Errorf("Expected the backend to be woken once, got %d", wakes.Load())
+	}
+	if idle := relay.IdleFor(); idle >= before {
+		t.Errorf("Expected the request to reset the idle time, got %s", idle)
+	}
+}
+
+func TestRelayReportsFailedWake(t *testing.T) {
+	relay := New(Options{
+		Name:        "web",
+		ListenAddr:  "127.0.0.1:0",
+		BackendAddr: "127.0.0.1:1",
+		HTTP:        true,
+		Logger:      utils.NewLogger(utils.LevelError),
+	})
+	relay.SetWake(func() error { return io.ErrUnexpectedEOF })
+	if err := relay.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer relay.Stop()
+
+	resp, err := http.Get("http://" + relay.Addr().String() + "/")
+	if err != nil {
+		t.Fatalf("Request through relay failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadGateway {
+		t.Errorf("Expected 502 when the forward can't be resumed, got %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,159 @@
+package relay
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+const (
+	localhostCertFile = "localhost.pem"
+	localhostKeyFile  = "localhost-key.pem"
+
+	// Regenerate the certificate this long before it expires
+	renewBefore = 30 * 24 * time.Hour
+)
+
+// certMutex serializes generation of the shared localhost certificate
+var certMutex sync.Mutex
+
+// ServerTLSConfig returns the TLS configuration for a service's local endpoint,
+// using the configured certificate or the generated localhost certificate
+func ServerTLSConfig(service config.Service) (*tls.Config, error) {
+	certFile, keyFile := service.TLSCert, service.TLSKey
+	if certFile == "" {
+		dir, err := CertificateDir()
+		if err != nil {
+			return nil, err
+		}
+		certFile, keyFile, err = EnsureLocalhostCertificate(dir)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		var err error
+		if certFile, err = utils.ExpandPath(certFile); err != nil {
+			return nil, err
+		}
+		if keyFile, err = utils.ExpandPath(keyFile); err != nil {
+			return nil, err
+		}
+	}
+
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load TLS certificate: %w", err)
+	}
+
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}, nil
+}
+
+// CertificateDir returns where the generated localhost certificate is stored
+func CertificateDir() (string, error) {
+	configDir, err := config.GetUserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "tls"), nil
+}
+
+// EnsureLocalhostCertificate returns the paths of a valid self-signed localhost
+// certificate in dir, generating a new one if it is missing or about to expire
+func EnsureLocalhostCertificate(dir string) (string, string, error) {
+	certMutex.Lock()
+	defer certMutex.Unlock()
+
+	certFile := filepath.Join(dir, localhostCertFile)
+	keyFile := filepath.Join(dir, localhostKeyFile)
+
+	if certificateValid(certFile, keyFile) {
+		return certFile, keyFile, nil
+	}
+
+	certPEM, keyPEM, err := generateLocalhostCertificate()
+	if err != nil {
+		return "", "", err
+	}
+
+	if err := utils.WriteFileAtomic(keyFile, keyPEM, 0600); err != nil {
+		return "", "", err
+	}
+	if err := utils.WriteFileAtomic(certFile, certPEM, 0644); err != nil {
+		return "", "", err
+	}
+
+	return certFile, keyFile, nil
+}
+
+// certificateValid reports whether the stored key pair loads and is not close to expiry
+func certificateValid(certFile, keyFile string) bool {
+	pair, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return false
+	}
+	cert, err := x509.ParseCertificate(pair.Certificate[0])
+	if err != nil {
+		return false
+	}
+	return time.Now().Add(renewBefore).Before(cert.NotAfter)
+}
+
+// generateLocalhostCertificate creates a self-signed certificate for localhost
+func generateLocalhostCertificate() ([]byte, []byte, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate key: %w", err)
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate serial number: %w", err)
+	}
+
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject: pkix.Name{
+			Organization: []string{"kportforward local development"},
+			CommonName:   "localhost",
+		},
+		DNSNames:              []string{"localhost"},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1"), net.IPv6loopback},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(365 * 24 * time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true, // Lets the certificate be trusted directly as its own root
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create certificate: %w", err)
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encode key: %w", err)
+	}
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	return certPEM, keyPEM, nil
+}
@@ -0,0 +1,174 @@
+package relay
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+// udpFlowTimeout is how long a client's backend connection is kept once the client has
+// stopped sending
+const udpFlowTimeout = 2 * time.Minute
+
+// maxDatagramSize is the largest UDP payload
+const maxDatagramSize = 65535
+
+// UDPRelay listens for UDP datagrams on the user-facing port and carries them over TCP
+// to the backend, a kubectl port-forward to a socat pod that sends them on as UDP. Each
+// client address gets its own backend connection, so replies find their way back.
+type UDPRelay struct {
+	name        string
+	listenAddr  string
+	backendAddr string
+	logger      *utils.Logger
+
+	conn   *net.UDPConn
+	flows  map[string]*udpFlow // By client address
+	closed bool
+	mutex  sync.Mutex
+	wg     sync.WaitGroup
+}
+
+// udpFlow is the backend connection of one client
+type udpFlow struct {
+	backend  net.Conn
+	lastSeen atomic.Int64 // Unix nanoseconds of the client's latest datagram
+}
+
+// NewUDP creates a UDP relay from listenAddr to the TCP backendAddr
+func NewUDP(name, listenAddr, backendAddr string, logger *utils.Logger) *UDPRelay {
+	return &UDPRelay{
+		name:        name,
+		listenAddr:  listenAddr,
+		backendAddr: backendAddr,
+		logger:      logger,
+		flows:       make(map[string]*udpFlow),
+	}
+}
+
+// Start begins accepting datagrams
+func (r *UDPRelay) Start() error {
+	addr, err := net.ResolveUDPAddr("udp", r.listenAddr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", r.listenAddr, err)
+	}
+	conn, err := net.ListenUDP("udp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", r.listenAddr, err)
+	}
+	r.conn = conn
+
+	r.wg.Add(1)
+	go r.readLoop()
+	return nil
+}
+
+// Stop closes the listener and all backend connections
+func (r *UDPRelay) Stop() error {
+	if r.conn == nil {
+		return nil
+	}
+	err := r.conn.Close()
+
+	r.mutex.Lock()
+	r.closed = true
+	for _, flow := range r.flows {
+		flow.backend.Close()
+	}
+	r.mutex.Unlock()
+
+	r.wg.Wait()
+	return err
+}
+
+// Addr returns the address the relay is listening on
+func (r *UDPRelay) Addr() net.Addr {
+	return r.conn.LocalAddr()
+}
+
+// readLoop relays datagrams from clients to their backend connections
+func (r *UDPRelay) readLoop() {
+	defer r.wg.Done()
+
+	buf := make([]byte, maxDatagramSize)
+	for {
+		n, client, err := r.conn.ReadFromUDP(buf)
+		if errors.Is(err, net.ErrClosed) {
+			return
+		}
+		if err != nil {
+			continue // e.g. an ICMP error from an earlier reply on Windows
+		}
+
+		flow, err := r.flow(client)
+		if err != nil {
+			r.logger.Warn("UDP relay for %s could not reach the port-forward: %v", r.name, err)
+			continue
+		}
+		flow.lastSeen.Store(time.Now().UnixNano())
+		if _, err := flow.backend.Write(buf[:n]); err != nil {
+			flow.backend.Close() // Its reply loop ends the flow; the next datagram opens a new one
+		}
+	}
+}
+
+// flow returns the backend connection of a client, connecting on its first datagram
+func (r *UDPRelay) flow(client *net.UDPAddr) (*udpFlow, error) {
+	key := client.String()
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.closed {
+		return nil, net.ErrClosed
+	}
+	if flow, ok := r.flows[key]; ok {
+		return flow, nil
+	}
+
+	backend, err := net.DialTimeout("tcp", r.backendAddr, 5*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	flow := &udpFlow{backend: backend}
+	r.flows[key] = flow
+
+	r.wg.Add(1)
+	go r.replyLoop(key, client, flow)
+	return flow, nil
+}
+
+// replyLoop sends what the backend connection of a client returns back to the client as
+// datagrams, and ends the flow once the client has been quiet for udpFlowTimeout
+func (r *UDPRelay) replyLoop(key string, client *net.UDPAddr, flow *udpFlow) {
+	defer r.wg.Done()
+	defer func() {
+		r.mutex.Lock()
+		if r.flows[key] == flow {
+			delete(r.flows, key)
+		}
+		r.mutex.Unlock()
+		flow.backend.Close()
+	}()
+
+	buf := make([]byte, maxDatagramSize)
+	for {
+		flow.backend.SetReadDeadline(time.Now().Add(udpFlowTimeout))
+		n, err := flow.backend.Read(buf)
+		if n > 0 {
+			r.conn.WriteToUDP(buf[:n], client)
+		}
+		if err == nil {
+			continue
+		}
+		var netErr net.Error
+		if errors.As(err, &netErr) && netErr.Timeout() && time.Since(time.Unix(0, flow.lastSeen.Load())) < udpFlowTimeout {
+			continue // No replies, but the client still sends
+		}
+		return
+	}
+}
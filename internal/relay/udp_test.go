@@ -0,0 +1,87 @@
+package relay
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+func TestUDPRelayCarriesDatagramsOverTCP(t *testing.T) {
+	// Stands in for the port-forward to socat: answers each read with "re:" and the data
+	backend, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer backend.Close()
+	accepted := make(chan struct{}, 4)
+	go func() {
+		for {
+			conn, err := backend.Accept()
+			if err != nil {
+				return
+			}
+			accepted <- struct{}{}
+			go func() {
+				defer conn.Close()
+				buf := make([]byte, 1024)
+				for {
+					n, err := conn.Read(buf)
+					if err != nil {
+						return
+					}
+					conn.Write(append([]byte("re:"), buf[:n]...))
+				}
+			}()
+		}
+	}()
+
+	relay := NewUDP("dns", "127.0.0.1:0", backend.Addr().String(), utils.NewLogger(utils.LevelError))
+	if err := relay.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer relay.Stop()
+
+	first := dialUDP(t, relay.Addr())
+	second := dialUDP(t, relay.Addr())
+	for _, message := range []string{"one", "two"} {
+		if reply := exchangeUDP(t, first, message); reply != "re:"+message {
+			t.Errorf("Expected re:%s, got %q", message, reply)
+		}
+	}
+	if reply := exchangeUDP(t, second, "three"); reply != "re:three" {
+		t.Errorf("Expected re:three, got %q", reply)
+	}
+	if len(accepted) != 2 {
+		t.Errorf("Expected one backend connection per client, got %d", len(accepted))
+	}
+
+	if err := relay.Stop(); err != nil {
+		t.Errorf("Stop failed: %v", err)
+	}
+}
+
+func dialUDP(t *testing.T, addr net.Addr) *net.UDPConn {
+	t.Helper()
+	conn, err := net.DialUDP("udp", nil, addr.(*net.UDPAddr))
+	if err != nil {
+		t.Fatalf("Failed to dial the relay: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func exchangeUDP(t *testing.T, conn *net.UDPConn, message string) string {
+	t.Helper()
+	if _, err := conn.Write([]byte(message)); err != nil {
+		t.Fatalf("Failed to send: %v", err)
+	}
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	buf := make([]byte, 1024)
+	n, err := conn.Read(buf)
+	if err != nil {
+		t.Fatalf("No reply to %s: %v", message, err)
+	}
+	return string(buf[:n])
+}
@@ -0,0 +1,241 @@
+package sessionreport
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/portforward"
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+// maxTopErrors is how many of the most frequent errors are listed, per service and overall
+const maxTopErrors = 3
+
+// Report summarizes the health of the services over one session
+type Report struct {
+	Start     time.Time        `json:"start"`
+	End       time.Time        `json:"end"`
+	Duration  string           `json:"duration"`
+	Context   string           `json:"context,omitempty"`
+	Restarts  int              `json:"restarts"`
+	Failures  int              `json:"failures"`
+	Services  []ServiceSummary `json:"services"`
+	TopErrors []ErrorCount     `json:"topErrors,omitempty"`
+}
+
+// ServiceSummary is one service's share of a report
+type ServiceSummary struct {
+	Name         string       `json:"name"`
+	Status       string       `json:"status"` // Status at the end of the session
+	Availability string       `json:"availability,omitempty"`
+	UpSeconds    float64      `json:"upSeconds"`
+	DownSeconds  float64      `json:"downSeconds"`
+	Restarts     int          `json:"restarts"`
+	Failures     int          `json:"failures"` // Transitions into Failed
+	TopErrors    []ErrorCount `json:"topErrors,omitempty"`
+}
+
+// ErrorCount is how often an error was the reason a service failed
+type ErrorCount struct {
+	Message string `json:"message"`
+	Count   int    `json:"count"`
+}
+
+// Collector tallies manager events over a session
+type Collector struct {
+	mutex    sync.Mutex
+	start    time.Time
+	context  string
+	services map[string]*tally
+}
+
+// tally is what the collector knows of one service
+type tally struct {
+	baseline config.Availability // Availability at the first snapshot; persisted time from earlier sessions
+	status   config.ServiceStatus
+	restarts int
+	failures int
+	errors   map[string]int
+}
+
+// NewCollector starts collecting a session that began at start
+func NewCollector(start time.Time) *Collector {
+	return &Collector{start: start, services: make(map[string]*tally)}
+}
+
+// HandleEvent records the events a report is built from
+func (c *Collector) HandleEvent(event portforward.Event) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	switch event.Type {
+	case portforward.EventStatusUpdated:
+		for name, status := range event.Snapshot {
+			t, seen := c.services[name]
+			if !seen {
+				t = c.tally(name)
+				t.baseline = config.Availability{Up: status.Availability.Up, Down: status.Availability.Down}
+			}
+			t.status = status
+		}
+	case portforward.EventServiceRestarted:
+		c.tally(event.Service).restarts++
+	case portforward.EventServiceStateChanged:
+		if event.Status.Status == "Failed" {
+			t := c.tally(event.Service)
+			t.failures++
+			if event.Error != "" {
+				t.errors[event.Error]++
+			}
+		}
+	case portforward.EventContextChanged:
+		c.context = event.Context
+	}
+}
+
+// tally returns the tally of a service, adding it when new; the caller holds the mutex
+func (c *Collector) tally(name string) *tally {
+	t, exists := c.services[name]
+	if !exists {
+		t = &tally{errors: make(map[string]int)}
+		c.services[name] = t
+	}
+	return t
+}
+
+// Report builds the report of the session ending at end
+func (c *Collector) Report(end time.Time) Report {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	report := Report{
+		Start:    c.start,
+		End:      end,
+		Duration: utils.FormatUptime(end.Sub(c.start)),
+		Context:  c.context,
+		Services: make([]ServiceSummary, 0, len(c.services)),
+	}
+	allErrors := make(map[string]int)
+	for name, t := range c.services {
+		availability := config.Availability{
+			Up:   t.status.Availability.Up - t.baseline.Up,
+			Down: t.status.Availability.Down - t.baseline.Down,
+		}
+		report.Services = append(report.Services, ServiceSummary{
+			Name:         name,
+			Status:       t.status.Status,
+			Availability: availability.String(),
+			UpSeconds:    availability.Up.Seconds(),
+			DownSeconds:  availability.Down.Seconds(),
+			Restarts:     t.restarts,
+			Failures:     t.failures,
+			TopErrors:    topErrors(t.errors),
+		})
+		report.Restarts += t.restarts
+		report.Failures += t.failures
+		for message, count := range t.errors {
+			allErrors[message] += count
+		}
+	}
+	sort.Slice(report.Services, func(i, j int) bool { return report.Services[i].Name < report.Services[j].Name })
+	report.TopErrors = topErrors(allErrors)
+	return report
+}
+
+// topErrors returns the most frequent errors, most frequent first
+func topErrors(errors map[string]int) []ErrorCount {
+	counts := make([]ErrorCount, 0, len(errors))
+	for message, count := range errors {
+		counts = append(counts, ErrorCount{Message: message, Count: count})
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Count != counts[j].Count {
+			return counts[i].Count > counts[j].Count
+		}
+		return counts[i].Message < counts[j].Message
+	})
+	if len(counts) > maxTopErrors {
+		counts = counts[:maxTopErrors]
+	}
+	return counts
+}
+
+// Log writes the report to the log, a line per service
+func (r Report) Log(logger *utils.Logger) {
+	logger.Info("Session summary: %s, %d services, %d restarts, %d failures",
+		r.Duration, len(r.Services), r.Restarts, r.Failures)
+	for _, service := range r.Services {
+		line := fmt.Sprintf("  %s: %s", service.Name, service.Status)
+		if service.Availability != "" {
+			line += fmt.Sprintf(", up %s", service.Availability)
+		}
+		line += fmt.Sprintf(", %d restarts, %d failures", service.Restarts, service.Failures)
+		if len(service.TopErrors) > 0 {
+			line += fmt.Sprintf("; most frequent error (%dx): %s", service.TopErrors[0].Count, service.TopErrors[0].Message)
+		}
+		logger.Info("%s", line)
+	}
+}
+
+// Markdown renders the report as a Markdown document
+func (r Report) Markdown() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# kportforward session %s\n\n", r.Start.Format("2006-01-02 15:04"))
+	fmt.Fprintf(&b, "- Duration: %s (until %s)\n", r.Duration, r.End.Format("2006-01-02 15:04"))
+	if r.Context != "" {
+		fmt.Fprintf(&b, "- Context: %s\n", r.Context)
+	}
+	fmt.Fprintf(&b, "- Restarts: %d\n- Failures: %d\n\n", r.Restarts, r.Failures)
+
+	b.WriteString("| Service | Final Status | Up | Restarts | Failures | Most Frequent Error |\n")
+	b.WriteString("| --- | --- | --- | --- | --- | --- |\n")
+	for _, service := range r.Services {
+		availability, topError := service.Availability, ""
+		if availability == "" {
+			availability = "-"
+		}
+		if len(service.TopErrors) > 0 {
+			topError = fmt.Sprintf("%s (%dx)", service.TopErrors[0].Message, service.TopErrors[0].Count)
+		}
+		fmt.Fprintf(&b, "| %s | %s | %s | %d | %d | %s |\n", service.Name, service.Status, availability,
+			service.Restarts, service.Failures, markdownCell(topError))
+	}
+
+	if len(r.TopErrors) > 0 {
+		b.WriteString("\n## Top Errors\n\n")
+		for _, count := range r.TopErrors {
+			fmt.Fprintf(&b, "- %dx %s\n", count.Count, count.Message)
+		}
+	}
+	return b.String()
+}
+
+// markdownCell keeps a value from breaking out of its table cell
+func markdownCell(value string) string {
+	return strings.NewReplacer("|", "\\|", "\n", " ").Replace(value)
+}
+
+// Write saves the report to path, as JSON when it ends in .json and as Markdown otherwise
+func (r Report) Write(path string) error {
+	var data []byte
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		encoded, err := json.MarshalIndent(r, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode session report: %w", err)
+		}
+		data = append(encoded, '\n')
+	} else {
+		data = []byte(r.Markdown())
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write session report: %w", err)
+	}
+	return nil
+}
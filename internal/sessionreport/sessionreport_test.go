@@ -0,0 +1,107 @@
+package sessionreport
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/portforward"
+)
+
+func snapshot(name, status string, up, down time.Duration) portforward.Event {
+	return portforward.Event{Type: portforward.EventStatusUpdated, Snapshot: map[string]config.ServiceStatus{
+		name: {Name: name, Status: status, Availability: config.Availability{Up: up, Down: down}},
+	}}
+}
+
+func failed(name, message string) portforward.Event {
+	return portforward.Event{Type: portforward.EventServiceStateChanged, Service: name,
+		Status: config.ServiceStatus{Status: "Failed"}, PreviousStatus: "Running", Error: message}
+}
+
+func TestCollectorReport(t *testing.T) {
+	start := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	collector := NewCollector(start)
+	collector.HandleEvent(portforward.Event{Type: portforward.EventContextChanged, Context: "dev"})
+
+	// api carries 10 minutes up over from a persisted earlier session
+	collector.HandleEvent(snapshot("api", "Running", 10*time.Minute, 0))
+	collector.HandleEvent(failed("api", "lost connection to pod"))
+	collector.HandleEvent(failed("api", "lost connection to pod"))
+	collector.HandleEvent(failed("api", "connection refused"))
+	collector.HandleEvent(portforward.Event{Type: portforward.EventServiceRestarted, Service: "api"})
+	collector.HandleEvent(snapshot("api", "Running", 10*time.Minute+45*time.Minute, 15*time.Minute))
+	collector.HandleEvent(snapshot("web", "Running", time.Hour, 0))
+	collector.HandleEvent(portforward.Event{Type: portforward.EventServiceStateChanged, Service: "web",
+		Status: config.ServiceStatus{Status: "Running"}, PreviousStatus: "Starting"})
+
+	report := collector.Report(start.Add(time.Hour))
+
+	if report.Duration != "1h0m" || report.Context != "dev" || report.Restarts != 1 || report.Failures != 3 {
+		t.Errorf("Unexpected totals: %+v", report)
+	}
+	if len(report.Services) != 2 || report.Services[0].Name != "api" {
+		t.Fatalf("Expected api and web sorted by name, got %+v", report.Services)
+	}
+	api := report.Services[0]
+	if api.Availability != "75.0%" || api.UpSeconds != 45*60 || api.DownSeconds != 15*60 {
+		t.Errorf("Expected api's session uptime without the earlier session, got %+v", api)
+	}
+	if len(api.TopErrors) != 2 || api.TopErrors[0] != (ErrorCount{Message: "lost connection to pod", Count: 2}) {
+		t.Errorf("Expected the most frequent error first, got %+v", api.TopErrors)
+	}
+	if web := report.Services[1]; web.Failures != 0 || web.Restarts != 0 || len(web.TopErrors) != 0 {
+		t.Errorf("Expected a clean web service, got %+v", web)
+	}
+}
+
+func TestTopErrorsLimited(t *testing.T) {
+	errors := map[string]int{"a": 1, "b": 5, "c": 2, "d": 2}
+	got := topErrors(errors)
+	want := []ErrorCount{{"b", 5}, {"c", 2}, {"d", 2}}
+	if len(got) != len(want) {
+		t.Fatalf("Expected %d errors, got %+v", len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Error %d: expected %+v, got %+v", i, want[i], got[i])
+		}
+	}
+}
+
+func TestReportWrite(t *testing.T) {
+	collector := NewCollector(time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC))
+	collector.HandleEvent(snapshot("api", "Failed", 0, time.Minute))
+	collector.HandleEvent(failed("api", "exit status 1 | broken pipe"))
+	report := collector.Report(time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC))
+	dir := t.TempDir()
+
+	jsonPath := filepath.Join(dir, "report.json")
+	if err := report.Write(jsonPath); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	data, _ := os.ReadFile(jsonPath)
+	var decoded Report
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Expected a JSON report: %v", err)
+	}
+	if len(decoded.Services) != 1 || decoded.Services[0].Failures != 1 {
+		t.Errorf("Unexpected JSON report: %+v", decoded)
+	}
+
+	markdownPath := filepath.Join(dir, "report.md")
+	if err := report.Write(markdownPath); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	data, _ = os.ReadFile(markdownPath)
+	markdown := string(data)
+	for _, want := range []string{"# kportforward session 2024-03-01 09:00", "| api | Failed |", `exit status 1 \| broken pipe (1x)`, "## Top Errors"} {
+		if !strings.Contains(markdown, want) {
+			t.Errorf("Expected the Markdown report to contain %q:\n%s", want, markdown)
+		}
+	}
+}
@@ -0,0 +1,242 @@
+package statusfile
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/portforward"
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+// ContextProvider returns the current Kubernetes context
+type ContextProvider interface {
+	GetKubernetesContext() string
+}
+
+// ServiceEntry is the status of a single service in the status file
+type ServiceEntry struct {
+	Status         string     `json:"status" yaml:"status"`
+	Type           string     `json:"type,omitempty" yaml:"type,omitempty"`
+	Namespace      string     `json:"namespace,omitempty" yaml:"namespace,omitempty"`
+	Target         string     `json:"target,omitempty" yaml:"target,omitempty"`
+	LocalPort      int        `json:"localPort" yaml:"localPort"`
+	ConfiguredPort int        `json:"configuredPort,omitempty" yaml:"configuredPort,omitempty"`
+	PID            int        `json:"pid,omitempty" yaml:"pid,omitempty"`
+	StartTime      *time.Time `json:"startTime,omitempty" yaml:"startTime,omitempty"`
+	RestartCount   int        `json:"restartCount" yaml:"restartCount"`
+	LastError      string     `json:"lastError,omitempty" yaml:"lastError,omitempty"`
+	ErrorCategory  string     `json:"errorCategory,omitempty" yaml:"errorCategory,omitempty"`
+	InCooldown     bool       `json:"inCooldown,omitempty" yaml:"inCooldown,omitempty"`
+	CooldownUntil  *time.Time `json:"cooldownUntil,omitempty" yaml:"cooldownUntil,omitempty"`
+	LatencyMs      float64    `json:"latencyMs,omitempty" yaml:"latencyMs,omitempty"`
+	LatencyP50Ms   float64    `json:"latencyP50Ms,omitempty" yaml:"latencyP50Ms,omitempty"`
+	LatencyP95Ms   float64    `json:"latencyP95Ms,omitempty" yaml:"latencyP95Ms,omitempty"`
+	Context        string     `json:"context,omitempty" yaml:"context,omitempty"`
+	Cluster        string     `json:"cluster,omitempty" yaml:"cluster,omitempty"`
+}
+
+// Summary counts services by state for cheap prompt rendering
+type Summary struct {
+	Total   int `json:"total" yaml:"total"`
+	Running int `json:"running" yaml:"running"`
+	Failed  int `json:"failed" yaml:"failed"`
+}
+
+// Document is the JSON written to the status file
+type Document struct {
+	Context   string                  `json:"context" yaml:"context"`
+	Summary   Summary                 `json:"summary" yaml:"summary"`
+	Services  map[string]ServiceEntry `json:"services" yaml:"services"`
+	UpdatedAt time.Time               `json:"updatedAt" yaml:"updatedAt"`
+}
+
+// Writer keeps a JSON status file in sync with the manager's status map
+type Writer struct {
+	path     string
+	provider ContextProvider
+	logger   *utils.Logger
+
+	// Content of the last written document, excluding the timestamp and latencies
+	last  []byte
+	mutex sync.Mutex
+}
+
+// NewWriter creates a status file writer for path (~ is expanded)
+func NewWriter(path string, provider ContextProvider, logger *utils.Logger) (*Writer, error) {
+	expanded, err := utils.ExpandPath(path)
+	if err != nil {
+		return nil, err
+	}
+	return &Writer{
+		path:     expanded,
+		provider: provider,
+		logger:   logger,
+	}, nil
+}
+
+// Path returns the resolved status file path
+func (w *Writer) Path() string {
+	return w.path
+}
+
+// HandleEvent rewrites the status file when a snapshot differs from the last one written
+func (w *Writer) HandleEvent(event portforward.Event) {
+	if event.Type != portforward.EventStatusUpdated {
+		return
+	}
+	if err := w.Write(event.Snapshot); err != nil {
+		w.logger.Warn("Failed to write status file: %v", err)
+	}
+}
+
+// Write writes the status file if the state changed since the last write. Latencies
+// change with every health check, so they are written along with other changes but
+// don't cause a write on their own.
+func (w *Writer) Write(statuses map[string]config.ServiceStatus) error {
+	doc := BuildDocument(w.provider.GetKubernetesContext(), statuses)
+
+	// Compare without the timestamp and latencies so unchanged ticks don't touch the file
+	content, err := json.Marshal(withoutLatencies(doc))
+	if err != nil {
+		return fmt.Errorf("failed to encode status: %w", err)
+	}
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if bytes.Equal(content, w.last) {
+		return nil
+	}
+
+	doc.UpdatedAt = time.Now()
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode status: %w", err)
+	}
+
+	if err := utils.WriteFileAtomic(w.path, append(data, '\n'), 0644); err != nil {
+		return err
+	}
+	w.last = content
+	return nil
+}
+
+// withoutLatencies returns a copy of doc with the latencies of its services cleared
+func withoutLatencies(doc Document) Document {
+	services := make(map[string]ServiceEntry, len(doc.Services))
+	for name, entry := range doc.Services {
+		entry.LatencyMs, entry.LatencyP50Ms, entry.LatencyP95Ms = 0, 0, 0
+		services[name] = entry
+	}
+	doc.Services = services
+	return doc
+}
+
+// Remove deletes the status file so readers don't see stale state after shutdown
+func (w *Writer) Remove() error {
+	if err := os.Remove(w.path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove status file: %w", err)
+	}
+	return nil
+}
+
+// BuildDocument converts a status map into the status file model, without a timestamp
+func BuildDocument(kubeContext string, statuses map[string]config.ServiceStatus) Document {
+	doc := Document{
+		Context:  kubeContext,
+		Services: make(map[string]ServiceEntry, len(statuses)),
+	}
+
+	for name, status := range statuses {
+		entry := ServiceEntry{
+			Status:         status.Status,
+			Type:           status.Type,
+			Namespace:      status.Namespace,
+			Target:         status.Target,
+			LocalPort:      status.LocalPort,
+			ConfiguredPort: status.ConfiguredPort,
+			PID:            status.PID,
+			RestartCount:   status.RestartCount,
+			LastError:      status.LastError,
+			ErrorCategory:  string(status.ErrorCategory),
+			InCooldown:     status.InCooldown,
+			LatencyMs:      utils.Milliseconds(status.Latency),
+			LatencyP50Ms:   utils.Milliseconds(status.LatencyP50),
+			LatencyP95Ms:   utils.Milliseconds(status.LatencyP95),
+			Context:        status.Context,
+			Cluster:        status.Cluster,
+		}
+		if !status.StartTime.IsZero() {
+			startTime := status.StartTime
+			entry.StartTime = &startTime
+		}
+		if status.InCooldown {
+			cooldownUntil := status.CooldownUntil
+			entry.CooldownUntil = &cooldownUntil
+		}
+		doc.Services[name] = entry
+
+		doc.Summary.Total++
+		switch status.Status {
+		case "Running":
+			doc.Summary.Running++
+		case "Failed":
+			doc.Summary.Failed++
+		}
+	}
+
+	return doc
+}
+
+// Read loads a status file written by a running instance (~ is expanded)
+func Read(path string) (Document, error) {
+	var doc Document
+	expanded, err := utils.ExpandPath(path)
+	if err != nil {
+		return doc, err
+	}
+	data, err := os.ReadFile(expanded)
+	if err != nil {
+		return doc, fmt.Errorf("failed to read status file: %w", err)
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return doc, fmt.Errorf("failed to parse status file %s: %w", expanded, err)
+	}
+	return doc, nil
+}
+
+// Statuses converts the document back into a status map
+func (d Document) Statuses() map[string]config.ServiceStatus {
+	statuses := make(map[string]config.ServiceStatus, len(d.Services))
+	for name, entry := range d.Services {
+		status := config.ServiceStatus{
+			Name:           name,
+			Status:         entry.Status,
+			Type:           entry.Type,
+			Namespace:      entry.Namespace,
+			Target:         entry.Target,
+			LocalPort:      entry.LocalPort,
+			ConfiguredPort: entry.ConfiguredPort,
+			PID:            entry.PID,
+			RestartCount:   entry.RestartCount,
+			LastError:      entry.LastError,
+			ErrorCategory:  config.ErrorCategory(entry.ErrorCategory),
+			InCooldown:     entry.InCooldown,
+			Context:        entry.Context,
+			Cluster:        entry.Cluster,
+		}
+		if entry.StartTime != nil {
+			status.StartTime = *entry.StartTime
+		}
+		if entry.CooldownUntil != nil {
+			status.CooldownUntil = *entry.CooldownUntil
+		}
+		statuses[name] = status
+	}
+	return statuses
+}
@@ -0,0 +1,128 @@
+package statusfile
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/portforward"
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+type staticContext string
+
+func (c staticContext) GetKubernetesContext() string {
+	return string(c)
+}
+
+func readDocument(t *testing.T, path string) Document {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read status file: %v", err)
+	}
+	var doc Document
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("Failed to parse status file: %v", err)
+	}
+	return doc
+}
+
+func TestWriterWritesOnChange(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "status.json")
+	writer, err := NewWriter(path, staticContext("dev"), utils.NewLogger(utils.LevelError))
+	if err != nil {
+		t.Fatalf("NewWriter failed: %v", err)
+	}
+
+	statuses := map[string]config.ServiceStatus{
+		"web": {Status: "Running", LocalPort: 8080, StartTime: time.Now()},
+		"api": {Status: "Failed", LocalPort: 8081, LastError: "connection refused"},
+	}
+	writer.HandleEvent(portforward.Event{Type: portforward.EventStatusUpdated, Snapshot: statuses})
+
+	doc := readDocument(t, path)
+	if doc.Context != "dev" {
+		t.Errorf("Expected context dev, got %s", doc.Context)
+	}
+	if doc.Summary != (Summary{Total: 2, Running: 1, Failed: 1}) {
+		t.Errorf("Unexpected summary: %+v", doc.Summary)
+	}
+	if doc.Services["api"].LastError != "connection refused" {
+		t.Errorf("Expected api error to be recorded, got %+v", doc.Services["api"])
+	}
+	if doc.Services["api"].StartTime != nil {
+		t.Error("Expected zero start time to be omitted")
+	}
+
+	// An identical snapshot must not rewrite the file
+	firstUpdate := doc.UpdatedAt
+	time.Sleep(10 * time.Millisecond)
+	writer.HandleEvent(portforward.Event{Type: portforward.EventStatusUpdated, Snapshot: statuses})
+	if doc := readDocument(t, path); !doc.UpdatedAt.Equal(firstUpdate) {
+		t.Error("Expected unchanged snapshot to leave the file untouched")
+	}
+
+	// A new latency sample alone doesn't rewrite the file either
+	web := statuses["web"]
+	web.Latency = 12 * time.Millisecond
+	statuses["web"] = web
+	writer.HandleEvent(portforward.Event{Type: portforward.EventStatusUpdated, Snapshot: statuses})
+	if doc := readDocument(t, path); !doc.UpdatedAt.Equal(firstUpdate) {
+		t.Error("Expected a latency change alone to leave the file untouched")
+	}
+
+	statuses["api"] = config.ServiceStatus{Status: "Running", LocalPort: 8081}
+	writer.HandleEvent(portforward.Event{Type: portforward.EventStatusUpdated, Snapshot: statuses})
+	doc = readDocument(t, path)
+	if doc.Summary.Running != 2 {
+		t.Errorf("Expected updated summary, got %+v", doc.Summary)
+	}
+	if doc.Services["web"].LatencyMs != 12 {
+		t.Errorf("Expected the latest latency to be written with the change, got %v", doc.Services["web"].LatencyMs)
+	}
+
+	// No temporary files are left behind
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 1 {
+		t.Errorf("Expected only the status file in the directory, got %d entries", len(entries))
+	}
+
+	if err := writer.Remove(); err != nil {
+		t.Fatalf("Remove failed: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("Expected status file to be removed")
+	}
+}
+
+func TestReadRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "status.json")
+	writer, err := NewWriter(path, staticContext("dev"), utils.NewLogger(utils.LevelError))
+	if err != nil {
+		t.Fatalf("NewWriter failed: %v", err)
+	}
+
+	started := time.Now().Add(-time.Hour).Truncate(time.Second)
+	if err := writer.Write(map[string]config.ServiceStatus{
+		"web": {Status: "Running", Type: "web", Namespace: "apps", Target: "service/web", LocalPort: 8080, StartTime: started},
+	}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	doc, err := Read(path)
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	web := doc.Statuses()["web"]
+	if web.Name != "web" || web.Namespace != "apps" || web.LocalPort != 8080 || !web.StartTime.Equal(started) {
+		t.Errorf("Unexpected status after round trip: %+v", web)
+	}
+
+	if _, err := Read(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("Expected an error for a missing status file")
+	}
+}
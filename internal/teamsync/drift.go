@@ -0,0 +1,57 @@
+package teamsync
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+)
+
+// Drift lists how the user config departs from the team config beneath it
+type Drift struct {
+	Overridden   []string // Team services the user config redefines differently
+	Redundant    []string // Team services the user config redefines identically
+	PersonalOnly []string // Services only the user config defines
+	Settings     []string // Team settings the user config overrides, by their yaml key
+}
+
+// ComputeDrift compares the user config with the team config
+func ComputeDrift(team, user *config.Config) Drift {
+	var drift Drift
+	for name, service := range user.PortForwards {
+		teamService, exists := team.PortForwards[name]
+		switch {
+		case !exists:
+			drift.PersonalOnly = append(drift.PersonalOnly, name)
+		case reflect.DeepEqual(teamService, service):
+			drift.Redundant = append(drift.Redundant, name)
+		default:
+			drift.Overridden = append(drift.Overridden, name)
+		}
+	}
+
+	// Any other setting both set, and set differently, is overridden by the user's
+	teamValue, userValue := reflect.ValueOf(*team), reflect.ValueOf(*user)
+	for i := 0; i < teamValue.NumField(); i++ {
+		field := teamValue.Type().Field(i)
+		key, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if key == "portForwards" || key == "sync" || key == "bundles" {
+			continue
+		}
+		teamField, userField := teamValue.Field(i), userValue.Field(i)
+		if !teamField.IsZero() && !userField.IsZero() && !reflect.DeepEqual(teamField.Interface(), userField.Interface()) {
+			drift.Settings = append(drift.Settings, key)
+		}
+	}
+
+	sort.Strings(drift.Overridden)
+	sort.Strings(drift.Redundant)
+	sort.Strings(drift.PersonalOnly)
+	return drift
+}
+
+// Empty reports whether the user config only adds to the team config
+func (d Drift) Empty() bool {
+	return len(d.Overridden) == 0 && len(d.Redundant) == 0 && len(d.Settings) == 0
+}
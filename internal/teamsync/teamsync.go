@@ -0,0 +1,182 @@
+// Package teamsync keeps a clone of the team's shared config repository, which the
+// config loader merges beneath the user config, and reports how the two differ.
+package teamsync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+)
+
+// Result describes a pull
+type Result struct {
+	Previous string             // Commit checked out before; "" for a fresh clone
+	Commit   string             // Commit checked out now
+	Changes  config.ServiceDiff // Team services the pull added, removed or changed
+}
+
+// Updated reports whether the pull checked out a different commit
+func (r Result) Updated() bool {
+	return r.Commit != r.Previous
+}
+
+// Pull clones the team config repository, or updates the clone to the latest commit
+// of the branch; local changes to the clone are discarded
+func Pull(ctx context.Context, sync config.SyncConfig) (Result, error) {
+	if sync.Repo == "" {
+		return Result{}, fmt.Errorf("no team config repository configured (sync.repo)")
+	}
+	dir, err := config.TeamConfigDir()
+	if err != nil {
+		return Result{}, err
+	}
+
+	before, err := config.LoadTeamConfig(sync)
+	if err != nil {
+		before = nil // A broken team config is what the pull may fix
+	}
+
+	var result Result
+	if cloned(dir) {
+		origin, _ := git(ctx, dir, "remote", "get-url", "origin")
+		if origin != sync.Repo {
+			// Pointed at another repository; start over
+			if err := os.RemoveAll(dir); err != nil {
+				return Result{}, fmt.Errorf("failed to remove the previous team config clone: %w", err)
+			}
+		} else {
+			result.Previous, _ = git(ctx, dir, "rev-parse", "HEAD")
+		}
+	}
+
+	if cloned(dir) {
+		if _, err := git(ctx, dir, "fetch", "--depth", "1", "origin", ref(sync)); err != nil {
+			return Result{}, err
+		}
+		if _, err := git(ctx, dir, "reset", "--hard", "FETCH_HEAD"); err != nil {
+			return Result{}, err
+		}
+	} else {
+		if err := os.MkdirAll(filepath.Dir(dir), 0755); err != nil {
+			return Result{}, fmt.Errorf("failed to create config directory: %w", err)
+		}
+		args := []string{"clone", "--depth", "1", "--single-branch"}
+		if sync.Branch != "" {
+			args = append(args, "--branch", sync.Branch)
+		}
+		if _, err := git(ctx, "", append(args, sync.Repo, dir)...); err != nil {
+			return Result{}, err
+		}
+	}
+
+	if result.Commit, err = git(ctx, dir, "rev-parse", "HEAD"); err != nil {
+		return Result{}, err
+	}
+	after, err := config.LoadTeamConfig(sync)
+	if err != nil {
+		return result, err
+	}
+	if after == nil {
+		path, _ := config.TeamConfigPath(sync)
+		return result, fmt.Errorf("the team config repository has no %s", filepath.Base(path))
+	}
+	result.Changes = config.DiffServices(services(before), after.PortForwards)
+	return result, nil
+}
+
+// Status describes the clone of the team config
+type Status struct {
+	Commit     string    // Commit checked out; "" when nothing has been pulled
+	CommitTime time.Time // When that commit was made
+}
+
+// CurrentStatus returns the state of the clone
+func CurrentStatus(ctx context.Context) (Status, error) {
+	dir, err := config.TeamConfigDir()
+	if err != nil {
+		return Status{}, err
+	}
+	if !cloned(dir) {
+		return Status{}, nil
+	}
+	output, err := git(ctx, dir, "log", "-1", "--format=%H %cI")
+	if err != nil {
+		return Status{}, err
+	}
+	commit, date, _ := strings.Cut(output, " ")
+	status := Status{Commit: commit}
+	status.CommitTime, _ = time.Parse(time.RFC3339, date)
+	return status, nil
+}
+
+// Upstream returns the latest commit of the branch in the repository
+func Upstream(ctx context.Context, sync config.SyncConfig) (string, error) {
+	pattern := "HEAD"
+	if sync.Branch != "" {
+		pattern = "refs/heads/" + sync.Branch
+	}
+	output, err := git(ctx, "", "ls-remote", sync.Repo, pattern)
+	if err != nil {
+		return "", err
+	}
+	commit, _, _ := strings.Cut(output, "\t")
+	if commit == "" {
+		return "", fmt.Errorf("branch %s not found in %s", sync.Branch, sync.Repo)
+	}
+	return commit, nil
+}
+
+// ShortCommit abbreviates a commit hash for display
+func ShortCommit(commit string) string {
+	if len(commit) > 7 {
+		return commit[:7]
+	}
+	return commit
+}
+
+// ref returns the ref to fetch for the configured branch
+func ref(sync config.SyncConfig) string {
+	if sync.Branch == "" {
+		return "HEAD"
+	}
+	return sync.Branch
+}
+
+// cloned reports whether dir holds a clone
+func cloned(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, ".git"))
+	return err == nil
+}
+
+// services returns a config's services, none for a nil config
+func services(cfg *config.Config) map[string]config.Service {
+	if cfg == nil {
+		return nil
+	}
+	return cfg.PortForwards
+}
+
+// git runs a git command in dir, failing instead of prompting for credentials
+func git(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return "", fmt.Errorf("git %s timed out", args[0])
+		}
+		if message := strings.TrimSpace(string(output)); message != "" {
+			return "", fmt.Errorf("git %s failed: %s", args[0], message)
+		}
+		return "", fmt.Errorf("git %s failed: %w", args[0], err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
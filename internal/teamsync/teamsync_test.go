@@ -0,0 +1,155 @@
+package teamsync
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+)
+
+// newUpstream creates a repository holding a team config and points the user
+// config directory at a temporary home
+func newUpstream(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("APPDATA", t.TempDir())
+
+	repo := t.TempDir()
+	run(t, repo, "init", "-q", "-b", "main")
+	commitConfig(t, repo, `portForwards:
+  api:
+    target: "service/api"
+    targetPort: 80
+    localPort: 8080
+    namespace: "default"
+`)
+	return repo
+}
+
+// commitConfig commits a new team config to the repository
+func commitConfig(t *testing.T, repo, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(repo, config.DefaultTeamConfigFile), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	run(t, repo, "add", ".")
+	run(t, repo, "-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "-m", "update")
+}
+
+func run(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %v: %v\n%s", args, err, output)
+	}
+}
+
+func TestPullClonesAndUpdates(t *testing.T) {
+	repo := newUpstream(t)
+	sync := config.SyncConfig{Repo: repo, Branch: "main"}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	result, err := Pull(ctx, sync)
+	if err != nil {
+		t.Fatalf("Pull() failed: %v", err)
+	}
+	if result.Previous != "" || result.Commit == "" || !reflect.DeepEqual(result.Changes.Added, []string{"api"}) {
+		t.Fatalf("first Pull() = %+v", result)
+	}
+
+	result, err = Pull(ctx, sync)
+	if err != nil || result.Updated() || !result.Changes.Empty() {
+		t.Fatalf("Pull() without changes = %+v, %v", result, err)
+	}
+
+	commitConfig(t, repo, `portForwards:
+  web:
+    target: "service/web"
+    targetPort: 80
+    localPort: 8081
+    namespace: "default"
+`)
+	result, err = Pull(ctx, sync)
+	if err != nil {
+		t.Fatalf("Pull() failed: %v", err)
+	}
+	if !result.Updated() || result.Changes.String() != "added web; removed api" {
+		t.Fatalf("Pull() after a commit = %+v", result)
+	}
+
+	upstream, err := Upstream(ctx, sync)
+	if err != nil || upstream != result.Commit {
+		t.Errorf("Upstream() = %q, %v; want %q", upstream, err, result.Commit)
+	}
+	status, err := CurrentStatus(ctx)
+	if err != nil || status.Commit != result.Commit || status.CommitTime.IsZero() {
+		t.Errorf("CurrentStatus() = %+v, %v", status, err)
+	}
+
+	team, err := config.LoadTeamConfig(sync)
+	if err != nil || team == nil {
+		t.Fatalf("LoadTeamConfig() = %v, %v", team, err)
+	}
+	if _, ok := team.PortForwards["web"]; !ok {
+		t.Errorf("team config not updated: %+v", team.PortForwards)
+	}
+}
+
+func TestPullMissingConfigFile(t *testing.T) {
+	repo := newUpstream(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if _, err := Pull(ctx, config.SyncConfig{Repo: repo, Path: "teams/payments.yaml"}); err == nil {
+		t.Error("Pull() without the config file should fail")
+	}
+}
+
+func TestComputeDrift(t *testing.T) {
+	api := config.Service{Target: "service/api", TargetPort: 80, LocalPort: 8080}
+	web := config.Service{Target: "service/web", TargetPort: 80, LocalPort: 8081}
+	team := &config.Config{
+		PortForwards:       map[string]config.Service{"api": api, "web": web},
+		MonitoringInterval: 5 * time.Second,
+		KubectlPath:        "kubectl",
+	}
+	movedWeb := web
+	movedWeb.LocalPort = 9081
+	user := &config.Config{
+		PortForwards: map[string]config.Service{
+			"api":     api,
+			"web":     movedWeb,
+			"scratch": {Target: "pod/scratch", TargetPort: 22, LocalPort: 2222},
+		},
+		MonitoringInterval: 10 * time.Second,
+		KubectlPath:        "kubectl",
+		StatusFile:         "/tmp/status.json",
+	}
+
+	drift := ComputeDrift(team, user)
+	want := Drift{
+		Overridden:   []string{"web"},
+		Redundant:    []string{"api"},
+		PersonalOnly: []string{"scratch"},
+		Settings:     []string{"monitoringInterval"},
+	}
+	if !reflect.DeepEqual(drift, want) {
+		t.Errorf("ComputeDrift() = %+v, want %+v", drift, want)
+	}
+	if drift.Empty() {
+		t.Error("Empty() = true")
+	}
+	if !ComputeDrift(team, &config.Config{PortForwards: map[string]config.Service{"other": api}}).Empty() {
+		t.Error("Empty() = false for a config that only adds services")
+	}
+}
@@ -0,0 +1,166 @@
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/portforward"
+	"github.com/victorkazakov/kportforward/internal/updater"
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+const instrumentationName = "github.com/victorkazakov/kportforward"
+
+// Tracer exports kportforward lifecycle activity as OpenTelemetry spans
+type Tracer struct {
+	provider *sdktrace.TracerProvider
+	tracer   trace.Tracer
+	logger   *utils.Logger
+}
+
+// NewTracer creates a tracer with the exporter selected in the telemetry config
+func NewTracer(ctx context.Context, cfg config.TelemetryConfig, version string, logger *utils.Logger) (*Tracer, error) {
+	exporter, err := newExporter(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	serviceName := cfg.ServiceName
+	if serviceName == "" {
+		serviceName = "kportforward"
+	}
+
+	res := resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(serviceName),
+		semconv.ServiceVersion(version),
+	)
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+
+	logger.Info("OpenTelemetry tracing enabled (exporter: %s)", exporterName(cfg))
+
+	return &Tracer{
+		provider: provider,
+		tracer:   provider.Tracer(instrumentationName),
+		logger:   logger,
+	}, nil
+}
+
+// newExporter builds the span exporter configured by the user
+func newExporter(ctx context.Context, cfg config.TelemetryConfig) (sdktrace.SpanExporter, error) {
+	switch exporterName(cfg) {
+	case "stdout":
+		return stdouttrace.New(stdouttrace.WithWriter(os.Stderr))
+	case "otlp":
+		// Without an explicit endpoint the standard OTEL_EXPORTER_OTLP_* variables apply
+		var opts []otlptracehttp.Option
+		if cfg.Endpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpoint(cfg.Endpoint))
+		}
+		if cfg.Insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		return otlptracehttp.New(ctx, opts...)
+	default:
+		return nil, fmt.Errorf("unsupported telemetry exporter: %s", cfg.Exporter)
+	}
+}
+
+// exporterName returns the configured exporter, defaulting to OTLP
+func exporterName(cfg config.TelemetryConfig) string {
+	if cfg.Exporter == "" {
+		return "otlp"
+	}
+	return cfg.Exporter
+}
+
+// HandleEvent records a manager lifecycle event as a span
+func (t *Tracer) HandleEvent(event portforward.Event) {
+	// Periodic snapshots and startup progress are not lifecycle operations
+	if event.Type == portforward.EventStatusUpdated || event.Type.IsProgress() {
+		return
+	}
+
+	start := event.Timestamp.Add(-event.Duration)
+
+	attrs := []attribute.KeyValue{
+		attribute.String("kportforward.event", string(event.Type)),
+	}
+	if event.Service != "" {
+		attrs = append(attrs,
+			attribute.String("kportforward.service", event.Service),
+			attribute.String("kportforward.status", event.Status.Status),
+			attribute.Int("kportforward.local_port", event.Status.LocalPort),
+			attribute.Int("kportforward.restart_count", event.Status.RestartCount),
+		)
+		if event.Status.LatencyP50 > 0 {
+			attrs = append(attrs,
+				attribute.Float64("kportforward.latency_p50_ms", utils.Milliseconds(event.Status.LatencyP50)),
+				attribute.Float64("kportforward.latency_p95_ms", utils.Milliseconds(event.Status.LatencyP95)),
+			)
+		}
+	}
+	if event.PreviousStatus != "" {
+		attrs = append(attrs, attribute.String("kportforward.previous_status", event.PreviousStatus))
+	}
+	if event.Type == portforward.EventContextChanged {
+		attrs = append(attrs,
+			attribute.String("kportforward.context", event.Context),
+			attribute.String("kportforward.previous_context", event.PreviousContext),
+		)
+	}
+
+	_, span := t.tracer.Start(context.Background(), string(event.Type),
+		trace.WithTimestamp(start),
+		trace.WithAttributes(attrs...),
+	)
+
+	if event.Error != "" || event.Status.Status == "Failed" {
+		span.SetStatus(codes.Error, event.Error)
+		span.AddEvent("failure", trace.WithTimestamp(event.Timestamp),
+			trace.WithAttributes(attribute.String("kportforward.error", event.Error)))
+	}
+
+	span.End(trace.WithTimestamp(event.Timestamp))
+}
+
+// RecordUpdateCheck records an update check as a span
+func (t *Tracer) RecordUpdateCheck(info *updater.UpdateInfo, err error, duration time.Duration) {
+	end := time.Now()
+	_, span := t.tracer.Start(context.Background(), "update.check",
+		trace.WithTimestamp(end.Add(-duration)),
+	)
+
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+	} else if info != nil {
+		span.SetAttributes(
+			attribute.Bool("kportforward.update_available", info.Available),
+			attribute.String("kportforward.current_version", info.CurrentVersion),
+			attribute.String("kportforward.latest_version", info.LatestVersion),
+		)
+	}
+
+	span.End(trace.WithTimestamp(end))
+}
+
+// Shutdown flushes pending spans and stops the exporter
+func (t *Tracer) Shutdown(ctx context.Context) error {
+	return t.provider.Shutdown(ctx)
+}
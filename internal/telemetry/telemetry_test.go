@@ -0,0 +1,83 @@
+package telemetry
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/portforward"
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+func newTestTracer() (*Tracer, *tracetest.InMemoryExporter) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	return &Tracer{
+		provider: provider,
+		tracer:   provider.Tracer(instrumentationName),
+		logger:   utils.NewLogger(utils.LevelError),
+	}, exporter
+}
+
+func TestHandleEventRecordsSpan(t *testing.T) {
+	tracer, exporter := newTestTracer()
+	defer tracer.Shutdown(context.Background())
+
+	tracer.HandleEvent(portforward.Event{
+		Type:      portforward.EventServiceRestarted,
+		Timestamp: time.Now(),
+		Service:   "api-gateway",
+		Status:    config.ServiceStatus{Status: "Running", LocalPort: 8080, RestartCount: 2},
+		Duration:  150 * time.Millisecond,
+	})
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("Expected 1 span, got %d", len(spans))
+	}
+
+	span := spans[0]
+	if span.Name != string(portforward.EventServiceRestarted) {
+		t.Errorf("Unexpected span name: %s", span.Name)
+	}
+	if got := span.EndTime.Sub(span.StartTime); got != 150*time.Millisecond {
+		t.Errorf("Expected span duration of 150ms, got %v", got)
+	}
+	if span.Status.Code == codes.Error {
+		t.Error("Successful restart should not be marked as error")
+	}
+}
+
+func TestHandleEventMarksFailures(t *testing.T) {
+	tracer, exporter := newTestTracer()
+	defer tracer.Shutdown(context.Background())
+
+	tracer.HandleEvent(portforward.Event{
+		Type:           portforward.EventServiceStateChanged,
+		Timestamp:      time.Now(),
+		Service:        "api-gateway",
+		Status:         config.ServiceStatus{Status: "Failed"},
+		PreviousStatus: "Running",
+		Error:          "Health check failed",
+	})
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("Expected 1 span, got %d", len(spans))
+	}
+	if spans[0].Status.Code != codes.Error {
+		t.Error("Failure transition should be marked as error")
+	}
+}
+
+func TestNewTracerRejectsUnknownExporter(t *testing.T) {
+	_, err := NewTracer(context.Background(), config.TelemetryConfig{Enabled: true, Exporter: "zipkin"}, "dev", utils.NewLogger(utils.LevelError))
+	if err == nil {
+		t.Error("Expected error for unsupported exporter")
+	}
+}
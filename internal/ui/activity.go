@@ -0,0 +1,126 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+)
+
+// maxActivityLines is how many activity lines are kept per service
+const maxActivityLines = 100
+
+// activityLine is a timestamped line in a service's activity tail
+type activityLine struct {
+	Time time.Time
+	Text string
+	Bad  bool // Rendered as an error
+}
+
+// recordActivity appends a line to a service's activity tail
+func (m *Model) recordActivity(service string, line activityLine) {
+	if m.activity == nil {
+		m.activity = make(map[string][]activityLine)
+	}
+	lines := append(m.activity[service], line)
+	if len(lines) > maxActivityLines {
+		lines = lines[len(lines)-maxActivityLines:]
+	}
+	m.activity[service] = lines
+}
+
+// recordStatusChanges turns the changed statuses of an update, compared with the
+// previous ones, into activity lines
+func (m *Model) recordStatusChanges(previous, current map[string]config.ServiceStatus) {
+	now := time.Now()
+	for name, status := range current {
+		before, known := previous[name]
+		if !known {
+			continue
+		}
+		if status.Status != before.Status {
+			m.recordActivity(name, activityLine{Time: now, Text: fmt.Sprintf("%s -> %s", before.Status, status.Status),
+				Bad: status.Status != "Running" && status.Status != "Starting"})
+		}
+		if status.RestartCount > before.RestartCount {
+			m.recordActivity(name, activityLine{Time: now, Text: fmt.Sprintf("restarted (restart #%d)", status.RestartCount)})
+		}
+		if status.LocalPort != before.LocalPort && status.LocalPort != 0 {
+			if portReassigned(status) {
+				m.recordActivity(name, activityLine{Time: now, Text: fmt.Sprintf("port %d in use, reassigned to %d",
+					status.ConfiguredPort, status.LocalPort), Bad: true})
+			} else if portAssigned(status) {
+				m.recordActivity(name, activityLine{Time: now, Text: fmt.Sprintf("assigned free port %d", status.LocalPort)})
+			} else {
+				m.recordActivity(name, activityLine{Time: now, Text: fmt.Sprintf("listening on port %d", status.LocalPort)})
+			}
+		}
+		if status.LastError != "" && status.LastError != before.LastError {
+			m.recordActivity(name, activityLine{Time: now, Text: "error: " + status.LastError, Bad: true})
+		}
+	}
+}
+
+// activityFor merges the activity tail of a service with its recent connections, oldest first
+func (m *Model) activityFor(serviceName string) []activityLine {
+	lines := append([]activityLine(nil), m.activity[serviceName]...)
+	for _, entry := range m.services[serviceName].RecentAccess {
+		lines = append(lines, activityLine{Time: entry.Time, Text: strings.TrimPrefix(entry.String(), entry.Time.Format("15:04:05")+" ")})
+	}
+	sort.SliceStable(lines, func(i, j int) bool { return lines[i].Time.Before(lines[j].Time) })
+	return lines
+}
+
+// splitPanelHeight is the number of lines the activity panel takes in split layout
+func (m *Model) splitPanelHeight() int {
+	height := m.height / 3
+	if height < 4 {
+		height = 4
+	}
+	return height
+}
+
+// renderActivityPanel renders the tail of the selected service's activity below the table
+func (m *Model) renderActivityPanel() string {
+	height := m.splitPanelHeight()
+	if len(m.serviceNames) == 0 || m.selectedIndex >= len(m.serviceNames) {
+		return titleStyle.Render("Activity")
+	}
+	serviceName := m.serviceNames[m.selectedIndex]
+
+	lines := []string{titleStyle.Render(fmt.Sprintf("Activity: %s", serviceName))}
+	activity := m.activityFor(serviceName)
+	if len(activity) > height-1 {
+		activity = activity[len(activity)-(height-1):]
+	}
+	if len(activity) == 0 {
+		lines = append(lines, helpStyle.Render("No activity since kportforward started"))
+	}
+	for _, line := range activity {
+		text := truncateString(fmt.Sprintf("%s %s", line.Time.Format("15:04:05"), line.Text), m.width-8)
+		if line.Bad {
+			text = errorMessageStyle.Render(text)
+		}
+		lines = append(lines, text)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// visibleRows returns the range of table rows to render so the selection stays in
+// view when at most limit rows fit; limit <= 0 shows every row
+func (m *Model) visibleRows(limit int) (first, last int) {
+	total := len(m.serviceNames)
+	if limit <= 0 || total <= limit {
+		return 0, total
+	}
+	first = m.selectedIndex - limit/2
+	if first < 0 {
+		first = 0
+	}
+	if first+limit > total {
+		first = total - limit
+	}
+	return first, first + limit
+}
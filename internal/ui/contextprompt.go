@@ -0,0 +1,73 @@
+package ui
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/victorkazakov/kportforward/internal/i18n"
+)
+
+// ContextChangePromptMsg asks whether to restart the services in the context kubectl
+// switched to, under contextPolicy ask
+type ContextChangePromptMsg struct {
+	Context  string
+	Previous string
+}
+
+// ContextChangeAnsweredMsg reports the outcome of answering a context change prompt
+type ContextChangeAnsweredMsg struct {
+	Context string
+	Switch  bool
+	Err     error
+}
+
+// handleContextPromptKey answers the pending context change prompt with y or n/esc;
+// other keys fall through to the view
+func (m *Model) handleContextPromptKey(msg tea.KeyMsg) (tea.Cmd, bool) {
+	var switchContext bool
+	switch msg.String() {
+	case "y", "Y":
+		switchContext = true
+	case "n", "N", "esc":
+	default:
+		return nil, false
+	}
+
+	prompt := m.contextPrompt
+	m.contextPrompt = nil
+	if m.controller == nil {
+		m.showNotice("answering context changes is not available", true)
+		return nil, true
+	}
+
+	controller := m.controller
+	return func() tea.Msg {
+		var err error
+		if switchContext {
+			err = controller.SwitchContext()
+		} else {
+			err = controller.KeepContext()
+		}
+		return ContextChangeAnsweredMsg{Context: prompt.Context, Switch: switchContext, Err: err}
+	}, true
+}
+
+// handleContextChangeAnswered reports what happened to the services
+func (m *Model) handleContextChangeAnswered(msg ContextChangeAnsweredMsg) {
+	switch {
+	case msg.Err != nil:
+		m.showNotice(msg.Err.Error(), true)
+	case msg.Switch:
+		m.showNotice("Restarting the services in "+msg.Context, false)
+	default:
+		m.showNotice("Keeping the services in "+m.kubeContext, false)
+	}
+}
+
+// renderContextPrompt asks whether to follow the kubectl context change
+func (m *Model) renderContextPrompt() string {
+	if m.contextPrompt == nil {
+		return ""
+	}
+	return errorMessageStyle.Render(i18n.T(
+		"kubectl switched from %s to %s: restart the services in %s? [y] Restart [n] Keep %s",
+		m.contextPrompt.Previous, m.contextPrompt.Context, m.contextPrompt.Context, m.contextPrompt.Previous))
+}
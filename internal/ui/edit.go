@@ -0,0 +1,220 @@
+package ui
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+// ServiceController applies service changes made in the TUI
+type ServiceController interface {
+	UpdateService(name string, service config.Service) error
+	SwapTarget(name, target string) (config.Service, error)
+	RestartService(name string) error
+	PauseService(name string) error  // Stop the service until it is resumed
+	ResumeService(name string) error // Start a paused service again
+	SwitchContext() error            // Answer a pending context change by restarting in the new context
+	KeepContext() error              // Answer a pending context change by keeping the current context
+	ServiceOutput(name string) ([]config.OutputLine, error)
+}
+
+// editableFields are the service settings that can be changed live; the yaml keys
+// are used when saving back to the user config
+var editableFields = []struct {
+	label string
+	key   string
+}{
+	{"Local Port", "localPort"},
+	{"Bind Address", "bindAddress"},
+	{"Priority", "priority"},
+}
+
+// editForm holds the values being edited for a service
+type editForm struct {
+	service string
+	values  []string
+	cursor  int
+	err     string
+	saving  bool
+}
+
+// ServiceUpdatedMsg reports the outcome of applying an edit
+type ServiceUpdatedMsg struct {
+	Name    string
+	Service config.Service
+	Applied bool
+	Saved   bool
+	Err     error
+}
+
+// startEdit opens the edit form for the selected service
+func (m *Model) startEdit(serviceName string) {
+	service := m.serviceConfigs[serviceName]
+	port := service.LocalPort
+	if status, ok := m.services[serviceName]; ok && status.ConfiguredPort != 0 {
+		port = status.ConfiguredPort
+	}
+	m.edit = &editForm{
+		service: serviceName,
+		values:  []string{strconv.Itoa(port), service.BindAddress, service.Priority},
+	}
+	m.viewMode = ViewEdit
+}
+
+// handleEditKeyPress handles keys in the edit form
+func (m *Model) handleEditKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	form := m.edit
+	if form == nil || form.saving {
+		if msg.String() == "ctrl+c" {
+			return m, tea.Quit
+		}
+		return m, nil
+	}
+
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		return m, tea.Quit
+	case tea.KeyEsc:
+		m.edit = nil
+		m.viewMode = ViewDetail
+	case tea.KeyUp, tea.KeyShiftTab:
+		form.cursor = (form.cursor + len(editableFields) - 1) % len(editableFields)
+	case tea.KeyDown, tea.KeyTab:
+		form.cursor = (form.cursor + 1) % len(editableFields)
+	case tea.KeyBackspace:
+		if value := form.values[form.cursor]; len(value) > 0 {
+			form.values[form.cursor] = value[:len(value)-1]
+		}
+	case tea.KeyEnter:
+		return m, m.applyEdit(false)
+	case tea.KeyCtrlS:
+		return m, m.applyEdit(true)
+	case tea.KeyRunes, tea.KeySpace:
+		form.values[form.cursor] += string(msg.Runes)
+	}
+	return m, nil
+}
+
+// applyEdit validates the form and applies it in the background, optionally saving it to the user config
+func (m *Model) applyEdit(save bool) tea.Cmd {
+	form := m.edit
+	service, err := editedService(m.serviceConfigs[form.service], form.values)
+	if err != nil {
+		form.err = err.Error()
+		return nil
+	}
+	if m.controller == nil {
+		form.err = "live editing is not available"
+		return nil
+	}
+
+	form.err = ""
+	form.saving = true
+	controller, name := m.controller, form.service
+	return func() tea.Msg {
+		if err := controller.UpdateService(name, service); err != nil {
+			return ServiceUpdatedMsg{Name: name, Err: err}
+		}
+		if save {
+			keys := make([]string, len(editableFields))
+			for i, field := range editableFields {
+				keys[i] = field.key
+			}
+			if err := config.SaveService(name, service, keys...); err != nil {
+				return ServiceUpdatedMsg{Name: name, Service: service, Applied: true,
+					Err: fmt.Errorf("applied, but saving failed: %w", err)}
+			}
+		}
+		return ServiceUpdatedMsg{Name: name, Service: service, Applied: true, Saved: save}
+	}
+}
+
+// handleServiceUpdated closes the edit form once the change was applied
+func (m *Model) handleServiceUpdated(msg ServiceUpdatedMsg) {
+	if msg.Applied {
+		m.serviceConfigs[msg.Name] = msg.Service
+	}
+	if m.edit == nil || m.edit.service != msg.Name {
+		return
+	}
+	m.edit.saving = false
+	if msg.Err != nil {
+		m.edit.err = msg.Err.Error()
+		return
+	}
+
+	m.edit = nil
+	m.viewMode = ViewDetail
+	note := "settings changed, restarted"
+	if msg.Saved {
+		note += " and saved to the user config"
+	}
+	m.recordActivity(msg.Name, activityLine{Time: time.Now(), Text: note})
+}
+
+// editedService applies the form values to a copy of the service
+func editedService(service config.Service, values []string) (config.Service, error) {
+	port, err := strconv.Atoi(strings.TrimSpace(values[0]))
+	if err != nil || port < 0 || port > 65535 {
+		return service, fmt.Errorf("local port must be a number between 1 and 65535, or 0 for any free port")
+	}
+
+	addresses := utils.SplitAddresses(values[1])
+	for _, address := range addresses {
+		if address != "localhost" && net.ParseIP(address) == nil {
+			return service, fmt.Errorf("bind addresses must be IP addresses or localhost, separated by commas")
+		}
+	}
+	bindAddress := strings.Join(addresses, ",")
+
+	priority := strings.TrimSpace(values[2])
+	switch priority {
+	case "", "critical", "high", "normal", "low":
+	default:
+		return service, fmt.Errorf("priority must be critical, high, normal or low")
+	}
+
+	service.LocalPort = port
+	service.BindAddress = bindAddress
+	service.Priority = priority
+	return service, nil
+}
+
+// renderEditView renders the edit form
+func (m *Model) renderEditView() string {
+	form := m.edit
+	lines := []string{
+		titleStyle.Render(fmt.Sprintf("Edit Service: %s", form.service)),
+		"",
+	}
+	for i, field := range editableFields {
+		line := fmt.Sprintf("%-14s %s", field.label+":", form.values[i])
+		if i == form.cursor {
+			line = tableSelectedRowStyle.Render(line + "_")
+		}
+		lines = append(lines, line)
+	}
+
+	if form.saving {
+		lines = append(lines, "", helpStyle.Render("Restarting service..."))
+	}
+	if form.err != "" {
+		lines = append(lines, "", errorMessageStyle.Render(form.err))
+	}
+
+	lines = append(lines,
+		"",
+		helpStyle.Render("[↑↓/Tab] Field  [Enter] Apply  [Ctrl+S] Apply and save to config  [ESC] Cancel"),
+	)
+
+	return containerStyle.
+		Width(m.width - 4).
+		Height(m.height - 2).
+		Render(strings.Join(lines, "\n"))
+}
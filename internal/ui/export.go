@@ -0,0 +1,76 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/victorkazakov/kportforward/internal/export"
+)
+
+// noticeDuration is how long a notice stays above the footer
+const noticeDuration = 5 * time.Second
+
+// StatusExportedMsg reports where the service table was exported to
+type StatusExportedMsg struct {
+	Path string
+	Err  error
+}
+
+// exportTable writes the service table, in the current sort order, to a file in the
+// working directory so it can be pasted into standup notes or incident docs
+func (m *Model) exportTable(format string) tea.Cmd {
+	byName := make(map[string]export.Row, len(m.services))
+	for _, row := range export.Rows(m.services, m.serviceConfigs, time.Now()) {
+		byName[row.Name] = row
+	}
+	rows := make([]export.Row, 0, len(m.serviceNames))
+	for _, name := range m.serviceNames {
+		rows = append(rows, byName[name])
+	}
+
+	extension := map[string]string{"markdown": "md", "csv": "csv"}[format]
+	path := fmt.Sprintf("kportforward-status-%s.%s", time.Now().Format("20060102-150405"), extension)
+	return func() tea.Msg {
+		output, err := export.Render(format, rows)
+		if err != nil {
+			return StatusExportedMsg{Err: err}
+		}
+		if err := os.WriteFile(path, []byte(output), 0644); err != nil {
+			return StatusExportedMsg{Err: fmt.Errorf("failed to export status: %w", err)}
+		}
+		if absolute, err := filepath.Abs(path); err == nil {
+			path = absolute
+		}
+		return StatusExportedMsg{Path: path}
+	}
+}
+
+// handleStatusExported shows where the export was written
+func (m *Model) handleStatusExported(msg StatusExportedMsg) {
+	if msg.Err != nil {
+		m.showNotice(msg.Err.Error(), true)
+		return
+	}
+	m.showNotice(fmt.Sprintf("Status table exported to %s", msg.Path), false)
+}
+
+// showNotice shows a message above the footer for a few seconds
+func (m *Model) showNotice(notice string, bad bool) {
+	m.notice = notice
+	m.noticeBad = bad
+	m.noticeUntil = time.Now().Add(noticeDuration)
+}
+
+// renderNotice renders the current notice, or "" once it expired
+func (m *Model) renderNotice() string {
+	if m.notice == "" || time.Now().After(m.noticeUntil) {
+		return ""
+	}
+	if m.noticeBad {
+		return errorMessageStyle.Render(m.notice)
+	}
+	return helpStyle.Render(m.notice)
+}
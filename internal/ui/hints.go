@@ -0,0 +1,36 @@
+package ui
+
+import (
+	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/i18n"
+)
+
+// remediationHint suggests what to do about a service's failure, based on its error category
+func remediationHint(status config.ServiceStatus) string {
+	context := status.Context
+	if context == "" {
+		context = i18n.T("the current context")
+	}
+
+	switch status.ErrorCategory {
+	case config.ErrorLostConnection:
+		return i18n.T("The pod restarted or was rescheduled; the forward reconnects to a new pod automatically")
+	case config.ErrorConnectionRefused:
+		return i18n.T("Nothing listens on port %d in the pod; check the targetPort and that the app has started", status.ConfiguredPort)
+	case config.ErrorPortInUse:
+		return i18n.T("Local port %d is taken; a free port is picked on the next restart, or stop whatever uses it", status.ConfiguredPort)
+	case config.ErrorClusterUnreachable:
+		return i18n.T("The API server can't be reached; check your VPN or network, forwards resume once it answers")
+	case config.ErrorUnauthorized:
+		return i18n.T("Your credentials for %s are missing or expired; log in to the cluster again", context)
+	case config.ErrorForbidden:
+		return i18n.T("RBAC denies the forward; run: kubectl auth can-i create pods/portforward -n %s", status.Namespace)
+	case config.ErrorNamespaceNotFound:
+		return i18n.T("Namespace %s doesn't exist in %s; check that you are on the right context", status.Namespace, context)
+	case config.ErrorUpgradeFailed:
+		return i18n.T("The API server couldn't open a stream to the pod; its node may be unreachable, or a proxy in between blocks connection upgrades")
+	case config.ErrorNotFound:
+		return i18n.T("%s doesn't exist in namespace %s; check the target name or whether it was deleted", status.Target, status.Namespace)
+	}
+	return ""
+}
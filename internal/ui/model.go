@@ -8,7 +8,10 @@ import (
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/victorkazakov/kportforward/internal/clusterwatch"
 	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/i18n"
+	"github.com/victorkazakov/kportforward/internal/updater"
 	"github.com/victorkazakov/kportforward/internal/utils"
 )
 
@@ -21,14 +24,18 @@ const (
 	SortByType
 	SortByPort
 	SortByUptime
+	SortByRestarts // Most restarts first
+	SortByErrors   // Services with an error first
 )
 
 var sortFieldNames = map[SortField]string{
-	SortByName:   "Name",
-	SortByStatus: "Status",
-	SortByType:   "Type",
-	SortByPort:   "Port",
-	SortByUptime: "Uptime",
+	SortByName:     "Name",
+	SortByStatus:   "Status",
+	SortByType:     "Type",
+	SortByPort:     "Port",
+	SortByUptime:   "Uptime",
+	SortByRestarts: "Restarts",
+	SortByErrors:   "Errors",
 }
 
 // ViewMode represents different view modes
@@ -37,6 +44,16 @@ type ViewMode int
 const (
 	ViewTable ViewMode = iota
 	ViewDetail
+	ViewEdit
+)
+
+const (
+	// maxDetailAccessEntries is how many recent connections the detail view shows
+	maxDetailAccessEntries = 10
+
+	// maxClusterEvents is how many cluster events are kept; panelClusterEvents are shown below the table
+	maxClusterEvents   = 50
+	panelClusterEvents = 5
 )
 
 // Model represents the main TUI model
@@ -48,6 +65,38 @@ type Model struct {
 	kubeContext     string
 	lastUpdate      time.Time
 	updateAvailable bool
+	updateProgress  *updater.Progress // Set while the update downloads
+	downloader      func() (string, error)
+	clusterEvents   []clusterwatch.Event // Most recent last
+	startupProgress StartupProgressMsg
+
+	// Live editing of the selected service
+	controller ServiceController
+	reloader   ConfigReloader
+	edit       *editForm
+	output     *outputViewer // Process output of the detail view's service, opened with l
+
+	// Profiles cycled with P; the empty profile runs every service
+	profiles        []string
+	activeProfile   string
+	profileSwitcher ProfileSwitcher
+
+	// Context change waiting for an answer under contextPolicy ask
+	contextPrompt *ContextChangePromptMsg
+
+	// Split layout: the selected service's activity tails below the table
+	splitView bool
+	activity  map[string][]activityLine
+
+	// Preferences restored on launch and saved when they change
+	prefsPath        string
+	savedPrefs       Preferences
+	pendingSelection string // Service to select once it appears
+
+	// Transient message shown above the footer, e.g. after an export
+	notice      string
+	noticeBad   bool
+	noticeUntil time.Time
 
 	// UI state
 	selectedIndex int
@@ -56,17 +105,25 @@ type Model struct {
 	viewMode      ViewMode
 
 	// Display settings
-	width       int
-	height      int
-	refreshRate time.Duration
+	width        int
+	height       int
+	refreshRate  time.Duration
+	lastActivity time.Time // Last key press, status change or other event; the tick slows down without one
 
 	// Channels
-	statusChan  <-chan map[string]config.ServiceStatus
+	statusChan  <-chan config.StatusUpdate
 	contextChan <-chan string
 }
 
-// StatusUpdateMsg represents a status update message
-type StatusUpdateMsg map[string]config.ServiceStatus
+// StatusUpdateMsg carries the services whose status changed
+type StatusUpdateMsg config.StatusUpdate
+
+// StartupProgressMsg reports how many services have been started (or restarted) so far
+type StartupProgressMsg struct {
+	Started    int
+	Total      int
+	Restarting bool // Restarting after a context change rather than starting up
+}
 
 // ContextUpdateMsg represents a context change message
 type ContextUpdateMsg string
@@ -74,14 +131,30 @@ type ContextUpdateMsg string
 // UpdateAvailableMsg represents an update notification
 type UpdateAvailableMsg bool
 
+// ClusterEventMsg represents a notable event in the pods behind a service
+type ClusterEventMsg clusterwatch.Event
+
 // TickMsg represents a timer tick
 type TickMsg time.Time
 
+const (
+	// idleRefreshRate is the tick once the screen has been idle for idleAfter; it only
+	// keeps uptimes and countdowns current, since updates and keys redraw right away
+	idleRefreshRate = 2 * time.Second
+	idleAfter       = 5 * time.Second
+)
+
 // NewModel creates a new TUI model
-func NewModel(statusChan <-chan map[string]config.ServiceStatus, serviceConfigs map[string]config.Service) *Model {
-	return &Model{
+func NewModel(statusChan <-chan config.StatusUpdate, serviceConfigs map[string]config.Service) *Model {
+	// Copied because live edits update it while other components read the original
+	configs := make(map[string]config.Service, len(serviceConfigs))
+	for name, service := range serviceConfigs {
+		configs[name] = service
+	}
+
+	m := &Model{
 		services:       make(map[string]config.ServiceStatus),
-		serviceConfigs: serviceConfigs,
+		serviceConfigs: configs,
 		serviceNames:   make([]string, 0),
 		selectedIndex:  0,
 		sortField:      SortByName,
@@ -90,18 +163,30 @@ func NewModel(statusChan <-chan map[string]config.ServiceStatus, serviceConfigs
 		refreshRate:    250 * time.Millisecond,
 		statusChan:     statusChan,
 	}
+
+	if path, err := preferencesPath(); err == nil {
+		m.prefsPath = path
+		m.applyPreferences(loadPreferences(path))
+	}
+	return m
 }
 
 // Init initializes the model
 func (m *Model) Init() tea.Cmd {
 	return tea.Batch(
-		m.listenForStatusUpdates(),
+		m.waitForStatusUpdate(),
 		m.tickEvery(),
 	)
 }
 
 // Update handles messages and updates the model
 func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg.(type) {
+	case TickMsg, StatusUpdateMsg:
+	default:
+		m.lastActivity = time.Now() // Status updates only count when something changed
+	}
+
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
@@ -109,9 +194,19 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case StatusUpdateMsg:
-		m.services = map[string]config.ServiceStatus(msg)
+		update := config.StatusUpdate(msg)
+		if statusChanged(m.services, update) {
+			m.lastActivity = time.Now()
+		}
+		m.recordStatusChanges(m.services, update.Changed)
+		m.services = update.Apply(m.services)
 		m.updateServiceNames()
+		m.restoreSelection()
 		m.lastUpdate = time.Now()
+		return m, m.waitForStatusUpdate()
+
+	case StartupProgressMsg:
+		m.startupProgress = msg
 		return m, nil
 
 	case ContextUpdateMsg:
@@ -122,11 +217,64 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.updateAvailable = bool(msg)
 		return m, nil
 
+	case ClusterEventMsg:
+		m.recordActivity(msg.Service, activityLine{Time: msg.Time, Text: fmt.Sprintf("%s (pod %s): %s", msg.Reason, msg.Pod, msg.Message), Bad: true})
+		m.clusterEvents = append(m.clusterEvents, clusterwatch.Event(msg))
+		if len(m.clusterEvents) > maxClusterEvents {
+			m.clusterEvents = m.clusterEvents[len(m.clusterEvents)-maxClusterEvents:]
+		}
+		return m, nil
+
+	case ContextChangePromptMsg:
+		m.contextPrompt = &msg
+		return m, nil
+
+	case ContextChangeAnsweredMsg:
+		m.handleContextChangeAnswered(msg)
+		return m, nil
+
+	case ServiceUpdatedMsg:
+		m.handleServiceUpdated(msg)
+		return m, nil
+
+	case StatusExportedMsg:
+		m.handleStatusExported(msg)
+		return m, nil
+
+	case TargetSwappedMsg:
+		m.handleTargetSwapped(msg)
+		return m, nil
+
+	case ServiceRestartedMsg:
+		m.handleServiceRestarted(msg)
+		return m, nil
+
+	case ServicePausedMsg:
+		m.handleServicePaused(msg)
+		return m, nil
+
+	case ConfigReloadedMsg:
+		m.handleConfigReloaded(msg)
+		return m, nil
+
+	case ProfileSwitchedMsg:
+		m.handleProfileSwitched(msg)
+		return m, nil
+
+	case UpdateProgressMsg:
+		if m.updateProgress != nil {
+			progress := updater.Progress(msg)
+			m.updateProgress = &progress
+		}
+		return m, nil
+
+	case UpdateDownloadedMsg:
+		m.handleUpdateDownloaded(msg)
+		return m, nil
+
 	case TickMsg:
-		return m, tea.Batch(
-			m.listenForStatusUpdates(),
-			m.tickEvery(),
-		)
+		m.refreshOutput()
+		return m, m.tickEvery()
 
 	case tea.KeyMsg:
 		return m.handleKeyPress(msg)
@@ -143,7 +291,12 @@ func (m *Model) View() string {
 
 	switch m.viewMode {
 	case ViewDetail:
+		if m.output != nil {
+			return m.renderOutputView()
+		}
 		return m.renderDetailView()
+	case ViewEdit:
+		return m.renderEditView()
 	default:
 		return m.renderTableView()
 	}
@@ -151,11 +304,38 @@ func (m *Model) View() string {
 
 // handleKeyPress processes keyboard input
 func (m *Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.contextPrompt != nil && m.viewMode != ViewEdit {
+		if cmd, handled := m.handleContextPromptKey(msg); handled {
+			return m, cmd
+		}
+	}
+
 	switch m.viewMode {
 	case ViewDetail:
+		if m.output != nil {
+			return m.handleOutputKeyPress(msg)
+		}
 		return m.handleDetailKeyPress(msg)
+	case ViewEdit:
+		return m.handleEditKeyPress(msg)
 	default:
-		return m.handleTableKeyPress(msg)
+		model, cmd := m.handleTableKeyPress(msg)
+		m.persistPreferences()
+		return model, cmd
+	}
+}
+
+// restoreSelection selects the service saved in the preferences once it is known
+func (m *Model) restoreSelection() {
+	if m.pendingSelection == "" {
+		return
+	}
+	for i, name := range m.serviceNames {
+		if name == m.pendingSelection {
+			m.selectedIndex = i
+			m.pendingSelection = ""
+			return
+		}
 	}
 }
 
@@ -199,9 +379,47 @@ func (m *Model) handleTableKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.sortField = SortByUptime
 		m.updateServiceNames()
 
+	case "c":
+		m.sortField = SortByRestarts
+		m.updateServiceNames()
+
+	case "e":
+		m.sortField = SortByErrors
+		m.updateServiceNames()
+
 	case "r":
 		m.sortReverse = !m.sortReverse
 		m.updateServiceNames()
+
+	case "l":
+		m.splitView = !m.splitView
+
+	case "x":
+		return m, m.exportTable("markdown")
+
+	case "X":
+		return m, m.exportTable("csv")
+
+	case "w":
+		return m, m.swapSelectedTarget()
+
+	case "R":
+		return m, m.restartSelected()
+
+	case "-":
+		return m, m.pauseSelected(true)
+
+	case "+", "=":
+		return m, m.pauseSelected(false)
+
+	case "f5", "ctrl+l":
+		return m, m.reloadConfig()
+
+	case "P":
+		return m, m.switchToNextProfile()
+
+	case "U":
+		return m, m.downloadUpdate()
 	}
 
 	return m, nil
@@ -216,6 +434,28 @@ func (m *Model) handleDetailKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case "esc", "backspace":
 		m.viewMode = ViewTable
 		return m, nil
+
+	case "e":
+		if m.selectedIndex < len(m.serviceNames) {
+			m.startEdit(m.serviceNames[m.selectedIndex])
+		}
+		return m, nil
+
+	case "w":
+		return m, m.swapSelectedTarget()
+
+	case "R":
+		return m, m.restartSelected()
+
+	case "-":
+		return m, m.pauseSelected(true)
+
+	case "+", "=":
+		return m, m.pauseSelected(false)
+
+	case "l":
+		m.toggleOutput()
+		return m, nil
 	}
 
 	return m, nil
@@ -232,15 +472,28 @@ func (m *Model) renderTableView() string {
 	// Footer
 	footer := m.renderFooter()
 
+	parts := []string{header, "", table, ""}
+	if m.splitView {
+		parts = append(parts, m.renderActivityPanel(), "")
+	} else if panel := m.renderClusterEvents(); panel != "" {
+		parts = append(parts, panel, "")
+	}
+	if prompt := m.renderContextPrompt(); prompt != "" {
+		parts = append(parts, prompt)
+	}
+	if hint := m.renderLoginHint(); hint != "" {
+		parts = append(parts, hint)
+	}
+	if hint := m.renderUnreachableHint(); hint != "" {
+		parts = append(parts, hint)
+	}
+	if notice := m.renderNotice(); notice != "" {
+		parts = append(parts, notice)
+	}
+	parts = append(parts, footer)
+
 	// Combine all parts
-	content := lipgloss.JoinVertical(
-		lipgloss.Left,
-		header,
-		"",
-		table,
-		"",
-		footer,
-	)
+	content := lipgloss.JoinVertical(lipgloss.Left, parts...)
 
 	return containerStyle.
 		Width(m.width - 4).
@@ -262,30 +515,134 @@ func (m *Model) renderDetailView() string {
 
 	// Service details
 	details := []string{
-		titleStyle.Render(fmt.Sprintf("Service Details: %s", serviceName)),
+		titleStyle.Render(i18n.T("Service Details: %s", serviceName)),
 		"",
-		fmt.Sprintf("Status: %s %s", GetStatusIndicator(service.Status), service.Status),
-		fmt.Sprintf("Local Port: %d", service.LocalPort),
+		fmt.Sprintf("Status: %s %s", GetStatusIndicator(service.Status), formatDetailStatus(service)),
+		fmt.Sprintf("Target: %s", formatTarget(service)),
+		fmt.Sprintf("Type: %s", formatDetailType(service)),
+		fmt.Sprintf("Local Port: %s", formatLocalPort(service)),
 		fmt.Sprintf("Process ID: %d", service.PID),
 		fmt.Sprintf("Restart Count: %d", service.RestartCount),
 	}
+	for _, port := range service.Ports {
+		details = append(details, fmt.Sprintf("Port %s: %s", formatPortName(port), formatPortStatus(port)))
+	}
+
+	if service.Context != "" {
+		details = append(details, fmt.Sprintf("Context: %s (cluster %s)", service.Context, service.Cluster))
+	}
+	if service.Wildcard != "" {
+		details = append(details, fmt.Sprintf("Wildcard: matched by %s", service.Wildcard))
+	}
+	if alternates := m.serviceConfigs[serviceName].AlternateTargets; len(alternates) > 0 {
+		details = append(details, fmt.Sprintf("Alternate Targets: %s ([w] to swap)", strings.Join(alternates, ", ")))
+	}
+	if addresses := m.serviceConfigs[serviceName].BindAddresses(); len(addresses) > 1 && service.Status == "Running" {
+		details = append(details, fmt.Sprintf("Listening On: %s", strings.Join(m.serviceConfigs[serviceName].LocalURLs(service.LocalPort), ", ")))
+	}
+	if m.serviceConfigs[serviceName].IsUDP() {
+		details = append(details, "Protocol: UDP, relayed through a socat pod in the namespace")
+	}
+	if kubeconfig := m.serviceConfigs[serviceName].Kubeconfig; kubeconfig != "" {
+		details = append(details, fmt.Sprintf("Kubeconfig: %s", kubeconfig))
+	}
+
+	if priority := m.serviceConfigs[serviceName].Priority; priority != "" {
+		details = append(details, fmt.Sprintf("Priority: %s", priority))
+	}
+	if m.serviceConfigs[serviceName].Pinned {
+		details = append(details, "Pinned: retries without cooldown")
+	}
 
 	if !service.StartTime.IsZero() {
 		uptime := time.Since(service.StartTime)
 		details = append(details, fmt.Sprintf("Uptime: %s", utils.FormatUptime(uptime)))
 	}
 
+	if percent := service.Availability.String(); percent != "" {
+		tracked := service.Availability.Up + service.Availability.Down
+		details = append(details, fmt.Sprintf("Availability: %s over %s", percent, utils.FormatUptime(tracked)))
+	}
+	if timeline := service.Availability.Timeline; len(timeline) > 0 {
+		details = append(details, fmt.Sprintf("Health (last %dm): %s", len(timeline), formatHealthTimeline(timeline)))
+	}
+
+	if service.Latency > 0 {
+		details = append(details, fmt.Sprintf("Latency: %s (p50 %s, p95 %s)",
+			utils.FormatLatency(service.Latency),
+			utils.FormatLatency(service.LatencyP50),
+			utils.FormatLatency(service.LatencyP95)))
+	}
+	if service.HealthCheckTime > 0 {
+		passed := fmt.Sprintf("passed in %s", utils.FormatLatency(service.HealthCheckTime))
+		if check := m.serviceConfigs[serviceName].HealthCheck; check != nil {
+			passed = fmt.Sprintf("%s, %s", check, passed)
+		}
+		details = append(details, fmt.Sprintf("Health Check: %s", passed))
+	}
+
 	if service.LastError != "" {
 		details = append(details,
 			"",
-			"Last Error:",
+			i18n.T("Last Error:"),
 			errorMessageStyle.Render(service.LastError),
 		)
+		if category := service.ErrorCategory; category != "" {
+			kind := i18n.T("transient, should recover on its own")
+			if category.Fatal() {
+				kind = i18n.T("needs fixing")
+			}
+			details = append(details, i18n.T("Error Type: %s (%s)", category, kind))
+		}
+		if hint := remediationHint(service); hint != "" {
+			details = append(details, helpStyle.Render(i18n.T("Next step: %s", hint)))
+		}
+	}
+
+	if len(service.RecentErrors) > 0 {
+		details = append(details, "", formatErrorHistoryTitle(service.RecentErrors))
+		for i := len(service.RecentErrors) - 1; i >= 0; i-- {
+			entry := service.RecentErrors[i]
+			line := fmt.Sprintf("%s %s", entry.Time.Format("15:04:05"), entry.Message)
+			details = append(details, helpStyle.Render(truncateString(line, m.width-8)))
+		}
+	}
+
+	var serviceEvents []string
+	for _, event := range m.clusterEvents {
+		if event.Service == serviceName {
+			serviceEvents = append(serviceEvents, fmt.Sprintf("%s %s: %s", event.Time.Format("15:04:05"), event.Reason, event.Message))
+		}
+	}
+	if len(serviceEvents) > 0 {
+		if len(serviceEvents) > panelClusterEvents {
+			serviceEvents = serviceEvents[len(serviceEvents)-panelClusterEvents:]
+		}
+		details = append(details, "", "Cluster Events:")
+		for _, line := range serviceEvents {
+			details = append(details, errorMessageStyle.Render(truncateString(line, m.width-8)))
+		}
+	}
+
+	if m.serviceConfigs[serviceName].AccessLog {
+		details = append(details, "", "Recent Connections:")
+		recent := service.RecentAccess
+		if len(recent) > maxDetailAccessEntries {
+			recent = recent[len(recent)-maxDetailAccessEntries:]
+		}
+		if len(recent) == 0 {
+			details = append(details, helpStyle.Render("No connections yet"))
+		}
+		for _, entry := range recent {
+			details = append(details, truncateString(entry.String(), m.width-8))
+		}
 	}
 
 	details = append(details,
 		"",
-		helpStyle.Render("[ESC] Back to table view  [q] Quit"),
+		helpStyle.Render(strings.Join([]string{
+			i18n.T("[e] Edit"), i18n.T("[w] Swap target"), i18n.T("[R] Restart"), i18n.T("[-/+] Pause/Resume"), i18n.T("[l] Output"), i18n.T("[ESC] Back to table view"), i18n.T("[q] Quit"),
+		}, "  ")),
 	)
 
 	content := strings.Join(details, "\n")
@@ -302,12 +659,22 @@ func (m *Model) renderHeader() string {
 
 	context := ""
 	if m.kubeContext != "" {
-		context = contextStyle.Render(fmt.Sprintf("Context: %s", m.kubeContext))
+		context = contextStyle.Render(i18n.T("Context: %s", m.kubeContext))
+	}
+	if others := m.otherContexts(); len(others) > 0 {
+		context += contextStyle.Render(fmt.Sprintf(" (+%s)", strings.Join(others, ", ")))
+	}
+	if len(m.profiles) > 0 {
+		profile := m.activeProfile
+		if profile == "" {
+			profile = i18n.T("all")
+		}
+		context += contextStyle.Render("  " + i18n.T("Profile: %s", profile))
 	}
 
 	updateNotice := ""
-	if m.updateAvailable {
-		updateNotice = lipgloss.NewStyle().Foreground(warningColor).Render("Update Available!")
+	if notice := m.renderUpdateNotice(); notice != "" {
+		updateNotice = lipgloss.NewStyle().Foreground(warningColor).Render(notice)
 	}
 
 	// Calculate running/total services
@@ -319,7 +686,14 @@ func (m *Model) renderHeader() string {
 		}
 	}
 
-	status := fmt.Sprintf("Services (%d/%d running)", running, total)
+	status := i18n.T("Services (%d/%d running)", running, total)
+	if progress := m.startupProgress; progress.Started < progress.Total {
+		if progress.Restarting {
+			status = i18n.T("Restarting services (%d/%d)", progress.Started, progress.Total)
+		} else {
+			status = i18n.T("Starting services (%d/%d)", progress.Started, progress.Total)
+		}
+	}
 
 	return headerStyle.Render(
 		lipgloss.JoinHorizontal(
@@ -338,45 +712,81 @@ func (m *Model) renderHeader() string {
 // renderTable renders the services table
 func (m *Model) renderTable() string {
 	if len(m.serviceNames) == 0 {
-		return "No services configured"
+		return i18n.T("No services configured")
 	}
 
 	// Calculate column widths based on terminal width
 	nameWidth := 25
-	statusWidth := 10
+	statusWidth := 14
 	urlWidth := 30
 	typeWidth := 8
 	uptimeWidth := 10
-	errorWidth := m.width - nameWidth - statusWidth - urlWidth - typeWidth - uptimeWidth - 20
+	latencyWidth := 8
+	// The cluster column only appears when services forward to more than one cluster
+	clusterWidth := 0
+	if m.multiCluster() {
+		clusterWidth = 14
+	}
+	errorWidth := m.width - nameWidth - statusWidth - urlWidth - typeWidth - uptimeWidth - latencyWidth - clusterWidth - 21
 
 	if errorWidth < 10 {
 		errorWidth = 10
-		urlWidth = m.width - nameWidth - statusWidth - typeWidth - uptimeWidth - errorWidth - 20
+		urlWidth = m.width - nameWidth - statusWidth - typeWidth - uptimeWidth - latencyWidth - clusterWidth - errorWidth - 21
 	}
 
-	// Table header
+	// Table header; translations are cut to the column width
 	headers := []string{
-		FormatTableHeader(fmt.Sprintf("%-*s", nameWidth, "Name")),
-		FormatTableHeader(fmt.Sprintf("%-*s", statusWidth, "Status")),
-		FormatTableHeader(fmt.Sprintf("%-*s", urlWidth, "URL")),
-		FormatTableHeader(fmt.Sprintf("%-*s", typeWidth, "Type")),
-		FormatTableHeader(fmt.Sprintf("%-*s", uptimeWidth, "Uptime")),
-		FormatTableHeader(fmt.Sprintf("%-*s", errorWidth, "Error")),
+		formatColumnHeader("Name", nameWidth),
+		formatColumnHeader("Status", statusWidth),
+		formatColumnHeader("URL", urlWidth),
+		formatColumnHeader("Type", typeWidth),
+		formatColumnHeader("Uptime", uptimeWidth),
+		formatColumnHeader("Latency", latencyWidth),
 	}
+	if clusterWidth > 0 {
+		headers = append(headers, formatColumnHeader("Cluster", clusterWidth-1))
+	}
+	headers = append(headers, formatColumnHeader("Error", errorWidth))
 
 	headerRow := strings.Join(headers, " ")
 
 	// Table rows
 	rows := []string{headerRow}
 
-	for i, serviceName := range m.serviceNames {
+	// In split layout the table keeps the upper part of the screen
+	limit := 0
+	if m.splitView {
+		limit = m.height - m.splitPanelHeight() - 10
+		if limit < 3 {
+			limit = 3
+		}
+	}
+	first, last := m.visibleRows(limit)
+
+	for i := first; i < last; i++ {
+		serviceName := m.serviceNames[i]
 		service := m.services[serviceName]
 		selected := (i == m.selectedIndex)
 
 		// Get raw content for each column
 		nameContent := truncateString(serviceName, nameWidth)
 		statusContent := service.Status
-		urlContent := m.formatServiceURL(service, urlWidth)
+		switch statusContent {
+		case "Cluster Unreachable":
+			statusContent = "Unreachable"
+		case "Waiting for workload":
+			statusContent = "Waiting"
+		}
+		if countdown := formatCooldown(service); countdown != "" {
+			statusContent = "Retry in " + countdown
+		}
+		// Reassigned ports are flagged in the URL column, the most common "why can't I connect"
+		reassigned := service.Status == "Running" && portReassigned(service)
+		urlContentWidth := urlWidth
+		if reassigned {
+			urlContentWidth -= 2
+		}
+		urlContent := m.formatServiceURL(service, urlContentWidth)
 		typeContent := truncateString(m.getServiceType(serviceName), typeWidth)
 
 		uptimeContent := "-"
@@ -385,6 +795,9 @@ func (m *Model) renderTable() string {
 			uptimeContent = utils.FormatUptime(uptime)
 		}
 
+		// Median round trip; the detail view shows p95 as well
+		latencyContent := utils.FormatLatency(service.LatencyP50)
+
 		errorContent := truncateString(service.LastError, errorWidth)
 
 		// Create columns with exact width (pad first, then style)
@@ -393,7 +806,9 @@ func (m *Model) renderTable() string {
 
 		// Handle URL with proper width - style only the actual URL part
 		var urlCol string
-		if service.Status == "Running" {
+		if reassigned {
+			urlCol = FormatReassignedURL(urlContent) + strings.Repeat(" ", urlContentWidth-len(urlContent))
+		} else if service.Status == "Running" {
 			// Only style if it's an actual URL, then pad to correct width
 			urlCol = FormatURL(urlContent) + strings.Repeat(" ", urlWidth-len(urlContent))
 		} else {
@@ -402,10 +817,15 @@ func (m *Model) renderTable() string {
 
 		typeCol := fmt.Sprintf("%-*s", typeWidth, typeContent)
 		uptimeCol := fmt.Sprintf("%-*s", uptimeWidth, uptimeContent)
+		latencyCol := fmt.Sprintf("%-*s", latencyWidth, latencyContent)
 		errorCol := fmt.Sprintf("%-*s", errorWidth, errorContent)
 
 		// Combine row with single spaces between columns
-		rowContent := nameCol + " " + statusCol + " " + urlCol + " " + typeCol + " " + uptimeCol + " " + errorCol
+		rowContent := nameCol + " " + statusCol + " " + urlCol + " " + typeCol + " " + uptimeCol + " " + latencyCol + " "
+		if clusterWidth > 0 {
+			rowContent += fmt.Sprintf("%-*s", clusterWidth-1, truncateString(serviceCluster(service), clusterWidth-1)) + " "
+		}
+		rowContent += errorCol
 
 		rows = append(rows, FormatTableRow(rowContent, selected))
 	}
@@ -413,20 +833,141 @@ func (m *Model) renderTable() string {
 	return strings.Join(rows, "\n")
 }
 
+// multiCluster reports whether services forward to more than one cluster
+func (m *Model) multiCluster() bool {
+	first := ""
+	for _, service := range m.services {
+		cluster := serviceCluster(service)
+		if cluster == "-" {
+			continue
+		}
+		if first == "" {
+			first = cluster
+		} else if cluster != first {
+			return true
+		}
+	}
+	return false
+}
+
+// serviceCluster names the cluster a service forwards to, or "-" for non-kubectl services
+func serviceCluster(service config.ServiceStatus) string {
+	if service.Cluster != "" {
+		return service.Cluster
+	}
+	if service.Context != "" {
+		return service.Context
+	}
+	return "-"
+}
+
+// otherContexts lists contexts services are pinned to besides the current one, with service counts
+func (m *Model) otherContexts() []string {
+	counts := make(map[string]int)
+	for _, service := range m.services {
+		if service.Context != "" && service.Context != m.kubeContext {
+			counts[service.Context]++
+		}
+	}
+
+	others := make([]string, 0, len(counts))
+	for context, count := range counts {
+		others = append(others, fmt.Sprintf("%s ×%d", context, count))
+	}
+	sort.Strings(others)
+	return others
+}
+
+// renderClusterEvents renders the most recent pod events across services
+func (m *Model) renderClusterEvents() string {
+	if len(m.clusterEvents) == 0 {
+		return ""
+	}
+
+	recent := m.clusterEvents
+	if len(recent) > panelClusterEvents {
+		recent = recent[len(recent)-panelClusterEvents:]
+	}
+
+	lines := []string{titleStyle.Render("Cluster Events")}
+	for _, event := range recent {
+		line := fmt.Sprintf("%s %s", event.Time.Format("15:04:05"), event.String())
+		lines = append(lines, errorMessageStyle.Render(truncateString(line, m.width-8)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// renderUnreachableHint explains that services are paused because their cluster is down
+func (m *Model) renderUnreachableHint() string {
+	count := 0
+	for _, name := range m.serviceNames {
+		if m.services[name].Status == "Cluster Unreachable" {
+			count++
+		}
+	}
+	if count == 0 {
+		return ""
+	}
+	return errorMessageStyle.Render(i18n.T(
+		"Kubernetes API server unreachable: %d services paused, they restart automatically once the cluster answers", count))
+}
+
+// renderLoginHint tells the user how to recover services waiting for Teleport login
+func (m *Model) renderLoginHint() string {
+	var waiting []string
+	commands := make(map[string]bool)
+	for _, name := range m.serviceNames {
+		if m.services[name].Status != "Login" {
+			continue
+		}
+		waiting = append(waiting, name)
+		if teleport := m.serviceConfigs[name].Teleport; teleport != nil {
+			commands[utils.TeleportLoginCommand(teleport.Proxy)] = true
+		}
+	}
+	if len(waiting) == 0 {
+		return ""
+	}
+
+	loginCommands := make([]string, 0, len(commands))
+	for command := range commands {
+		loginCommands = append(loginCommands, "'"+command+"'")
+	}
+	sort.Strings(loginCommands)
+
+	return errorMessageStyle.Render(i18n.T(
+		"Teleport login required for %s: run %s in another terminal; services start automatically once logged in",
+		strings.Join(waiting, ", "), strings.Join(loginCommands, i18n.T(" or "))))
+}
+
+// formatColumnHeader renders a table column's translated title padded to width
+func formatColumnHeader(title string, width int) string {
+	return FormatTableHeader(fmt.Sprintf("%-*s", width, truncateString(i18n.T(title), width)))
+}
+
 // renderFooter renders the footer with help text
 func (m *Model) renderFooter() string {
-	sortInfo := fmt.Sprintf("Sort: %s", sortFieldNames[m.sortField])
+	sortInfo := i18n.T("Sort: %s", i18n.T(sortFieldNames[m.sortField]))
 	if m.sortReverse {
-		sortInfo += " (desc)"
+		sortInfo = i18n.T("Sort: %s (desc)", i18n.T(sortFieldNames[m.sortField]))
 	}
 
 	help := []string{
-		"[↑↓] Navigate",
-		"[Enter] Details",
-		"[n/s/t/p/u] Sort by Name/Status/Type/Port/Uptime",
-		"[r] Reverse",
-		"[q] Quit",
+		i18n.T("[↑↓] Navigate"),
+		i18n.T("[Enter] Details"),
+		i18n.T("[n/s/t/p/u/c/e] Sort by Name/Status/Type/Port/Uptime/Restarts/Errors"),
+		i18n.T("[r] Reverse"),
+		i18n.T("[l] Split log"),
+		i18n.T("[x/X] Export MD/CSV"),
+		i18n.T("[w] Swap target"),
+		i18n.T("[R] Restart"),
+		i18n.T("[-/+] Pause/Resume"),
+		i18n.T("[F5] Reload config"),
+	}
+	if len(m.profiles) > 0 {
+		help = append(help, i18n.T("[P] Profile"))
 	}
+	help = append(help, i18n.T("[q] Quit"))
 
 	return footerStyle.Render(
 		lipgloss.JoinHorizontal(
@@ -444,7 +985,7 @@ func (m *Model) formatServiceURL(service config.ServiceStatus, maxWidth int) str
 		return "-"
 	}
 
-	url := fmt.Sprintf("http://localhost:%d", service.LocalPort)
+	url := m.serviceConfigs[service.Name].LocalURL(service.LocalPort)
 	if len(url) > maxWidth {
 		url = truncateString(url, maxWidth)
 	}
@@ -473,6 +1014,22 @@ func (m *Model) updateServiceNames() {
 			less = a.LocalPort < b.LocalPort
 		case SortByUptime:
 			less = a.StartTime.Before(b.StartTime)
+		case SortByRestarts:
+			if a.RestartCount != b.RestartCount {
+				less = a.RestartCount > b.RestartCount
+			} else {
+				less = m.serviceNames[i] < m.serviceNames[j]
+			}
+		case SortByErrors:
+			aFailing, bFailing := a.LastError != "", b.LastError != ""
+			switch {
+			case aFailing != bFailing:
+				less = aFailing
+			case a.RestartCount != b.RestartCount:
+				less = a.RestartCount > b.RestartCount
+			default:
+				less = m.serviceNames[i] < m.serviceNames[j]
+			}
 		default: // SortByName
 			less = m.serviceNames[i] < m.serviceNames[j]
 		}
@@ -492,14 +1049,140 @@ func (m *Model) updateServiceNames() {
 	}
 }
 
+// formatDetailType shows the service's type, marking a type inferred by probing
+func formatDetailType(service config.ServiceStatus) string {
+	switch {
+	case service.TypeDetected:
+		return service.Type + " (detected)"
+	case service.Type == "":
+		return "unknown"
+	}
+	return service.Type
+}
+
+// formatHealthTimeline renders one block per timeline slot, shaped as well as colored
+// by its state so it reads without colors too
+func formatHealthTimeline(timeline []config.HealthSlot) string {
+	var b strings.Builder
+	for _, slot := range timeline {
+		switch slot.State() {
+		case config.HealthUp:
+			b.WriteString(statusRunningStyle.Render("█"))
+		case config.HealthDegraded:
+			b.WriteString(statusStartingStyle.Render("▄"))
+		case config.HealthDown:
+			b.WriteString(statusFailedStyle.Render("▁"))
+		default:
+			b.WriteString(helpStyle.Render("·"))
+		}
+	}
+	return b.String()
+}
+
 // getServiceType returns the type of a service from the service configs
 func (m *Model) getServiceType(serviceName string) string {
+	if serviceType := m.services[serviceName].Type; serviceType != "" {
+		return serviceType
+	}
 	if serviceConfig, exists := m.serviceConfigs[serviceName]; exists {
 		return serviceConfig.Type
 	}
 	return "unknown"
 }
 
+// formatCooldown returns the time left until a service in cooldown is retried, or ""
+func formatCooldown(service config.ServiceStatus) string {
+	remaining := time.Until(service.CooldownUntil)
+	if service.CooldownUntil.IsZero() || remaining <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("%ds", int((remaining+time.Second-1)/time.Second))
+}
+
+// formatDetailStatus adds the cooldown countdown to the status
+func formatDetailStatus(service config.ServiceStatus) string {
+	if countdown := formatCooldown(service); countdown != "" {
+		return fmt.Sprintf("%s (retry in %s)", service.Status, countdown)
+	}
+	return service.Status
+}
+
+// formatErrorHistoryTitle titles the error history, noting how many errors match the latest one
+func formatErrorHistoryTitle(errors []config.ErrorEntry) string {
+	latest := errors[len(errors)-1].Message
+	same := 0
+	for _, entry := range errors {
+		if entry.Message == latest {
+			same++
+		}
+	}
+	if same == len(errors) && same > 1 {
+		return fmt.Sprintf("Error History (all %d identical, newest first):", same)
+	}
+	if same > 1 {
+		return fmt.Sprintf("Error History (%d of %d match the latest, newest first):", same, len(errors))
+	}
+	return "Error History (newest first):"
+}
+
+// formatTarget shows a kubectl target with its namespace
+func formatTarget(service config.ServiceStatus) string {
+	if service.Namespace == "" {
+		return service.Target
+	}
+	return fmt.Sprintf("%s/%s", service.Namespace, service.Target)
+}
+
+// portReassigned reports whether the service listens on a port other than the configured one
+func portReassigned(service config.ServiceStatus) bool {
+	return service.ConfiguredPort != 0 && service.LocalPort != 0 && service.ConfiguredPort != service.LocalPort
+}
+
+// portAssigned reports whether the service listens on a free port picked for it, as
+// its configured port is 0
+func portAssigned(service config.ServiceStatus) bool {
+	return service.ConfiguredPort == 0 && service.LocalPort != 0
+}
+
+// formatLocalPort notes when the local port was reassigned away from the configured one,
+// or picked because any free port will do
+func formatLocalPort(service config.ServiceStatus) string {
+	if portReassigned(service) {
+		return portReassignedStyle.Render(fmt.Sprintf("%d (configured port %d was in use)", service.LocalPort, service.ConfiguredPort))
+	}
+	if portAssigned(service) {
+		return portAssignedStyle.Render(fmt.Sprintf("%d (assigned, localPort is 0)", service.LocalPort))
+	}
+	if service.LocalPort == 0 {
+		return "not assigned yet"
+	}
+	return fmt.Sprintf("%d", service.LocalPort)
+}
+
+// formatPortName names a further port by its name and target port, e.g. metrics (9090)
+func formatPortName(port config.PortStatus) string {
+	if port.Name == "" {
+		return fmt.Sprintf("%d", port.TargetPort)
+	}
+	return fmt.Sprintf("%s (%d)", port.Name, port.TargetPort)
+}
+
+// formatPortStatus shows the local port of a further port with its health and last error
+func formatPortStatus(port config.PortStatus) string {
+	state := "not checked yet"
+	switch {
+	case port.Healthy:
+		state = "healthy"
+	case port.LastError != "":
+		state = "failing"
+	}
+	text := fmt.Sprintf("local %d, %s", port.LocalPort, state)
+	if port.LastError != "" {
+		text += " " + errorMessageStyle.Render("- "+port.LastError)
+	}
+	return text
+}
+
 // truncateString truncates a string to fit within the specified width
 func truncateString(s string, width int) string {
 	if len(s) <= width {
@@ -511,21 +1194,41 @@ func truncateString(s string, width int) string {
 	return s[:width-3] + "..."
 }
 
-// listenForStatusUpdates listens for status updates
-func (m *Model) listenForStatusUpdates() tea.Cmd {
+// waitForStatusUpdate waits for the next status update; nothing once the channel is closed
+func (m *Model) waitForStatusUpdate() tea.Cmd {
 	return func() tea.Msg {
-		select {
-		case status := <-m.statusChan:
-			return StatusUpdateMsg(status)
-		default:
+		update, ok := <-m.statusChan
+		if !ok {
 			return nil
 		}
+		return StatusUpdateMsg(update)
 	}
 }
 
-// tickEvery returns a command that ticks at the refresh rate
+// statusChanged reports whether an update changes what services are doing, as
+// opposed to only refreshing measurements like latency
+func statusChanged(previous map[string]config.ServiceStatus, update config.StatusUpdate) bool {
+	if len(update.Removed) > 0 || (update.Full && len(update.Changed) != len(previous)) {
+		return true
+	}
+	for name, status := range update.Changed {
+		old, exists := previous[name]
+		if !exists || old.Status != status.Status || old.LastError != status.LastError ||
+			old.LocalPort != status.LocalPort || old.RestartCount != status.RestartCount {
+			return true
+		}
+	}
+	return false
+}
+
+// tickEvery returns a command that ticks at the refresh rate while things are
+// happening, and at idleRefreshRate once the screen has been idle for idleAfter
 func (m *Model) tickEvery() tea.Cmd {
-	return tea.Tick(m.refreshRate, func(t time.Time) tea.Msg {
+	rate := m.refreshRate
+	if time.Since(m.lastActivity) > idleAfter && rate < idleRefreshRate {
+		rate = idleRefreshRate
+	}
+	return tea.Tick(rate, func(t time.Time) tea.Msg {
 		return TickMsg(t)
 	})
 }
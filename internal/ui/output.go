@@ -0,0 +1,135 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/i18n"
+)
+
+// outputViewer scrolls through what the forward processes of a service wrote, opened
+// with l in the detail view
+type outputViewer struct {
+	service string
+	lines   []config.OutputLine
+	err     error
+	offset  int // Lines scrolled up from the newest; 0 follows new output
+}
+
+// toggleOutput opens the output of the selected service, or closes it again
+func (m *Model) toggleOutput() {
+	if m.output != nil {
+		m.output = nil
+		return
+	}
+	if m.selectedIndex >= len(m.serviceNames) {
+		return
+	}
+	m.output = &outputViewer{service: m.serviceNames[m.selectedIndex]}
+	m.refreshOutput()
+}
+
+// refreshOutput fetches the latest output, keeping the scrolled-to lines in view
+func (m *Model) refreshOutput() {
+	if m.output == nil {
+		return
+	}
+	if m.controller == nil {
+		m.output.err = fmt.Errorf("process output is not available")
+		return
+	}
+	lines, err := m.controller.ServiceOutput(m.output.service)
+	if m.output.offset > 0 {
+		m.output.offset += len(lines) - len(m.output.lines)
+	}
+	m.output.lines, m.output.err = lines, err
+	m.scrollOutput(0)
+}
+
+// outputPageSize is how many lines of output fit on the screen
+func (m *Model) outputPageSize() int {
+	return max(m.height-9, 1)
+}
+
+// scrollOutput moves the output up by delta lines, or down when negative
+func (m *Model) scrollOutput(delta int) {
+	maxOffset := max(len(m.output.lines)-m.outputPageSize(), 0)
+	m.output.offset = min(max(m.output.offset+delta, 0), maxOffset)
+}
+
+// handleOutputKeyPress handles keys while the output is open
+func (m *Model) handleOutputKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "q", "ctrl+c":
+		return m, tea.Quit
+
+	case "l":
+		m.output = nil
+
+	case "esc", "backspace":
+		m.output = nil
+		m.viewMode = ViewTable
+
+	case "up", "k":
+		m.scrollOutput(1)
+
+	case "down", "j":
+		m.scrollOutput(-1)
+
+	case "pgup", "b":
+		m.scrollOutput(m.outputPageSize())
+
+	case "pgdown", " ":
+		m.scrollOutput(-m.outputPageSize())
+
+	case "g", "home":
+		m.scrollOutput(len(m.output.lines))
+
+	case "G", "end":
+		m.output.offset = 0
+	}
+
+	return m, nil
+}
+
+// renderOutputView renders the output of the service, newest at the bottom
+func (m *Model) renderOutputView() string {
+	viewer := m.output
+	title := i18n.T("Output: %s", viewer.service)
+	if viewer.offset > 0 {
+		title += " " + helpStyle.Render(i18n.T("(%d newer lines below)", viewer.offset))
+	}
+	lines := []string{titleStyle.Render(title), ""}
+
+	switch {
+	case viewer.err != nil:
+		lines = append(lines, errorMessageStyle.Render(viewer.err.Error()))
+	case len(viewer.lines) == 0:
+		lines = append(lines, helpStyle.Render(i18n.T("No output yet")))
+	default:
+		end := len(viewer.lines) - viewer.offset
+		start := max(end-m.outputPageSize(), 0)
+		for _, line := range viewer.lines[start:end] {
+			text := truncateString(line.Time.Format("15:04:05")+" "+line.Text, m.width-8)
+			if strings.HasPrefix(line.Text, "---") {
+				text = helpStyle.Render(text) // Process start marker
+			}
+			lines = append(lines, text)
+		}
+	}
+
+	lines = append(lines,
+		"",
+		helpStyle.Render(strings.Join([]string{
+			i18n.T("[↑↓/PgUp/PgDn] Scroll"), i18n.T("[g/G] Oldest/Newest"), i18n.T("[l] Details"), i18n.T("[ESC] Back to table view"), i18n.T("[q] Quit"),
+		}, "  ")),
+	)
+
+	return containerStyle.
+		Width(m.width - 4).
+		Height(m.height - 2).
+		Render(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,57 @@
+package ui
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// ServicePausedMsg reports the outcome of pausing or resuming a service from the TUI
+type ServicePausedMsg struct {
+	Name   string
+	Paused bool // Paused rather than resumed
+	Err    error
+}
+
+// pauseSelected pauses (or resumes) the selected service in the background
+func (m *Model) pauseSelected(pause bool) tea.Cmd {
+	if m.selectedIndex >= len(m.serviceNames) {
+		return nil
+	}
+	if m.controller == nil {
+		m.showNotice("pausing services is not available", true)
+		return nil
+	}
+
+	name := m.serviceNames[m.selectedIndex]
+	controller := m.controller
+	if pause {
+		m.showNotice("Pausing "+name+"...", false)
+		return func() tea.Msg {
+			return ServicePausedMsg{Name: name, Paused: true, Err: controller.PauseService(name)}
+		}
+	}
+	m.showNotice("Resuming "+name+"...", false)
+	return func() tea.Msg {
+		return ServicePausedMsg{Name: name, Err: controller.ResumeService(name)}
+	}
+}
+
+// handleServicePaused reports how pausing or resuming went
+func (m *Model) handleServicePaused(msg ServicePausedMsg) {
+	action, done := "Resuming", "resumed"
+	if msg.Paused {
+		action, done = "Pausing", "paused"
+	}
+	if msg.Err != nil {
+		m.showNotice(action+" "+msg.Name+" failed: "+msg.Err.Error(), true)
+		m.recordActivity(msg.Name, activityLine{Time: time.Now(), Text: done + " from the TUI failed: " + msg.Err.Error(), Bad: true})
+		return
+	}
+	if msg.Paused {
+		m.showNotice("Paused "+msg.Name+"; it stays stopped until resumed with +", false)
+	} else {
+		m.showNotice("Resumed "+msg.Name, false)
+	}
+	m.recordActivity(msg.Name, activityLine{Time: time.Now(), Text: done + " from the TUI"})
+}
@@ -0,0 +1,213 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/victorkazakov/kportforward/internal/pick"
+)
+
+// ErrPickCancelled is returned by RunPicker when the user quits without choosing
+var ErrPickCancelled = errors.New("cancelled")
+
+// pickerVisibleRows is how many matches the picker lists at once
+const pickerVisibleRows = 15
+
+// pickerModel is a fuzzy finder over forward candidates followed by a port prompt
+type pickerModel struct {
+	candidates []pick.Candidate
+	matches    []pick.Candidate
+	query      string
+	cursor     int
+
+	chosen     *pick.Candidate // Set once a candidate is chosen; the port prompt follows
+	portCursor int
+	portInput  string // A port typed instead of one the resource declares
+
+	port      int
+	cancelled bool
+}
+
+// RunPicker lets the user choose a candidate by typing a fuzzy query and then one of
+// its ports (or any typed port), returning ErrPickCancelled if they quit
+func RunPicker(candidates []pick.Candidate) (pick.Candidate, int, error) {
+	model := &pickerModel{candidates: candidates, matches: candidates}
+	result, err := tea.NewProgram(model).Run()
+	if err != nil {
+		return pick.Candidate{}, 0, fmt.Errorf("picker failed: %w", err)
+	}
+
+	final := result.(*pickerModel)
+	if final.cancelled || final.chosen == nil {
+		return pick.Candidate{}, 0, ErrPickCancelled
+	}
+	return *final.chosen, final.port, nil
+}
+
+// Init implements tea.Model
+func (m *pickerModel) Init() tea.Cmd {
+	return nil
+}
+
+// Update implements tea.Model
+func (m *pickerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	switch key.Type {
+	case tea.KeyCtrlC:
+		m.cancelled = true
+		return m, tea.Quit
+	case tea.KeyEsc:
+		if m.chosen == nil {
+			m.cancelled = true
+			return m, tea.Quit
+		}
+		m.chosen, m.portInput = nil, "" // Back to the list
+		return m, nil
+	}
+
+	if m.chosen == nil {
+		return m, m.updateList(key)
+	}
+	return m, m.updatePort(key)
+}
+
+// updateList handles a key while choosing a candidate
+func (m *pickerModel) updateList(key tea.KeyMsg) tea.Cmd {
+	switch key.Type {
+	case tea.KeyUp, tea.KeyCtrlP:
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case tea.KeyDown, tea.KeyCtrlN:
+		if m.cursor < len(m.matches)-1 {
+			m.cursor++
+		}
+	case tea.KeyBackspace:
+		if m.query != "" {
+			runes := []rune(m.query)
+			m.setQuery(string(runes[:len(runes)-1]))
+		}
+	case tea.KeyRunes, tea.KeySpace:
+		m.setQuery(m.query + string(key.Runes))
+	case tea.KeyEnter:
+		if len(m.matches) == 0 {
+			return nil
+		}
+		chosen := m.matches[m.cursor]
+		m.chosen, m.portCursor = &chosen, 0
+		if len(chosen.Ports) == 1 {
+			m.port = chosen.Ports[0]
+			return tea.Quit
+		}
+	}
+	return nil
+}
+
+// updatePort handles a key while choosing the target port
+func (m *pickerModel) updatePort(key tea.KeyMsg) tea.Cmd {
+	ports := m.chosen.Ports
+	switch key.Type {
+	case tea.KeyUp:
+		if m.portCursor > 0 {
+			m.portCursor--
+		}
+	case tea.KeyDown:
+		if m.portCursor < len(ports)-1 {
+			m.portCursor++
+		}
+	case tea.KeyBackspace:
+		if m.portInput != "" {
+			m.portInput = m.portInput[:len(m.portInput)-1]
+		}
+	case tea.KeyRunes:
+		for _, r := range key.Runes {
+			if r >= '0' && r <= '9' && len(m.portInput) < 5 {
+				m.portInput += string(r)
+			}
+		}
+	case tea.KeyEnter:
+		if m.portInput != "" {
+			port, err := strconv.Atoi(m.portInput)
+			if err != nil || port < 1 || port > 65535 {
+				return nil
+			}
+			m.port = port
+			return tea.Quit
+		}
+		if len(ports) > 0 {
+			m.port = ports[m.portCursor]
+			return tea.Quit
+		}
+	}
+	return nil
+}
+
+// setQuery filters the candidates by a new query
+func (m *pickerModel) setQuery(query string) {
+	m.query = query
+	m.matches = pick.Filter(m.candidates, query)
+	m.cursor = 0
+}
+
+// View implements tea.Model
+func (m *pickerModel) View() string {
+	if m.port != 0 || m.cancelled {
+		return ""
+	}
+	if m.chosen != nil {
+		return m.portView()
+	}
+
+	var b strings.Builder
+	b.WriteString(titleStyle.Render("Forward what?") + " " + m.query + "█\n\n")
+
+	// Keep the cursor within the visible window
+	start := 0
+	if m.cursor >= pickerVisibleRows {
+		start = m.cursor - pickerVisibleRows + 1
+	}
+	end := start + pickerVisibleRows
+	if end > len(m.matches) {
+		end = len(m.matches)
+	}
+	for i := start; i < end; i++ {
+		line := fmt.Sprintf("%-60s %s", m.matches[i].String(), formatPorts(m.matches[i].Ports))
+		b.WriteString(FormatTableRow(line, i == m.cursor) + "\n")
+	}
+	if len(m.matches) == 0 {
+		b.WriteString(helpStyle.Render("No matches") + "\n")
+	}
+
+	b.WriteString("\n" + helpStyle.Render(fmt.Sprintf("%d/%d • type to filter • ↑/↓ select • enter choose • esc quit",
+		len(m.matches), len(m.candidates))))
+	return b.String()
+}
+
+// portView renders the port prompt for the chosen candidate
+func (m *pickerModel) portView() string {
+	var b strings.Builder
+	b.WriteString(titleStyle.Render("Port of "+m.chosen.String()+"?") + " " + m.portInput + "█\n\n")
+	for i, port := range m.chosen.Ports {
+		b.WriteString(FormatTableRow(strconv.Itoa(port), m.portInput == "" && i == m.portCursor) + "\n")
+	}
+	if len(m.chosen.Ports) == 0 {
+		b.WriteString(helpStyle.Render("No ports declared; type one") + "\n")
+	}
+	b.WriteString("\n" + helpStyle.Render("↑/↓ select • type a port • enter forward • esc back"))
+	return b.String()
+}
+
+// formatPorts renders a candidate's ports for the list
+func formatPorts(ports []int) string {
+	parts := make([]string, len(ports))
+	for i, port := range ports {
+		parts[i] = strconv.Itoa(port)
+	}
+	return strings.Join(parts, ",")
+}
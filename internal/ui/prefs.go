@@ -0,0 +1,97 @@
+package ui
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+// Preferences is the TUI state restored between runs
+type Preferences struct {
+	Sort     string `json:"sort,omitempty"` // Name of the sort field, e.g. "Restarts"
+	Reverse  bool   `json:"reverse,omitempty"`
+	Selected string `json:"selected,omitempty"` // Last selected service
+	Split    bool   `json:"split,omitempty"`    // Split layout with the activity tail
+}
+
+// preferencesPath returns the TUI state file in the user cache directory
+func preferencesPath() (string, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(cacheDir, "kportforward", "tui.json"), nil
+}
+
+// loadPreferences reads saved preferences; missing or unreadable state gives the defaults
+func loadPreferences(path string) Preferences {
+	var prefs Preferences
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return prefs
+	}
+	json.Unmarshal(data, &prefs)
+	return prefs
+}
+
+// savePreferences writes preferences atomically
+func savePreferences(path string, prefs Preferences) error {
+	data, err := json.MarshalIndent(prefs, "", "  ")
+	if err != nil {
+		return err
+	}
+	return utils.WriteFileAtomic(path, append(data, '\n'), 0644)
+}
+
+// sortFieldByName looks up a sort field by its display name
+func sortFieldByName(name string) (SortField, bool) {
+	for field, fieldName := range sortFieldNames {
+		if fieldName == name {
+			return field, true
+		}
+	}
+	return SortByName, false
+}
+
+// applyPreferences restores the sort order and remembers the service to select
+// once it appears in a status update
+func (m *Model) applyPreferences(prefs Preferences) {
+	if field, ok := sortFieldByName(prefs.Sort); ok {
+		m.sortField = field
+	}
+	m.sortReverse = prefs.Reverse
+	m.splitView = prefs.Split
+	m.pendingSelection = prefs.Selected
+	m.savedPrefs = prefs
+}
+
+// currentPreferences captures the preferences from the current view state
+func (m *Model) currentPreferences() Preferences {
+	prefs := Preferences{
+		Sort:     sortFieldNames[m.sortField],
+		Reverse:  m.sortReverse,
+		Selected: m.savedPrefs.Selected,
+		Split:    m.splitView,
+	}
+	if m.selectedIndex < len(m.serviceNames) {
+		prefs.Selected = m.serviceNames[m.selectedIndex]
+	}
+	return prefs
+}
+
+// persistPreferences saves the preferences when they changed since the last save
+func (m *Model) persistPreferences() {
+	if m.prefsPath == "" {
+		return
+	}
+	prefs := m.currentPreferences()
+	if prefs == m.savedPrefs {
+		return
+	}
+	if err := savePreferences(m.prefsPath, prefs); err != nil {
+		return
+	}
+	m.savedPrefs = prefs
+}
@@ -0,0 +1,64 @@
+package ui
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/victorkazakov/kportforward/internal/config"
+)
+
+// ProfileSwitcher applies the services of a profile to the running ones, returning the
+// new services and what changed; the empty profile runs every service
+type ProfileSwitcher func(profile string) (map[string]config.Service, config.ServiceDiff, error)
+
+// ProfileSwitchedMsg reports the outcome of switching profiles
+type ProfileSwitchedMsg struct {
+	Profile  string
+	Services map[string]config.Service
+	Diff     config.ServiceDiff
+	Err      error
+}
+
+// switchToNextProfile switches to the profile after the active one in the background;
+// after the last profile come all services
+func (m *Model) switchToNextProfile() tea.Cmd {
+	if m.profileSwitcher == nil || len(m.profiles) == 0 {
+		m.showNotice("no profiles are configured", true)
+		return nil
+	}
+
+	next := m.profiles[0]
+	for i, profile := range m.profiles {
+		if profile == m.activeProfile {
+			next = ""
+			if i+1 < len(m.profiles) {
+				next = m.profiles[i+1]
+			}
+			break
+		}
+	}
+
+	m.showNotice("Switching to "+profileLabel(next)+"...", false)
+	switcher := m.profileSwitcher
+	return func() tea.Msg {
+		services, diff, err := switcher(next)
+		return ProfileSwitchedMsg{Profile: next, Services: services, Diff: diff, Err: err}
+	}
+}
+
+// handleProfileSwitched switches to the services of the new profile
+func (m *Model) handleProfileSwitched(msg ProfileSwitchedMsg) {
+	if msg.Err != nil {
+		m.showNotice("Switching to "+profileLabel(msg.Profile)+" failed: "+msg.Err.Error(), true)
+		return
+	}
+	m.activeProfile = msg.Profile
+	m.applyServiceConfigs(msg.Services, msg.Diff, profileLabel(msg.Profile))
+	m.showNotice("Switched to "+profileLabel(msg.Profile)+": "+msg.Diff.String(), false)
+}
+
+// profileLabel names a profile in notices
+func profileLabel(profile string) string {
+	if profile == "" {
+		return "all services"
+	}
+	return "profile " + profile
+}
@@ -0,0 +1,68 @@
+package ui
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/victorkazakov/kportforward/internal/config"
+)
+
+// ConfigReloader reads the configuration from disk again and applies it to the
+// running services, returning the new services and what changed
+type ConfigReloader func() (map[string]config.Service, config.ServiceDiff, error)
+
+// ConfigReloadedMsg reports the outcome of a configuration reload
+type ConfigReloadedMsg struct {
+	Services    map[string]config.Service
+	Diff        config.ServiceDiff
+	Err         error
+	FileChanged bool // Reloaded because the config file changed rather than on request
+}
+
+// reloadConfig reloads the configuration from disk in the background
+func (m *Model) reloadConfig() tea.Cmd {
+	if m.reloader == nil {
+		m.showNotice("configuration reload is not available", true)
+		return nil
+	}
+
+	m.showNotice("Reloading configuration...", false)
+	reloader := m.reloader
+	return func() tea.Msg {
+		services, diff, err := reloader()
+		return ConfigReloadedMsg{Services: services, Diff: diff, Err: err}
+	}
+}
+
+// handleConfigReloaded switches to the reloaded services and summarizes the changes
+func (m *Model) handleConfigReloaded(msg ConfigReloadedMsg) {
+	if msg.Err != nil {
+		m.showNotice("Reload failed: "+msg.Err.Error(), true)
+		return
+	}
+
+	m.applyServiceConfigs(msg.Services, msg.Diff, "config reload")
+	if msg.FileChanged {
+		m.showNotice("Config file changed, reloaded: "+msg.Diff.String(), false)
+	} else {
+		m.showNotice("Reloaded configuration: "+msg.Diff.String(), false)
+	}
+}
+
+// applyServiceConfigs switches to a new set of services, noting in the activity of the
+// added and changed ones what caused it
+func (m *Model) applyServiceConfigs(services map[string]config.Service, diff config.ServiceDiff, cause string) {
+	configs := make(map[string]config.Service, len(services))
+	for name, service := range services {
+		configs[name] = service
+	}
+	m.serviceConfigs = configs
+
+	now := time.Now()
+	for _, name := range diff.Added {
+		m.recordActivity(name, activityLine{Time: now, Text: "added by " + cause})
+	}
+	for _, name := range diff.Changed {
+		m.recordActivity(name, activityLine{Time: now, Text: "restarted by " + cause})
+	}
+}
@@ -0,0 +1,42 @@
+package ui
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// ServiceRestartedMsg reports the outcome of restarting a service from the TUI
+type ServiceRestartedMsg struct {
+	Name string
+	Err  error
+}
+
+// restartSelected restarts the selected service in the background
+func (m *Model) restartSelected() tea.Cmd {
+	if m.selectedIndex >= len(m.serviceNames) {
+		return nil
+	}
+	if m.controller == nil {
+		m.showNotice("restarting services is not available", true)
+		return nil
+	}
+
+	name := m.serviceNames[m.selectedIndex]
+	m.showNotice("Restarting "+name+"...", false)
+	controller := m.controller
+	return func() tea.Msg {
+		return ServiceRestartedMsg{Name: name, Err: controller.RestartService(name)}
+	}
+}
+
+// handleServiceRestarted reports how the restart went
+func (m *Model) handleServiceRestarted(msg ServiceRestartedMsg) {
+	if msg.Err != nil {
+		m.showNotice("Restart of "+msg.Name+" failed: "+msg.Err.Error(), true)
+		m.recordActivity(msg.Name, activityLine{Time: time.Now(), Text: "restart from the TUI failed: " + msg.Err.Error(), Bad: true})
+		return
+	}
+	m.showNotice("Restarted "+msg.Name, false)
+	m.recordActivity(msg.Name, activityLine{Time: time.Now(), Text: "restarted from the TUI"})
+}
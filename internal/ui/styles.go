@@ -92,6 +92,16 @@ var (
 				Foreground(errorColor).
 				Italic(true)
 
+	// Marks a URL whose port was reassigned away from the configured one
+	portReassignedStyle = lipgloss.NewStyle().
+				Foreground(warningColor).
+				Bold(true)
+
+	// Marks a port picked for a service configured with localPort 0
+	portAssignedStyle = lipgloss.NewStyle().
+				Foreground(primaryColor).
+				Bold(true)
+
 	// Footer style
 	footerStyle = lipgloss.NewStyle().
 			Foreground(mutedColor).
@@ -108,7 +118,7 @@ func GetStatusStyle(status string) lipgloss.Style {
 		return statusFailedStyle
 	case "Starting":
 		return statusStartingStyle
-	case "Cooldown":
+	case "Cooldown", "Cluster Unreachable", "Waiting for workload", "Paused":
 		return statusCooldownStyle
 	default:
 		return statusStartingStyle
@@ -126,6 +136,11 @@ func FormatURL(url string) string {
 	return urlStyle.Render(url)
 }
 
+// FormatReassignedURL formats the URL of a service listening on a reassigned port
+func FormatReassignedURL(url string) string {
+	return portReassignedStyle.Render("⚠ " + url)
+}
+
 // FormatTableHeader formats table headers
 func FormatTableHeader(text string) string {
 	return tableHeaderStyle.Render(text)
@@ -0,0 +1,50 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/victorkazakov/kportforward/internal/config"
+)
+
+// TargetSwappedMsg reports the outcome of switching a service to its next target
+type TargetSwappedMsg struct {
+	Name    string
+	Service config.Service
+	Err     error
+}
+
+// swapSelectedTarget switches the selected service to its next alternate target,
+// restarting only that forward
+func (m *Model) swapSelectedTarget() tea.Cmd {
+	if m.selectedIndex >= len(m.serviceNames) {
+		return nil
+	}
+	name := m.serviceNames[m.selectedIndex]
+	if len(m.serviceConfigs[name].AlternateTargets) == 0 {
+		m.showNotice(fmt.Sprintf("%s has no alternateTargets to swap to", name), true)
+		return nil
+	}
+	if m.controller == nil {
+		m.showNotice("target swapping is not available", true)
+		return nil
+	}
+
+	controller := m.controller
+	return func() tea.Msg {
+		service, err := controller.SwapTarget(name, "")
+		return TargetSwappedMsg{Name: name, Service: service, Err: err}
+	}
+}
+
+// handleTargetSwapped records the new target of a service
+func (m *Model) handleTargetSwapped(msg TargetSwappedMsg) {
+	if msg.Err != nil {
+		m.showNotice(msg.Err.Error(), true)
+		return
+	}
+	m.serviceConfigs[msg.Name] = msg.Service
+	m.showNotice(fmt.Sprintf("%s now forwards to %s", msg.Name, msg.Service.Target), false)
+	m.recordActivity(msg.Name, activityLine{Time: time.Now(), Text: "switched to " + msg.Service.Target})
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/victorkazakov/kportforward/internal/clusterwatch"
 	"github.com/victorkazakov/kportforward/internal/config"
 	"github.com/victorkazakov/kportforward/internal/updater"
 )
@@ -13,13 +14,13 @@ import (
 type TUI struct {
 	program    *tea.Program
 	model      *Model
-	statusChan <-chan map[string]config.ServiceStatus
+	statusChan <-chan config.StatusUpdate
 	ctx        context.Context
 	cancel     context.CancelFunc
 }
 
 // NewTUI creates a new terminal user interface
-func NewTUI(statusChan <-chan map[string]config.ServiceStatus, serviceConfigs map[string]config.Service) *TUI {
+func NewTUI(statusChan <-chan config.StatusUpdate, serviceConfigs map[string]config.Service) *TUI {
 	ctx, cancel := context.WithCancel(context.Background())
 
 	model := NewModel(statusChan, serviceConfigs)
@@ -60,6 +61,34 @@ func (t *TUI) Stop() error {
 	return nil
 }
 
+// SetController enables live editing of services through the controller; call before Start
+func (t *TUI) SetController(controller ServiceController) {
+	t.model.controller = controller
+}
+
+// SetConfigReloader enables reloading the configuration with F5 or Ctrl+L; call before Start
+func (t *TUI) SetConfigReloader(reloader ConfigReloader) {
+	t.model.reloader = reloader
+}
+
+// SetProfiles enables cycling through the configured profiles with P, starting at
+// active; call before Start
+func (t *TUI) SetProfiles(profiles []string, active string, switcher ProfileSwitcher) {
+	t.model.profiles = profiles
+	t.model.activeProfile = active
+	t.model.profileSwitcher = switcher
+}
+
+// SetUpdateDownloader enables downloading an available update with U, showing its
+// progress in the header; call before Start
+func (t *TUI) SetUpdateDownloader(downloader UpdateDownloader) {
+	t.model.downloader = func() (string, error) {
+		return downloader(func(progress updater.Progress) {
+			t.program.Send(UpdateProgressMsg(progress))
+		})
+	}
+}
+
 // UpdateKubernetesContext sends a context update to the TUI
 func (t *TUI) UpdateKubernetesContext(context string) {
 	if t.program != nil {
@@ -67,9 +96,45 @@ func (t *TUI) UpdateKubernetesContext(context string) {
 	}
 }
 
+// PromptContextChange asks whether to restart the services in the context kubectl
+// switched to
+func (t *TUI) PromptContextChange(context, previous string) {
+	if t.program != nil {
+		t.program.Send(ContextChangePromptMsg{Context: context, Previous: previous})
+	}
+}
+
+// NotifyConfigReloaded shows the outcome of a reload after the config file changed
+func (t *TUI) NotifyConfigReloaded(services map[string]config.Service, diff config.ServiceDiff, err error) {
+	if t.program != nil {
+		t.program.Send(ConfigReloadedMsg{Services: services, Diff: diff, Err: err, FileChanged: true})
+	}
+}
+
+// UpdateStartupProgress shows how many services have been started so far
+func (t *TUI) UpdateStartupProgress(started, total int) {
+	if t.program != nil {
+		t.program.Send(StartupProgressMsg{Started: started, Total: total})
+	}
+}
+
+// UpdateRestartProgress shows how many services have been restarted after a context change
+func (t *TUI) UpdateRestartProgress(restarted, total int) {
+	if t.program != nil {
+		t.program.Send(StartupProgressMsg{Started: restarted, Total: total, Restarting: true})
+	}
+}
+
 // NotifyUpdateAvailable sends an update notification to the TUI
 func (t *TUI) NotifyUpdateAvailable(updateInfo *updater.UpdateInfo) {
 	if t.program != nil {
 		t.program.Send(UpdateAvailableMsg(updateInfo != nil && updateInfo.Available))
 	}
 }
+
+// AddClusterEvent shows a pod event in the cluster events panel
+func (t *TUI) AddClusterEvent(event clusterwatch.Event) {
+	if t.program != nil {
+		t.program.Send(ClusterEventMsg(event))
+	}
+}
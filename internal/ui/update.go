@@ -0,0 +1,60 @@
+package ui
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/victorkazakov/kportforward/internal/updater"
+)
+
+// UpdateDownloader downloads the available update, reporting progress, and returns
+// where the new binary was saved
+type UpdateDownloader func(progress updater.ProgressFunc) (string, error)
+
+// UpdateProgressMsg reports how far the update download has come
+type UpdateProgressMsg updater.Progress
+
+// UpdateDownloadedMsg reports the outcome of downloading the update
+type UpdateDownloadedMsg struct {
+	Path string
+	Err  error
+}
+
+// downloadUpdate downloads the available update in the background
+func (m *Model) downloadUpdate() tea.Cmd {
+	if !m.updateAvailable || m.updateProgress != nil {
+		return nil
+	}
+	if m.downloader == nil {
+		m.showNotice("downloading updates is not available", true)
+		return nil
+	}
+
+	m.updateProgress = &updater.Progress{}
+	downloader := m.downloader
+	return func() tea.Msg {
+		path, err := downloader()
+		return UpdateDownloadedMsg{Path: path, Err: err}
+	}
+}
+
+// handleUpdateDownloaded reports where the update was saved
+func (m *Model) handleUpdateDownloaded(msg UpdateDownloadedMsg) {
+	m.updateProgress = nil
+	if msg.Err != nil {
+		m.showNotice("Update download failed (press U to resume): "+msg.Err.Error(), true)
+		return
+	}
+	m.showNotice(fmt.Sprintf("Update downloaded to %s; replace the kportforward binary with it to finish", msg.Path), false)
+}
+
+// renderUpdateNotice renders the header's update notice, with progress while downloading
+func (m *Model) renderUpdateNotice() string {
+	if m.updateProgress != nil {
+		return "Downloading update: " + m.updateProgress.String()
+	}
+	if m.updateAvailable {
+		return "Update Available! [U] Download"
+	}
+	return ""
+}
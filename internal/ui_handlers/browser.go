@@ -0,0 +1,104 @@
+package ui_handlers
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+// URLProvider exposes the URL of a UI attached to a service
+type URLProvider interface {
+	GetServiceURL(serviceName string) string
+}
+
+// BrowserOpener opens service URLs in the default browser once services are running
+type BrowserOpener struct {
+	logger    *utils.Logger
+	openAll   bool
+	providers []URLProvider
+	opened    map[string]bool
+	openFunc  func(url string) error
+	mutex     sync.Mutex
+	enabled   bool
+}
+
+// NewBrowserOpener creates a new browser opener. When openAll is false only
+// services with autoOpen set in their configuration are opened.
+func NewBrowserOpener(logger *utils.Logger, openAll bool) *BrowserOpener {
+	return &BrowserOpener{
+		logger:   logger,
+		openAll:  openAll,
+		opened:   make(map[string]bool),
+		openFunc: utils.OpenBrowser,
+		enabled:  true,
+	}
+}
+
+// AddURLProvider registers a UI manager (gRPC UI, Swagger UI) whose URLs should also be opened
+func (bo *BrowserOpener) AddURLProvider(provider URLProvider) {
+	bo.mutex.Lock()
+	defer bo.mutex.Unlock()
+	bo.providers = append(bo.providers, provider)
+}
+
+// StartService is a no-op; URLs are opened from MonitorServices once they are available
+func (bo *BrowserOpener) StartService(serviceName string, serviceStatus config.ServiceStatus, serviceConfig config.Service) error {
+	return nil
+}
+
+// StopService is a no-op; opened tabs are intentionally not tracked per restart
+func (bo *BrowserOpener) StopService(serviceName string) error {
+	return nil
+}
+
+// IsEnabled returns whether the browser opener is enabled
+func (bo *BrowserOpener) IsEnabled() bool {
+	return bo.enabled
+}
+
+// MonitorServices opens URLs for services that have reached Running. Each URL
+// source is opened at most once per session so restarts don't re-open tabs.
+func (bo *BrowserOpener) MonitorServices(services map[string]config.ServiceStatus, configs map[string]config.Service) {
+	bo.mutex.Lock()
+	defer bo.mutex.Unlock()
+
+	for serviceName, serviceStatus := range services {
+		if serviceStatus.Status != "Running" {
+			continue
+		}
+
+		serviceConfig, exists := configs[serviceName]
+		if !exists || !(bo.openAll || serviceConfig.AutoOpen) {
+			continue
+		}
+
+		// Open the service itself for web services, or when explicitly requested
+		if serviceConfig.Type == "web" || (serviceConfig.AutoOpen && serviceConfig.Type != "rpc") {
+			bo.open(serviceName, "service", serviceConfig.LocalURL(serviceStatus.LocalPort))
+		}
+
+		// Open any attached gRPC UI / Swagger UI once it is up
+		for i, provider := range bo.providers {
+			if url := provider.GetServiceURL(serviceName); url != "" {
+				bo.open(serviceName, fmt.Sprintf("provider-%d", i), url)
+			}
+		}
+	}
+}
+
+// open opens a URL unless it was already opened for the given service and source (assumes lock is held)
+func (bo *BrowserOpener) open(serviceName, source, url string) {
+	key := serviceName + "/" + source
+	if bo.opened[key] {
+		return
+	}
+	bo.opened[key] = true
+
+	if err := bo.openFunc(url); err != nil {
+		bo.logger.Warn("Failed to open %s for %s: %v", url, serviceName, err)
+		return
+	}
+	bo.logger.Info("Opened %s for %s in browser", url, serviceName)
+}
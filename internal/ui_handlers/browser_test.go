@@ -0,0 +1,75 @@
+package ui_handlers
+
+import (
+	"testing"
+
+	"github.com/victorkazakov/kportforward/internal/config"
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+type staticURLProvider map[string]string
+
+func (p staticURLProvider) GetServiceURL(serviceName string) string {
+	return p[serviceName]
+}
+
+func TestBrowserOpenerOpensOnce(t *testing.T) {
+	logger := utils.NewLogger(utils.LevelError)
+	opener := NewBrowserOpener(logger, true)
+
+	var opened []string
+	opener.openFunc = func(url string) error {
+		opened = append(opened, url)
+		return nil
+	}
+
+	configs := map[string]config.Service{
+		"console": {Type: "web", LocalPort: 8088},
+		"backend": {Type: "rpc", LocalPort: 50051},
+	}
+	services := map[string]config.ServiceStatus{
+		"console": {Name: "console", Status: "Running", LocalPort: 8088},
+		"backend": {Name: "backend", Status: "Running", LocalPort: 50051},
+	}
+
+	opener.MonitorServices(services, configs)
+	opener.MonitorServices(services, configs)
+
+	if len(opened) != 1 || opened[0] != "http://localhost:8088" {
+		t.Errorf("Expected console to be opened exactly once, got %v", opened)
+	}
+}
+
+func TestBrowserOpenerAutoOpenAndProviders(t *testing.T) {
+	logger := utils.NewLogger(utils.LevelError)
+	opener := NewBrowserOpener(logger, false)
+	opener.AddURLProvider(staticURLProvider{"backend": "http://localhost:9090"})
+
+	var opened []string
+	opener.openFunc = func(url string) error {
+		opened = append(opened, url)
+		return nil
+	}
+
+	configs := map[string]config.Service{
+		"console": {Type: "web", LocalPort: 8088},
+		"backend": {Type: "rpc", LocalPort: 50051, AutoOpen: true},
+	}
+	services := map[string]config.ServiceStatus{
+		"console": {Name: "console", Status: "Running", LocalPort: 8088},
+		"backend": {Name: "backend", Status: "Starting", LocalPort: 50051},
+	}
+
+	// Nothing is running with autoOpen yet
+	opener.MonitorServices(services, configs)
+	if len(opened) != 0 {
+		t.Fatalf("Expected nothing to be opened, got %v", opened)
+	}
+
+	// Once running, the rpc service opens its UI URL rather than the raw port
+	services["backend"] = config.ServiceStatus{Name: "backend", Status: "Running", LocalPort: 50051}
+	opener.MonitorServices(services, configs)
+	if len(opened) != 1 || opened[0] != "http://localhost:9090" {
+		t.Errorf("Expected gRPC UI URL to be opened, got %v", opened)
+	}
+}
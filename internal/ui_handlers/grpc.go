@@ -103,7 +103,7 @@ func (gm *GRPCUIManager) StartService(serviceName string, serviceStatus config.S
 	}
 
 	// Start grpcui process
-	cmd, err := gm.startGRPCUIProcess(serviceName, serviceStatus.LocalPort, grpcuiPort, logFile)
+	cmd, err := gm.startGRPCUIProcess(serviceName, serviceStatus.LocalPort, grpcuiPort, logFile, serviceConfig.LocalTLS)
 	if err != nil {
 		return fmt.Errorf("failed to start grpcui process: %w", err)
 	}
@@ -194,12 +194,18 @@ func (gm *GRPCUIManager) isGRPCUIAvailable() bool {
 }
 
 // startGRPCUIProcess starts the grpcui process
-func (gm *GRPCUIManager) startGRPCUIProcess(serviceName string, targetPort, grpcuiPort int, logFile string) (*exec.Cmd, error) {
+func (gm *GRPCUIManager) startGRPCUIProcess(serviceName string, targetPort, grpcuiPort int, logFile string, localTLS bool) (*exec.Cmd, error) {
+	// Services behind local TLS use the self-signed localhost certificate
+	transport := "-plaintext"
+	if localTLS {
+		transport = "-insecure"
+	}
+
 	// grpcui arguments
 	args := []string{
 		"-bind", "localhost",
 		"-port", fmt.Sprintf("%d", grpcuiPort),
-		"-plaintext",
+		transport,
 		fmt.Sprintf("localhost:%d", targetPort),
 	}
 
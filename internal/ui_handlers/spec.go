@@ -0,0 +1,60 @@
+package ui_handlers
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// specTimeout bounds fetching a service's OpenAPI document
+const specTimeout = 5 * time.Second
+
+// maxSpecSize caps how much of an OpenAPI document is read
+const maxSpecSize = 16 << 20
+
+// checkSpec fetches an OpenAPI document and checks that it parses, so a wrong
+// swaggerPath shows up as a precise error instead of a blank Swagger UI
+func checkSpec(baseURL, swaggerPath string) error {
+	client := &http.Client{
+		Timeout: specTimeout,
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true}, // The forward's local certificate
+		},
+	}
+
+	path := "/" + strings.TrimPrefix(swaggerPath, "/")
+	resp, err := client.Get(baseURL + path)
+	if err != nil {
+		return fmt.Errorf("failed to fetch %s: %w", path, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%d at %s", resp.StatusCode, path)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxSpecSize))
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	var document map[string]interface{}
+	trimmed := strings.TrimSpace(string(body))
+	if strings.HasPrefix(trimmed, "{") || strings.Contains(resp.Header.Get("Content-Type"), "json") {
+		if err := json.Unmarshal(body, &document); err != nil {
+			return fmt.Errorf("invalid JSON at %s: %w", path, err)
+		}
+	} else if err := yaml.Unmarshal(body, &document); err != nil || document == nil {
+		return fmt.Errorf("%s is neither JSON nor YAML", path)
+	}
+
+	if document["openapi"] == nil && document["swagger"] == nil {
+		return fmt.Errorf("%s is not an OpenAPI document (no openapi or swagger field)", path)
+	}
+	return nil
+}
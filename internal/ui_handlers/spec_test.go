@@ -0,0 +1,52 @@
+package ui_handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCheckSpec(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/openapi.json":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"openapi": "3.0.0", "paths": {}}`))
+		case "/openapi.yaml":
+			w.Write([]byte("swagger: \"2.0\"\npaths: {}\n"))
+		case "/broken.json":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"openapi": `))
+		case "/health":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"status": "ok"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{"openapi.json", ""},
+		{"/openapi.yaml", ""},
+		{"configuration/swagger", "404 at /configuration/swagger"},
+		{"broken.json", "invalid JSON at /broken.json"},
+		{"health", "/health is not an OpenAPI document"},
+	}
+	for _, test := range tests {
+		err := checkSpec(server.URL, test.path)
+		if test.expected == "" {
+			if err != nil {
+				t.Errorf("Expected %s to pass, got %v", test.path, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), test.expected) {
+			t.Errorf("Expected an error containing %q for %s, got %v", test.expected, test.path, err)
+		}
+	}
+}
@@ -31,8 +31,12 @@ type SwaggerUIService struct {
 	status        string
 	swaggerPath   string
 	apiPath       string
+	lastError     string // Why the Swagger UI couldn't start, e.g. "404 at /configuration/swagger"
 }
 
+// specRetryInterval is how long a Swagger UI whose spec check failed waits before checking again
+const specRetryInterval = 30 * time.Second
+
 // NewSwaggerUIManager creates a new Swagger UI manager
 func NewSwaggerUIManager(logger *utils.Logger) *SwaggerUIManager {
 	return &SwaggerUIManager{
@@ -106,8 +110,27 @@ func (sm *SwaggerUIManager) StartService(serviceName string, serviceStatus confi
 		apiPath = "api" // Default API path
 	}
 
+	scheme := "http"
+	if serviceConfig.LocalTLS {
+		scheme = "https"
+	}
+
+	// Catch a wrong swaggerPath before it turns into a blank UI
+	if err := checkSpec(fmt.Sprintf("%s://localhost:%d", scheme, serviceStatus.LocalPort), swaggerPath); err != nil {
+		sm.services[serviceName] = &SwaggerUIService{
+			serviceName: serviceName,
+			localPort:   serviceStatus.LocalPort,
+			startTime:   time.Now(),
+			status:      "Failed",
+			swaggerPath: swaggerPath,
+			apiPath:     apiPath,
+			lastError:   err.Error(),
+		}
+		return fmt.Errorf("OpenAPI spec check failed: %w", err)
+	}
+
 	// Start Docker container
-	containerID, containerName, err := sm.startSwaggerContainer(serviceName, serviceStatus.LocalPort, swaggerPort, swaggerPath, apiPath)
+	containerID, containerName, err := sm.startSwaggerContainer(serviceName, scheme, serviceStatus.LocalPort, swaggerPort, swaggerPath, apiPath)
 	if err != nil {
 		return fmt.Errorf("failed to start Swagger UI container: %w", err)
 	}
@@ -179,6 +202,16 @@ func (sm *SwaggerUIManager) GetServiceInfo(serviceName string) *SwaggerUIService
 	return service
 }
 
+// Status returns the state of the Swagger UI: Running, Failed or Stopped
+func (s *SwaggerUIService) Status() string {
+	return s.status
+}
+
+// LastError returns why the Swagger UI couldn't start, if it failed
+func (s *SwaggerUIService) LastError() string {
+	return s.lastError
+}
+
 // GetServiceURL returns the URL for accessing the Swagger UI
 func (sm *SwaggerUIManager) GetServiceURL(serviceName string) string {
 	service := sm.GetServiceInfo(serviceName)
@@ -202,7 +235,7 @@ func (sm *SwaggerUIManager) isDockerAvailable() bool {
 }
 
 // startSwaggerContainer starts a Docker container with Swagger UI
-func (sm *SwaggerUIManager) startSwaggerContainer(serviceName string, targetPort, swaggerPort int, swaggerPath, apiPath string) (string, string, error) {
+func (sm *SwaggerUIManager) startSwaggerContainer(serviceName, scheme string, targetPort, swaggerPort int, swaggerPath, apiPath string) (string, string, error) {
 	containerName := fmt.Sprintf("kpf-swagger-%s", strings.ReplaceAll(serviceName, "_", "-"))
 
 	// Stop any existing container with the same name
@@ -215,7 +248,7 @@ func (sm *SwaggerUIManager) startSwaggerContainer(serviceName string, targetPort
 		"--rm", // Remove container when it stops
 		"--name", containerName,
 		"-p", fmt.Sprintf("%d:8080", swaggerPort),
-		"-e", fmt.Sprintf("SWAGGER_JSON=http://host.docker.internal:%d/%s", targetPort, swaggerPath),
+		"-e", fmt.Sprintf("SWAGGER_JSON=%s://host.docker.internal:%d/%s", scheme, targetPort, swaggerPath),
 		"swaggerapi/swagger-ui",
 	}
 
@@ -228,7 +261,7 @@ func (sm *SwaggerUIManager) startSwaggerContainer(serviceName string, targetPort
 		// Update the environment variable for Linux
 		for i, arg := range args {
 			if strings.HasPrefix(arg, "SWAGGER_JSON=") {
-				args[i] = fmt.Sprintf("SWAGGER_JSON=http://localhost:%d/%s", targetPort, swaggerPath)
+				args[i] = fmt.Sprintf("SWAGGER_JSON=%s://localhost:%d/%s", scheme, targetPort, swaggerPath)
 				break
 			}
 		}
@@ -296,7 +329,12 @@ func (sm *SwaggerUIManager) MonitorServices(services map[string]config.ServiceSt
 	for serviceName, serviceStatus := range services {
 		if serviceConfig, exists := configs[serviceName]; exists {
 			if serviceConfig.Type == "rest" && serviceStatus.Status == "Running" {
-				if _, uiExists := sm.services[serviceName]; !uiExists {
+				service, uiExists := sm.services[serviceName]
+				retry := uiExists && service.status == "Failed" && time.Since(service.startTime) >= specRetryInterval
+				if retry {
+					service.startTime = time.Now() // Don't queue another check while this one runs
+				}
+				if !uiExists || retry {
 					go func(name string, status config.ServiceStatus, config config.Service) {
 						if err := sm.StartService(name, status, config); err != nil {
 							sm.logger.Error("Failed to start Swagger UI for %s: %v", name, err)
@@ -0,0 +1,65 @@
+package updater
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Update channels: stable releases only, prereleases as well, or nightly builds too
+const (
+	ChannelStable  = "stable"
+	ChannelBeta    = "beta"
+	ChannelNightly = "nightly"
+)
+
+// Channels lists the update channels, most conservative first
+var Channels = []string{ChannelStable, ChannelBeta, ChannelNightly}
+
+// ValidateChannel checks that channel is one of Channels; empty means stable
+func ValidateChannel(channel string) error {
+	if channel == "" {
+		return nil
+	}
+	for _, known := range Channels {
+		if channel == known {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown update channel %q (expected %s)", channel, strings.Join(Channels, ", "))
+}
+
+// isNightly reports whether a release is a nightly build, tagged e.g. nightly-20260101
+// or v1.3.0-nightly.20260101
+func isNightly(release Release) bool {
+	return strings.Contains(strings.ToLower(release.TagName), "nightly")
+}
+
+// isPrereleaseTag reports whether a tag names a prerelease, e.g. v1.3.0-beta.1, for
+// sources without a prerelease flag
+func isPrereleaseTag(tag string) bool {
+	return strings.Contains(tag, "-") || strings.Contains(strings.ToLower(tag), "nightly")
+}
+
+// selectRelease returns the newest release of releases, listed newest first, that the
+// channel takes: stable skips prereleases, beta skips nightly builds and nightly takes
+// any published release. nil when none qualifies.
+func selectRelease(releases []Release, channel string) *Release {
+	for i, release := range releases {
+		if release.Draft {
+			continue
+		}
+		switch channel {
+		case ChannelNightly:
+		case ChannelBeta:
+			if isNightly(release) {
+				continue
+			}
+		default:
+			if release.Prerelease || isNightly(release) {
+				continue
+			}
+		}
+		return &releases[i]
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package updater
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+func TestSelectRelease(t *testing.T) {
+	releases := []Release{
+		{TagName: "v1.4.0-draft", Draft: true},
+		{TagName: "nightly-20260102", Prerelease: true},
+		{TagName: "v1.3.0-beta.1", Prerelease: true},
+		{TagName: "v1.2.0"},
+	}
+
+	tests := map[string]string{
+		ChannelStable:  "v1.2.0",
+		ChannelBeta:    "v1.3.0-beta.1",
+		ChannelNightly: "nightly-20260102",
+	}
+	for channel, expected := range tests {
+		release := selectRelease(releases, channel)
+		if release == nil || release.TagName != expected {
+			t.Errorf("Expected %s on the %s channel, got %+v", expected, channel, release)
+		}
+	}
+
+	if release := selectRelease(releases[:3], ChannelStable); release != nil {
+		t.Errorf("Expected no stable release, got %s", release.TagName)
+	}
+}
+
+func TestIsNewerVersion(t *testing.T) {
+	checker := NewChecker(&UpdateConfig{}, utils.NewLogger(utils.LevelError))
+	tests := []struct {
+		latest, current string
+		newer           bool
+	}{
+		{"v1.10.0", "v1.9.0", true},
+		{"v1.3.0", "v1.3.0-beta.2", true},
+		{"v1.3.0-beta.2", "v1.3.0", false},
+		{"v1.3.0-beta.10", "v1.3.0-beta.2", true},
+		{"v1.3.0-rc.1", "v1.3.0-beta.2", true},
+		{"v1.2.0", "v1.2.0", false},
+		{"v1.2.0", "dev", true},
+		{"nightly-20260102", "nightly-20260101", true},
+	}
+	for _, test := range tests {
+		if newer := checker.isNewerVersion(test.latest, test.current); newer != test.newer {
+			t.Errorf("isNewerVersion(%s, %s) = %v, expected %v", test.latest, test.current, newer, test.newer)
+		}
+	}
+}
+
+func TestCheckerFollowsChannel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.EscapedPath() != "/api/v4/projects/tools%2Fkportforward/releases" {
+			t.Errorf("Unexpected path %s", r.URL.EscapedPath())
+		}
+		w.Write([]byte(`[
+			{"tag_name": "v1.3.0-beta.1"},
+			{"tag_name": "v1.2.0"}
+		]`))
+	}))
+	defer server.Close()
+
+	source, err := NewSource(SourceConfig{Provider: "gitlab", URL: server.URL, Repo: "tools/kportforward"})
+	if err != nil {
+		t.Fatalf("NewSource failed: %v", err)
+	}
+	for channel, expected := range map[string]string{ChannelStable: "v1.2.0", ChannelBeta: "v1.3.0-beta.1"} {
+		m := NewManager(source, "v1.1.0", utils.NewLogger(utils.LevelError))
+		m.config.LastCheckFile = ""
+		m.checker.client = server.Client()
+		if err := m.SetChannel(channel); err != nil {
+			t.Fatalf("SetChannel(%s) failed: %v", channel, err)
+		}
+		info, err := m.ForceCheck()
+		if err != nil {
+			t.Fatalf("Check on the %s channel failed: %v", channel, err)
+		}
+		if !info.Available || info.LatestVersion != expected {
+			t.Errorf("Expected %s on the %s channel, got %+v", expected, channel, info)
+		}
+	}
+
+	if err := NewManager(source, "v1.1.0", utils.NewLogger(utils.LevelError)).SetChannel("canary"); err == nil {
+		t.Error("Expected an unknown channel to be rejected")
+	}
+}
@@ -1,13 +1,12 @@
 package updater
 
 import (
-	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 	"os"
 	"path/filepath"
 	"runtime"
+	"strconv"
 	"strings"
 	"time"
 
@@ -42,7 +41,7 @@ func (c *Checker) CheckForUpdates() (*UpdateInfo, error) {
 		return &UpdateInfo{Available: false}, nil
 	}
 
-	// Get latest release from GitHub
+	// Get latest release from the release source
 	release, err := c.getLatestRelease()
 	if err != nil {
 		c.logger.Error("Failed to fetch latest release: %v", err)
@@ -66,32 +65,21 @@ func (c *Checker) CheckForUpdates() (*UpdateInfo, error) {
 	return updateInfo, nil
 }
 
-// getLatestRelease fetches the latest release from GitHub API
+// getLatestRelease fetches the latest release of the update channel from the configured source
 func (c *Checker) getLatestRelease() (*Release, error) {
-	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/releases/latest",
-		c.config.RepoOwner, c.config.RepoName)
-
-	resp, err := c.client.Get(url)
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch release data: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("GitHub API returned status %d", resp.StatusCode)
+	if c.config.UpdateChannel == "" || c.config.UpdateChannel == ChannelStable {
+		return c.config.Source.LatestRelease(c.client)
 	}
 
-	body, err := io.ReadAll(resp.Body)
+	releases, err := c.config.Source.Releases(c.client)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+		return nil, err
 	}
-
-	var release Release
-	if err := json.Unmarshal(body, &release); err != nil {
-		return nil, fmt.Errorf("failed to parse release data: %w", err)
+	release := selectRelease(releases, c.config.UpdateChannel)
+	if release == nil {
+		return nil, fmt.Errorf("no releases for the %s channel in %s", c.config.UpdateChannel, c.config.Source)
 	}
-
-	return &release, nil
+	return release, nil
 }
 
 // compareVersions compares current version with latest release
@@ -111,8 +99,18 @@ func (c *Checker) compareVersions(release *Release) *UpdateInfo {
 		asset := c.findAssetForPlatform(release.Assets)
 		if asset != nil {
 			updateInfo.DownloadURL = asset.BrowserDownloadURL
+			updateInfo.AssetName = asset.Name
 			updateInfo.AssetSize = asset.Size
 		}
+
+		if checksums := findChecksumsAsset(release.Assets); checksums != nil {
+			updateInfo.ChecksumsURL = checksums.BrowserDownloadURL
+			if suffix, ok := signatureSuffixes[c.config.Verification.Signature]; ok {
+				if signature := findAsset(release.Assets, checksums.Name+suffix); signature != nil {
+					updateInfo.SignatureURL = signature.BrowserDownloadURL
+				}
+			}
+		}
 	}
 
 	return updateInfo
@@ -129,11 +127,96 @@ func (c *Checker) isNewerVersion(versionA, versionB string) bool {
 		return true
 	}
 
-	// Simple string comparison for now
-	// In production, you'd want proper semantic version parsing
+	if cmp, ok := compareSemver(versionA, versionB); ok {
+		return cmp > 0
+	}
+
+	// Tags that aren't semantic versions, e.g. nightly-20260101, compare as strings
 	return versionA > versionB
 }
 
+// compareSemver compares two semantic versions without the v prefix, returning -1, 0
+// or 1, and false if either isn't one. A prerelease sorts before its release, so beta
+// users are offered the final release.
+func compareSemver(a, b string) (int, bool) {
+	coreA, preA, _ := strings.Cut(strings.SplitN(a, "+", 2)[0], "-")
+	coreB, preB, _ := strings.Cut(strings.SplitN(b, "+", 2)[0], "-")
+	partsA, okA := parseVersionCore(coreA)
+	partsB, okB := parseVersionCore(coreB)
+	if !okA || !okB {
+		return 0, false
+	}
+
+	for i := range partsA {
+		if partsA[i] != partsB[i] {
+			if partsA[i] > partsB[i] {
+				return 1, true
+			}
+			return -1, true
+		}
+	}
+	switch {
+	case preA == preB:
+		return 0, true
+	case preA == "":
+		return 1, true
+	case preB == "":
+		return -1, true
+	}
+	return comparePrerelease(preA, preB), true
+}
+
+// parseVersionCore parses major.minor.patch; a missing minor or patch counts as 0
+func parseVersionCore(core string) ([3]int, bool) {
+	var parts [3]int
+	fields := strings.Split(core, ".")
+	if len(fields) > 3 {
+		return parts, false
+	}
+	for i, field := range fields {
+		n, err := strconv.Atoi(field)
+		if err != nil || n < 0 {
+			return parts, false
+		}
+		parts[i] = n
+	}
+	return parts, true
+}
+
+// comparePrerelease compares prerelease identifiers like beta.2 and rc.1 field by field,
+// numbers numerically and before words
+func comparePrerelease(a, b string) int {
+	fieldsA, fieldsB := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(fieldsA) && i < len(fieldsB); i++ {
+		numA, errA := strconv.Atoi(fieldsA[i])
+		numB, errB := strconv.Atoi(fieldsB[i])
+		switch {
+		case errA == nil && errB == nil:
+			if numA != numB {
+				if numA > numB {
+					return 1
+				}
+				return -1
+			}
+		case errA == nil:
+			return -1
+		case errB == nil:
+			return 1
+		default:
+			if cmp := strings.Compare(fieldsA[i], fieldsB[i]); cmp != 0 {
+				return cmp
+			}
+		}
+	}
+	switch {
+	case len(fieldsA) > len(fieldsB):
+		return 1
+	case len(fieldsA) < len(fieldsB):
+		return -1
+	}
+	return 0
+}
+
 // findAssetForPlatform finds the appropriate asset for the current platform
 func (c *Checker) findAssetForPlatform(assets []Asset) *Asset {
 	// Determine platform-specific binary name
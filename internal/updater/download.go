@@ -0,0 +1,237 @@
+package updater
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+)
+
+const (
+	downloadAttempts     = 5                // Attempts before giving up, each resuming where the last stopped
+	downloadRetryDelay   = 2 * time.Second  // Pause between attempts
+	downloadStallTimeout = 30 * time.Second // Abort an attempt that received nothing for this long
+	progressInterval     = 250 * time.Millisecond
+)
+
+// Progress reports how far a download has come
+type Progress struct {
+	Downloaded int64
+	Total      int64         // 0 when the size is unknown
+	ETA        time.Duration // 0 when unknown
+}
+
+// ProgressFunc receives download progress, a few times per second and once more when done
+type ProgressFunc func(Progress)
+
+// Percent returns the share downloaded so far, or -1 when the size is unknown
+func (p Progress) Percent() float64 {
+	if p.Total <= 0 {
+		return -1
+	}
+	return float64(p.Downloaded) / float64(p.Total) * 100
+}
+
+// String formats the progress, e.g. "4.2 MB / 12.0 MB (35%), ETA 8s"
+func (p Progress) String() string {
+	if p.Total <= 0 {
+		return formatBytes(p.Downloaded)
+	}
+	s := fmt.Sprintf("%s / %s (%.0f%%)", formatBytes(p.Downloaded), formatBytes(p.Total), p.Percent())
+	if p.ETA > 0 {
+		s += fmt.Sprintf(", ETA %v", p.ETA.Round(time.Second))
+	}
+	return s
+}
+
+// formatBytes formats a byte count in decimal units
+func formatBytes(n int64) string {
+	switch {
+	case n >= 1e9:
+		return fmt.Sprintf("%.1f GB", float64(n)/1e9)
+	case n >= 1e6:
+		return fmt.Sprintf("%.1f MB", float64(n)/1e6)
+	case n >= 1e3:
+		return fmt.Sprintf("%.1f KB", float64(n)/1e3)
+	default:
+		return fmt.Sprintf("%d B", n)
+	}
+}
+
+// statusError is an unexpected HTTP status from the download server
+type statusError struct {
+	code int
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("download server returned status %d", e.code)
+}
+
+// retryable reports whether another attempt might succeed
+func retryable(err error) bool {
+	var statusErr *statusError
+	if errors.As(err, &statusErr) {
+		return statusErr.code >= 500 || statusErr.code == http.StatusRequestTimeout || statusErr.code == http.StatusTooManyRequests
+	}
+	return true
+}
+
+// Download fetches url into path. Data is written to path + ".part" first, and a
+// partial file left by an interrupted attempt, or an earlier run, is resumed with a
+// Range request when the server supports it. size is the expected size, 0 if unknown.
+func Download(ctx context.Context, client *http.Client, url, path string, size int64, progress ProgressFunc) error {
+	partPath := path + ".part"
+	tracker := &progressTracker{total: size, report: progress, started: time.Now(), resumedFrom: -1}
+
+	var err error
+	for attempt := 1; attempt <= downloadAttempts; attempt++ {
+		if err = downloadAttempt(ctx, client, url, partPath, tracker); err == nil {
+			break
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if !retryable(err) || attempt == downloadAttempts {
+			return err
+		}
+
+		select {
+		case <-time.After(downloadRetryDelay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	tracker.flush()
+	if err := os.Rename(partPath, path); err != nil {
+		return fmt.Errorf("failed to move download into place: %w", err)
+	}
+	return nil
+}
+
+// downloadAttempt downloads the rest of the file after what partPath already holds
+func downloadAttempt(ctx context.Context, client *http.Client, url, partPath string, tracker *progressTracker) error {
+	var offset int64
+	if info, err := os.Stat(partPath); err == nil {
+		offset = info.Size()
+	}
+	if tracker.total > 0 && offset > tracker.total {
+		offset = 0 // Not a prefix of this asset
+	}
+	if tracker.resumedFrom < 0 {
+		tracker.resumedFrom = offset
+	}
+	if tracker.total > 0 && offset == tracker.total {
+		tracker.downloaded = offset
+		return nil
+	}
+
+	// Abort the request when no data arrives for a while, so a stalled VPN connection is retried
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stall := time.AfterFunc(downloadStallTimeout, cancel)
+	defer stall.Stop()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to download update: %w", err)
+	}
+	defer resp.Body.Close()
+
+	flags := os.O_CREATE | os.O_WRONLY
+	switch {
+	case resp.StatusCode == http.StatusPartialContent && offset > 0:
+		flags |= os.O_APPEND
+	case resp.StatusCode == http.StatusOK:
+		flags |= os.O_TRUNC // The server ignored the range; start over
+		if offset > 0 {
+			offset = 0
+			tracker.resumedFrom = 0
+			tracker.started = time.Now()
+		}
+	case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable:
+		os.Remove(partPath) // The partial file doesn't match the asset; start over next attempt
+		return fmt.Errorf("download server rejected resuming at byte %d", offset)
+	default:
+		return &statusError{code: resp.StatusCode}
+	}
+	if tracker.total == 0 && resp.ContentLength > 0 {
+		tracker.total = offset + resp.ContentLength
+	}
+
+	f, err := os.OpenFile(partPath, flags, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open download file: %w", err)
+	}
+	defer f.Close()
+
+	tracker.downloaded = offset
+	buf := make([]byte, 32*1024)
+	for {
+		n, readErr := resp.Body.Read(buf)
+		if n > 0 {
+			stall.Reset(downloadStallTimeout)
+			if _, err := f.Write(buf[:n]); err != nil {
+				return fmt.Errorf("failed to write download file: %w", err)
+			}
+			tracker.add(int64(n))
+		}
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			return fmt.Errorf("download interrupted after %s: %w", formatBytes(tracker.downloaded), readErr)
+		}
+	}
+
+	if tracker.total > 0 && tracker.downloaded != tracker.total {
+		return fmt.Errorf("download incomplete: got %d of %d bytes", tracker.downloaded, tracker.total)
+	}
+	return nil
+}
+
+// progressTracker throttles progress reports and estimates the remaining time from
+// the rate since the download started, not counting resumed bytes
+type progressTracker struct {
+	total       int64
+	downloaded  int64
+	report      ProgressFunc
+	started     time.Time
+	resumedFrom int64 // Bytes already on disk when the download started, -1 before the first attempt
+	lastReport  time.Time
+}
+
+// add records received bytes and reports progress if enough time passed since the last report
+func (t *progressTracker) add(n int64) {
+	t.downloaded += n
+	if time.Since(t.lastReport) >= progressInterval {
+		t.flush()
+	}
+}
+
+// flush reports the current progress
+func (t *progressTracker) flush() {
+	t.lastReport = time.Now()
+	if t.report == nil {
+		return
+	}
+
+	progress := Progress{Downloaded: t.downloaded, Total: t.total}
+	received := t.downloaded - t.resumedFrom
+	if elapsed := time.Since(t.started); t.total > 0 && received > 0 && elapsed > 0 {
+		rate := float64(received) / elapsed.Seconds()
+		progress.ETA = time.Duration(float64(t.total-t.downloaded) / rate * float64(time.Second))
+	}
+	t.report(progress)
+}
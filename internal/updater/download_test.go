@@ -0,0 +1,127 @@
+package updater
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDownload(t *testing.T) {
+	content := bytes.Repeat([]byte("kportforward"), 10000)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeContent(w, r, "asset", time.Time{}, bytes.NewReader(content))
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "kportforward")
+	var last Progress
+	if err := Download(context.Background(), server.Client(), server.URL, path, int64(len(content)), func(p Progress) { last = p }); err != nil {
+		t.Fatalf("Download failed: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read download: %v", err)
+	}
+	if !bytes.Equal(data, content) {
+		t.Errorf("Downloaded content differs: got %d bytes, expected %d", len(data), len(content))
+	}
+	if last.Downloaded != int64(len(content)) || last.Total != int64(len(content)) {
+		t.Errorf("Expected final progress of %d bytes, got %+v", len(content), last)
+	}
+	if _, err := os.Stat(path + ".part"); !os.IsNotExist(err) {
+		t.Errorf("Expected the partial file to be gone, got %v", err)
+	}
+}
+
+func TestDownloadResumes(t *testing.T) {
+	content := bytes.Repeat([]byte("0123456789"), 1000)
+	var ranges []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ranges = append(ranges, r.Header.Get("Range"))
+		http.ServeContent(w, r, "asset", time.Time{}, bytes.NewReader(content))
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "kportforward")
+	if err := os.WriteFile(path+".part", content[:4000], 0644); err != nil {
+		t.Fatalf("Failed to write partial file: %v", err)
+	}
+
+	if err := Download(context.Background(), server.Client(), server.URL, path, int64(len(content)), nil); err != nil {
+		t.Fatalf("Download failed: %v", err)
+	}
+
+	if len(ranges) != 1 || ranges[0] != "bytes=4000-" {
+		t.Errorf("Expected one request resuming at 4000, got %v", ranges)
+	}
+	data, _ := os.ReadFile(path)
+	if !bytes.Equal(data, content) {
+		t.Errorf("Resumed content differs: got %d bytes, expected %d", len(data), len(content))
+	}
+}
+
+func TestDownloadRestartsWithoutRangeSupport(t *testing.T) {
+	content := []byte(strings.Repeat("a", 5000))
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(content) // Ignores Range
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "kportforward")
+	if err := os.WriteFile(path+".part", []byte("stale"), 0644); err != nil {
+		t.Fatalf("Failed to write partial file: %v", err)
+	}
+
+	if err := Download(context.Background(), server.Client(), server.URL, path, 0, nil); err != nil {
+		t.Fatalf("Download failed: %v", err)
+	}
+	data, _ := os.ReadFile(path)
+	if !bytes.Equal(data, content) {
+		t.Errorf("Expected the partial file to be replaced, got %d bytes", len(data))
+	}
+}
+
+func TestDownloadDoesNotRetryNotFound(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "kportforward")
+	err := Download(context.Background(), server.Client(), server.URL, path, 0, nil)
+	if err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("Expected a 404 error, got %v", err)
+	}
+	if requests != 1 {
+		t.Errorf("Expected a single request, got %d", requests)
+	}
+}
+
+func TestProgressString(t *testing.T) {
+	tests := []struct {
+		progress Progress
+		expected string
+	}{
+		{Progress{Downloaded: 4200000, Total: 12000000, ETA: 8 * time.Second}, "4.2 MB / 12.0 MB (35%), ETA 8s"},
+		{Progress{Downloaded: 500, Total: 1000}, "500 B / 1.0 KB (50%)"},
+		{Progress{Downloaded: 2500}, "2.5 KB"},
+	}
+
+	for _, test := range tests {
+		if got := test.progress.String(); got != test.expected {
+			t.Errorf("%+v: expected %q, got %q", test.progress, test.expected, got)
+		}
+	}
+	if percent := (Progress{Downloaded: 10}).Percent(); percent != -1 {
+		t.Errorf("Expected -1 for an unknown size, got %v", percent)
+	}
+}
@@ -3,6 +3,7 @@ package updater
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -11,6 +12,9 @@ import (
 	"github.com/victorkazakov/kportforward/internal/utils"
 )
 
+// lastCheckFileName is the file in the cache directory holding the time of the last check
+const lastCheckFileName = "last_update_check"
+
 // Manager coordinates update checking and application
 type Manager struct {
 	checker *Checker
@@ -25,10 +29,19 @@ type Manager struct {
 
 	// State
 	lastUpdateInfo *UpdateInfo
+	downloadDir    string            // Where PrepareUpdate saves downloaded binaries
+	transport      http.RoundTripper // For checks and downloads; nil uses the default
+
+	// Checks the signature of the checksums file, when configured
+	verifier        signatureVerifier
+	verificationErr error // Invalid verification config; updates are refused until fixed
+
+	// Optional observer invoked after every update check
+	checkHook func(info *UpdateInfo, err error, duration time.Duration)
 }
 
-// NewManager creates a new update manager
-func NewManager(repoOwner, repoName, currentVersion string, logger *utils.Logger) *Manager {
+// NewManager creates a new update manager checking the given release source
+func NewManager(source ReleaseSource, currentVersion string, logger *utils.Logger) *Manager {
 	ctx, cancel := context.WithCancel(context.Background())
 
 	// Get user cache directory for storing last check time
@@ -39,33 +52,33 @@ func NewManager(repoOwner, repoName, currentVersion string, logger *utils.Logger
 	}
 
 	config := &UpdateConfig{
-		RepoOwner:      repoOwner,
-		RepoName:       repoName,
+		Source:         source,
 		CurrentVersion: currentVersion,
 		CheckInterval:  24 * time.Hour, // Daily checks
-		LastCheckFile:  filepath.Join(cacheDir, "kportforward", "last_update_check"),
-		UpdateChannel:  "stable",
+		LastCheckFile:  filepath.Join(cacheDir, "kportforward", lastCheckFileName),
+		UpdateChannel:  ChannelStable,
 	}
 
 	checker := NewChecker(config, logger)
 
 	return &Manager{
-		checker:    checker,
-		config:     config,
-		logger:     logger,
-		ctx:        ctx,
-		cancel:     cancel,
-		updateChan: make(chan *UpdateInfo, 1),
+		checker:     checker,
+		config:      config,
+		logger:      logger,
+		ctx:         ctx,
+		cancel:      cancel,
+		updateChan:  make(chan *UpdateInfo, 1),
+		downloadDir: filepath.Join(cacheDir, "kportforward", "updates"),
 	}
 }
 
 // Start begins the update checking process
 func (m *Manager) Start() error {
-	m.logger.Info("Starting update manager")
+	m.logger.Info("Starting update manager for %s", m.config.Source)
 
 	// Check for updates immediately on startup
 	go func() {
-		updateInfo, err := m.checker.CheckForUpdates()
+		updateInfo, err := m.checkForUpdates()
 		if err != nil {
 			m.logger.Error("Initial update check failed: %v", err)
 			return
@@ -100,6 +113,60 @@ func (m *Manager) Stop() error {
 	return nil
 }
 
+// SetCheckHook registers a function called after every update check (e.g. for tracing)
+func (m *Manager) SetCheckHook(hook func(info *UpdateInfo, err error, duration time.Duration)) {
+	m.checkHook = hook
+}
+
+// checkForUpdates runs an update check and reports it to the check hook
+func (m *Manager) checkForUpdates() (*UpdateInfo, error) {
+	started := time.Now()
+	updateInfo, err := m.checker.CheckForUpdates()
+	if m.checkHook != nil {
+		m.checkHook(updateInfo, err, time.Since(started))
+	}
+	return updateInfo, err
+}
+
+// SetChannel selects the update channel, one of Channels. Each channel remembers its own
+// last check, so switching channels checks right away.
+func (m *Manager) SetChannel(channel string) error {
+	if err := ValidateChannel(channel); err != nil {
+		return err
+	}
+	if channel == "" {
+		channel = ChannelStable
+	}
+	m.config.UpdateChannel = channel
+	if m.config.LastCheckFile != "" {
+		name := lastCheckFileName
+		if channel != ChannelStable {
+			name += "_" + channel
+		}
+		m.config.LastCheckFile = filepath.Join(filepath.Dir(m.config.LastCheckFile), name)
+	}
+	return nil
+}
+
+// SetNetwork configures the proxy and CA bundle used to reach the release source
+func (m *Manager) SetNetwork(cfg NetworkConfig) error {
+	transport, err := newTransport(cfg)
+	if err != nil {
+		return err
+	}
+	m.transport = transport
+	m.checker.client.Transport = transport
+	return nil
+}
+
+// SetVerification configures how downloaded updates are verified. An invalid
+// configuration is returned and also fails PrepareUpdate, rather than skipping the check.
+func (m *Manager) SetVerification(v Verification) error {
+	m.config.Verification = v
+	m.verifier, m.verificationErr = newSignatureVerifier(v)
+	return m.verificationErr
+}
+
 // GetUpdateChannel returns the channel for update notifications
 func (m *Manager) GetUpdateChannel() <-chan *UpdateInfo {
 	return m.updateChan
@@ -138,7 +205,7 @@ func (m *Manager) periodicCheck() {
 			return
 
 		case <-m.checkTicker.C:
-			updateInfo, err := m.checker.CheckForUpdates()
+			updateInfo, err := m.checkForUpdates()
 			if err != nil {
 				m.logger.Error("Periodic update check failed: %v", err)
 				continue
@@ -159,20 +226,91 @@ func (m *Manager) periodicCheck() {
 	}
 }
 
-// PrepareUpdate downloads and prepares the update (but doesn't apply it)
-func (m *Manager) PrepareUpdate(updateInfo *UpdateInfo) error {
+// PrepareUpdate downloads the update's binary next to the update cache (but doesn't
+// apply it), reporting progress, and returns where the binary was saved. An
+// interrupted download is resumed by the next call.
+func (m *Manager) PrepareUpdate(updateInfo *UpdateInfo, progress ProgressFunc) (string, error) {
 	if updateInfo.DownloadURL == "" {
-		return fmt.Errorf("no download URL available")
+		return "", fmt.Errorf("no download URL available")
 	}
 
 	m.logger.Info("Preparing update %s", updateInfo.LatestVersion)
 
-	// TODO: Implement download and verification
-	// For now, just log that we would download
-	m.logger.Info("Would download from: %s", updateInfo.DownloadURL)
-	m.logger.Info("Asset size: %d bytes", updateInfo.AssetSize)
+	if err := os.MkdirAll(m.downloadDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create download directory: %w", err)
+	}
+	name := "kportforward-" + updateInfo.LatestVersion
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+	path := filepath.Join(m.downloadDir, name)
 
-	return nil
+	// No overall timeout: large assets over a slow VPN take a while, and stalls are detected per attempt
+	client := &http.Client{Transport: m.transport}
+
+	// Checked first, so a release that can't be verified isn't downloaded at all
+	checksum, err := m.expectedChecksum(client, updateInfo)
+	if err != nil {
+		return "", err
+	}
+	if err := Download(m.ctx, client, updateInfo.DownloadURL, path, updateInfo.AssetSize, progress); err != nil {
+		return "", err
+	}
+
+	// Never made executable unless it matches the checksum
+	if checksum != "" {
+		if err := verifyChecksum(path, checksum); err != nil {
+			os.Remove(path)
+			return "", fmt.Errorf("update %s failed verification: %w", updateInfo.LatestVersion, err)
+		}
+		m.logger.Info("Verified the SHA-256 of update %s", updateInfo.LatestVersion)
+	}
+	if err := os.Chmod(path, 0755); err != nil {
+		return "", fmt.Errorf("failed to make update executable: %w", err)
+	}
+
+	m.logger.Info("Downloaded update %s to %s", updateInfo.LatestVersion, path)
+	return path, nil
+}
+
+// expectedChecksum fetches the release's checksums file, verifying its signature when
+// configured, and returns the SHA-256 the update's binary must have. It is empty only
+// when the release has no checksums file and unverified updates are allowed.
+func (m *Manager) expectedChecksum(client *http.Client, updateInfo *UpdateInfo) (string, error) {
+	if m.verificationErr != nil {
+		return "", fmt.Errorf("invalid update verification config: %w", m.verificationErr)
+	}
+	if updateInfo.ChecksumsURL == "" {
+		if m.config.Verification.AllowUnverified {
+			m.logger.Warn("Release %s publishes no checksums; installing it unverified", updateInfo.LatestVersion)
+			return "", nil
+		}
+		return "", fmt.Errorf("release %s publishes no checksums file, so the update can't be verified (set allowUnverified under updates to install it anyway)", updateInfo.LatestVersion)
+	}
+
+	checksums, err := fetchFile(m.ctx, client, updateInfo.ChecksumsURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to download checksums: %w", err)
+	}
+	if m.verifier != nil {
+		if updateInfo.SignatureURL == "" {
+			return "", fmt.Errorf("release %s has no %s signature of its checksums", updateInfo.LatestVersion, m.config.Verification.Signature)
+		}
+		signature, err := fetchFile(m.ctx, client, updateInfo.SignatureURL)
+		if err != nil {
+			return "", fmt.Errorf("failed to download checksums signature: %w", err)
+		}
+		if err := m.verifier.verify(checksums, signature); err != nil {
+			return "", fmt.Errorf("checksums of release %s failed verification: %w", updateInfo.LatestVersion, err)
+		}
+		m.logger.Info("Verified the %s signature of the checksums of %s", m.config.Verification.Signature, updateInfo.LatestVersion)
+	}
+
+	checksum, listed := parseChecksums(checksums)[updateInfo.AssetName]
+	if !listed {
+		return "", fmt.Errorf("checksums of release %s don't list %s", updateInfo.LatestVersion, updateInfo.AssetName)
+	}
+	return checksum, nil
 }
 
 // getUserCacheDir returns the appropriate cache directory for the current platform
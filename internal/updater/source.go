@@ -0,0 +1,265 @@
+package updater
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// releaseListSize is how many recent releases are listed when looking beyond the latest
+// stable one, e.g. for the beta and nightly update channels
+const releaseListSize = 30
+
+// ReleaseSource fetches releases from where kportforward is published
+type ReleaseSource interface {
+	LatestRelease(client *http.Client) (*Release, error) // Latest stable release
+	Releases(client *http.Client) ([]Release, error)     // Recent releases, prereleases included, newest first
+	String() string                                      // e.g. "GitHub catio-tech/kportforward"
+}
+
+// SourceConfig selects and configures a release source
+type SourceConfig struct {
+	Provider string // "github" (default), "gitlab" or "gitea"
+	URL      string // Base URL of the instance; defaults to github.com for GitHub
+	Repo     string // "owner/name", or a GitLab project path or ID
+	Token    string // Access token for private instances
+}
+
+// NewSource creates the release source described by the config
+func NewSource(cfg SourceConfig) (ReleaseSource, error) {
+	baseURL := strings.TrimSuffix(cfg.URL, "/")
+
+	switch cfg.Provider {
+	case "", "github":
+		owner, name, err := splitRepo(cfg.Repo)
+		if err != nil {
+			return nil, err
+		}
+		apiURL := "https://api.github.com"
+		if baseURL != "" {
+			apiURL = baseURL + "/api/v3" // GitHub Enterprise Server
+		}
+		return &githubSource{apiURL: apiURL, owner: owner, name: name, token: cfg.Token}, nil
+
+	case "gitlab":
+		if baseURL == "" {
+			baseURL = "https://gitlab.com"
+		}
+		if cfg.Repo == "" {
+			return nil, fmt.Errorf("gitlab release source needs a project path or ID")
+		}
+		return &gitlabSource{baseURL: baseURL, project: cfg.Repo, token: cfg.Token}, nil
+
+	case "gitea":
+		if baseURL == "" {
+			return nil, fmt.Errorf("gitea release source needs the instance URL")
+		}
+		owner, name, err := splitRepo(cfg.Repo)
+		if err != nil {
+			return nil, err
+		}
+		return &giteaSource{baseURL: baseURL, owner: owner, name: name, token: cfg.Token}, nil
+
+	default:
+		return nil, fmt.Errorf("unknown release provider %q (expected github, gitlab or gitea)", cfg.Provider)
+	}
+}
+
+// splitRepo splits an "owner/name" repository reference
+func splitRepo(repo string) (string, string, error) {
+	owner, name, ok := strings.Cut(repo, "/")
+	if !ok || owner == "" || name == "" {
+		return "", "", fmt.Errorf("invalid repository %q, expected owner/name", repo)
+	}
+	return owner, name, nil
+}
+
+// githubSource reads releases from GitHub or GitHub Enterprise Server
+type githubSource struct {
+	apiURL string
+	owner  string
+	name   string
+	token  string
+}
+
+func (s *githubSource) LatestRelease(client *http.Client) (*Release, error) {
+	endpoint := fmt.Sprintf("%s/repos/%s/%s/releases/latest", s.apiURL, s.owner, s.name)
+	var release Release
+	if err := getJSON(client, endpoint, s.header(), "GitHub", &release); err != nil {
+		return nil, err
+	}
+	return &release, nil
+}
+
+func (s *githubSource) Releases(client *http.Client) ([]Release, error) {
+	endpoint := fmt.Sprintf("%s/repos/%s/%s/releases?per_page=%d", s.apiURL, s.owner, s.name, releaseListSize)
+	var releases []Release
+	if err := getJSON(client, endpoint, s.header(), "GitHub", &releases); err != nil {
+		return nil, err
+	}
+	return releases, nil
+}
+
+// header returns the request headers of the GitHub API
+func (s *githubSource) header() http.Header {
+	header := http.Header{"Accept": {"application/vnd.github+json"}}
+	if s.token != "" {
+		header.Set("Authorization", "Bearer "+s.token)
+	}
+	return header
+}
+
+func (s *githubSource) String() string {
+	return fmt.Sprintf("GitHub %s/%s", s.owner, s.name)
+}
+
+// gitlabSource reads releases from gitlab.com or a self-managed GitLab
+type gitlabSource struct {
+	baseURL string
+	project string
+	token   string
+}
+
+// gitlabRelease is a release as returned by the GitLab releases API
+type gitlabRelease struct {
+	TagName         string    `json:"tag_name"`
+	Name            string    `json:"name"`
+	Description     string    `json:"description"`
+	ReleasedAt      time.Time `json:"released_at"`
+	UpcomingRelease bool      `json:"upcoming_release"`
+	Assets          struct {
+		Links []struct {
+			Name           string `json:"name"`
+			URL            string `json:"url"`
+			DirectAssetURL string `json:"direct_asset_url"`
+		} `json:"links"`
+	} `json:"assets"`
+}
+
+func (s *gitlabSource) LatestRelease(client *http.Client) (*Release, error) {
+	releases, err := s.Releases(client)
+	if err != nil {
+		return nil, err
+	}
+	if release := selectRelease(releases, ChannelStable); release != nil {
+		return release, nil
+	}
+	return nil, fmt.Errorf("no published releases in GitLab project %s", s.project)
+}
+
+func (s *gitlabSource) Releases(client *http.Client) ([]Release, error) {
+	// Releases are listed newest first; upcoming releases aren't published yet
+	endpoint := fmt.Sprintf("%s/api/v4/projects/%s/releases?per_page=%d", s.baseURL, url.PathEscape(s.project), releaseListSize)
+	header := http.Header{}
+	if s.token != "" {
+		header.Set("PRIVATE-TOKEN", s.token)
+	}
+
+	var gitlabReleases []gitlabRelease
+	if err := getJSON(client, endpoint, header, "GitLab", &gitlabReleases); err != nil {
+		return nil, err
+	}
+
+	releases := make([]Release, 0, len(gitlabReleases))
+	for _, gr := range gitlabReleases {
+		if gr.UpcomingRelease {
+			continue
+		}
+		// GitLab has no prerelease flag; the tag tells, e.g. v1.3.0-beta.1
+		release := Release{
+			TagName:     gr.TagName,
+			Name:        gr.Name,
+			Body:        gr.Description,
+			Prerelease:  isPrereleaseTag(gr.TagName),
+			PublishedAt: gr.ReleasedAt,
+		}
+		for _, link := range gr.Assets.Links {
+			downloadURL := link.DirectAssetURL
+			if downloadURL == "" {
+				downloadURL = link.URL
+			}
+			// GitLab doesn't report the size of linked assets
+			release.Assets = append(release.Assets, Asset{Name: link.Name, BrowserDownloadURL: downloadURL})
+		}
+		releases = append(releases, release)
+	}
+	return releases, nil
+}
+
+func (s *gitlabSource) String() string {
+	return "GitLab " + s.project
+}
+
+// giteaSource reads releases from Gitea or Forgejo, whose API mirrors GitHub's release shape
+type giteaSource struct {
+	baseURL string
+	owner   string
+	name    string
+	token   string
+}
+
+func (s *giteaSource) LatestRelease(client *http.Client) (*Release, error) {
+	endpoint := fmt.Sprintf("%s/api/v1/repos/%s/%s/releases/latest", s.baseURL, s.owner, s.name)
+	var release Release
+	if err := getJSON(client, endpoint, s.header(), "Gitea", &release); err != nil {
+		return nil, err
+	}
+	return &release, nil
+}
+
+func (s *giteaSource) Releases(client *http.Client) ([]Release, error) {
+	endpoint := fmt.Sprintf("%s/api/v1/repos/%s/%s/releases?limit=%d", s.baseURL, s.owner, s.name, releaseListSize)
+	var releases []Release
+	if err := getJSON(client, endpoint, s.header(), "Gitea", &releases); err != nil {
+		return nil, err
+	}
+	return releases, nil
+}
+
+// header returns the request headers of the Gitea API
+func (s *giteaSource) header() http.Header {
+	header := http.Header{}
+	if s.token != "" {
+		header.Set("Authorization", "token "+s.token)
+	}
+	return header
+}
+
+func (s *giteaSource) String() string {
+	return fmt.Sprintf("Gitea %s/%s", s.owner, s.name)
+}
+
+// getJSON fetches an API endpoint and decodes its JSON response into target
+func getJSON(client *http.Client, endpoint string, header http.Header, provider string, target interface{}) error {
+	req, err := http.NewRequest(http.MethodGet, endpoint, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to fetch release data: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s API returned status %d", provider, resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if err := json.Unmarshal(body, target); err != nil {
+		return fmt.Errorf("failed to parse release data: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package updater
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGitLabSourceLatestRelease(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.EscapedPath() != "/api/v4/projects/tools%2Fkportforward/releases" {
+			t.Errorf("Unexpected path %s", r.URL.EscapedPath())
+		}
+		if token := r.Header.Get("PRIVATE-TOKEN"); token != "secret" {
+			t.Errorf("Expected PRIVATE-TOKEN secret, got %q", token)
+		}
+		w.Write([]byte(`[
+			{"tag_name": "v2.0.0", "upcoming_release": true},
+			{"tag_name": "v1.5.0", "description": "Notes", "released_at": "2026-01-02T03:04:05Z",
+			 "assets": {"links": [
+				{"name": "kportforward-linux-amd64", "url": "https://example.com/a", "direct_asset_url": "https://example.com/direct"},
+				{"name": "kportforward-darwin-arm64", "url": "https://example.com/b"}
+			 ]}}
+		]`))
+	}))
+	defer server.Close()
+
+	source, err := NewSource(SourceConfig{Provider: "gitlab", URL: server.URL + "/", Repo: "tools/kportforward", Token: "secret"})
+	if err != nil {
+		t.Fatalf("NewSource failed: %v", err)
+	}
+	release, err := source.LatestRelease(server.Client())
+	if err != nil {
+		t.Fatalf("LatestRelease failed: %v", err)
+	}
+
+	if release.TagName != "v1.5.0" || release.Body != "Notes" {
+		t.Errorf("Expected v1.5.0 with notes, got %+v", release)
+	}
+	if len(release.Assets) != 2 {
+		t.Fatalf("Expected 2 assets, got %d", len(release.Assets))
+	}
+	if release.Assets[0].BrowserDownloadURL != "https://example.com/direct" {
+		t.Errorf("Expected the direct asset URL, got %s", release.Assets[0].BrowserDownloadURL)
+	}
+	if release.Assets[1].BrowserDownloadURL != "https://example.com/b" {
+		t.Errorf("Expected the link URL without a direct URL, got %s", release.Assets[1].BrowserDownloadURL)
+	}
+}
+
+func TestGiteaSourceLatestRelease(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/repos/tools/kportforward/releases/latest" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		if auth := r.Header.Get("Authorization"); auth != "token secret" {
+			t.Errorf("Expected token authorization, got %q", auth)
+		}
+		w.Write([]byte(`{"tag_name": "v1.5.0", "assets": [{"name": "kportforward-linux-amd64", "size": 42, "browser_download_url": "https://example.com/a"}]}`))
+	}))
+	defer server.Close()
+
+	source, err := NewSource(SourceConfig{Provider: "gitea", URL: server.URL, Repo: "tools/kportforward", Token: "secret"})
+	if err != nil {
+		t.Fatalf("NewSource failed: %v", err)
+	}
+	release, err := source.LatestRelease(server.Client())
+	if err != nil {
+		t.Fatalf("LatestRelease failed: %v", err)
+	}
+
+	if release.TagName != "v1.5.0" || len(release.Assets) != 1 || release.Assets[0].Size != 42 {
+		t.Errorf("Unexpected release %+v", release)
+	}
+}
+
+func TestSourceErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	source, err := NewSource(SourceConfig{URL: server.URL, Repo: "catio-tech/kportforward"})
+	if err != nil {
+		t.Fatalf("NewSource failed: %v", err)
+	}
+	if _, err := source.LatestRelease(server.Client()); err == nil || err.Error() != "GitHub API returned status 401" {
+		t.Errorf("Expected a 401 error, got %v", err)
+	}
+}
+
+func TestNewSourceInvalid(t *testing.T) {
+	tests := []SourceConfig{
+		{Provider: "bitbucket", Repo: "a/b"},
+		{Provider: "github", Repo: "kportforward"},
+		{Provider: "gitlab"},
+		{Provider: "gitea", Repo: "a/b"},
+	}
+
+	for _, cfg := range tests {
+		if _, err := NewSource(cfg); err == nil {
+			t.Errorf("Expected an error for %+v", cfg)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package updater
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTransportTrustsCABundle(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: mustTransport(t, NetworkConfig{})}
+	if _, err := client.Get(server.URL); err == nil {
+		t.Fatal("Expected the test server's certificate to be untrusted without a CA bundle")
+	}
+
+	bundle := filepath.Join(t.TempDir(), "ca.pem")
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(bundle, certPEM, 0644); err != nil {
+		t.Fatalf("Failed to write CA bundle: %v", err)
+	}
+	client = &http.Client{Transport: mustTransport(t, NetworkConfig{CABundle: bundle})}
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Expected the CA bundle to be trusted, got %v", err)
+	}
+	resp.Body.Close()
+}
+
+func TestTransportUsesProxy(t *testing.T) {
+	var proxied string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = r.URL.String()
+		w.Write([]byte("ok"))
+	}))
+	defer proxy.Close()
+
+	client := &http.Client{Transport: mustTransport(t, NetworkConfig{Proxy: proxy.URL})}
+	resp, err := client.Get("http://releases.example.com/latest")
+	if err != nil {
+		t.Fatalf("Request through the proxy failed: %v", err)
+	}
+	resp.Body.Close()
+	if proxied != "http://releases.example.com/latest" {
+		t.Errorf("Expected the proxy to receive the request, got %q", proxied)
+	}
+}
+
+func TestTransportInvalidConfig(t *testing.T) {
+	empty := filepath.Join(t.TempDir(), "empty.pem")
+	os.WriteFile(empty, []byte("not a certificate"), 0644)
+
+	for _, cfg := range []NetworkConfig{
+		{Proxy: "not a url"},
+		{CABundle: filepath.Join(t.TempDir(), "missing.pem")},
+		{CABundle: empty},
+	} {
+		if _, err := newTransport(cfg); err == nil {
+			t.Errorf("Expected an error for %+v", cfg)
+		}
+	}
+}
+
+func mustTransport(t *testing.T, cfg NetworkConfig) *http.Transport {
+	t.Helper()
+	transport, err := newTransport(cfg)
+	if err != nil {
+		t.Fatalf("newTransport failed: %v", err)
+	}
+	return transport
+}
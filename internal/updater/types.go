@@ -4,7 +4,7 @@ import (
 	"time"
 )
 
-// Release represents a GitHub release
+// Release represents a release, in the shape of the GitHub API that other sources are converted to
 type Release struct {
 	TagName     string    `json:"tag_name"`
 	Name        string    `json:"name"`
@@ -30,18 +30,21 @@ type UpdateInfo struct {
 	LatestVersion  string
 	ReleaseNotes   string
 	DownloadURL    string
+	AssetName      string
 	AssetSize      int64
+	ChecksumsURL   string // Checksums file of the release; empty if it publishes none
+	SignatureURL   string // Signature of the checksums file for the configured signature method
 	PublishedAt    time.Time
 }
 
 // UpdateConfig contains configuration for the updater
 type UpdateConfig struct {
-	RepoOwner      string
-	RepoName       string
+	Source         ReleaseSource
 	CurrentVersion string
 	CheckInterval  time.Duration
 	LastCheckFile  string
-	UpdateChannel  string // "stable" or "beta"
+	UpdateChannel  string // One of Channels
+	Verification   Verification
 }
 
 // UpdateStatus represents the current update status
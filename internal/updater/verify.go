@@ -0,0 +1,249 @@
+package updater
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+// maxVerificationFileSize bounds the checksums and signature files read into memory
+const maxVerificationFileSize = 1 << 20
+
+// checksumsAssetNames are the names release tooling gives the checksums file; names
+// ending in one of checksumsAssetSuffixes count too, e.g. kportforward_1.2.0_checksums.txt
+var (
+	checksumsAssetNames    = []string{"checksums.txt", "SHA256SUMS", "sha256sums.txt"}
+	checksumsAssetSuffixes = []string{"_checksums.txt", "-checksums.txt"}
+)
+
+// signatureSuffixes name the signature of the checksums file for each signature method
+var signatureSuffixes = map[string]string{
+	"cosign":   ".sig",
+	"minisign": ".minisig",
+}
+
+// Verification configures how downloaded updates are checked before they are installed.
+// The SHA-256 of the binary must match the release's checksums file; with a signature
+// method the checksums file must also carry a valid signature by PublicKey.
+type Verification struct {
+	Signature       string // "", "cosign" or "minisign"
+	PublicKey       string // The public key, or a file holding it
+	AllowUnverified bool   // Install releases that publish no checksums file
+}
+
+// signatureVerifier checks a detached signature of a file
+type signatureVerifier interface {
+	verify(data, signature []byte) error
+}
+
+// newSignatureVerifier parses the public key of a signature method; nil when there is none
+func newSignatureVerifier(v Verification) (signatureVerifier, error) {
+	if v.Signature == "" {
+		return nil, nil
+	}
+	if _, known := signatureSuffixes[v.Signature]; !known {
+		return nil, fmt.Errorf("unknown signature method %q (expected cosign or minisign)", v.Signature)
+	}
+	if v.PublicKey == "" {
+		return nil, fmt.Errorf("%s signature verification needs a public key", v.Signature)
+	}
+
+	key := []byte(v.PublicKey)
+	if data, err := os.ReadFile(v.PublicKey); err == nil {
+		key = data
+	}
+	if v.Signature == "cosign" {
+		return newCosignVerifier(key)
+	}
+	return newMinisignVerifier(key)
+}
+
+// findChecksumsAsset returns the checksums file of a release, or nil if it has none
+func findChecksumsAsset(assets []Asset) *Asset {
+	for i, asset := range assets {
+		for _, name := range checksumsAssetNames {
+			if asset.Name == name {
+				return &assets[i]
+			}
+		}
+		for _, suffix := range checksumsAssetSuffixes {
+			if strings.HasSuffix(asset.Name, suffix) {
+				return &assets[i]
+			}
+		}
+	}
+	return nil
+}
+
+// findAsset returns the asset with the given name, or nil
+func findAsset(assets []Asset, name string) *Asset {
+	for i, asset := range assets {
+		if asset.Name == name {
+			return &assets[i]
+		}
+	}
+	return nil
+}
+
+// parseChecksums reads a sha256sum style file of "<hex digest>  <file name>" lines
+func parseChecksums(data []byte) map[string]string {
+	checksums := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		name := strings.TrimPrefix(fields[1], "*") // Binary mode marker
+		checksums[name] = strings.ToLower(fields[0])
+	}
+	return checksums
+}
+
+// verifyChecksum checks that the SHA-256 of the file at path is the expected hex digest
+func verifyChecksum(path, expected string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open download: %w", err)
+	}
+	defer f.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, f); err != nil {
+		return fmt.Errorf("failed to read download: %w", err)
+	}
+	if actual := hex.EncodeToString(hash.Sum(nil)); actual != expected {
+		return fmt.Errorf("checksum mismatch: expected SHA-256 %s, got %s", expected, actual)
+	}
+	return nil
+}
+
+// fetchFile downloads a small release file, such as the checksums or their signature
+func fetchFile(ctx context.Context, client *http.Client, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, &statusError{code: resp.StatusCode}
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxVerificationFileSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxVerificationFileSize {
+		return nil, fmt.Errorf("file is larger than %s", formatBytes(maxVerificationFileSize))
+	}
+	return data, nil
+}
+
+// cosignVerifier checks signatures made with 'cosign sign-blob --key': a base64 ECDSA
+// signature over the SHA-256 of the file
+type cosignVerifier struct {
+	key *ecdsa.PublicKey
+}
+
+func newCosignVerifier(key []byte) (*cosignVerifier, error) {
+	block, _ := pem.Decode(key)
+	if block == nil {
+		return nil, fmt.Errorf("invalid cosign public key: no PEM block")
+	}
+	parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cosign public key: %w", err)
+	}
+	ecdsaKey, ok := parsed.(*ecdsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("unsupported cosign public key type %T (expected ECDSA)", parsed)
+	}
+	return &cosignVerifier{key: ecdsaKey}, nil
+}
+
+func (v *cosignVerifier) verify(data, signature []byte) error {
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(signature)))
+	if err != nil {
+		return fmt.Errorf("invalid cosign signature: %w", err)
+	}
+	digest := sha256.Sum256(data)
+	if !ecdsa.VerifyASN1(v.key, digest[:], sig) {
+		return fmt.Errorf("cosign signature doesn't match the public key")
+	}
+	return nil
+}
+
+// minisignVerifier checks minisign signatures, both legacy ("Ed") and prehashed ("ED")
+type minisignVerifier struct {
+	keyID [8]byte
+	key   ed25519.PublicKey
+}
+
+func newMinisignVerifier(key []byte) (*minisignVerifier, error) {
+	decoded, err := base64.StdEncoding.DecodeString(lastLine(key))
+	if err != nil || len(decoded) != 2+8+ed25519.PublicKeySize || string(decoded[:2]) != "Ed" {
+		return nil, fmt.Errorf("invalid minisign public key")
+	}
+	v := &minisignVerifier{key: ed25519.PublicKey(decoded[10:])}
+	copy(v.keyID[:], decoded[2:10])
+	return v, nil
+}
+
+func (v *minisignVerifier) verify(data, signature []byte) error {
+	// untrusted comment, signature, trusted comment, global signature over signature and trusted comment
+	lines := strings.Split(strings.TrimSpace(string(signature)), "\n")
+	if len(lines) < 4 || !strings.HasPrefix(lines[2], "trusted comment: ") {
+		return fmt.Errorf("invalid minisign signature")
+	}
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(lines[1]))
+	if err != nil || len(sig) != 2+8+ed25519.SignatureSize {
+		return fmt.Errorf("invalid minisign signature")
+	}
+	if !bytes.Equal(sig[2:10], v.keyID[:]) {
+		return fmt.Errorf("minisign signature was made with another key (key ID %X)", sig[2:10])
+	}
+
+	message := data
+	switch string(sig[:2]) {
+	case "Ed":
+	case "ED":
+		digest := blake2b.Sum512(data)
+		message = digest[:]
+	default:
+		return fmt.Errorf("unsupported minisign signature algorithm %q", sig[:2])
+	}
+	if !ed25519.Verify(v.key, message, sig[10:]) {
+		return fmt.Errorf("minisign signature doesn't match the public key")
+	}
+
+	trusted := strings.TrimSuffix(strings.TrimPrefix(lines[2], "trusted comment: "), "\r")
+	global, err := base64.StdEncoding.DecodeString(strings.TrimSpace(lines[3]))
+	if err != nil || !ed25519.Verify(v.key, append(append([]byte{}, sig[10:]...), trusted...), global) {
+		return fmt.Errorf("minisign trusted comment signature doesn't match the public key")
+	}
+	return nil
+}
+
+// lastLine returns the last non-empty line, skipping the comment of a minisign key file
+func lastLine(data []byte) string {
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
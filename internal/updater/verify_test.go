@@ -0,0 +1,188 @@
+package updater
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/blake2b"
+
+	"github.com/victorkazakov/kportforward/internal/utils"
+)
+
+func TestParseChecksums(t *testing.T) {
+	checksums := parseChecksums([]byte("ABC123  kportforward-linux-amd64\ndef456 *kportforward-windows-amd64.exe\n\nnot a checksum line at all\n"))
+	if checksums["kportforward-linux-amd64"] != "abc123" {
+		t.Errorf("Expected the lowercased digest, got %q", checksums["kportforward-linux-amd64"])
+	}
+	if checksums["kportforward-windows-amd64.exe"] != "def456" {
+		t.Errorf("Expected the binary mode marker to be dropped, got %v", checksums)
+	}
+	if len(checksums) != 2 {
+		t.Errorf("Expected 2 checksums, got %v", checksums)
+	}
+}
+
+func TestFindChecksumsAsset(t *testing.T) {
+	assets := []Asset{{Name: "kportforward-linux-amd64"}, {Name: "kportforward_1.2.0_checksums.txt"}}
+	if asset := findChecksumsAsset(assets); asset == nil || asset.Name != "kportforward_1.2.0_checksums.txt" {
+		t.Errorf("Expected the checksums asset, got %v", asset)
+	}
+	if asset := findChecksumsAsset(assets[:1]); asset != nil {
+		t.Errorf("Expected no checksums asset, got %v", asset)
+	}
+}
+
+func TestCosignVerifier(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatalf("Failed to encode key: %v", err)
+	}
+	publicKey := string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+
+	data := []byte("abc123  kportforward-linux-amd64\n")
+	digest := sha256.Sum256(data)
+	sig, err := ecdsa.SignASN1(rand.Reader, key, digest[:])
+	if err != nil {
+		t.Fatalf("Failed to sign: %v", err)
+	}
+	signature := []byte(base64.StdEncoding.EncodeToString(sig))
+
+	verifier, err := newSignatureVerifier(Verification{Signature: "cosign", PublicKey: publicKey})
+	if err != nil {
+		t.Fatalf("Failed to parse key: %v", err)
+	}
+	if err := verifier.verify(data, signature); err != nil {
+		t.Errorf("Expected a valid signature, got %v", err)
+	}
+	if err := verifier.verify([]byte("tampered"), signature); err == nil {
+		t.Error("Expected tampered checksums to fail")
+	}
+}
+
+func TestMinisignVerifier(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	keyID := []byte("12345678")
+	keyFile := filepath.Join(t.TempDir(), "minisign.pub")
+	encodedKey := base64.StdEncoding.EncodeToString(append(append([]byte("Ed"), keyID...), publicKey...))
+	if err := os.WriteFile(keyFile, []byte("untrusted comment: minisign public key\n"+encodedKey+"\n"), 0644); err != nil {
+		t.Fatalf("Failed to write key: %v", err)
+	}
+
+	verifier, err := newSignatureVerifier(Verification{Signature: "minisign", PublicKey: keyFile})
+	if err != nil {
+		t.Fatalf("Failed to parse key: %v", err)
+	}
+
+	data := []byte("abc123  kportforward-linux-amd64\n")
+	for _, algorithm := range []string{"Ed", "ED"} {
+		message := data
+		if algorithm == "ED" {
+			digest := blake2b.Sum512(data)
+			message = digest[:]
+		}
+		sig := ed25519.Sign(privateKey, message)
+		trusted := "timestamp:1700000000\tfile:checksums.txt"
+		global := ed25519.Sign(privateKey, append(append([]byte{}, sig...), trusted...))
+		signature := []byte(fmt.Sprintf("untrusted comment: signature\n%s\ntrusted comment: %s\n%s\n",
+			base64.StdEncoding.EncodeToString(append(append([]byte(algorithm), keyID...), sig...)),
+			trusted, base64.StdEncoding.EncodeToString(global)))
+
+		if err := verifier.verify(data, signature); err != nil {
+			t.Errorf("Expected a valid %s signature, got %v", algorithm, err)
+		}
+		if err := verifier.verify([]byte("tampered"), signature); err == nil {
+			t.Errorf("Expected tampered checksums to fail with %s", algorithm)
+		}
+		tamperedComment := strings.Replace(string(signature), "timestamp", "timestamq", 1)
+		if err := verifier.verify(data, []byte(tamperedComment)); err == nil {
+			t.Errorf("Expected a tampered trusted comment to fail with %s", algorithm)
+		}
+	}
+}
+
+func TestPrepareUpdateVerifiesChecksum(t *testing.T) {
+	binary := []byte("#!/bin/sh\necho kportforward\n")
+	digest := sha256.Sum256(binary)
+	checksums := hex.EncodeToString(digest[:]) + "  kportforward-linux-amd64\n"
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/binary":
+			w.Write(binary)
+		case "/tampered":
+			w.Write(append(binary, "rm -rf ~\n"...))
+		case "/checksums.txt":
+			w.Write([]byte(checksums))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	newManager := func() *Manager {
+		m := NewManager(nil, "v1.0.0", utils.NewLogger(utils.LevelError))
+		m.downloadDir = t.TempDir()
+		return m
+	}
+	info := func(binaryPath string, withChecksums bool) *UpdateInfo {
+		updateInfo := &UpdateInfo{LatestVersion: "v1.1.0", AssetName: "kportforward-linux-amd64", DownloadURL: server.URL + binaryPath}
+		if withChecksums {
+			updateInfo.ChecksumsURL = server.URL + "/checksums.txt"
+		}
+		return updateInfo
+	}
+
+	path, err := newManager().PrepareUpdate(info("/binary", true), nil)
+	if err != nil {
+		t.Fatalf("Expected the verified update to be prepared, got %v", err)
+	}
+	if stat, err := os.Stat(path); err != nil || stat.Mode().Perm()&0100 == 0 {
+		t.Errorf("Expected an executable update at %s, got %v", path, err)
+	}
+
+	m := newManager()
+	if _, err := m.PrepareUpdate(info("/tampered", true), nil); err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Errorf("Expected a checksum mismatch, got %v", err)
+	}
+	if entries, _ := os.ReadDir(m.downloadDir); len(entries) != 0 {
+		t.Errorf("Expected the tampered download to be removed, found %d files", len(entries))
+	}
+
+	if _, err := newManager().PrepareUpdate(info("/binary", false), nil); err == nil {
+		t.Error("Expected a release without checksums to be refused")
+	}
+	m = newManager()
+	m.SetVerification(Verification{AllowUnverified: true})
+	if _, err := m.PrepareUpdate(info("/binary", false), nil); err != nil {
+		t.Errorf("Expected allowUnverified to accept a release without checksums, got %v", err)
+	}
+
+	m = newManager()
+	if err := m.SetVerification(Verification{Signature: "minisign", PublicKey: "not a key"}); err == nil {
+		t.Error("Expected an invalid public key to be rejected")
+	}
+	if _, err := m.PrepareUpdate(info("/binary", true), nil); err == nil {
+		t.Error("Expected updates to be refused with an invalid verification config")
+	}
+}
@@ -0,0 +1,71 @@
+package utils
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// CloudSQLProxy describes a Cloud SQL Auth Proxy listening on a local port
+type CloudSQLProxy struct {
+	Instance                  string // Instance connection name (project:region:instance)
+	Binary                    string // Proxy executable (default: cloud-sql-proxy)
+	PrivateIP                 bool
+	AutoIAMAuthn              bool
+	CredentialsFile           string
+	ImpersonateServiceAccount string
+
+	BindAddress string // Local address to listen on (default: 127.0.0.1)
+	LocalPort   int
+
+	Env []string // Process environment as KEY=value pairs (default: inherited)
+}
+
+// Args returns the cloud-sql-proxy command line arguments
+func (p CloudSQLProxy) Args() ([]string, error) {
+	if strings.Count(p.Instance, ":") != 2 {
+		return nil, fmt.Errorf("invalid Cloud SQL instance connection name %q (expected project:region:instance)", p.Instance)
+	}
+
+	args := []string{"--port", strconv.Itoa(p.LocalPort)}
+	switch addresses := SplitAddresses(p.BindAddress); len(addresses) {
+	case 0:
+	case 1:
+		args = append(args, "--address", addresses[0])
+	default:
+		return nil, fmt.Errorf("cloud-sql-proxy listens on a single address, got bindAddress %q", p.BindAddress)
+	}
+	if p.PrivateIP {
+		args = append(args, "--private-ip")
+	}
+	if p.AutoIAMAuthn {
+		args = append(args, "--auto-iam-authn")
+	}
+	if p.CredentialsFile != "" {
+		args = append(args, "--credentials-file", p.CredentialsFile)
+	}
+	if p.ImpersonateServiceAccount != "" {
+		args = append(args, "--impersonate-service-account", p.ImpersonateServiceAccount)
+	}
+	return append(args, p.Instance), nil
+}
+
+// StartCloudSQLProxy starts a cloud-sql-proxy process for a single instance
+func StartCloudSQLProxy(proxy CloudSQLProxy) (*exec.Cmd, error) {
+	args, err := proxy.Args()
+	if err != nil {
+		return nil, err
+	}
+
+	binary := proxy.Binary
+	if binary == "" {
+		binary = "cloud-sql-proxy"
+	}
+
+	cmd, err := startForwardProcess(binary, args, proxy.Env)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start cloud-sql-proxy: %w", err)
+	}
+	return cmd, nil
+}
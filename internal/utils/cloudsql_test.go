@@ -0,0 +1,34 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCloudSQLProxyArgs(t *testing.T) {
+	proxy := CloudSQLProxy{
+		Instance:        "my-project:europe-west1:orders",
+		PrivateIP:       true,
+		AutoIAMAuthn:    true,
+		CredentialsFile: "/secrets/sa.json",
+		BindAddress:     "0.0.0.0",
+		LocalPort:       15432,
+	}
+
+	args, err := proxy.Args()
+	if err != nil {
+		t.Fatalf("Args failed: %v", err)
+	}
+	expected := "--port 15432 --address 0.0.0.0 --private-ip --auto-iam-authn --credentials-file /secrets/sa.json my-project:europe-west1:orders"
+	if got := strings.Join(args, " "); got != expected {
+		t.Errorf("Args() = %q, expected %q", got, expected)
+	}
+
+	if _, err := (CloudSQLProxy{Instance: "orders", LocalPort: 5432}).Args(); err == nil {
+		t.Error("Expected error for an instance name without project and region")
+	}
+	proxy.BindAddress = "127.0.0.1,::1"
+	if _, err := proxy.Args(); err == nil {
+		t.Error("Expected error for several bind addresses")
+	}
+}
@@ -126,3 +126,22 @@ func FormatUptime(duration time.Duration) string {
 		return fmt.Sprintf("%dd%dh", days, hours)
 	}
 }
+
+// FormatLatency formats a round-trip time compactly, or "-" when not measured
+func FormatLatency(duration time.Duration) string {
+	switch {
+	case duration <= 0:
+		return "-"
+	case duration < 10*time.Millisecond:
+		return fmt.Sprintf("%.1fms", float64(duration)/float64(time.Millisecond))
+	case duration < time.Second:
+		return fmt.Sprintf("%dms", duration.Milliseconds())
+	default:
+		return fmt.Sprintf("%.1fs", duration.Seconds())
+	}
+}
+
+// Milliseconds converts a duration to milliseconds with 0.1ms precision for JSON output
+func Milliseconds(duration time.Duration) float64 {
+	return float64(duration.Round(100*time.Microsecond)) / float64(time.Millisecond)
+}
@@ -239,3 +239,21 @@ func TestLoggerClose(t *testing.T) {
 		t.Errorf("Unexpected error closing already closed logger: %v", err)
 	}
 }
+
+func TestFormatLatency(t *testing.T) {
+	tests := []struct {
+		duration time.Duration
+		expected string
+	}{
+		{0, "-"},
+		{1500 * time.Microsecond, "1.5ms"},
+		{42 * time.Millisecond, "42ms"},
+		{2300 * time.Millisecond, "2.3s"},
+	}
+
+	for _, tt := range tests {
+		if result := FormatLatency(tt.duration); result != tt.expected {
+			t.Errorf("FormatLatency(%v) = %s, expected %s", tt.duration, result, tt.expected)
+		}
+	}
+}
@@ -3,6 +3,8 @@ package utils
 import (
 	"fmt"
 	"net"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -17,6 +19,16 @@ func IsPortAvailable(port int) bool {
 	return true
 }
 
+// IsUDPPortAvailable checks if a UDP port is available for binding
+func IsUDPPortAvailable(port int) bool {
+	conn, err := net.ListenPacket("udp", fmt.Sprintf(":%d", port))
+	if err != nil {
+		return false
+	}
+	defer conn.Close()
+	return true
+}
+
 // FindAvailablePort finds the next available port starting from the given port
 func FindAvailablePort(startPort int) (int, error) {
 	for port := startPort; port <= 65535; port++ {
@@ -27,9 +39,66 @@ func FindAvailablePort(startPort int) (int, error) {
 	return 0, fmt.Errorf("no available ports found starting from %d", startPort)
 }
 
+// FindAvailableUDPPort finds the next available UDP port starting from the given port
+func FindAvailableUDPPort(startPort int) (int, error) {
+	for port := startPort; port <= 65535; port++ {
+		if IsUDPPortAvailable(port) {
+			return port, nil
+		}
+	}
+	return 0, fmt.Errorf("no available UDP ports found starting from %d", startPort)
+}
+
+// FindFreePort asks the OS for a port unused on every interface, as IsPortAvailable checks
+func FindFreePort() (int, error) {
+	listener, err := net.Listen("tcp", ":0")
+	if err != nil {
+		return 0, fmt.Errorf("failed to find a free port: %w", err)
+	}
+	defer listener.Close()
+	return listener.Addr().(*net.TCPAddr).Port, nil
+}
+
+// FindFreeUDPPort asks the OS for a UDP port unused on every interface
+func FindFreeUDPPort() (int, error) {
+	conn, err := net.ListenPacket("udp", ":0")
+	if err != nil {
+		return 0, fmt.Errorf("failed to find a free UDP port: %w", err)
+	}
+	defer conn.Close()
+	return conn.LocalAddr().(*net.UDPAddr).Port, nil
+}
+
+// FindFreeLoopbackPort asks the OS for an unused port on 127.0.0.1
+func FindFreeLoopbackPort() (int, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, fmt.Errorf("failed to find a free port: %w", err)
+	}
+	defer listener.Close()
+	return listener.Addr().(*net.TCPAddr).Port, nil
+}
+
+// SplitAddresses splits a comma-separated list of bind addresses, as kubectl's --address
+// takes them, dropping blanks
+func SplitAddresses(list string) []string {
+	var addresses []string
+	for _, address := range strings.Split(list, ",") {
+		if address = strings.TrimSpace(address); address != "" {
+			addresses = append(addresses, address)
+		}
+	}
+	return addresses
+}
+
 // CheckPortConnectivity tests if a service is responding on the given port
 func CheckPortConnectivity(port int) bool {
-	address := fmt.Sprintf("localhost:%d", port)
+	return CheckHostConnectivity("localhost", port)
+}
+
+// CheckHostConnectivity tests if a service is responding on the given host and port
+func CheckHostConnectivity(host string, port int) bool {
+	address := net.JoinHostPort(host, strconv.Itoa(port))
 	conn, err := net.DialTimeout("tcp", address, 1*time.Second)
 	if err != nil {
 		return false
@@ -1,12 +1,16 @@
 package utils
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"runtime"
 	"strconv"
+	"strings"
 	"syscall"
+	"time"
 )
 
 // ProcessInfo represents information about a running process
@@ -83,6 +87,58 @@ func KillProcess(pid int) error {
 	return nil
 }
 
+// KubectlPortForward describes a kubectl port-forward process
+type KubectlPortForward struct {
+	Kubectl     string // kubectl binary or wrapper (default: kubectl)
+	Namespace   string
+	Target      string
+	LocalPort   int
+	TargetPort  int
+	BindAddress string // Comma-separated local addresses to listen on (default: localhost)
+	Kubeconfig  string // Kubeconfig file (default: KUBECONFIG)
+	Context     string
+	Env         []string   // Process environment as KEY=value pairs (default: inherited)
+	Output      io.Writer  // Receives kubectl's stdout and stderr (default: discarded)
+	Ports       []PortPair // Further ports forwarded by the same process
+}
+
+// PortPair maps a local port to a port of the target
+type PortPair struct {
+	Local  int
+	Target int
+}
+
+// Args returns the kubectl command line arguments for the forward
+func (f KubectlPortForward) Args() []string {
+	args := []string{
+		"port-forward",
+		"-n", f.Namespace,
+		f.Target,
+		fmt.Sprintf("%d:%d", f.LocalPort, f.TargetPort),
+	}
+	for _, port := range f.Ports {
+		args = append(args, fmt.Sprintf("%d:%d", port.Local, port.Target))
+	}
+	if addresses := SplitAddresses(f.BindAddress); len(addresses) > 0 {
+		args = append(args, "--address", strings.Join(addresses, ","))
+	}
+	if f.Kubeconfig != "" {
+		args = append(args, "--kubeconfig", f.Kubeconfig)
+	}
+	if f.Context != "" {
+		args = append(args, "--context", f.Context)
+	}
+	return args
+}
+
+// binary returns the kubectl executable to run
+func (f KubectlPortForward) binary() string {
+	if f.Kubectl == "" {
+		return "kubectl"
+	}
+	return f.Kubectl
+}
+
 // StartKubectlPortForward is implemented in platform-specific files
 
 // GetProcessInfo retrieves information about a running process
@@ -100,3 +156,17 @@ func GetProcessInfo(pid int) (*ProcessInfo, error) {
 		Args:    []string{"port-forward"},
 	}, nil
 }
+
+// ShellCommand prepares a user-supplied command line to run through the platform's
+// shell (sh -c, or cmd /C on Windows)
+func ShellCommand(ctx context.Context, command string) *exec.Cmd {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	// Children the shell started may hold its output open after it is killed
+	cmd.WaitDelay = time.Second
+	return cmd
+}
@@ -0,0 +1,41 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestKubectlPortForwardArgs(t *testing.T) {
+	forward := KubectlPortForward{
+		Kubectl:     "kubectl-oidc",
+		Namespace:   "payments",
+		Target:      "service/api",
+		LocalPort:   9080,
+		TargetPort:  8080,
+		BindAddress: "0.0.0.0",
+		Kubeconfig:  "/etc/kube/prod.yaml",
+		Context:     "prod",
+	}
+
+	args := strings.Join(forward.Args(), " ")
+	expected := "port-forward -n payments service/api 9080:8080 --address 0.0.0.0 --kubeconfig /etc/kube/prod.yaml --context prod"
+	if args != expected {
+		t.Errorf("Expected args %q, got %q", expected, args)
+	}
+	if forward.binary() != "kubectl-oidc" {
+		t.Errorf("Expected the configured kubectl wrapper, got %q", forward.binary())
+	}
+	if (KubectlPortForward{}).binary() != "kubectl" {
+		t.Errorf("Expected kubectl by default")
+	}
+
+	forward.Ports = []PortPair{{Local: 9090, Target: 9090}}
+	if args := strings.Join(forward.Args(), " "); !strings.Contains(args, "service/api 9080:8080 9090:9090 ") {
+		t.Errorf("Expected every port mapping, got %q", args)
+	}
+
+	forward.BindAddress = "127.0.0.1, ::1,"
+	if args := strings.Join(forward.Args(), " "); !strings.Contains(args, "--address 127.0.0.1,::1 ") {
+		t.Errorf("Expected the bind addresses as one list, got %q", args)
+	}
+}
@@ -9,15 +9,11 @@ import (
 )
 
 // StartKubectlPortForward starts a kubectl port-forward process with Unix-specific settings
-func StartKubectlPortForward(namespace, target string, localPort, targetPort int) (*exec.Cmd, error) {
-	args := []string{
-		"port-forward",
-		"-n", namespace,
-		target,
-		fmt.Sprintf("%d:%d", localPort, targetPort),
-	}
-
-	cmd := exec.Command("kubectl", args...)
+func StartKubectlPortForward(forward KubectlPortForward) (*exec.Cmd, error) {
+	cmd := exec.Command(forward.binary(), forward.Args()...)
+	cmd.Env = forward.Env
+	cmd.Stdout = forward.Output
+	cmd.Stderr = forward.Output
 
 	// Set up process group for proper cleanup on Unix systems
 	cmd.SysProcAttr = &syscall.SysProcAttr{
@@ -31,3 +27,24 @@ func StartKubectlPortForward(namespace, target string, localPort, targetPort int
 
 	return cmd, nil
 }
+
+// startForwardProcess starts a long-running forwarding process such as ssh; a nil env
+// inherits the current environment
+func startForwardProcess(name string, args, env []string) (*exec.Cmd, error) {
+	cmd := ForwardCommand(name, args, env)
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	return cmd, nil
+}
+
+// ForwardCommand prepares a long-running forwarding process, in its own process group
+// so it can be cleaned up with its children; a nil env inherits the current environment
+func ForwardCommand(name string, args, env []string) *exec.Cmd {
+	cmd := exec.Command(name, args...)
+	cmd.Env = env
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		Setpgid: true,
+	}
+	return cmd
+}
@@ -8,15 +8,11 @@ import (
 )
 
 // StartKubectlPortForward starts a kubectl port-forward process with Windows-specific settings
-func StartKubectlPortForward(namespace, target string, localPort, targetPort int) (*exec.Cmd, error) {
-	args := []string{
-		"port-forward",
-		"-n", namespace,
-		target,
-		fmt.Sprintf("%d:%d", localPort, targetPort),
-	}
-
-	cmd := exec.Command("kubectl", args...)
+func StartKubectlPortForward(forward KubectlPortForward) (*exec.Cmd, error) {
+	cmd := exec.Command(forward.binary(), forward.Args()...)
+	cmd.Env = forward.Env
+	cmd.Stdout = forward.Output
+	cmd.Stderr = forward.Output
 
 	// No special process group setup needed on Windows
 
@@ -27,3 +23,21 @@ func StartKubectlPortForward(namespace, target string, localPort, targetPort int
 
 	return cmd, nil
 }
+
+// startForwardProcess starts a long-running forwarding process such as ssh; a nil env
+// inherits the current environment
+func startForwardProcess(name string, args, env []string) (*exec.Cmd, error) {
+	cmd := ForwardCommand(name, args, env)
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	return cmd, nil
+}
+
+// ForwardCommand prepares a long-running forwarding process; a nil env inherits the
+// current environment
+func ForwardCommand(name string, args, env []string) *exec.Cmd {
+	cmd := exec.Command(name, args...)
+	cmd.Env = env
+	return cmd
+}
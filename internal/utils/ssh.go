@@ -0,0 +1,86 @@
+package utils
+
+import (
+	"fmt"
+	"net"
+	"os/exec"
+	"strconv"
+)
+
+// SSHTunnel describes a local port forward through an SSH bastion
+type SSHTunnel struct {
+	Host         string   // Bastion host
+	Port         int      // Bastion SSH port (default: 22)
+	User         string   // Login user (default: from ~/.ssh/config or the local user)
+	IdentityFile string   // Private key (default: ssh-agent and ~/.ssh/config)
+	JumpHosts    []string // Additional hops before the bastion (ssh -J)
+	Options      []string // Extra ssh -o options, e.g. StrictHostKeyChecking=accept-new
+
+	BindAddress string // Comma-separated local addresses to listen on (default: localhost)
+	LocalPort   int
+	RemoteHost  string // Host to connect to as seen from the bastion
+	RemotePort  int
+
+	Env []string // Process environment as KEY=value pairs (default: inherited)
+}
+
+// Args returns the ssh command line arguments for the tunnel
+func (t SSHTunnel) Args() []string {
+	args := []string{
+		"-N", // Forward only, no remote command
+		"-o", "ExitOnForwardFailure=yes",
+		// Never prompt: a password or passphrase prompt would hang behind the TUI
+		"-o", "BatchMode=yes",
+		"-o", "ServerAliveInterval=15",
+		"-o", "ServerAliveCountMax=3",
+	}
+	for _, forward := range localForwards(t.BindAddress, t.LocalPort, t.RemoteHost, t.RemotePort) {
+		args = append(args, "-L", forward)
+	}
+	for _, option := range t.Options {
+		args = append(args, "-o", option)
+	}
+	if t.Port != 0 {
+		args = append(args, "-p", strconv.Itoa(t.Port))
+	}
+	if t.IdentityFile != "" {
+		args = append(args, "-i", t.IdentityFile)
+	}
+	for _, jump := range t.JumpHosts {
+		args = append(args, "-J", jump)
+	}
+
+	destination := t.Host
+	if t.User != "" {
+		destination = t.User + "@" + t.Host
+	}
+	return append(args, destination)
+}
+
+// StartSSHTunnel starts an ssh process holding the local forward open
+func StartSSHTunnel(tunnel SSHTunnel) (*exec.Cmd, error) {
+	if tunnel.Host == "" {
+		return nil, fmt.Errorf("ssh tunnel requires a bastion host")
+	}
+
+	cmd, err := startForwardProcess("ssh", tunnel.Args(), tunnel.Env)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start ssh tunnel: %w", err)
+	}
+	return cmd, nil
+}
+
+// localForwards returns an ssh -L forward spec for each bind address, or a single one
+// on localhost when there are none
+func localForwards(bindAddress string, localPort int, remoteHost string, remotePort int) []string {
+	remote := net.JoinHostPort(remoteHost, strconv.Itoa(remotePort))
+	addresses := SplitAddresses(bindAddress)
+	if len(addresses) == 0 {
+		return []string{fmt.Sprintf("%d:%s", localPort, remote)}
+	}
+	forwards := make([]string, 0, len(addresses))
+	for _, address := range addresses {
+		forwards = append(forwards, net.JoinHostPort(address, strconv.Itoa(localPort))+":"+remote)
+	}
+	return forwards
+}
@@ -0,0 +1,61 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSSHTunnelArgs(t *testing.T) {
+	tunnel := SSHTunnel{
+		Host:         "bastion.example.com",
+		Port:         2222,
+		User:         "deploy",
+		IdentityFile: "/home/me/.ssh/bastion",
+		JumpHosts:    []string{"edge.example.com"},
+		Options:      []string{"StrictHostKeyChecking=accept-new"},
+		LocalPort:    5432,
+		RemoteHost:   "db.internal",
+		RemotePort:   5432,
+	}
+
+	args := strings.Join(tunnel.Args(), " ")
+	for _, expected := range []string{
+		"-N",
+		"-o ExitOnForwardFailure=yes",
+		"-o BatchMode=yes",
+		"-L 5432:db.internal:5432",
+		"-o StrictHostKeyChecking=accept-new",
+		"-p 2222",
+		"-i /home/me/.ssh/bastion",
+		"-J edge.example.com",
+	} {
+		if !strings.Contains(args, expected) {
+			t.Errorf("Expected %q in ssh args: %s", expected, args)
+		}
+	}
+	if !strings.HasSuffix(args, "deploy@bastion.example.com") {
+		t.Errorf("Expected destination last: %s", args)
+	}
+
+	tunnel.BindAddress = "0.0.0.0"
+	tunnel.User = ""
+	args = strings.Join(tunnel.Args(), " ")
+	if !strings.Contains(args, "-L 0.0.0.0:5432:db.internal:5432") {
+		t.Errorf("Expected bind address in forward spec: %s", args)
+	}
+	if !strings.HasSuffix(args, " bastion.example.com") {
+		t.Errorf("Expected bare host without user: %s", args)
+	}
+
+	tunnel.BindAddress = "127.0.0.1, ::1"
+	args = strings.Join(tunnel.Args(), " ")
+	if !strings.Contains(args, "-L 127.0.0.1:5432:db.internal:5432 -L [::1]:5432:db.internal:5432") {
+		t.Errorf("Expected a forward spec per bind address: %s", args)
+	}
+}
+
+func TestStartSSHTunnelRequiresHost(t *testing.T) {
+	if _, err := StartSSHTunnel(SSHTunnel{LocalPort: 1, RemoteHost: "db", RemotePort: 1}); err == nil {
+		t.Error("Expected error without a bastion host")
+	}
+}
@@ -0,0 +1,54 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"time"
+)
+
+// dialRetryInterval is how often DialWithRetry tries again while a forward is (re)starting
+const dialRetryInterval = 250 * time.Millisecond
+
+// DialWithRetry connects to a TCP address, retrying until it accepts a connection or
+// ctx is done, so a caller can wait out a forward that is starting or restarting
+func DialWithRetry(ctx context.Context, address string) (net.Conn, error) {
+	var dialer net.Dialer
+	for {
+		attemptCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
+		conn, err := dialer.DialContext(attemptCtx, "tcp", address)
+		cancel()
+		if err == nil {
+			return conn, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("failed to connect to %s: %w", address, err)
+		case <-time.After(dialRetryInterval):
+		}
+	}
+}
+
+// PipeStdio copies in to conn and conn to out, netcat-style. The end of in is passed
+// on as a half-close so the peer can finish its reply; PipeStdio returns once the peer
+// closes the connection.
+func PipeStdio(conn net.Conn, in io.Reader, out io.Writer) error {
+	go func() {
+		io.Copy(conn, in)
+		if tcp, ok := conn.(*net.TCPConn); ok {
+			tcp.CloseWrite()
+		} else {
+			conn.Close()
+		}
+	}()
+
+	_, err := io.Copy(out, conn)
+	conn.Close()
+	if err != nil && !errors.Is(err, net.ErrClosed) {
+		return fmt.Errorf("connection failed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPipeStdio(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer listener.Close()
+
+	// Echo upper-cased input once the client half-closes
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		data, _ := io.ReadAll(conn)
+		conn.Write([]byte(strings.ToUpper(string(data))))
+	}()
+
+	conn, err := DialWithRetry(context.Background(), listener.Addr().String())
+	if err != nil {
+		t.Fatalf("DialWithRetry failed: %v", err)
+	}
+
+	var out bytes.Buffer
+	if err := PipeStdio(conn, strings.NewReader("ssh-2.0 hello"), &out); err != nil {
+		t.Fatalf("PipeStdio failed: %v", err)
+	}
+	if out.String() != "SSH-2.0 HELLO" {
+		t.Errorf("Expected the echoed reply, got %q", out.String())
+	}
+}
+
+func TestDialWithRetryWaitsForListener(t *testing.T) {
+	port, err := FindFreeLoopbackPort()
+	if err != nil {
+		t.Fatalf("Failed to find a port: %v", err)
+	}
+	address := net.JoinHostPort("127.0.0.1", strconv.Itoa(port))
+
+	// The forward comes up after a few failed attempts
+	go func() {
+		time.Sleep(600 * time.Millisecond)
+		listener, err := net.Listen("tcp", address)
+		if err != nil {
+			return
+		}
+		defer listener.Close()
+		if conn, err := listener.Accept(); err == nil {
+			conn.Close()
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	conn, err := DialWithRetry(ctx, address)
+	if err != nil {
+		t.Fatalf("Expected to connect once the listener is up, got %v", err)
+	}
+	conn.Close()
+
+	ctx, cancel = context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+	if _, err := DialWithRetry(ctx, "127.0.0.1:1"); err == nil {
+		t.Error("Expected an error once the context expires")
+	}
+}
@@ -0,0 +1,139 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrTeleportLoginRequired is returned when there is no valid Teleport session
+var ErrTeleportLoginRequired = errors.New("teleport login required")
+
+// teleportStatusTimeout bounds a tsh status run, e.g. one stuck on an unreachable proxy
+const teleportStatusTimeout = 15 * time.Second
+
+// TeleportTunnel describes a local forward through Teleport
+type TeleportTunnel struct {
+	Kind    string // "db", "app" or "node"
+	Proxy   string // Teleport proxy (default: the active tsh profile)
+	Cluster string // Leaf cluster
+	Target  string // Database or app name, or the host to reach from Node
+	Node    string // Node to tunnel through for kind node ([login@]node)
+	DBUser  string
+	DBName  string
+
+	BindAddress string // Local address to listen on; only supported for kind node
+	LocalPort   int
+	TargetPort  int // Port on Target for kind node
+
+	Env []string // Process environment as KEY=value pairs (default: inherited)
+}
+
+// Args returns the tsh command line arguments for the tunnel
+func (t TeleportTunnel) Args() ([]string, error) {
+	var args []string
+	switch t.Kind {
+	case "db":
+		args = []string{"proxy", "db", "--tunnel", "--port", strconv.Itoa(t.LocalPort)}
+		if t.DBUser != "" {
+			args = append(args, "--db-user", t.DBUser)
+		}
+		if t.DBName != "" {
+			args = append(args, "--db-name", t.DBName)
+		}
+	case "app":
+		args = []string{"proxy", "app", "--port", strconv.Itoa(t.LocalPort)}
+	case "node":
+		if t.Node == "" {
+			return nil, fmt.Errorf("teleport node tunnel requires a node")
+		}
+		args = []string{"ssh", "-N"}
+		for _, forward := range localForwards(t.BindAddress, t.LocalPort, t.Target, t.TargetPort) {
+			args = append(args, "-L", forward)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported teleport kind %q (use db, app or node)", t.Kind)
+	}
+
+	if t.BindAddress != "" && t.Kind != "node" {
+		return nil, fmt.Errorf("bindAddress is not supported for teleport %s tunnels", t.Kind)
+	}
+
+	args = append(args, teleportFlags(t.Proxy, t.Cluster)...)
+	if t.Kind == "node" {
+		return append(args, t.Node), nil
+	}
+	return append(args, t.Target), nil
+}
+
+// StartTeleportTunnel starts a tsh process holding the local forward open
+func StartTeleportTunnel(tunnel TeleportTunnel) (*exec.Cmd, error) {
+	args, err := tunnel.Args()
+	if err != nil {
+		return nil, err
+	}
+
+	cmd, err := startForwardProcess("tsh", args, tunnel.Env)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start tsh tunnel: %w", err)
+	}
+	return cmd, nil
+}
+
+// CheckTeleportSession returns ErrTeleportLoginRequired when tsh has no valid session for
+// the proxy. tsh is killed after teleportStatusTimeout or once ctx is done.
+func CheckTeleportSession(ctx context.Context, proxy string) error {
+	ctx, cancel := context.WithTimeout(ctx, teleportStatusTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "tsh", append([]string{"status"}, teleportFlags(proxy, "")...)...)
+	cmd.WaitDelay = time.Second // A child left running may hold the output open
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+
+	err := cmd.Run()
+	if err == nil {
+		return nil
+	}
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("tsh status timed out after %s", teleportStatusTimeout)
+	}
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		return fmt.Errorf("failed to run tsh: %w", err)
+	}
+
+	// tsh exits non-zero with "Not logged in" or "Active profile expired"
+	message := strings.ToLower(output.String())
+	if strings.Contains(message, "not logged in") || strings.Contains(message, "expired") ||
+		strings.Contains(message, "login") {
+		return ErrTeleportLoginRequired
+	}
+	return fmt.Errorf("tsh status failed: %s", strings.TrimSpace(output.String()))
+}
+
+// TeleportLoginCommand returns the command users run to start a new session
+func TeleportLoginCommand(proxy string) string {
+	if proxy == "" {
+		return "tsh login"
+	}
+	return "tsh login --proxy=" + proxy
+}
+
+// teleportFlags returns the proxy and cluster flags shared by tsh commands
+func teleportFlags(proxy, cluster string) []string {
+	var flags []string
+	if proxy != "" {
+		flags = append(flags, "--proxy="+proxy)
+	}
+	if cluster != "" {
+		flags = append(flags, "--cluster="+cluster)
+	}
+	return flags
+}
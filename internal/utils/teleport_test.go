@@ -0,0 +1,80 @@
+package utils
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTeleportTunnelArgs(t *testing.T) {
+	tests := []struct {
+		tunnel   TeleportTunnel
+		expected string
+	}{
+		{
+			TeleportTunnel{Kind: "db", Proxy: "teleport.example.com", Target: "orders", DBUser: "reader", LocalPort: 15432},
+			"proxy db --tunnel --port 15432 --db-user reader --proxy=teleport.example.com orders",
+		},
+		{
+			TeleportTunnel{Kind: "app", Cluster: "leaf", Target: "grafana", LocalPort: 3000},
+			"proxy app --port 3000 --cluster=leaf grafana",
+		},
+		{
+			TeleportTunnel{Kind: "node", Node: "ops@bastion", Target: "redis.internal", TargetPort: 6379, LocalPort: 16379},
+			"ssh -N -L 16379:redis.internal:6379 ops@bastion",
+		},
+	}
+
+	for _, tt := range tests {
+		args, err := tt.tunnel.Args()
+		if err != nil {
+			t.Fatalf("Args(%s) failed: %v", tt.tunnel.Kind, err)
+		}
+		if got := strings.Join(args, " "); got != tt.expected {
+			t.Errorf("Args(%s) = %q, expected %q", tt.tunnel.Kind, got, tt.expected)
+		}
+	}
+
+	if _, err := (TeleportTunnel{Kind: "kube"}).Args(); err == nil {
+		t.Error("Expected error for unsupported kind")
+	}
+	if _, err := (TeleportTunnel{Kind: "db", Target: "orders", BindAddress: "0.0.0.0"}).Args(); err == nil {
+		t.Error("Expected error for bindAddress on a db tunnel")
+	}
+}
+
+func TestTeleportLoginCommand(t *testing.T) {
+	if got := TeleportLoginCommand(""); got != "tsh login" {
+		t.Errorf("Unexpected login command: %q", got)
+	}
+	if got := TeleportLoginCommand("teleport.example.com"); got != "tsh login --proxy=teleport.example.com" {
+		t.Errorf("Unexpected login command: %q", got)
+	}
+}
+
+func TestCheckTeleportSessionStopsWithContext(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script as tsh")
+	}
+
+	// A tsh that hangs, e.g. on an unreachable proxy
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "tsh"), []byte("#!/bin/sh\nsleep 30\n"), 0755); err != nil {
+		t.Fatalf("Failed to write fake tsh: %v", err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	started := time.Now()
+	if err := CheckTeleportSession(ctx, ""); err == nil {
+		t.Error("Expected a cancelled tsh status to fail")
+	}
+	if elapsed := time.Since(started); elapsed > 5*time.Second {
+		t.Errorf("Expected tsh to be killed with the context, took %v", elapsed)
+	}
+}
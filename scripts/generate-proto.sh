@@ -0,0 +1,17 @@
+#!/usr/bin/env bash
+
+set -e
+
+# Regenerates the gRPC admin API stubs
+# Requires: buf, protoc-gen-go, protoc-gen-go-grpc
+#   go install github.com/bufbuild/buf/cmd/buf@latest
+#   go install google.golang.org/protobuf/cmd/protoc-gen-go@latest
+#   go install google.golang.org/grpc/cmd/protoc-gen-go-grpc@latest
+
+PROTO_DIR="internal/adminapi/adminpb"
+
+cd "$(dirname "$0")/.."
+
+echo "Generating gRPC code in ${PROTO_DIR}..."
+(cd "${PROTO_DIR}" && buf generate --template buf.gen.yaml .)
+echo "Done"